@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/britej3/gobot/internal/app"
 	"github.com/britej3/gobot/pkg/brain"
 	"github.com/britej3/gobot/pkg/platform"
 	internalPlatform "github.com/britej3/gobot/internal/platform"
@@ -66,13 +67,20 @@ func main() {
 	}
 
 	// Initialize platform
-	platform := platform.NewPlatform()
-	if err := platform.Start(); err != nil {
+	container := app.FromEnv()
+	platform := platform.NewPlatform(container.Klines())
+	container.Register(app.Hook{
+		Name:    "platform",
+		OnStart: func(context.Context) error { return platform.Start() },
+		OnStop:  platform.Stop,
+	})
+
+	if err := container.Start(context.Background()); err != nil {
 		logrus.Fatalf("❌ Platform initialization failed: %v", err)
 	}
 
 	// Setup graceful shutdown
-	setupGracefulShutdown(platform)
+	setupGracefulShutdown(container)
 
 	logrus.Info("✅ Cognee production system initialized successfully")
 	logrus.Info("🎯 System is ready for high-frequency scalping with AI intelligence")
@@ -185,7 +193,7 @@ func runTestTrade(symbol, side string, aggressive bool) {
 	logrus.Info("You can now start the full platform: ./cognee")
 }
 
-func setupGracefulShutdown(platform *platform.Platform) {
+func setupGracefulShutdown(container *app.Container) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
@@ -196,7 +204,7 @@ func setupGracefulShutdown(platform *platform.Platform) {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 		
-		if err := platform.Stop(ctx); err != nil {
+		if err := container.Stop(ctx); err != nil {
 			logrus.WithError(err).Error("Failed to stop platform gracefully")
 		}
 		
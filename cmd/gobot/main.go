@@ -0,0 +1,982 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/infra/binance"
+	"github.com/britej3/gobot/internal/app"
+	"github.com/britej3/gobot/internal/brain"
+	"github.com/britej3/gobot/internal/engine"
+	internalPlatform "github.com/britej3/gobot/internal/platform"
+	"github.com/britej3/gobot/internal/strategyplatform"
+	"github.com/britej3/gobot/pkg/alerting"
+	pkgbrain "github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/pkg/platform"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/configlog"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/equity"
+	"github.com/britej3/gobot/services/promptlab"
+	"github.com/britej3/gobot/services/report"
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `Usage: gobot <command> [flags]
+
+Commands:
+  run autonomous   AI brain platform with screener and position manager
+  run engine       watchlist trading engine with health and signal webhooks
+  run platform     strategy platform: configured strategies, allocator, universes and n8n automation
+  run follower     mirror a leader engine's signal stream on the local account
+  screener         list the pairs the screener currently selects
+  audit            check API connectivity and balances, then exit
+  audit-verify     check the hash chain of the signed audit and trade logs
+  fix-account      align position mode, margin mode and leverage with config
+  backtest         replay the WAL with a different confidence threshold
+  attribution      realized PnL by signal component from the trade journal
+  report           write an HTML report with charts of the journaled trades
+  decisions        query the decision log for why signals were taken or skipped
+  config-versions  list config versions with the trades taken under each
+  prompt-replay    compare the candidate brain prompt with the live one on recent setups
+
+Run "gobot <command> -h" for command flags.
+`
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		logrus.Debug("No .env file found, using system environment variables")
+	}
+
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch args[0] {
+	case "run":
+		if len(args) < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		switch args[1] {
+		case "autonomous":
+			err = runAutonomous(args[2:])
+		case "engine":
+			err = runEngine(args[2:])
+		case "platform":
+			err = runPlatform(args[2:])
+		case "follower":
+			err = runFollower(args[2:])
+		default:
+			err = fmt.Errorf("unknown run mode %q", args[1])
+		}
+	case "screener":
+		err = runScreener(args[1:])
+	case "audit":
+		err = runAudit(args[1:])
+	case "audit-verify":
+		err = runAuditVerify(args[1:])
+	case "fix-account":
+		err = runFixAccount(args[1:])
+	case "backtest":
+		err = runBacktest(args[1:])
+	case "attribution":
+		err = runAttribution(args[1:])
+	case "report":
+		err = runReport(args[1:])
+	case "decisions":
+		err = runDecisions(args[1:])
+	case "config-versions":
+		err = runConfigVersions(args[1:])
+	case "prompt-replay":
+		err = runPromptReplay(args[1:])
+	default:
+		err = fmt.Errorf("unknown command %q", args[0])
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("gobot failed")
+		os.Exit(1)
+	}
+}
+
+// loadContainer builds the container from the config file when it exists,
+// falling back to environment variables for modes that do not need one.
+func loadContainer(ctx context.Context, path string, required bool) (*app.Container, error) {
+	if _, err := os.Stat(path); err != nil {
+		if required {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+		return app.FromEnv(), nil
+	}
+	return app.Load(ctx, path)
+}
+
+// applyProfile applies the account preset named by a -profile flag over the
+// loaded config. It must run before any component is resolved.
+func applyProfile(container *app.Container, name string) error {
+	if name == "" {
+		return nil
+	}
+	if err := container.Config.ApplyProfile(name); err != nil {
+		return err
+	}
+	logrus.WithField("profile", name).Info("Account profile applied")
+	return nil
+}
+
+// lockInstance takes the account lock before anything writes to the
+// account. When another bot holds it the start is refused unless force is
+// set. The returned release drops the lock should the start fail before the
+// container's hooks run; after that it is a no-op.
+func lockInstance(ctx context.Context, container *app.Container, force bool) (func(), error) {
+	guard := container.InstanceLock()
+	if guard == nil {
+		return func() {}, nil
+	}
+	if err := guard.Acquire(ctx, force); err != nil {
+		// The conflict alert may be held for a digest; send it before exiting.
+		container.Telegram().Flush()
+		return nil, err
+	}
+	if !guard.Stats().Held {
+		logrus.Warn("⚠️ Forced start: another bot holds the account lock, both may trade until it stops")
+	}
+	return func() { guard.Stop() }, nil
+}
+
+// signalContext returns a context cancelled on SIGINT or SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigCh:
+			logrus.Info("🛑 Shutdown signal received")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+func shutdown(container *app.Container) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := container.Stop(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to stop components gracefully")
+	}
+	logrus.Info("✅ Shutdown complete")
+}
+
+func runAutonomous(args []string) error {
+	fs := flag.NewFlagSet("run autonomous", flag.ExitOnError)
+	skipAudit := fs.Bool("skip-audit", false, "Skip the pre-flight API audit")
+	force := fs.Bool("force", false, "Start even when another bot holds the account lock")
+	fs.Parse(args)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	if !*skipAudit {
+		status := internalPlatform.CheckConnection(os.Getenv("BINANCE_USE_TESTNET") == "true")
+		internalPlatform.PrintAuditReport(status)
+		if !status.IsConnected {
+			return fmt.Errorf("could not establish API connection, check keys and IP whitelist")
+		}
+	}
+
+	container := app.FromEnv()
+	release, err := lockInstance(ctx, container, *force)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	p := platform.NewPlatform(container.Klines())
+	container.Register(app.Hook{
+		Name:    "platform",
+		OnStart: func(context.Context) error { return p.Start() },
+		OnStop:  p.Stop,
+	})
+
+	if err := container.Start(ctx); err != nil {
+		return err
+	}
+	defer shutdown(container)
+
+	logrus.Info("✅ Autonomous platform running")
+	<-ctx.Done()
+	return nil
+}
+
+func runEngine(args []string) error {
+	fs := flag.NewFlagSet("run engine", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file")
+	addr := fs.String("addr", ":8080", "Health and webhook listen address")
+	fixAccount := fs.Bool("fix-account", false, "Apply account settings from config before trading")
+	watchOnly := fs.Bool("watch-only", false, "Log and alert on would-be entries without writing to the account")
+	skipPreflight := fs.Bool("skip-preflight", false, "Start without the preflight backtest even when it is enabled")
+	profile := fs.String("profile", "", "Account preset to size for: micro, small or medium")
+	force := fs.Bool("force", false, "Start even when another bot holds the account lock")
+	fs.Parse(args)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	container, err := loadContainer(ctx, *configPath, true)
+	if err != nil {
+		return err
+	}
+	if err := applyProfile(container, *profile); err != nil {
+		return err
+	}
+	release, err := lockInstance(ctx, container, *force)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if *watchOnly {
+		container.Config.Execution.WatchOnly = true
+	}
+
+	if container.Config.Execution.WatchOnly {
+		logrus.Warn("👀 Watch-only mode: signals are logged and alerted, no orders are sent")
+	} else if *fixAccount || container.Config.Account.FixOnStartup {
+		if err := applyAccountSettings(ctx, container, false); err != nil {
+			return err
+		}
+	}
+
+	if container.Config.Preflight.Enabled && !*skipPreflight {
+		if err := preflightBacktest(ctx, container); err != nil {
+			return err
+		}
+	}
+
+	eng, err := engine.NewTradingEngine(container)
+	if err != nil {
+		return err
+	}
+
+	elector, err := container.Failover()
+	if err != nil {
+		return err
+	}
+	if _, err := container.Research(); err != nil {
+		return err
+	}
+
+	if err := container.Start(ctx); err != nil {
+		return err
+	}
+	defer shutdown(container)
+
+	if elector != nil {
+		// Only the lease holder trades; the standby keeps its state in sync
+		// and starts the engine on the leader's state once it takes over.
+		elector.OnPromote(func(termCtx context.Context) {
+			logrus.WithField("instance", elector.Status().InstanceID).Warn("👑 Promoted to leader - taking over order management")
+			if err := eng.Start(termCtx); err != nil {
+				logrus.WithError(err).Error("Engine failed to start after promotion")
+			}
+		})
+		elector.OnDemote(func() {
+			logrus.WithField("instance", elector.Status().InstanceID).Warn("⏸️ Lost leadership - standing by")
+			eng.Stop()
+		})
+		if err := elector.Start(ctx); err != nil {
+			return err
+		}
+		logrus.WithField("instance", elector.Status().InstanceID).Info("🔁 Failover enabled - waiting for leadership")
+	} else {
+		if err := eng.Start(ctx); err != nil {
+			return err
+		}
+	}
+	defer eng.Stop()
+
+	server := &http.Server{Addr: *addr, Handler: eng.Handler(ctx)}
+	go func() {
+		logrus.WithField("addr", *addr).Info("Webhook server starting")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.WithError(err).Error("Webhook server failed")
+		}
+	}()
+
+	<-ctx.Done()
+	server.Shutdown(context.Background())
+	return nil
+}
+
+func runPlatform(args []string) error {
+	fs := flag.NewFlagSet("run platform", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file (optional, environment variables otherwise)")
+	addr := fs.String("addr", ":8080", "Health, suspensions, ideas and n8n webhook listen address")
+	strategies := fs.String("strategies", os.Getenv("STRATEGIES_CONFIG"), "JSON file of strategies to run next to the scalper and their allocation")
+	shadow := fs.String("shadow", os.Getenv("SHADOW_STRATEGY_CONFIG"), "JSON strategy config to evaluate in dry-run next to the primary one")
+	interval := fs.Duration("interval", strategyplatform.DefaultCycleInterval, "How often to select assets and run the strategies")
+	profile := fs.String("profile", "", "Account preset to size for: micro, small or medium")
+	force := fs.Bool("force", false, "Start even when another bot holds the account lock")
+	fs.Parse(args)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	container, err := loadContainer(ctx, *configPath, false)
+	if err != nil {
+		return err
+	}
+	if err := applyProfile(container, *profile); err != nil {
+		return err
+	}
+	release, err := lockInstance(ctx, container, *force)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	runner, err := strategyplatform.New(ctx, container, strategyplatform.Config{
+		ShadowStrategyPath: *shadow,
+		StrategiesPath:     *strategies,
+		CycleInterval:      *interval,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := container.Start(ctx); err != nil {
+		return err
+	}
+	defer shutdown(container)
+
+	server := &http.Server{Addr: *addr, Handler: runner.Handler()}
+	go func() {
+		logrus.WithField("addr", *addr).Info("Webhook server starting")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.WithError(err).Error("Webhook server failed")
+		}
+	}()
+	defer server.Shutdown(context.Background())
+
+	logrus.WithField("n8n", runner.N8NBaseURL()).Info("✅ Strategy platform running")
+	runner.Run(ctx)
+	return nil
+}
+
+func runFollower(args []string) error {
+	fs := flag.NewFlagSet("run follower", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file for the local account")
+	leader := fs.String("leader", "", "Base URL of the leader engine, e.g. http://leader:8080")
+	maxAge := fs.Duration("max-age", 30*time.Second, "Ignore leader signals older than this")
+	addr := fs.String("addr", ":8081", "Health listen address")
+	profile := fs.String("profile", "", "Account preset to size for: micro, small or medium")
+	force := fs.Bool("force", false, "Start even when another bot holds the account lock")
+	fs.Parse(args)
+
+	if *leader == "" {
+		return fmt.Errorf("-leader is required")
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	container, err := loadContainer(ctx, *configPath, true)
+	if err != nil {
+		return err
+	}
+	if err := applyProfile(container, *profile); err != nil {
+		return err
+	}
+	release, err := lockInstance(ctx, container, *force)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	eng, err := engine.NewTradingEngine(container)
+	if err != nil {
+		return err
+	}
+
+	if err := container.Start(ctx); err != nil {
+		return err
+	}
+	defer shutdown(container)
+
+	server := &http.Server{Addr: *addr, Handler: eng.Handler(ctx)}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.WithError(err).Error("Health server failed")
+		}
+	}()
+	defer server.Shutdown(context.Background())
+
+	if container.Config.Binance.UseTestnet {
+		logrus.WithField("leader", *leader).Info("🧪 Following leader on testnet")
+	} else {
+		logrus.WithField("leader", *leader).Warn("⚠️ Following leader on a LIVE account")
+	}
+
+	return eng.Follow(ctx, engine.FollowerConfig{LeaderURL: *leader, MaxSignalAge: *maxAge})
+}
+
+func runScreener(args []string) error {
+	fs := flag.NewFlagSet("screener", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file (optional)")
+	profile := fs.String("profile", "", "Account preset whose liquidity floor and pair count to screen with")
+	fs.Parse(args)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	container, err := loadContainer(ctx, *configPath, false)
+	if err != nil {
+		return err
+	}
+	if err := applyProfile(container, *profile); err != nil {
+		return err
+	}
+
+	s := container.Screener()
+	if err := container.Start(ctx); err != nil {
+		return err
+	}
+	defer shutdown(container)
+
+	for i, p := range s.GetPairsInfo() {
+		universe := p.Universe
+		if universe == "" {
+			universe = "-"
+		}
+		fmt.Printf("%2d. %-14s %-10s vol $%.0f  change %6.2f%%  15m %+6.2f%%  oi %6.2f%%  score %.2f\n",
+			i+1, p.Symbol, universe, p.Volume24h, p.PriceChangePct, p.Momentum.Return15m, p.OIChangePct, s.GetScore(p.Symbol))
+	}
+	if listings := container.Listing(); listings != nil {
+		if fresh := listings.NewListings(); len(fresh) > 0 {
+			fmt.Printf("\nNew listings: %s\n", strings.Join(fresh, ", "))
+		}
+	}
+	return nil
+}
+
+func runAudit(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	fs.Parse(args)
+
+	status := internalPlatform.CheckConnection(os.Getenv("BINANCE_USE_TESTNET") == "true")
+	internalPlatform.PrintAuditReport(status)
+	if !status.IsConnected {
+		return fmt.Errorf("could not establish API connection")
+	}
+	return nil
+}
+
+func runAuditVerify(args []string) error {
+	fs := flag.NewFlagSet("audit-verify", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file")
+	env := fs.String("env", "", "Environment the logs were signed for; defaults to the config's")
+	fs.Parse(args)
+
+	container, err := loadContainer(context.Background(), *configPath, true)
+	if err != nil {
+		return err
+	}
+	mon := container.Config.Monitoring
+	if mon.AuditSigningKey == "" {
+		return fmt.Errorf("monitoring.audit_signing_key is not set in %s or AUDIT_SIGNING_KEY", *configPath)
+	}
+	if *env == "" {
+		*env = container.Config.GetAuditEnvironment()
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{mon.AuditLogPath, mon.TradeLogPath}
+	}
+	failed := 0
+	for _, path := range paths {
+		v, err := alerting.VerifyAuditLog(path, mon.AuditSigningKey, *env)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL %s: %v (%d records verified before it)\n", path, err, v.Records)
+			continue
+		}
+		fmt.Printf("OK   %s: %d records, %d unsigned before the chain, head %d:%s\n", path, v.Records, v.Unsigned, v.LastSeq, v.Head)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d logs failed verification", failed, len(paths))
+	}
+	return nil
+}
+
+func runFixAccount(args []string) error {
+	fs := flag.NewFlagSet("fix-account", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file")
+	dryRun := fs.Bool("dry-run", false, "Report mismatches without changing anything")
+	fs.Parse(args)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	container, err := loadContainer(ctx, *configPath, true)
+	if err != nil {
+		return err
+	}
+	return applyAccountSettings(ctx, container, *dryRun)
+}
+
+// applyAccountSettings reports or fixes account settings that differ from
+// config, failing if any mismatch could not be corrected.
+func applyAccountSettings(ctx context.Context, container *app.Container, dryRun bool) error {
+	fixer := container.AccountSetup()
+
+	run := fixer.Fix
+	if dryRun {
+		run = fixer.Check
+	}
+	mismatches, err := run(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(mismatches) == 0 {
+		logrus.Info("✅ Account settings match config")
+		return nil
+	}
+
+	failed := 0
+	for _, m := range mismatches {
+		entry := logrus.WithField("mismatch", m.String())
+		switch {
+		case m.Err != nil:
+			failed++
+			entry.Error("Account setting not corrected")
+		case m.Fixed:
+			entry.Info("🔧 Account setting corrected")
+		default:
+			entry.Warn("Account setting differs from config")
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d account settings could not be corrected", failed)
+	}
+	return nil
+}
+
+// preflightBacktest replays the config over recent candles of the watchlist
+// and stops startup when the simulated drawdown is past the tolerance,
+// unless the operator confirms
+func preflightBacktest(ctx context.Context, container *app.Container) error {
+	cfg := container.Config.Preflight
+	end := time.Now()
+	start := end.AddDate(0, 0, -cfg.GetDays())
+	source := binance.NewFuturesKlineSource(container.Futures())
+
+	history := make(map[string][]trade.Kline, len(container.Config.Watchlist.Symbols))
+	for _, symbol := range container.Config.Watchlist.Symbols {
+		klines, err := source.Range(ctx, symbol, engine.ReplayInterval, start, end)
+		if err != nil {
+			return fmt.Errorf("preflight: failed to load %s candles: %w", symbol, err)
+		}
+		history[symbol] = klines
+	}
+
+	result, err := engine.Replay(ctx, container.Config, history)
+	if err != nil {
+		return fmt.Errorf("preflight: %w", err)
+	}
+	logrus.WithField("result", result.String()).Info("🧪 Preflight backtest")
+	if result.MaxDrawdownPct <= cfg.MaxDrawdownPct {
+		return nil
+	}
+
+	breach := fmt.Sprintf("simulated max drawdown %.2f%% exceeds preflight.max_drawdown_percent %.2f%%", result.MaxDrawdownPct, cfg.MaxDrawdownPct)
+	if cfg.OnBreach != "confirm" {
+		return fmt.Errorf("preflight: %s; rerun with --skip-preflight to start anyway", breach)
+	}
+	fmt.Printf("%s. Go live anyway? [y/N] ", breach)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+		return fmt.Errorf("preflight: %s", breach)
+	}
+	logrus.Warn("Preflight drawdown accepted by the operator")
+	return nil
+}
+
+func runBacktest(args []string) error {
+	fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+	walPath := fs.String("wal", "trade.wal", "Write-ahead log to replay")
+	threshold := fs.Float64("threshold", 0.75, "Confidence threshold to simulate")
+	perturbation := fs.Float64("perturbation", 0, "Also run a perturbation test with this threshold offset")
+	fs.Parse(args)
+
+	bt := brain.NewBacktester(*walPath)
+	result, err := bt.RunBacktest(*threshold)
+	if err != nil {
+		return err
+	}
+	printSimulation("backtest", result)
+
+	if *perturbation > 0 {
+		result, err := bt.PerturbationTest(*threshold, *perturbation)
+		if err != nil {
+			return err
+		}
+		printSimulation("perturbation", result)
+	}
+	return nil
+}
+
+func runAttribution(args []string) error {
+	fs := flag.NewFlagSet("attribution", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file")
+	fs.Parse(args)
+
+	container, err := loadContainer(context.Background(), *configPath, true)
+	if err != nil {
+		return err
+	}
+	st, err := container.State()
+	if err != nil {
+		return err
+	}
+
+	stats := st.Attribution()
+	if len(stats) == 0 {
+		fmt.Println("No closed trades with signal components in the journal yet")
+		return nil
+	}
+
+	fmt.Printf("%-16s %7s %8s %12s %10s %8s\n", "component", "trades", "win%", "pnl", "avg", "corr")
+	for _, c := range stats {
+		fmt.Printf("%-16s %7d %7.1f%% %12.2f %10.2f %+8.2f\n",
+			c.Component, c.Trades, c.WinRate, c.PnL, c.AvgPnL, c.Correlation)
+	}
+	return nil
+}
+
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file")
+	statePath := fs.String("state", "", "Report on this state file, e.g. a testnet run's, instead of the configured journal")
+	since := fs.Duration("since", 0, "Only trades closed in this window; 0 reports the whole journal")
+	out := fs.String("out", "reports", "Directory the report is written to")
+	title := fs.String("title", "", "Report title")
+	sendTelegram := fs.Bool("telegram", false, "Also send the report as a Telegram document")
+	fs.Parse(args)
+
+	container, err := loadContainer(context.Background(), *configPath, *statePath == "")
+	if err != nil {
+		return err
+	}
+
+	var st *state.TradingState
+	if *statePath != "" {
+		data, err := os.ReadFile(*statePath)
+		if err != nil {
+			return err
+		}
+		st = &state.TradingState{}
+		if err := st.Restore(data); err != nil {
+			return err
+		}
+	} else if st, err = container.State(); err != nil {
+		return err
+	}
+
+	var from time.Time
+	if *since > 0 {
+		from = time.Now().Add(-*since)
+	}
+	var trades []state.Trade
+	var pnl float64
+	for _, t := range st.Trades() {
+		if t.ExitTime.IsZero() || t.ExitTime.Before(from) {
+			continue
+		}
+		trades = append(trades, t)
+		pnl += t.PnL
+	}
+	if len(trades) == 0 {
+		fmt.Println("No closed trades in the journal for this window")
+		return nil
+	}
+
+	// Account snapshots only describe the configured account, not a state
+	// file from elsewhere.
+	var snaps []equity.Snapshot
+	if path := container.Config.Monitoring.EquityLogPath; path != "" && *statePath == "" {
+		if snaps, err = equity.Read(path, from); err != nil {
+			return err
+		}
+	}
+
+	r := report.Build(report.Config{Title: *title, Capital: st.GetStats().Capital - pnl}, trades, snaps)
+	path, err := report.Write(*out, "report", r)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d trades, PnL %.2f, max drawdown %.2f%%: %s\n", r.Summary.Trades, r.Summary.PnL, r.Summary.MaxDrawdown, path)
+
+	if *sendTelegram {
+		if !container.Config.Monitoring.TelegramEnabled {
+			return fmt.Errorf("monitoring.telegram_enabled is off in %s", *configPath)
+		}
+		caption := fmt.Sprintf("%s: %d trades, PnL %.2f", r.Title, r.Summary.Trades, r.Summary.PnL)
+		if err := container.Telegram().SendDocument(path, caption); err != nil {
+			return fmt.Errorf("failed to send report: %w", err)
+		}
+	}
+	return nil
+}
+
+func runDecisions(args []string) error {
+	fs := flag.NewFlagSet("decisions", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file")
+	symbol := fs.String("symbol", "", "Only decisions for this symbol")
+	action := fs.String("action", "", "Only this action: enter, skip, reject or drop")
+	reason := fs.String("reason", "", "Only decisions with this reason code, e.g. risk_reward")
+	since := fs.Duration("since", 24*time.Hour, "How far back to look; 0 reads the whole log")
+	limit := fs.Int("limit", 50, "Show at most this many of the most recent decisions; 0 shows all")
+	asJSON := fs.Bool("json", false, "Print matching records as JSON lines")
+	fs.Parse(args)
+
+	container, err := loadContainer(context.Background(), *configPath, true)
+	if err != nil {
+		return err
+	}
+	path := container.Config.Monitoring.DecisionLogPath
+	if path == "" {
+		return fmt.Errorf("monitoring.decision_log_path is not set in %s", *configPath)
+	}
+
+	q := decisionlog.Query{
+		Symbol: *symbol,
+		Action: decisionlog.Action(*action),
+		Reason: *reason,
+		Limit:  *limit,
+	}
+	if *since > 0 {
+		q.Since = time.Now().Add(-*since)
+	}
+	records, err := decisionlog.Read(path, q)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println("No matching decisions")
+		return nil
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, r := range records {
+			enc.Encode(r)
+		}
+		return nil
+	}
+	fmt.Printf("%-20s %-12s %-6s %-7s %-22s %s\n", "time", "symbol", "side", "action", "reasons", "detail")
+	for _, r := range records {
+		fmt.Printf("%-20s %-12s %-6s %-7s %-22s %s\n",
+			r.Time.Format("2006-01-02 15:04:05"), r.Symbol, r.Candidate, r.Action, strings.Join(r.Reasons, ","), r.Detail)
+	}
+	return nil
+}
+
+func runConfigVersions(args []string) error {
+	fs := flag.NewFlagSet("config-versions", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file")
+	show := fs.Int("show", 0, "Print the changes and full settings of this version")
+	fs.Parse(args)
+
+	container, err := loadContainer(context.Background(), *configPath, true)
+	if err != nil {
+		return err
+	}
+	path := container.Config.Monitoring.ConfigLogPath
+	if path == "" {
+		return fmt.Errorf("monitoring.config_log_path is not set in %s", *configPath)
+	}
+	versions, err := configlog.Read(path)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Println("No config versions recorded yet")
+		return nil
+	}
+
+	if *show > 0 {
+		for _, v := range versions {
+			if v.Version != *show {
+				continue
+			}
+			fmt.Printf("version %d, %s from %s %s\n", v.Version, v.Time.Format("2006-01-02 15:04:05"), v.Source, v.Note)
+			for _, c := range v.Changes {
+				fmt.Printf("  %s: %v -> %v\n", c.Key, c.Old, c.New)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(v.Settings)
+		}
+		return fmt.Errorf("no config version %d", *show)
+	}
+
+	// Trades are attributed to the version in effect when they were entered.
+	type regime struct {
+		trades, wins int
+		pnl          float64
+	}
+	regimes := make(map[int]*regime)
+	if st, err := container.State(); err == nil {
+		for _, t := range st.Trades() {
+			v, ok := configlog.At(versions, t.EntryTime)
+			if !ok {
+				continue
+			}
+			r := regimes[v.Version]
+			if r == nil {
+				r = &regime{}
+				regimes[v.Version] = r
+			}
+			r.trades++
+			r.pnl += t.PnL
+			if t.PnL > 0 {
+				r.wins++
+			}
+		}
+	}
+
+	fmt.Printf("%-8s %-20s %-10s %8s %7s %8s %12s\n", "version", "time", "source", "changes", "trades", "win%", "pnl")
+	for _, v := range versions {
+		r := regimes[v.Version]
+		if r == nil {
+			r = &regime{}
+		}
+		winRate := 0.0
+		if r.trades > 0 {
+			winRate = float64(r.wins) / float64(r.trades) * 100
+		}
+		fmt.Printf("%-8d %-20s %-10s %8d %7d %7.1f%% %12.2f\n",
+			v.Version, v.Time.Format("2006-01-02 15:04:05"), v.Source, len(v.Changes), r.trades, winRate, r.pnl)
+	}
+	return nil
+}
+
+func runPromptReplay(args []string) error {
+	fs := flag.NewFlagSet("prompt-replay", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "Production config file")
+	sample := fs.Int("sample", 20, "Replay this many of the most recent brain-confirmed setups")
+	since := fs.Duration("since", 24*time.Hour, "How far back to sample; candles only reach back about a day")
+	horizon := fs.Duration("horizon", time.Hour, "Close simulated trades that hit neither exit after this long")
+	verbose := fs.Bool("v", false, "List every replayed setup, not only those the prompts disagree on")
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	fs.Parse(args)
+
+	container, err := loadContainer(context.Background(), *configPath, true)
+	if err != nil {
+		return err
+	}
+	cfg := container.Config
+	if cfg.AI.CandidatePrompt == "" {
+		return fmt.Errorf("ai.candidate_prompt is not set in %s", *configPath)
+	}
+	if cfg.Monitoring.DecisionLogPath == "" {
+		return fmt.Errorf("monitoring.decision_log_path is not set in %s", *configPath)
+	}
+	candidate, err := pkgbrain.ParsePrompt(cfg.AI.CandidatePrompt)
+	if err != nil {
+		return fmt.Errorf("ai.candidate_prompt: %w", err)
+	}
+	engineBrain, err := container.Brain()
+	if err != nil {
+		return err
+	}
+
+	records, err := decisionlog.Read(cfg.Monitoring.DecisionLogPath, decisionlog.Query{Since: time.Now().Add(-*since)})
+	if err != nil {
+		return err
+	}
+	report, err := promptlab.Run(context.Background(), promptlab.Config{
+		Sample:            *sample,
+		MinConfidence:     cfg.Trading.MinConfidence,
+		StopLossPercent:   cfg.Trading.StopLossPercent,
+		TakeProfitPercent: cfg.Trading.TakeProfitPercent,
+		Horizon:           *horizon,
+		Calls:             container.Calls(),
+	}, records, engineBrain.DecisionPrompt, candidate.Render, engineBrain, binance.NewFuturesKlineSource(container.Futures()))
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	if len(report.Cases) == 0 {
+		fmt.Println("No brain-confirmed setups with recorded inputs to replay")
+		return nil
+	}
+
+	fmt.Printf("%-20s %-12s %-6s %-16s %-16s %-12s %8s\n", "time", "symbol", "side", "live", "candidate", "exit", "return%")
+	for _, c := range report.Cases {
+		if !*verbose && !c.Differs() {
+			continue
+		}
+		fmt.Printf("%-20s %-12s %-6s %-16s %-16s %-12s %+8.2f\n",
+			c.Time.Format("2006-01-02 15:04:05"), c.Symbol, c.Side, verdict(c.Live), verdict(c.Candidate), c.Outcome.Exit, c.Outcome.ReturnPct)
+	}
+	fmt.Printf("\n%d setups replayed, %d decided differently (* marks an entry)\n", len(report.Cases), report.Differ)
+	for _, t := range []struct {
+		name  string
+		tally promptlab.Tally
+	}{{"live", report.Live}, {"candidate", report.Candidate}} {
+		fmt.Printf("%-10s entries=%d wins=%d errors=%d simulated_return=%+.2f%%\n",
+			t.name, t.tally.Entries, t.tally.Wins, t.tally.Errors, t.tally.ReturnPct)
+	}
+	return nil
+}
+
+func verdict(v promptlab.Verdict) string {
+	if v.Err != "" {
+		return "error"
+	}
+	s := fmt.Sprintf("%s %.2f", v.Decision, v.Confidence)
+	if v.Enter {
+		s += " *"
+	}
+	return s
+}
+
+func printSimulation(name string, r *brain.SimulationResult) {
+	winRate := 0.0
+	if r.TotalTrades > 0 {
+		winRate = float64(r.WinningTrades) / float64(r.TotalTrades) * 100
+	}
+	fmt.Printf("%s: trades=%d win_rate=%.1f%% original_pnl=%.4f simulated_pnl=%.4f avg_slippage=%.2fbps\n",
+		name, r.TotalTrades, winRate, r.OriginalPnL, r.SimulatedPnL, r.AverageSlippage)
+}
@@ -37,6 +37,7 @@ func main() {
 
 	screenerInstance := screener.NewScreener(client,
 		screener.WithAssetFilter(screener.DefaultMemeCoinFilter()),
+		screener.WithUniverses(screener.DefaultUniverses(), "meme"),
 		screener.WithMaxPairs(10),
 		screener.WithSortBy("volatility"),
 	)
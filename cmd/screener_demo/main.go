@@ -29,9 +29,11 @@ func main() {
 
 	screenerInstance := screener.NewScreener(adapter,
 		screener.WithAssetFilter(screener.DefaultMemeCoinFilter()),
+		screener.WithUniverses(screener.DefaultUniverses(), "meme"),
 		screener.WithInterval(5*time.Minute),
 		screener.WithMaxPairs(10),
 		screener.WithSortBy("volatility"),
+		screener.WithOpenInterest(adapter),
 	)
 
 	log.Println("Starting screener...")
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccountPreset sizes the bot for an account of up to MaxCapitalUSD. It sets
+// position sizing, leverage, the screener's liquidity floor and pair count,
+// and the earn sweep thresholds, which otherwise default to values that only
+// make sense for one account size.
+type AccountPreset struct {
+	Name             string
+	MaxCapitalUSD    float64
+	MaxPositionUSD   float64
+	MaxRiskPerTrade  float64
+	Leverage         int
+	MaxLeverage      int
+	MaxTradesPerDay  int
+	MinVolume24HUSD  float64
+	MaxPairs         int
+	MinSweepUSD      float64
+	MinFreeMarginUSD float64
+}
+
+// AccountPresets are the built-in presets, smallest account first. A preset
+// covers capital from the previous preset's MaxCapitalUSD up to its own.
+var AccountPresets = []AccountPreset{
+	{Name: "micro", MaxCapitalUSD: 100, MaxPositionUSD: 25, MaxRiskPerTrade: 0.01, Leverage: 5, MaxLeverage: 10,
+		MaxTradesPerDay: 3, MinVolume24HUSD: 20_000_000, MaxPairs: 3, MinSweepUSD: 10, MinFreeMarginUSD: 20},
+	{Name: "small", MaxCapitalUSD: 1_000, MaxPositionUSD: 150, MaxRiskPerTrade: 0.01, Leverage: 5, MaxLeverage: 10,
+		MaxTradesPerDay: 5, MinVolume24HUSD: 50_000_000, MaxPairs: 5, MinSweepUSD: 50, MinFreeMarginUSD: 100},
+	{Name: "medium", MaxCapitalUSD: 10_000, MaxPositionUSD: 1_500, MaxRiskPerTrade: 0.0075, Leverage: 3, MaxLeverage: 10,
+		MaxTradesPerDay: 8, MinVolume24HUSD: 100_000_000, MaxPairs: 8, MinSweepUSD: 250, MinFreeMarginUSD: 1_000},
+}
+
+// FindAccountPreset returns the built-in preset called name.
+func FindAccountPreset(name string) (AccountPreset, bool) {
+	for _, p := range AccountPresets {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return AccountPreset{}, false
+}
+
+// ApplyProfile overwrites the fields covered by the named account preset and
+// records it in Profile. It fails for an unknown preset, and for one made for
+// a different account size than trading.initial_capital_usd.
+func (c *ProductionConfig) ApplyProfile(name string) error {
+	p, ok := FindAccountPreset(name)
+	if !ok {
+		names := make([]string, 0, len(AccountPresets))
+		for _, p := range AccountPresets {
+			names = append(names, p.Name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	var min float64
+	for _, q := range AccountPresets {
+		if q.MaxCapitalUSD < p.MaxCapitalUSD && q.MaxCapitalUSD > min {
+			min = q.MaxCapitalUSD
+		}
+	}
+	if capital := c.Trading.InitialCapitalUSD; capital > 0 && (capital < min || capital >= p.MaxCapitalUSD) {
+		return fmt.Errorf("profile %s is for accounts from %.0f to under %.0f USDT, trading.initial_capital_usd is %.2f",
+			p.Name, min, p.MaxCapitalUSD, capital)
+	}
+
+	c.Profile = p.Name
+	c.Trading.MaxPositionUSD = p.MaxPositionUSD
+	c.Trading.MaxRiskPerTrade = p.MaxRiskPerTrade
+	c.Trading.MaxTradesPerDay = p.MaxTradesPerDay
+	c.Trading.MinVolume24HUSD = p.MinVolume24HUSD
+	c.Trading.MaxPairs = p.MaxPairs
+	c.Account.Leverage = p.Leverage
+	c.Account.MaxLeverage = p.MaxLeverage
+	c.Earn.MinSweepUSD = p.MinSweepUSD
+	c.Earn.MinFreeMarginUSD = p.MinFreeMarginUSD
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -13,21 +14,57 @@ import (
 
 type ProductionConfig struct {
 	Binance        BinanceAPIConfig     `yaml:"binance"`
+	Exchange       ExchangeConfig       `yaml:"exchange"`
 	Trading        TradingConfig        `yaml:"trading"`
 	Execution      ExecutionConfig      `yaml:"execution"`
 	Stealth        StealthConfig        `yaml:"stealth"`
 	AI             AIConfig             `yaml:"ai"`
 	Watchlist      WatchlistConfig      `yaml:"watchlist"`
+	Universes      UniversesConfig      `yaml:"universes"`
+	SymbolMemory   SymbolMemoryConfig   `yaml:"symbol_memory"`
+	SymbolThrottle SymbolThrottleConfig `yaml:"symbol_throttle"`
+	Account        AccountConfig        `yaml:"account"`
+	Earn           EarnConfig           `yaml:"earn"`
 	Risk           RiskConfig           `yaml:"risk"`
 	Emergency      EmergencyConfig      `yaml:"emergency"`
+	Calendar       CalendarConfig       `yaml:"calendar"`
+	KeyHealth      KeyHealthConfig      `yaml:"key_health"`
+	Drift          DriftConfig          `yaml:"drift"`
+	Listing        ListingConfig        `yaml:"listing"`
+	Fees           FeesConfig           `yaml:"fees"`
+	Ideas          IdeasConfig          `yaml:"ideas"`
+	Research       ResearchConfig       `yaml:"research"`
+	Screener       ScreenerConfig       `yaml:"screener"`
+	Protection     ProtectionConfig     `yaml:"protection"`
+	EntryLimits    EntryLimitsConfig    `yaml:"entry_limits"`
+	Preflight      PreflightConfig      `yaml:"preflight"`
+	RiskModes      RiskModesConfig      `yaml:"risk_modes"`
+	Sentiment      SentimentConfig      `yaml:"sentiment"`
 	Monitoring     MonitoringConfig     `yaml:"monitoring"`
 	State          StateConfig          `yaml:"state"`
+	Failover       FailoverConfig       `yaml:"failover"`
+	InstanceLock   InstanceLockConfig   `yaml:"instance_lock"`
+	Events         EventsConfig         `yaml:"events"`
+	Strategies     StrategiesConfig     `yaml:"strategies"`
+	Regime         RegimeConfig         `yaml:"regime"`
 	Performance    PerformanceConfig    `yaml:"performance"`
 	TradingView    TradingViewConfig    `yaml:"tradingview"`
 	N8NIntegration N8NConfig            `yaml:"n8n"`
 	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+
+	// Profile names the account preset applied over this file, micro, small
+	// or medium; see AccountPresets. Empty keeps the file's values.
+	Profile string `yaml:"profile"`
+
+	// Namespace labels everything this bot writes or sends (trade journal,
+	// decision and equity logs, audit records, events, metrics and alerts)
+	// so several bots can share storage, dashboards and a chat and still be
+	// told apart. Empty leaves records unlabelled.
+	Namespace string `yaml:"namespace"`
 }
 
+var namespacePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+
 type BinanceAPIConfig struct {
 	APIKey         string `yaml:"api_key"`
 	APISecret      string `yaml:"api_secret"`
@@ -35,6 +72,28 @@ type BinanceAPIConfig struct {
 	RateLimitRPS   int    `yaml:"rate_limit_rps"`
 	RateLimitBurst int    `yaml:"rate_limit_burst"`
 	RecvWindowMS   int    `yaml:"recv_window_ms"`
+	// EndpointSelection benchmarks Endpoints (the fapi clusters when empty)
+	// and routes orders through the fastest healthy one. Ignored on testnet.
+	EndpointSelection bool     `yaml:"endpoint_selection"`
+	Endpoints         []string `yaml:"endpoints"`
+	EndpointProbeSecs int      `yaml:"endpoint_probe_seconds"`
+
+	// WeightLimit is the request weight the account may spend per minute.
+	// Components named in WeightQuotas (screener, klines, monitors) are held
+	// to that share of it, and only the executor may spend the last
+	// WeightReserve share.
+	WeightLimit   int                `yaml:"weight_limit"`
+	WeightQuotas  map[string]float64 `yaml:"weight_quotas"`
+	WeightReserve float64            `yaml:"weight_reserve"`
+}
+
+// GetEndpointProbeInterval returns how often endpoint latency is measured
+// again after startup, one minute by default.
+func (c BinanceAPIConfig) GetEndpointProbeInterval() time.Duration {
+	if c.EndpointProbeSecs <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.EndpointProbeSecs) * time.Second
 }
 
 func (c BinanceAPIConfig) Endpoint() string {
@@ -44,6 +103,20 @@ func (c BinanceAPIConfig) Endpoint() string {
 	return "https://fapi.binance.com"
 }
 
+// ExchangeConfig picks the venue the platform executor routes orders to.
+// The watchlist engine and market data stay on Binance either way.
+type ExchangeConfig struct {
+	Venue       string            `yaml:"venue"` // binance (default) or hyperliquid
+	Hyperliquid HyperliquidConfig `yaml:"hyperliquid"`
+}
+
+type HyperliquidConfig struct {
+	PrivateKey      string  `yaml:"private_key"`
+	AccountAddress  string  `yaml:"account_address"`
+	Testnet         bool    `yaml:"testnet"`
+	SlippagePercent float64 `yaml:"slippage_percent"`
+}
+
 type TradingConfig struct {
 	InitialCapitalUSD   float64 `yaml:"initial_capital_usd"`
 	MaxPositionUSD      float64 `yaml:"max_position_usd"`
@@ -51,18 +124,46 @@ type TradingConfig struct {
 	WeeklyLossLimit     float64 `yaml:"weekly_loss_limit"`
 	StopLossPercent     float64 `yaml:"stop_loss_percent"`
 	TakeProfitPercent   float64 `yaml:"take_profit_percent"`
+	StopMode            string  `yaml:"stop_mode"`
+	ATRStopMultiple     float64 `yaml:"atr_stop_multiple"`
+	ATRTargetMultiple   float64 `yaml:"atr_target_multiple"`
 	TrailingStopEnabled bool    `yaml:"trailing_stop_enabled"`
 	TrailingStopPercent float64 `yaml:"trailing_stop_percent"`
 	MaxDailyDrawdown    float64 `yaml:"max_daily_drawdown"`
 	KellyFraction       float64 `yaml:"kelly_fraction"`
 	MaxRiskPerTrade     float64 `yaml:"max_risk_per_trade"`
+	MinNotionalMaxRisk  float64 `yaml:"min_notional_max_risk"`
 	TradingIntervalMin  int     `yaml:"trading_interval_minutes"`
 	MaxTradesPerDay     int     `yaml:"max_trades_per_day"`
 	SymbolCooldownMin   int     `yaml:"symbol_cooldown_minutes"`
 	MinConfidence       float64 `yaml:"min_confidence_threshold"`
 	MinRiskRewardRatio  float64 `yaml:"min_risk_reward_ratio"`
+	TakerFeeRate        float64 `yaml:"taker_fee_rate"`
+	MakerFeeRate        float64 `yaml:"maker_fee_rate"`
+	MinExpectedValueBps float64 `yaml:"min_expected_value_bps"`
+	ClusterWindowSecs   int     `yaml:"cluster_window_seconds"`
 	MaxSpreadPercent    float64 `yaml:"max_spread_percent"`
 	MinVolume24HUSD     float64 `yaml:"min_volume_24h_usd"`
+	MaxPairs            int     `yaml:"max_pairs"`
+	MaxDataAgeSeconds   int     `yaml:"max_data_age_seconds"`
+	ScoreHalfLifeSecs   int     `yaml:"score_half_life_seconds"`
+	SignalFreshnessSecs int     `yaml:"signal_freshness_seconds"`
+	MomentumWeight      float64 `yaml:"momentum_weight"`
+
+	// LevelsInterval turns on support and resistance levels clustered on
+	// candles of this interval, e.g. "15m". Breakouts must then close
+	// through a level of at least LevelsMinStrength (0..1, default 0.3),
+	// and ATR stops are placed beyond the nearest one.
+	LevelsInterval    string  `yaml:"levels_interval"`
+	LevelsMinStrength float64 `yaml:"levels_min_strength"`
+
+	// SqueezeInterval turns on the Bollinger/Keltner squeeze detector over
+	// the watchlist's candles of this interval, e.g. "5m". A squeeze of at
+	// least SqueezeMinBars candles (default 6) releasing in a setup's
+	// direction adds up to SqueezeWeight (default 0.15) to its confidence.
+	SqueezeInterval string  `yaml:"squeeze_interval"`
+	SqueezeMinBars  int     `yaml:"squeeze_min_bars"`
+	SqueezeWeight   float64 `yaml:"squeeze_weight"`
 }
 
 type ExecutionConfig struct {
@@ -71,6 +172,103 @@ type ExecutionConfig struct {
 	MaxDailyTrades      int     `yaml:"max_daily_trades"`
 	RequireTrendConfirm bool    `yaml:"require_trend_confirmation"`
 	RequireVolumeSpike  bool    `yaml:"require_volume_spike"`
+	OrderWorkers        int     `yaml:"order_workers"`
+	MaxQueuedOrders     int     `yaml:"max_queued_orders"`
+	MaxSignalAgeSeconds int     `yaml:"max_signal_age_seconds"`
+
+	// EntryMode is "market" (default) or "chase", which works entries as a
+	// limit at the top of book re-pegged up to ChaseMaxRepegs times.
+	EntryMode           string `yaml:"entry_mode"`
+	ChaseMaxRepegs      int    `yaml:"chase_max_repegs"`
+	ChaseMaxWaitSeconds int    `yaml:"chase_max_wait_seconds"`
+	ChasePollMS         int    `yaml:"chase_poll_ms"`
+	// ChaseRemainder is what a chase that ran out partly filled does with the
+	// rest: "cancel" (default) keeps the partial position, "market" takes it
+	// at market within ChaseRemainderMaxSlippageBps of arrival, "chase"
+	// re-pegs it ChaseRemainderRepegs more times.
+	ChaseRemainder               string  `yaml:"chase_remainder"`
+	ChaseRemainderMaxSlippageBps float64 `yaml:"chase_remainder_max_slippage_bps"`
+	ChaseRemainderRepegs         int     `yaml:"chase_remainder_repegs"`
+
+	// TimeInForce, when set with the market entry mode, enters with limit
+	// orders instead: IOC sweeps TIFSweepBps through the far touch and
+	// retries the remainder, FOK does so all-or-nothing, and GTX posts at the
+	// near touch for up to TIFRestSeconds. Each attempt is retried up to
+	// TIFRetries times.
+	TimeInForce    string  `yaml:"time_in_force"`
+	TIFSweepBps    float64 `yaml:"tif_sweep_bps"`
+	TIFRetries     int     `yaml:"tif_retries"`
+	TIFRestSeconds int     `yaml:"tif_rest_seconds"`
+
+	// MaxEntryDrift rejects an entry when the live book has already moved
+	// this fraction of the way from the signal's entry to its take profit.
+	// Zero disables the check.
+	MaxEntryDrift float64 `yaml:"max_entry_drift"`
+
+	// LocalBooks maintains order books for the watchlist from the depth
+	// diff stream and reads quotes from them instead of the REST book
+	// ticker. MinBookDepthUSD, when set, rejects entries with less than that
+	// resting on the side they take within BookDepthBps of the mid.
+	LocalBooks      bool    `yaml:"local_books"`
+	MinBookDepthUSD float64 `yaml:"min_book_depth_usd"`
+	BookDepthBps    float64 `yaml:"book_depth_bps"`
+
+	// WatchOnly runs the full signal pipeline but never places, cancels or
+	// transfers anything; signals that would have been entered are logged
+	// and alerted instead.
+	WatchOnly bool `yaml:"watch_only"`
+
+	// CostModel forecasts each entry's slippage from the book it would walk
+	// and the slippage its symbol and size have realized, at least
+	// CostMinSamples fills, and charges it with fees in the EV gate in place
+	// of half the spread.
+	CostModel      bool `yaml:"cost_model"`
+	CostMinSamples int  `yaml:"cost_min_samples"`
+
+	// AsyncOrders returns from an entry once its order is queued; the fill,
+	// cancel or rejection arrives on the account stream and completes the
+	// entry then. An order the exchange accepted but the stream has not
+	// settled within AckTimeoutSecs is looked up.
+	AsyncOrders    bool `yaml:"async_orders"`
+	AckTimeoutSecs int  `yaml:"ack_timeout_seconds"`
+
+	// ShadowFills fills every live entry on paper as well, charging market
+	// fills ShadowSlippageBps beyond the touch, and reports how far the
+	// simulated fills and PnL drift from the real ones.
+	ShadowFills       bool    `yaml:"shadow_fills"`
+	ShadowSlippageBps float64 `yaml:"shadow_slippage_bps"`
+}
+
+// GetMaxSignalAge returns how old a queued entry's signal may get before it
+// is dropped instead of sent, ten seconds by default.
+func (c ExecutionConfig) GetMaxSignalAge() time.Duration {
+	if c.MaxSignalAgeSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.MaxSignalAgeSeconds) * time.Second
+}
+
+// GetBookDepthBps returns the band around the mid the depth gate counts, 10
+// basis points by default.
+func (c ExecutionConfig) GetBookDepthBps() float64 {
+	if c.BookDepthBps <= 0 {
+		return 10
+	}
+	return c.BookDepthBps
+}
+
+func (c ExecutionConfig) GetChaseMaxWait() time.Duration {
+	return time.Duration(c.ChaseMaxWaitSeconds) * time.Second
+}
+
+func (c ExecutionConfig) GetChasePoll() time.Duration {
+	return time.Duration(c.ChasePollMS) * time.Millisecond
+}
+
+// PassiveEntry reports whether entries rest on the book and so pay the maker
+// fee: chased, or sent post-only with GTX.
+func (c ExecutionConfig) PassiveEntry() bool {
+	return c.EntryMode == "chase" || c.TimeInForce == "GTX"
 }
 
 type StealthConfig struct {
@@ -92,12 +290,122 @@ type AIConfig struct {
 	VisionMaxTokens   int     `yaml:"vision_max_tokens"`
 	VisionTemperature float64 `yaml:"vision_temperature"`
 	MaxImageSizeKB    int     `yaml:"max_image_size_kb"`
+	DecisionBudgetSec int     `yaml:"decision_budget_seconds"`
+
+	// DecisionPrompt is the live trading decision prompt template, empty
+	// for the built-in one. CandidatePrompt is a template under trial; it is
+	// only used by prompt-replay until promoted to DecisionPrompt.
+	DecisionPrompt  string `yaml:"decision_prompt"`
+	CandidatePrompt string `yaml:"candidate_prompt"`
+}
+
+// GetDecisionBudget returns how long a brain confirmation may take before
+// the cycle falls back to the rule-based score; zero leaves only the LLM
+// call timeout.
+func (c AIConfig) GetDecisionBudget() time.Duration {
+	return time.Duration(c.DecisionBudgetSec) * time.Second
 }
 
 type WatchlistConfig struct {
 	Symbols []string `yaml:"symbols"`
 }
 
+// UniversesConfig defines named symbol universes. When Active is empty the
+// screener keeps using the watchlist; otherwise only pairs in the active
+// universes are screened and the watchlist is ignored.
+type UniversesConfig struct {
+	Active      []string         `yaml:"active"`
+	Definitions []UniverseConfig `yaml:"definitions"`
+}
+
+// undefined returns the first active universe missing from Definitions.
+// Without definitions the screener defaults apply and any name is accepted.
+func (c UniversesConfig) undefined() (string, bool) {
+	if len(c.Definitions) == 0 {
+		return "", true
+	}
+	for _, name := range c.Active {
+		found := false
+		for _, d := range c.Definitions {
+			if strings.EqualFold(d.Name, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return name, false
+		}
+	}
+	return "", true
+}
+
+type UniverseConfig struct {
+	Name         string   `yaml:"name"`
+	Symbols      []string `yaml:"symbols"`
+	Patterns     []string `yaml:"patterns"`
+	Exclude      []string `yaml:"exclude"`
+	MinVolume24h float64  `yaml:"min_volume_24h_usd"`
+	MaxVolume24h float64  `yaml:"max_volume_24h_usd"`
+	MinMarketCap float64  `yaml:"min_market_cap_usd"`
+	MaxMarketCap float64  `yaml:"max_market_cap_usd"`
+	// NewListings restricts the universe to symbols the listing monitor
+	// reports as listed within listing.new_listing_hours.
+	NewListings bool `yaml:"new_listings"`
+}
+
+// SymbolMemoryConfig biases screening and entry thresholds by the bot's own
+// realized results and entry slippage per symbol, decaying with the given
+// half-life.
+type SymbolMemoryConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	HalfLifeHours float64 `yaml:"half_life_hours"`
+	MaxBoost      float64 `yaml:"max_score_boost"`
+	MaxPenalty    float64 `yaml:"max_confidence_penalty"`
+
+	SlippageAllowanceBps float64 `yaml:"slippage_allowance_bps"`
+	MaxSlippagePenalty   float64 `yaml:"max_slippage_penalty"`
+}
+
+// SymbolThrottleConfig holds back symbols whose realized edge after fees
+// has turned negative over their last window_trades trades: they need
+// confidence_step more confidence, and below suspend_bps are suspended for
+// cooldown_hours.
+type SymbolThrottleConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	WindowTrades   int     `yaml:"window_trades"`
+	MinTrades      int     `yaml:"min_trades"`
+	ConfidenceStep float64 `yaml:"confidence_step"`
+	SuspendBps     float64 `yaml:"suspend_bps"`
+	CooldownHours  float64 `yaml:"cooldown_hours"`
+}
+
+type AccountConfig struct {
+	PositionMode      string `yaml:"position_mode"`
+	MultiAssetsMargin bool   `yaml:"multi_assets_margin"`
+	MarginType        string `yaml:"margin_type"`
+	Leverage          int    `yaml:"leverage"`
+	MaxLeverage       int    `yaml:"max_leverage"`
+	FixOnStartup      bool   `yaml:"fix_on_startup"`
+}
+
+// HedgeMode reports whether the desired position mode is hedge (dual side).
+func (c AccountConfig) HedgeMode() bool {
+	return strings.EqualFold(c.PositionMode, "hedge")
+}
+
+// EarnConfig parks persistently idle futures margin in Binance flexible earn
+// and pulls it back as margin usage grows.
+type EarnConfig struct {
+	Enabled              bool    `yaml:"enabled"`
+	Asset                string  `yaml:"asset"`
+	KeepFreePercent      float64 `yaml:"keep_free_percent"`
+	MinFreeMarginUSD     float64 `yaml:"min_free_margin_usd"`
+	MinSweepUSD          float64 `yaml:"min_sweep_usd"`
+	IdleMinutes          int     `yaml:"idle_minutes"`
+	RecallBelowPercent   float64 `yaml:"recall_below_percent"`
+	CheckIntervalSeconds int     `yaml:"check_interval_seconds"`
+}
+
 type RiskConfig struct {
 	MaxAPIErrorsPerHour  int     `yaml:"max_api_errors_per_hour"`
 	MaxAPIErrorPercent   float64 `yaml:"max_api_error_percent"`
@@ -105,22 +413,304 @@ type RiskConfig struct {
 	DailyLossAlert       float64 `yaml:"daily_loss_alert"`
 	ConsecutiveLossAlert int     `yaml:"consecutive_loss_alert"`
 	PositionSizeAlert    float64 `yaml:"position_size_alert"`
+
+	// CorrelationBuckets groups symbols that move together for the risk
+	// heatmap and entry clustering; unlisted symbols fall in "other".
+	CorrelationBuckets map[string][]string `yaml:"correlation_buckets"`
+
+	// TargetMarginPercent, when set, scales new entries so the account's
+	// initial margin stays near that percent of equity: up to MarginScaleMax
+	// while below it, down to MarginScaleMin above it. The account is read
+	// every MarginPollSeconds.
+	TargetMarginPercent float64 `yaml:"target_margin_percent"`
+	MarginScaleMin      float64 `yaml:"margin_scale_min"`
+	MarginScaleMax      float64 `yaml:"margin_scale_max"`
+	MarginPollSeconds   int     `yaml:"margin_poll_seconds"`
+
+	// ProfitLockPercent, when set, arms an intraday profit lock once equity
+	// is that percent above where the UTC day opened. Giving back
+	// ProfitGivebackPercent of the day's peak gain then stops new entries
+	// until the next day and, with ProfitLockFlatten, closes open positions.
+	ProfitLockPercent     float64 `yaml:"profit_lock_percent"`
+	ProfitGivebackPercent float64 `yaml:"profit_giveback_percent"`
+	ProfitLockFlatten     bool    `yaml:"profit_lock_flatten"`
+	ProfitLockPollSeconds int     `yaml:"profit_lock_poll_seconds"`
+
+	// SpikeZScore, when set, throttles every entry account-wide for
+	// SpikeMinutes once a watched symbol's last 1m move reaches that
+	// z-score against the hour before it: leverage is capped at
+	// SpikeMaxLeverage and size scaled by SpikeSizeFactor.
+	SpikeZScore      float64 `yaml:"spike_zscore"`
+	SpikeMinutes     int     `yaml:"spike_minutes"`
+	SpikeMaxLeverage int     `yaml:"spike_max_leverage"`
+	SpikeSizeFactor  float64 `yaml:"spike_size_factor"`
+
+	// CascadeSymbolNotional and CascadeMarketNotional, when either is set,
+	// watch the market-wide liquidation stream. Once a symbol's (or the
+	// whole market's) liquidations over CascadeWindowSeconds reach that
+	// notional, entries in the symbol (or every symbol) pause for
+	// CascadeCooldownMinutes, and the stops of open positions caught in it
+	// move to CascadeStopFactor of their distance from price.
+	CascadeSymbolNotional  float64 `yaml:"cascade_symbol_notional"`
+	CascadeMarketNotional  float64 `yaml:"cascade_market_notional"`
+	CascadeWindowSeconds   int     `yaml:"cascade_window_seconds"`
+	CascadeCooldownMinutes int     `yaml:"cascade_cooldown_minutes"`
+	CascadeStopFactor      float64 `yaml:"cascade_stop_factor"`
 }
 
 type EmergencyConfig struct {
 	KillSwitchEnabled     bool   `yaml:"kill_switch_enabled"`
 	KillSwitchPassword    string `yaml:"kill_switch_password"`
 	KillSwitchFile        string `yaml:"kill_switch_file"`
+	SuspensionsDir        string `yaml:"suspensions_dir"`
 	EnableRecovery        bool   `yaml:"enable_recovery"`
 	RecoveryMode          string `yaml:"recovery_mode"`
 	MaxRecoveryAttempts   int    `yaml:"max_recovery_attempts"`
 	RecoveryCooldownHours int    `yaml:"recovery_cooldown_hours"`
+
+	// PauseOnForeignActivity watches the account stream and pauses entries
+	// when it shows orders, transfers or positions the bot did not cause,
+	// for ForeignPauseMinutes or, when zero, until /resume.
+	// IgnoreTransfers keeps transfers from counting, for accounts funded or
+	// swept while the bot runs.
+	PauseOnForeignActivity bool `yaml:"pause_on_foreign_activity"`
+	ForeignPauseMinutes    int  `yaml:"foreign_pause_minutes"`
+	IgnoreTransfers        bool `yaml:"ignore_transfers"`
+}
+
+// CalendarConfig pauses entries while the exchange is in maintenance: the
+// Windows listed here, maintenance Binance reports, and symbols whose
+// contract is not in TRADING status.
+type CalendarConfig struct {
+	Enabled      bool                `yaml:"enabled"`
+	PollSeconds  int                 `yaml:"poll_seconds"`
+	LeadMinutes  int                 `yaml:"lead_minutes"`
+	GraceMinutes int                 `yaml:"grace_minutes"`
+	Windows      []MaintenanceWindow `yaml:"windows"`
+}
+
+// MaintenanceWindow is a scheduled maintenance period with RFC 3339 bounds.
+// Without Symbols it closes the whole exchange.
+type MaintenanceWindow struct {
+	Start   string   `yaml:"start"`
+	End     string   `yaml:"end"`
+	Symbols []string `yaml:"symbols"`
+	Reason  string   `yaml:"reason"`
+}
+
+// Bounds parses the window's start and end.
+func (w MaintenanceWindow) Bounds() (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse(time.RFC3339, w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end %s is not after start %s", w.End, w.Start)
+	}
+	return start, end, nil
+}
+
+// KeyHealthConfig checks the API key every IntervalSeconds and stops
+// entries while Binance rejects it: revoked, without futures permission or
+// called from outside its IP whitelist. ExpiryWarningDays warns in the alert
+// chat before the key's trading authority expires.
+type KeyHealthConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	IntervalSeconds   int  `yaml:"interval_seconds"`
+	ExpiryWarningDays int  `yaml:"expiry_warning_days"`
+}
+
+// DriftConfig checks every IntervalSeconds that the account still matches
+// the config: the watchlist at account.leverage, no position above
+// account.max_leverage, at most MaxPositions open, and, with RequireStops, a
+// stop order on the exchange behind every position. Drift is alerted with
+// its fix; nothing is changed.
+type DriftConfig struct {
+	Enabled         bool `yaml:"enabled"`
+	IntervalSeconds int  `yaml:"interval_seconds"`
+	MaxPositions    int  `yaml:"max_positions"`
+	RequireStops    bool `yaml:"require_stops"`
+}
+
+// ListingConfig diffs exchangeInfo every IntervalSeconds for new listings
+// and delistings. Delisted symbols, and those whose delivery date is pulled
+// within DelistingHorizonDays, are dropped from the screener; with
+// FlattenOnDelist their open positions are closed at market. Symbols listed
+// within NewListingHours are reported as new.
+type ListingConfig struct {
+	Enabled              bool    `yaml:"enabled"`
+	IntervalSeconds      int     `yaml:"interval_seconds"`
+	NewListingHours      float64 `yaml:"new_listing_hours"`
+	DelistingHorizonDays float64 `yaml:"delisting_horizon_days"`
+	FlattenOnDelist      bool    `yaml:"flatten_on_delist"`
+}
+
+// FeesConfig reads the account's fee tier and commission rates at startup
+// and every RefreshMinutes, so the R:R and EV gates, the cost model and the
+// symbol throttle charge what the account actually pays rather than
+// trading.taker_fee_rate and maker_fee_rate. A change of tier is alerted.
+type FeesConfig struct {
+	Enabled        bool `yaml:"enabled"`
+	RefreshMinutes int  `yaml:"refresh_minutes"`
+}
+
+// IdeasConfig serves an inbox at /ideas where people or outside systems
+// submit a symbol with a thesis. For WindowHours the symbol is screened and
+// analyzed ahead of the rest, and trades entered on it are reported apart.
+// Requests must carry "Authorization: Bearer <token>"; IDEAS_TOKEN
+// overrides Token.
+type IdeasConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	Token       string  `yaml:"token"`
+	WindowHours float64 `yaml:"window_hours"`
+	MaxActive   int     `yaml:"max_active"`
+}
+
+// ScreenerConfig limits the request weight market scans spend. TickerStream
+// reads 24h statistics from the all-market mini ticker stream, with a full
+// REST scan every FullScanMinutes (default 30) and at most PageSize (default
+// 20) symbols the stream has not reported fetched one by one per scan.
+// Periodic scans wait while more than BackoffAbove (default 0.7) of the
+// weight limit is used, for up to MaxDeferMinutes (default 15).
+type ScreenerConfig struct {
+	TickerStream    bool    `yaml:"ticker_stream"`
+	FullScanMinutes int     `yaml:"full_scan_minutes"`
+	PageSize        int     `yaml:"page_size"`
+	BackoffAbove    float64 `yaml:"backoff_above"`
+	MaxDeferMinutes int     `yaml:"max_defer_minutes"`
+}
+
+// ResearchConfig serves the kline cache, with the live indicators on every
+// candle, and the trade and decision journals read-only at Addr, which must
+// be a loopback address, for research notebooks.
+type ResearchConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"`
+}
+
+// ProtectionConfig keeps a close-position stop and take profit on the
+// exchange behind every open position. Orders lost to a cancel or expiry
+// are placed again at once, every ReconcileSeconds the open orders are
+// checked against the positions, and a position left without either order
+// for AlertAfterSeconds is alerted.
+type ProtectionConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	ReconcileSeconds  int  `yaml:"reconcile_seconds"`
+	AlertAfterSeconds int  `yaml:"alert_after_seconds"`
+}
+
+// EntryLimitsConfig caps new positions per rolling window, per session.
+// Hours no session covers allow MaxEntries per WindowMinutes, or any number
+// when MaxEntries is zero.
+type EntryLimitsConfig struct {
+	Enabled       bool           `yaml:"enabled"`
+	MaxEntries    int            `yaml:"max_entries"`
+	WindowMinutes int            `yaml:"window_minutes"`
+	Sessions      []EntrySession `yaml:"sessions"`
+}
+
+// EntrySession is a daily UTC range, "HH:MM" to "HH:MM", that allows
+// MaxEntries new positions per WindowMinutes. An end before the start runs
+// past midnight.
+type EntrySession struct {
+	Name          string `yaml:"name"`
+	Start         string `yaml:"start"`
+	End           string `yaml:"end"`
+	MaxEntries    int    `yaml:"max_entries"`
+	WindowMinutes int    `yaml:"window_minutes"`
+}
+
+// Hours parses the session's start and end as offsets from midnight.
+func (s EntrySession) Hours() (time.Duration, time.Duration, error) {
+	start, err := time.Parse("15:04", s.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := time.Parse("15:04", s.End)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start.Equal(end) {
+		return 0, 0, fmt.Errorf("start and end are both %s", s.Start)
+	}
+	midnight := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Sub(midnight), end.Sub(midnight), nil
+}
+
+// PreflightConfig replays the trading config over the last Days of candles
+// before the engine starts and holds it back when the simulated drawdown
+// exceeds MaxDrawdownPct. OnBreach is "refuse" to exit or "confirm" to ask
+// on the terminal.
+type PreflightConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	Days           int     `yaml:"days"`
+	MaxDrawdownPct float64 `yaml:"max_drawdown_percent"`
+	OnBreach       string  `yaml:"on_breach"`
+}
+
+// GetDays returns how much history is replayed, 7 days by default.
+func (c PreflightConfig) GetDays() int {
+	if c.Days <= 0 {
+		return 7
+	}
+	return c.Days
+}
+
+// RiskModesConfig offers the conservative, moderate, aggressive and high
+// risk profiles for switching from the alert chat with /risk. Initial is the
+// profile applied at start, and Profiles overrides the built-in values of a
+// profile or adds one. A switch waits ConfirmSeconds for confirmation.
+type RiskModesConfig struct {
+	Enabled        bool                   `yaml:"enabled"`
+	Initial        string                 `yaml:"initial"`
+	ConfirmSeconds int                    `yaml:"confirm_seconds"`
+	Profiles       map[string]RiskProfile `yaml:"profiles"`
+}
+
+// RiskProfile is one risk mode's bundle; zero fields keep the built-in value.
+type RiskProfile struct {
+	MinConfidence   float64 `yaml:"min_confidence"`
+	RiskPerTrade    float64 `yaml:"risk_per_trade"`
+	MaxLeverage     int     `yaml:"max_leverage"`
+	MaxTradesPerDay int     `yaml:"max_trades_per_day"`
+	MinVolume24hUSD float64 `yaml:"min_volume_24h_usd"`
+	MaxPairs        int     `yaml:"max_pairs"`
+}
+
+// SentimentConfig scores CryptoPanic headlines on the screened symbols with
+// the brain's LLM, BatchSize symbols per call and at most MaxCallsPerHour
+// calls. Scores are kept for an hour and scale the screener rank by up to
+// Weight either way.
+type SentimentConfig struct {
+	Enabled          bool    `yaml:"enabled"`
+	CryptoPanicToken string  `yaml:"cryptopanic_token"`
+	IntervalMinutes  int     `yaml:"interval_minutes"`
+	BatchSize        int     `yaml:"batch_size"`
+	MaxHeadlines     int     `yaml:"max_headlines"`
+	MaxCallsPerHour  int     `yaml:"max_calls_per_hour"`
+	Weight           float64 `yaml:"weight"`
+}
+
+// GetWeight returns how far sentiment moves the screener rank, 0.1 by default.
+func (c SentimentConfig) GetWeight() float64 {
+	if c.Weight <= 0 {
+		return 0.1
+	}
+	return c.Weight
 }
 
 type MonitoringConfig struct {
 	TelegramEnabled     bool   `yaml:"telegram_enabled"`
 	TelegramToken       string `yaml:"telegram_token"`
 	TelegramChatID      string `yaml:"telegram_chat_id"`
+	TelegramDigestSecs  int    `yaml:"telegram_digest_seconds"`
+	TelegramDedupSecs   int    `yaml:"telegram_dedup_seconds"`
+	Locale              string `yaml:"locale"`
+	TemplatesDir        string `yaml:"templates_dir"`
 	AlertOnTrade        bool   `yaml:"alert_on_trade"`
 	AlertOnPNLMilestone bool   `yaml:"alert_on_pnl_milestone"`
 	AlertOnRiskBreach   bool   `yaml:"alert_on_risk_breach"`
@@ -128,15 +718,140 @@ type MonitoringConfig struct {
 	AuditLogEnabled     bool   `yaml:"audit_log_enabled"`
 	AuditLogPath        string `yaml:"audit_log_path"`
 	TradeLogPath        string `yaml:"trade_log_path"`
+	DecisionLogPath     string `yaml:"decision_log_path"`
+	ConfigLogPath       string `yaml:"config_log_path"`
+	EquityLogPath       string `yaml:"equity_log_path"`
+	EquitySnapshotSecs  int    `yaml:"equity_snapshot_seconds"`
+	BookArchivePath     string `yaml:"book_archive_path"`
+	BookArchiveDepth    int    `yaml:"book_archive_depth"`
+	BookArchiveAfter    []int  `yaml:"book_archive_after_seconds"`
 	DetailedTradeLog    bool   `yaml:"detailed_trade_log"`
 	LogLevel            string `yaml:"log_level"`
+
+	// AuditSigningKey hash-chains and HMAC-signs every audit and trade log
+	// record, bound to AuditEnvironment so a testnet log cannot pass for a
+	// mainnet one. "gobot audit-verify" checks the chains.
+	AuditSigningKey  string `yaml:"audit_signing_key"`
+	AuditEnvironment string `yaml:"audit_environment"`
+
+	// VerifyTradeAlerts reads back the order of every trade executed alert
+	// and alerts again if it did not fill as announced within
+	// VerifyAlertTimeoutSecs.
+	VerifyTradeAlerts      bool `yaml:"verify_trade_alerts"`
+	VerifyAlertTimeoutSecs int  `yaml:"verify_alert_timeout_seconds"`
+
+	// GraphQLEnabled serves /graphql on the engine's health port for
+	// queries over trades, positions, signals and metrics. Requests must
+	// carry emergency.kill_switch_password as X-Kill-Switch-Password.
+	GraphQLEnabled bool `yaml:"graphql_enabled"`
 }
 
+// StateConfig persists the trading state to state_file by default, or to
+// Redis or an S3-compatible bucket. Remote backends are journaled in
+// state_dir, so a save the backend missed is replayed on the next start.
 type StateConfig struct {
 	PersistenceEnabled  bool   `yaml:"persistence_enabled"`
 	StateDir            string `yaml:"state_dir"`
 	StateFile           string `yaml:"state_file"`
 	SaveIntervalSeconds int    `yaml:"save_interval_seconds"`
+
+	Backend       string        `yaml:"backend"` // file, redis or s3
+	RedisAddr     string        `yaml:"redis_addr"`
+	RedisPassword string        `yaml:"redis_password"`
+	RedisKey      string        `yaml:"redis_key"`
+	S3            StateS3Config `yaml:"s3"`
+}
+
+type StateS3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	Key       string `yaml:"key"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+}
+
+// FailoverConfig runs this instance as one of a primary/standby pair. Both
+// instances contend for a lease; the holder trades and publishes its state,
+// the other syncs that state and takes over when the lease lapses.
+type FailoverConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	InstanceID       string `yaml:"instance_id"`
+	Backend          string `yaml:"backend"` // file or redis
+	LockPath         string `yaml:"lock_path"`
+	RedisAddr        string `yaml:"redis_addr"`
+	RedisPassword    string `yaml:"redis_password"`
+	LeaseSeconds     int    `yaml:"lease_seconds"`
+	HeartbeatSeconds int    `yaml:"heartbeat_seconds"`
+}
+
+// InstanceLockConfig keeps a second bot from trading the same account. The
+// lock is keyed by a hash of the API key, so bots on other accounts do not
+// contend for it. It is skipped when failover is enabled, since the failover
+// lease already lets only one instance trade.
+type InstanceLockConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Backend       string `yaml:"backend"`  // file or redis
+	LockDir       string `yaml:"lock_dir"` // state.state_dir when empty
+	RedisAddr     string `yaml:"redis_addr"`
+	RedisPassword string `yaml:"redis_password"`
+	LeaseSeconds  int    `yaml:"lease_seconds"`
+}
+
+// EventsConfig streams screener refreshes, decisions and executions to a
+// broker for external consumers. Subjects are <prefix>.<type>.v<version>;
+// on Redis each subject is a stream.
+type EventsConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Backend  string `yaml:"backend"` // redis or nats
+	Addr     string `yaml:"addr"`
+	Password string `yaml:"password"`
+	Token    string `yaml:"token"` // nats only
+	Prefix   string `yaml:"prefix"`
+	MaxLen   int64  `yaml:"max_len"` // redis only
+	Buffer   int    `yaml:"buffer"`
+}
+
+// StrategiesConfig points at a directory of compiled strategy modules
+// (Go plugins) loaded into the platform at startup. Empty loads none.
+// Allocation splits a notional budget between signal sources.
+type StrategiesConfig struct {
+	PluginDir  string           `yaml:"plugin_dir"`
+	Allocation AllocationConfig `yaml:"allocation"`
+}
+
+// AllocationConfig caps the open notional of each signal source (brain,
+// striker, webhook, ...) at its weight's share of Budget. Sources without a
+// weight cannot enter while it is on. A zero Budget turns allocation off.
+type AllocationConfig struct {
+	Budget           float64            `yaml:"budget"`
+	Method           string             `yaml:"method"` // fixed, volatility_parity or performance
+	Weights          map[string]float64 `yaml:"weights"`
+	MinWeight        float64            `yaml:"min_weight"`
+	RebalanceMinutes int                `yaml:"rebalance_minutes"`
+}
+
+// RegimeConfig rotates platform strategies with the regime of Symbol on
+// Interval candles. Rules maps trend, chop and squeeze to the strategy names
+// each turns on and off; empty uses the built-in rules. A new regime must be
+// read on ConfirmChecks checks in a row, at least MinDwellMinutes after the
+// last switch.
+type RegimeConfig struct {
+	Enabled              bool                  `yaml:"enabled"`
+	Symbol               string                `yaml:"symbol"`
+	Interval             string                `yaml:"interval"`
+	Length               int                   `yaml:"length"`
+	TrendEfficiency      float64               `yaml:"trend_efficiency"`
+	ChopEfficiency       float64               `yaml:"chop_efficiency"`
+	ConfirmChecks        int                   `yaml:"confirm_checks"`
+	MinDwellMinutes      int                   `yaml:"min_dwell_minutes"`
+	CheckIntervalSeconds int                   `yaml:"check_interval_seconds"`
+	Rules                map[string]RegimeRule `yaml:"rules"`
+}
+
+type RegimeRule struct {
+	Enable  []string `yaml:"enable"`
+	Disable []string `yaml:"disable"`
 }
 
 type PerformanceConfig struct {
@@ -145,6 +860,18 @@ type PerformanceConfig struct {
 	CacheKlinesMinutes    int `yaml:"cache_klines_minutes"`
 	CachePriceSeconds     int `yaml:"cache_price_seconds"`
 	MaxConcurrentRequests int `yaml:"max_concurrent_requests"`
+
+	// AnalysisWorkers is how many watchlist symbols are analyzed at once
+	// each trading cycle. Default 1, one after another.
+	AnalysisWorkers int `yaml:"analysis_workers"`
+
+	// LatencyBudgetMS caps each trading cycle phase (analysis, entry); a
+	// phase that overruns aborts the rest of the cycle.
+	LatencyBudgetMS map[string]int `yaml:"latency_budget_ms"`
+
+	// CallTimeoutMS bounds each external call by type (order, klines, llm,
+	// account, exchange, reconcile); unset types keep their default.
+	CallTimeoutMS map[string]int `yaml:"call_timeout_ms"`
 }
 
 type TradingViewConfig struct {
@@ -183,6 +910,12 @@ func LoadProductionConfig(ctx context.Context, configPath string) (*ProductionCo
 
 	cfg = cfg.applyEnvironmentOverrides()
 
+	if cfg.Profile != "" {
+		if err := cfg.ApplyProfile(cfg.Profile); err != nil {
+			return nil, fmt.Errorf("config validation failed: %w", err)
+		}
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
@@ -206,12 +939,48 @@ func (c ProductionConfig) applyEnvironmentOverrides() ProductionConfig {
 	if tgChat := os.Getenv("TELEGRAM_CHAT_ID"); tgChat != "" {
 		c.Monitoring.TelegramChatID = tgChat
 	}
+	if locale := os.Getenv("ALERT_LOCALE"); locale != "" {
+		c.Monitoring.Locale = locale
+	}
 	if useTestnet := os.Getenv("BINANCE_USE_TESTNET"); useTestnet == "true" {
 		c.Binance.UseTestnet = true
 	}
 	if killSwitch := os.Getenv("KILL_SWITCH_PASSWORD"); killSwitch != "" {
 		c.Emergency.KillSwitchPassword = killSwitch
 	}
+	if hlKey := os.Getenv("HYPERLIQUID_PRIVATE_KEY"); hlKey != "" {
+		c.Exchange.Hyperliquid.PrivateKey = hlKey
+	}
+	if instanceID := os.Getenv("GOBOT_INSTANCE_ID"); instanceID != "" {
+		c.Failover.InstanceID = instanceID
+	}
+	if profile := os.Getenv("GOBOT_PROFILE"); profile != "" {
+		c.Profile = profile
+	}
+	if ns := os.Getenv("GOBOT_NAMESPACE"); ns != "" {
+		c.Namespace = ns
+	}
+	if key := os.Getenv("AUDIT_SIGNING_KEY"); key != "" {
+		c.Monitoring.AuditSigningKey = key
+	}
+	if token := os.Getenv("CRYPTOPANIC_TOKEN"); token != "" {
+		c.Sentiment.CryptoPanicToken = token
+	}
+	if token := os.Getenv("IDEAS_TOKEN"); token != "" {
+		c.Ideas.Token = token
+	}
+	if addr := os.Getenv("EVENTS_ADDR"); addr != "" {
+		c.Events.Addr = addr
+	}
+	if password := os.Getenv("EVENTS_PASSWORD"); password != "" {
+		c.Events.Password = password
+	}
+	if accessKey := os.Getenv("STATE_S3_ACCESS_KEY"); accessKey != "" {
+		c.State.S3.AccessKey = accessKey
+	}
+	if secretKey := os.Getenv("STATE_S3_SECRET_KEY"); secretKey != "" {
+		c.State.S3.SecretKey = secretKey
+	}
 	return c
 }
 
@@ -253,6 +1022,220 @@ func (c ProductionConfig) Validate() error {
 	if c.Emergency.KillSwitchPassword == "" {
 		errors = append(errors, "emergency.kill_switch_password must be set")
 	}
+	switch c.State.Backend {
+	case "", "file", "redis":
+	case "s3":
+		if c.State.S3.Endpoint == "" || c.State.S3.Bucket == "" {
+			errors = append(errors, "state.s3.endpoint and state.s3.bucket must be set for state.backend s3")
+		}
+	default:
+		errors = append(errors, "state.backend must be file, redis or s3")
+	}
+	for component, share := range c.Binance.WeightQuotas {
+		if share < 0 || share > 1 {
+			errors = append(errors, fmt.Sprintf("binance.weight_quotas.%s must be between 0 and 1", component))
+		}
+	}
+	if c.Binance.WeightReserve < 0 || c.Binance.WeightReserve >= 1 {
+		errors = append(errors, "binance.weight_reserve must be at least 0 and below 1")
+	}
+	if c.Preflight.Enabled && c.Preflight.MaxDrawdownPct <= 0 {
+		errors = append(errors, "preflight.max_drawdown_percent must be positive")
+	}
+	if b := c.Preflight.OnBreach; b != "" && b != "refuse" && b != "confirm" {
+		errors = append(errors, "preflight.on_breach must be refuse or confirm")
+	}
+	if c.Sentiment.Enabled && c.Sentiment.CryptoPanicToken == "" {
+		errors = append(errors, "sentiment.cryptopanic_token is required when sentiment is enabled")
+	}
+	if c.Sentiment.Weight < 0 || c.Sentiment.Weight > 0.5 {
+		errors = append(errors, "sentiment.weight must be between 0 and 0.5")
+	}
+	if c.Risk.TargetMarginPercent < 0 || c.Risk.TargetMarginPercent > 100 {
+		errors = append(errors, "risk.target_margin_percent must be between 0 and 100")
+	}
+	if c.Risk.MarginScaleMin < 0 || c.Risk.MarginScaleMin > 1 {
+		errors = append(errors, "risk.margin_scale_min must be between 0 and 1")
+	}
+	if c.Risk.MarginScaleMax != 0 && c.Risk.MarginScaleMax < 1 {
+		errors = append(errors, "risk.margin_scale_max must be at least 1")
+	}
+	if c.Risk.ProfitLockPercent < 0 {
+		errors = append(errors, "risk.profit_lock_percent must not be negative")
+	}
+	if c.Risk.ProfitGivebackPercent < 0 || c.Risk.ProfitGivebackPercent >= 100 {
+		errors = append(errors, "risk.profit_giveback_percent must be between 0 and 100")
+	}
+	if c.Risk.SpikeZScore < 0 {
+		errors = append(errors, "risk.spike_zscore must not be negative")
+	}
+	if c.Risk.SpikeSizeFactor < 0 || c.Risk.SpikeSizeFactor > 1 {
+		errors = append(errors, "risk.spike_size_factor must be between 0 and 1")
+	}
+	if c.Risk.CascadeSymbolNotional < 0 || c.Risk.CascadeMarketNotional < 0 {
+		errors = append(errors, "risk.cascade_symbol_notional and cascade_market_notional must not be negative")
+	}
+	if c.Risk.CascadeStopFactor < 0 || c.Risk.CascadeStopFactor > 1 {
+		errors = append(errors, "risk.cascade_stop_factor must be between 0 and 1")
+	}
+	for name, p := range c.RiskModes.Profiles {
+		if p.MinConfidence < 0 || p.MinConfidence > 1 {
+			errors = append(errors, fmt.Sprintf("risk_modes.profiles.%s.min_confidence must be between 0 and 1", name))
+		}
+		if p.RiskPerTrade < 0 || p.RiskPerTrade > 0.1 {
+			errors = append(errors, fmt.Sprintf("risk_modes.profiles.%s.risk_per_trade must be between 0 and 0.1", name))
+		}
+		if p.MaxLeverage < 0 || p.MaxLeverage > 125 {
+			errors = append(errors, fmt.Sprintf("risk_modes.profiles.%s.max_leverage must be between 0 and 125", name))
+		}
+	}
+	for i, w := range c.Calendar.Windows {
+		if _, _, err := w.Bounds(); err != nil {
+			errors = append(errors, fmt.Sprintf("calendar.windows[%d]: %v", i, err))
+		}
+	}
+	for i, s := range c.EntryLimits.Sessions {
+		if _, _, err := s.Hours(); err != nil {
+			errors = append(errors, fmt.Sprintf("entry_limits.sessions[%d]: %v", i, err))
+		}
+		if s.MaxEntries < 0 || s.WindowMinutes < 0 {
+			errors = append(errors, fmt.Sprintf("entry_limits.sessions[%d]: max_entries and window_minutes must not be negative", i))
+		}
+	}
+	if c.KeyHealth.IntervalSeconds < 0 || c.KeyHealth.ExpiryWarningDays < 0 {
+		errors = append(errors, "key_health.interval_seconds and expiry_warning_days must not be negative")
+	}
+	if c.Drift.IntervalSeconds < 0 || c.Drift.MaxPositions < 0 {
+		errors = append(errors, "drift.interval_seconds and max_positions must not be negative")
+	}
+	if c.Listing.IntervalSeconds < 0 || c.Listing.NewListingHours < 0 || c.Listing.DelistingHorizonDays < 0 {
+		errors = append(errors, "listing.interval_seconds, new_listing_hours and delisting_horizon_days must not be negative")
+	}
+	if c.Ideas.Enabled && c.Ideas.Token == "" {
+		errors = append(errors, "ideas.token (or IDEAS_TOKEN) is required when ideas.enabled is on")
+	}
+	if c.Ideas.WindowHours < 0 || c.Ideas.MaxActive < 0 {
+		errors = append(errors, "ideas.window_hours and max_active must not be negative")
+	}
+	if c.Screener.FullScanMinutes < 0 || c.Screener.PageSize < 0 || c.Screener.MaxDeferMinutes < 0 {
+		errors = append(errors, "screener.full_scan_minutes, page_size and max_defer_minutes must not be negative")
+	}
+	if c.Screener.BackoffAbove < 0 || c.Screener.BackoffAbove > 1 {
+		errors = append(errors, "screener.backoff_above must be between 0 and 1")
+	}
+	if c.Fees.RefreshMinutes < 0 {
+		errors = append(errors, "fees.refresh_minutes must not be negative")
+	}
+	if c.Protection.ReconcileSeconds < 0 || c.Protection.AlertAfterSeconds < 0 {
+		errors = append(errors, "protection.reconcile_seconds and alert_after_seconds must not be negative")
+	}
+	if c.Emergency.ForeignPauseMinutes < 0 {
+		errors = append(errors, "emergency.foreign_pause_minutes must not be negative")
+	}
+	if name, ok := c.Universes.undefined(); !ok {
+		errors = append(errors, fmt.Sprintf("universes.active references undefined universe %q", name))
+	}
+	if c.Exchange.Venue == "hyperliquid" && c.Exchange.Hyperliquid.PrivateKey == "" {
+		errors = append(errors, "HYPERLIQUID_PRIVATE_KEY must be set for exchange.venue hyperliquid")
+	}
+	if c.Events.Enabled && c.Events.Backend != "redis" && c.Events.Backend != "nats" {
+		errors = append(errors, "events.backend must be redis or nats")
+	}
+	if c.Failover.Enabled && c.Failover.Backend != "file" && c.Failover.Backend != "redis" {
+		errors = append(errors, "failover.backend must be file or redis")
+	}
+	if !namespacePattern.MatchString(c.Namespace) {
+		errors = append(errors, "namespace may only contain letters, digits, '.', '_' and '-'")
+	}
+	if t := c.SymbolThrottle; t.Enabled {
+		if t.WindowTrades < 0 || t.MinTrades < 0 || (t.WindowTrades > 0 && t.MinTrades > t.WindowTrades) {
+			errors = append(errors, "symbol_throttle.min_trades must be between 0 and window_trades")
+		}
+		if t.ConfidenceStep < 0 || t.ConfidenceStep > 0.5 {
+			errors = append(errors, "symbol_throttle.confidence_step must be between 0 and 0.5")
+		}
+		if t.SuspendBps > 0 {
+			errors = append(errors, "symbol_throttle.suspend_bps must not be positive")
+		}
+		if t.CooldownHours < 0 {
+			errors = append(errors, "symbol_throttle.cooldown_hours must not be negative")
+		}
+	}
+	if b := c.InstanceLock.Backend; c.InstanceLock.Enabled && b != "" && b != "file" && b != "redis" {
+		errors = append(errors, "instance_lock.backend must be file or redis")
+	}
+	if c.InstanceLock.LeaseSeconds < 0 {
+		errors = append(errors, "instance_lock.lease_seconds must not be negative")
+	}
+	if m := c.Execution.EntryMode; m != "" && m != "market" && m != "chase" {
+		errors = append(errors, "execution.entry_mode must be market or chase")
+	}
+	if m := c.Execution.ChaseRemainder; m != "" && m != "cancel" && m != "market" && m != "chase" {
+		errors = append(errors, "execution.chase_remainder must be cancel, market or chase")
+	}
+	if tif := c.Execution.TimeInForce; tif != "" {
+		if tif != "IOC" && tif != "FOK" && tif != "GTX" {
+			errors = append(errors, "execution.time_in_force must be IOC, FOK or GTX")
+		}
+		if c.Execution.EntryMode == "chase" {
+			errors = append(errors, "execution.time_in_force cannot be used with entry_mode chase")
+		}
+	}
+	if c.Execution.AckTimeoutSecs < 0 {
+		errors = append(errors, "execution.ack_timeout_seconds must not be negative")
+	}
+	if c.Execution.ShadowSlippageBps < 0 {
+		errors = append(errors, "execution.shadow_slippage_bps must not be negative")
+	}
+	if m := c.Trading.StopMode; m != "" && m != "percent" && m != "atr" {
+		errors = append(errors, "trading.stop_mode must be percent or atr")
+	}
+	if s := c.Trading.LevelsMinStrength; s < 0 || s > 1 {
+		errors = append(errors, "trading.levels_min_strength must be between 0 and 1")
+	}
+	if w := c.Trading.SqueezeWeight; w < 0 || w > 1 {
+		errors = append(errors, "trading.squeeze_weight must be between 0 and 1")
+	}
+	for key, text := range map[string]string{"ai.decision_prompt": c.AI.DecisionPrompt, "ai.candidate_prompt": c.AI.CandidatePrompt} {
+		if _, err := template.New(key).Parse(text); err != nil {
+			errors = append(errors, fmt.Sprintf("%s is not a valid template: %v", key, err))
+		}
+	}
+	switch c.Monitoring.BookArchiveDepth {
+	case 0, 5, 10, 20, 50, 100, 500, 1000:
+	default:
+		errors = append(errors, "monitoring.book_archive_depth must be 5, 10, 20, 50, 100, 500 or 1000")
+	}
+	if c.Execution.MinBookDepthUSD > 0 && !c.Execution.LocalBooks {
+		errors = append(errors, "execution.min_book_depth_usd needs execution.local_books")
+	}
+	if c.Account.MaxLeverage < 0 || c.Account.MaxLeverage > 125 {
+		errors = append(errors, "account.max_leverage must be between 0 and 125")
+	}
+	if c.Account.MaxLeverage > 0 && c.Account.Leverage > c.Account.MaxLeverage {
+		errors = append(errors, "account.leverage must not exceed account.max_leverage")
+	}
+	for name := range c.Regime.Rules {
+		if name != "trend" && name != "chop" && name != "squeeze" {
+			errors = append(errors, fmt.Sprintf("regime.rules.%s: regimes are trend, chop and squeeze", name))
+		}
+	}
+	if c.Regime.ChopEfficiency > 0 && c.Regime.TrendEfficiency > 0 && c.Regime.ChopEfficiency >= c.Regime.TrendEfficiency {
+		errors = append(errors, "regime.chop_efficiency must be below regime.trend_efficiency")
+	}
+	if d := c.Execution.MaxEntryDrift; d < 0 || d > 1 {
+		errors = append(errors, "execution.max_entry_drift must be between 0 and 1")
+	}
+	if a := c.Strategies.Allocation; a.Budget > 0 {
+		switch a.Method {
+		case "", "fixed", "volatility_parity", "performance":
+		default:
+			errors = append(errors, "strategies.allocation.method must be fixed, volatility_parity or performance")
+		}
+		if len(a.Weights) == 0 {
+			errors = append(errors, "strategies.allocation.weights must name at least one source when a budget is set")
+		}
+	}
 
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors: %s", strings.Join(errors, "; "))
@@ -276,6 +1259,18 @@ func (c ProductionConfig) GetAuditLogPath() string {
 	return c.Monitoring.AuditLogPath
 }
 
+// GetAuditEnvironment returns the environment audit records are signed for,
+// testnet or mainnet by default.
+func (c ProductionConfig) GetAuditEnvironment() string {
+	if c.Monitoring.AuditEnvironment != "" {
+		return c.Monitoring.AuditEnvironment
+	}
+	if c.Binance.UseTestnet {
+		return "testnet"
+	}
+	return "mainnet"
+}
+
 func (c ProductionConfig) GetScreenshotDir() string {
 	return c.TradingView.ScreenshotDir
 }
@@ -300,6 +1295,58 @@ func (c TradingConfig) GetTradingInterval() time.Duration {
 	return time.Duration(c.TradingIntervalMin) * time.Minute
 }
 
+// GetMinRiskReward returns the minimum net reward/risk for an entry, 1.5R by default.
+func (c TradingConfig) GetMinRiskReward() float64 {
+	if c.MinRiskRewardRatio <= 0 {
+		return 1.5
+	}
+	return c.MinRiskRewardRatio
+}
+
+// GetMaxDataAge returns how old ticker and kline data may be before a symbol
+// is skipped, two minutes by default.
+func (c TradingConfig) GetMaxDataAge() time.Duration {
+	if c.MaxDataAgeSeconds <= 0 {
+		return 2 * time.Minute
+	}
+	return time.Duration(c.MaxDataAgeSeconds) * time.Second
+}
+
+// GetScoreHalfLife returns how fast screener scores lose priority as they
+// age. Zero disables decay.
+func (c TradingConfig) GetScoreHalfLife() time.Duration {
+	if c.ScoreHalfLifeSecs <= 0 {
+		return 0
+	}
+	return time.Duration(c.ScoreHalfLifeSecs) * time.Second
+}
+
+// GetSignalFreshness returns how old a score may be before it is refused as
+// an entry signal. Zero disables the check.
+func (c TradingConfig) GetSignalFreshness() time.Duration {
+	if c.SignalFreshnessSecs <= 0 {
+		return 0
+	}
+	return time.Duration(c.SignalFreshnessSecs) * time.Second
+}
+
+// GetTakerFeeRate returns the per-side fee used for R:R checks, 0.04% by default.
+func (c TradingConfig) GetTakerFeeRate() float64 {
+	if c.TakerFeeRate <= 0 {
+		return 0.0004
+	}
+	return c.TakerFeeRate
+}
+
+// GetMakerFeeRate returns the fee for entries resting on the book, 0.02% by
+// default.
+func (c TradingConfig) GetMakerFeeRate() float64 {
+	if c.MakerFeeRate <= 0 {
+		return 0.0002
+	}
+	return c.MakerFeeRate
+}
+
 func (c TradingConfig) GetSymbolCooldown() time.Duration {
 	return time.Duration(c.SymbolCooldownMin) * time.Minute
 }
@@ -312,10 +1359,33 @@ func (c CircuitBreakerConfig) GetRecoveryTimeout() time.Duration {
 	return time.Duration(c.RecoveryTimeoutSecs) * time.Second
 }
 
+func (c MonitoringConfig) GetTelegramDigestWindow() time.Duration {
+	return time.Duration(c.TelegramDigestSecs) * time.Second
+}
+
+func (c MonitoringConfig) GetTelegramDedupWindow() time.Duration {
+	return time.Duration(c.TelegramDedupSecs) * time.Second
+}
+
 func (c StateConfig) GetSaveInterval() time.Duration {
 	return time.Duration(c.SaveIntervalSeconds) * time.Second
 }
 
+func (c FailoverConfig) GetLeaseTTL() time.Duration {
+	return time.Duration(c.LeaseSeconds) * time.Second
+}
+
+func (c FailoverConfig) GetHeartbeat() time.Duration {
+	return time.Duration(c.HeartbeatSeconds) * time.Second
+}
+
+func (c InstanceLockConfig) GetLeaseTTL() time.Duration {
+	if c.LeaseSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.LeaseSeconds) * time.Second
+}
+
 func (c PerformanceConfig) GetRestartInterval() time.Duration {
 	return time.Duration(c.RestartIntervalHours) * time.Hour
 }
@@ -327,3 +1397,11 @@ func (c PerformanceConfig) GetCacheKlinesDuration() time.Duration {
 func (c PerformanceConfig) GetCachePriceDuration() time.Duration {
 	return time.Duration(c.CachePriceSeconds) * time.Second
 }
+
+// GetAnalysisWorkers returns how many symbols are analyzed at once, at least 1.
+func (c PerformanceConfig) GetAnalysisWorkers() int {
+	if c.AnalysisWorkers < 1 {
+		return 1
+	}
+	return c.AnalysisWorkers
+}
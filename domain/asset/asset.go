@@ -2,6 +2,8 @@ package asset
 
 import (
 	"context"
+	"math"
+	"sort"
 	"time"
 )
 
@@ -67,6 +69,39 @@ func (a *Asset) IsQualified(c Criteria) bool {
 		a.Confidence >= c.MinConfidence
 }
 
+// Freshness is the share of a score taken at scoredAt still counted at now:
+// 1 when fresh, halving every halfLife. Unstamped scores and a zero halfLife
+// count in full.
+func Freshness(scoredAt, now time.Time, halfLife time.Duration) float64 {
+	if scoredAt.IsZero() || halfLife <= 0 {
+		return 1
+	}
+	age := now.Sub(scoredAt)
+	if age <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+// FreshConfidence is Confidence decayed by the age of the score.
+func (a Asset) FreshConfidence(now time.Time, halfLife time.Duration) float64 {
+	return a.Confidence * Freshness(a.ScoredAt, now, halfLife)
+}
+
+// IsStale reports whether the score is older than maxAge. Unstamped scores
+// and a zero maxAge are never stale.
+func (a Asset) IsStale(now time.Time, maxAge time.Duration) bool {
+	return maxAge > 0 && !a.ScoredAt.IsZero() && now.Sub(a.ScoredAt) > maxAge
+}
+
+// RankFresh orders assets by decayed confidence, highest first, so a stale
+// high score falls behind a fresh lower one.
+func RankFresh(assets []Asset, now time.Time, halfLife time.Duration) {
+	sort.SliceStable(assets, func(i, j int) bool {
+		return assets[i].FreshConfidence(now, halfLife) > assets[j].FreshConfidence(now, halfLife)
+	})
+}
+
 type ScoredAsset struct {
 	Asset
 	Score float64
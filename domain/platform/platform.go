@@ -2,13 +2,19 @@ package platform
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/britej3/gobot/domain/asset"
 	"github.com/britej3/gobot/domain/automation"
 	"github.com/britej3/gobot/domain/executor"
 	"github.com/britej3/gobot/domain/selector"
 	"github.com/britej3/gobot/domain/strategy"
 	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/entrylimit"
+	"github.com/britej3/gobot/pkg/intent"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/pkg/symlock"
 )
 
 type Platform struct {
@@ -16,19 +22,31 @@ type Platform struct {
 	Engine     *PlatformEngine
 	Components *Components
 	stopCh     chan struct{}
+	shadow     shadowState
 }
 
 type PlatformConfig struct {
-	Name             string                      `json:"name"`
-	Version          string                      `json:"version"`
-	Environment      string                      `json:"environment"`
-	StrategyConfig   strategy.StrategyConfig     `json:"strategy_config"`
-	SelectorConfig   selector.SelectorConfig     `json:"selector_config"`
-	ExecutorConfig   executor.ExecutionConfig    `json:"executor_config"`
-	AutomationConfig automation.AutomationConfig `json:"automation_config"`
-	RiskConfig       RiskConfig                  `json:"risk_config"`
-	Notifications    NotificationConfig          `json:"notifications"`
-	Logging          LoggingConfig               `json:"logging"`
+	Name           string                  `json:"name"`
+	Version        string                  `json:"version"`
+	Environment    string                  `json:"environment"`
+	StrategyConfig strategy.StrategyConfig `json:"strategy_config"`
+	// Strategies run live next to StrategyConfig, sharing capital through
+	// Components.Allocator when one is set.
+	Strategies []strategy.StrategyConfig `json:"strategies,omitempty"`
+	// ShadowStrategyConfig, when set, is evaluated in dry-run next to
+	// StrategyConfig and its would-be orders are diffed against the live ones.
+	ShadowStrategyConfig *strategy.StrategyConfig    `json:"shadow_strategy_config,omitempty"`
+	SelectorConfig       selector.SelectorConfig     `json:"selector_config"`
+	ExecutorConfig       executor.ExecutionConfig    `json:"executor_config"`
+	AutomationConfig     automation.AutomationConfig `json:"automation_config"`
+	RiskConfig           RiskConfig                  `json:"risk_config"`
+	Notifications        NotificationConfig          `json:"notifications"`
+	Logging              LoggingConfig               `json:"logging"`
+	// ScoreHalfLife ranks selected assets by confidence decayed with the age
+	// of their score; MaxSignalAge skips assets scored longer ago than that.
+	// Zero disables either.
+	ScoreHalfLife time.Duration `json:"score_half_life"`
+	MaxSignalAge  time.Duration `json:"max_signal_age"`
 }
 
 type RiskConfig struct {
@@ -57,12 +75,26 @@ type LoggingConfig struct {
 type Components struct {
 	MarketDataProvider MarketDataProvider
 	Strategy           strategy.Strategy
+	ShadowStrategy     strategy.Strategy
+	Strategies         []strategy.Strategy
+	Allocator          CapitalAllocator
+	Universes          UniverseClassifier
+	Suspensions        SuspensionChecker
+	PositionLocks      *symlock.Locks
+	Intents            *intent.Tracker
+	EntryLimits        *entrylimit.Limiter
+	Journal            PositionJournal
 	Selector           selector.Selector
 	Executor           executor.Executor
 	Automation         automation.Automation
 	PositionManager    PositionManager
 	RiskManager        RiskManager
 	Notifier           Notifier
+
+	// Planner, when set, sizes every entry from Capital's equity at the
+	// strategy's stop, in place of the strategy's own sizing.
+	Planner *trade.PositionPlanner
+	Capital CapitalSource
 }
 
 type PlatformEngine struct {
@@ -85,6 +117,12 @@ func (e *PlatformEngine) RegisterStrategy(t strategy.StrategyType, factory strat
 	e.strategies[t] = factory
 }
 
+// HasStrategy reports whether a factory is registered for t
+func (e *PlatformEngine) HasStrategy(t strategy.StrategyType) bool {
+	_, ok := e.strategies[t]
+	return ok
+}
+
 func (e *PlatformEngine) RegisterSelector(t selector.SelectorType, factory selector.SelectorFactory) {
 	e.selectors[t] = factory
 }
@@ -105,6 +143,20 @@ func (p *Platform) Initialize(ctx context.Context) error {
 		return err
 	}
 
+	for _, cfg := range p.Cfg.Strategies {
+		s, err := p.Engine.CreateStrategy(cfg)
+		if err != nil {
+			return err
+		}
+		p.Components.Strategies = append(p.Components.Strategies, s)
+	}
+
+	if p.Cfg.ShadowStrategyConfig != nil {
+		if err := p.EnableShadow(*p.Cfg.ShadowStrategyConfig); err != nil {
+			return err
+		}
+	}
+
 	p.Components.Selector, err = p.Engine.CreateSelector(p.Cfg.SelectorConfig)
 	if err != nil {
 		return err
@@ -128,6 +180,12 @@ func (p *Platform) Start(ctx context.Context) error {
 		return err
 	}
 
+	for i, s := range p.Components.Strategies {
+		if err := s.Configure(p.Cfg.Strategies[i]); err != nil {
+			return err
+		}
+	}
+
 	if err := p.Components.Selector.Configure(p.Cfg.SelectorConfig); err != nil {
 		return err
 	}
@@ -163,50 +221,164 @@ func (p *Platform) RunCycle(ctx context.Context) error {
 		return err
 	}
 
-	for _, asset := range selectedAssets {
-		market, ok := marketData[asset.Symbol]
-		if !ok {
+	asset.RankFresh(selectedAssets, time.Now(), p.Cfg.ScoreHalfLife)
+
+	for _, a := range selectedAssets {
+		// Checked per asset: evaluating the earlier ones takes time too.
+		if a.IsStale(time.Now(), p.Cfg.MaxSignalAge) {
 			continue
 		}
-
-		shouldEnter, reason, err := p.Components.Strategy.ShouldEnter(ctx, *market)
-		if err != nil {
+		market, ok := marketData[a.Symbol]
+		if !ok || p.suspended(a.Symbol) {
 			continue
 		}
 
-		if shouldEnter {
-			positionSize, _ := p.Components.Strategy.CalculatePositionSize(ctx, *market, 0)
-			stopLoss, _ := p.Components.Strategy.CalculateStopLoss(ctx, market.CurrentPrice, *market)
-			takeProfit, _ := p.Components.Strategy.CalculateTakeProfit(ctx, market.CurrentPrice, *market)
-
-			result := strategy.StrategyResult{
-				ShouldEnter:  true,
-				Reason:       reason,
-				PositionSize: positionSize,
-				StopLoss:     stopLoss,
-				TakeProfit:   takeProfit,
+		for i, s := range p.strategies() {
+			if !p.inUniverse(i, a.Symbol) || p.suspended(p.strategyName(i, s)) {
+				continue
 			}
 
-			order, err := p.Components.Executor.Execute(ctx, result, *market)
+			result, err := p.evaluate(ctx, s, *market)
 			if err != nil {
 				continue
 			}
 
-			p.Components.Automation.Execute(ctx, automation.EventData{
-				Type:      "trade_signal",
-				Timestamp: time.Now(),
-				Data: map[string]interface{}{
-					"signal": result,
-					"order":  order,
-					"market": market,
-				},
-			})
+			var orderID string
+			if result.ShouldEnter {
+				orderID = p.enter(ctx, p.strategyName(i, s), result, *market)
+			}
+
+			if i == 0 && p.Components.ShadowStrategy != nil {
+				p.runShadow(ctx, a.Symbol, *market, result, orderID)
+			}
 		}
 	}
 
 	return nil
 }
 
+// strategies returns the primary strategy followed by the additional ones.
+func (p *Platform) strategies() []strategy.Strategy {
+	return append([]strategy.Strategy{p.Components.Strategy}, p.Components.Strategies...)
+}
+
+// strategyName identifies the i-th strategy by its configured name, which is
+// what allocations are keyed on, falling back to the implementation's name.
+func (p *Platform) strategyName(i int, s strategy.Strategy) string {
+	cfg := p.Cfg.StrategyConfig
+	if i > 0 {
+		cfg = p.Cfg.Strategies[i-1]
+	}
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return s.Name()
+}
+
+func (p *Platform) suspended(target string) bool {
+	return p.Components.Suspensions != nil && p.Components.Suspensions.IsSuspended(target)
+}
+
+// inUniverse reports whether the i-th strategy may trade symbol. Strategies
+// without a universe, or platforms without a classifier, trade everything.
+func (p *Platform) inUniverse(i int, symbol string) bool {
+	cfg := p.Cfg.StrategyConfig
+	if i > 0 {
+		cfg = p.Cfg.Strategies[i-1]
+	}
+	if cfg.Universe == "" || p.Components.Universes == nil {
+		return true
+	}
+	return strings.EqualFold(p.Components.Universes.UniverseOf(symbol), cfg.Universe)
+}
+
+// enter books the entry against the strategy's allocation and executes it,
+// giving the notional back if execution fails. It returns the order ID, or
+// "" when nothing was placed.
+func (p *Platform) enter(ctx context.Context, name string, result strategy.StrategyResult, market trade.MarketData) string {
+	intents := p.Components.Intents
+	id := intents.Propose(market.Symbol, "", name)
+	unlock, err := p.Components.PositionLocks.Lock(ctx, market.Symbol)
+	if err != nil {
+		intents.Advance(id, intent.Rejected, err.Error())
+		return ""
+	}
+	defer unlock()
+
+	release, err := p.Components.EntryLimits.Take()
+	if err != nil {
+		intents.Advance(id, intent.Rejected, err.Error())
+		return ""
+	}
+	notional := result.PositionSize * market.CurrentPrice
+	allocator := p.Components.Allocator
+	if allocator != nil {
+		if err := allocator.Reserve(name, notional); err != nil {
+			release()
+			intents.Advance(id, intent.Rejected, err.Error())
+			return ""
+		}
+	}
+	intents.Advance(id, intent.Validated, "")
+
+	intents.Advance(id, intent.Submitted, "")
+	order, err := p.Components.Executor.Execute(ctx, result, market)
+	if err != nil {
+		if allocator != nil {
+			allocator.Release(name, notional)
+		}
+		release()
+		intents.Advance(id, intent.Rejected, err.Error())
+		return ""
+	}
+	intents.Advance(id, intent.Filled, "order "+order.ID)
+	if p.Components.Journal != nil {
+		// Size at the market price books back exactly the reserved notional
+		// once the position closes.
+		p.Components.Journal.AddPosition(state.Position{
+			Symbol:     market.Symbol,
+			Side:       string(order.Side),
+			Size:       result.PositionSize,
+			EntryPrice: market.CurrentPrice,
+			StopLoss:   result.StopLoss,
+			TakeProfit: result.TakeProfit,
+			OpenTime:   time.Now(),
+			Confidence: result.Confidence,
+			Reasoning:  result.Reason,
+			FillPrice:  order.AvgFillPrice,
+			Strategy:   name,
+		})
+	}
+
+	p.Components.Automation.Execute(ctx, automation.EventData{
+		Type:      "trade_signal",
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"strategy": name,
+			"signal":   result,
+			"order":    order,
+			"market":   market,
+		},
+	})
+	return order.ID
+}
+
+// PositionClosed returns a closed position's notional to its strategy's
+// allocation and feeds the realized return into the next rebalance.
+func (p *Platform) PositionClosed(strategyName string, notional, pnlPercent float64) {
+	if p.Components.Allocator == nil || strategyName == "" {
+		return
+	}
+	p.Components.Allocator.Release(strategyName, notional)
+	p.Components.Allocator.RecordReturn(strategyName, pnlPercent)
+}
+
+// TradeClosed hands a journaled trade opened by one of the platform's
+// strategies to PositionClosed. Register it with the journal's OnTrade.
+func (p *Platform) TradeClosed(t state.Trade) {
+	p.PositionClosed(t.Strategy, t.Size*t.EntryPrice, t.PnLPercent)
+}
+
 func (p *Platform) UpdateStrategy(config strategy.StrategyConfig) error {
 	p.Cfg.StrategyConfig = config
 	return p.Components.Strategy.Configure(config)
@@ -284,6 +456,33 @@ type RiskManager interface {
 	CalculatePositionSize(ctx context.Context, market trade.MarketData) (float64, error)
 }
 
+// PositionJournal keeps the positions the platform opens until the account
+// reports them closed, and journals them as trades then.
+type PositionJournal interface {
+	AddPosition(pos state.Position)
+}
+
+type CapitalAllocator interface {
+	Reserve(strategy string, notional float64) error
+	Release(strategy string, notional float64)
+	RecordReturn(strategy string, returnPct float64)
+}
+
+// CapitalSource reports the equity entries are sized against.
+type CapitalSource interface {
+	GetStats() state.StateStats
+}
+
+type UniverseClassifier interface {
+	UniverseOf(symbol string) string
+}
+
+// SuspensionChecker reports strategies and symbols suspended on their own,
+// without halting the platform.
+type SuspensionChecker interface {
+	IsSuspended(target string) bool
+}
+
 type Notifier interface {
 	Send(ctx context.Context, message string, channel string) error
 }
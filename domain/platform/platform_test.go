@@ -0,0 +1,107 @@
+package platform
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/britej3/gobot/domain/automation"
+	"github.com/britej3/gobot/domain/executor"
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/allocator"
+)
+
+// filledExecutor fills every order at the market price.
+type filledExecutor struct {
+	executor.Executor
+	orders int
+}
+
+func (e *filledExecutor) Execute(ctx context.Context, signal strategy.StrategyResult, market trade.MarketData) (*trade.Order, error) {
+	e.orders++
+	return &trade.Order{ID: "1", Symbol: market.Symbol, Side: trade.SideBuy, FilledQty: signal.PositionSize, AvgFillPrice: market.CurrentPrice}, nil
+}
+
+type quietAutomation struct {
+	automation.Automation
+}
+
+func (quietAutomation) Execute(ctx context.Context, event automation.EventData) error {
+	return nil
+}
+
+func TestPlatform_ClosedPositionsFreeTheirAllocation(t *testing.T) {
+	alloc := allocator.New(allocator.Config{Budget: 1000, Weights: map[string]float64{"scalper": 1}})
+	journal := &state.TradingState{}
+	exec := &filledExecutor{}
+	p := &Platform{Components: &Components{
+		Allocator:  alloc,
+		Journal:    journal,
+		Executor:   exec,
+		Automation: quietAutomation{},
+	}}
+	journal.OnTrade(p.TradeClosed)
+
+	result := strategy.StrategyResult{ShouldEnter: true, PositionSize: 8}
+	market := trade.MarketData{Symbol: "DOGEUSDT", CurrentPrice: 100}
+
+	if id := p.enter(context.Background(), "scalper", result, market); id == "" {
+		t.Fatal("the first entry fits the allocation")
+	}
+	if id := p.enter(context.Background(), "scalper", result, market); id != "" || exec.orders != 1 {
+		t.Fatal("a second entry must not fit while the first is open")
+	}
+
+	journal.ClosePosition("DOGEUSDT", 110)
+	if id := p.enter(context.Background(), "scalper", result, market); id == "" {
+		t.Fatal("closing the position should free its notional")
+	}
+	allocs := alloc.Allocations()
+	if len(allocs) != 1 || allocs[0].Used != 800 {
+		t.Errorf("expected 800 in use after re-entering, got %+v", allocs)
+	}
+}
+
+// fixedStrategy always enters with a 2% stop and sizes itself at 1 unit.
+type fixedStrategy struct {
+	strategy.Strategy
+}
+
+func (fixedStrategy) ShouldEnter(ctx context.Context, market trade.MarketData) (bool, string, error) {
+	return true, "always", nil
+}
+
+func (fixedStrategy) CalculatePositionSize(ctx context.Context, market trade.MarketData, balance float64) (float64, error) {
+	return 1, nil
+}
+
+func (fixedStrategy) CalculateStopLoss(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	return entryPrice * 0.98, nil
+}
+
+func (fixedStrategy) CalculateTakeProfit(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	return entryPrice * 1.04, nil
+}
+
+func TestPlatform_PlannerSizesStrategyEntries(t *testing.T) {
+	market := trade.MarketData{Symbol: "BTCUSDT", CurrentPrice: 100}
+	p := &Platform{Components: &Components{}}
+
+	result, err := p.evaluate(context.Background(), fixedStrategy{}, market)
+	if err != nil || result.PositionSize != 1 {
+		t.Fatalf("without a planner the strategy sizes, got %+v, %v", result, err)
+	}
+
+	p.Components.Planner = trade.NewPositionPlanner(trade.SizingLimits{RiskPerTrade: 0.01})
+	p.Components.Capital = &state.TradingState{Capital: 10000}
+	result, err = p.evaluate(context.Background(), fixedStrategy{}, market)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1% of 10000 lost over a 2 point stop is 50 units.
+	if math.Abs(result.PositionSize-50) > 1e-9 || result.StopLoss != 98 {
+		t.Errorf("expected 50 units at the strategy's stop, got %+v", result)
+	}
+}
@@ -0,0 +1,238 @@
+package platform
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+)
+
+type ShadowDiffKind string
+
+const (
+	ShadowMatch   ShadowDiffKind = "match"
+	ShadowOnly    ShadowDiffKind = "shadow_only"
+	LiveOnly      ShadowDiffKind = "live_only"
+	ShadowChanged ShadowDiffKind = "changed"
+)
+
+// shadowTolerance is the relative difference below which live and shadow
+// sizes and levels are treated as equal.
+const shadowTolerance = 0.001
+
+// ShadowDiff compares what the live strategy did for a symbol with the order
+// the shadow config would have placed. Shadow orders are never sent.
+type ShadowDiff struct {
+	Symbol      string
+	Kind        ShadowDiffKind
+	Live        strategy.StrategyResult
+	Shadow      strategy.StrategyResult
+	LiveOrderID string
+	Timestamp   time.Time
+}
+
+type ShadowStats struct {
+	Evaluated   int
+	Matches     int
+	ShadowOnly  int
+	LiveOnly    int
+	Changed     int
+	LastDiffAt  time.Time
+	ShadowSince time.Time
+}
+
+type shadowState struct {
+	mu       sync.RWMutex
+	stats    ShadowStats
+	diffs    []ShadowDiff
+	handlers []func(ShadowDiff)
+}
+
+const maxShadowDiffs = 500
+
+// EnableShadow starts evaluating cfg alongside the live strategy on every
+// cycle. Its decisions are only diffed and reported, never executed.
+func (p *Platform) EnableShadow(cfg strategy.StrategyConfig) error {
+	s, err := p.Engine.CreateStrategy(cfg)
+	if err != nil {
+		return err
+	}
+
+	p.Cfg.ShadowStrategyConfig = &cfg
+	p.Components.ShadowStrategy = s
+
+	p.shadow.mu.Lock()
+	p.shadow.stats = ShadowStats{ShadowSince: time.Now()}
+	p.shadow.diffs = nil
+	p.shadow.mu.Unlock()
+	return nil
+}
+
+func (p *Platform) DisableShadow() {
+	p.Cfg.ShadowStrategyConfig = nil
+	p.Components.ShadowStrategy = nil
+}
+
+// PromoteShadow switches the live strategy to the shadow config once the
+// operator is satisfied with the diff, and stops shadowing.
+func (p *Platform) PromoteShadow() error {
+	if p.Cfg.ShadowStrategyConfig == nil {
+		return strategy.ErrUnknownStrategy
+	}
+	if err := p.UpdateStrategy(*p.Cfg.ShadowStrategyConfig); err != nil {
+		return err
+	}
+	p.DisableShadow()
+	return nil
+}
+
+// OnShadowDiff registers a callback for every diff that is not a match.
+func (p *Platform) OnShadowDiff(fn func(ShadowDiff)) {
+	p.shadow.mu.Lock()
+	defer p.shadow.mu.Unlock()
+	p.shadow.handlers = append(p.shadow.handlers, fn)
+}
+
+func (p *Platform) ShadowDiffs() []ShadowDiff {
+	p.shadow.mu.RLock()
+	defer p.shadow.mu.RUnlock()
+
+	result := make([]ShadowDiff, len(p.shadow.diffs))
+	copy(result, p.shadow.diffs)
+	return result
+}
+
+func (p *Platform) ShadowStats() ShadowStats {
+	p.shadow.mu.RLock()
+	defer p.shadow.mu.RUnlock()
+	return p.shadow.stats
+}
+
+func (p *Platform) recordShadow(diff ShadowDiff) {
+	p.shadow.mu.Lock()
+	p.shadow.stats.Evaluated++
+	switch diff.Kind {
+	case ShadowMatch:
+		p.shadow.stats.Matches++
+	case ShadowOnly:
+		p.shadow.stats.ShadowOnly++
+	case LiveOnly:
+		p.shadow.stats.LiveOnly++
+	case ShadowChanged:
+		p.shadow.stats.Changed++
+	}
+
+	var handlers []func(ShadowDiff)
+	if diff.Kind != ShadowMatch {
+		p.shadow.stats.LastDiffAt = diff.Timestamp
+		p.shadow.diffs = append(p.shadow.diffs, diff)
+		if len(p.shadow.diffs) > maxShadowDiffs {
+			p.shadow.diffs = p.shadow.diffs[len(p.shadow.diffs)-maxShadowDiffs:]
+		}
+		handlers = make([]func(ShadowDiff), len(p.shadow.handlers))
+		copy(handlers, p.shadow.handlers)
+	}
+	p.shadow.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(diff)
+	}
+}
+
+// runShadow evaluates the shadow strategy for one market and records how its
+// would-be order differs from the live decision and the order actually sent.
+func (p *Platform) runShadow(ctx context.Context, symbol string, market trade.MarketData, live strategy.StrategyResult, liveOrderID string) {
+	shadow, err := p.evaluate(ctx, p.Components.ShadowStrategy, market)
+	if err != nil {
+		return
+	}
+
+	kind, ok := diffKind(live, shadow)
+	if !ok {
+		return
+	}
+
+	p.recordShadow(ShadowDiff{
+		Symbol:      symbol,
+		Kind:        kind,
+		Live:        live,
+		Shadow:      shadow,
+		LiveOrderID: liveOrderID,
+		Timestamp:   time.Now(),
+	})
+}
+
+// evaluate runs a strategy's entry logic for one market without executing.
+// With a planner the entry is sized by it at the strategy's levels instead
+// of by the strategy.
+func (p *Platform) evaluate(ctx context.Context, s strategy.Strategy, market trade.MarketData) (strategy.StrategyResult, error) {
+	shouldEnter, reason, err := s.ShouldEnter(ctx, market)
+	if err != nil || !shouldEnter {
+		return strategy.StrategyResult{Reason: reason}, err
+	}
+
+	stopLoss, _ := s.CalculateStopLoss(ctx, market.CurrentPrice, market)
+	takeProfit, _ := s.CalculateTakeProfit(ctx, market.CurrentPrice, market)
+	var positionSize float64
+	if planner := p.Components.Planner; planner != nil {
+		var equity float64
+		if p.Components.Capital != nil {
+			equity = p.Components.Capital.GetStats().Capital
+		}
+		plan, err := planner.Plan(trade.PlanRequest{
+			Side:       trade.SideBuy,
+			Entry:      market.CurrentPrice,
+			StopLoss:   stopLoss,
+			TakeProfit: takeProfit,
+			Equity:     equity,
+		})
+		if err != nil {
+			return strategy.StrategyResult{Reason: err.Error()}, err
+		}
+		if plan.Quantity <= 0 {
+			return strategy.StrategyResult{Reason: "nothing to risk"}, nil
+		}
+		positionSize, stopLoss, takeProfit = plan.Quantity, plan.StopLoss, plan.TakeProfit
+	} else {
+		positionSize, _ = s.CalculatePositionSize(ctx, market, 0)
+	}
+
+	return strategy.StrategyResult{
+		ShouldEnter:  true,
+		Reason:       reason,
+		PositionSize: positionSize,
+		StopLoss:     stopLoss,
+		TakeProfit:   takeProfit,
+	}, nil
+}
+
+// diffKind classifies a live/shadow pair. It returns false when neither
+// side wanted to trade, which is not worth recording.
+func diffKind(live, shadow strategy.StrategyResult) (ShadowDiffKind, bool) {
+	switch {
+	case !live.ShouldEnter && !shadow.ShouldEnter:
+		return "", false
+	case !live.ShouldEnter:
+		return ShadowOnly, true
+	case !shadow.ShouldEnter:
+		return LiveOnly, true
+	}
+
+	if differs(live.PositionSize, shadow.PositionSize) ||
+		differs(live.StopLoss, shadow.StopLoss) ||
+		differs(live.TakeProfit, shadow.TakeProfit) {
+		return ShadowChanged, true
+	}
+	return ShadowMatch, true
+}
+
+func differs(a, b float64) bool {
+	scale := math.Max(math.Abs(a), math.Abs(b))
+	if scale == 0 {
+		return false
+	}
+	return math.Abs(a-b)/scale > shadowTolerance
+}
@@ -17,6 +17,7 @@ const (
 	StrategyGrid        StrategyType = "grid"
 	StrategyAIAutomated StrategyType = "ai_automated"
 	StrategyCustom      StrategyType = "custom"
+	StrategyRules       StrategyType = "rules"
 )
 
 type Strategy interface {
@@ -49,6 +50,33 @@ type StrategyConfig struct {
 	MaxPositions   int                `json:"max_positions"`
 	MaxDrawdown    float64            `json:"max_drawdown"`
 	DailyLossLimit float64            `json:"daily_loss_limit"`
+	Universe       string             `json:"universe,omitempty"`
+	// Rules holds the entry and exit conditions of a rules strategy.
+	Rules RuleConfig `json:"rules,omitempty"`
+	// Grid lays out the levels of a grid strategy.
+	Grid GridConfig `json:"grid,omitempty"`
+}
+
+// RuleConfig expresses a strategy as conditions over the indicator
+// snapshot, e.g. `rsi < 70 && ema_fast > ema_slow`. An empty Exit leaves
+// exits to the stop loss and take profit.
+type RuleConfig struct {
+	Entry string `json:"entry,omitempty"`
+	Exit  string `json:"exit,omitempty"`
+}
+
+// GridConfig spaces Levels buy lines evenly from Lower up to Upper. Each
+// line bought takes profit one line up; a break below Lower stops it out.
+// Zero bounds follow the 24h low and high. LevelSize is the quote notional
+// bought per line.
+type GridConfig struct {
+	Lower     float64 `json:"lower,omitempty"`
+	Upper     float64 `json:"upper,omitempty"`
+	Levels    int     `json:"levels,omitempty"`
+	LevelSize float64 `json:"level_size,omitempty"`
+	// MaxTrendPercent is the widest fast/slow EMA spread, in percent of
+	// price, at which a market still counts as ranging. Default 0.5.
+	MaxTrendPercent float64 `json:"max_trend_percent,omitempty"`
 }
 
 type RiskConfig struct {
@@ -59,6 +87,42 @@ type RiskConfig struct {
 	TrailingStopPercent float64 `json:"trailing_stop_percent"`
 	MaxLeverage         float64 `json:"max_leverage"`
 	RiskPerTrade        float64 `json:"risk_per_trade"`
+
+	// StopMode is "percent" (default) or "atr", which places the stop
+	// ATRStopMultiple ATRs beyond the recent swing and the target
+	// ATRTargetMultiple ATRs from entry.
+	StopMode          string  `json:"stop_mode,omitempty"`
+	ATRStopMultiple   float64 `json:"atr_stop_multiple,omitempty"`
+	ATRTargetMultiple float64 `json:"atr_target_multiple,omitempty"`
+}
+
+// Stop placement modes
+const (
+	StopModePercent = "percent"
+	StopModeATR     = "atr"
+)
+
+// ATRLevels returns the stop and target for an entry when ATR placement is
+// selected and market carries an ATR, by default 1.2 ATRs beyond structure
+// and 2.5 ATRs out. Otherwise ok is false and the strategy places its
+// levels by percent.
+func (r RiskConfig) ATRLevels(side trade.Side, entry float64, market trade.MarketData) (stopLoss, takeProfit float64, ok bool) {
+	if r.StopMode != StopModeATR || market.ATR <= 0 || entry <= 0 {
+		return 0, 0, false
+	}
+	stopMultiple, targetMultiple := r.ATRStopMultiple, r.ATRTargetMultiple
+	if stopMultiple <= 0 {
+		stopMultiple = 1.2
+	}
+	if targetMultiple <= 0 {
+		targetMultiple = 2.5
+	}
+	structure := market.SwingLow
+	if side == trade.SideSell {
+		structure = market.SwingHigh
+	}
+	stopLoss, takeProfit = trade.ATRLevels(side, entry, market.ATR, structure, stopMultiple, targetMultiple)
+	return stopLoss, takeProfit, true
 }
 
 type FilterConfig struct {
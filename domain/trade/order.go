@@ -54,6 +54,26 @@ func (ot OrderType) IsValid() bool {
 	return false
 }
 
+// TimeInForce is how long a limit order may rest; limit orders without one
+// are GTC. GTX is post-only: the order is rejected rather than taking
+// liquidity.
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+	TimeInForceIOC TimeInForce = "IOC"
+	TimeInForceFOK TimeInForce = "FOK"
+	TimeInForceGTX TimeInForce = "GTX"
+)
+
+func (t TimeInForce) IsValid() bool {
+	switch t {
+	case TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForceGTX:
+		return true
+	}
+	return false
+}
+
 type OrderStatus string
 
 const (
@@ -75,20 +95,24 @@ func (s OrderStatus) IsTerminal() bool {
 }
 
 type Order struct {
-	ID           string
-	Symbol       string
-	Side         Side
-	Type         OrderType
-	Quantity     float64
-	Price        float64
-	StopLoss     float64
-	TakeProfit   float64
-	Status       OrderStatus
-	FilledQty    float64
-	AvgFillPrice float64
-	Commission   float64
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID string
+	// ClientOrderID is sent with the order when set, so its events can be
+	// matched before the exchange has answered; otherwise one is generated.
+	ClientOrderID string
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	Quantity      float64
+	Price         float64
+	TimeInForce   TimeInForce
+	StopLoss      float64
+	TakeProfit    float64
+	Status        OrderStatus
+	FilledQty     float64
+	AvgFillPrice  float64
+	Commission    float64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
 
 func (o *Order) Validate() error {
@@ -196,7 +220,106 @@ type MarketData struct {
 	RSI          float64
 	EMAFast      float64
 	EMASlow      float64
-	Timestamp    time.Time
+	VWAP         float64
+	// AnchoredVWAP is the VWAP since the last volume spike, or since the
+	// session open without one; 0 when it is not known.
+	AnchoredVWAP float64
+	ATR          float64
+	SwingLow     float64
+	SwingHigh    float64
+	// Patterns are candlestick pattern strengths by name, positive when
+	// bullish and negative when bearish; absent patterns read 0.
+	Patterns  map[string]float64
+	Timestamp time.Time
+}
+
+// TightenToVWAP raises a long stop (or lowers a short stop) to VWAP when VWAP
+// sits between the stop and the current price. The anchored VWAP is used
+// when known, the rolling VWAP otherwise.
+func (m MarketData) TightenToVWAP(side Side, stop float64) float64 {
+	vwap := m.AnchoredVWAP
+	if vwap <= 0 {
+		vwap = m.VWAP
+	}
+	if vwap <= 0 {
+		return stop
+	}
+	if side == SideBuy && vwap < m.CurrentPrice && vwap > stop {
+		return vwap
+	}
+	if side == SideSell && vwap > m.CurrentPrice && vwap < stop {
+		return vwap
+	}
+	return stop
+}
+
+// ATRLevels places a stop stopMultiple ATRs beyond structure, the recent
+// swing low for a long or swing high for a short, and a target
+// targetMultiple ATRs from entry. A structure level on the wrong side of
+// entry, or zero, places the stop from entry instead.
+func ATRLevels(side Side, entry, atr, structure, stopMultiple, targetMultiple float64) (stopLoss, takeProfit float64) {
+	if side == SideSell {
+		base := entry
+		if structure > entry {
+			base = structure
+		}
+		return base + stopMultiple*atr, entry - targetMultiple*atr
+	}
+	base := entry
+	if structure > 0 && structure < entry {
+		base = structure
+	}
+	return base - stopMultiple*atr, entry + targetMultiple*atr
+}
+
+// RiskReward returns reward over risk for an entry with the given stop and
+// target, net of round-trip fees at feeRate. It returns 0 when the stop or
+// target sits on the wrong side of entry.
+func RiskReward(side Side, entry, stopLoss, takeProfit, feeRate float64) float64 {
+	risk, reward := entry-stopLoss, takeProfit-entry
+	if side == SideSell {
+		risk, reward = stopLoss-entry, entry-takeProfit
+	}
+	if entry <= 0 || risk <= 0 || reward <= 0 {
+		return 0
+	}
+
+	fees := 2 * entry * feeRate
+	if reward <= fees {
+		return 0
+	}
+	return (reward - fees) / (risk + fees)
+}
+
+// ExpectedValue returns the expected return of an entry in basis points of
+// entry: winProb times the distance to takeProfit, less the loss probability
+// times the distance to stopLoss, less round-trip fees at feeRate and half of
+// spread, the cost of crossing the book.
+func ExpectedValue(side Side, entry, stopLoss, takeProfit, winProb, feeRate, spread float64) float64 {
+	if entry <= 0 {
+		return 0
+	}
+	return ExpectedValueNet(side, entry, stopLoss, takeProfit, winProb, (2*entry*feeRate+spread/2)/entry*1e4)
+}
+
+// ExpectedValueNet is ExpectedValue with the execution cost given directly,
+// in basis points of entry, for callers that forecast it.
+func ExpectedValueNet(side Side, entry, stopLoss, takeProfit, winProb, costBps float64) float64 {
+	if entry <= 0 {
+		return 0
+	}
+	risk, reward := entry-stopLoss, takeProfit-entry
+	if side == SideSell {
+		risk, reward = stopLoss-entry, entry-takeProfit
+	}
+	if winProb < 0 {
+		winProb = 0
+	} else if winProb > 1 {
+		winProb = 1
+	}
+
+	ev := winProb*reward - (1-winProb)*risk
+	return ev/entry*1e4 - costBps
 }
 
 type Strategy interface {
@@ -0,0 +1,18 @@
+package trade
+
+import "testing"
+
+func TestMarketData_TightenToVWAPPrefersTheAnchoredVWAP(t *testing.T) {
+	m := MarketData{CurrentPrice: 100, VWAP: 95, AnchoredVWAP: 98}
+	if got := m.TightenToVWAP(SideBuy, 94); got != 98 {
+		t.Errorf("expected the long stop raised to the anchored VWAP 98, got %v", got)
+	}
+	if got := m.TightenToVWAP(SideBuy, 99); got != 99 {
+		t.Errorf("a stop above VWAP must not be lowered, got %v", got)
+	}
+
+	m.AnchoredVWAP = 0
+	if got := m.TightenToVWAP(SideBuy, 94); got != 95 {
+		t.Errorf("expected the rolling VWAP without an anchored one, got %v", got)
+	}
+}
@@ -0,0 +1,187 @@
+package trade
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SizingLimits are the risk constraints every entry is sized under.
+// RiskPerTrade is the fraction of equity lost if the stop is hit, and
+// MaxNotional caps the position's value in quote currency. Leverage falls
+// back to DefaultLeverage and is kept within MinLeverage and MaxLeverage.
+// StopPercent and TargetPercent place the stop and target, as fractions of
+// entry, for requests that come without them.
+type SizingLimits struct {
+	RiskPerTrade    float64
+	MaxNotional     float64
+	DefaultLeverage int
+	MinLeverage     int
+	MaxLeverage     int
+	StopPercent     float64
+	TargetPercent   float64
+}
+
+// PlanRequest describes one prospective entry. Equity is the capital the
+// risk is taken from; Available, when set, is the free margin the position
+// must fit in. Multiplier scales the size for the session or a throttle,
+// zero meaning 1. MaxLeverage, when set, lowers the limit for this entry.
+type PlanRequest struct {
+	Side        Side
+	Entry       float64
+	StopLoss    float64
+	TakeProfit  float64
+	Leverage    int
+	MaxLeverage int
+	Equity      float64
+	Available   float64
+	Multiplier  float64
+}
+
+// Plan is a sized entry.
+type Plan struct {
+	Quantity   float64
+	Notional   float64
+	Leverage   int
+	StopLoss   float64
+	TakeProfit float64
+	RiskUSD    float64
+}
+
+// Throttle caps leverage and scales size account-wide while active, e.g. for
+// some minutes after a volatility spike.
+type Throttle interface {
+	Throttle() (maxLeverage int, sizeFactor float64, active bool)
+}
+
+// PositionPlanner turns a signal and the account into quantity, leverage and
+// protective levels, so every entry path sizes the same way.
+type PositionPlanner struct {
+	mu       sync.RWMutex
+	limits   SizingLimits
+	throttle Throttle
+}
+
+func NewPositionPlanner(limits SizingLimits) *PositionPlanner {
+	return &PositionPlanner{limits: withDefaults(limits)}
+}
+
+func (p *PositionPlanner) Limits() SizingLimits {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.limits
+}
+
+// SetLimits replaces the limits for every later plan.
+func (p *PositionPlanner) SetLimits(limits SizingLimits) {
+	p.mu.Lock()
+	p.limits = withDefaults(limits)
+	p.mu.Unlock()
+}
+
+// SetThrottle makes every later plan honour throttle while it is active.
+func (p *PositionPlanner) SetThrottle(throttle Throttle) {
+	p.mu.Lock()
+	p.throttle = throttle
+	p.mu.Unlock()
+}
+
+func withDefaults(limits SizingLimits) SizingLimits {
+	if limits.MinLeverage <= 0 {
+		limits.MinLeverage = 1
+	}
+	if limits.MaxLeverage <= 0 {
+		limits.MaxLeverage = 125
+	}
+	if limits.DefaultLeverage <= 0 {
+		limits.DefaultLeverage = limits.MinLeverage
+	}
+	return limits
+}
+
+// Plan sizes req so that hitting the stop loses RiskPerTrade of equity,
+// scaled by the multiplier and any active throttle, then shrinks it to
+// MaxNotional and to the free margin at the chosen leverage. A zero quantity means there is nothing to
+// risk; a stop or target on the wrong side of entry is an error.
+func (p *PositionPlanner) Plan(req PlanRequest) (Plan, error) {
+	if req.Entry <= 0 {
+		return Plan{}, ErrInvalidPrice
+	}
+	p.mu.RLock()
+	limits, throttle := p.limits, p.throttle
+	p.mu.RUnlock()
+
+	stopLoss, takeProfit := req.StopLoss, req.TakeProfit
+	if stopLoss == 0 && limits.StopPercent > 0 {
+		stopLoss = offset(req.Side, req.Entry, -limits.StopPercent)
+	}
+	if takeProfit == 0 && limits.TargetPercent > 0 {
+		takeProfit = offset(req.Side, req.Entry, limits.TargetPercent)
+	}
+	risk := req.Entry - stopLoss
+	if req.Side == SideSell {
+		risk = -risk
+	}
+	if stopLoss <= 0 || risk <= 0 {
+		return Plan{}, fmt.Errorf("%w: stop %v on the wrong side of entry %v", ErrInvalidOrder, stopLoss, req.Entry)
+	}
+	if takeProfit != 0 && (takeProfit-req.Entry)*(req.Entry-stopLoss) <= 0 {
+		return Plan{}, fmt.Errorf("%w: target %v on the wrong side of entry %v", ErrInvalidOrder, takeProfit, req.Entry)
+	}
+
+	leverage := req.Leverage
+	if leverage <= 0 {
+		leverage = limits.DefaultLeverage
+	}
+	maxLeverage := limits.MaxLeverage
+	if req.MaxLeverage > 0 && req.MaxLeverage < maxLeverage {
+		maxLeverage = req.MaxLeverage
+	}
+	multiplier := req.Multiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	if throttle != nil {
+		if capped, factor, active := throttle.Throttle(); active {
+			if capped > 0 && capped < maxLeverage {
+				maxLeverage = capped
+			}
+			multiplier *= factor
+		}
+	}
+	if leverage > maxLeverage {
+		leverage = maxLeverage
+	}
+	if leverage < limits.MinLeverage {
+		leverage = limits.MinLeverage
+	}
+
+	notional := req.Equity * limits.RiskPerTrade * multiplier / risk * req.Entry
+	if limits.MaxNotional > 0 && notional > limits.MaxNotional {
+		notional = limits.MaxNotional
+	}
+	if req.Available > 0 && notional > req.Available*float64(leverage) {
+		notional = req.Available * float64(leverage)
+	}
+	if notional < 0 {
+		notional = 0
+	}
+
+	quantity := notional / req.Entry
+	return Plan{
+		Quantity:   quantity,
+		Notional:   notional,
+		Leverage:   leverage,
+		StopLoss:   stopLoss,
+		TakeProfit: takeProfit,
+		RiskUSD:    quantity * risk,
+	}, nil
+}
+
+// offset moves pct of entry in the side's favour, or against it when pct is
+// negative.
+func offset(side Side, entry, pct float64) float64 {
+	if side == SideSell {
+		return entry * (1 - pct)
+	}
+	return entry * (1 + pct)
+}
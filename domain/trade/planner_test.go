@@ -0,0 +1,67 @@
+package trade
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPositionPlanner_Plan(t *testing.T) {
+	p := NewPositionPlanner(SizingLimits{RiskPerTrade: 0.01, MaxNotional: 5000, DefaultLeverage: 5, MaxLeverage: 20, StopPercent: 0.005, TargetPercent: 0.01})
+
+	// 1% of 10k at a 2% stop is a 5000 notional, right at the cap.
+	plan, err := p.Plan(PlanRequest{Side: SideBuy, Entry: 100, StopLoss: 98, TakeProfit: 104, Equity: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(plan.Quantity-50) > 1e-9 || plan.Leverage != 5 || math.Abs(plan.RiskUSD-100) > 1e-9 {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	// Halved by the multiplier, the request's leverage capped, and the
+	// levels filled in from the limits on the short side.
+	plan, err = p.Plan(PlanRequest{Side: SideSell, Entry: 100, Leverage: 50, Equity: 10000, Multiplier: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(plan.StopLoss-100.5) > 1e-9 {
+		t.Fatalf("stop = %v", plan.StopLoss)
+	}
+	if math.Abs(plan.TakeProfit-99) > 1e-9 || plan.Leverage != 20 || math.Abs(plan.Notional-5000) > 1e-9 {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	// Free margin of 100 at 4x fits a 400 notional.
+	plan, err = p.Plan(PlanRequest{Side: SideBuy, Entry: 100, StopLoss: 99, Leverage: 4, Equity: 10000, Available: 100})
+	if err != nil || math.Abs(plan.Notional-400) > 1e-9 {
+		t.Fatalf("margin-bound plan = %+v, %v", plan, err)
+	}
+
+	if _, err := p.Plan(PlanRequest{Side: SideBuy, Entry: 100, StopLoss: 101, Equity: 10000}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("stop above a long entry: %v", err)
+	}
+	if _, err := p.Plan(PlanRequest{Side: SideSell, Entry: 100, StopLoss: 101, TakeProfit: 102, Equity: 10000}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("target above a short entry: %v", err)
+	}
+}
+
+type spike bool
+
+func (s spike) Throttle() (int, float64, bool) { return 3, 0.5, bool(s) }
+
+func TestPositionPlanner_HonoursTheThrottle(t *testing.T) {
+	p := NewPositionPlanner(SizingLimits{RiskPerTrade: 0.01, DefaultLeverage: 10, MaxLeverage: 20})
+	var active spike
+	p.SetThrottle(&active)
+	req := PlanRequest{Side: SideBuy, Entry: 100, StopLoss: 98, Equity: 10000}
+
+	plan, _ := p.Plan(req)
+	if plan.Leverage != 10 || math.Abs(plan.Notional-5000) > 1e-9 {
+		t.Fatalf("quiet plan = %+v", plan)
+	}
+	active = true
+	plan, _ = p.Plan(req)
+	if plan.Leverage != 3 || math.Abs(plan.Notional-2500) > 1e-9 {
+		t.Fatalf("throttled plan = %+v", plan)
+	}
+}
@@ -0,0 +1,83 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/accountsetup"
+)
+
+// FuturesAccountSettings reads and changes position mode, multi-assets mode,
+// margin type and leverage on a futures account
+type FuturesAccountSettings struct {
+	client *futures.Client
+}
+
+// NewFuturesAccountSettings creates an account settings adapter backed by a futures client
+func NewFuturesAccountSettings(client *futures.Client) *FuturesAccountSettings {
+	return &FuturesAccountSettings{client: client}
+}
+
+// PositionMode reports whether hedge (dual side) mode is enabled
+func (a *FuturesAccountSettings) PositionMode(ctx context.Context) (bool, error) {
+	res, err := a.client.NewGetPositionModeService().Do(ctx)
+	if err != nil {
+		return false, err
+	}
+	return res.DualSidePosition, nil
+}
+
+// SetPositionMode switches between hedge and one-way mode
+func (a *FuturesAccountSettings) SetPositionMode(ctx context.Context, dualSide bool) error {
+	return a.client.NewChangePositionModeService().DualSide(dualSide).Do(ctx)
+}
+
+// MultiAssetsMode reports whether multi-assets margin is enabled
+func (a *FuturesAccountSettings) MultiAssetsMode(ctx context.Context) (bool, error) {
+	res, err := a.client.NewGetMultiAssetModeService().Do(ctx)
+	if err != nil {
+		return false, err
+	}
+	return res.MultiAssetsMargin, nil
+}
+
+// SetMultiAssetsMode enables or disables multi-assets margin
+func (a *FuturesAccountSettings) SetMultiAssetsMode(ctx context.Context, enabled bool) error {
+	return a.client.NewChangeMultiAssetModeService().MultiAssetsMargin(enabled).Do(ctx)
+}
+
+// SymbolSettings returns leverage and margin type for every symbol from position risk
+func (a *FuturesAccountSettings) SymbolSettings(ctx context.Context) ([]accountsetup.SymbolSettings, error) {
+	risks, err := a.client.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]accountsetup.SymbolSettings, 0, len(risks))
+	for _, r := range risks {
+		leverage, _ := strconv.Atoi(r.Leverage)
+		marginType := accountsetup.MarginCrossed
+		if strings.EqualFold(r.MarginType, "isolated") {
+			marginType = accountsetup.MarginIsolated
+		}
+		settings = append(settings, accountsetup.SymbolSettings{
+			Symbol:     r.Symbol,
+			Leverage:   leverage,
+			MarginType: marginType,
+		})
+	}
+	return settings, nil
+}
+
+// SetLeverage changes the initial leverage of a symbol
+func (a *FuturesAccountSettings) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	_, err := a.client.NewChangeLeverageService().Symbol(symbol).Leverage(leverage).Do(ctx)
+	return err
+}
+
+// SetMarginType changes a symbol between isolated and crossed margin
+func (a *FuturesAccountSettings) SetMarginType(ctx context.Context, symbol, marginType string) error {
+	return a.client.NewChangeMarginTypeService().Symbol(symbol).MarginType(futures.MarginType(marginType)).Do(ctx)
+}
@@ -2,7 +2,9 @@ package binance
 
 import (
 	"context"
+	"time"
 
+	"github.com/britej3/gobot/services/listing"
 	"github.com/britej3/gobot/services/screener"
 )
 
@@ -36,6 +38,15 @@ func (a *ScreenerAdapter) GetExchangeInfo(ctx context.Context) ([]screener.Excha
 	return result, nil
 }
 
+func (a *ScreenerAdapter) OpenInterestChange(ctx context.Context, symbol string) (float64, error) {
+	return a.client.GetOpenInterestChange(ctx, symbol, "5m", 12)
+}
+
+// Scans counts the client's full, incremental and per-symbol scans
+func (a *ScreenerAdapter) Scans() screener.ScanCounts {
+	return a.client.Scans()
+}
+
 func (a *ScreenerAdapter) GetUSDMFuturesPairs(ctx context.Context) ([]screener.ExchangeInfo, error) {
 	return a.GetExchangeInfo(ctx)
 }
@@ -61,3 +72,37 @@ func (a *ScreenerAdapter) GetTopMemeCoins(ctx context.Context, limit int) ([]scr
 
 	return result, nil
 }
+
+type ListingAdapter struct {
+	client *ScreenerClient
+}
+
+func NewListingAdapter(client *ScreenerClient) *ListingAdapter {
+	return &ListingAdapter{client: client}
+}
+
+func (a *ListingAdapter) GetSymbols(ctx context.Context) ([]listing.Symbol, error) {
+	symbols, err := a.client.GetSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]listing.Symbol, 0, len(symbols))
+	for _, s := range symbols {
+		sym := listing.Symbol{
+			Symbol:       s.Symbol,
+			ContractType: s.ContractType,
+			QuoteAsset:   s.QuoteAsset,
+			Status:       s.Status,
+		}
+		if s.OnboardDate > 0 {
+			sym.OnboardDate = time.UnixMilli(s.OnboardDate)
+		}
+		if s.DeliveryDate > 0 {
+			sym.DeliveryDate = time.UnixMilli(s.DeliveryDate)
+		}
+		result = append(result, sym)
+	}
+
+	return result, nil
+}
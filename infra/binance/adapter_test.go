@@ -7,6 +7,8 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
 )
 
 func TestScreenerAdapter(t *testing.T) {
@@ -162,6 +164,63 @@ func TestScreenerClient_GetVolume24h(t *testing.T) {
 	}
 }
 
+func TestScreenerClient_ScansIncrementallyFromTheStream(t *testing.T) {
+	fullScans, paged := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/fapi/v1/exchangeInfo":
+			w.Write([]byte(`{"symbols": [
+				{"symbol": "BTCUSDT", "contractType": "PERPETUAL", "quoteAsset": "USDT", "status": "TRADING"},
+				{"symbol": "WIFUSDT", "contractType": "PERPETUAL", "quoteAsset": "USDT", "status": "TRADING"},
+				{"symbol": "NEWUSDT", "contractType": "PERPETUAL", "quoteAsset": "USDT", "status": "TRADING"}
+			]}`))
+		case r.URL.Query().Get("symbol") == "NEWUSDT":
+			paged++
+			w.Write([]byte(`{"symbol": "NEWUSDT", "quoteVolume": "3000000", "priceChangePercent": "40"}`))
+		default:
+			fullScans++
+			w.Write([]byte(`[
+				{"symbol": "BTCUSDT", "quoteVolume": "50000000", "priceChangePercent": "2.0"},
+				{"symbol": "WIFUSDT", "quoteVolume": "8000000", "priceChangePercent": "8.5"}
+			]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewScreenerClient(Config{BaseURL: server.URL})
+	stream := NewTickerStream()
+	client.SetTickerStream(stream, time.Hour, 5)
+	ctx := context.Background()
+
+	if info, err := client.GetExchangeInfo(ctx); err != nil || len(info) != 2 {
+		t.Fatalf("full scan = %v, %v", info, err)
+	}
+	stream.handle(futures.WsAllMiniMarketTickerEvent{
+		{Symbol: "WIFUSDT", OpenPrice: "1.0", ClosePrice: "1.2", QuoteVolume: "9000000", Time: time.Now().UnixMilli()},
+	})
+
+	info, err := client.GetExchangeInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]ExchangeInfo)
+	for _, p := range info {
+		got[p.Symbol] = p
+	}
+	if len(got) != 3 || got["NEWUSDT"].PriceChangePct != 40 {
+		t.Fatalf("incremental scan = %+v", info)
+	}
+	if wif := got["WIFUSDT"]; wif.Volume24h != 9000000 || wif.PriceChangePct < 19.99 || wif.PriceChangePct > 20.01 {
+		t.Errorf("WIFUSDT = %+v, want the streamed values", wif)
+	}
+	if fullScans != 1 || paged != 1 {
+		t.Errorf("%d full scans and %d paged tickers, want 1 and 1", fullScans, paged)
+	}
+	if scans := client.Scans(); scans.Full != 1 || scans.Incremental != 1 || scans.Paged != 1 {
+		t.Errorf("scans = %+v", scans)
+	}
+}
+
 func TestTicker24hr_JSON(t *testing.T) {
 	data := `{
 		"symbol": "PEPEUSDT",
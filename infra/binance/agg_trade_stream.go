@@ -0,0 +1,89 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/footprint"
+	"github.com/sirupsen/logrus"
+)
+
+// AggTradeStream feeds aggregated trades for a set of symbols into a
+// footprint builder
+type AggTradeStream struct {
+	symbols []string
+	builder *footprint.Builder
+	logger  *logrus.Logger
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+}
+
+// NewAggTradeStream creates a stream that forwards aggTrades to builder
+func NewAggTradeStream(symbols []string, builder *footprint.Builder) *AggTradeStream {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	return &AggTradeStream{
+		symbols: symbols,
+		builder: builder,
+		logger:  logger,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start connects to the combined aggTrade stream in the background
+func (s *AggTradeStream) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running || len(s.symbols) == 0 {
+		return nil
+	}
+	s.running = true
+
+	go serveWithReconnect(ctx, s.stopCh, "aggTrade", s.logger, func() (chan struct{}, chan struct{}, error) {
+		return futures.WsCombinedAggTradeServe(s.symbols, s.handle, s.handleError)
+	})
+
+	return nil
+}
+
+// Stop closes the stream
+func (s *AggTradeStream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+func (s *AggTradeStream) handle(event *futures.WsAggTradeEvent) {
+	price, err := strconv.ParseFloat(event.Price, 64)
+	if err != nil {
+		return
+	}
+	qty, err := strconv.ParseFloat(event.Quantity, 64)
+	if err != nil {
+		return
+	}
+
+	s.builder.Add(footprint.Trade{
+		Symbol:     event.Symbol,
+		Price:      price,
+		Quantity:   qty,
+		BuyerMaker: event.Maker,
+		Time:       time.UnixMilli(event.TradeTime),
+	})
+}
+
+func (s *AggTradeStream) handleError(err error) {
+	s.logger.WithError(err).Warn("agg_trade_stream_error")
+}
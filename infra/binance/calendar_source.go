@@ -0,0 +1,78 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/calendar"
+)
+
+// systemStatusURL reports Binance-wide maintenance; futures has no status
+// endpoint of its own
+const systemStatusURL = "https://api.binance.com/sapi/v1/system/status"
+
+// FuturesCalendarSource reads the Binance system status and the status of
+// every futures contract for the trading calendar
+type FuturesCalendarSource struct {
+	client    *futures.Client
+	http      *http.Client
+	statusURL string
+}
+
+// NewFuturesCalendarSource creates a calendar source backed by a futures
+// client. An empty statusURL skips the system status, as on testnet.
+func NewFuturesCalendarSource(client *futures.Client, statusURL string) *FuturesCalendarSource {
+	return &FuturesCalendarSource{client: client, http: client.HTTPClient, statusURL: statusURL}
+}
+
+// NewMainnetCalendarSource creates a calendar source that also checks the
+// Binance system status
+func NewMainnetCalendarSource(client *futures.Client) *FuturesCalendarSource {
+	return NewFuturesCalendarSource(client, systemStatusURL)
+}
+
+// SystemStatus returns whether Binance reports system maintenance
+func (s *FuturesCalendarSource) SystemStatus(ctx context.Context) (calendar.SystemStatus, error) {
+	if s.statusURL == "" {
+		return calendar.SystemStatus{}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.statusURL, nil)
+	if err != nil {
+		return calendar.SystemStatus{}, err
+	}
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return calendar.SystemStatus{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return calendar.SystemStatus{}, fmt.Errorf("system status: %s", resp.Status)
+	}
+	var body struct {
+		Status int    `json:"status"`
+		Msg    string `json:"msg"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return calendar.SystemStatus{}, err
+	}
+	return calendar.SystemStatus{Maintenance: body.Status != 0, Message: body.Msg}, nil
+}
+
+// SymbolStatuses returns the contract status of every futures symbol
+func (s *FuturesCalendarSource) SymbolStatuses(ctx context.Context) (map[string]string, error) {
+	info, err := s.client.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]string, len(info.Symbols))
+	for _, sym := range info.Symbols {
+		statuses[sym.Symbol] = sym.Status
+	}
+	return statuses, nil
+}
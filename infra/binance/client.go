@@ -77,6 +77,7 @@ func (c *Client) CreateOrder(ctx context.Context, order *trade.Order) (*trade.Or
 	params.Set("side", string(order.Side))
 	params.Set("type", string(order.Type))
 	params.Set("quantity", strconv.FormatFloat(order.Quantity, 'f', -1, 64))
+	params.Set("newClientOrderId", NewClientOrderID())
 
 	if order.Type == trade.OrderTypeLimit {
 		params.Set("price", strconv.FormatFloat(order.Price, 'f', -1, 64))
@@ -413,6 +414,7 @@ func (c *Client) ClosePosition(ctx context.Context, position *trade.Position) er
 	params.Set("type", "MARKET")
 	params.Set("quantity", strconv.FormatFloat(position.Quantity, 'f', -1, 64))
 	params.Set("reduceOnly", "true")
+	params.Set("newClientOrderId", NewClientOrderID())
 	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
 	params.Set("recvWindow", "5000")
 
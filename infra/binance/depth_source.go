@@ -0,0 +1,62 @@
+package binance
+
+import (
+	"context"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/bookarchive"
+	"github.com/britej3/gobot/services/orderbook"
+)
+
+// FuturesDepthSource reads L2 order book snapshots through the go-binance
+// futures client
+type FuturesDepthSource struct {
+	client *futures.Client
+}
+
+// NewFuturesDepthSource creates a depth source backed by a futures client
+func NewFuturesDepthSource(client *futures.Client) *FuturesDepthSource {
+	return &FuturesDepthSource{client: client}
+}
+
+// Depth returns the best limit levels on each side. Binance accepts limits
+// of 5, 10, 20, 50, 100, 500 and 1000.
+func (s *FuturesDepthSource) Depth(ctx context.Context, symbol string, limit int) (bookarchive.Book, error) {
+	res, err := s.client.NewDepthService().Symbol(symbol).Limit(limit).Do(ctx)
+	if err != nil {
+		return bookarchive.Book{}, err
+	}
+
+	book := bookarchive.Book{
+		Bids: make([]bookarchive.Level, 0, len(res.Bids)),
+		Asks: make([]bookarchive.Level, 0, len(res.Asks)),
+	}
+	for _, b := range res.Bids {
+		book.Bids = append(book.Bids, bookarchive.Level{Price: parseFloat(b.Price), Quantity: parseFloat(b.Quantity)})
+	}
+	for _, a := range res.Asks {
+		book.Asks = append(book.Asks, bookarchive.Level{Price: parseFloat(a.Price), Quantity: parseFloat(a.Quantity)})
+	}
+	return book, nil
+}
+
+// Snapshot returns a depth snapshot with the update ID local books sync from
+func (s *FuturesDepthSource) Snapshot(ctx context.Context, symbol string, limit int) (orderbook.Snapshot, error) {
+	res, err := s.client.NewDepthService().Symbol(symbol).Limit(limit).Do(ctx)
+	if err != nil {
+		return orderbook.Snapshot{}, err
+	}
+
+	snap := orderbook.Snapshot{
+		LastUpdateID: res.LastUpdateID,
+		Bids:         make([]orderbook.Level, 0, len(res.Bids)),
+		Asks:         make([]orderbook.Level, 0, len(res.Asks)),
+	}
+	for _, b := range res.Bids {
+		snap.Bids = append(snap.Bids, orderbook.Level{Price: parseFloat(b.Price), Quantity: parseFloat(b.Quantity)})
+	}
+	for _, a := range res.Asks {
+		snap.Asks = append(snap.Asks, orderbook.Level{Price: parseFloat(a.Price), Quantity: parseFloat(a.Quantity)})
+	}
+	return snap, nil
+}
@@ -0,0 +1,87 @@
+package binance
+
+import (
+	"context"
+	"sync"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/orderbook"
+	"github.com/sirupsen/logrus"
+)
+
+// DepthStream feeds the depth diff stream of a set of symbols into local
+// order books
+type DepthStream struct {
+	symbols []string
+	books   *orderbook.Books
+	logger  *logrus.Logger
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+}
+
+// NewDepthStream creates a stream that applies depth diffs to books
+func NewDepthStream(symbols []string, books *orderbook.Books) *DepthStream {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	return &DepthStream{
+		symbols: symbols,
+		books:   books,
+		logger:  logger,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start connects to the combined diff depth stream in the background. A
+// reconnect skips updates, which the books detect as a gap and resync.
+func (s *DepthStream) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running || len(s.symbols) == 0 {
+		return nil
+	}
+	s.running = true
+
+	go serveWithReconnect(ctx, s.stopCh, "depth", s.logger, func() (chan struct{}, chan struct{}, error) {
+		return futures.WsCombinedDiffDepthServe(s.symbols, s.handle, s.handleError)
+	})
+
+	return nil
+}
+
+// Stop closes the stream
+func (s *DepthStream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+func (s *DepthStream) handle(event *futures.WsDepthEvent) {
+	diff := orderbook.Diff{
+		Symbol:      event.Symbol,
+		FirstID:     event.FirstUpdateID,
+		FinalID:     event.LastUpdateID,
+		PrevFinalID: event.PrevLastUpdateID,
+		Bids:        make([]orderbook.Level, 0, len(event.Bids)),
+		Asks:        make([]orderbook.Level, 0, len(event.Asks)),
+	}
+	for _, b := range event.Bids {
+		diff.Bids = append(diff.Bids, orderbook.Level{Price: parseFloat(b.Price), Quantity: parseFloat(b.Quantity)})
+	}
+	for _, a := range event.Asks {
+		diff.Asks = append(diff.Asks, orderbook.Level{Price: parseFloat(a.Price), Quantity: parseFloat(a.Quantity)})
+	}
+	s.books.Apply(diff)
+}
+
+func (s *DepthStream) handleError(err error) {
+	s.logger.WithError(err).Warn("depth_stream_error")
+}
@@ -0,0 +1,78 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/drift"
+)
+
+// stopOrderTypes are the order types that stop a position out
+var stopOrderTypes = map[string]bool{"STOP": true, "STOP_MARKET": true, "TRAILING_STOP_MARKET": true}
+
+// FuturesDriftSource reads positions and stop orders for the drift checker
+type FuturesDriftSource struct {
+	client *futures.Client
+}
+
+// NewFuturesDriftSource creates a drift source backed by a futures client
+func NewFuturesDriftSource(client *futures.Client) *FuturesDriftSource {
+	return &FuturesDriftSource{client: client}
+}
+
+// Positions returns every position risk row, flat ones included for their leverage
+func (s *FuturesDriftSource) Positions(ctx context.Context) ([]drift.Position, error) {
+	risks, err := s.client.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]drift.Position, 0, len(risks))
+	for _, r := range risks {
+		size, _ := strconv.ParseFloat(r.PositionAmt, 64)
+		if r.PositionSide == "SHORT" && size > 0 {
+			size = -size
+		}
+		leverage, _ := strconv.Atoi(r.Leverage)
+		positions = append(positions, drift.Position{Symbol: r.Symbol, Size: size, Leverage: leverage})
+	}
+	return positions, nil
+}
+
+// Stops returns the open stop orders that close or reduce a position, both
+// plain orders and the conditional algo orders Binance now keeps apart
+func (s *FuturesDriftSource) Stops(ctx context.Context) ([]drift.Stop, error) {
+	orders, err := s.client.NewListOpenOrdersService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	algos, err := s.client.NewListOpenAlgoOrdersService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var stops []drift.Stop
+	for _, o := range orders {
+		if stopOrderTypes[string(o.Type)] && closes(o.Side, o.PositionSide, o.ClosePosition || o.ReduceOnly) {
+			stops = append(stops, drift.Stop{Symbol: o.Symbol, Side: string(o.Side)})
+		}
+	}
+	for _, o := range algos {
+		if stopOrderTypes[string(o.OrderType)] && closes(o.Side, o.PositionSide, o.ClosePosition || o.ReduceOnly) {
+			stops = append(stops, drift.Stop{Symbol: o.Symbol, Side: string(o.Side)})
+		}
+	}
+	return stops, nil
+}
+
+// closes reports whether an order reduces a position rather than opening
+// one. In hedge mode that is a SELL on the long side or a BUY on the short.
+func closes(side futures.SideType, positionSide futures.PositionSideType, reduceOnly bool) bool {
+	switch positionSide {
+	case futures.PositionSideTypeLong:
+		return side == futures.SideTypeSell
+	case futures.PositionSideTypeShort:
+		return side == futures.SideTypeBuy
+	}
+	return reduceOnly
+}
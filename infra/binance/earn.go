@@ -0,0 +1,133 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	gobinance "github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/earnsweep"
+)
+
+// FuturesEarnAccount moves idle futures margin into Simple Earn flexible
+// products through the spot wallet, and back
+type FuturesEarnAccount struct {
+	spot    *gobinance.Client
+	futures *futures.Client
+
+	mu       sync.Mutex
+	products map[string]string
+}
+
+// NewFuturesEarnAccount creates an earn adapter. The API key needs universal
+// transfer and Simple Earn permissions in addition to futures trading.
+func NewFuturesEarnAccount(spot *gobinance.Client, fut *futures.Client) *FuturesEarnAccount {
+	return &FuturesEarnAccount{spot: spot, futures: fut, products: make(map[string]string)}
+}
+
+// FuturesMargin returns wallet and available balance of asset in the USDⓈ-M account
+func (a *FuturesEarnAccount) FuturesMargin(ctx context.Context, asset string) (earnsweep.Margin, error) {
+	acct, err := a.futures.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return earnsweep.Margin{}, err
+	}
+	for _, as := range acct.Assets {
+		if as.Asset == asset {
+			wallet, _ := strconv.ParseFloat(as.WalletBalance, 64)
+			available, _ := strconv.ParseFloat(as.AvailableBalance, 64)
+			return earnsweep.Margin{Wallet: wallet, Available: available}, nil
+		}
+	}
+	return earnsweep.Margin{}, nil
+}
+
+// EarnPosition returns the flexible earn holding of asset and its cumulative rewards
+func (a *FuturesEarnAccount) EarnPosition(ctx context.Context, asset string) (earnsweep.EarnPosition, error) {
+	res, err := a.spot.NewSimpleEarnService().FlexibleService().GetPosition().Asset(asset).Do(ctx)
+	if err != nil {
+		return earnsweep.EarnPosition{}, err
+	}
+
+	var pos earnsweep.EarnPosition
+	for _, row := range res.Rows {
+		amount, _ := strconv.ParseFloat(row.TotalAmount, 64)
+		rewards, _ := strconv.ParseFloat(row.CumulativeTotalRewards, 64)
+		pos.Amount += amount
+		pos.Rewards += rewards
+	}
+	return pos, nil
+}
+
+// Sweep transfers amount from futures to spot and subscribes it to flexible earn
+func (a *FuturesEarnAccount) Sweep(ctx context.Context, asset string, amount float64) error {
+	productID, err := a.product(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	qty := formatAmount(amount)
+	if _, err := a.spot.NewUserUniversalTransferService().
+		Type(gobinance.UserUniversalTransferTypeUmFuturesToMain).
+		Asset(asset).Amount(qty).Do(ctx); err != nil {
+		return fmt.Errorf("futures to spot transfer failed: %w", err)
+	}
+
+	if _, err := a.spot.NewSimpleEarnService().FlexibleService().Subscribe().
+		ProductId(productID).Amount(qty).
+		SourceAccount(gobinance.SourceAccountSpot).Do(ctx); err != nil {
+		// Put the funds back rather than leave them idle in spot.
+		a.spot.NewUserUniversalTransferService().
+			Type(gobinance.UserUniversalTransferTypeMainToUmFutures).
+			Asset(asset).Amount(qty).Do(ctx)
+		return fmt.Errorf("earn subscribe failed: %w", err)
+	}
+	return nil
+}
+
+// Recall redeems amount from flexible earn to spot and transfers it to futures
+func (a *FuturesEarnAccount) Recall(ctx context.Context, asset string, amount float64) error {
+	productID, err := a.product(ctx, asset)
+	if err != nil {
+		return err
+	}
+
+	qty := formatAmount(amount)
+	if _, err := a.spot.NewSimpleEarnService().FlexibleService().Redeem().
+		ProductId(productID).Amount(qty).DestAccount("SPOT").Do(ctx); err != nil {
+		return fmt.Errorf("earn redeem failed: %w", err)
+	}
+
+	if _, err := a.spot.NewUserUniversalTransferService().
+		Type(gobinance.UserUniversalTransferTypeMainToUmFutures).
+		Asset(asset).Amount(qty).Do(ctx); err != nil {
+		return fmt.Errorf("spot to futures transfer failed: %w", err)
+	}
+	return nil
+}
+
+func (a *FuturesEarnAccount) product(ctx context.Context, asset string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if id, ok := a.products[asset]; ok {
+		return id, nil
+	}
+
+	res, err := a.spot.NewSimpleEarnService().FlexibleService().ListProduct().Asset(asset).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range res.Rows {
+		if p.CanPurchase && p.CanRedeem && !p.IsSoldOut {
+			a.products[asset] = p.ProductId
+			return p.ProductId, nil
+		}
+	}
+	return "", fmt.Errorf("no flexible earn product available for %s", asset)
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
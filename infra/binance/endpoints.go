@@ -0,0 +1,244 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultEndpoints are the futures REST clusters benchmarked when endpoint
+// selection is enabled without an explicit list
+var DefaultEndpoints = []string{
+	"https://fapi.binance.com",
+	"https://fapi1.binance.com",
+	"https://fapi2.binance.com",
+	"https://fapi3.binance.com",
+}
+
+type EndpointConfig struct {
+	URLs          []string
+	ProbeInterval time.Duration
+	ProbeTimeout  time.Duration
+}
+
+// EndpointStatus is the last known state of one endpoint. Latency is
+// smoothed across probes so a single slow ping does not flip the route.
+type EndpointStatus struct {
+	URL       string
+	Latency   time.Duration
+	Healthy   bool
+	Failures  int
+	LastError string
+	CheckedAt time.Time
+}
+
+// EndpointRouter benchmarks a set of REST base URLs by pinging them at
+// startup and periodically, and routes requests to the fastest healthy one.
+type EndpointRouter struct {
+	cfg    EndpointConfig
+	client *http.Client
+
+	mu       sync.RWMutex
+	status   []EndpointStatus
+	best     int
+	switches int
+	running  bool
+	stopCh   chan struct{}
+}
+
+// latencyWeight is how much a new probe moves the smoothed latency
+const latencyWeight = 0.3
+
+func NewEndpointRouter(cfg EndpointConfig) *EndpointRouter {
+	if len(cfg.URLs) == 0 {
+		cfg.URLs = DefaultEndpoints
+	}
+	if cfg.ProbeInterval == 0 {
+		cfg.ProbeInterval = time.Minute
+	}
+	if cfg.ProbeTimeout == 0 {
+		cfg.ProbeTimeout = 2 * time.Second
+	}
+
+	status := make([]EndpointStatus, len(cfg.URLs))
+	for i, u := range cfg.URLs {
+		// Unprobed endpoints count as healthy so requests have a route
+		// before the first benchmark finishes.
+		status[i] = EndpointStatus{URL: u, Healthy: true}
+	}
+	return &EndpointRouter{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.ProbeTimeout},
+		status: status,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Best returns the base URL requests should go to
+func (r *EndpointRouter) Best() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.status[r.best].URL
+}
+
+// Status returns a snapshot of every endpoint
+func (r *EndpointRouter) Status() []EndpointStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]EndpointStatus, len(r.status))
+	copy(out, r.status)
+	return out
+}
+
+// Switches returns how often the route moved to another endpoint
+func (r *EndpointRouter) Switches() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.switches
+}
+
+// Start benchmarks every endpoint before returning, then keeps probing in
+// the background every ProbeInterval.
+func (r *EndpointRouter) Start(ctx context.Context) error {
+	r.mu.Lock()
+	if r.running {
+		r.mu.Unlock()
+		return nil
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	r.Probe(ctx)
+	go r.run(ctx)
+	return nil
+}
+
+func (r *EndpointRouter) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.running {
+		return nil
+	}
+	r.running = false
+	close(r.stopCh)
+	return nil
+}
+
+func (r *EndpointRouter) run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.Probe(ctx)
+		}
+	}
+}
+
+// Probe pings every endpoint concurrently and reselects the route
+func (r *EndpointRouter) Probe(ctx context.Context) {
+	type result struct {
+		rtt time.Duration
+		err error
+	}
+	results := make([]result, len(r.cfg.URLs))
+
+	var wg sync.WaitGroup
+	for i, u := range r.cfg.URLs {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			rtt, err := r.ping(ctx, u)
+			results[i] = result{rtt, err}
+		}(i, u)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, res := range results {
+		s := &r.status[i]
+		s.CheckedAt = now
+		if res.err != nil {
+			s.Healthy = false
+			s.Failures++
+			s.LastError = res.err.Error()
+			continue
+		}
+		if s.Latency == 0 {
+			s.Latency = res.rtt
+		} else {
+			s.Latency += time.Duration(latencyWeight * float64(res.rtt-s.Latency))
+		}
+		s.Healthy = true
+		s.LastError = ""
+	}
+	r.reselect()
+}
+
+// MarkFailed takes an endpoint out of rotation after a request to it failed
+// in transport, until a later probe reaches it again.
+func (r *EndpointRouter) MarkFailed(url string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.status {
+		if r.status[i].URL != url {
+			continue
+		}
+		r.status[i].Healthy = false
+		r.status[i].Failures++
+		if err != nil {
+			r.status[i].LastError = err.Error()
+		}
+	}
+	r.reselect()
+}
+
+// reselect routes to the healthy endpoint with the lowest latency. With
+// none healthy, the route stays where it is. Callers hold r.mu.
+func (r *EndpointRouter) reselect() {
+	best := -1
+	for i, s := range r.status {
+		if !s.Healthy {
+			continue
+		}
+		if best < 0 || s.Latency < r.status[best].Latency {
+			best = i
+		}
+	}
+	if best >= 0 && best != r.best {
+		r.best = best
+		r.switches++
+	}
+}
+
+func (r *EndpointRouter) ping(ctx context.Context, baseURL string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/fapi/v1/ping", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	rtt := time.Since(start)
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("ping returned %s", resp.Status)
+	}
+	return rtt, nil
+}
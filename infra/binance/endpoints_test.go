@@ -0,0 +1,43 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pingServer(delay time.Duration, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(status)
+	}))
+}
+
+func TestEndpointRouter_RoutesToFastestHealthy(t *testing.T) {
+	slow := pingServer(50*time.Millisecond, http.StatusOK)
+	defer slow.Close()
+	fast := pingServer(0, http.StatusOK)
+	defer fast.Close()
+	broken := pingServer(0, http.StatusServiceUnavailable)
+	defer broken.Close()
+
+	router := NewEndpointRouter(EndpointConfig{URLs: []string{slow.URL, broken.URL, fast.URL}})
+	router.Probe(context.Background())
+
+	assert.Equal(t, fast.URL, router.Best())
+	status := router.Status()
+	assert.False(t, status[1].Healthy)
+	assert.NotEmpty(t, status[1].LastError)
+
+	router.MarkFailed(fast.URL, errors.New("connection reset"))
+	assert.Equal(t, slow.URL, router.Best())
+	assert.Equal(t, 2, router.Switches())
+
+	router.Probe(context.Background())
+	assert.Equal(t, fast.URL, router.Best())
+}
@@ -0,0 +1,77 @@
+package binance
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/equity"
+)
+
+// FuturesEquitySource reads balances, margin and open positions from the
+// futures account endpoint
+type FuturesEquitySource struct {
+	client *futures.Client
+}
+
+// NewFuturesEquitySource creates an equity source backed by a futures client
+func NewFuturesEquitySource(client *futures.Client) *FuturesEquitySource {
+	return &FuturesEquitySource{client: client}
+}
+
+// Snapshot returns the account state in a single request
+func (s *FuturesEquitySource) Snapshot(ctx context.Context) (equity.Snapshot, error) {
+	acct, err := s.client.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return equity.Snapshot{}, err
+	}
+
+	snap := equity.Snapshot{
+		Time:          time.Now(),
+		Balance:       parseFloat(acct.TotalWalletBalance),
+		Equity:        parseFloat(acct.TotalMarginBalance),
+		UnrealizedPnL: parseFloat(acct.TotalUnrealizedProfit),
+		InitialMargin: parseFloat(acct.TotalInitialMargin),
+		MaintMargin:   parseFloat(acct.TotalMaintMargin),
+		Available:     parseFloat(acct.AvailableBalance),
+	}
+	for _, p := range acct.Positions {
+		amt := parseFloat(p.PositionAmt)
+		if amt == 0 {
+			continue
+		}
+		side := "LONG"
+		if amt < 0 {
+			side = "SHORT"
+		}
+		snap.Positions = append(snap.Positions, equity.Position{
+			Symbol:        p.Symbol,
+			Side:          side,
+			Size:          math.Abs(amt),
+			EntryPrice:    parseFloat(p.EntryPrice),
+			Notional:      math.Abs(parseFloat(p.Notional)),
+			UnrealizedPnL: parseFloat(p.UnrealizedProfit),
+			Leverage:      parseFloat(p.Leverage),
+		})
+	}
+	return snap, nil
+}
+
+// ClosePosition closes p at market with a reduce-only order
+func (s *FuturesEquitySource) ClosePosition(ctx context.Context, p equity.Position) error {
+	side := futures.SideTypeSell
+	if p.Side == "SHORT" {
+		side = futures.SideTypeBuy
+	}
+	_, err := s.client.NewCreateOrderService().
+		Symbol(p.Symbol).
+		Side(side).
+		Type(futures.OrderTypeMarket).
+		Quantity(strconv.FormatFloat(p.Size, 'f', -1, 64)).
+		ReduceOnly(true).
+		NewClientOrderID(NewClientOrderID()).
+		Do(ctx)
+	return err
+}
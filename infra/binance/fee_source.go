@@ -0,0 +1,45 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/feetier"
+)
+
+// FuturesFeeSource reads the account's fee tier and commission rates
+type FuturesFeeSource struct {
+	client *futures.Client
+}
+
+// NewFuturesFeeSource creates a fee source backed by a futures client
+func NewFuturesFeeSource(client *futures.Client) *FuturesFeeSource {
+	return &FuturesFeeSource{client: client}
+}
+
+// Tier returns the account's VIP fee tier
+func (s *FuturesFeeSource) Tier(ctx context.Context) (int, error) {
+	cfg, err := s.client.NewGetAccountConfigService().Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return cfg.FeeTier, nil
+}
+
+// Rate returns the maker and taker commission the account pays on symbol
+func (s *FuturesFeeSource) Rate(ctx context.Context, symbol string) (feetier.Rate, error) {
+	res, err := s.client.NewCommissionRateService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return feetier.Rate{}, err
+	}
+	maker, err := strconv.ParseFloat(res.MakerCommissionRate, 64)
+	if err != nil {
+		return feetier.Rate{}, err
+	}
+	taker, err := strconv.ParseFloat(res.TakerCommissionRate, 64)
+	if err != nil {
+		return feetier.Rate{}, err
+	}
+	return feetier.Rate{Maker: maker, Taker: taker}, nil
+}
@@ -261,6 +261,7 @@ func (fc *FuturesClient) CreateOrder(ctx context.Context, order *FuturesOrder) (
 	startTime := time.Now()
 
 	service := fc.client.NewCreateOrderService().
+		NewClientOrderID(NewClientOrderID()).
 		Symbol(order.Symbol).
 		Side(order.Side).
 		Type(order.Type).
@@ -5,6 +5,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -16,6 +17,7 @@ import (
 	"time"
 
 	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/apiweight"
 	"github.com/britej3/gobot/pkg/circuitbreaker"
 	"golang.org/x/time/rate"
 )
@@ -30,14 +32,21 @@ type HardenedConfig struct {
 	Timeout           time.Duration
 	RecvWindow        time.Duration
 	SignatureVariance float64
+	// ReadOnly refuses every order placement and cancellation with
+	// ErrReadOnly before anything is sent.
+	ReadOnly bool
 }
 
+// ErrReadOnly is returned for account writes on a read-only client
+var ErrReadOnly = errors.New("client is read-only, account writes are disabled")
+
 type HardenedClient struct {
 	cfg            HardenedConfig
 	client         *http.Client
 	limiter        *rate.Limiter
 	circuitBreaker *circuitbreaker.CircuitBreaker
 	requestCache   *RequestCache
+	endpoints      *EndpointRouter
 	mu             sync.RWMutex
 	lastRequest    time.Time
 	minInterval    time.Duration
@@ -99,21 +108,65 @@ func NewHardenedClient(cfg HardenedConfig) *HardenedClient {
 	}
 }
 
+// SetEndpoints routes every request through the fastest healthy endpoint
+// instead of BaseURL
+func (c *HardenedClient) SetEndpoints(router *EndpointRouter) {
+	c.endpoints = router
+}
+
+// SetWeightBudget charges every request to component of budget
+func (c *HardenedClient) SetWeightBudget(budget *apiweight.Budget, component string) {
+	c.client = WithWeightBudget(c.client, budget, component)
+}
+
+// Endpoints returns the endpoint router, nil when requests go to BaseURL
+func (c *HardenedClient) Endpoints() *EndpointRouter {
+	return c.endpoints
+}
+
+func (c *HardenedClient) baseURL() string {
+	if c.endpoints != nil {
+		return c.endpoints.Best()
+	}
+	return c.cfg.BaseURL
+}
+
+// do sends req, taking its endpoint out of rotation when it cannot be
+// reached so the next request goes elsewhere
+func (c *HardenedClient) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil && c.endpoints != nil && req.Context().Err() == nil {
+		c.endpoints.MarkFailed(req.URL.Scheme+"://"+req.URL.Host, err)
+	}
+	return resp, err
+}
+
 func (c *HardenedClient) CreateOrder(ctx context.Context, order *trade.Order) (*trade.Order, error) {
+	if c.cfg.ReadOnly {
+		return nil, ErrReadOnly
+	}
 	return circuitbreaker.Execute(c.circuitBreaker, func() (*trade.Order, error) {
 		c.waitForRateLimit(ctx)
 
-		endpoint := fmt.Sprintf("%s/fapi/v1/order", c.cfg.BaseURL)
+		endpoint := fmt.Sprintf("%s/fapi/v1/order", c.baseURL())
 
 		params := url.Values{}
 		params.Set("symbol", order.Symbol)
 		params.Set("side", string(order.Side))
 		params.Set("type", string(order.Type))
 		params.Set("quantity", strconv.FormatFloat(order.Quantity, 'f', -1, 64))
+		if order.ClientOrderID == "" {
+			order.ClientOrderID = NewClientOrderID()
+		}
+		params.Set("newClientOrderId", order.ClientOrderID)
 
 		if order.Type == trade.OrderTypeLimit {
+			tif := trade.TimeInForceGTC
+			if order.TimeInForce != "" {
+				tif = order.TimeInForce
+			}
 			params.Set("price", strconv.FormatFloat(order.Price, 'f', -1, 64))
-			params.Set("timeInForce", "GTC")
+			params.Set("timeInForce", string(tif))
 		}
 
 		if order.StopLoss > 0 {
@@ -139,7 +192,7 @@ func (c *HardenedClient) CreateOrder(ctx context.Context, order *trade.Order) (*
 		req.Header.Set("X-MBX-APIKEY", c.cfg.APIKey)
 		req.Header.Set("X-MBX-USER-IP", c.getRandomIP())
 
-		resp, err := c.client.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -151,23 +204,10 @@ func (c *HardenedClient) CreateOrder(ctx context.Context, order *trade.Order) (*
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			return nil, c.parseError(respBody)
-		}
-
-		var result struct {
-			OrderID     int64   `json:"orderId"`
-			Symbol      string  `json:"symbol"`
-			Status      string  `json:"status"`
-			Side        string  `json:"side"`
-			Type        string  `json:"type"`
-			Price       float64 `json:"price"`
-			AvgPrice    float64 `json:"avgPrice"`
-			OrigQty     float64 `json:"origQty"`
-			ExecutedQty float64 `json:"executedQty"`
-			StopPrice   float64 `json:"stopPrice"`
-			UpdateTime  int64   `json:"updateTime"`
+			return nil, c.parseError(resp.StatusCode, respBody)
 		}
 
+		var result orderResponse
 		if err := json.Unmarshal(respBody, &result); err != nil {
 			return nil, fmt.Errorf("failed to parse response: %w", err)
 		}
@@ -183,20 +223,30 @@ func (c *HardenedClient) CreateOrder(ctx context.Context, order *trade.Order) (*
 }
 
 func (c *HardenedClient) GetOrder(ctx context.Context, orderID, symbol string) (*trade.Order, error) {
+	return c.getOrder(ctx, symbol, "orderId", orderID)
+}
+
+// GetOrderByClientID reads an order back by the client order ID it was
+// sent with, which is known even when the submission's response was lost
+func (c *HardenedClient) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*trade.Order, error) {
+	return c.getOrder(ctx, symbol, "origClientOrderId", clientOrderID)
+}
+
+func (c *HardenedClient) getOrder(ctx context.Context, symbol, idParam, id string) (*trade.Order, error) {
 	return circuitbreaker.Execute(c.circuitBreaker, func() (*trade.Order, error) {
 		c.waitForRateLimit(ctx)
 
-		cacheKey := fmt.Sprintf("order:%s:%s", symbol, orderID)
+		cacheKey := fmt.Sprintf("order:%s:%s:%s", symbol, idParam, id)
 		if cached := c.requestCache.Get(cacheKey); cached != nil {
 			if order, ok := cached.(*trade.Order); ok {
 				return order, nil
 			}
 		}
 
-		endpoint := fmt.Sprintf("%s/fapi/v1/order", c.cfg.BaseURL)
+		endpoint := fmt.Sprintf("%s/fapi/v1/order", c.baseURL())
 
 		params := url.Values{}
-		params.Set("orderId", orderID)
+		params.Set(idParam, id)
 		params.Set("symbol", symbol)
 		params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()+int64(rand.Float64()*100), 10))
 		params.Set("recvWindow", strconv.FormatInt(int64(c.cfg.RecvWindow.Milliseconds()), 10))
@@ -214,7 +264,7 @@ func (c *HardenedClient) GetOrder(ctx context.Context, orderID, symbol string) (
 		req.Header.Set("X-MBX-APIKEY", c.cfg.APIKey)
 		req.Header.Set("X-MBX-USER-IP", c.getRandomIP())
 
-		resp, err := c.client.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -226,53 +276,229 @@ func (c *HardenedClient) GetOrder(ctx context.Context, orderID, symbol string) (
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			return nil, c.parseError(respBody)
-		}
-
-		var result struct {
-			OrderID     int64   `json:"orderId"`
-			Symbol      string  `json:"symbol"`
-			Status      string  `json:"status"`
-			Side        string  `json:"side"`
-			Type        string  `json:"type"`
-			Price       float64 `json:"price"`
-			AvgPrice    float64 `json:"avgPrice"`
-			OrigQty     float64 `json:"origQty"`
-			ExecutedQty float64 `json:"executedQty"`
-			UpdateTime  int64   `json:"updateTime"`
+			return nil, c.parseError(resp.StatusCode, respBody)
 		}
 
+		var result orderResponse
 		if err := json.Unmarshal(respBody, &result); err != nil {
 			return nil, fmt.Errorf("failed to parse response: %w", err)
 		}
 
-		order := &trade.Order{
-			ID:           strconv.FormatInt(result.OrderID, 10),
-			Symbol:       result.Symbol,
-			Side:         trade.Side(result.Side),
-			Type:         trade.OrderType(result.Type),
-			Price:        result.Price,
-			AvgFillPrice: result.AvgPrice,
-			Quantity:     result.OrigQty,
-			FilledQty:    result.ExecutedQty,
-			Status:       trade.OrderStatus(result.Status),
-			UpdatedAt:    time.UnixMilli(result.UpdateTime),
-		}
+		order := result.toOrder()
 
-		c.requestCache.Set(cacheKey, order)
+		// Open orders change under us; only final states are safe to serve
+		// from cache.
+		if order.Status == trade.OrderStatusFilled {
+			c.requestCache.Set(cacheKey, order)
+		}
 
 		return order, nil
 	})
 }
 
+// CancelOrder cancels an open order and returns its final state, including
+// any quantity filled before the cancel
+func (c *HardenedClient) CancelOrder(ctx context.Context, symbol, orderID string) (*trade.Order, error) {
+	return c.cancelOrder(ctx, symbol, "orderId", orderID)
+}
+
+// CancelOrderByClientID cancels an open order by the client order ID it was
+// sent with and returns its final state
+func (c *HardenedClient) CancelOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*trade.Order, error) {
+	return c.cancelOrder(ctx, symbol, "origClientOrderId", clientOrderID)
+}
+
+func (c *HardenedClient) cancelOrder(ctx context.Context, symbol, idParam, id string) (*trade.Order, error) {
+	if c.cfg.ReadOnly {
+		return nil, ErrReadOnly
+	}
+	return circuitbreaker.Execute(c.circuitBreaker, func() (*trade.Order, error) {
+		c.waitForRateLimit(ctx)
+
+		params := url.Values{}
+		params.Set("symbol", symbol)
+		params.Set(idParam, id)
+		params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()+int64(rand.Float64()*100), 10))
+		params.Set("recvWindow", strconv.FormatInt(int64(c.cfg.RecvWindow.Milliseconds()), 10))
+		params.Set("signature", c.sign(params.Encode()))
+
+		endpoint := fmt.Sprintf("%s/fapi/v1/order?%s", c.baseURL(), params.Encode())
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("X-MBX-APIKEY", c.cfg.APIKey)
+		req.Header.Set("X-MBX-USER-IP", c.getRandomIP())
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, c.parseError(resp.StatusCode, respBody)
+		}
+
+		var result orderResponse
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		return result.toOrder(), nil
+	})
+}
+
+// BookTop returns the best bid and ask of symbol
+func (c *HardenedClient) BookTop(ctx context.Context, symbol string) (float64, float64, error) {
+	c.waitForRateLimit(ctx)
+
+	endpoint := fmt.Sprintf("%s/fapi/v1/ticker/bookTicker?symbol=%s", c.baseURL(), url.QueryEscape(symbol))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("X-MBX-USER-IP", c.getRandomIP())
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, c.parseError(resp.StatusCode, respBody)
+	}
+
+	var result struct {
+		BidPrice float64 `json:"bidPrice,string"`
+		AskPrice float64 `json:"askPrice,string"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.BidPrice, result.AskPrice, nil
+}
+
+// orderResponse is an order as returned by the order endpoints, which quote
+// prices and quantities as strings
+type orderResponse struct {
+	OrderID     int64   `json:"orderId"`
+	Symbol      string  `json:"symbol"`
+	Status      string  `json:"status"`
+	Side        string  `json:"side"`
+	Type        string  `json:"type"`
+	Price       float64 `json:"price,string"`
+	AvgPrice    float64 `json:"avgPrice,string"`
+	OrigQty     float64 `json:"origQty,string"`
+	ExecutedQty float64 `json:"executedQty,string"`
+	StopPrice   float64 `json:"stopPrice,string"`
+	UpdateTime  int64   `json:"updateTime"`
+}
+
+func (r orderResponse) toOrder() *trade.Order {
+	return &trade.Order{
+		ID:           strconv.FormatInt(r.OrderID, 10),
+		Symbol:       r.Symbol,
+		Side:         trade.Side(r.Side),
+		Type:         trade.OrderType(r.Type),
+		Price:        r.Price,
+		AvgFillPrice: r.AvgPrice,
+		Quantity:     r.OrigQty,
+		FilledQty:    r.ExecutedQty,
+		Status:       trade.OrderStatus(r.Status),
+		UpdatedAt:    time.UnixMilli(r.UpdateTime),
+	}
+}
+
 func (c *HardenedClient) GetPosition(ctx context.Context, symbol string) (*trade.Position, error) {
-	return circuitbreaker.Execute(c.circuitBreaker, func() (*trade.Position, error) {
+	rows, err := c.positionRisk(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pos := range rows {
+		if pos.Symbol == symbol && pos.PositionAmt != 0 {
+			side := trade.SideBuy
+			if pos.PositionSide == "SHORT" {
+				side = trade.SideSell
+			}
+
+			pnlPercent := 0.0
+			if pos.EntryPrice > 0 {
+				pnlPercent = (pos.MarkPrice - pos.EntryPrice) / pos.EntryPrice * 100
+				if side == trade.SideSell {
+					pnlPercent = -pnlPercent
+				}
+			}
+
+			return &trade.Position{
+				Symbol:       symbol,
+				Side:         side,
+				Quantity:     pos.PositionAmt,
+				EntryPrice:   pos.EntryPrice,
+				CurrentPrice: pos.MarkPrice,
+				PnL:          pos.UnRealizedProfit,
+				PnLPercent:   pnlPercent,
+				UpdatedAt:    time.Now(),
+			}, nil
+		}
+	}
+
+	return nil, trade.ErrPositionNotFound
+}
+
+// PositionRisk is a row of the position risk endpoint. PositionAmt is
+// negative for shorts in one-way mode.
+type PositionRisk struct {
+	Symbol           string  `json:"symbol"`
+	PositionSide     string  `json:"positionSide"`
+	PositionAmt      float64 `json:"positionAmt,string"`
+	EntryPrice       float64 `json:"entryPrice,string"`
+	MarkPrice        float64 `json:"markPrice,string"`
+	UnRealizedProfit float64 `json:"unRealizedProfit,string"`
+	LiquidationPrice float64 `json:"liquidationPrice,string"`
+	Leverage         float64 `json:"leverage,string"`
+	Notional         float64 `json:"notional,string"`
+}
+
+// OpenPositions returns the position risk of every symbol with a non-zero position
+func (c *HardenedClient) OpenPositions(ctx context.Context) ([]PositionRisk, error) {
+	rows, err := c.positionRisk(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	open := rows[:0]
+	for _, row := range rows {
+		if row.PositionAmt != 0 {
+			open = append(open, row)
+		}
+	}
+	return open, nil
+}
+
+func (c *HardenedClient) positionRisk(ctx context.Context, symbol string) ([]PositionRisk, error) {
+	return circuitbreaker.Execute(c.circuitBreaker, func() ([]PositionRisk, error) {
 		c.waitForRateLimit(ctx)
 
-		endpoint := fmt.Sprintf("%s/fapi/v2/positionRisk", c.cfg.BaseURL)
+		endpoint := fmt.Sprintf("%s/fapi/v2/positionRisk", c.baseURL())
 
 		params := url.Values{}
-		params.Set("symbol", symbol)
+		if symbol != "" {
+			params.Set("symbol", symbol)
+		}
 		params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()+int64(rand.Float64()*100), 10))
 		params.Set("recvWindow", strconv.FormatInt(int64(c.cfg.RecvWindow.Milliseconds()), 10))
 
@@ -289,7 +515,7 @@ func (c *HardenedClient) GetPosition(ctx context.Context, symbol string) (*trade
 		req.Header.Set("X-MBX-APIKEY", c.cfg.APIKey)
 		req.Header.Set("X-MBX-USER-IP", c.getRandomIP())
 
-		resp, err := c.client.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -301,51 +527,14 @@ func (c *HardenedClient) GetPosition(ctx context.Context, symbol string) (*trade
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			return nil, c.parseError(respBody)
-		}
-
-		var result []struct {
-			Symbol           string  `json:"symbol"`
-			PositionSide     string  `json:"positionSide"`
-			PositionAmt      float64 `json:"positionAmt"`
-			EntryPrice       float64 `json:"entryPrice"`
-			MarkPrice        float64 `json:"markPrice"`
-			UnRealizedProfit float64 `json:"unRealizedProfit"`
+			return nil, c.parseError(resp.StatusCode, respBody)
 		}
 
+		var result []PositionRisk
 		if err := json.Unmarshal(respBody, &result); err != nil {
 			return nil, fmt.Errorf("failed to parse response: %w", err)
 		}
-
-		for _, pos := range result {
-			if pos.Symbol == symbol && pos.PositionAmt != 0 {
-				side := trade.SideBuy
-				if pos.PositionSide == "SHORT" {
-					side = trade.SideSell
-				}
-
-				pnlPercent := 0.0
-				if pos.EntryPrice > 0 {
-					pnlPercent = (pos.MarkPrice - pos.EntryPrice) / pos.EntryPrice * 100
-					if side == trade.SideSell {
-						pnlPercent = -pnlPercent
-					}
-				}
-
-				return &trade.Position{
-					Symbol:       symbol,
-					Side:         side,
-					Quantity:     pos.PositionAmt,
-					EntryPrice:   pos.EntryPrice,
-					CurrentPrice: pos.MarkPrice,
-					PnL:          pos.UnRealizedProfit,
-					PnLPercent:   pnlPercent,
-					UpdatedAt:    time.Now(),
-				}, nil
-			}
-		}
-
-		return nil, trade.ErrPositionNotFound
+		return result, nil
 	})
 }
 
@@ -353,7 +542,7 @@ func (c *HardenedClient) GetBalance(ctx context.Context) (float64, error) {
 	return circuitbreaker.Execute(c.circuitBreaker, func() (float64, error) {
 		c.waitForRateLimit(ctx)
 
-		endpoint := fmt.Sprintf("%s/fapi/v2/balance", c.cfg.BaseURL)
+		endpoint := fmt.Sprintf("%s/fapi/v2/balance", c.baseURL())
 
 		params := url.Values{}
 		params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()+int64(rand.Float64()*100), 10))
@@ -372,7 +561,7 @@ func (c *HardenedClient) GetBalance(ctx context.Context) (float64, error) {
 		req.Header.Set("X-MBX-APIKEY", c.cfg.APIKey)
 		req.Header.Set("X-MBX-USER-IP", c.getRandomIP())
 
-		resp, err := c.client.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return 0, err
 		}
@@ -384,12 +573,12 @@ func (c *HardenedClient) GetBalance(ctx context.Context) (float64, error) {
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			return 0, c.parseError(respBody)
+			return 0, c.parseError(resp.StatusCode, respBody)
 		}
 
 		var result []struct {
 			Asset   string  `json:"asset"`
-			Balance float64 `json:"balance"`
+			Balance float64 `json:"balance,string"`
 		}
 
 		if err := json.Unmarshal(respBody, &result); err != nil {
@@ -416,7 +605,7 @@ func (c *HardenedClient) Price(ctx context.Context, symbol string) (float64, err
 
 	c.waitForRateLimit(ctx)
 
-	endpoint := fmt.Sprintf("%s/fapi/v1/ticker/price", c.cfg.BaseURL)
+	endpoint := fmt.Sprintf("%s/fapi/v1/ticker/price", c.baseURL())
 
 	params := url.Values{}
 	params.Set("symbol", symbol)
@@ -430,7 +619,7 @@ func (c *HardenedClient) Price(ctx context.Context, symbol string) (float64, err
 
 	req.Header.Set("X-MBX-USER-IP", c.getRandomIP())
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -442,7 +631,7 @@ func (c *HardenedClient) Price(ctx context.Context, symbol string) (float64, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return 0, c.parseError(respBody)
+		return 0, c.parseError(resp.StatusCode, respBody)
 	}
 
 	var result struct {
@@ -461,7 +650,7 @@ func (c *HardenedClient) Price(ctx context.Context, symbol string) (float64, err
 func (c *HardenedClient) Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
 	c.waitForRateLimit(ctx)
 
-	endpoint := fmt.Sprintf("%s/fapi/v1/klines", c.cfg.BaseURL)
+	endpoint := fmt.Sprintf("%s/fapi/v1/klines", c.baseURL())
 
 	params := url.Values{}
 	params.Set("symbol", symbol)
@@ -475,7 +664,7 @@ func (c *HardenedClient) Kline(ctx context.Context, symbol, interval string, lim
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -487,7 +676,7 @@ func (c *HardenedClient) Kline(ctx context.Context, symbol, interval string, lim
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.parseError(respBody)
+		return nil, c.parseError(resp.StatusCode, respBody)
 	}
 
 	var raw [][]interface{}
@@ -537,15 +726,69 @@ func (c *HardenedClient) getRandomIP() string {
 	return fmt.Sprintf("192.168.%d.%d", rand.Intn(256), rand.Intn(256))
 }
 
-func (c *HardenedClient) parseError(respBody []byte) error {
+func (c *HardenedClient) parseError(status int, respBody []byte) error {
 	var errResp struct {
 		Code int64  `json:"code"`
 		Msg  string `json:"msg"`
 	}
 	if err := json.Unmarshal(respBody, &errResp); err != nil {
-		return fmt.Errorf("unknown error: %s", string(respBody))
+		return fmt.Errorf("unknown error (HTTP %d): %s", status, string(respBody))
+	}
+	return &APIError{Code: errResp.Code, Msg: errResp.Msg, Status: status}
+}
+
+// APIError is an error response returned by the Binance API
+type APIError struct {
+	Code int64
+	Msg  string
+	// Status is the HTTP status of the response, when known.
+	Status int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("binance API error %d: %s", e.Code, e.Msg)
+}
+
+// IsPrecisionError reports whether an order was rejected for too many
+// decimals (-1111) or a price off the tick size (-4014), both of which are
+// fixed by re-rounding to the symbol's exchange filters
+func IsPrecisionError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.Code == -1111 || apiErr.Code == -4014)
+}
+
+// IsPostOnlyRejection reports whether err is the -5022 rejection of a GTX
+// order that would have taken liquidity
+func IsPostOnlyRejection(err error) bool {
+	return ErrorCode(err) == -5022
+}
+
+// IsRejection reports whether err is the exchange turning a request down
+// with a 4xx response, or the client refusing to send it, so nothing it
+// asked for took effect. A 5xx, a transport error or a timeout leaves the
+// outcome unknown: the exchange may still have acted on it
+func IsRejection(err error) bool {
+	if errors.Is(err, ErrReadOnly) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status >= 400 && apiErr.Status < 500
+}
+
+// IsUnknownOrder reports whether err is the -2013 answer to a lookup of an
+// order the exchange has no record of
+func IsUnknownOrder(err error) bool {
+	return ErrorCode(err) == -2013
+}
+
+// ErrorCode returns the Binance error code wrapped in err, or 0 if err is not
+// an API error
+func ErrorCode(err error) int64 {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
 	}
-	return fmt.Errorf("binance API error %d: %s", errResp.Code, errResp.Msg)
+	return 0
 }
 
 func (c *RequestCache) Get(key string) interface{} {
@@ -0,0 +1,69 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gobinance "github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/keyhealth"
+)
+
+// keyRejectionCodes are the Binance errors that mean the key itself is
+// refused: -2015 invalid key, IP or permissions, -2014 malformed key, -2008
+// unknown key ID, -1022 bad signature and -1002 unauthorized
+var keyRejectionCodes = map[int64]bool{-2015: true, -2014: true, -2008: true, -1022: true, -1002: true}
+
+// KeyHealthSource checks the API key with a signed futures balance call and
+// reads its permissions from the spot API key endpoint, which futures does
+// not have
+type KeyHealthSource struct {
+	futures *futures.Client
+	spot    *gobinance.Client
+}
+
+// NewKeyHealthSource creates a key health source. A nil spot client, as on
+// testnet where the endpoint does not exist, leaves the permissions unknown.
+func NewKeyHealthSource(client *futures.Client, spot *gobinance.Client) *KeyHealthSource {
+	return &KeyHealthSource{futures: client, spot: spot}
+}
+
+// Ping makes the cheapest signed futures call
+func (s *KeyHealthSource) Ping(ctx context.Context) error {
+	_, err := s.futures.NewGetBalanceService().Do(ctx)
+	return keyError(err)
+}
+
+// Permissions reads the key's permission flags and trading expiry
+func (s *KeyHealthSource) Permissions(ctx context.Context) (keyhealth.Permissions, error) {
+	if s.spot == nil {
+		return keyhealth.Permissions{}, nil
+	}
+	p, err := s.spot.NewGetAPIKeyPermission().Do(ctx)
+	if err != nil {
+		return keyhealth.Permissions{}, keyError(err)
+	}
+	perms := keyhealth.Permissions{
+		Known:        true,
+		Futures:      p.EnableFutures,
+		Reading:      p.EnableReading,
+		IPRestricted: p.IPRestrict,
+		Created:      time.UnixMilli(int64(p.CreateTime)),
+	}
+	if p.TradingAuthorityExpirationTime > 0 {
+		perms.Expires = time.UnixMilli(int64(p.TradingAuthorityExpirationTime))
+	}
+	return perms, nil
+}
+
+// keyError wraps a key rejection in keyhealth.ErrRejected
+func keyError(err error) error {
+	var apiErr *common.APIError
+	if errors.As(err, &apiErr) && keyRejectionCodes[apiErr.Code] {
+		return fmt.Errorf("%w: %v", keyhealth.ErrRejected, err)
+	}
+	return err
+}
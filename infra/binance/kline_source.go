@@ -0,0 +1,87 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// FuturesKlineSource fetches candles through the go-binance futures client
+// and converts them to domain klines
+type FuturesKlineSource struct {
+	client *futures.Client
+}
+
+// NewFuturesKlineSource creates a kline source backed by a futures client
+func NewFuturesKlineSource(client *futures.Client) *FuturesKlineSource {
+	return &FuturesKlineSource{client: client}
+}
+
+// Kline returns the latest limit candles for symbol, oldest first
+func (s *FuturesKlineSource) Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	raw, err := s.client.NewKlinesService().
+		Symbol(symbol).
+		Interval(interval).
+		Limit(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ConvertKlines(raw), nil
+}
+
+// Range returns the closed candles of symbol between start and end, oldest
+// first, paging through the 1500-candle request limit
+func (s *FuturesKlineSource) Range(ctx context.Context, symbol, interval string, start, end time.Time) ([]trade.Kline, error) {
+	var klines []trade.Kline
+	from := start.UnixMilli()
+	for from < end.UnixMilli() {
+		raw, err := s.client.NewKlinesService().
+			Symbol(symbol).
+			Interval(interval).
+			StartTime(from).
+			EndTime(end.UnixMilli()).
+			Limit(1500).
+			Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) == 0 {
+			break
+		}
+		for _, k := range ConvertKlines(raw) {
+			if k.CloseTime.Before(end) {
+				klines = append(klines, k)
+			}
+		}
+		from = raw[len(raw)-1].CloseTime + 1
+	}
+	return klines, nil
+}
+
+// ConvertKlines converts go-binance klines to domain klines
+func ConvertKlines(raw []*futures.Kline) []trade.Kline {
+	klines := make([]trade.Kline, 0, len(raw))
+	for _, k := range raw {
+		open, _ := strconv.ParseFloat(k.Open, 64)
+		high, _ := strconv.ParseFloat(k.High, 64)
+		low, _ := strconv.ParseFloat(k.Low, 64)
+		closePrice, _ := strconv.ParseFloat(k.Close, 64)
+		volume, _ := strconv.ParseFloat(k.Volume, 64)
+
+		klines = append(klines, trade.Kline{
+			OpenTime:  time.UnixMilli(k.OpenTime),
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     closePrice,
+			Volume:    volume,
+			CloseTime: time.UnixMilli(k.CloseTime),
+		})
+	}
+	return klines
+}
@@ -0,0 +1,92 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/liquidation"
+	"github.com/sirupsen/logrus"
+)
+
+// LiquidationStream feeds the market-wide forceOrder stream into a
+// liquidation monitor
+type LiquidationStream struct {
+	monitor *liquidation.Monitor
+	logger  *logrus.Logger
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+}
+
+// NewLiquidationStream creates a stream that forwards liquidations to monitor
+func NewLiquidationStream(monitor *liquidation.Monitor) *LiquidationStream {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	return &LiquidationStream{
+		monitor: monitor,
+		logger:  logger,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start connects to the all-market forceOrder stream in the background
+func (s *LiquidationStream) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+
+	go serveWithReconnect(ctx, s.stopCh, "forceOrder", s.logger, func() (chan struct{}, chan struct{}, error) {
+		return futures.WsAllLiquidationOrderServe(s.handle, s.handleError)
+	})
+
+	return nil
+}
+
+// Stop closes the stream
+func (s *LiquidationStream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+func (s *LiquidationStream) handle(event *futures.WsLiquidationOrderEvent) {
+	order := event.LiquidationOrder
+
+	price, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	if price == 0 {
+		price, _ = strconv.ParseFloat(order.Price, 64)
+	}
+	qty, _ := strconv.ParseFloat(order.AccumulatedFilledQty, 64)
+	if qty == 0 {
+		qty, _ = strconv.ParseFloat(order.OrigQuantity, 64)
+	}
+	if price <= 0 || qty <= 0 {
+		return
+	}
+
+	s.monitor.Add(liquidation.Event{
+		Symbol:   order.Symbol,
+		Side:     string(order.Side),
+		Price:    price,
+		Quantity: qty,
+		Time:     time.UnixMilli(order.TradeTime),
+	})
+}
+
+func (s *LiquidationStream) handleError(err error) {
+	s.logger.WithError(err).Warn("liquidation_stream_error")
+}
@@ -7,13 +7,21 @@ import (
 
 	"github.com/britej3/gobot/domain/platform"
 	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/indicator"
 	"github.com/britej3/gobot/pkg/stealth"
+	"github.com/britej3/gobot/services/kline"
 	"golang.org/x/time/rate"
 )
 
+// IndicatorSource serves indicators from the shared candle cache
+type IndicatorSource interface {
+	Indicators(ctx context.Context, symbol, interval string) (kline.Indicators, error)
+}
+
 type MarketDataProvider struct {
 	client     *Client
 	rateClient *RateLimitedClient
+	indicators IndicatorSource
 	limiter    *rate.Limiter
 	subs       map[string][]func(*trade.MarketData)
 	mu         sync.RWMutex
@@ -35,6 +43,12 @@ func NewMarketDataProviderWithStealth(client *RateLimitedClient, stealth *stealt
 	}
 }
 
+// SetIndicators fills each market's AnchoredVWAP from the spike- or
+// session-anchored VWAP of source's 15m candles
+func (m *MarketDataProvider) SetIndicators(source IndicatorSource) {
+	m.indicators = source
+}
+
 func (m *MarketDataProvider) GetMarketData(ctx context.Context, symbol string) (*trade.MarketData, error) {
 	if err := m.limiter.Wait(ctx); err != nil {
 		return nil, err
@@ -63,6 +77,7 @@ func (m *MarketDataProvider) GetMarketData(ctx context.Context, symbol string) (
 	}
 
 	market := buildMarketData(symbol, price, klines)
+	market.AnchoredVWAP = m.anchoredVWAP(ctx, symbol)
 
 	go m.notifySubscribers(market)
 
@@ -107,6 +122,7 @@ func (m *MarketDataProvider) GetAllMarketData(ctx context.Context) (map[string]*
 		}
 
 		market := buildMarketData(symbol, price, klines)
+		market.AnchoredVWAP = m.anchoredVWAP(ctx, symbol)
 		result[symbol] = market
 
 		m.notifySubscribers(market)
@@ -143,6 +159,22 @@ func (m *MarketDataProvider) notifySubscribers(market *trade.MarketData) {
 	}
 }
 
+// anchoredVWAP returns the VWAP since the last volume spike, falling back
+// to the session VWAP, or 0 without an indicator source
+func (m *MarketDataProvider) anchoredVWAP(ctx context.Context, symbol string) float64 {
+	if m.indicators == nil {
+		return 0
+	}
+	ind, err := m.indicators.Indicators(ctx, symbol, "15m")
+	if err != nil {
+		return 0
+	}
+	if ind.SpikeVWAP > 0 {
+		return ind.SpikeVWAP
+	}
+	return ind.SessionVWAP
+}
+
 func buildMarketData(symbol string, currentPrice float64, klines []trade.Kline) *trade.MarketData {
 	if len(klines) == 0 {
 		return &trade.MarketData{
@@ -171,6 +203,8 @@ func buildMarketData(symbol string, currentPrice float64, klines []trade.Kline)
 	}
 
 	rsi := calculateRSI(klines)
+	vwap := indicator.VWAP(klines)
+	swingLow, swingHigh := indicator.SwingRange(klines, indicator.SwingLookback)
 
 	volatility := 0.0
 	if len(klines) > 1 {
@@ -192,6 +226,11 @@ func buildMarketData(symbol string, currentPrice float64, klines []trade.Kline)
 		RSI:          math.Round(rsi*100) / 100,
 		EMAFast:      math.Round(emaFast*10000000) / 10000000,
 		EMASlow:      math.Round(emaSlow*10000000) / 10000000,
+		VWAP:         vwap,
+		ATR:          indicator.ATR(klines, 14),
+		SwingLow:     swingLow,
+		SwingHigh:    swingHigh,
+		Patterns:     indicator.PatternFeatures(indicator.DetectPatterns(klines)),
 	}
 }
 
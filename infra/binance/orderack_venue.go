@@ -0,0 +1,35 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/orderack"
+)
+
+// OrderAckVenue reads back and cancels async entries by their client order
+// ID for the order acknowledgement tracker
+type OrderAckVenue struct {
+	client *HardenedClient
+}
+
+// NewOrderAckVenue creates an order acknowledgement venue
+func NewOrderAckVenue(client *HardenedClient) *OrderAckVenue {
+	return &OrderAckVenue{client: client}
+}
+
+// GetOrderByClientID reads the order back, wrapping orderack.ErrNotFound
+// when the exchange never took it
+func (v *OrderAckVenue) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*trade.Order, error) {
+	order, err := v.client.GetOrderByClientID(ctx, symbol, clientOrderID)
+	if IsUnknownOrder(err) {
+		return nil, fmt.Errorf("%w: %v", orderack.ErrNotFound, err)
+	}
+	return order, err
+}
+
+// CancelOrderByClientID cancels the order and returns its final state
+func (v *OrderAckVenue) CancelOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*trade.Order, error) {
+	return v.client.CancelOrderByClientID(ctx, symbol, clientOrderID)
+}
@@ -0,0 +1,105 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/protect"
+)
+
+// FuturesProtectVenue places protective stops and take profits as
+// conditional algo orders, which is how Binance futures takes them now,
+// each closing the whole position at the mark price
+type FuturesProtectVenue struct {
+	client *futures.Client
+	hedge  bool
+}
+
+// NewFuturesProtectVenue creates a protective order venue. hedge sets the
+// position side on each order, as hedge mode requires.
+func NewFuturesProtectVenue(client *futures.Client, hedge bool) *FuturesProtectVenue {
+	return &FuturesProtectVenue{client: client, hedge: hedge}
+}
+
+// Place sends a close-position STOP_MARKET or TAKE_PROFIT_MARKET for p
+func (v *FuturesProtectVenue) Place(ctx context.Context, p protect.Position, kind string, trigger float64) (string, error) {
+	side, positionSide := futures.SideTypeSell, futures.PositionSideTypeLong
+	if p.Side == trade.SideSell {
+		side, positionSide = futures.SideTypeBuy, futures.PositionSideTypeShort
+	}
+	orderType := futures.AlgoOrderTypeStopMarket
+	if kind == protect.KindTakeProfit {
+		orderType = futures.AlgoOrderTypeTakeProfitMarket
+	}
+
+	service := v.client.NewCreateAlgoOrderService().
+		AlgoType(futures.OrderAlgoTypeConditional).
+		ClientAlgoId(NewClientOrderID()).
+		Symbol(p.Symbol).
+		Side(side).
+		Type(orderType).
+		TriggerPrice(strconv.FormatFloat(trigger, 'f', -1, 64)).
+		WorkingType(futures.WorkingTypeMarkPrice).
+		ClosePosition(true)
+	if v.hedge {
+		service = service.PositionSide(positionSide)
+	}
+	res, err := service.Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(res.AlgoId, 10), nil
+}
+
+// Cancel cancels a protective order by its algo ID
+func (v *FuturesProtectVenue) Cancel(ctx context.Context, _, id string) error {
+	algoID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, err = v.client.NewCancelAlgoOrderService().AlgoID(algoID).Do(ctx)
+	return err
+}
+
+// Open lists the open close-position stops and take profits
+func (v *FuturesProtectVenue) Open(ctx context.Context) ([]protect.Order, error) {
+	algos, err := v.client.NewListOpenAlgoOrdersService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var orders []protect.Order
+	for _, o := range algos {
+		kind := ""
+		switch o.OrderType {
+		case futures.AlgoOrderTypeStopMarket, futures.AlgoOrderTypeStop:
+			kind = protect.KindStop
+		case futures.AlgoOrderTypeTakeProfitMarket, futures.AlgoOrderTypeTakeProfit:
+			kind = protect.KindTakeProfit
+		default:
+			continue
+		}
+		orders = append(orders, protect.Order{ID: strconv.FormatInt(o.AlgoId, 10), Symbol: o.Symbol, Kind: kind})
+	}
+	return orders, nil
+}
+
+// Positions returns the signed size of every open position
+func (v *FuturesProtectVenue) Positions(ctx context.Context) (map[string]float64, error) {
+	risks, err := v.client.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]float64)
+	for _, r := range risks {
+		size, _ := strconv.ParseFloat(r.PositionAmt, 64)
+		if r.PositionSide == "SHORT" && size > 0 {
+			size = -size
+		}
+		if size != 0 {
+			sizes[r.Symbol] += size
+		}
+	}
+	return sizes, nil
+}
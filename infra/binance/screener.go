@@ -8,13 +8,57 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
+
+	"github.com/britej3/gobot/pkg/apiweight"
+	"github.com/britej3/gobot/services/screener"
 )
 
+// streamMaxAge is how long the ticker stream may be silent before scans
+// fall back to REST
+const streamMaxAge = 30 * time.Second
+
 type ScreenerClient struct {
 	cfg    Config
 	client *http.Client
+
+	stream    *TickerStream
+	fullEvery time.Duration
+	pageSize  int
+
+	mu       sync.Mutex
+	symbols  []SymbolInfo
+	lastFull time.Time
+	scans    screener.ScanCounts
+}
+
+// SetTickerStream reads ticker statistics from stream instead of the
+// all-symbol ticker endpoint. A full REST scan still runs at least every
+// fullEvery, and whenever the stream is silent. At most pageSize symbols the
+// stream has not reported are fetched one by one per scan.
+func (c *ScreenerClient) SetTickerStream(stream *TickerStream, fullEvery time.Duration, pageSize int) {
+	if fullEvery <= 0 {
+		fullEvery = 30 * time.Minute
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	c.stream = stream
+	c.fullEvery = fullEvery
+	c.pageSize = pageSize
+}
+
+// Scans counts the scans run each way
+func (c *ScreenerClient) Scans() screener.ScanCounts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.scans
+}
+
+// SetWeightBudget charges every request to component of budget
+func (c *ScreenerClient) SetWeightBudget(budget *apiweight.Budget, component string) {
+	c.client = WithWeightBudget(c.client, budget, component)
 }
 
 type ExchangeInfo struct {
@@ -34,6 +78,7 @@ type Ticker24hr struct {
 	PriceChangePercent string `json:"priceChangePercent"`
 	Volume             string `json:"volume"`
 	QuoteVolume        string `json:"quoteVolume"`
+	CloseTime          int64  `json:"closeTime"`
 }
 
 type SymbolInfo struct {
@@ -41,6 +86,15 @@ type SymbolInfo struct {
 	ContractType string `json:"contractType"`
 	QuoteAsset   string `json:"quoteAsset"`
 	Status       string `json:"status"`
+	OnboardDate  int64  `json:"onboardDate"`
+	DeliveryDate int64  `json:"deliveryDate"`
+}
+
+type OpenInterestHist struct {
+	Symbol               string `json:"symbol"`
+	SumOpenInterest      string `json:"sumOpenInterest"`
+	SumOpenInterestValue string `json:"sumOpenInterestValue"`
+	Timestamp            int64  `json:"timestamp"`
 }
 
 type ExchangeInfoResponse struct {
@@ -67,10 +121,67 @@ func NewScreenerClient(cfg Config) *ScreenerClient {
 	}
 }
 
+// GetExchangeInfo returns every symbol with its 24h statistics. With a
+// ticker stream the statistics come from the stream and the symbols from the
+// last full scan, so a scan costs at most a page of per-symbol requests.
 func (c *ScreenerClient) GetExchangeInfo(ctx context.Context) ([]ExchangeInfo, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
 	defer cancel()
 
+	if pairs, ok := c.incremental(ctx); ok {
+		return pairs, nil
+	}
+	return c.fullScan(ctx)
+}
+
+// incremental builds the pairs from the ticker stream, or returns false when
+// a full scan is due.
+func (c *ScreenerClient) incremental(ctx context.Context) ([]ExchangeInfo, bool) {
+	if c.stream == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	symbols, lastFull := c.symbols, c.lastFull
+	c.mu.Unlock()
+	if symbols == nil || time.Since(lastFull) >= c.fullEvery {
+		return nil, false
+	}
+	stats, ok := c.stream.snapshot(streamMaxAge)
+	if !ok {
+		return nil, false
+	}
+
+	pairs := make([]ExchangeInfo, 0, len(symbols))
+	paged := 0
+	for _, symbol := range symbols {
+		stat, ok := stats[symbol.Symbol]
+		if !ok {
+			if symbol.Status != "TRADING" || paged >= c.pageSize {
+				continue
+			}
+			ticker, err := c.ticker(ctx, symbol.Symbol)
+			if err != nil {
+				// Most likely the weight quota; the rest wait for the next scan.
+				paged = c.pageSize
+				continue
+			}
+			paged++
+			c.stream.seed([]Ticker24hr{ticker})
+			stat = statOf(ticker)
+		}
+		pairs = append(pairs, pairInfo(symbol, stat))
+	}
+
+	c.mu.Lock()
+	c.scans.Incremental++
+	c.scans.Paged += int64(paged)
+	c.mu.Unlock()
+	return pairs, true
+}
+
+// fullScan reads the symbols and the statistics of all of them over REST.
+func (c *ScreenerClient) fullScan(ctx context.Context) ([]ExchangeInfo, error) {
+
 	symbolURL := fmt.Sprintf("%s/fapi/v1/exchangeInfo", c.cfg.BaseURL)
 	tickerURL := fmt.Sprintf("%s/fapi/v1/ticker/24hr", c.cfg.BaseURL)
 
@@ -115,6 +226,15 @@ func (c *ScreenerClient) GetExchangeInfo(ctx context.Context) ([]ExchangeInfo, e
 		tickerMap[tickers[i].Symbol] = &tickers[i]
 	}
 
+	c.mu.Lock()
+	c.symbols = exchangeResp.Symbols
+	c.lastFull = time.Now()
+	c.scans.Full++
+	c.mu.Unlock()
+	if c.stream != nil {
+		c.stream.seed(tickers)
+	}
+
 	pairs := make([]ExchangeInfo, 0, len(exchangeResp.Symbols))
 
 	for _, symbol := range exchangeResp.Symbols {
@@ -126,6 +246,11 @@ func (c *ScreenerClient) GetExchangeInfo(ctx context.Context) ([]ExchangeInfo, e
 		vol, _ := strconv.ParseFloat(ticker.QuoteVolume, 64)
 		change, _ := strconv.ParseFloat(ticker.PriceChangePercent, 64)
 
+		updated := time.Now()
+		if ticker.CloseTime > 0 {
+			updated = time.UnixMilli(ticker.CloseTime)
+		}
+
 		pairs = append(pairs, ExchangeInfo{
 			Symbol:         symbol.Symbol,
 			ContractType:   symbol.ContractType,
@@ -133,13 +258,123 @@ func (c *ScreenerClient) GetExchangeInfo(ctx context.Context) ([]ExchangeInfo, e
 			Status:         symbol.Status,
 			Volume24h:      vol,
 			PriceChangePct: change,
-			LastUpdated:    time.Now(),
+			LastUpdated:    updated,
 		})
 	}
 
 	return pairs, nil
 }
 
+// pairInfo combines a symbol with its statistics from the stream
+func pairInfo(symbol SymbolInfo, stat tickerStat) ExchangeInfo {
+	return ExchangeInfo{
+		Symbol:         symbol.Symbol,
+		ContractType:   symbol.ContractType,
+		QuoteAsset:     symbol.QuoteAsset,
+		Status:         symbol.Status,
+		Volume24h:      stat.volume,
+		PriceChangePct: stat.change,
+		LastUpdated:    stat.at,
+	}
+}
+
+// ticker reads one symbol's 24h statistics, at weight 1 instead of 40
+func (c *ScreenerClient) ticker(ctx context.Context, symbol string) (Ticker24hr, error) {
+	url := fmt.Sprintf("%s/fapi/v1/ticker/24hr?symbol=%s", c.cfg.BaseURL, symbol)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Ticker24hr{}, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Ticker24hr{}, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	var ticker Ticker24hr
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return Ticker24hr{}, fmt.Errorf("failed to parse ticker: %w", err)
+	}
+	if ticker.Symbol == "" {
+		return Ticker24hr{}, fmt.Errorf("no ticker for %s", symbol)
+	}
+	return ticker, nil
+}
+
+func (c *ScreenerClient) GetSymbols(ctx context.Context) ([]SymbolInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/fapi/v1/exchangeInfo", c.cfg.BaseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("exchange info: %s: %s", resp.Status, body)
+	}
+
+	var exchangeResp ExchangeInfoResponse
+	if err := json.Unmarshal(body, &exchangeResp); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange info: %w", err)
+	}
+	// An empty list would read as every symbol delisted.
+	if len(exchangeResp.Symbols) == 0 {
+		return nil, fmt.Errorf("exchange info lists no symbols")
+	}
+
+	return exchangeResp.Symbols, nil
+}
+
+// GetOpenInterestChange returns the percentage change in open interest over
+// the last limit periods, e.g. period "5m" and limit 12 covers one hour.
+func (c *ScreenerClient) GetOpenInterestChange(ctx context.Context, symbol, period string, limit int) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/futures/data/openInterestHist?symbol=%s&period=%s&limit=%d", c.cfg.BaseURL, symbol, period, limit)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	var hist []OpenInterestHist
+	if err := json.Unmarshal(body, &hist); err != nil {
+		return 0, fmt.Errorf("failed to parse open interest: %w", err)
+	}
+	if len(hist) < 2 {
+		return 0, fmt.Errorf("not enough open interest history for %s", symbol)
+	}
+
+	first, _ := strconv.ParseFloat(hist[0].SumOpenInterest, 64)
+	last, _ := strconv.ParseFloat(hist[len(hist)-1].SumOpenInterest, 64)
+	if first <= 0 {
+		return 0, fmt.Errorf("invalid open interest for %s", symbol)
+	}
+
+	return (last - first) / first * 100, nil
+}
+
 func (c *ScreenerClient) GetUSDMFuturesPairs(ctx context.Context) ([]ExchangeInfo, error) {
 	allPairs, err := c.GetExchangeInfo(ctx)
 	if err != nil {
@@ -162,27 +397,13 @@ func (c *ScreenerClient) GetTopMemeCoins(ctx context.Context, limit int) ([]Exch
 		return nil, err
 	}
 
-	memeKeywords := []string{
-		"PEPE", "WIF", "POPCAT", "TURBO", "MOG", "FWOG", "MEW", "ACT",
-		"LUNA", "NEIRO", "BOME", "SLERF", "BONK", "JUP", "WEN",
-		"MEME", "DOGE", "SHIB", "FLOKI", "ARBIT", "STRK", "SUNDOG",
-		"PIPPIN", "GRIFFAIN", "NEG",
-	}
+	meme, _ := screener.FindUniverse(screener.DefaultUniverses(), "meme")
 
 	memePairs := make([]ExchangeInfo, 0)
 	otherPairs := make([]ExchangeInfo, 0)
 
 	for _, p := range pairs {
-		isMeme := false
-		symbolUpper := strings.ToUpper(p.Symbol)
-		for _, keyword := range memeKeywords {
-			if strings.Contains(symbolUpper, keyword) {
-				isMeme = true
-				break
-			}
-		}
-
-		if isMeme {
+		if meme.MatchSymbol(p.Symbol) {
 			memePairs = append(memePairs, p)
 		} else {
 			otherPairs = append(otherPairs, p)
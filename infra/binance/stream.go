@@ -0,0 +1,57 @@
+package binance
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// wsServeFunc opens a go-binance websocket and returns its done/stop channels
+type wsServeFunc func() (doneC, stopC chan struct{}, err error)
+
+// serveWithReconnect keeps a websocket stream alive, reconnecting with
+// exponential backoff until ctx is cancelled or stopCh is closed
+func serveWithReconnect(ctx context.Context, stopCh <-chan struct{}, name string, logger *logrus.Logger, serve wsServeFunc) {
+	backoff := time.Second
+	const maxBackoff = time.Minute
+
+	for {
+		doneC, stopC, err := serve()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"stream":  name,
+				"error":   err.Error(),
+				"backoff": backoff.String(),
+			}).Warn("stream_connect_failed")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-stopCh:
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+
+		backoff = time.Second
+		logger.WithField("stream", name).Info("stream_connected")
+
+		select {
+		case <-ctx.Done():
+			close(stopC)
+			return
+		case <-stopCh:
+			close(stopC)
+			return
+		case <-doneC:
+			logger.WithField("stream", name).Warn("stream_disconnected")
+		}
+	}
+}
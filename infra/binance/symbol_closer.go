@@ -0,0 +1,47 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/equity"
+)
+
+// FuturesSymbolCloser flattens whatever the account holds in a symbol, for
+// the listing monitor's delisting flatten
+type FuturesSymbolCloser struct {
+	client *futures.Client
+	equity *FuturesEquitySource
+}
+
+// NewFuturesSymbolCloser creates a symbol closer backed by a futures client
+func NewFuturesSymbolCloser(client *futures.Client) *FuturesSymbolCloser {
+	return &FuturesSymbolCloser{client: client, equity: NewFuturesEquitySource(client)}
+}
+
+// ClosePosition closes every open position in symbol at market with
+// reduce-only orders. A flat symbol is not an error.
+func (s *FuturesSymbolCloser) ClosePosition(ctx context.Context, symbol string) error {
+	risks, err := s.client.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("position risk for %s: %w", symbol, err)
+	}
+	for _, r := range risks {
+		amt, _ := strconv.ParseFloat(r.PositionAmt, 64)
+		if amt == 0 {
+			continue
+		}
+		side := "LONG"
+		if amt < 0 || r.PositionSide == "SHORT" {
+			side = "SHORT"
+		}
+		p := equity.Position{Symbol: r.Symbol, Side: side, Size: math.Abs(amt)}
+		if err := s.equity.ClosePosition(ctx, p); err != nil {
+			return fmt.Errorf("close %s %s: %w", side, symbol, err)
+		}
+	}
+	return nil
+}
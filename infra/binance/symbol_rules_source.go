@@ -0,0 +1,56 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/symbolrules"
+)
+
+// FuturesSymbolRulesSource reads tick, lot and notional filters from the
+// futures exchangeInfo endpoint
+type FuturesSymbolRulesSource struct {
+	client *futures.Client
+}
+
+// NewFuturesSymbolRulesSource creates a symbol rules source backed by a futures client
+func NewFuturesSymbolRulesSource(client *futures.Client) *FuturesSymbolRulesSource {
+	return &FuturesSymbolRulesSource{client: client}
+}
+
+// SymbolRules returns the order constraints of every trading symbol
+func (s *FuturesSymbolRulesSource) SymbolRules(ctx context.Context) ([]symbolrules.Rules, error) {
+	info, err := s.client.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rules := make([]symbolrules.Rules, 0, len(info.Symbols))
+	for i := range info.Symbols {
+		sym := &info.Symbols[i]
+		if sym.Status != "TRADING" {
+			continue
+		}
+
+		r := symbolrules.Rules{Symbol: sym.Symbol, UpdatedAt: now}
+		if f := sym.PriceFilter(); f != nil {
+			r.TickSize, _ = strconv.ParseFloat(f.TickSize, 64)
+			r.MinPrice, _ = strconv.ParseFloat(f.MinPrice, 64)
+			r.MaxPrice, _ = strconv.ParseFloat(f.MaxPrice, 64)
+		}
+		if f := sym.LotSizeFilter(); f != nil {
+			r.StepSize, _ = strconv.ParseFloat(f.StepSize, 64)
+			r.MinQty, _ = strconv.ParseFloat(f.MinQuantity, 64)
+			r.MaxQty, _ = strconv.ParseFloat(f.MaxQuantity, 64)
+		}
+		if f := sym.MinNotionalFilter(); f != nil {
+			r.MinNotional, _ = strconv.ParseFloat(f.Notional, 64)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,137 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/sirupsen/logrus"
+)
+
+// tickerStat is the part of a symbol's 24h statistics the screener ranks on
+type tickerStat struct {
+	change float64
+	volume float64
+	at     time.Time
+}
+
+// TickerStream keeps the 24h statistics of every symbol current from the
+// all-market mini ticker stream, which each second pushes only the symbols
+// whose statistics changed. A full REST scan seeds it.
+type TickerStream struct {
+	logger  *logrus.Logger
+	mu      sync.RWMutex
+	tickers map[string]tickerStat
+	last    time.Time
+	running bool
+	stopCh  chan struct{}
+}
+
+// NewTickerStream creates an empty ticker stream
+func NewTickerStream() *TickerStream {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	return &TickerStream{
+		logger:  logger,
+		tickers: make(map[string]tickerStat),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start connects to the !miniTicker@arr stream in the background
+func (s *TickerStream) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+
+	go serveWithReconnect(ctx, s.stopCh, "miniTicker", s.logger, func() (chan struct{}, chan struct{}, error) {
+		return futures.WsAllMiniMarketTickerServe(s.handle, s.handleError)
+	})
+
+	return nil
+}
+
+// Stop closes the stream
+func (s *TickerStream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+func (s *TickerStream) handle(events futures.WsAllMiniMarketTickerEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range events {
+		open, _ := strconv.ParseFloat(e.OpenPrice, 64)
+		last, _ := strconv.ParseFloat(e.ClosePrice, 64)
+		volume, _ := strconv.ParseFloat(e.QuoteVolume, 64)
+		if open <= 0 {
+			continue
+		}
+		s.tickers[e.Symbol] = tickerStat{
+			change: (last - open) / open * 100,
+			volume: volume,
+			at:     time.UnixMilli(e.Time),
+		}
+	}
+	s.last = time.Now()
+}
+
+func (s *TickerStream) handleError(err error) {
+	s.logger.WithError(err).Warn("ticker_stream_error")
+}
+
+// seed stores statistics read over REST. A symbol the stream has updated
+// since keeps the newer values.
+func (s *TickerStream) seed(tickers []Ticker24hr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range tickers {
+		stat := statOf(t)
+		if old, ok := s.tickers[t.Symbol]; ok && old.at.After(stat.at) {
+			continue
+		}
+		s.tickers[t.Symbol] = stat
+	}
+}
+
+func statOf(t Ticker24hr) tickerStat {
+	stat := tickerStat{at: time.Now()}
+	stat.change, _ = strconv.ParseFloat(t.PriceChangePercent, 64)
+	stat.volume, _ = strconv.ParseFloat(t.QuoteVolume, 64)
+	if t.CloseTime > 0 {
+		stat.at = time.UnixMilli(t.CloseTime)
+	}
+	return stat
+}
+
+// snapshot returns the statistics of every symbol seen, or false when no
+// message arrived within maxAge and the stream cannot be trusted.
+func (s *TickerStream) snapshot(maxAge time.Duration) (map[string]tickerStat, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.last.IsZero() || time.Since(s.last) > maxAge {
+		return nil, false
+	}
+	out := make(map[string]tickerStat, len(s.tickers))
+	for symbol, stat := range s.tickers {
+		out[symbol] = stat
+	}
+	return out, true
+}
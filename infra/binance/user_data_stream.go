@@ -0,0 +1,191 @@
+package binance
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/services/accountguard"
+	"github.com/britej3/gobot/services/protect"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// OrderIDPrefix starts the client order ID of every order the bot places,
+// so its orders can be told apart on the account stream
+const OrderIDPrefix = "gobot-"
+
+// NewClientOrderID returns a unique client order ID carrying OrderIDPrefix
+func NewClientOrderID() string {
+	return OrderIDPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")[:24]
+}
+
+// UserDataStream feeds the futures account stream to its order and account
+// handlers, keeping its listen key alive
+type UserDataStream struct {
+	client    *futures.Client
+	logger    *logrus.Logger
+	mu        sync.Mutex
+	running   bool
+	onOrder   []func(accountguard.OrderUpdate)
+	onAccount []func(accountguard.AccountUpdate)
+	onAlgo    []func(protect.Update)
+	stopCh    chan struct{}
+}
+
+// NewUserDataStream creates a stream without handlers
+func NewUserDataStream(client *futures.Client) *UserDataStream {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	return &UserDataStream{
+		client: client,
+		logger: logger,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// OnOrder registers fn for every order event. Register before Start.
+func (s *UserDataStream) OnOrder(fn func(accountguard.OrderUpdate)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onOrder = append(s.onOrder, fn)
+}
+
+// OnAccount registers fn for every balance and position event. Register
+// before Start.
+func (s *UserDataStream) OnAccount(fn func(accountguard.AccountUpdate)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onAccount = append(s.onAccount, fn)
+}
+
+// OnAlgo registers fn for every conditional algo order event. Register
+// before Start.
+func (s *UserDataStream) OnAlgo(fn func(protect.Update)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onAlgo = append(s.onAlgo, fn)
+}
+
+// Start opens a listen key and connects to the account stream in the background
+func (s *UserDataStream) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+
+	go serveWithReconnect(ctx, s.stopCh, "userData", s.logger, func() (chan struct{}, chan struct{}, error) {
+		listenKey, err := s.client.NewStartUserStreamService().Do(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		doneC, stopC, err := futures.WsUserDataServe(listenKey, s.handle, s.handleError)
+		if err != nil {
+			return nil, nil, err
+		}
+		go s.keepAlive(ctx, listenKey, doneC)
+		return doneC, stopC, nil
+	})
+
+	return nil
+}
+
+// Stop closes the stream
+func (s *UserDataStream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+// keepAlive extends listenKey every 30 minutes until its connection closes;
+// Binance expires it after an hour without one
+func (s *UserDataStream) keepAlive(ctx context.Context, listenKey string, doneC chan struct{}) {
+	ticker := time.NewTicker(30 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-doneC:
+			return
+		case <-ticker.C:
+			if err := s.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx); err != nil {
+				s.logger.WithError(err).Warn("listen_key_keepalive_failed")
+			}
+		}
+	}
+}
+
+func (s *UserDataStream) handle(event *futures.WsUserDataEvent) {
+	at := time.UnixMilli(event.Time)
+
+	switch event.Event {
+	case futures.UserDataEventTypeOrderTradeUpdate:
+		o := event.OrderTradeUpdate
+		filled, _ := strconv.ParseFloat(o.AccumulatedFilledQty, 64)
+		avg, _ := strconv.ParseFloat(o.AveragePrice, 64)
+		update := accountguard.OrderUpdate{
+			Symbol:        o.Symbol,
+			OrderID:       o.ID,
+			ClientOrderID: o.ClientOrderID,
+			Side:          string(o.Side),
+			Type:          string(o.Type),
+			Status:        string(o.Status),
+			Filled:        filled,
+			AvgPrice:      avg,
+			Time:          at,
+		}
+		for _, fn := range s.onOrder {
+			fn(update)
+		}
+	case futures.UserDataEventTypeAccountUpdate:
+		a := event.AccountUpdate
+		update := accountguard.AccountUpdate{
+			Reason:    string(a.Reason),
+			Balances:  make(map[string]float64, len(a.Balances)),
+			Positions: make(map[string]float64, len(a.Positions)),
+			Time:      at,
+		}
+		for _, b := range a.Balances {
+			change, _ := strconv.ParseFloat(b.ChangeBalance, 64)
+			update.Balances[b.Asset] = change
+		}
+		for _, p := range a.Positions {
+			amount, _ := strconv.ParseFloat(p.Amount, 64)
+			update.Positions[p.Symbol] = amount
+		}
+		for _, fn := range s.onAccount {
+			fn(update)
+		}
+	case futures.UserDataEventTypeAlgoUpdate:
+		a := event.AlgoUpdate
+		update := protect.Update{
+			ID:     strconv.FormatInt(a.AlgoID, 10),
+			Symbol: a.Symbol,
+			Status: a.AlgoStatus,
+		}
+		for _, fn := range s.onAlgo {
+			fn(update)
+		}
+	case futures.UserDataEventTypeListenKeyExpired:
+		s.logger.Warn("listen_key_expired")
+	}
+}
+
+func (s *UserDataStream) handleError(err error) {
+	s.logger.WithError(err).Warn("user_data_stream_error")
+}
@@ -0,0 +1,140 @@
+package binance
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/britej3/gobot/pkg/apiweight"
+)
+
+// usedWeightHeader carries the IP's request weight used in the current minute
+const usedWeightHeader = "X-MBX-USED-WEIGHT-1M"
+
+// WeightTransport charges each request to a component of the API weight
+// budget before sending it, and feeds the used weight Binance reports back
+// into the budget. A refused request fails with apiweight.ErrQuotaExceeded
+// without reaching the exchange.
+type WeightTransport struct {
+	Base      http.RoundTripper
+	Budget    *apiweight.Budget
+	Component string
+}
+
+// NewWeightTransport wraps base, http.DefaultTransport when nil
+func NewWeightTransport(base http.RoundTripper, budget *apiweight.Budget, component string) *WeightTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &WeightTransport{Base: base, Budget: budget, Component: component}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *WeightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Budget.Acquire(t.Component, RequestWeight(req)); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if used, err := strconv.Atoi(resp.Header.Get(usedWeightHeader)); err == nil {
+		t.Budget.Observe(used)
+	}
+	return resp, nil
+}
+
+// WithWeightBudget returns a copy of client whose requests are charged to
+// component
+func WithWeightBudget(client *http.Client, budget *apiweight.Budget, component string) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	wrapped := *client
+	wrapped.Transport = NewWeightTransport(client.Transport, budget, component)
+	return &wrapped
+}
+
+// RequestWeight returns the weight Binance charges for a USD-M futures
+// request, 1 for endpoints it does not list
+func RequestWeight(req *http.Request) int {
+	path := req.URL.Path
+	query := req.URL.Query()
+	bySymbol := query.Get("symbol") != ""
+
+	switch {
+	case path == "/fapi/v1/klines", path == "/fapi/v1/continuousKlines",
+		path == "/fapi/v1/indexPriceKlines", path == "/fapi/v1/markPriceKlines":
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			limit = 500
+		}
+		switch {
+		case limit < 100:
+			return 1
+		case limit < 500:
+			return 2
+		case limit <= 1000:
+			return 5
+		default:
+			return 10
+		}
+	case path == "/fapi/v1/depth":
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			limit = 500
+		}
+		switch {
+		case limit <= 50:
+			return 2
+		case limit <= 100:
+			return 5
+		case limit <= 500:
+			return 10
+		default:
+			return 20
+		}
+	case path == "/fapi/v1/ticker/24hr":
+		if bySymbol {
+			return 1
+		}
+		return 40
+	case path == "/fapi/v1/ticker/price", path == "/fapi/v2/ticker/price":
+		if bySymbol {
+			return 1
+		}
+		return 2
+	case path == "/fapi/v1/ticker/bookTicker":
+		if bySymbol {
+			return 2
+		}
+		return 5
+	case path == "/fapi/v1/premiumIndex":
+		if bySymbol {
+			return 1
+		}
+		return 10
+	case path == "/fapi/v1/openOrders":
+		if bySymbol {
+			return 1
+		}
+		return 40
+	case path == "/fapi/v1/positionSide/dual" && req.Method == http.MethodGet:
+		return 30
+	case path == "/fapi/v1/income":
+		return 30
+	case path == "/fapi/v1/aggTrades":
+		return 20
+	case path == "/fapi/v1/allOrders", path == "/fapi/v1/userTrades",
+		path == "/fapi/v1/batchOrders", path == "/fapi/v1/trades":
+		return 5
+	case strings.HasSuffix(path, "/account"), strings.HasSuffix(path, "/balance"),
+		strings.HasSuffix(path, "/positionRisk"):
+		return 5
+	}
+	return 1
+}
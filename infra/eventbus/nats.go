@@ -0,0 +1,159 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+type NATSConfig struct {
+	Addr     string
+	Token    string
+	User     string
+	Password string
+}
+
+// NATS publishes over the NATS core text protocol: fire-and-forget PUB on a
+// plain TCP connection, answering the server's PINGs. It only publishes, so
+// it needs none of a full client's subscription machinery. A broken
+// connection is redialled on the next publish.
+type NATS struct {
+	cfg NATSConfig
+
+	mu   sync.Mutex
+	conn net.Conn
+	w    *bufio.Writer
+	err  error // last -ERR from the server
+}
+
+func NewNATS(cfg NATSConfig) *NATS {
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:4222"
+	}
+	return &NATS{cfg: cfg}
+}
+
+func (n *NATS) Publish(ctx context.Context, subject string, payload []byte) error {
+	if strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("invalid NATS subject %q", subject)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conn == nil {
+		if err := n.dial(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		n.conn.SetWriteDeadline(deadline)
+	}
+
+	fmt.Fprintf(n.w, "PUB %s %d\r\n", subject, len(payload))
+	n.w.Write(payload)
+	n.w.WriteString("\r\n")
+	if err := n.w.Flush(); err != nil {
+		n.closeLocked()
+		return err
+	}
+	// Server errors arrive asynchronously, so this reports the most recent
+	// one since the previous publish.
+	err := n.err
+	n.err = nil
+	return err
+}
+
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.closeLocked()
+}
+
+func (n *NATS) closeLocked() error {
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn, n.w = nil, nil
+	return err
+}
+
+// dial connects, reads the server's INFO and sends CONNECT. Called with
+// n.mu held.
+func (n *NATS) dial(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", n.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		if err == nil {
+			err = fmt.Errorf("unexpected NATS greeting %q", strings.TrimSpace(line))
+		}
+		return err
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	opts, _ := json.Marshal(map[string]interface{}{
+		"verbose":    false,
+		"pedantic":   false,
+		"name":       "gobot",
+		"lang":       "go",
+		"version":    "1",
+		"auth_token": n.cfg.Token,
+		"user":       n.cfg.User,
+		"pass":       n.cfg.Password,
+	})
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "CONNECT %s\r\n", opts)
+	if err := w.Flush(); err != nil {
+		conn.Close()
+		return err
+	}
+
+	n.conn, n.w, n.err = conn, w, nil
+	go n.read(conn, r)
+	return nil
+}
+
+// read answers PINGs and records server errors until conn is closed.
+func (n *NATS) read(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			n.mu.Lock()
+			if n.conn == conn {
+				n.closeLocked()
+			}
+			n.mu.Unlock()
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			n.mu.Lock()
+			if n.conn == conn {
+				n.w.WriteString("PONG\r\n")
+				n.w.Flush()
+			}
+			n.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			n.mu.Lock()
+			n.err = errors.New("nats: " + strings.Trim(strings.TrimPrefix(line, "-ERR"), " '"))
+			n.mu.Unlock()
+		}
+	}
+}
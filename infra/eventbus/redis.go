@@ -0,0 +1,48 @@
+// Package eventbus holds the broker backends events are published to
+package eventbus
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// MaxLen caps each stream, approximately, so research consumers that
+	// fall behind cannot grow Redis without bound. Zero keeps everything.
+	MaxLen int64
+}
+
+// Redis appends each event to a Redis stream named after its subject, under
+// a "payload" field.
+type Redis struct {
+	client *redis.Client
+	maxLen int64
+}
+
+func NewRedis(cfg RedisConfig) *Redis {
+	return &Redis{
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}),
+		maxLen: cfg.MaxLen,
+	}
+}
+
+func (r *Redis) Publish(ctx context.Context, subject string, payload []byte) error {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: subject,
+		MaxLen: r.maxLen,
+		Approx: r.maxLen > 0,
+		Values: map[string]interface{}{"payload": payload},
+	}).Err()
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,176 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// obj is a JSON/msgpack map that keeps key order. Hyperliquid hashes the
+// msgpack encoding of an action, so keys must appear exactly as the
+// exchange's own SDK emits them.
+type obj []field
+
+type field struct {
+	key string
+	val interface{}
+}
+
+func (o obj) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		val, err := json.Marshal(f.val)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// msgpack encodes the subset of types actions are built from.
+func msgpack(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		if v < 0 {
+			return fmt.Errorf("msgpack: negative int %d", v)
+		}
+		packUint(buf, uint64(v))
+	case int64:
+		if v < 0 {
+			return fmt.Errorf("msgpack: negative int %d", v)
+		}
+		packUint(buf, uint64(v))
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n < 1<<8:
+			buf.Write([]byte{0xd9, byte(n)})
+		default:
+			buf.WriteByte(0xda)
+			binary.Write(buf, binary.BigEndian, uint16(n))
+		}
+		buf.WriteString(v)
+	case obj:
+		packHeader(buf, len(v), 0x80, 0xde)
+		for _, f := range v {
+			msgpack(buf, f.key)
+			if err := msgpack(buf, f.val); err != nil {
+				return err
+			}
+		}
+	case []obj:
+		packHeader(buf, len(v), 0x90, 0xdc)
+		for _, o := range v {
+			if err := msgpack(buf, o); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	return nil
+}
+
+func packUint(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v < 128:
+		buf.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(v)})
+	case v <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(v))
+	case v <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(v))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+func packHeader(buf *bytes.Buffer, n int, fix, wide byte) {
+	if n < 16 {
+		buf.WriteByte(fix | byte(n))
+		return
+	}
+	buf.WriteByte(wide)
+	binary.Write(buf, binary.BigEndian, uint16(n))
+}
+
+// actionHash is the connection id an L1 action is signed under: keccak of
+// the msgpack'd action, the nonce and the optional vault address.
+func actionHash(action obj, nonce int64, vault string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := msgpack(&buf, action); err != nil {
+		return nil, err
+	}
+	binary.Write(&buf, binary.BigEndian, uint64(nonce))
+	if vault == "" {
+		buf.WriteByte(0x00)
+	} else {
+		addr, err := hex.DecodeString(strings.TrimPrefix(vault, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid vault address: %w", err)
+		}
+		buf.WriteByte(0x01)
+		buf.Write(addr)
+	}
+	return keccak256(buf.Bytes()), nil
+}
+
+var (
+	eip712DomainType = keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	agentType        = keccak256([]byte("Agent(string source,bytes32 connectionId)"))
+)
+
+// agentDigest is the EIP-712 digest of the "phantom agent" Hyperliquid uses
+// to sign L1 actions: source "a" on mainnet, "b" on testnet.
+func agentDigest(connectionID []byte, mainnet bool) []byte {
+	source := "b"
+	if mainnet {
+		source = "a"
+	}
+
+	chainID := make([]byte, 32)
+	chainID[30], chainID[31] = 0x05, 0x39 // 1337
+	domain := keccak256(eip712DomainType,
+		keccak256([]byte("Exchange")),
+		keccak256([]byte("1")),
+		chainID,
+		make([]byte, 32), // zero verifying contract
+	)
+	agent := keccak256(agentType, keccak256([]byte(source)), connectionID)
+	return keccak256([]byte{0x19, 0x01}, domain, agent)
+}
+
+func signAction(s *Signer, action obj, nonce int64, vault string, mainnet bool) (Signature, error) {
+	hash, err := actionHash(action, nonce, vault)
+	if err != nil {
+		return Signature{}, err
+	}
+	return s.Sign(agentDigest(hash, mainnet))
+}
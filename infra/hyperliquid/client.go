@@ -0,0 +1,672 @@
+// Package hyperliquid trades perpetuals on the Hyperliquid DEX. Orders are
+// signed with a wallet key instead of a CEX API key; the client satisfies
+// the same exchange interface as the Binance client so executors can route
+// part of the flow on-chain.
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+const (
+	MainnetURL = "https://api.hyperliquid.xyz"
+	TestnetURL = "https://api.hyperliquid-testnet.xyz"
+)
+
+var ErrUnknownSymbol = errors.New("symbol not listed on hyperliquid")
+
+// Config selects the wallet and network. AccountAddress is the trading
+// account when PrivateKey belongs to an API (agent) wallet approved for it;
+// empty means the key's own address. Slippage bounds market orders, which
+// Hyperliquid only supports as aggressive IOC limits.
+type Config struct {
+	PrivateKey     string
+	AccountAddress string
+	VaultAddress   string
+	Testnet        bool
+	BaseURL        string
+	Slippage       float64
+	Timeout        time.Duration
+	MetaTTL        time.Duration
+}
+
+type assetInfo struct {
+	index      int
+	coin       string
+	szDecimals int
+}
+
+type Client struct {
+	cfg     Config
+	signer  *Signer
+	account string
+	http    *http.Client
+	stream  *MidStream
+
+	mu        sync.Mutex
+	assets    map[string]assetInfo
+	metaAt    time.Time
+	lastNonce int64
+}
+
+func New(cfg Config) (*Client, error) {
+	signer, err := NewSigner(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = MainnetURL
+		if cfg.Testnet {
+			cfg.BaseURL = TestnetURL
+		}
+	}
+	if cfg.Slippage <= 0 {
+		cfg.Slippage = 0.05
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MetaTTL <= 0 {
+		cfg.MetaTTL = time.Hour
+	}
+
+	account := strings.ToLower(cfg.AccountAddress)
+	if account == "" {
+		account = signer.Address()
+	}
+
+	return &Client{
+		cfg:     cfg,
+		signer:  signer,
+		account: account,
+		http:    &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// UseMidStream prices market orders off a running mid stream instead of a
+// REST round trip.
+func (c *Client) UseMidStream(s *MidStream) {
+	c.stream = s
+}
+
+// Address is the account orders and balances belong to.
+func (c *Client) Address() string {
+	return c.account
+}
+
+func (c *Client) CreateOrder(ctx context.Context, order *trade.Order) (*trade.Order, error) {
+	asset, err := c.asset(ctx, order.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	isBuy := order.Side == trade.SideBuy
+	size := roundSize(order.Quantity, asset.szDecimals)
+	if size <= 0 {
+		return nil, trade.ErrInvalidQuantity
+	}
+
+	price, tif := order.Price, "Gtc"
+	switch order.TimeInForce {
+	case trade.TimeInForceIOC:
+		tif = "Ioc"
+	case trade.TimeInForceGTX:
+		tif = "Alo"
+	case trade.TimeInForceFOK:
+		return nil, fmt.Errorf("hyperliquid: %s orders are not supported", order.TimeInForce)
+	}
+	if order.Type != trade.OrderTypeLimit {
+		if price, err = c.marketPrice(ctx, asset.coin, isBuy); err != nil {
+			return nil, err
+		}
+		tif = "Ioc"
+	}
+
+	orders := []obj{orderWire(asset, isBuy, price, size, false, obj{{"limit", obj{{"tif", tif}}}})}
+	grouping := "na"
+	if order.StopLoss > 0 {
+		orders = append(orders, c.triggerWire(asset, !isBuy, order.StopLoss, size, "sl"))
+		grouping = "normalTpsl"
+	}
+	if order.TakeProfit > 0 {
+		orders = append(orders, c.triggerWire(asset, !isBuy, order.TakeProfit, size, "tp"))
+		grouping = "normalTpsl"
+	}
+
+	var data struct {
+		Statuses []struct {
+			Resting *struct {
+				OID int64 `json:"oid"`
+			} `json:"resting"`
+			Filled *struct {
+				TotalSz string `json:"totalSz"`
+				AvgPx   string `json:"avgPx"`
+				OID     int64  `json:"oid"`
+			} `json:"filled"`
+			Error string `json:"error"`
+		} `json:"statuses"`
+	}
+	action := obj{{"type", "order"}, {"orders", orders}, {"grouping", grouping}}
+	if err := c.exchange(ctx, action, &data); err != nil {
+		return nil, err
+	}
+	if len(data.Statuses) == 0 {
+		return nil, fmt.Errorf("hyperliquid: empty order response")
+	}
+
+	result := *order
+	result.Quantity = size
+	result.Price = roundPrice(price, asset.szDecimals)
+	result.UpdatedAt = time.Now()
+
+	status := data.Statuses[0]
+	switch {
+	case status.Error != "":
+		return nil, fmt.Errorf("hyperliquid order rejected: %s", status.Error)
+	case status.Filled != nil:
+		result.ID = strconv.FormatInt(status.Filled.OID, 10)
+		result.Status = trade.OrderStatusFilled
+		result.FilledQty = parseFloat(status.Filled.TotalSz)
+		result.AvgFillPrice = parseFloat(status.Filled.AvgPx)
+		if result.FilledQty < size {
+			result.Status = trade.OrderStatusPartially
+		}
+	case status.Resting != nil:
+		result.ID = strconv.FormatInt(status.Resting.OID, 10)
+		result.Status = trade.OrderStatusSubmitted
+	}
+	return &result, nil
+}
+
+func (c *Client) CancelOrder(ctx context.Context, orderID string) error {
+	oid, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid hyperliquid order id %q", orderID)
+	}
+
+	// Cancels are addressed by asset, which the order id alone doesn't carry.
+	status, err := c.orderStatus(ctx, oid)
+	if err != nil {
+		return err
+	}
+	asset, err := c.asset(ctx, status.Order.Order.Coin)
+	if err != nil {
+		return err
+	}
+
+	var data struct {
+		Statuses []json.RawMessage `json:"statuses"`
+	}
+	action := obj{{"type", "cancel"}, {"cancels", []obj{{{"a", asset.index}, {"o", oid}}}}}
+	if err := c.exchange(ctx, action, &data); err != nil {
+		return err
+	}
+	for _, s := range data.Statuses {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(s, &e) == nil && e.Error != "" {
+			return fmt.Errorf("hyperliquid cancel rejected: %s", e.Error)
+		}
+	}
+	return nil
+}
+
+func (c *Client) GetOrder(ctx context.Context, orderID string) (*trade.Order, error) {
+	oid, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hyperliquid order id %q", orderID)
+	}
+	status, err := c.orderStatus(ctx, oid)
+	if err != nil {
+		return nil, err
+	}
+
+	o := status.Order.Order
+	side := trade.SideSell
+	if o.Side == "B" {
+		side = trade.SideBuy
+	}
+	orig, remaining := parseFloat(o.OrigSz), parseFloat(o.Sz)
+
+	order := &trade.Order{
+		ID:        orderID,
+		Symbol:    symbolOf(o.Coin),
+		Side:      side,
+		Type:      trade.OrderTypeLimit,
+		Quantity:  orig,
+		Price:     parseFloat(o.LimitPx),
+		FilledQty: orig - remaining,
+		CreatedAt: time.UnixMilli(o.Timestamp),
+		UpdatedAt: time.UnixMilli(status.Order.StatusTimestamp),
+	}
+	switch s := status.Order.Status; {
+	case s == "filled":
+		order.Status = trade.OrderStatusFilled
+	case s == "rejected":
+		order.Status = trade.OrderStatusRejected
+	case strings.HasSuffix(s, "anceled"):
+		order.Status = trade.OrderStatusCancelled
+	case order.FilledQty > 0:
+		order.Status = trade.OrderStatusPartially
+	default:
+		order.Status = trade.OrderStatusSubmitted
+	}
+	return order, nil
+}
+
+func (c *Client) GetPosition(ctx context.Context, symbol string) (*trade.Position, error) {
+	state, err := c.clearinghouse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	coin := coinOf(symbol)
+	for _, ap := range state.AssetPositions {
+		p := ap.Position
+		if p.Coin != coin {
+			continue
+		}
+		szi := parseFloat(p.Szi)
+		if szi == 0 {
+			break
+		}
+
+		pos := &trade.Position{
+			Symbol:     symbolOf(coin),
+			Side:       trade.SideBuy,
+			Quantity:   szi,
+			EntryPrice: parseFloat(p.EntryPx),
+			MarginUsed: parseFloat(p.MarginUsed),
+			PnL:        parseFloat(p.UnrealizedPnl),
+			UpdatedAt:  time.Now(),
+		}
+		if szi < 0 {
+			pos.Side = trade.SideSell
+			pos.Quantity = -szi
+		}
+		if pos.MarginUsed > 0 {
+			pos.PnLPercent = pos.PnL / pos.MarginUsed * 100
+		}
+		return pos, nil
+	}
+	return nil, trade.ErrPositionNotFound
+}
+
+// GetBalance returns the account value in USDC, the margin asset on
+// Hyperliquid.
+func (c *Client) GetBalance(ctx context.Context) (float64, error) {
+	state, err := c.clearinghouse(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return parseFloat(state.MarginSummary.AccountValue), nil
+}
+
+func (c *Client) ClosePosition(ctx context.Context, position *trade.Position) error {
+	asset, err := c.asset(ctx, position.Symbol)
+	if err != nil {
+		return err
+	}
+
+	isBuy := position.Side == trade.SideSell
+	price, err := c.marketPrice(ctx, asset.coin, isBuy)
+	if err != nil {
+		return err
+	}
+
+	size := roundSize(position.Quantity, asset.szDecimals)
+	wire := orderWire(asset, isBuy, price, size, true, obj{{"limit", obj{{"tif", "Ioc"}}}})
+
+	var data struct {
+		Statuses []struct {
+			Error string `json:"error"`
+		} `json:"statuses"`
+	}
+	action := obj{{"type", "order"}, {"orders", []obj{wire}}, {"grouping", "na"}}
+	if err := c.exchange(ctx, action, &data); err != nil {
+		return err
+	}
+	if len(data.Statuses) > 0 && data.Statuses[0].Error != "" {
+		return fmt.Errorf("hyperliquid close rejected: %s", data.Statuses[0].Error)
+	}
+	return nil
+}
+
+// Symbols lists tradable perps as Binance-style symbols.
+func (c *Client) Symbols(ctx context.Context) ([]string, error) {
+	if err := c.loadMeta(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	symbols := make([]string, 0, len(c.assets))
+	for _, a := range c.assets {
+		symbols = append(symbols, symbolOf(a.coin))
+	}
+	return symbols, nil
+}
+
+func (c *Client) Price(ctx context.Context, symbol string) (float64, error) {
+	if c.stream != nil {
+		if px, ok := c.stream.Price(symbol); ok {
+			return px, nil
+		}
+	}
+	mids, err := c.mids(ctx)
+	if err != nil {
+		return 0, err
+	}
+	px, ok := mids[coinOf(symbol)]
+	if !ok {
+		return 0, ErrUnknownSymbol
+	}
+	return px, nil
+}
+
+var intervals = map[string]time.Duration{
+	"1m": time.Minute, "3m": 3 * time.Minute, "5m": 5 * time.Minute,
+	"15m": 15 * time.Minute, "30m": 30 * time.Minute, "1h": time.Hour,
+	"2h": 2 * time.Hour, "4h": 4 * time.Hour, "8h": 8 * time.Hour,
+	"12h": 12 * time.Hour, "1d": 24 * time.Hour,
+}
+
+func (c *Client) Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	step, ok := intervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval %q", interval)
+	}
+	end := time.Now()
+	start := end.Add(-time.Duration(limit) * step)
+
+	var raw []struct {
+		T0 int64  `json:"t"`
+		T1 int64  `json:"T"`
+		O  string `json:"o"`
+		H  string `json:"h"`
+		L  string `json:"l"`
+		C  string `json:"c"`
+		V  string `json:"v"`
+	}
+	req := obj{{"type", "candleSnapshot"}, {"req", obj{
+		{"coin", coinOf(symbol)},
+		{"interval", interval},
+		{"startTime", start.UnixMilli()},
+		{"endTime", end.UnixMilli()},
+	}}}
+	if err := c.post(ctx, "/info", req, &raw); err != nil {
+		return nil, err
+	}
+
+	klines := make([]trade.Kline, 0, len(raw))
+	for _, k := range raw {
+		klines = append(klines, trade.Kline{
+			OpenTime:  time.UnixMilli(k.T0),
+			Open:      parseFloat(k.O),
+			High:      parseFloat(k.H),
+			Low:       parseFloat(k.L),
+			Close:     parseFloat(k.C),
+			Volume:    parseFloat(k.V),
+			CloseTime: time.UnixMilli(k.T1),
+		})
+	}
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
+
+func orderWire(asset assetInfo, isBuy bool, price, size float64, reduceOnly bool, orderType obj) obj {
+	return obj{
+		{"a", asset.index},
+		{"b", isBuy},
+		{"p", floatToWire(roundPrice(price, asset.szDecimals))},
+		{"s", floatToWire(size)},
+		{"r", reduceOnly},
+		{"t", orderType},
+	}
+}
+
+// triggerWire is a reduce-only market stop or take-profit. Its limit price
+// is the trigger widened by the slippage bound so it fills once triggered.
+func (c *Client) triggerWire(asset assetInfo, isBuy bool, trigger, size float64, tpsl string) obj {
+	limit := trigger * (1 - c.cfg.Slippage)
+	if isBuy {
+		limit = trigger * (1 + c.cfg.Slippage)
+	}
+	return orderWire(asset, isBuy, limit, size, true, obj{{"trigger", obj{
+		{"isMarket", true},
+		{"triggerPx", floatToWire(roundPrice(trigger, asset.szDecimals))},
+		{"tpsl", tpsl},
+	}}})
+}
+
+func (c *Client) marketPrice(ctx context.Context, coin string, isBuy bool) (float64, error) {
+	mid, err := c.Price(ctx, symbolOf(coin))
+	if err != nil {
+		return 0, err
+	}
+	if isBuy {
+		return mid * (1 + c.cfg.Slippage), nil
+	}
+	return mid * (1 - c.cfg.Slippage), nil
+}
+
+func (c *Client) mids(ctx context.Context) (map[string]float64, error) {
+	var raw map[string]string
+	if err := c.post(ctx, "/info", obj{{"type", "allMids"}}, &raw); err != nil {
+		return nil, err
+	}
+	mids := make(map[string]float64, len(raw))
+	for coin, px := range raw {
+		mids[coin] = parseFloat(px)
+	}
+	return mids, nil
+}
+
+func (c *Client) asset(ctx context.Context, symbol string) (assetInfo, error) {
+	if err := c.loadMeta(ctx); err != nil {
+		return assetInfo{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	a, ok := c.assets[coinOf(symbol)]
+	if !ok {
+		return assetInfo{}, fmt.Errorf("%w: %s", ErrUnknownSymbol, symbol)
+	}
+	return a, nil
+}
+
+func (c *Client) loadMeta(ctx context.Context) error {
+	c.mu.Lock()
+	fresh := c.assets != nil && time.Since(c.metaAt) < c.cfg.MetaTTL
+	c.mu.Unlock()
+	if fresh {
+		return nil
+	}
+
+	var meta struct {
+		Universe []struct {
+			Name       string `json:"name"`
+			SzDecimals int    `json:"szDecimals"`
+			IsDelisted bool   `json:"isDelisted"`
+		} `json:"universe"`
+	}
+	if err := c.post(ctx, "/info", obj{{"type", "meta"}}, &meta); err != nil {
+		return err
+	}
+
+	// The asset id is the coin's position in the universe, delisted or not.
+	assets := make(map[string]assetInfo, len(meta.Universe))
+	for i, u := range meta.Universe {
+		if u.IsDelisted {
+			continue
+		}
+		assets[u.Name] = assetInfo{index: i, coin: u.Name, szDecimals: u.SzDecimals}
+	}
+
+	c.mu.Lock()
+	c.assets = assets
+	c.metaAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+type orderStatusResponse struct {
+	Status string `json:"status"`
+	Order  struct {
+		Order struct {
+			Coin      string `json:"coin"`
+			Side      string `json:"side"`
+			LimitPx   string `json:"limitPx"`
+			Sz        string `json:"sz"`
+			OrigSz    string `json:"origSz"`
+			Timestamp int64  `json:"timestamp"`
+		} `json:"order"`
+		Status          string `json:"status"`
+		StatusTimestamp int64  `json:"statusTimestamp"`
+	} `json:"order"`
+}
+
+func (c *Client) orderStatus(ctx context.Context, oid int64) (*orderStatusResponse, error) {
+	var resp orderStatusResponse
+	req := obj{{"type", "orderStatus"}, {"user", c.account}, {"oid", oid}}
+	if err := c.post(ctx, "/info", req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "order" {
+		return nil, trade.ErrOrderNotFound
+	}
+	return &resp, nil
+}
+
+type clearinghouseState struct {
+	MarginSummary struct {
+		AccountValue string `json:"accountValue"`
+	} `json:"marginSummary"`
+	AssetPositions []struct {
+		Position struct {
+			Coin          string `json:"coin"`
+			Szi           string `json:"szi"`
+			EntryPx       string `json:"entryPx"`
+			UnrealizedPnl string `json:"unrealizedPnl"`
+			MarginUsed    string `json:"marginUsed"`
+		} `json:"position"`
+	} `json:"assetPositions"`
+}
+
+func (c *Client) clearinghouse(ctx context.Context) (*clearinghouseState, error) {
+	var state clearinghouseState
+	req := obj{{"type", "clearinghouseState"}, {"user", c.account}}
+	if err := c.post(ctx, "/info", req, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// exchange signs and submits an action and decodes response.data into out.
+func (c *Client) exchange(ctx context.Context, action obj, out interface{}) error {
+	nonce := c.nonce()
+	sig, err := signAction(c.signer, action, nonce, c.cfg.VaultAddress, !c.cfg.Testnet)
+	if err != nil {
+		return fmt.Errorf("failed to sign action: %w", err)
+	}
+
+	var vault interface{}
+	if c.cfg.VaultAddress != "" {
+		vault = c.cfg.VaultAddress
+	}
+	body := obj{{"action", action}, {"nonce", nonce}, {"signature", sig}, {"vaultAddress", vault}}
+
+	var resp struct {
+		Status   string          `json:"status"`
+		Response json.RawMessage `json:"response"`
+	}
+	if err := c.post(ctx, "/exchange", body, &resp); err != nil {
+		return err
+	}
+	if resp.Status != "ok" {
+		var msg string
+		if json.Unmarshal(resp.Response, &msg) != nil {
+			msg = string(resp.Response)
+		}
+		return fmt.Errorf("hyperliquid API error: %s", msg)
+	}
+
+	var wrapped struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Response, &wrapped); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if out == nil || len(wrapped.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(wrapped.Data, out)
+}
+
+// nonce is the current time in milliseconds, strictly increasing per client
+// as the exchange rejects reused nonces.
+func (c *Client) nonce() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := time.Now().UnixMilli()
+	if n <= c.lastNonce {
+		n = c.lastNonce + 1
+	}
+	c.lastNonce = n
+	return n
+}
+
+func (c *Client) post(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("hyperliquid API error %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
@@ -0,0 +1,120 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+const testKey = "0x0123456789012345678901234567890123456789012345678901234567890123"
+
+func TestSignerMatchesReferenceVectors(t *testing.T) {
+	s, err := NewSigner("0000000000000000000000000000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0x7e5f4552091a69125d5dfcb7b8c2659029395bdf"; s.Address() != want {
+		t.Fatalf("address = %s, want %s", s.Address(), want)
+	}
+
+	// Vectors from the exchange's reference SDK.
+	s, _ = NewSigner(testKey)
+	action := obj{{"type", "dummy"}, {"num", int64(100000000000)}}
+	cases := []struct {
+		mainnet bool
+		r, s    string
+		v       int
+	}{
+		{true, "53749d5b30552aeb2fca34b530185976545bb22d0b3ce6f62e31be961a59298", "755c40ba9bf05223521753995abb2f73ab3229be8ec921f350cb447e384d8ed8", 27},
+		{false, "542af61ef1f429707e3c76c5293c80d01f74ef853e34b76efffcb57e574f9510", "17b8b32f086e8cdede991f1e2c529f5dd5297cbe8128500e00cbaf766204a613", 28},
+	}
+	for _, tc := range cases {
+		sig, err := signAction(s, action, 0, "", tc.mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameHex(sig.R, tc.r) || !sameHex(sig.S, tc.s) || sig.V != tc.v {
+			t.Errorf("mainnet=%v: got %+v", tc.mainnet, sig)
+		}
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	if got := roundPrice(67123.456, 5); got != 67123 {
+		t.Errorf("roundPrice = %v, want 67123", got)
+	}
+	if got := roundPrice(123456.7, 2); got != 123457 {
+		t.Errorf("roundPrice = %v, want 123457", got)
+	}
+	if got := roundPrice(0.0123456789, 0); got != 0.012346 {
+		t.Errorf("roundPrice = %v, want 0.012346", got)
+	}
+	if got := floatToWire(roundSize(1.23456, 3)); got != "1.235" {
+		t.Errorf("size wire = %s, want 1.235", got)
+	}
+	if coinOf("1000PEPEUSDT") != "kPEPE" || symbolOf("kPEPE") != "1000PEPEUSDT" {
+		t.Error("symbol mapping does not round-trip")
+	}
+}
+
+func TestCreateOrderSignsAndParsesFill(t *testing.T) {
+	var exchangeBody map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if r.URL.Path == "/exchange" {
+			exchangeBody = req
+			w.Write([]byte(`{"status":"ok","response":{"type":"order","data":{"statuses":[{"filled":{"totalSz":"0.01","avgPx":"60010","oid":42}}]}}}`))
+			return
+		}
+		switch string(req["type"]) {
+		case `"meta"`:
+			w.Write([]byte(`{"universe":[{"name":"BTC","szDecimals":5},{"name":"ETH","szDecimals":4}]}`))
+		case `"allMids"`:
+			w.Write([]byte(`{"BTC":"60000","ETH":"3000"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(Config{PrivateKey: testKey, BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := c.CreateOrder(context.Background(), &trade.Order{
+		Symbol: "BTCUSDT", Side: trade.SideBuy, Type: trade.OrderTypeMarket,
+		Quantity: 0.0100004, StopLoss: 59000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != "42" || order.Status != trade.OrderStatusFilled || order.AvgFillPrice != 60010 {
+		t.Fatalf("unexpected order %+v", order)
+	}
+
+	action := string(exchangeBody["action"])
+	for _, want := range []string{`"grouping":"normalTpsl"`, `"s":"0.01"`, `"tif":"Ioc"`, `"tpsl":"sl"`, `"p":"63000"`} {
+		if !strings.Contains(action, want) {
+			t.Errorf("action %s missing %s", action, want)
+		}
+	}
+	if !strings.HasPrefix(action, `{"type":"order","orders":[{"a":0,"b":true`) {
+		t.Errorf("action keys out of order: %s", action)
+	}
+	if _, ok := exchangeBody["signature"]; !ok {
+		t.Error("exchange request not signed")
+	}
+}
+
+func sameHex(got, want string) bool {
+	a, _ := new(big.Int).SetString(strings.TrimPrefix(got, "0x"), 16)
+	b, _ := new(big.Int).SetString(want, 16)
+	return a != nil && b != nil && a.Cmp(b) == 0
+}
@@ -0,0 +1,12 @@
+package hyperliquid
+
+import "golang.org/x/crypto/sha3"
+
+// keccak256 is the original Keccak padding used by Ethereum, not SHA3-256.
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,71 @@
+package hyperliquid
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Hyperliquid has no tick or step size per symbol. Sizes are rounded to the
+// asset's szDecimals; prices keep at most five significant figures and at
+// most 6-szDecimals decimals, except that integer prices are always valid.
+const (
+	maxPriceDecimals = 6
+	maxSigFigs       = 5
+)
+
+func roundSize(sz float64, szDecimals int) float64 {
+	return roundTo(sz, szDecimals)
+}
+
+func roundPrice(px float64, szDecimals int) float64 {
+	if math.Abs(px) >= math.Pow10(maxSigFigs) {
+		return math.Round(px)
+	}
+	sig, _ := strconv.ParseFloat(strconv.FormatFloat(px, 'g', maxSigFigs, 64), 64)
+	return roundTo(sig, maxPriceDecimals-szDecimals)
+}
+
+func roundTo(v float64, decimals int) float64 {
+	if decimals < 0 {
+		decimals = 0
+	}
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
+
+// floatToWire renders a number the way the exchange hashes it: no exponent
+// and no trailing zeros.
+func floatToWire(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 8, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// coinOf maps a Binance-style symbol onto a Hyperliquid coin, so strategies
+// keep using the same symbols: BTCUSDT -> BTC, 1000PEPEUSDT -> kPEPE.
+func coinOf(symbol string) string {
+	coin := strings.ToUpper(symbol)
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if strings.HasSuffix(coin, quote) && len(coin) > len(quote) {
+			coin = strings.TrimSuffix(coin, quote)
+			break
+		}
+	}
+	if strings.HasPrefix(coin, "1000") && len(coin) > 4 {
+		coin = "k" + coin[4:]
+	}
+	return coin
+}
+
+// symbolOf is the inverse of coinOf.
+func symbolOf(coin string) string {
+	if strings.HasPrefix(coin, "k") && len(coin) > 1 {
+		coin = "1000" + coin[1:]
+	}
+	return coin + "USDT"
+}
@@ -0,0 +1,65 @@
+package hyperliquid
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+var ErrInvalidKey = errors.New("invalid wallet private key")
+
+// Signer holds the wallet key that signs exchange actions. Prefer an API
+// (agent) wallet with no withdrawal rights.
+type Signer struct {
+	key     *secp256k1.PrivateKey
+	address string
+}
+
+func NewSigner(privateKeyHex string) (*Signer, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil || len(raw) != 32 {
+		return nil, ErrInvalidKey
+	}
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(raw); overflow || scalar.IsZero() {
+		return nil, ErrInvalidKey
+	}
+	key := secp256k1.NewPrivateKey(&scalar)
+
+	// The address is the tail of the hash of the uncompressed public key,
+	// without its 0x04 prefix.
+	pub := key.PubKey().SerializeUncompressed()
+	addr := keccak256(pub[1:])[12:]
+	return &Signer{key: key, address: "0x" + hex.EncodeToString(addr)}, nil
+}
+
+// Address is the wallet's lower-case hex address.
+func (s *Signer) Address() string {
+	return s.address
+}
+
+type Signature struct {
+	R string `json:"r"`
+	S string `json:"s"`
+	V int    `json:"v"`
+}
+
+// Sign produces a low-s, recoverable signature over a 32-byte digest with an
+// RFC 6979 nonce.
+func (s *Signer) Sign(digest []byte) (Signature, error) {
+	if len(digest) != 32 {
+		return Signature{}, fmt.Errorf("digest must be 32 bytes, got %d", len(digest))
+	}
+	// The compact form is the recovery code, 27 plus the recovery id for
+	// an uncompressed key as Ethereum expects, then r and s.
+	sig := ecdsa.SignCompact(s.key, digest, false)
+	return Signature{
+		R: "0x" + hex.EncodeToString(sig[1:33]),
+		S: "0x" + hex.EncodeToString(sig[33:65]),
+		V: int(sig[0]),
+	}, nil
+}
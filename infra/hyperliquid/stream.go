@@ -0,0 +1,160 @@
+package hyperliquid
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// MidStream keeps the latest mid price of every perp from the allMids
+// websocket channel, reconnecting with backoff.
+type MidStream struct {
+	url     string
+	logger  *logrus.Logger
+	mu      sync.RWMutex
+	mids    map[string]float64
+	running bool
+	stopCh  chan struct{}
+}
+
+func NewMidStream(testnet bool) *MidStream {
+	base := MainnetURL
+	if testnet {
+		base = TestnetURL
+	}
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	return &MidStream{
+		url:    strings.Replace(base, "https://", "wss://", 1) + "/ws",
+		logger: logger,
+		mids:   make(map[string]float64),
+		stopCh: make(chan struct{}),
+	}
+}
+
+func (s *MidStream) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+	go s.run(ctx)
+	return nil
+}
+
+func (s *MidStream) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+// Price returns the last streamed mid for a Binance-style symbol.
+func (s *MidStream) Price(symbol string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	px, ok := s.mids[coinOf(symbol)]
+	return px, ok
+}
+
+func (s *MidStream) run(ctx context.Context) {
+	backoff := time.Second
+	const maxBackoff = time.Minute
+
+	for {
+		err := s.serve(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		default:
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"stream":  "hyperliquid_mids",
+			"error":   errString(err),
+			"backoff": backoff.String(),
+		}).Warn("stream_disconnected")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (s *MidStream) serve(ctx context.Context) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, s.url, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	sub := map[string]interface{}{
+		"method":       "subscribe",
+		"subscription": map[string]string{"type": "allMids"},
+	}
+	if err := conn.WriteJSON(sub); err != nil {
+		return err
+	}
+	s.logger.WithField("stream", "hyperliquid_mids").Info("stream_connected")
+
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.stopCh:
+		case <-closed:
+		}
+		conn.Close()
+	}()
+
+	for {
+		var msg struct {
+			Channel string `json:"channel"`
+			Data    struct {
+				Mids map[string]string `json:"mids"`
+			} `json:"data"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		if msg.Channel != "allMids" {
+			continue
+		}
+
+		s.mu.Lock()
+		for coin, px := range msg.Data.Mids {
+			s.mids[coin] = parseFloat(px)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,104 @@
+package lease
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// renewScript extends the lease only while id still holds it.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes the lease only while id still holds it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+type Config struct {
+	Addr     string
+	Password string
+	DB       int
+	Prefix   string
+}
+
+// RedisLock is a lease on a single Redis key. Acquire is SET NX, renew and
+// release are scripts that check the holder, so an instance that lost the
+// lease cannot extend or drop its successor's.
+type RedisLock struct {
+	client *redis.Client
+	key    string
+}
+
+// RedisSnapshotStore keeps the leader's state snapshot in Redis next to the
+// lease.
+type RedisSnapshotStore struct {
+	client *redis.Client
+	key    string
+}
+
+func NewRedis(cfg Config) (*RedisLock, *RedisSnapshotStore) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "gobot:failover"
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	return &RedisLock{client: client, key: cfg.Prefix + ":leader"},
+		&RedisSnapshotStore{client: client, key: cfg.Prefix + ":state"}
+}
+
+func (l *RedisLock) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	ok, err := l.client.SetNX(ctx, l.key, id, ttl).Result()
+	if err != nil || ok {
+		return ok, err
+	}
+
+	// Re-acquiring our own unexpired lease, e.g. after a restart.
+	return l.Renew(ctx, id, ttl)
+}
+
+func (l *RedisLock) Renew(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	n, err := renewScript.Run(ctx, l.client, []string{l.key}, id, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (l *RedisLock) Release(ctx context.Context, id string) error {
+	return releaseScript.Run(ctx, l.client, []string{l.key}, id).Err()
+}
+
+// Holder returns the instance currently holding the lease, empty if none.
+func (l *RedisLock) Holder(ctx context.Context) (string, error) {
+	id, err := l.client.Get(ctx, l.key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return id, err
+}
+
+func (l *RedisLock) Close() error {
+	return l.client.Close()
+}
+
+func (s *RedisSnapshotStore) Put(ctx context.Context, data []byte) error {
+	return s.client.Set(ctx, s.key, data, 0).Err()
+}
+
+func (s *RedisSnapshotStore) Get(ctx context.Context) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return data, err
+}
@@ -0,0 +1,119 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/britej3/gobot/services/sentiment"
+)
+
+const cryptoPanicURL = "https://cryptopanic.com/api/v1/posts/"
+
+// CryptoPanicSource reads news headlines for futures symbols from the
+// CryptoPanic posts API
+type CryptoPanicSource struct {
+	token   string
+	baseURL string
+	http    *http.Client
+}
+
+// NewCryptoPanicSource creates a headline source authenticated with token
+func NewCryptoPanicSource(token string) *CryptoPanicSource {
+	return &CryptoPanicSource{
+		token:   token,
+		baseURL: cryptoPanicURL,
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Headlines returns the news posts since since that mention the base asset of
+// any of symbols, once per symbol mentioned
+func (s *CryptoPanicSource) Headlines(ctx context.Context, symbols []string, since time.Time) ([]sentiment.Headline, error) {
+	bySymbol := make(map[string][]string)
+	codes := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		code := BaseAsset(symbol)
+		if _, ok := bySymbol[code]; !ok {
+			codes = append(codes, code)
+		}
+		bySymbol[code] = append(bySymbol[code], symbol)
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	q := url.Values{}
+	q.Set("auth_token", s.token)
+	q.Set("currencies", strings.Join(codes, ","))
+	q.Set("kind", "news")
+	q.Set("public", "true")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cryptopanic: %s", resp.Status)
+	}
+	var body struct {
+		Results []struct {
+			Title       string    `json:"title"`
+			PublishedAt time.Time `json:"published_at"`
+			Source      struct {
+				Title string `json:"title"`
+			} `json:"source"`
+			Currencies []struct {
+				Code string `json:"code"`
+			} `json:"currencies"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	var headlines []sentiment.Headline
+	for _, post := range body.Results {
+		if post.PublishedAt.Before(since) {
+			continue
+		}
+		for _, c := range post.Currencies {
+			for _, symbol := range bySymbol[c.Code] {
+				headlines = append(headlines, sentiment.Headline{
+					Symbol:    symbol,
+					Title:     post.Title,
+					Source:    post.Source.Title,
+					Published: post.PublishedAt,
+				})
+			}
+		}
+	}
+	return headlines, nil
+}
+
+// BaseAsset returns the coin a futures symbol trades, without the quote asset
+// and the 1000 multiplier prefix of low-priced contracts
+func BaseAsset(symbol string) string {
+	base := strings.ToUpper(symbol)
+	for _, quote := range []string{"USDT", "USDC", "BUSD"} {
+		if strings.HasSuffix(base, quote) {
+			base = strings.TrimSuffix(base, quote)
+			break
+		}
+	}
+	for _, prefix := range []string{"1000000", "1000"} {
+		if strings.HasPrefix(base, prefix) && len(base) > len(prefix) {
+			return strings.TrimPrefix(base, prefix)
+		}
+	}
+	return base
+}
@@ -0,0 +1,51 @@
+// Package statestore holds the remote backends the trading state can be
+// kept in instead of a local file, so it survives the host and can be read
+// by whichever instance runs next.
+package statestore
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	Key      string
+}
+
+// RedisStore keeps the state as a single Redis string.
+type RedisStore struct {
+	client *redis.Client
+	key    string
+}
+
+func NewRedis(cfg RedisConfig) *RedisStore {
+	if cfg.Key == "" {
+		cfg.Key = "gobot:state"
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	return &RedisStore{client: client, key: cfg.Key}
+}
+
+func (s *RedisStore) Put(ctx context.Context, data []byte) error {
+	return s.client.Set(ctx, s.key, data, 0).Err()
+}
+
+func (s *RedisStore) Get(ctx context.Context) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,51 @@
+package statestore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestS3Store_RoundTripsSignedObject(t *testing.T) {
+	objects := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20240501/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+			t.Errorf("authorization = %q", auth)
+		}
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				http.Error(w, "<Code>NoSuchKey</Code>", http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewS3(S3Config{Endpoint: srv.URL, Region: "eu-west-1", Bucket: "bot-state", Key: "prod/state.json", AccessKey: "AKID", SecretKey: "secret"})
+	s.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	if data, err := s.Get(ctx); err != nil || data != nil {
+		t.Fatalf("missing object = %q, %v", data, err)
+	}
+	if err := s.Put(ctx, []byte(`{"Capital":250}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objects["/bot-state/prod/state.json"]; !ok {
+		t.Fatalf("object stored at %v", objects)
+	}
+	if data, err := s.Get(ctx); err != nil || string(data) != `{"Capital":250}` {
+		t.Fatalf("Get = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,2631 @@
+// Package app wires the dependencies shared by the gobot binaries from a
+// single production config and runs their lifecycle hooks
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gobinance "github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/config"
+	"github.com/britej3/gobot/domain/automation"
+	"github.com/britej3/gobot/domain/executor"
+	"github.com/britej3/gobot/domain/platform"
+	"github.com/britej3/gobot/domain/selector"
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/infra/binance"
+	"github.com/britej3/gobot/infra/eventbus"
+	"github.com/britej3/gobot/infra/hyperliquid"
+	"github.com/britej3/gobot/infra/lease"
+	"github.com/britej3/gobot/infra/news"
+	"github.com/britej3/gobot/infra/statestore"
+	"github.com/britej3/gobot/pkg/alerting"
+	"github.com/britej3/gobot/pkg/apiweight"
+	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/pkg/entrylimit"
+	"github.com/britej3/gobot/pkg/intent"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/pkg/stealth"
+	"github.com/britej3/gobot/pkg/symlock"
+	"github.com/britej3/gobot/services/accountguard"
+	"github.com/britej3/gobot/services/accountsetup"
+	"github.com/britej3/gobot/services/allocator"
+	"github.com/britej3/gobot/services/bookarchive"
+	"github.com/britej3/gobot/services/calendar"
+	"github.com/britej3/gobot/services/configlog"
+	"github.com/britej3/gobot/services/costmodel"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/drift"
+	"github.com/britej3/gobot/services/earnsweep"
+	"github.com/britej3/gobot/services/equity"
+	"github.com/britej3/gobot/services/events"
+	"github.com/britej3/gobot/services/executor/market"
+	"github.com/britej3/gobot/services/failover"
+	"github.com/britej3/gobot/services/feetier"
+	"github.com/britej3/gobot/services/fillcheck"
+	"github.com/britej3/gobot/services/ideas"
+	"github.com/britej3/gobot/services/instancelock"
+	"github.com/britej3/gobot/services/keyhealth"
+	"github.com/britej3/gobot/services/kline"
+	"github.com/britej3/gobot/services/levels"
+	"github.com/britej3/gobot/services/liquidation"
+	"github.com/britej3/gobot/services/listing"
+	"github.com/britej3/gobot/services/margintarget"
+	"github.com/britej3/gobot/services/orderack"
+	"github.com/britej3/gobot/services/orderbook"
+	"github.com/britej3/gobot/services/paperfill"
+	"github.com/britej3/gobot/services/profitlock"
+	"github.com/britej3/gobot/services/protect"
+	"github.com/britej3/gobot/services/regime"
+	"github.com/britej3/gobot/services/research"
+	"github.com/britej3/gobot/services/riskmode"
+	"github.com/britej3/gobot/services/screener"
+	"github.com/britej3/gobot/services/selector/volume"
+	"github.com/britej3/gobot/services/sentiment"
+	"github.com/britej3/gobot/services/squeeze"
+	"github.com/britej3/gobot/services/strategy/external"
+	"github.com/britej3/gobot/services/strategy/grid"
+	"github.com/britej3/gobot/services/strategy/momentum"
+	"github.com/britej3/gobot/services/strategy/rules"
+	"github.com/britej3/gobot/services/strategy/scalper"
+	"github.com/britej3/gobot/services/suspension"
+	"github.com/britej3/gobot/services/symbolmemory"
+	"github.com/britej3/gobot/services/symbolrules"
+	"github.com/britej3/gobot/services/throttle"
+	"github.com/britej3/gobot/services/volspike"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a named pair of lifecycle callbacks. Either callback may be nil.
+// Leader hooks act on the live account; with failover enabled they start
+// when this instance is promoted and stop when it is demoted, rather than
+// with the container, so a standby never touches the account.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+	Leader  bool
+}
+
+// Container lazily builds each dependency on first use and memoizes it, so a
+// binary only pays for the components it actually asks for
+type Container struct {
+	Config *config.ProductionConfig
+
+	mu          sync.Mutex
+	hardened    *binance.HardenedClient
+	weights     *apiweight.Budget
+	rest        *binance.RateLimitedClient
+	stealth     *stealth.StealthClient
+	marketData  *binance.MarketDataProvider
+	futures     *futures.Client
+	state       *state.TradingState
+	telegram    *alerting.TelegramAlert
+	audit       *alerting.AuditLogger
+	brain       *brain.BrainEngine
+	screener    *screener.Screener
+	klines      *kline.Service
+	levels      *levels.Service
+	squeezes    *squeeze.Detector
+	calls       *callpolicy.Policy
+	symbolRules *symbolrules.Registry
+	memory      *symbolmemory.Memory
+	throttle    *throttle.Throttle
+	decisions   *decisionlog.Log
+	suspensions *suspension.Controller
+	guard       *accountguard.Guard
+	exits       *state.Exits
+	userData    *binance.UserDataStream
+	acks        *orderack.Tracker
+	shadow      *paperfill.Shadow
+	entryLimits *entrylimit.Limiter
+	planner     *trade.PositionPlanner
+	riskModes   *riskmode.Switch
+	sentiment   *sentiment.Service
+	orderBooks  *orderbook.Books
+	margin      *margintarget.Controller
+	profitLock  *profitlock.Lock
+	volSpikes   *volspike.Detector
+	cascades    *liquidation.Monitor
+	fills       *fillcheck.Checker
+	costs       *costmodel.Model
+	regime      *regime.Rotator
+	calendar    *calendar.Calendar
+	keyHealth   *keyhealth.Monitor
+	drift       *drift.Checker
+	listing     *listing.Monitor
+	protection  *protect.Guard
+	fees        *feetier.Tracker
+	ideas       *ideas.Inbox
+	research    *research.Server
+	events      *events.Stream
+	equity      *equity.Recorder
+	books       *bookarchive.Archiver
+	configLog   *configlog.Journal
+	posLocks    *symlock.Locks
+	intents     *intent.Tracker
+	exchange    market.ExchangeClient
+	earn        *earnsweep.Sweeper
+	allocator   *allocator.Allocator
+	failover    *failover.Elector
+	instance    *instancelock.Guard
+	engine      *platform.PlatformEngine
+
+	hooks   []Hook
+	started []Hook
+	// leading holds the leader hooks started for the current leadership
+	// term when failover is enabled.
+	leading []Hook
+}
+
+// New creates a container around an already loaded config
+func New(cfg *config.ProductionConfig) *Container {
+	return &Container{Config: cfg}
+}
+
+// Load reads and validates the production config at path and returns a container for it
+func Load(ctx context.Context, path string) (*Container, error) {
+	cfg, err := config.LoadProductionConfig(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg), nil
+}
+
+// FromEnv builds a container from BINANCE_* environment variables only, for
+// binaries that run without a config file
+func FromEnv() *Container {
+	cfg := &config.ProductionConfig{}
+	cfg.Binance.APIKey = os.Getenv("BINANCE_API_KEY")
+	cfg.Binance.APISecret = os.Getenv("BINANCE_API_SECRET")
+	cfg.Binance.UseTestnet = os.Getenv("BINANCE_USE_TESTNET") == "true"
+	cfg.Monitoring.TelegramToken = os.Getenv("TELEGRAM_TOKEN")
+	cfg.Monitoring.TelegramChatID = os.Getenv("TELEGRAM_CHAT_ID")
+	cfg.Monitoring.TelegramEnabled = cfg.Monitoring.TelegramToken != ""
+	cfg.InstanceLock.Enabled = true
+	cfg.Exchange.Venue = os.Getenv("EXCHANGE_VENUE")
+	cfg.Exchange.Hyperliquid.PrivateKey = os.Getenv("HYPERLIQUID_PRIVATE_KEY")
+	cfg.Exchange.Hyperliquid.AccountAddress = os.Getenv("HYPERLIQUID_ACCOUNT_ADDRESS")
+	cfg.Exchange.Hyperliquid.Testnet = os.Getenv("HYPERLIQUID_USE_TESTNET") == "true"
+	return New(cfg)
+}
+
+// Register adds lifecycle hooks. Hooks start in registration order and stop in reverse.
+func (c *Container) Register(h Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, h)
+}
+
+// Start runs every OnStart hook. Components must be resolved before Start for
+// their hooks to run. If one fails, hooks that already started are stopped again.
+// With failover enabled, leader hooks are left to the elector's promotion.
+func (c *Container) Start(ctx context.Context) error {
+	if _, err := c.ConfigVersions(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	hooks := make([]Hook, len(c.hooks))
+	copy(hooks, c.hooks)
+	standby := c.failover != nil
+	c.mu.Unlock()
+
+	for _, h := range hooks {
+		if h.Leader && standby {
+			continue
+		}
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				c.Stop(ctx)
+				return fmt.Errorf("failed to start %s: %w", h.Name, err)
+			}
+		}
+
+		c.mu.Lock()
+		c.started = append(c.started, h)
+		c.mu.Unlock()
+
+		logrus.WithField("component", h.Name).Debug("Component started")
+	}
+
+	return nil
+}
+
+// Stop runs OnStop for every started hook in reverse order and returns the first error
+func (c *Container) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	started := append(c.started, c.leading...)
+	c.started, c.leading = nil, nil
+	c.mu.Unlock()
+
+	var firstErr error
+	for i := len(started) - 1; i >= 0; i-- {
+		h := started[i]
+		if h.OnStop == nil {
+			continue
+		}
+		if err := h.OnStop(ctx); err != nil {
+			logrus.WithError(err).WithField("component", h.Name).Error("Failed to stop component")
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// Binance returns the hardened order client used for live execution
+func (c *Container) Binance() *binance.HardenedClient {
+	weights := c.APIWeight()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.hardened == nil {
+		c.hardened = binance.NewHardenedClient(binance.HardenedConfig{
+			APIKey:    c.Config.Binance.APIKey,
+			APISecret: c.Config.Binance.APISecret,
+			Testnet:   c.Config.Binance.UseTestnet,
+			ReadOnly:  c.Config.Execution.WatchOnly,
+		})
+		c.hardened.SetWeightBudget(weights, apiweight.Executor)
+		if api := c.Config.Binance; api.EndpointSelection && !api.UseTestnet {
+			router := binance.NewEndpointRouter(binance.EndpointConfig{
+				URLs:          api.Endpoints,
+				ProbeInterval: api.GetEndpointProbeInterval(),
+			})
+			c.hardened.SetEndpoints(router)
+			c.hooks = append(c.hooks, Hook{
+				Name:    "endpoints",
+				OnStart: router.Start,
+				OnStop:  func(context.Context) error { return router.Stop() },
+			})
+		}
+	}
+	return c.hardened
+}
+
+// APIWeight returns the per-component request weight budget shared by every
+// Binance REST client
+func (c *Container) APIWeight() *apiweight.Budget {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.weights == nil {
+		c.weights = apiweight.New(apiweight.Config{
+			Limit:   c.Config.Binance.WeightLimit,
+			Quotas:  c.Config.Binance.WeightQuotas,
+			Reserve: c.Config.Binance.WeightReserve,
+		})
+	}
+	return c.weights
+}
+
+// RESTClient returns the rate limited REST client used for market data
+func (c *Container) RESTClient() *binance.RateLimitedClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rest == nil {
+		rps, burst := float64(c.Config.Binance.RateLimitRPS), c.Config.Binance.RateLimitBurst
+		if rps <= 0 {
+			rps = 10
+		}
+		if burst <= 0 {
+			burst = 20
+		}
+
+		client := binance.New(binance.Config{
+			APIKey:    c.Config.Binance.APIKey,
+			APISecret: c.Config.Binance.APISecret,
+			Testnet:   c.Config.Binance.UseTestnet,
+		})
+		c.rest = binance.NewRateLimitedClient(client, rps, burst)
+	}
+	return c.rest
+}
+
+// Stealth returns the request jitter client configured from the stealth section
+func (c *Container) Stealth() *stealth.StealthClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stealth == nil {
+		cfg := c.Config.Stealth
+		jitter := time.Duration(cfg.JitterRangeMS) * time.Millisecond
+		if jitter <= 0 {
+			jitter = 100 * time.Millisecond
+		}
+		delayMin := time.Duration(cfg.RequestDelayMinMS) * time.Millisecond
+		if delayMin <= 0 {
+			delayMin = 50 * time.Millisecond
+		}
+		delayMax := time.Duration(cfg.RequestDelayMaxMS) * time.Millisecond
+		if delayMax <= delayMin {
+			delayMax = 4 * delayMin
+		}
+		variance := cfg.SignatureVariance
+		if variance <= 0 {
+			variance = 0.01
+		}
+
+		c.stealth = stealth.New(stealth.StealthConfig{
+			JitterRange:       jitter,
+			RequestDelayMin:   delayMin,
+			RequestDelayMax:   delayMax,
+			UserAgents:        stealth.CommonUserAgents(),
+			RotateUserAgents:  true,
+			SignatureVariance: variance,
+		})
+	}
+	return c.stealth
+}
+
+// MarketData returns the market data provider behind the REST and stealth clients
+func (c *Container) MarketData() *binance.MarketDataProvider {
+	rest, stealthClient := c.RESTClient(), c.Stealth()
+	klines := c.Klines()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.marketData == nil {
+		c.marketData = binance.NewMarketDataProviderWithStealth(rest, stealthClient)
+		c.marketData.SetIndicators(klines)
+	}
+	return c.marketData
+}
+
+// Futures returns the go-binance futures client used by the internal packages
+func (c *Container) Futures() *futures.Client {
+	weights := c.APIWeight()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.futures == nil {
+		c.futures = c.newFutures(weights, apiweight.Monitors)
+	}
+	return c.futures
+}
+
+// newFutures creates a futures SDK client whose requests are charged to
+// component
+func (c *Container) newFutures(weights *apiweight.Budget, component string) *futures.Client {
+	client := futures.NewClient(c.Config.Binance.APIKey, c.Config.Binance.APISecret)
+	if c.Config.Binance.UseTestnet {
+		client.BaseURL = "https://testnet.binancefuture.com"
+	}
+	client.HTTPClient = binance.WithWeightBudget(client.HTTPClient, weights, component)
+	return client
+}
+
+// State returns the persisted trading state, saving it on shutdown
+func (c *Container) State() (*state.TradingState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == nil {
+		cfg := c.Config.State
+		var store state.Store
+		switch cfg.Backend {
+		case "redis":
+			store = statestore.NewRedis(statestore.RedisConfig{
+				Addr:     cfg.RedisAddr,
+				Password: cfg.RedisPassword,
+				Key:      cfg.RedisKey,
+			})
+		case "s3":
+			store = statestore.NewS3(statestore.S3Config{
+				Endpoint:  cfg.S3.Endpoint,
+				Region:    cfg.S3.Region,
+				Bucket:    cfg.S3.Bucket,
+				Key:       cfg.S3.Key,
+				AccessKey: cfg.S3.AccessKey,
+				SecretKey: cfg.S3.SecretKey,
+			})
+		}
+		st, err := state.NewStateManager(state.StateConfig{
+			StateDir:     cfg.StateDir,
+			StateFile:    cfg.StateFile,
+			SaveInterval: cfg.GetSaveInterval(),
+			Store:        store,
+			Namespace:    c.Config.Namespace,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create state manager: %w", err)
+		}
+		c.state = st
+		c.hooks = append(c.hooks, Hook{
+			Name:   "state",
+			OnStop: func(context.Context) error { return st.Save() },
+		})
+	}
+	return c.state, nil
+}
+
+// Telegram returns the Telegram alert channel from the monitoring section,
+// flushing any pending digest on shutdown
+func (c *Container) Telegram() *alerting.TelegramAlert {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.telegram == nil {
+		mon := c.Config.Monitoring
+		templates := alerting.DefaultTemplates()
+		if mon.TemplatesDir != "" {
+			loaded, err := alerting.LoadTemplates(mon.TemplatesDir, mon.Locale)
+			if err != nil {
+				logrus.WithError(err).Warn("Alert templates unavailable, falling back to English")
+			}
+			if loaded != nil {
+				templates = loaded
+			}
+		}
+
+		tg := alerting.NewTelegramAlert(alerting.TelegramConfig{
+			Token:        mon.TelegramToken,
+			ChatID:       mon.TelegramChatID,
+			Enabled:      mon.TelegramEnabled,
+			DigestWindow: mon.GetTelegramDigestWindow(),
+			DedupWindow:  mon.GetTelegramDedupWindow(),
+			Templates:    templates,
+			Namespace:    c.Config.Namespace,
+		})
+		c.telegram = tg
+		c.hooks = append(c.hooks, Hook{
+			Name:   "telegram",
+			OnStop: func(context.Context) error { return tg.Flush() },
+		})
+	}
+	return c.telegram
+}
+
+// Audit returns the audit and trade logger from the monitoring section
+func (c *Container) Audit() *alerting.AuditLogger {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.audit == nil {
+		c.audit = alerting.NewAuditLogger(alerting.AuditConfig{
+			AuditLogPath:   c.Config.Monitoring.AuditLogPath,
+			TradeLogPath:   c.Config.Monitoring.TradeLogPath,
+			Enabled:        c.Config.Monitoring.AuditLogEnabled,
+			DetailedTrades: c.Config.Monitoring.DetailedTradeLog,
+			SigningKey:     c.Config.Monitoring.AuditSigningKey,
+			Environment:    c.Config.GetAuditEnvironment(),
+			Namespace:      c.Config.Namespace,
+		})
+	}
+	return c.audit
+}
+
+// Brain returns the AI decision engine backed by the futures client
+func (c *Container) Brain() (*brain.BrainEngine, error) {
+	client := c.Futures()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.brain == nil {
+		cfg := brain.DefaultBrainConfig()
+		cfg.DecisionPrompt = c.Config.AI.DecisionPrompt
+		engine, err := brain.NewBrainEngine(client, nil, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create brain engine: %w", err)
+		}
+		c.brain = engine
+		c.hooks = append(c.hooks, Hook{
+			Name:    "brain",
+			OnStart: func(context.Context) error { return engine.Start() },
+			OnStop:  func(context.Context) error { return engine.Stop() },
+		})
+	}
+	return c.brain, nil
+}
+
+// Klines returns the shared candle cache, watching the configured watchlist
+func (c *Container) Klines() *kline.Service {
+	weights := c.APIWeight()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.klines == nil {
+		svc := kline.New(kline.Config{}, binance.NewFuturesKlineSource(c.newFutures(weights, apiweight.Klines)))
+		svc.Watch(c.Config.Watchlist.Symbols...)
+		c.klines = svc
+		c.hooks = append(c.hooks, Hook{
+			Name:    "klines",
+			OnStart: svc.Start,
+			OnStop:  func(context.Context) error { return svc.Stop() },
+		})
+	}
+	return c.klines
+}
+
+// Levels returns the support and resistance finder on the shared candle
+// cache, or nil unless trading.levels_interval is set
+func (c *Container) Levels() *levels.Service {
+	klines := c.Klines()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.levels == nil && c.Config.Trading.LevelsInterval != "" {
+		c.levels = levels.New(levels.Config{
+			Interval:    c.Config.Trading.LevelsInterval,
+			MinStrength: c.Config.Trading.LevelsMinStrength,
+		}, klines)
+	}
+	return c.levels
+}
+
+// Squeezes returns the volatility squeeze detector over the watchlist, or
+// nil unless trading.squeeze_interval is set
+func (c *Container) Squeezes() *squeeze.Detector {
+	cfg := c.Config.Trading
+	if cfg.SqueezeInterval == "" {
+		return nil
+	}
+	klines := c.Klines()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.squeezes == nil {
+		d := squeeze.New(squeeze.Config{
+			Interval: cfg.SqueezeInterval,
+			MinBars:  cfg.SqueezeMinBars,
+			Weight:   cfg.SqueezeWeight,
+		}, klines)
+		d.Watch(c.Config.Watchlist.Symbols...)
+		d.OnSignal(func(s squeeze.Signal) {
+			logrus.WithFields(logrus.Fields{
+				"symbol":    s.Symbol,
+				"direction": s.Direction,
+				"bars":      s.Bars,
+				"momentum":  fmt.Sprintf("%.3f%%", s.Momentum),
+			}).Info("Squeeze released")
+		})
+		c.squeezes = d
+		c.hooks = append(c.hooks, Hook{
+			Name:    "squeeze",
+			OnStart: d.Start,
+			OnStop:  func(context.Context) error { return d.Stop() },
+		})
+	}
+	return c.squeezes
+}
+
+// Calls returns the timeout policy for external calls from the performance section
+func (c *Container) Calls() *callpolicy.Policy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.calls == nil {
+		c.calls = callpolicy.New(c.Config.Performance.CallTimeoutMS)
+	}
+	return c.calls
+}
+
+// SymbolRules returns the exchange filter registry persisted next to the trading state
+func (c *Container) SymbolRules() *symbolrules.Registry {
+	client := c.Futures()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.symbolRules == nil {
+		var path string
+		if c.Config.State.StateDir != "" {
+			path = filepath.Join(c.Config.State.StateDir, "symbol_rules.json")
+		}
+		reg := symbolrules.New(symbolrules.Config{Path: path}, binance.NewFuturesSymbolRulesSource(client))
+		reg.Watch(c.Config.Watchlist.Symbols...)
+		c.symbolRules = reg
+		c.hooks = append(c.hooks, Hook{
+			Name:    "symbol_rules",
+			OnStart: reg.Start,
+			OnStop:  func(context.Context) error { return reg.Stop() },
+		})
+	}
+	return c.symbolRules
+}
+
+// SymbolMemory returns the per-symbol performance memory seeded from the
+// trade journal and fed by every trade closed afterwards, or nil when the
+// symbol_memory section is disabled
+func (c *Container) SymbolMemory() *symbolmemory.Memory {
+	if !c.Config.SymbolMemory.Enabled {
+		return nil
+	}
+	st, err := c.State()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.memory == nil {
+		cfg := c.Config.SymbolMemory
+		mem := symbolmemory.New(symbolmemory.Config{
+			HalfLife:           time.Duration(cfg.HalfLifeHours * float64(time.Hour)),
+			MaxBoost:           cfg.MaxBoost,
+			MaxPenalty:         cfg.MaxPenalty,
+			SlippageAllowance:  cfg.SlippageAllowanceBps,
+			MaxSlippagePenalty: cfg.MaxSlippagePenalty,
+		})
+		if err != nil {
+			logrus.WithError(err).Warn("Symbol memory starts empty: trade journal unavailable")
+		} else {
+			remember := func(t state.Trade) {
+				mem.Record(t.Symbol, t.PnLPercent, t.ExitTime)
+				if bps, ok := t.SlippageBps(); ok {
+					mem.RecordSlippage(t.Symbol, bps, t.EntryTime)
+				}
+			}
+			for _, t := range st.Trades() {
+				remember(t)
+			}
+			st.OnTrade(remember)
+		}
+		c.memory = mem
+	}
+	return c.memory
+}
+
+// SymbolThrottle returns the throttle that raises the entry threshold of
+// symbols with negative realized expectancy and suspends the worst, seeded
+// from the trade journal and fed by every trade closed afterwards, or nil
+// when the symbol_throttle section is disabled
+func (c *Container) SymbolThrottle() (*throttle.Throttle, error) {
+	if !c.Config.SymbolThrottle.Enabled {
+		return nil, nil
+	}
+	st, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+	suspensions, err := c.Suspensions()
+	if err != nil {
+		return nil, err
+	}
+	audit := c.Audit()
+	feeRate := c.FeeRate()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.throttle == nil {
+		cfg := c.Config.SymbolThrottle
+		th := throttle.New(throttle.Config{
+			Window:         cfg.WindowTrades,
+			MinTrades:      cfg.MinTrades,
+			ConfidenceStep: cfg.ConfidenceStep,
+			SuspendBps:     cfg.SuspendBps,
+			Cooldown:       time.Duration(cfg.CooldownHours * float64(time.Hour)),
+			OnChange: func(ch throttle.Change) {
+				logrus.WithFields(logrus.Fields{
+					"symbol":     ch.Symbol,
+					"action":     ch.Action,
+					"expectancy": ch.Expectancy,
+					"trades":     ch.Trades,
+				}).Warn("Symbol throttle: " + ch.String())
+				audit.Log("SYMBOL_THROTTLE", map[string]interface{}{
+					"symbol":         ch.Symbol,
+					"action":         ch.Action,
+					"expectancy_bps": ch.Expectancy,
+					"trades":         ch.Trades,
+					"reason":         ch.Reason,
+				})
+			},
+		}, suspensions)
+
+		record := func(t state.Trade) {
+			fill := t.FillPrice
+			if fill <= 0 {
+				fill = t.EntryPrice
+			}
+			th.Record(t.Symbol, throttle.Edge(t.Side, fill, t.ExitPrice, feeRate(t.Symbol)), t.ExitTime)
+		}
+		for _, t := range st.Trades() {
+			record(t)
+		}
+		st.OnTrade(record)
+
+		c.throttle = th
+		c.hooks = append(c.hooks, Hook{
+			Name:    "symbol_throttle",
+			OnStart: th.Start,
+			OnStop:  func(context.Context) error { return th.Stop() },
+		})
+	}
+	return c.throttle, nil
+}
+
+// PositionLocks returns the per-symbol locks every component that opens,
+// closes or amends positions shares
+func (c *Container) PositionLocks() *symlock.Locks {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.posLocks == nil {
+		c.posLocks = symlock.New()
+	}
+	return c.posLocks
+}
+
+// Intents returns the lifecycle tracker shared by every entry path. Its
+// transitions are published on the event stream, and intents on a symbol
+// close when a trade on it is journaled.
+func (c *Container) Intents() *intent.Tracker {
+	st, err := c.State()
+	stream := c.Events()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.intents == nil {
+		tracker := intent.New(intent.Config{}, stream)
+		if err == nil {
+			st.OnTrade(func(t state.Trade) { tracker.CloseSymbol(t.Symbol, "trade closed") })
+		}
+		c.intents = tracker
+	}
+	return c.intents
+}
+
+// EntryLimits returns the per-session cap on new positions shared by every
+// entry path, or nil when the entry_limits section is disabled
+func (c *Container) EntryLimits() *entrylimit.Limiter {
+	if !c.Config.EntryLimits.Enabled {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entryLimits == nil {
+		cfg := c.Config.EntryLimits
+		sessions := make([]entrylimit.Session, 0, len(cfg.Sessions))
+		for _, s := range cfg.Sessions {
+			start, end, err := s.Hours()
+			if err != nil {
+				continue
+			}
+			sessions = append(sessions, entrylimit.Session{
+				Name:       s.Name,
+				Start:      start,
+				End:        end,
+				MaxEntries: s.MaxEntries,
+				Window:     time.Duration(s.WindowMinutes) * time.Minute,
+			})
+		}
+		c.entryLimits = entrylimit.New(entrylimit.Config{
+			Sessions: sessions,
+			Default: entrylimit.Session{
+				MaxEntries: cfg.MaxEntries,
+				Window:     time.Duration(cfg.WindowMinutes) * time.Minute,
+			},
+		})
+	}
+	return c.entryLimits
+}
+
+// Decisions returns the decision log at monitoring.decision_log_path, or nil
+// when no path is configured
+func (c *Container) Decisions() (*decisionlog.Log, error) {
+	path := c.Config.Monitoring.DecisionLogPath
+	if path == "" {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.decisions == nil {
+		l, err := decisionlog.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open decision log: %w", err)
+		}
+		l.SetNamespace(c.Config.Namespace)
+		c.decisions = l
+		c.hooks = append(c.hooks, Hook{
+			Name:   "decisions",
+			OnStop: func(context.Context) error { return l.Close() },
+		})
+	}
+	return c.decisions, nil
+}
+
+// ConfigVersions returns the config version journal at
+// monitoring.config_log_path, or nil when no path is configured. Starting the
+// container records the effective config as a new version if it changed
+// since the last run; runtime changes are recorded with RecordConfig.
+func (c *Container) ConfigVersions() (*configlog.Journal, error) {
+	path := c.Config.Monitoring.ConfigLogPath
+	if path == "" {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.configLog == nil {
+		j, err := configlog.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open config log: %w", err)
+		}
+		c.configLog = j
+		c.hooks = append(c.hooks, Hook{
+			Name: "config-versions",
+			OnStart: func(context.Context) error {
+				return c.RecordConfig(configlog.SourceStartup, "")
+			},
+			OnStop: func(context.Context) error { return j.Close() },
+		})
+	}
+	return c.configLog, nil
+}
+
+// RecordConfig journals the effective config as a new version when it
+// differs from the last one, logging what changed
+func (c *Container) RecordConfig(source, note string) error {
+	j, err := c.ConfigVersions()
+	if err != nil || j == nil {
+		return err
+	}
+	settings, err := configlog.Flatten(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot config: %w", err)
+	}
+	v, changed, err := j.Record(source, note, settings)
+	if err != nil {
+		return fmt.Errorf("failed to record config version: %w", err)
+	}
+	if changed {
+		logrus.WithFields(logrus.Fields{
+			"version": v.Version,
+			"source":  source,
+			"changes": len(v.Changes),
+		}).Info("Config version recorded")
+	}
+	return nil
+}
+
+// Suspensions returns the per-strategy and per-symbol suspension controller,
+// persisted in the trading state. Once started it syncs the suspensions
+// directory and answers /disable, /enable and /suspended in the alert chat,
+// /resume for the account guard and /risk for the risk modes.
+func (c *Container) Suspensions() (*suspension.Controller, error) {
+	st, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+	modes, err := c.RiskModes()
+	if err != nil {
+		return nil, err
+	}
+	tg := c.Telegram()
+	guard := c.AccountGuard()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.suspensions == nil {
+		ctrl := suspension.New(suspension.Config{
+			Dir:      c.Config.Emergency.SuspensionsDir,
+			Password: c.Config.Emergency.KillSwitchPassword,
+		}, st)
+		c.suspensions = ctrl
+
+		var cancel context.CancelFunc
+		c.hooks = append(c.hooks, Hook{
+			Name: "suspensions",
+			OnStart: func(ctx context.Context) error {
+				ctx, cancel = context.WithCancel(ctx)
+				go ctrl.Run(ctx)
+				go tg.Interactive(ctx, func(cmd alerting.Command) (alerting.Reply, bool) {
+					if modes != nil {
+						if reply, ok := modes.Command(cmd.Text, cmd.From); ok {
+							return telegramReply(reply), true
+						}
+					}
+					if guard != nil {
+						if reply, ok := guard.Command(cmd.Text); ok {
+							return alerting.Reply{Text: reply}, true
+						}
+					}
+					reply, ok := ctrl.Command(cmd.Text)
+					return alerting.Reply{Text: reply}, ok
+				})
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				if cancel != nil {
+					cancel()
+				}
+				return nil
+			},
+		})
+	}
+	return c.suspensions, nil
+}
+
+func telegramReply(r riskmode.Reply) alerting.Reply {
+	out := alerting.Reply{Text: r.Text}
+	for _, row := range r.Keyboard {
+		buttons := make([]alerting.Button, 0, len(row))
+		for _, b := range row {
+			buttons = append(buttons, alerting.Button{Text: b.Text, Data: b.Data})
+		}
+		out.Keyboard = append(out.Keyboard, buttons)
+	}
+	return out
+}
+
+// AccountGuard returns the guard that pauses entries on account activity the
+// bot did not cause, fed by the futures account stream and alerting in the
+// alert chat, or nil when emergency.pause_on_foreign_activity is off
+func (c *Container) AccountGuard() *accountguard.Guard {
+	if !c.Config.Emergency.PauseOnForeignActivity {
+		return nil
+	}
+	stream := c.UserData()
+	tg := c.Telegram()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.guard == nil {
+		cfg := c.Config.Emergency
+		guard := accountguard.New(accountguard.Config{
+			OwnPrefixes: []string{binance.OrderIDPrefix},
+			// The earn sweep moves margin in and out of futures itself.
+			IgnoreTransfers: cfg.IgnoreTransfers || c.Config.Earn.Enabled,
+			PauseFor:        time.Duration(cfg.ForeignPauseMinutes) * time.Minute,
+		})
+		guard.OnAnomaly(func(a accountguard.Anomaly) {
+			logrus.WithFields(logrus.Fields{
+				"kind":   a.Kind,
+				"symbol": a.Symbol,
+				"detail": a.Detail,
+			}).Warn("Foreign account activity")
+			if a.Paused {
+				tg.Send(alerting.AlertRiskBreach, fmt.Sprintf("Entries paused on %s. Send /resume once the account is the bot's again.", a))
+			}
+		})
+		stream.OnOrder(func(u accountguard.OrderUpdate) { guard.Order(u) })
+		stream.OnAccount(func(u accountguard.AccountUpdate) { guard.Account(u) })
+		c.guard = guard
+	}
+	return c.guard
+}
+
+// Exits returns the tracker that journals the state's positions as trades
+// once the account stream reports them closed, by their protective orders,
+// a reduce-only close or by hand
+func (c *Container) Exits() (*state.Exits, error) {
+	st, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+	stream := c.UserData()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.exits == nil {
+		exits := state.NewExits(st)
+		stream.OnOrder(func(u accountguard.OrderUpdate) {
+			exits.Fill(u.Symbol, u.OrderID, u.Side, u.Filled, u.AvgPrice)
+		})
+		stream.OnAccount(func(u accountguard.AccountUpdate) {
+			for symbol, amount := range u.Positions {
+				exits.Position(symbol, amount)
+			}
+		})
+		c.exits = exits
+	}
+	return c.exits, nil
+}
+
+// Allocator returns the split of strategies.allocation.budget between signal
+// sources, or nil when no budget is set. Journaled trades give their
+// notional back to the source that opened them and feed its returns.
+func (c *Container) Allocator() (*allocator.Allocator, error) {
+	cfg := c.Config.Strategies.Allocation
+	if cfg.Budget <= 0 {
+		return nil, nil
+	}
+	st, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.allocator == nil {
+		alloc := allocator.New(allocator.Config{
+			Method:            allocator.Method(cfg.Method),
+			Budget:            cfg.Budget,
+			Weights:           cfg.Weights,
+			MinWeight:         cfg.MinWeight,
+			RebalanceInterval: time.Duration(cfg.RebalanceMinutes) * time.Minute,
+		})
+		st.OnTrade(func(t state.Trade) {
+			if t.Strategy == "" {
+				return
+			}
+			alloc.Release(t.Strategy, t.Size*t.EntryPrice)
+			alloc.RecordReturn(t.Strategy, t.PnLPercent)
+		})
+		c.allocator = alloc
+		c.hooks = append(c.hooks, Hook{
+			Name:    "allocator",
+			OnStart: alloc.Start,
+			OnStop:  func(context.Context) error { return alloc.Stop() },
+		})
+	}
+	return c.allocator, nil
+}
+
+// UserData returns the futures account stream. Components register their
+// handlers on it before the container starts.
+func (c *Container) UserData() *binance.UserDataStream {
+	client := c.Futures()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.userData == nil {
+		stream := binance.NewUserDataStream(client)
+		c.userData = stream
+		c.hooks = append(c.hooks, Hook{
+			Name:    "user-data",
+			OnStart: stream.Start,
+			OnStop:  func(context.Context) error { return stream.Stop() },
+		})
+	}
+	return c.userData
+}
+
+// OrderAcks returns the tracker that completes entries submitted without
+// waiting, fed by the account stream, or nil when execution.async_orders is
+// off
+func (c *Container) OrderAcks() *orderack.Tracker {
+	if !c.Config.Execution.AsyncOrders {
+		return nil
+	}
+	stream := c.UserData()
+	client := c.Binance()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.acks == nil {
+		acks := orderack.New(orderack.Config{
+			Timeout: time.Duration(c.Config.Execution.AckTimeoutSecs) * time.Second,
+		}, binance.NewOrderAckVenue(client))
+		stream.OnOrder(func(u accountguard.OrderUpdate) {
+			acks.Update(orderack.Update{
+				Symbol:        u.Symbol,
+				OrderID:       u.OrderID,
+				ClientOrderID: u.ClientOrderID,
+				Status:        u.Status,
+				Filled:        u.Filled,
+				AvgPrice:      u.AvgPrice,
+			})
+		})
+		c.acks = acks
+		c.hooks = append(c.hooks, Hook{
+			Name:    "order_acks",
+			OnStart: acks.Start,
+			OnStop:  func(context.Context) error { return acks.Stop() },
+		})
+	}
+	return c.acks
+}
+
+// ShadowFills returns the paper simulator shadowing live entries, settled
+// by every trade journaled, or nil when execution.shadow_fills is off
+func (c *Container) ShadowFills() *paperfill.Shadow {
+	if !c.Config.Execution.ShadowFills {
+		return nil
+	}
+	st, err := c.State()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shadow == nil {
+		shadow := paperfill.New(paperfill.Config{SlippageBps: c.Config.Execution.ShadowSlippageBps})
+		if err == nil {
+			st.OnTrade(shadow.Close)
+		}
+		c.shadow = shadow
+	}
+	return c.shadow
+}
+
+// Planner returns the position planner every entry is sized with,
+// throttled account-wide after volatility spikes when risk.spike_zscore is
+// set
+func (c *Container) Planner() *trade.PositionPlanner {
+	spikes := c.VolSpikes()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.planner == nil {
+		c.planner = trade.NewPositionPlanner(SizingLimits(c.Config))
+		if spikes != nil {
+			c.planner.SetThrottle(spikes)
+		}
+	}
+	return c.planner
+}
+
+// VolSpikes returns the volatility spike detector over the watchlist's 1m
+// candles, or nil when risk.spike_zscore is unset
+func (c *Container) VolSpikes() *volspike.Detector {
+	risk := c.Config.Risk
+	if risk.SpikeZScore <= 0 {
+		return nil
+	}
+	klines := c.Klines()
+	tg := c.Telegram()
+	audit := c.Audit()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.volSpikes == nil {
+		d := volspike.New(volspike.Config{
+			ZScore:      risk.SpikeZScore,
+			Duration:    time.Duration(risk.SpikeMinutes) * time.Minute,
+			MaxLeverage: risk.SpikeMaxLeverage,
+			SizeFactor:  risk.SpikeSizeFactor,
+		}, klines)
+		d.Watch(c.Config.Watchlist.Symbols...)
+		d.OnSpike(func(s volspike.Spike) {
+			logrus.WithFields(logrus.Fields{
+				"symbol":  s.Symbol,
+				"z_score": fmt.Sprintf("%.1f", s.ZScore),
+				"return":  fmt.Sprintf("%.2f%%", s.Return*100),
+				"until":   s.Until.Format(time.Kitchen),
+			}).Warn("Volatility spike: capping leverage and size account-wide")
+			audit.Log("VOLATILITY_SPIKE", map[string]interface{}{
+				"symbol":  s.Symbol,
+				"z_score": s.ZScore,
+				"return":  s.Return,
+				"until":   s.Until,
+			})
+			tg.Send(alerting.AlertRiskBreach, fmt.Sprintf("Volatility spike on %s (z %.1f, %.2f%%): entries throttled until %s",
+				s.Symbol, s.ZScore, s.Return*100, s.Until.Format(time.Kitchen)))
+		})
+		c.volSpikes = d
+		c.hooks = append(c.hooks, Hook{
+			Name:    "volspike",
+			OnStart: d.Start,
+			OnStop:  func(context.Context) error { return d.Stop() },
+		})
+	}
+	return c.volSpikes
+}
+
+// Liquidations returns the liquidation cascade monitor fed by the
+// market-wide forceOrder stream, or nil when neither
+// risk.cascade_symbol_notional nor cascade_market_notional is set
+func (c *Container) Liquidations() *liquidation.Monitor {
+	risk := c.Config.Risk
+	if risk.CascadeSymbolNotional <= 0 && risk.CascadeMarketNotional <= 0 {
+		return nil
+	}
+	tg := c.Telegram()
+	audit := c.Audit()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cascades == nil {
+		m := liquidation.New(liquidation.Config{
+			Window:          time.Duration(risk.CascadeWindowSeconds) * time.Second,
+			SymbolThreshold: risk.CascadeSymbolNotional,
+			MarketThreshold: risk.CascadeMarketNotional,
+			Cooldown:        time.Duration(risk.CascadeCooldownMinutes) * time.Minute,
+		})
+		m.OnCascade(func(s liquidation.Stats) {
+			scope := s.Symbol
+			if scope == "" {
+				scope = "the market"
+			}
+			logrus.WithFields(logrus.Fields{
+				"scope":            scope,
+				"long_liquidated":  fmt.Sprintf("%.0f", s.LongLiquidated),
+				"short_liquidated": fmt.Sprintf("%.0f", s.ShortLiquidated),
+				"until":            s.CascadeUntil.Format(time.Kitchen),
+			}).Warn("Liquidation cascade: pausing entries")
+			audit.Log("LIQUIDATION_CASCADE", map[string]interface{}{
+				"symbol":           s.Symbol,
+				"long_liquidated":  s.LongLiquidated,
+				"short_liquidated": s.ShortLiquidated,
+				"until":            s.CascadeUntil,
+			})
+			tg.Send(alerting.AlertRiskBreach, fmt.Sprintf("Liquidation cascade in %s ($%.0f liquidated): entries paused until %s",
+				scope, s.Total(), s.CascadeUntil.Format(time.Kitchen)))
+		})
+		stream := binance.NewLiquidationStream(m)
+		c.cascades = m
+		c.hooks = append(c.hooks, Hook{
+			Name:    "liquidations",
+			OnStart: stream.Start,
+			OnStop:  func(context.Context) error { return stream.Stop() },
+		})
+	}
+	return c.cascades
+}
+
+// RiskModes returns the risk profile switch answering /risk in the alert
+// chat, or nil when risk_modes.enabled is off. The active profile sets the
+// planner's risk per trade and leverage cap and the screener's volume floor
+// and pair count; the engine reads its confidence and trade limits.
+func (c *Container) RiskModes() (*riskmode.Switch, error) {
+	if !c.Config.RiskModes.Enabled {
+		return nil, nil
+	}
+	planner := c.Planner()
+	audit := c.Audit()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.riskModes == nil {
+		apply := func(p riskmode.Profile) {
+			planner.SetLimits(ProfileLimits(c.Config, p))
+			c.mu.Lock()
+			s := c.screener
+			c.mu.Unlock()
+			if s != nil {
+				s.Tune(p.MinVolume24h, p.MaxPairs)
+			}
+			logrus.WithField("profile", p.Name).Info("Risk mode applied")
+		}
+		sw, err := riskmode.New(riskmode.Config{
+			Profiles:      riskProfiles(c.Config.RiskModes),
+			Initial:       c.Config.RiskModes.Initial,
+			ConfirmWindow: time.Duration(c.Config.RiskModes.ConfirmSeconds) * time.Second,
+			OnSwitch:      apply,
+		}, audit)
+		if err != nil {
+			return nil, err
+		}
+		planner.SetLimits(ProfileLimits(c.Config, sw.Current()))
+		c.riskModes = sw
+	}
+	return c.riskModes, nil
+}
+
+// riskProfiles returns the built-in profiles with the configured overrides,
+// followed by any profiles only the config defines
+func riskProfiles(cfg config.RiskModesConfig) []riskmode.Profile {
+	profiles := riskmode.Defaults()
+	known := make(map[string]bool, len(profiles))
+	for i := range profiles {
+		known[profiles[i].Name] = true
+		if o, ok := cfg.Profiles[profiles[i].Name]; ok {
+			profiles[i] = overrideProfile(profiles[i], o)
+		}
+	}
+	extra := make([]string, 0)
+	for name := range cfg.Profiles {
+		if !known[strings.ToLower(name)] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		profiles = append(profiles, overrideProfile(riskmode.Profile{Name: strings.ToLower(name)}, cfg.Profiles[name]))
+	}
+	return profiles
+}
+
+func overrideProfile(p riskmode.Profile, o config.RiskProfile) riskmode.Profile {
+	if o.MinConfidence > 0 {
+		p.MinConfidence = o.MinConfidence
+	}
+	if o.RiskPerTrade > 0 {
+		p.RiskPerTrade = o.RiskPerTrade
+	}
+	if o.MaxLeverage > 0 {
+		p.MaxLeverage = o.MaxLeverage
+	}
+	if o.MaxTradesPerDay > 0 {
+		p.MaxTradesPerDay = o.MaxTradesPerDay
+	}
+	if o.MinVolume24hUSD > 0 {
+		p.MinVolume24h = o.MinVolume24hUSD
+	}
+	if o.MaxPairs > 0 {
+		p.MaxPairs = o.MaxPairs
+	}
+	return p
+}
+
+// ProfileLimits returns the sizing limits of cfg under risk profile p
+func ProfileLimits(cfg *config.ProductionConfig, p riskmode.Profile) trade.SizingLimits {
+	limits := SizingLimits(cfg)
+	if p.RiskPerTrade > 0 {
+		limits.RiskPerTrade = p.RiskPerTrade
+	}
+	if p.MaxLeverage > 0 && (limits.MaxLeverage == 0 || p.MaxLeverage < limits.MaxLeverage) {
+		limits.MaxLeverage = p.MaxLeverage
+	}
+	return limits
+}
+
+// SizingLimits returns the position planner limits from the trading and
+// account sections: trading.max_risk_per_trade of capital at the stop, at
+// most trading.max_position_usd, at account.leverage and no more than
+// account.max_leverage
+func SizingLimits(cfg *config.ProductionConfig) trade.SizingLimits {
+	return trade.SizingLimits{
+		RiskPerTrade:    cfg.Trading.MaxRiskPerTrade,
+		MaxNotional:     cfg.Trading.MaxPositionUSD,
+		DefaultLeverage: cfg.Account.Leverage,
+		MaxLeverage:     cfg.Account.MaxLeverage,
+		StopPercent:     cfg.Trading.StopLossPercent / 100,
+		TargetPercent:   cfg.Trading.TakeProfitPercent / 100,
+	}
+}
+
+// Calendar returns the trading calendar that closes entries during
+// maintenance and on halted symbols, or nil when calendar.enabled is off
+func (c *Container) Calendar() *calendar.Calendar {
+	if !c.Config.Calendar.Enabled {
+		return nil
+	}
+	client := c.Futures()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.calendar == nil {
+		cfg := c.Config.Calendar
+		windows := make([]calendar.Window, 0, len(cfg.Windows))
+		for _, w := range cfg.Windows {
+			start, end, err := w.Bounds()
+			if err != nil {
+				continue
+			}
+			windows = append(windows, calendar.Window{Start: start, End: end, Symbols: w.Symbols, Reason: w.Reason})
+		}
+
+		source := binance.NewMainnetCalendarSource(client)
+		if c.Config.Binance.UseTestnet {
+			source = binance.NewFuturesCalendarSource(client, "")
+		}
+		cal := calendar.New(calendar.Config{
+			Windows:      windows,
+			PollInterval: time.Duration(cfg.PollSeconds) * time.Second,
+			Lead:         time.Duration(cfg.LeadMinutes) * time.Minute,
+			Grace:        time.Duration(cfg.GraceMinutes) * time.Minute,
+		}, source)
+		c.calendar = cal
+		c.hooks = append(c.hooks, Hook{
+			Name:    "calendar",
+			OnStart: cal.Start,
+			OnStop:  func(context.Context) error { return cal.Stop() },
+		})
+	}
+	return c.calendar
+}
+
+// KeyHealth returns the monitor that checks the API key and holds entries
+// while Binance rejects it, alerting on rejection, recovery and coming
+// expiry, or nil when key_health.enabled is off
+func (c *Container) KeyHealth() *keyhealth.Monitor {
+	if !c.Config.KeyHealth.Enabled {
+		return nil
+	}
+	client := c.Futures()
+	tg := c.Telegram()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keyHealth == nil {
+		cfg := c.Config.KeyHealth
+		// The API key permission endpoint is spot only and not on testnet.
+		var spot *gobinance.Client
+		if !c.Config.Binance.UseTestnet {
+			spot = gobinance.NewClient(c.Config.Binance.APIKey, c.Config.Binance.APISecret)
+		}
+		monitor := keyhealth.New(keyhealth.Config{
+			Interval:      time.Duration(cfg.IntervalSeconds) * time.Second,
+			ExpiryWarning: time.Duration(cfg.ExpiryWarningDays) * 24 * time.Hour,
+		}, binance.NewKeyHealthSource(client, spot))
+		monitor.OnEvent(func(e keyhealth.Event) {
+			entry := logrus.WithFields(logrus.Fields{"kind": e.Kind, "reason": e.Reason})
+			switch e.Kind {
+			case keyhealth.EventInvalid:
+				entry.Error("API key rejected, entries stopped")
+				tg.Send(alerting.AlertRiskBreach, fmt.Sprintf("%s. Entries are stopped until the key works again.", e))
+			case keyhealth.EventRecovered:
+				entry.Info("API key accepted again, entries resumed")
+				tg.Send(alerting.AlertRiskBreach, fmt.Sprintf("%s. Entries resumed.", e))
+			case keyhealth.EventExpiring:
+				entry.WithField("expires", e.Expires).Warn("API key expiring")
+				tg.Send(alerting.AlertRiskBreach, fmt.Sprintf("%s. Renew it before then or entries stop.", e))
+			}
+		})
+		c.keyHealth = monitor
+		c.hooks = append(c.hooks, Hook{
+			Name:    "key-health",
+			OnStart: monitor.Start,
+			OnStop:  func(context.Context) error { return monitor.Stop() },
+		})
+	}
+	return c.keyHealth
+}
+
+// Drift returns the checker that compares the live account with the config
+// and alerts each drift with its fix, or nil when drift.enabled is off
+func (c *Container) Drift() *drift.Checker {
+	if !c.Config.Drift.Enabled {
+		return nil
+	}
+	client := c.Futures()
+	tg := c.Telegram()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.drift == nil {
+		cfg := c.Config.Drift
+		checker := drift.New(drift.Config{
+			Interval:     time.Duration(cfg.IntervalSeconds) * time.Second,
+			Symbols:      c.Config.Watchlist.Symbols,
+			Leverage:     c.Config.Account.Leverage,
+			MaxLeverage:  c.Config.Account.MaxLeverage,
+			MaxPositions: cfg.MaxPositions,
+			RequireStops: cfg.RequireStops,
+		}, binance.NewFuturesDriftSource(client))
+		checker.OnDrift(func(d drift.Drift) {
+			entry := logrus.WithFields(logrus.Fields{"kind": d.Kind, "symbol": d.Symbol, "detail": d.Detail})
+			if d.Resolved {
+				entry.Info("Config drift resolved")
+			} else {
+				entry.WithField("remedy", d.Remedy).Warn("Config drift")
+			}
+			tg.Send(alerting.AlertRiskBreach, d.String())
+		})
+		c.drift = checker
+		c.hooks = append(c.hooks, Hook{
+			Name:    "drift",
+			OnStart: checker.Start,
+			OnStop:  func(context.Context) error { return checker.Stop() },
+		})
+	}
+	return c.drift
+}
+
+// Listing returns the monitor that diffs exchangeInfo for new listings and
+// delistings and keeps delisted symbols out of the screener, or nil when
+// listing.enabled is off
+func (c *Container) Listing() *listing.Monitor {
+	if !c.Config.Listing.Enabled {
+		return nil
+	}
+	client := c.Futures()
+	weights := c.APIWeight()
+	tg := c.Telegram()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.listing == nil {
+		cfg := c.Config.Listing
+		symbols := binance.NewScreenerClient(binance.Config{Testnet: c.Config.Binance.UseTestnet})
+		symbols.SetWeightBudget(weights, apiweight.Screener)
+		monitor := listing.New(listing.Config{
+			Interval:         time.Duration(cfg.IntervalSeconds) * time.Second,
+			NewListingWindow: time.Duration(cfg.NewListingHours * float64(time.Hour)),
+			DelistingHorizon: time.Duration(cfg.DelistingHorizonDays * float64(24*time.Hour)),
+			FlattenOnDelist:  cfg.FlattenOnDelist,
+		}, binance.NewListingAdapter(symbols), binance.NewFuturesSymbolCloser(client))
+		monitor.OnEvent(func(e listing.Event) {
+			entry := logrus.WithFields(logrus.Fields{"type": e.Type, "symbol": e.Symbol, "reason": e.Reason})
+			switch e.Type {
+			case listing.EventNewListing:
+				entry.Info("New listing")
+			case listing.EventDelisting:
+				entry.Warn("Symbol delisted, dropped from the screener")
+				tg.Send(alerting.AlertRiskBreach, fmt.Sprintf("%s delisted (%s), dropped from the screener", e.Symbol, e.Reason))
+			case listing.EventFlattenFailed:
+				entry.Error("Delisted position not closed, retrying")
+				tg.Send(alerting.AlertRiskBreach, fmt.Sprintf("Closing the %s position after its delisting failed: %s. Retrying every refresh.", e.Symbol, e.Reason))
+			}
+		})
+		c.listing = monitor
+		c.hooks = append(c.hooks, Hook{
+			Name:    "listing",
+			OnStart: monitor.Start,
+			OnStop:  func(context.Context) error { return monitor.Stop() },
+		})
+	}
+	return c.listing
+}
+
+// Fees returns the tracker of the account's fee tier and commission rates,
+// or nil when fees.enabled is off
+func (c *Container) Fees() *feetier.Tracker {
+	if !c.Config.Fees.Enabled {
+		return nil
+	}
+	client := c.Futures()
+	tg := c.Telegram()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fees == nil {
+		tracker := feetier.New(feetier.Config{
+			Interval: time.Duration(c.Config.Fees.RefreshMinutes) * time.Minute,
+			Symbols:  c.Config.Watchlist.Symbols,
+			Default: feetier.Rate{
+				Maker: c.Config.Trading.GetMakerFeeRate(),
+				Taker: c.Config.Trading.GetTakerFeeRate(),
+			},
+		}, binance.NewFuturesFeeSource(client))
+		tracker.OnChange(func(ch feetier.Change) {
+			logrus.WithFields(logrus.Fields{
+				"from":  ch.From,
+				"to":    ch.To,
+				"maker": ch.Rate.Maker,
+				"taker": ch.Rate.Taker,
+			}).Warn("Fee tier changed")
+			tg.Send(alerting.AlertRiskBreach, ch.String())
+		})
+		c.fees = tracker
+		c.hooks = append(c.hooks, Hook{
+			Name:    "fees",
+			OnStart: tracker.Start,
+			OnStop:  func(context.Context) error { return tracker.Stop() },
+		})
+	}
+	return c.fees
+}
+
+// Ideas returns the inbox for external trade ideas, or nil when
+// ideas.enabled is off
+func (c *Container) Ideas() *ideas.Inbox {
+	if !c.Config.Ideas.Enabled {
+		return nil
+	}
+	rules := c.SymbolRules()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ideas == nil {
+		cfg := c.Config.Ideas
+		c.ideas = ideas.New(ideas.Config{
+			Token:     cfg.Token,
+			Window:    time.Duration(cfg.WindowHours * float64(time.Hour)),
+			MaxActive: cfg.MaxActive,
+			Symbols: func(ctx context.Context, symbol string) error {
+				_, err := rules.Ensure(ctx, symbol)
+				if errors.Is(err, symbolrules.ErrUnknownSymbol) {
+					return fmt.Errorf("%w: %s", ideas.ErrUnknownSymbol, symbol)
+				}
+				return err
+			},
+		})
+	}
+	return c.ideas
+}
+
+// Research returns the read-only API on the candle cache and the journals
+// for research notebooks, or nil when research.enabled is off
+func (c *Container) Research() (*research.Server, error) {
+	if !c.Config.Research.Enabled {
+		return nil, nil
+	}
+	st, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+	klines := c.Klines()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.research == nil {
+		server := research.New(research.Config{
+			Addr:        c.Config.Research.Addr,
+			DecisionLog: c.Config.Monitoring.DecisionLogPath,
+		}, klines, st)
+		c.research = server
+		c.hooks = append(c.hooks, Hook{
+			Name:    "research",
+			OnStart: server.Start,
+			OnStop:  server.Stop,
+		})
+	}
+	return c.research, nil
+}
+
+// FeeRate returns the fee per side of a round trip on a symbol, entering at
+// maker when entries rest on the book and leaving at taker. The rates are
+// the account's own with fees.enabled, else the trading section's.
+func (c *Container) FeeRate() func(symbol string) float64 {
+	passive := c.Config.Execution.PassiveEntry()
+	if fees := c.Fees(); fees != nil {
+		return func(symbol string) float64 { return fees.PerSide(symbol, passive) }
+	}
+	taker, entry := c.Config.Trading.GetTakerFeeRate(), c.Config.Trading.GetTakerFeeRate()
+	if passive {
+		entry = c.Config.Trading.GetMakerFeeRate()
+	}
+	return func(string) float64 { return (entry + taker) / 2 }
+}
+
+// Protection returns the guard that keeps a stop and take profit on the
+// exchange behind every position, or nil when protection.enabled is off.
+// Positions already in the state are adopted when it starts.
+func (c *Container) Protection() *protect.Guard {
+	if !c.Config.Protection.Enabled {
+		return nil
+	}
+	client := c.Futures()
+	tg := c.Telegram()
+	stream := c.UserData()
+	calls := c.Calls()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.protection == nil {
+		cfg := c.Config.Protection
+		guard := protect.New(protect.Config{
+			Interval:   time.Duration(cfg.ReconcileSeconds) * time.Second,
+			AlertAfter: time.Duration(cfg.AlertAfterSeconds) * time.Second,
+			Calls:      calls,
+		}, binance.NewFuturesProtectVenue(client, c.Config.Account.HedgeMode()))
+		guard.OnAlert(func(a protect.Alert) {
+			entry := logrus.WithFields(logrus.Fields{"symbol": a.Symbol, "missing": a.Missing, "for": a.For})
+			if a.Restored {
+				entry.Info("Position protected again")
+			} else {
+				entry.WithError(a.Err).Error("Position unprotected")
+			}
+			tg.Send(alerting.AlertRiskBreach, a.String())
+		})
+		stream.OnAlgo(guard.Update)
+		c.protection = guard
+		c.hooks = append(c.hooks, Hook{
+			Name:   "protection",
+			Leader: true,
+			OnStart: func(ctx context.Context) error {
+				st, err := c.State()
+				if err != nil {
+					return err
+				}
+				for _, p := range st.Positions() {
+					if err := guard.Protect(ctx, protect.Position{
+						Symbol:     p.Symbol,
+						Side:       trade.Side(p.Side),
+						Stop:       p.StopLoss,
+						TakeProfit: p.TakeProfit,
+					}); err != nil {
+						logrus.WithError(err).WithField("symbol", p.Symbol).Warn("Protective orders not placed")
+					}
+				}
+				return guard.Start(ctx)
+			},
+			OnStop: func(context.Context) error { return guard.Stop() },
+		})
+	}
+	return c.protection
+}
+
+// Exchange returns the order venue for the platform executor from the
+// exchange section: Binance futures by default, or Hyperliquid signed with a
+// wallet key
+func (c *Container) Exchange() (market.ExchangeClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.exchange == nil {
+		switch venue := c.Config.Exchange.Venue; venue {
+		case "", "binance":
+			c.exchange = binance.New(binance.Config{
+				APIKey:    c.Config.Binance.APIKey,
+				APISecret: c.Config.Binance.APISecret,
+				Testnet:   c.Config.Binance.UseTestnet,
+			})
+		case "hyperliquid":
+			hl := c.Config.Exchange.Hyperliquid
+			client, err := hyperliquid.New(hyperliquid.Config{
+				PrivateKey:     hl.PrivateKey,
+				AccountAddress: hl.AccountAddress,
+				Testnet:        hl.Testnet,
+				Slippage:       hl.SlippagePercent / 100,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create hyperliquid client: %w", err)
+			}
+			stream := hyperliquid.NewMidStream(hl.Testnet)
+			client.UseMidStream(stream)
+			c.hooks = append(c.hooks, Hook{
+				Name:    "hyperliquid-mids",
+				OnStart: stream.Start,
+				OnStop:  func(context.Context) error { return stream.Stop() },
+			})
+			logrus.WithField("account", client.Address()).Info("🔗 Platform orders routed to Hyperliquid")
+			c.exchange = client
+		default:
+			return nil, fmt.Errorf("unknown exchange venue %q", venue)
+		}
+	}
+	return c.exchange, nil
+}
+
+// EarnSweeper returns the idle margin sweeper from the earn section, or nil
+// when disabled or on testnet, which has no Simple Earn
+func (c *Container) EarnSweeper() *earnsweep.Sweeper {
+	if !c.Config.Earn.Enabled {
+		return nil
+	}
+	if c.Config.Execution.WatchOnly {
+		logrus.Warn("Earn sweep disabled in watch-only mode")
+		return nil
+	}
+	if c.Config.Binance.UseTestnet {
+		logrus.Warn("Earn sweep disabled: Simple Earn is not available on testnet")
+		return nil
+	}
+	fut := c.Futures()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.earn == nil {
+		cfg := c.Config.Earn
+		spot := gobinance.NewClient(c.Config.Binance.APIKey, c.Config.Binance.APISecret)
+		sweeper := earnsweep.New(earnsweep.Config{
+			Asset:           cfg.Asset,
+			KeepFreePercent: cfg.KeepFreePercent,
+			MinFreeMargin:   cfg.MinFreeMarginUSD,
+			MinSweep:        cfg.MinSweepUSD,
+			IdleFor:         time.Duration(cfg.IdleMinutes) * time.Minute,
+			RecallBelow:     cfg.RecallBelowPercent / 100,
+			Interval:        time.Duration(cfg.CheckIntervalSeconds) * time.Second,
+		}, binance.NewFuturesEarnAccount(spot, fut))
+		c.earn = sweeper
+		c.hooks = append(c.hooks, Hook{
+			Name:    "earn-sweep",
+			Leader:  true,
+			OnStart: sweeper.Start,
+			OnStop:  func(context.Context) error { return sweeper.Stop() },
+		})
+	}
+	return c.earn
+}
+
+// Events returns the event stream from the events section, or nil when it
+// is disabled. Queued events are flushed on shutdown.
+func (c *Container) Events() *events.Stream {
+	if !c.Config.Events.Enabled {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.events == nil {
+		cfg := c.Config.Events
+		var pub events.Publisher
+		switch cfg.Backend {
+		case "nats":
+			pub = eventbus.NewNATS(eventbus.NATSConfig{Addr: cfg.Addr, Token: cfg.Token, Password: cfg.Password})
+		default:
+			pub = eventbus.NewRedis(eventbus.RedisConfig{Addr: cfg.Addr, Password: cfg.Password, MaxLen: cfg.MaxLen})
+		}
+
+		instance := c.Config.Failover.InstanceID
+		if instance == "" {
+			instance, _ = os.Hostname()
+		}
+		stream := events.New(events.Config{Prefix: cfg.Prefix, Instance: instance, Namespace: c.Config.Namespace, Buffer: cfg.Buffer}, pub)
+		c.events = stream
+		c.hooks = append(c.hooks, Hook{
+			Name:    "events",
+			OnStart: stream.Start,
+			OnStop:  func(context.Context) error { return stream.Close() },
+		})
+	}
+	return c.events
+}
+
+// Equity returns the account snapshot recorder writing to
+// monitoring.equity_log_path, or nil when no path is configured
+func (c *Container) Equity() (*equity.Recorder, error) {
+	path := c.Config.Monitoring.EquityLogPath
+	if path == "" {
+		return nil, nil
+	}
+	fut := c.Futures()
+	calls := c.Calls()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.equity == nil {
+		rec, err := equity.New(equity.Config{
+			Path:      path,
+			Interval:  time.Duration(c.Config.Monitoring.EquitySnapshotSecs) * time.Second,
+			Calls:     calls,
+			Namespace: c.Config.Namespace,
+		}, binance.NewFuturesEquitySource(fut))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open equity log: %w", err)
+		}
+		c.equity = rec
+		c.hooks = append(c.hooks, Hook{
+			Name:    "equity",
+			OnStart: rec.Start,
+			OnStop:  func(context.Context) error { return rec.Stop() },
+		})
+	}
+	return c.equity, nil
+}
+
+// BookArchive returns the archiver of order book snapshots around entries,
+// or nil when monitoring.book_archive_path is unset
+func (c *Container) BookArchive() (*bookarchive.Archiver, error) {
+	mon := c.Config.Monitoring
+	if mon.BookArchivePath == "" {
+		return nil, nil
+	}
+	fut := c.Futures()
+	calls := c.Calls()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.books == nil {
+		after := make([]time.Duration, 0, len(mon.BookArchiveAfter))
+		for _, secs := range mon.BookArchiveAfter {
+			after = append(after, time.Duration(secs)*time.Second)
+		}
+		archiver, err := bookarchive.New(bookarchive.Config{
+			Path:  mon.BookArchivePath,
+			Depth: mon.BookArchiveDepth,
+			After: after,
+			Calls: calls,
+		}, binance.NewFuturesDepthSource(fut))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open book archive: %w", err)
+		}
+		c.books = archiver
+		c.hooks = append(c.hooks, Hook{
+			Name:   "book_archive",
+			OnStop: func(context.Context) error { return archiver.Stop() },
+		})
+	}
+	return c.books, nil
+}
+
+// OrderBooks returns the watchlist order books kept from the depth diff
+// stream, or nil when execution.local_books is off
+func (c *Container) OrderBooks() *orderbook.Books {
+	if !c.Config.Execution.LocalBooks {
+		return nil
+	}
+	fut := c.Futures()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.orderBooks == nil {
+		books := orderbook.New(orderbook.Config{}, binance.NewFuturesDepthSource(fut))
+		stream := binance.NewDepthStream(c.Config.Watchlist.Symbols, books)
+		c.orderBooks = books
+		c.hooks = append(c.hooks, Hook{
+			Name: "order_books",
+			OnStart: func(ctx context.Context) error {
+				if err := books.Start(ctx); err != nil {
+					return err
+				}
+				return stream.Start(ctx)
+			},
+			OnStop: func(context.Context) error {
+				stream.Stop()
+				return books.Stop()
+			},
+		})
+	}
+	return c.orderBooks
+}
+
+// CostModel returns the execution cost forecaster used by the engine's
+// expected value gate, or nil when execution.cost_model is off. It walks the
+// local order books when they are kept and starts from the slippage of the
+// journaled trades.
+func (c *Container) CostModel() (*costmodel.Model, error) {
+	if !c.Config.Execution.CostModel {
+		return nil, nil
+	}
+	st, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+	var book costmodel.Book
+	if books := c.OrderBooks(); books != nil {
+		book = books
+	}
+	feeRate := c.FeeRate()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.costs == nil {
+		model := costmodel.New(costmodel.Config{
+			FeeRate:    c.Config.Trading.GetTakerFeeRate(),
+			Fee:        feeRate,
+			MinSamples: c.Config.Execution.CostMinSamples,
+		}, book)
+		for _, t := range st.Trades() {
+			if bps, ok := t.SlippageBps(); ok {
+				model.Observe(t.Symbol, t.Size*t.EntryPrice, bps)
+			}
+		}
+		c.costs = model
+	}
+	return c.costs, nil
+}
+
+// MarginTarget returns the controller that scales entry sizes to hold the
+// account's initial margin near risk.target_margin_percent of equity, or nil
+// when no target is set
+func (c *Container) MarginTarget() *margintarget.Controller {
+	risk := c.Config.Risk
+	if risk.TargetMarginPercent <= 0 {
+		return nil
+	}
+	fut := c.Futures()
+	calls := c.Calls()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.margin == nil {
+		ctrl := margintarget.New(margintarget.Config{
+			Target:   risk.TargetMarginPercent / 100,
+			MinScale: risk.MarginScaleMin,
+			MaxScale: risk.MarginScaleMax,
+			Interval: time.Duration(risk.MarginPollSeconds) * time.Second,
+			Calls:    calls,
+		}, binance.NewFuturesEquitySource(fut))
+		c.margin = ctrl
+		c.hooks = append(c.hooks, Hook{
+			Name:    "margin_target",
+			OnStart: ctrl.Start,
+			OnStop:  func(context.Context) error { return ctrl.Stop() },
+		})
+	}
+	return c.margin
+}
+
+// ProfitLock returns the intraday profit lock, or nil when
+// risk.profit_lock_percent is unset
+func (c *Container) ProfitLock() (*profitlock.Lock, error) {
+	risk := c.Config.Risk
+	if risk.ProfitLockPercent <= 0 {
+		return nil, nil
+	}
+	st, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+	fut := c.Futures()
+	calls := c.Calls()
+	tg := c.Telegram()
+	audit := c.Audit()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.profitLock == nil {
+		source := binance.NewFuturesEquitySource(fut)
+		lock := profitlock.New(profitlock.Config{
+			ArmPercent: risk.ProfitLockPercent,
+			Giveback:   risk.ProfitGivebackPercent / 100,
+			Flatten:    risk.ProfitLockFlatten,
+			Interval:   time.Duration(risk.ProfitLockPollSeconds) * time.Second,
+			Calls:      calls,
+			OnChange: func(ch profitlock.Change) {
+				logrus.WithFields(logrus.Fields{
+					"action": ch.Action,
+					"open":   ch.Open,
+					"peak":   ch.Peak,
+					"equity": ch.Equity,
+				}).Warn("Profit lock: " + ch.String())
+				audit.Log("PROFIT_LOCK", map[string]interface{}{
+					"action": ch.Action,
+					"day":    ch.Day,
+					"open":   ch.Open,
+					"peak":   ch.Peak,
+					"equity": ch.Equity,
+					"floor":  ch.Floor,
+					"closed": ch.Closed,
+					"errors": ch.Errors,
+				})
+				tg.Send(alerting.AlertRiskBreach, ch.String())
+			},
+		}, source, source, st)
+		c.profitLock = lock
+		c.hooks = append(c.hooks, Hook{
+			Name:    "profit_lock",
+			Leader:  true,
+			OnStart: lock.Start,
+			OnStop:  func(context.Context) error { return lock.Stop() },
+		})
+	}
+	return c.profitLock, nil
+}
+
+// FillCheck returns the checker that confirms each trade executed alert
+// against the exchange order and alerts on a mismatch, or nil when
+// monitoring.verify_trade_alerts is off or Telegram alerts are disabled
+func (c *Container) FillCheck() *fillcheck.Checker {
+	mon := c.Config.Monitoring
+	if !mon.VerifyTradeAlerts || !mon.TelegramEnabled {
+		return nil
+	}
+	client := c.Binance()
+	tg := c.Telegram()
+	audit := c.Audit()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fills == nil {
+		checker := fillcheck.New(fillcheck.Config{
+			Timeout: time.Duration(mon.VerifyAlertTimeoutSecs) * time.Second,
+			OnDiscrepancy: func(d fillcheck.Discrepancy) {
+				logrus.WithFields(logrus.Fields{
+					"symbol":   d.Claim.Symbol,
+					"order_id": d.Claim.OrderID,
+					"kind":     d.Kind,
+				}).Warn("Trade alert does not match the exchange: " + d.Detail)
+				audit.Log("ALERT_MISMATCH", map[string]interface{}{
+					"symbol":   d.Claim.Symbol,
+					"order_id": d.Claim.OrderID,
+					"side":     d.Claim.Side,
+					"alerted":  d.Claim.Quantity,
+					"filled":   d.Filled,
+					"status":   d.Status,
+					"kind":     d.Kind,
+				})
+				tg.SendTemplate(alerting.AlertRiskBreach, alerting.MsgTradeMismatch, map[string]interface{}{
+					"Side":   d.Claim.Side,
+					"Symbol": d.Claim.Symbol,
+					"Detail": d.Detail,
+				})
+			},
+		}, client)
+		c.fills = checker
+		c.hooks = append(c.hooks, Hook{
+			Name:    "fill_check",
+			OnStart: checker.Start,
+			OnStop:  func(context.Context) error { return checker.Stop() },
+		})
+	}
+	return c.fills
+}
+
+// Regime returns the rotator that suspends and resumes platform strategies
+// with the market regime, or nil when regime.enabled is off. Every
+// activation decision is logged and written to the audit log as
+// STRATEGY_ROTATION.
+func (c *Container) Regime() (*regime.Rotator, error) {
+	if !c.Config.Regime.Enabled {
+		return nil, nil
+	}
+	suspensions, err := c.Suspensions()
+	if err != nil {
+		return nil, err
+	}
+	klines := c.Klines()
+	audit := c.Audit()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.regime == nil {
+		cfg := c.Config.Regime
+		rules := make(map[string]regime.Rule, len(cfg.Rules))
+		for name, r := range cfg.Rules {
+			rules[name] = regime.Rule{Enable: r.Enable, Disable: r.Disable}
+		}
+		rotator := regime.New(regime.Config{
+			Symbol:          cfg.Symbol,
+			Interval:        cfg.Interval,
+			Length:          cfg.Length,
+			TrendEfficiency: cfg.TrendEfficiency,
+			ChopEfficiency:  cfg.ChopEfficiency,
+			Confirm:         cfg.ConfirmChecks,
+			MinDwell:        time.Duration(cfg.MinDwellMinutes) * time.Minute,
+			CheckInterval:   time.Duration(cfg.CheckIntervalSeconds) * time.Second,
+			Rules:           rules,
+			OnDecision: func(d regime.Decision) {
+				logrus.WithFields(logrus.Fields{
+					"regime":   d.Regime,
+					"previous": d.Previous,
+					"strategy": d.Strategy,
+					"enable":   d.Enable,
+					"applied":  d.Applied,
+				}).Info("Strategy rotation: " + d.Detail)
+				audit.Log("STRATEGY_ROTATION", map[string]interface{}{
+					"regime":   d.Regime,
+					"previous": d.Previous,
+					"strategy": d.Strategy,
+					"enable":   d.Enable,
+					"applied":  d.Applied,
+					"detail":   d.Detail,
+				})
+			},
+		}, klines, suspensions)
+		c.regime = rotator
+		c.hooks = append(c.hooks, Hook{
+			Name:    "regime",
+			OnStart: rotator.Start,
+			OnStop:  func(context.Context) error { return rotator.Stop() },
+		})
+	}
+	return c.regime, nil
+}
+
+// Failover returns the leader elector from the failover section, or nil when
+// failover is disabled. The elector syncs the trading state: the leader
+// publishes it every heartbeat and the standby restores it, so a promoted
+// standby resumes managing the same positions.
+func (c *Container) Failover() (*failover.Elector, error) {
+	if !c.Config.Failover.Enabled {
+		return nil, nil
+	}
+	st, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failover == nil {
+		cfg := c.Config.Failover
+		id := cfg.InstanceID
+		if id == "" {
+			id, _ = os.Hostname()
+		}
+
+		var lock failover.Lock
+		var store failover.SnapshotStore
+		switch cfg.Backend {
+		case "redis":
+			lock, store = lease.NewRedis(lease.Config{Addr: cfg.RedisAddr, Password: cfg.RedisPassword})
+		default:
+			lock = failover.NewFileLock(filepath.Join(cfg.LockPath, "leader.lock"))
+			store = failover.NewFileSnapshotStore(filepath.Join(cfg.LockPath, "state.json"))
+		}
+
+		elector, err := failover.New(failover.Config{
+			InstanceID: id,
+			LeaseTTL:   cfg.GetLeaseTTL(),
+			Heartbeat:  cfg.GetHeartbeat(),
+		}, lock, store, st)
+		if err != nil {
+			return nil, err
+		}
+		// Registered before any caller's handlers, so the account is
+		// covered before the engine starts trading on promotion.
+		elector.OnPromote(c.lead)
+		elector.OnDemote(func() { c.standBy(context.Background()) })
+		c.failover = elector
+		c.hooks = append(c.hooks, Hook{
+			Name:   "failover",
+			OnStop: func(context.Context) error { return elector.Stop() },
+		})
+	}
+	return c.failover, nil
+}
+
+// lead starts the leader hooks for a leadership term. ctx ends with the
+// term. A hook that fails to start is logged and the others still start.
+func (c *Container) lead(ctx context.Context) {
+	c.mu.Lock()
+	var hooks []Hook
+	for _, h := range c.hooks {
+		if h.Leader {
+			hooks = append(hooks, h)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, h := range hooks {
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				logrus.WithError(err).WithField("component", h.Name).Error("Failed to start component after promotion")
+				continue
+			}
+		}
+		c.mu.Lock()
+		c.leading = append(c.leading, h)
+		c.mu.Unlock()
+		logrus.WithField("component", h.Name).Debug("Component started as leader")
+	}
+}
+
+// standBy stops the leader hooks of the term that ended, in reverse order.
+func (c *Container) standBy(ctx context.Context) {
+	c.mu.Lock()
+	leading := c.leading
+	c.leading = nil
+	c.mu.Unlock()
+
+	for i := len(leading) - 1; i >= 0; i-- {
+		h := leading[i]
+		if h.OnStop == nil {
+			continue
+		}
+		if err := h.OnStop(ctx); err != nil {
+			logrus.WithError(err).WithField("component", h.Name).Error("Failed to stop component")
+		}
+	}
+}
+
+// InstanceLock returns the guard that keeps a second bot off this account,
+// or nil when the lock is disabled, failover is enabled or there is no API
+// key to key it by. A conflict is logged, audited and sent to Telegram.
+func (c *Container) InstanceLock() *instancelock.Guard {
+	cfg := c.Config.InstanceLock
+	if !cfg.Enabled || c.Config.Failover.Enabled || c.Config.Binance.APIKey == "" {
+		return nil
+	}
+	tg := c.Telegram()
+	audit := c.Audit()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.instance == nil {
+		key := instancelock.Key(c.Config.Binance.APIKey)
+		host := c.Config.Failover.InstanceID
+		if host == "" {
+			host, _ = os.Hostname()
+		}
+		id := fmt.Sprintf("%s:%d", host, os.Getpid())
+
+		var lock instancelock.Lock
+		switch cfg.Backend {
+		case "redis":
+			lock, _ = lease.NewRedis(lease.Config{Addr: cfg.RedisAddr, Password: cfg.RedisPassword, Prefix: "gobot:instance:" + key})
+		default:
+			dir := cfg.LockDir
+			if dir == "" {
+				dir = c.Config.State.StateDir
+			}
+			if dir == "" {
+				dir = os.TempDir()
+			}
+			lock = failover.NewFileLock(filepath.Join(dir, "instance-"+key+".lock"))
+		}
+
+		guard := instancelock.New(instancelock.Config{
+			Instance: id,
+			TTL:      cfg.GetLeaseTTL(),
+			OnConflict: func(conflict instancelock.Conflict) {
+				logrus.WithFields(logrus.Fields{
+					"instance": conflict.Instance,
+					"holder":   conflict.Holder,
+				}).Error("Account lock conflict: " + conflict.String())
+				audit.Log("INSTANCE_CONFLICT", map[string]interface{}{
+					"instance": conflict.Instance,
+					"holder":   conflict.Holder,
+					"forced":   conflict.Forced,
+					"lost":     conflict.Lost,
+				})
+				tg.SendTemplate(alerting.AlertRiskBreach, alerting.MsgInstanceLock, map[string]interface{}{
+					"Instance": conflict.Instance,
+					"Detail":   conflict.String(),
+				})
+			},
+		}, lock)
+		c.instance = guard
+		c.hooks = append(c.hooks, Hook{
+			Name:    "instance_lock",
+			OnStart: guard.Start,
+			OnStop:  func(context.Context) error { return guard.Stop() },
+		})
+	}
+	return c.instance
+}
+
+// AccountSetup returns a fixer that aligns position mode, multi-assets margin,
+// margin type and leverage of the watchlist with the account config
+func (c *Container) AccountSetup() *accountsetup.Fixer {
+	return accountsetup.New(accountsetup.Config{
+		DualSide:    c.Config.Account.HedgeMode(),
+		MultiAssets: c.Config.Account.MultiAssetsMargin,
+		MarginType:  c.Config.Account.MarginType,
+		Leverage:    c.Config.Account.Leverage,
+		Symbols:     c.Config.Watchlist.Symbols,
+	}, binance.NewFuturesAccountSettings(c.Futures()))
+}
+
+// Screener returns the pair screener filtered by the trading volume floor and
+// restricted to the active universes, or to the watchlist when none are active
+func (c *Container) Screener() *screener.Screener {
+	modes, err := c.RiskModes()
+	if err != nil {
+		logrus.WithError(err).Warn("Risk modes unavailable, screening with the configured filter")
+	}
+	sent, err := c.Sentiment()
+	if err != nil {
+		logrus.WithError(err).Warn("News sentiment unavailable, ranking without it")
+	}
+	weights := c.APIWeight()
+	memory := c.SymbolMemory()
+	stream := c.Events()
+	klines := c.Klines()
+	lv := c.Levels()
+	inbox := c.Ideas()
+	listings := c.Listing()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.screener == nil {
+		scan := c.Config.Screener
+		client := binance.NewScreenerClient(binance.Config{Testnet: c.Config.Binance.UseTestnet})
+		client.SetWeightBudget(weights, apiweight.Screener)
+		var tickers *binance.TickerStream
+		if scan.TickerStream {
+			tickers = binance.NewTickerStream()
+			client.SetTickerStream(tickers, time.Duration(scan.FullScanMinutes)*time.Minute, scan.PageSize)
+		}
+		adapter := binance.NewScreenerAdapter(client)
+
+		filter := screener.AssetFilter{
+			ContractType:   "PERPETUAL",
+			QuoteAsset:     "USDT",
+			MinVolume24h:   c.Config.Trading.MinVolume24HUSD,
+			Status:         "TRADING",
+			IncludeSymbols: c.Config.Watchlist.Symbols,
+		}
+		if len(c.Config.Universes.Active) > 0 {
+			filter.IncludeSymbols = nil
+		}
+
+		opts := []screener.Option{
+			screener.WithAssetFilter(filter),
+			screener.WithOpenInterest(adapter),
+			screener.WithMaxDataAge(c.Config.Trading.GetMaxDataAge()),
+			screener.WithScoreHalfLife(c.Config.Trading.GetScoreHalfLife()),
+			screener.WithUniverses(c.universes(), c.Config.Universes.Active...),
+			screener.WithMomentum(klines, c.Config.Trading.MomentumWeight),
+			screener.WithWeightBackoff(weights, scan.BackoffAbove, time.Duration(scan.MaxDeferMinutes)*time.Minute),
+		}
+		if c.Config.Trading.MaxPairs > 0 {
+			opts = append(opts, screener.WithMaxPairs(c.Config.Trading.MaxPairs))
+		}
+		if memory != nil {
+			opts = append(opts, screener.WithMemory(memory))
+		}
+		if lv != nil {
+			opts = append(opts, screener.WithLevels(lv))
+		}
+		if sent != nil {
+			opts = append(opts, screener.WithSentiment(sent, c.Config.Sentiment.GetWeight()))
+		}
+		if inbox != nil {
+			opts = append(opts, screener.WithIdeas(inbox))
+		}
+		if listings != nil {
+			opts = append(opts, screener.WithBlacklist(listings), screener.WithNewListings(listings))
+		}
+		if stream != nil || sent != nil {
+			opts = append(opts, screener.WithOnRefresh(func(pairs []screener.ExchangeInfo, active []string) {
+				if sent != nil {
+					sent.Track(active)
+				}
+				if stream != nil {
+					stream.Publish(events.TypeScreenerRefresh, screenerRefresh(pairs, active))
+				}
+			}))
+		}
+
+		s := screener.NewScreener(adapter, opts...)
+		if modes != nil {
+			p := modes.Current()
+			s.Tune(p.MinVolume24h, p.MaxPairs)
+		}
+		c.screener = s
+		c.hooks = append(c.hooks, Hook{
+			Name: "screener",
+			OnStart: func(ctx context.Context) error {
+				if tickers != nil {
+					if err := tickers.Start(ctx); err != nil {
+						return err
+					}
+				}
+				return s.Initialize(ctx)
+			},
+			OnStop: func(context.Context) error {
+				s.Stop()
+				if tickers != nil {
+					return tickers.Stop()
+				}
+				return nil
+			},
+		})
+	}
+	return c.screener
+}
+
+// Sentiment returns the news sentiment scorer feeding the screener rank, or
+// nil when sentiment.enabled is off. It scores the watchlist until the
+// screener's first refresh hands it the active pairs.
+func (c *Container) Sentiment() (*sentiment.Service, error) {
+	if !c.Config.Sentiment.Enabled {
+		return nil, nil
+	}
+	model, err := c.Brain()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sentiment == nil {
+		cfg := c.Config.Sentiment
+		svc := sentiment.New(sentiment.Config{
+			Interval:        time.Duration(cfg.IntervalMinutes) * time.Minute,
+			BatchSize:       cfg.BatchSize,
+			MaxHeadlines:    cfg.MaxHeadlines,
+			MaxCallsPerHour: cfg.MaxCallsPerHour,
+		}, news.NewCryptoPanicSource(cfg.CryptoPanicToken), model)
+		svc.Track(c.Config.Watchlist.Symbols)
+		c.sentiment = svc
+		c.hooks = append(c.hooks, Hook{
+			Name:    "sentiment",
+			OnStart: svc.Start,
+			OnStop:  func(context.Context) error { return svc.Stop() },
+		})
+	}
+	return c.sentiment, nil
+}
+
+func screenerRefresh(pairs []screener.ExchangeInfo, active []string) events.ScreenerRefresh {
+	out := events.ScreenerRefresh{Active: active, Pairs: make([]events.Pair, 0, len(pairs))}
+	for _, p := range pairs {
+		out.Pairs = append(out.Pairs, events.Pair{
+			Symbol:         p.Symbol,
+			Volume24h:      p.Volume24h,
+			PriceChangePct: p.PriceChangePct,
+			OIChangePct:    p.OIChangePct,
+			Breakout:       p.BreakoutSignal,
+			BreakoutLevel:  p.BreakoutLevel,
+			SqueezeRisk:    p.SqueezeRisk,
+			Universe:       p.Universe,
+		})
+	}
+	return out
+}
+
+// universes converts the configured universe definitions, falling back to
+// the screener's defaults when none are configured
+func (c *Container) universes() []screener.Universe {
+	defs := c.Config.Universes.Definitions
+	if len(defs) == 0 {
+		return screener.DefaultUniverses()
+	}
+
+	universes := make([]screener.Universe, 0, len(defs))
+	for _, d := range defs {
+		universes = append(universes, screener.Universe{
+			Name:         d.Name,
+			Symbols:      d.Symbols,
+			Patterns:     d.Patterns,
+			Exclude:      d.Exclude,
+			MinVolume24h: d.MinVolume24h,
+			MaxVolume24h: d.MaxVolume24h,
+			MinMarketCap: d.MinMarketCap,
+			MaxMarketCap: d.MaxMarketCap,
+			NewListings:  d.NewListings,
+		})
+	}
+	return universes
+}
+
+// Engine returns a platform engine with the built-in strategies, selectors
+// and executors registered. Callers add automations they need.
+func (c *Container) Engine() *platform.PlatformEngine {
+	klines := c.Klines()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.engine == nil {
+		engine := platform.NewPlatformEngine()
+		engine.RegisterStrategy(strategy.StrategyScalper, func() strategy.Strategy {
+			return &scalper.ScalperStrategy{}
+		})
+		engine.RegisterStrategy(strategy.StrategyMomentum, func() strategy.Strategy {
+			return &momentum.MomentumStrategy{}
+		})
+		engine.RegisterStrategy(strategy.StrategyRules, func() strategy.Strategy {
+			return rules.NewRulesStrategy(klines)
+		})
+		engine.RegisterStrategy(strategy.StrategyGrid, func() strategy.Strategy {
+			return &grid.GridStrategy{}
+		})
+		engine.RegisterSelector(selector.SelectorVolume, func() selector.Selector {
+			return &volume.VolumeSelector{}
+		})
+		engine.RegisterExecutor(executor.ExecutionMarket, func() executor.Executor {
+			client, err := c.Exchange()
+			if err != nil {
+				logrus.WithError(err).Error("Market executor has no exchange")
+				return market.NewMarketExecutor()
+			}
+			return market.NewMarketExecutorWithClient(client)
+		})
+		engine.RegisterAutomation(automation.AutomationN8N, func() automation.Automation {
+			return automation.NewN8NAutomation()
+		})
+		if dir := c.Config.Strategies.PluginDir; dir != "" {
+			registerExternalStrategies(engine, dir)
+		}
+		c.engine = engine
+	}
+	return c.engine
+}
+
+// registerExternalStrategies adds the strategy modules in dir to engine.
+// Modules that fail to load or claim an already registered type are logged
+// and skipped rather than stopping startup.
+func registerExternalStrategies(engine *platform.PlatformEngine, dir string) {
+	modules, errs := external.Load(dir)
+	for _, err := range errs {
+		logrus.WithError(err).Warn("Strategy module not loaded")
+	}
+	for _, m := range modules {
+		if engine.HasStrategy(m.Type) {
+			logrus.WithFields(logrus.Fields{"path": m.Path, "type": m.Type}).Warn("Strategy module skipped: type already registered")
+			continue
+		}
+		engine.RegisterStrategy(m.Type, m.Factory)
+		logrus.WithFields(logrus.Fields{
+			"path":    m.Path,
+			"type":    m.Type,
+			"name":    m.Name,
+			"version": m.Version,
+		}).Info("Strategy module loaded")
+	}
+}
@@ -0,0 +1,402 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/config"
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/kline"
+	"github.com/britej3/gobot/services/levels"
+	"github.com/britej3/gobot/services/squeeze"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNoAnalyzer is returned by Start when no analyzer has been injected
+var ErrNoAnalyzer = errors.New("no analyzer configured, refusing to trade")
+
+// ErrChaseAbandoned is returned for a chased entry that never filled
+var ErrChaseAbandoned = errors.New("chased entry abandoned without a fill")
+
+// Analyzer turns the current market state of a symbol into a trading signal.
+// It returns a nil signal when there is no setup worth trading, and may
+// return a *Rejection for a setup it found but declined.
+type Analyzer interface {
+	Analyze(ctx context.Context, symbol string) (*TradingSignal, error)
+}
+
+// DecisionMaker confirms indicator setups, typically the AI brain engine
+type DecisionMaker interface {
+	MakeTradingDecision(ctx context.Context, signal interface{}) (*brain.TradingDecision, error)
+}
+
+// LevelSource finds a symbol's support and resistance levels
+type LevelSource interface {
+	Levels(ctx context.Context, symbol string) (levels.Set, error)
+}
+
+// SqueezeSource reports a symbol's latest released volatility squeeze
+type SqueezeSource interface {
+	Last(symbol string) (squeeze.Signal, bool)
+}
+
+// AnalyzerConfig controls the indicator pipeline thresholds
+type AnalyzerConfig struct {
+	Interval          string
+	MinConfidence     float64
+	StopLossPercent   float64
+	TakeProfitPercent float64
+	RSIOverbought     float64
+	RSIOversold       float64
+	// StopMode is "percent" or "atr"; ATR placement falls back to the
+	// percentages while a symbol has no ATR yet.
+	StopMode          string
+	ATRStopMultiple   float64
+	ATRTargetMultiple float64
+	// Calls bounds the kline and brain calls; nil uses the default timeouts.
+	Calls *callpolicy.Policy
+	// DecisionBudget caps each brain confirmation below the LLM timeout.
+	DecisionBudget time.Duration
+	// Levels, when set, anchors ATR stops beyond the nearest level of at
+	// least LevelMinStrength instead of the recent swing, and gives the
+	// brain the nearest support and resistance.
+	Levels           LevelSource
+	LevelMinStrength float64
+	// Squeezes, when set, adds a released squeeze's contribution to the
+	// confidence of a setup in the same direction.
+	Squeezes SqueezeSource
+}
+
+// analyzerConfig returns the analyzer thresholds from the trading section
+func analyzerConfig(cfg *config.ProductionConfig) AnalyzerConfig {
+	return AnalyzerConfig{
+		MinConfidence:     cfg.Trading.MinConfidence,
+		StopLossPercent:   cfg.Trading.StopLossPercent,
+		TakeProfitPercent: cfg.Trading.TakeProfitPercent,
+		StopMode:          cfg.Trading.StopMode,
+		ATRStopMultiple:   cfg.Trading.ATRStopMultiple,
+		ATRTargetMultiple: cfg.Trading.ATRTargetMultiple,
+		LevelMinStrength:  cfg.Trading.LevelsMinStrength,
+	}
+}
+
+// BrainStats counts brain confirmations and the setups decided without them
+type BrainStats struct {
+	Calls       int
+	Timeouts    int
+	Degraded    int
+	LastTimeout time.Time
+}
+
+// cycleAnalyzer is implemented by analyzers that keep state for one
+// trading cycle
+type cycleAnalyzer interface {
+	BeginCycle()
+}
+
+// PipelineAnalyzer derives a direction from trend, VWAP and RSI on the shared
+// kline cache and asks the brain to confirm it before emitting a signal
+type PipelineAnalyzer struct {
+	cfg    AnalyzerConfig
+	klines *kline.Service
+	brain  DecisionMaker
+
+	mu       sync.Mutex
+	degraded bool
+	stats    BrainStats
+}
+
+// NewPipelineAnalyzer creates an analyzer. brain may be nil, in which case
+// confidence comes from indicator agreement alone.
+func NewPipelineAnalyzer(cfg AnalyzerConfig, klines *kline.Service, brain DecisionMaker) *PipelineAnalyzer {
+	if cfg.Interval == "" {
+		cfg.Interval = "5m"
+	}
+	if cfg.MinConfidence <= 0 {
+		cfg.MinConfidence = 0.65
+	}
+	if cfg.StopLossPercent <= 0 {
+		cfg.StopLossPercent = 1
+	}
+	if cfg.TakeProfitPercent <= 0 {
+		cfg.TakeProfitPercent = 2 * cfg.StopLossPercent
+	}
+	if cfg.RSIOverbought <= 0 {
+		cfg.RSIOverbought = 70
+	}
+	if cfg.RSIOversold <= 0 {
+		cfg.RSIOversold = 30
+	}
+	if cfg.ATRStopMultiple <= 0 {
+		cfg.ATRStopMultiple = 1.2
+	}
+	if cfg.ATRTargetMultiple <= 0 {
+		cfg.ATRTargetMultiple = 2.5
+	}
+	if cfg.LevelMinStrength <= 0 {
+		cfg.LevelMinStrength = 0.3
+	}
+
+	return &PipelineAnalyzer{cfg: cfg, klines: klines, brain: brain}
+}
+
+// Analyze implements Analyzer
+func (a *PipelineAnalyzer) Analyze(ctx context.Context, symbol string) (*TradingSignal, error) {
+	klinesCtx, cancel := a.cfg.Calls.Context(ctx, callpolicy.Klines)
+	ind, err := a.klines.Indicators(klinesCtx, symbol, a.cfg.Interval)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load indicators for %s: %w", symbol, err)
+	}
+
+	price := ind.LastClose
+	if price <= 0 || ind.EMASlow <= 0 || ind.VWAP <= 0 {
+		return nil, nil
+	}
+
+	var action string
+	var confirms []bool
+	switch {
+	case ind.EMAFast > ind.EMASlow && price > ind.VWAP && ind.RSI < a.cfg.RSIOverbought:
+		action = "LONG"
+		confirms = []bool{price > ind.EMAFast, ind.RSI > 50, price > ind.SessionVWAP && ind.SessionVWAP > 0}
+	case ind.EMAFast < ind.EMASlow && price < ind.VWAP && ind.RSI > a.cfg.RSIOversold:
+		action = "SHORT"
+		confirms = []bool{price < ind.EMAFast, ind.RSI < 50, price < ind.SessionVWAP && ind.SessionVWAP > 0}
+	default:
+		return nil, nil
+	}
+
+	// Trend and VWAP agreement earns 0.6, each confirming factor adds 0.1.
+	components := map[string]float64{"trend_vwap": 0.6}
+	for i, name := range []string{"ema_momentum", "rsi", "session_vwap"} {
+		if confirms[i] {
+			components[name] = 0.1
+		}
+	}
+	confidence := 0.6 + 0.1*float64(countTrue(confirms...))
+	support, resistance, hasLevels := a.levels(ctx, symbol)
+	released, hasSqueeze := a.squeeze(symbol, action)
+	reasoning := fmt.Sprintf("%s %s: ema %.4f/%.4f, vwap %.4f, rsi %.1f", action, a.cfg.Interval, ind.EMAFast, ind.EMASlow, ind.VWAP, ind.RSI)
+
+	var decision *brain.TradingDecision
+	var inputs map[string]float64
+	if a.brain != nil {
+		inputs = map[string]float64{
+			"price":        price,
+			"ema_fast":     ind.EMAFast,
+			"ema_slow":     ind.EMASlow,
+			"rsi":          ind.RSI,
+			"atr":          ind.ATR,
+			"vwap":         ind.VWAP,
+			"session_vwap": ind.SessionVWAP,
+			"confidence":   confidence,
+		}
+		for name, v := range ind.Patterns {
+			inputs[name] = v
+		}
+		if hasLevels {
+			inputs["support"], inputs["support_strength"] = support.Price, support.Strength
+			inputs["resistance"], inputs["resistance_strength"] = resistance.Price, resistance.Strength
+		}
+		if hasSqueeze {
+			inputs["squeeze_bars"], inputs["squeeze_momentum"] = float64(released.Bars), released.Momentum
+		}
+		decision, err = a.confirm(ctx, brain.SignalData(symbol, action, inputs))
+		if err != nil {
+			return nil, fmt.Errorf("brain confirmation failed for %s: %w", symbol, err)
+		}
+		if decision == nil {
+			components["brain_degraded"] = 1
+			reasoning += " (rule-based, brain over budget)"
+		}
+	}
+
+	if decision != nil {
+		want := "BUY"
+		if action == "SHORT" {
+			want = "SELL"
+		}
+		if !strings.EqualFold(decision.Decision, want) {
+			logrus.WithFields(logrus.Fields{
+				"symbol":   symbol,
+				"setup":    action,
+				"decision": decision.Decision,
+			}).Debug("Brain did not confirm indicator setup")
+			components["confidence"] = confidence
+			components["llm_confidence"] = decision.Confidence
+			return nil, &Rejection{
+				Candidate: action,
+				Reason:    decisionlog.ReasonBrainVeto,
+				Scores:    components,
+				Detail:    "brain decided " + decision.Decision,
+				Inputs:    inputs,
+			}
+		}
+		components["llm_boost"] = decision.Confidence - confidence
+		confidence = decision.Confidence
+		reasoning = decision.Reasoning
+	}
+
+	// A released squeeze is a signal of its own; it is added after the
+	// brain so its contribution counts whoever set the confidence.
+	if hasSqueeze {
+		components["squeeze"] = released.Contribution
+		confidence = math.Min(1, confidence+released.Contribution)
+		reasoning += fmt.Sprintf("; squeeze released after %d bars", released.Bars)
+	}
+
+	if confidence < a.cfg.MinConfidence {
+		components["confidence"] = confidence
+		return nil, &Rejection{
+			Candidate:  action,
+			Reason:     decisionlog.ReasonLowConfidence,
+			Scores:     components,
+			Thresholds: map[string]float64{"min_confidence": a.cfg.MinConfidence},
+			Inputs:     inputs,
+		}
+	}
+
+	sl, tp := a.cfg.StopLossPercent/100, a.cfg.TakeProfitPercent/100
+	signal := &TradingSignal{
+		Symbol:     symbol,
+		Action:     action,
+		Confidence: confidence,
+		EntryPrice: price,
+		StopLoss:   price * (1 - sl),
+		TakeProfit: price * (1 + tp),
+		Reasoning:  reasoning,
+		Components: components,
+		Inputs:     inputs,
+	}
+	if action == "SHORT" {
+		signal.StopLoss = price * (1 + sl)
+		signal.TakeProfit = price * (1 - tp)
+	}
+	if a.cfg.StopMode == strategy.StopModeATR && ind.ATR > 0 {
+		side, structure := trade.SideBuy, ind.SwingLow
+		if support.Low > 0 {
+			structure = support.Low
+		}
+		if action == "SHORT" {
+			side, structure = trade.SideSell, ind.SwingHigh
+			if resistance.High > 0 {
+				structure = resistance.High
+			}
+		}
+		signal.StopLoss, signal.TakeProfit = trade.ATRLevels(side, price, ind.ATR, structure, a.cfg.ATRStopMultiple, a.cfg.ATRTargetMultiple)
+	}
+
+	return signal, nil
+}
+
+// squeeze returns symbol's live squeeze release when it points the way of
+// action
+func (a *PipelineAnalyzer) squeeze(symbol, action string) (squeeze.Signal, bool) {
+	if a.cfg.Squeezes == nil {
+		return squeeze.Signal{}, false
+	}
+	s, ok := a.cfg.Squeezes.Last(symbol)
+	if !ok || s.Direction != action {
+		return squeeze.Signal{}, false
+	}
+	return s, true
+}
+
+// levels returns the nearest support and resistance of symbol, zero when
+// there is none strong enough. ok is false without a level source or when
+// the levels cannot be loaded, leaving stops on the recent swing.
+func (a *PipelineAnalyzer) levels(ctx context.Context, symbol string) (support, resistance levels.Level, ok bool) {
+	if a.cfg.Levels == nil {
+		return support, resistance, false
+	}
+	klinesCtx, cancel := a.cfg.Calls.Context(ctx, callpolicy.Klines)
+	set, err := a.cfg.Levels.Levels(klinesCtx, symbol)
+	cancel()
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Debug("Levels unavailable, placing stops on the recent swing")
+		return support, resistance, false
+	}
+	support, _ = set.Support(a.cfg.LevelMinStrength)
+	resistance, _ = set.Resistance(a.cfg.LevelMinStrength)
+	return support, resistance, true
+}
+
+// confirm asks the brain for a decision within the decision budget. It
+// returns a nil decision once the brain has timed out in this cycle, so the
+// remaining setups are scored by the indicators alone rather than each
+// waiting out the budget again.
+func (a *PipelineAnalyzer) confirm(ctx context.Context, signal map[string]interface{}) (*brain.TradingDecision, error) {
+	a.mu.Lock()
+	if a.degraded {
+		a.stats.Degraded++
+		a.mu.Unlock()
+		return nil, nil
+	}
+	a.stats.Calls++
+	a.mu.Unlock()
+
+	llmCtx, cancel := a.cfg.Calls.Context(ctx, callpolicy.LLM)
+	defer cancel()
+	if a.cfg.DecisionBudget > 0 {
+		var cancelBudget context.CancelFunc
+		llmCtx, cancelBudget = context.WithTimeout(llmCtx, a.cfg.DecisionBudget)
+		defer cancelBudget()
+	}
+
+	decision, err := a.brain.MakeTradingDecision(llmCtx, signal)
+	if err == nil {
+		return decision, nil
+	}
+	// Only our own deadline degrades; a cancelled or expired cycle context
+	// means the cycle itself is over.
+	if ctx.Err() != nil || !errors.Is(llmCtx.Err(), context.DeadlineExceeded) {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.degraded = true
+	a.stats.Timeouts++
+	a.stats.Degraded++
+	a.stats.LastTimeout = time.Now()
+	a.mu.Unlock()
+	logrus.WithFields(logrus.Fields{
+		"symbol": signal["symbol"],
+		"budget": a.cfg.DecisionBudget,
+	}).Warn("Brain over decision budget, using rule-based scores for this cycle")
+	return nil, nil
+}
+
+// BeginCycle implements cycleAnalyzer, giving the brain another chance
+// after a cycle it timed out in
+func (a *PipelineAnalyzer) BeginCycle() {
+	a.mu.Lock()
+	a.degraded = false
+	a.mu.Unlock()
+}
+
+// BrainStats returns the brain confirmation counters
+func (a *PipelineAnalyzer) BrainStats() BrainStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stats
+}
+
+func countTrue(conds ...bool) int {
+	n := 0
+	for _, c := range conds {
+		if c {
+			n++
+		}
+	}
+	return n
+}
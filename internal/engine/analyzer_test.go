@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/kline"
+	"github.com/britej3/gobot/services/levels"
+	"github.com/britej3/gobot/services/squeeze"
+)
+
+type risingSource struct{}
+
+func (risingSource) Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	start := time.Now().Add(-time.Duration(limit) * 5 * time.Minute).Truncate(5 * time.Minute)
+	klines := make([]trade.Kline, limit)
+	for i := range klines {
+		price := 100 + float64(i)*0.1
+		if i%3 == 0 {
+			price -= 0.25
+		}
+		klines[i] = trade.Kline{
+			OpenTime: start.Add(time.Duration(i) * 5 * time.Minute),
+			Open:     price,
+			High:     price + 0.2,
+			Low:      price - 0.2,
+			Close:    price,
+			Volume:   100,
+		}
+	}
+	return klines, nil
+}
+
+type stubBrain struct {
+	decision string
+}
+
+func (b stubBrain) MakeTradingDecision(ctx context.Context, signal interface{}) (*brain.TradingDecision, error) {
+	return &brain.TradingDecision{Decision: b.decision, Confidence: 0.8}, nil
+}
+
+func TestPipelineAnalyzer_BrainConfirmation(t *testing.T) {
+	klines := kline.New(kline.Config{}, risingSource{})
+	cfg := AnalyzerConfig{RSIOverbought: 80}
+
+	confirmed := NewPipelineAnalyzer(cfg, klines, stubBrain{decision: "BUY"})
+	signal, err := confirmed.Analyze(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signal == nil || signal.Action != "LONG" || signal.StopLoss >= signal.EntryPrice {
+		t.Fatalf("expected confirmed LONG signal, got %+v", signal)
+	}
+
+	rejected := NewPipelineAnalyzer(cfg, klines, stubBrain{decision: "HOLD"})
+	signal, err = rejected.Analyze(context.Background(), "BTCUSDT")
+	if signal != nil {
+		t.Errorf("expected no signal when brain disagrees, got %+v", signal)
+	}
+	var rejection *Rejection
+	if !errors.As(err, &rejection) || rejection.Reason != decisionlog.ReasonBrainVeto {
+		t.Errorf("expected brain veto rejection, got %v", err)
+	}
+}
+
+type stubLevels struct {
+	set levels.Set
+}
+
+func (s stubLevels) Levels(ctx context.Context, symbol string) (levels.Set, error) {
+	return s.set, nil
+}
+
+type recordingBrain struct {
+	signal map[string]interface{}
+}
+
+func (b *recordingBrain) MakeTradingDecision(ctx context.Context, signal interface{}) (*brain.TradingDecision, error) {
+	b.signal = signal.(map[string]interface{})
+	return &brain.TradingDecision{Decision: "BUY", Confidence: 0.8}, nil
+}
+
+func TestPipelineAnalyzer_StopsBeyondNearestLevel(t *testing.T) {
+	klines := kline.New(kline.Config{}, risingSource{})
+	support := levels.Level{Price: 118, Low: 117.8, High: 118.2, Strength: 0.9}
+	weak := levels.Level{Price: 119, Low: 118.9, High: 119.1, Strength: 0.1}
+	cfg := AnalyzerConfig{
+		RSIOverbought: 80,
+		StopMode:      "atr",
+		Levels:        stubLevels{levels.Set{Price: 119.5, Levels: []levels.Level{support, weak}}},
+	}
+	b := &recordingBrain{}
+
+	signal, err := NewPipelineAnalyzer(cfg, klines, b).Analyze(context.Background(), "BTCUSDT")
+	if err != nil || signal == nil {
+		t.Fatalf("signal = %+v, %v", signal, err)
+	}
+	if signal.StopLoss >= support.Low || signal.StopLoss < support.Low-2 {
+		t.Errorf("expected the stop just beyond the support zone at %.2f, got %.4f", support.Low, signal.StopLoss)
+	}
+	if b.signal["support"] != 118.0 || b.signal["resistance"] != 0.0 {
+		t.Errorf("brain was given support %v and resistance %v", b.signal["support"], b.signal["resistance"])
+	}
+}
+
+type stubSqueezes map[string]squeeze.Signal
+
+func (s stubSqueezes) Last(symbol string) (squeeze.Signal, bool) {
+	sig, ok := s[symbol]
+	return sig, ok
+}
+
+func TestPipelineAnalyzer_SqueezeReleaseAddsConfidence(t *testing.T) {
+	klines := kline.New(kline.Config{}, risingSource{})
+	cfg := AnalyzerConfig{
+		RSIOverbought: 80,
+		Squeezes: stubSqueezes{
+			"BTCUSDT": {Symbol: "BTCUSDT", Direction: "LONG", Contribution: 0.1, Bars: 8},
+			"ETHUSDT": {Symbol: "ETHUSDT", Direction: "SHORT", Contribution: 0.1, Bars: 8},
+		},
+	}
+	a := NewPipelineAnalyzer(cfg, klines, stubBrain{decision: "BUY"})
+
+	with, err := a.Analyze(context.Background(), "BTCUSDT")
+	if err != nil || with == nil {
+		t.Fatalf("signal = %+v, %v", with, err)
+	}
+	against, err := a.Analyze(context.Background(), "ETHUSDT")
+	if err != nil || against == nil {
+		t.Fatalf("signal = %+v, %v", against, err)
+	}
+	if with.Confidence-against.Confidence < 0.099 {
+		t.Errorf("expected a squeeze in the setup's direction to add 0.1, got %.3f vs %.3f", with.Confidence, against.Confidence)
+	}
+}
+
+func TestTradingEngine_RefusesWithoutAnalyzer(t *testing.T) {
+	e := &TradingEngine{}
+	if err := e.Start(context.Background()); !errors.Is(err, ErrNoAnalyzer) {
+		t.Errorf("expected ErrNoAnalyzer, got %v", err)
+	}
+}
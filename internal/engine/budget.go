@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// Trading cycle phases with their own latency budget
+const (
+	PhaseAnalysis = "analysis"
+	PhaseEntry    = "entry"
+)
+
+// defaultPhaseBudgets apply when the config leaves a phase unset
+var defaultPhaseBudgets = map[string]time.Duration{
+	PhaseAnalysis: 60 * time.Second,
+	PhaseEntry:    30 * time.Second,
+}
+
+// PhaseStats is the timing of one cycle phase
+type PhaseStats struct {
+	Budget    time.Duration
+	Last      time.Duration
+	Max       time.Duration
+	Overruns  int
+	LastRunAt time.Time
+}
+
+// cycleMetrics tracks per-phase timings and how many cycles were aborted
+// because a phase blew its budget
+type cycleMetrics struct {
+	mu      sync.Mutex
+	budgets map[string]time.Duration
+	phases  map[string]*PhaseStats
+	aborted int
+}
+
+func newCycleMetrics(budgetsMS map[string]int) *cycleMetrics {
+	budgets := make(map[string]time.Duration, len(defaultPhaseBudgets))
+	for phase, d := range defaultPhaseBudgets {
+		budgets[phase] = d
+	}
+	for phase, ms := range budgetsMS {
+		if ms > 0 {
+			budgets[phase] = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return &cycleMetrics{budgets: budgets, phases: make(map[string]*PhaseStats)}
+}
+
+func (m *cycleMetrics) budget(phase string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.budgets[phase]
+}
+
+// record stores a phase's duration and reports whether it stayed in budget
+func (m *cycleMetrics) record(phase string, took time.Duration) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.phases[phase]
+	if !ok {
+		s = &PhaseStats{Budget: m.budgets[phase]}
+		m.phases[phase] = s
+	}
+	s.Last = took
+	s.LastRunAt = time.Now()
+	if took > s.Max {
+		s.Max = took
+	}
+
+	within := s.Budget <= 0 || took <= s.Budget
+	if !within {
+		s.Overruns++
+	}
+	return within
+}
+
+func (m *cycleMetrics) abort() {
+	m.mu.Lock()
+	m.aborted++
+	m.mu.Unlock()
+}
+
+func (m *cycleMetrics) snapshot() (map[string]PhaseStats, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]PhaseStats, len(m.phases))
+	for phase, s := range m.phases {
+		out[phase] = *s
+	}
+	return out, m.aborted
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/britej3/gobot/services/cluster"
+	"github.com/britej3/gobot/services/decisionlog"
+)
+
+// declusterSignals keeps only the highest-confidence signal of each cluster
+// of same-direction signals on correlated symbols within
+// trading.cluster_window_seconds, since those are usually one market-wide
+// move entered several times over. The rest are rejected.
+func (e *TradingEngine) declusterSignals(signals []*TradingSignal) []*TradingSignal {
+	if e.clusters == nil {
+		return signals
+	}
+	cands := make([]cluster.Candidate, len(signals))
+	for i, s := range signals {
+		cands[i] = cluster.Candidate{Symbol: s.Symbol, Side: s.Action, Score: s.Confidence, Time: s.Timestamp}
+	}
+	clusters := e.clusters.Detect(cands)
+	if len(clusters) == 0 {
+		return signals
+	}
+
+	dropped := make(map[int]bool)
+	for _, cl := range clusters {
+		winner := signals[cl.Winner]
+		var others []string
+		for _, i := range cl.Members {
+			if i == cl.Winner {
+				continue
+			}
+			dropped[i] = true
+			others = append(others, signals[i].Symbol)
+
+			rec := signalDecision(signals[i].Symbol, signals[i], decisionlog.ActionReject, decisionlog.ReasonClustered)
+			rec.Scores["winner_confidence"] = winner.Confidence
+			rec.Thresholds = map[string]float64{"cluster_window_seconds": float64(e.cfg.Trading.ClusterWindowSecs)}
+			rec.Detail = fmt.Sprintf("%s cluster led by %s", cl.Bucket, winner.Symbol)
+			e.decide(rec)
+		}
+		log.Printf("Clustered %s %s entries: taking %s, skipping %s", cl.Bucket, cl.Side, winner.Symbol, strings.Join(others, ", "))
+		e.auditLogger.Log("SIGNALS_CLUSTERED", map[string]interface{}{
+			"bucket":  cl.Bucket,
+			"side":    cl.Side,
+			"taken":   winner.Symbol,
+			"skipped": others,
+		})
+	}
+
+	kept := signals[:0:0]
+	for i, s := range signals {
+		if !dropped[i] {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
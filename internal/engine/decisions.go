@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/britej3/gobot/pkg/intent"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/events"
+	"github.com/britej3/gobot/services/orderqueue"
+	"github.com/britej3/gobot/services/tif"
+)
+
+// Signal sources recorded with each decision
+const (
+	SourceAnalysis = "analysis"
+	SourceWebhook  = "webhook"
+	SourceLeader   = "leader"
+	SourceIdea     = "idea"
+)
+
+// Rejection is returned by an Analyzer that found a setup but declined to
+// signal it. The trading loop records it as a rejected decision rather than
+// an analysis failure.
+type Rejection struct {
+	Candidate  string
+	Reason     string
+	Scores     map[string]float64
+	Thresholds map[string]float64
+	Detail     string
+	Inputs     map[string]float64
+}
+
+func (r *Rejection) Error() string {
+	if r.Detail != "" {
+		return fmt.Sprintf("%s setup rejected: %s (%s)", r.Candidate, r.Reason, r.Detail)
+	}
+	return fmt.Sprintf("%s setup rejected: %s", r.Candidate, r.Reason)
+}
+
+// decide appends rec to the decision log, if one is configured, and prints a
+// one-line summary. Skips for lack of a setup are only logged to the file.
+func (e *TradingEngine) decide(rec decisionlog.Record) {
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	if e.decisions != nil {
+		if err := e.decisions.Append(rec); err != nil {
+			log.Printf("Failed to record decision for %s: %v", rec.Symbol, err)
+		}
+	}
+	e.events.Publish(events.TypeDecision, rec)
+	switch rec.Action {
+	case decisionlog.ActionReject, decisionlog.ActionDrop, decisionlog.ActionWatch:
+		e.advanceIntent(rec.Intent, intent.Rejected, strings.Join(rec.Reasons, ","))
+	}
+	if rec.Action == decisionlog.ActionSkip && len(rec.Reasons) == 1 && rec.Reasons[0] == decisionlog.ReasonNoSetup {
+		return
+	}
+
+	line := fmt.Sprintf("Decision %s %s", rec.Action, rec.Symbol)
+	if rec.Candidate != "" {
+		line += " " + rec.Candidate
+	}
+	line += ": " + strings.Join(rec.Reasons, ",")
+	if rec.Detail != "" {
+		line += " (" + rec.Detail + ")"
+	}
+	log.Print(line)
+}
+
+// signalDecision starts a record for signal on symbol with its confidence
+// and components as scores
+func signalDecision(symbol string, signal *TradingSignal, action decisionlog.Action, reason string) decisionlog.Record {
+	scores := map[string]float64{"confidence": signal.Confidence, "ev_bps": signal.ExpectedValue}
+	if signal.Cost.Entry > 0 {
+		scores["cost_bps"] = signal.Cost.TotalBps
+		scores["slippage_bps"] = signal.Cost.SlippageBps
+	}
+	for name, v := range signal.Components {
+		scores[name] = v
+	}
+	return decisionlog.Record{
+		Symbol:    symbol,
+		Candidate: signal.Action,
+		Action:    action,
+		Reasons:   []string{reason},
+		Scores:    scores,
+		Source:    signal.Source,
+		Intent:    signal.Intent,
+		Inputs:    signal.Inputs,
+	}
+}
+
+// advanceIntent moves a signal's intent along its lifecycle. A refused
+// transition means an entry path skipped a step, so it is logged rather
+// than failing the trade.
+func (e *TradingEngine) advanceIntent(id string, to intent.State, reason string) {
+	if err := e.intents.Advance(id, to, reason); err != nil {
+		log.Printf("Intent lifecycle: %v", err)
+	}
+}
+
+// dropReason returns the reason code for an accepted entry that was dropped
+// before reaching the book, or "" if err is not such a drop
+func dropReason(err error) string {
+	switch {
+	case errors.Is(err, orderqueue.ErrStale):
+		return decisionlog.ReasonStaleSignal
+	case errors.Is(err, orderqueue.ErrCancelled):
+		return decisionlog.ReasonOrderCancelled
+	case errors.Is(err, ErrChaseAbandoned):
+		return decisionlog.ReasonChaseAbandoned
+	case errors.Is(err, tif.ErrUnfilled):
+		return decisionlog.ReasonUnfilled
+	}
+	return ""
+}
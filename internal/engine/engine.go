@@ -0,0 +1,2009 @@
+// Package engine implements the watchlist trading engine run by `gobot run engine`
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/config"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/infra/binance"
+	"github.com/britej3/gobot/internal/app"
+	"github.com/britej3/gobot/pkg/alerting"
+	"github.com/britej3/gobot/pkg/apiweight"
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/pkg/entrylimit"
+	"github.com/britej3/gobot/pkg/graphql"
+	"github.com/britej3/gobot/pkg/intent"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/pkg/symlock"
+	"github.com/britej3/gobot/services/accountguard"
+	"github.com/britej3/gobot/services/allocator"
+	"github.com/britej3/gobot/services/bookarchive"
+	"github.com/britej3/gobot/services/calendar"
+	"github.com/britej3/gobot/services/chase"
+	"github.com/britej3/gobot/services/cluster"
+	"github.com/britej3/gobot/services/costmodel"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/drift"
+	"github.com/britej3/gobot/services/earnsweep"
+	"github.com/britej3/gobot/services/equity"
+	"github.com/britej3/gobot/services/events"
+	"github.com/britej3/gobot/services/feetier"
+	"github.com/britej3/gobot/services/fillcheck"
+	"github.com/britej3/gobot/services/ideas"
+	"github.com/britej3/gobot/services/instancelock"
+	"github.com/britej3/gobot/services/keyhealth"
+	"github.com/britej3/gobot/services/liquidation"
+	"github.com/britej3/gobot/services/margintarget"
+	"github.com/britej3/gobot/services/orderack"
+	"github.com/britej3/gobot/services/orderbook"
+	"github.com/britej3/gobot/services/orderqueue"
+	"github.com/britej3/gobot/services/paperfill"
+	"github.com/britej3/gobot/services/profitlock"
+	"github.com/britej3/gobot/services/protect"
+	"github.com/britej3/gobot/services/riskmode"
+	"github.com/britej3/gobot/services/suspension"
+	"github.com/britej3/gobot/services/symbolmemory"
+	"github.com/britej3/gobot/services/symbolrules"
+	"github.com/britej3/gobot/services/throttle"
+	"github.com/britej3/gobot/services/tif"
+)
+
+// TradingSignal is an entry suggestion for one symbol, produced by analysis
+// or received over the trade signal webhook
+type TradingSignal struct {
+	Symbol     string    `json:"symbol"`
+	Action     string    `json:"action"`
+	Confidence float64   `json:"confidence"`
+	EntryPrice float64   `json:"entry_price"`
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	Reasoning  string    `json:"reasoning"`
+	Timestamp  time.Time `json:"timestamp,omitempty"`
+
+	// Components are the weighted inputs behind Confidence, journaled with
+	// the position for PnL attribution.
+	Components map[string]float64 `json:"components,omitempty"`
+
+	// Source is where the signal came from, recorded with its decisions.
+	Source string `json:"-"`
+	// Idea is who submitted the external idea behind the signal, if any.
+	Idea string `json:"-"`
+	// ExpectedValue is the entry's EV in basis points of entry price, set
+	// when the signal is considered for entry.
+	ExpectedValue float64 `json:"-"`
+	// Intent is the lifecycle the signal's entry is tracked under.
+	Intent string `json:"-"`
+	// Inputs are the indicator values the brain confirmed the signal on.
+	Inputs map[string]float64 `json:"-"`
+	// Cost is the execution cost forecast the EV gate charged the entry.
+	Cost costmodel.Forecast `json:"-"`
+}
+
+// TradingEngine runs the watchlist trading loop against the hardened client
+type TradingEngine struct {
+	cfg          *config.ProductionConfig
+	binance      *binance.HardenedClient
+	stateManager *state.TradingState
+	telegram     *alerting.TelegramAlert
+	auditLogger  *alerting.AuditLogger
+	analyzer     Analyzer
+	rules        *symbolrules.Registry
+	memory       *symbolmemory.Memory
+	throttle     *throttle.Throttle
+	orders       *orderqueue.Queue
+	acks         *orderack.Tracker
+	paper        *paperfill.Shadow
+	chaser       *chase.Chaser
+	timeInForce  *tif.Executor
+	earn         *earnsweep.Sweeper
+	cycle        *cycleMetrics
+	calls        *callpolicy.Policy
+	decisions    *decisionlog.Log
+	suspensions  *suspension.Controller
+	guard        *accountguard.Guard
+	planner      *trade.PositionPlanner
+	calendar     *calendar.Calendar
+	keys         *keyhealth.Monitor
+	drift        *drift.Checker
+	protection   *protect.Guard
+	liquidations *liquidation.Monitor
+	fees         *feetier.Tracker
+	ideas        *ideas.Inbox
+	fee          func(symbol string) float64
+	riskModes    *riskmode.Switch
+	depth        *orderbook.Books
+	margin       *margintarget.Controller
+	profitLock   *profitlock.Lock
+	fills        *fillcheck.Checker
+	costs        *costmodel.Model
+	instance     *instancelock.Guard
+	weights      *apiweight.Budget
+	events       *events.Stream
+	equity       *equity.Recorder
+	books        *bookarchive.Archiver
+	clusters     *cluster.Detector
+	positions    *symlock.Locks
+	intents      *intent.Tracker
+	entryLimits  *entrylimit.Limiter
+	allocator    *allocator.Allocator
+	hub          signalHub
+
+	mu             sync.RWMutex
+	running        bool
+	cancel         context.CancelFunc
+	lastTrade      time.Time
+	symbolCooldown map[string]time.Time
+	tradesToday    int
+	dailyPnL       float64
+	// inFlight counts async entries submitted but not yet settled; they
+	// count towards the daily trade limit
+	inFlight int
+
+	precisionRetries  int
+	precisionRecovers int
+	watched           int
+}
+
+// NewTradingEngine builds an engine from the container's shared components.
+// The indicator pipeline is used as analyzer, confirmed by the brain when AI is enabled.
+func NewTradingEngine(c *app.Container) (*TradingEngine, error) {
+	stateManager, err := c.State()
+	if err != nil {
+		return nil, err
+	}
+
+	var confirm DecisionMaker
+	if c.Config.AI.Enabled {
+		engine, err := c.Brain()
+		if err != nil {
+			return nil, err
+		}
+		confirm = engine
+	}
+
+	decisions, err := c.Decisions()
+	if err != nil {
+		return nil, err
+	}
+	suspensions, err := c.Suspensions()
+	if err != nil {
+		return nil, err
+	}
+	equityLog, err := c.Equity()
+	if err != nil {
+		return nil, err
+	}
+	books, err := c.BookArchive()
+	if err != nil {
+		return nil, err
+	}
+	riskModes, err := c.RiskModes()
+	if err != nil {
+		return nil, err
+	}
+	costs, err := c.CostModel()
+	if err != nil {
+		return nil, err
+	}
+	th, err := c.SymbolThrottle()
+	if err != nil {
+		return nil, err
+	}
+	profitLock, err := c.ProfitLock()
+	if err != nil {
+		return nil, err
+	}
+	// The positions the engine opens are journaled as trades once the
+	// account stream reports them closed.
+	if _, err := c.Exits(); err != nil {
+		return nil, err
+	}
+	alloc, err := c.Allocator()
+	if err != nil {
+		return nil, err
+	}
+
+	calls := c.Calls()
+	analyzerCfg := analyzerConfig(c.Config)
+	analyzerCfg.Calls = calls
+	analyzerCfg.DecisionBudget = c.Config.AI.GetDecisionBudget()
+	if lv := c.Levels(); lv != nil {
+		analyzerCfg.Levels = lv
+	}
+	if sq := c.Squeezes(); sq != nil {
+		analyzerCfg.Squeezes = sq
+	}
+	if riskModes != nil {
+		// The active profile's confidence is applied in confidentEnough.
+		analyzerCfg.MinConfidence = riskModes.MinConfidence()
+	}
+	analyzer := NewPipelineAnalyzer(analyzerCfg, c.Klines(), confirm)
+
+	e := &TradingEngine{
+		analyzer:       analyzer,
+		cfg:            c.Config,
+		binance:        c.Binance(),
+		stateManager:   stateManager,
+		telegram:       c.Telegram(),
+		auditLogger:    c.Audit(),
+		rules:          c.SymbolRules(),
+		memory:         c.SymbolMemory(),
+		throttle:       th,
+		earn:           c.EarnSweeper(),
+		symbolCooldown: make(map[string]time.Time),
+		cycle:          newCycleMetrics(c.Config.Performance.LatencyBudgetMS),
+		calls:          calls,
+		decisions:      decisions,
+		suspensions:    suspensions,
+		guard:          c.AccountGuard(),
+		planner:        c.Planner(),
+		calendar:       c.Calendar(),
+		keys:           c.KeyHealth(),
+		drift:          c.Drift(),
+		protection:     c.Protection(),
+		liquidations:   c.Liquidations(),
+		fees:           c.Fees(),
+		fee:            c.FeeRate(),
+		ideas:          c.Ideas(),
+		riskModes:      riskModes,
+		depth:          c.OrderBooks(),
+		margin:         c.MarginTarget(),
+		profitLock:     profitLock,
+		fills:          c.FillCheck(),
+		costs:          costs,
+		instance:       c.InstanceLock(),
+		weights:        c.APIWeight(),
+		events:         c.Events(),
+		equity:         equityLog,
+		books:          books,
+		positions:      c.PositionLocks(),
+		intents:        c.Intents(),
+		entryLimits:    c.EntryLimits(),
+		allocator:      alloc,
+		acks:           c.OrderAcks(),
+		paper:          c.ShadowFills(),
+		orders: orderqueue.New(orderqueue.Config{
+			Workers:      c.Config.Execution.OrderWorkers,
+			MaxQueued:    c.Config.Execution.MaxQueuedOrders,
+			MaxSignalAge: c.Config.Execution.GetMaxSignalAge(),
+		}),
+	}
+
+	if e.liquidations != nil && e.protection != nil && c.Config.Risk.CascadeStopFactor > 0 {
+		e.liquidations.OnCascade(func(s liquidation.Stats) { go e.tightenStops(s) })
+	}
+
+	if window := c.Config.Trading.ClusterWindowSecs; window > 0 {
+		e.clusters = cluster.New(cluster.Config{
+			Window:  time.Duration(window) * time.Second,
+			Buckets: c.Config.Risk.CorrelationBuckets,
+		})
+	}
+
+	if c.Config.Execution.EntryMode == "chase" {
+		var venue chase.Venue = e.binance
+		if e.depth != nil {
+			venue = bookVenue{HardenedClient: e.binance, books: e.depth}
+		}
+		chaser := chase.New(chase.Config{
+			MaxRepegs:               c.Config.Execution.ChaseMaxRepegs,
+			MaxWait:                 c.Config.Execution.GetChaseMaxWait(),
+			PollInterval:            c.Config.Execution.GetChasePoll(),
+			RoundQuantity:           e.roundQuantity,
+			Calls:                   calls,
+			Remainder:               c.Config.Execution.ChaseRemainder,
+			RemainderMaxSlippageBps: c.Config.Execution.ChaseRemainderMaxSlippageBps,
+			RemainderRepegs:         c.Config.Execution.ChaseRemainderRepegs,
+		}, venue)
+		c.UserData().OnOrder(func(u accountguard.OrderUpdate) {
+			chaser.Update(chase.Fill{
+				OrderID:  strconv.FormatInt(u.OrderID, 10),
+				Status:   u.Status,
+				Filled:   u.Filled,
+				AvgPrice: u.AvgPrice,
+			})
+		})
+		e.chaser = chaser
+	}
+	if t := c.Config.Execution.TimeInForce; t != "" && e.chaser == nil {
+		var venue tif.Venue = e.binance
+		if e.depth != nil {
+			venue = bookVenue{HardenedClient: e.binance, books: e.depth}
+		}
+		executor, err := tif.New(tif.Config{
+			TimeInForce:      trade.TimeInForce(t),
+			SweepBps:         c.Config.Execution.TIFSweepBps,
+			Retries:          c.Config.Execution.TIFRetries,
+			RestFor:          time.Duration(c.Config.Execution.TIFRestSeconds) * time.Second,
+			RoundPrice:       e.roundPrice,
+			RoundQuantity:    e.roundQuantity,
+			PostOnlyRejected: binance.IsPostOnlyRejection,
+			Calls:            calls,
+		}, venue)
+		if err != nil {
+			return nil, err
+		}
+		e.timeInForce = executor
+	}
+	return e, nil
+}
+
+// SetAnalyzer replaces the signal source used by the trading loop
+func (e *TradingEngine) SetAnalyzer(analyzer Analyzer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.analyzer = analyzer
+}
+
+// Start runs the trading loop until ctx is cancelled or Stop is called. It
+// refuses to start without an analyzer.
+func (e *TradingEngine) Start(ctx context.Context) error {
+	e.mu.Lock()
+	if e.analyzer == nil {
+		e.mu.Unlock()
+		return ErrNoAnalyzer
+	}
+	if e.running {
+		e.mu.Unlock()
+		return fmt.Errorf("engine already running")
+	}
+	e.running = true
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.mu.Unlock()
+
+	log.Println("Starting GOBOT Trading Engine...")
+
+	if e.orders != nil {
+		e.orders.Start(ctx)
+	}
+	e.checkKillSwitch()
+
+	e.auditLogger.Log("ENGINE_START", map[string]interface{}{
+		"initial_capital": e.cfg.Trading.InitialCapitalUSD,
+		"max_position":    e.cfg.Trading.MaxPositionUSD,
+	})
+
+	go e.runTradingLoop(ctx)
+
+	log.Println("GOBOT Trading Engine started")
+	return nil
+}
+
+// Stop halts the engine, cancelling any in-flight calls, and saves state
+func (e *TradingEngine) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.running {
+		return
+	}
+
+	e.running = false
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+	e.stateManager.Save()
+	log.Println("GOBOT Trading Engine stopped")
+}
+
+func (e *TradingEngine) runTradingLoop(ctx context.Context) {
+	interval := e.cfg.Trading.GetTradingInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if e.shouldTrade() {
+				e.executeTradingCycle(ctx)
+			} else {
+				e.skipWatchlist(e.tradingBlock())
+			}
+		}
+	}
+}
+
+// executeTradingCycle analyzes the whole watchlist and then enters on the
+// resulting signals. Each phase runs against its latency budget: if analysis
+// overruns, nothing is entered on the stale results, and if entries overrun,
+// the remaining ones are skipped.
+func (e *TradingEngine) executeTradingCycle(ctx context.Context) {
+	e.auditLogger.Log("TRADING_CYCLE_START", nil)
+	if e.cycle == nil {
+		e.cycle = newCycleMetrics(nil)
+	}
+	if e.stateManager.IsSuspended(SourceAnalysis) {
+		e.skipWatchlist(decisionlog.ReasonSuspended)
+		return
+	}
+
+	signals, ok := e.analyzeWatchlist(ctx)
+	if !ok {
+		e.abortCycle(PhaseAnalysis, signals)
+		return
+	}
+	signals = e.declusterSignals(signals)
+
+	budget := e.cycle.budget(PhaseEntry)
+	start := time.Now()
+	for i, signal := range signals {
+		if budget > 0 && time.Since(start) > budget {
+			e.cycle.record(PhaseEntry, time.Since(start))
+			e.abortCycle(PhaseEntry, signals[i:])
+			return
+		}
+		e.executeTrade(ctx, signal.Symbol, signal)
+	}
+	e.cycle.record(PhaseEntry, time.Since(start))
+
+	e.auditLogger.Log("TRADING_CYCLE_END", nil)
+}
+
+// analyzeWatchlist runs analysis for every tradable symbol under the
+// analysis deadline and reports whether it finished within budget
+func (e *TradingEngine) analyzeWatchlist(ctx context.Context) ([]*TradingSignal, bool) {
+	budget := e.cycle.budget(PhaseAnalysis)
+	phaseCtx := ctx
+	if budget > 0 {
+		var cancel context.CancelFunc
+		phaseCtx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	if a, ok := e.analyzer.(cycleAnalyzer); ok {
+		a.BeginCycle()
+	}
+
+	start := time.Now()
+	symbols, ideaBy := e.watchlist()
+	source := func(symbol string) string {
+		if _, ok := ideaBy[symbol]; ok {
+			return SourceIdea
+		}
+		return SourceAnalysis
+	}
+	var candidates []string
+	for _, symbol := range symbols {
+		if reason := e.symbolBlock(symbol); reason != "" {
+			e.decide(decisionlog.Record{Symbol: symbol, Action: decisionlog.ActionSkip, Reasons: []string{reason}, Source: source(symbol)})
+			continue
+		}
+		candidates = append(candidates, symbol)
+	}
+
+	results, starved := e.analyzeAll(phaseCtx, candidates, e.cfg.Performance.GetAnalysisWorkers())
+	var signals []*TradingSignal
+	for i, symbol := range candidates {
+		signal, err := results[i].signal, results[i].err
+		if !results[i].ran {
+			if starved {
+				e.decide(decisionlog.Record{Symbol: symbol, Action: decisionlog.ActionSkip, Reasons: []string{decisionlog.ReasonAnalysisError}, Detail: "not analyzed: API weight budget spent", Source: source(symbol)})
+			}
+			continue
+		}
+		var rejection *Rejection
+		if errors.As(err, &rejection) {
+			e.decide(decisionlog.Record{
+				Symbol:     symbol,
+				Candidate:  rejection.Candidate,
+				Action:     decisionlog.ActionReject,
+				Reasons:    []string{rejection.Reason},
+				Scores:     rejection.Scores,
+				Thresholds: rejection.Thresholds,
+				Detail:     rejection.Detail,
+				Inputs:     rejection.Inputs,
+				Source:     source(symbol),
+			})
+			continue
+		}
+		if err != nil {
+			e.decide(decisionlog.Record{Symbol: symbol, Action: decisionlog.ActionReject, Reasons: []string{decisionlog.ReasonAnalysisError}, Detail: err.Error(), Source: source(symbol)})
+			continue
+		}
+		if signal == nil {
+			e.decide(decisionlog.Record{Symbol: symbol, Action: decisionlog.ActionSkip, Reasons: []string{decisionlog.ReasonNoSetup}, Source: source(symbol)})
+			continue
+		}
+		signal.Symbol = symbol
+		signal.Source = source(symbol)
+		signal.Idea = ideaBy[symbol]
+		if signal.Timestamp.IsZero() {
+			signal.Timestamp = time.Now()
+		}
+		signals = append(signals, signal)
+		e.books.Capture(ctx, symbol)
+	}
+
+	within := e.cycle.record(PhaseAnalysis, time.Since(start))
+	return signals, within && phaseCtx.Err() == nil
+}
+
+// watchlist returns the symbols to analyze this cycle: the configured
+// watchlist, those with an active external idea first, newest idea first.
+// ideaBy maps each idea symbol to its submitter.
+func (e *TradingEngine) watchlist() (symbols []string, ideaBy map[string]string) {
+	watched := make(map[string]bool, len(e.cfg.Watchlist.Symbols))
+	for _, symbol := range e.cfg.Watchlist.Symbols {
+		watched[symbol] = true
+	}
+	ideaBy = make(map[string]string)
+	for _, idea := range e.ideas.Active() {
+		// Ideas only reorder the watchlist; one on a symbol outside it is
+		// not analyzed, let alone traded.
+		if !watched[idea.Symbol] {
+			continue
+		}
+		ideaBy[idea.Symbol] = idea.Submitter
+		symbols = append(symbols, idea.Symbol)
+	}
+	for _, symbol := range e.cfg.Watchlist.Symbols {
+		if _, ok := ideaBy[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols, ideaBy
+}
+
+// abortCycle ends a cycle that overran its budget, recording each signal
+// that will not be entered
+func (e *TradingEngine) abortCycle(phase string, skipped []*TradingSignal) {
+	e.cycle.abort()
+	budget := e.cycle.budget(phase)
+	log.Printf("Trading cycle aborted: %s phase exceeded its %s budget, %d signal(s) not entered", phase, budget, len(skipped))
+	e.auditLogger.Log("TRADING_CYCLE_ABORTED", map[string]interface{}{
+		"phase":     phase,
+		"budget_ms": budget.Milliseconds(),
+		"skipped":   len(skipped),
+	})
+
+	for _, signal := range skipped {
+		rec := signalDecision(signal.Symbol, signal, decisionlog.ActionDrop, decisionlog.ReasonCycleBudget)
+		rec.Thresholds = map[string]float64{"budget_ms": float64(budget.Milliseconds())}
+		rec.Detail = phase + " phase"
+		e.decide(rec)
+	}
+}
+
+// executeTrade validates signal and enters it, and reports whether the
+// entry filled. With async orders it returns false once the order is
+// queued, as nothing has filled yet: completeEntry runs when the order
+// settles, holding the symbol's lock until then, and counts the trade
+func (e *TradingEngine) executeTrade(ctx context.Context, symbol string, signal *TradingSignal) bool {
+	if signal.Source == "" {
+		signal.Source = SourceWebhook
+	}
+	signal.Intent = e.intents.Propose(symbol, signal.Action, signal.Source)
+	unlock, ok := e.lockSymbol(ctx, symbol, signal)
+	if !ok {
+		return false
+	}
+	async := false
+	defer func() {
+		if !async {
+			unlock()
+		}
+	}()
+	e.books.Capture(ctx, symbol)
+
+	side := trade.SideBuy
+	if signal.Action == "SHORT" {
+		side = trade.SideSell
+	}
+	// Provisional EV on the raw levels, so every decision carries one; the
+	// gate below recomputes it on rounded levels with the live spread.
+	signal.ExpectedValue = e.expectedValue(side, signal, signal.StopLoss, signal.TakeProfit, 0)
+	if e.stateManager.IsSuspended(signal.Source) || e.stateManager.IsSuspended(symbol) {
+		e.decide(signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonSuspended))
+		return false
+	}
+	if e.tradeCount()+e.entriesInFlight() >= e.maxTradesPerDay() {
+		rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonMaxTradesPerDay)
+		rec.Thresholds = map[string]float64{"max_trades_per_day": float64(e.maxTradesPerDay())}
+		e.decide(rec)
+		return false
+	}
+	if !e.confidentEnough(symbol, signal) {
+		return false
+	}
+
+	positionSize, leverage := e.calculatePositionSize(side, signal)
+	if positionSize <= 0 {
+		e.decide(signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonZeroSize))
+		return false
+	}
+
+	stopLoss, takeProfit, ok := e.checkRiskReward(ctx, symbol, side, signal)
+	if !ok {
+		return false
+	}
+	positionSize, ok = e.fitExchangeMinimum(symbol, signal, positionSize, stopLoss)
+	if !ok {
+		return false
+	}
+	bid, ask := e.liveQuote(ctx, symbol)
+	if !e.checkQuoteDrift(symbol, side, signal, takeProfit, bid, ask) {
+		return false
+	}
+	if !e.checkExpectedValue(symbol, side, signal, positionSize, stopLoss, takeProfit, bid, ask) {
+		return false
+	}
+	if !e.checkBookDepth(symbol, side, signal) {
+		return false
+	}
+	unreserve, ok := e.reserveAllocation(symbol, signal, positionSize*signal.EntryPrice)
+	if !ok {
+		return false
+	}
+	freeSlot, ok := e.takeEntrySlot(symbol, signal)
+	if !ok {
+		unreserve()
+		return false
+	}
+	release := func() {
+		freeSlot()
+		unreserve()
+	}
+	e.advanceIntent(signal.Intent, intent.Validated, "")
+	if e.cfg.Execution.WatchOnly {
+		release()
+		e.watchEntry(symbol, signal, positionSize, stopLoss, takeProfit)
+		return false
+	}
+
+	order := &trade.Order{
+		Symbol:     symbol,
+		Side:       side,
+		Type:       trade.OrderTypeMarket,
+		Quantity:   positionSize,
+		StopLoss:   stopLoss,
+		TakeProfit: takeProfit,
+	}
+	if e.paper != nil {
+		e.paper.Open(paperfill.Entry{
+			ID:       signal.Intent,
+			Symbol:   symbol,
+			Side:     side,
+			Quantity: positionSize,
+			Bid:      bid,
+			Ask:      ask,
+			Expected: signal.EntryPrice,
+			Passive:  e.chaser != nil || e.cfg.Execution.TimeInForce == string(trade.TimeInForceGTX),
+		})
+	}
+
+	if e.acks != nil {
+		async = true
+		e.submitAsync(ctx, order, signal.Timestamp, signal.Intent, func(err error) {
+			defer unlock()
+			if !e.completeEntry(symbol, signal, order, leverage, err) {
+				release()
+			}
+		})
+		return false
+	}
+	err := e.submitOrder(ctx, order, signal.Timestamp, signal.Intent)
+	if !e.completeEntry(symbol, signal, order, leverage, err) {
+		release()
+		return false
+	}
+	return true
+}
+
+// takeEntrySlot counts the entry against its session's entry limit,
+// rejecting it when the session's window is full. The returned func gives
+// the slot back to an entry that does not open
+func (e *TradingEngine) takeEntrySlot(symbol string, signal *TradingSignal) (func(), bool) {
+	release, err := e.entryLimits.Take()
+	var limit *entrylimit.LimitError
+	if errors.As(err, &limit) {
+		rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonEntryRate)
+		rec.Thresholds = map[string]float64{"max_entries": float64(limit.Max), "window_minutes": limit.Window.Minutes()}
+		rec.Detail = err.Error()
+		e.decide(rec)
+		return nil, false
+	}
+	return release, true
+}
+
+// reserveAllocation books notional against the allocation of the signal's
+// source, rejecting the entry when the source has none or its share of the
+// budget is in use. The returned func gives the notional back to an entry
+// that does not open; closed positions give theirs back when journaled
+func (e *TradingEngine) reserveAllocation(symbol string, signal *TradingSignal, notional float64) (func(), bool) {
+	if e.allocator == nil {
+		return func() {}, true
+	}
+	if err := e.allocator.Reserve(signal.Source, notional); err != nil {
+		rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonAllocation)
+		rec.Thresholds = map[string]float64{"cap": e.allocator.Cap(signal.Source)}
+		rec.Detail = err.Error()
+		e.decide(rec)
+		return nil, false
+	}
+	return func() { e.allocator.Release(signal.Source, notional) }, true
+}
+
+// allocationStrategy names the allocation a new position is booked
+// against, or empty when allocation is off
+func (e *TradingEngine) allocationStrategy(signal *TradingSignal) string {
+	if e.allocator == nil {
+		return ""
+	}
+	return signal.Source
+}
+
+// completeEntry records how an entry order ended: a failure is logged and
+// alerted, a fill opens the position, publishes the execution and alerts it
+func (e *TradingEngine) completeEntry(symbol string, signal *TradingSignal, order *trade.Order, leverage int, err error) bool {
+	side := order.Side
+	positionSize, stopLoss, takeProfit := order.Quantity, order.StopLoss, order.TakeProfit
+	e.shadowEntry(signal.Intent, order, err)
+	if reason := dropReason(err); reason != "" {
+		rec := signalDecision(symbol, signal, decisionlog.ActionDrop, reason)
+		rec.Detail = err.Error()
+		e.decide(rec)
+		return false
+	}
+	if err != nil {
+		rec := signalDecision(symbol, signal, decisionlog.ActionDrop, decisionlog.ReasonOrderFailed)
+		rec.Detail = err.Error()
+		e.decide(rec)
+		e.telegram.SendTemplate(alerting.AlertSystemError, alerting.MsgOrderFailed, map[string]interface{}{
+			"Error":  err,
+			"Code":   binance.ErrorCode(err),
+			"Symbol": symbol,
+		})
+		return false
+	}
+
+	fillPrice := order.AvgFillPrice
+	if fillPrice <= 0 {
+		fillPrice = signal.EntryPrice
+	}
+	if e.margin != nil {
+		e.margin.Commit(positionSize * fillPrice / float64(leverage))
+	}
+
+	rec := signalDecision(symbol, signal, decisionlog.ActionEnter, decisionlog.ReasonExecuted)
+	rec.Scores["size"] = positionSize
+	if e.costs != nil {
+		if c, ok := e.costs.Calibrate(signal.Cost, order.AvgFillPrice); ok {
+			rec.Scores["realized_slippage_bps"] = c.RealizedBps
+			log.Printf("💸 Cost forecast %s: realized slippage %.1f bps, %+.1f bps off", signal.Cost, c.RealizedBps, c.ErrorBps)
+		}
+	}
+	e.decide(rec)
+	e.advanceIntent(signal.Intent, intent.Filled, "")
+	e.books.Record(bookarchive.Entry{
+		Symbol:    symbol,
+		Intent:    signal.Intent,
+		Side:      string(side),
+		FillPrice: order.AvgFillPrice,
+		Quantity:  positionSize,
+	})
+
+	e.mu.Lock()
+	e.tradesToday++
+	e.lastTrade = time.Now()
+	e.symbolCooldown[symbol] = time.Now()
+	e.mu.Unlock()
+
+	e.stateManager.AddPosition(state.Position{
+		Symbol:     symbol,
+		Side:       string(side),
+		Size:       positionSize,
+		EntryPrice: signal.EntryPrice,
+		StopLoss:   stopLoss,
+		TakeProfit: takeProfit,
+		OpenTime:   time.Now(),
+		Confidence: signal.Confidence,
+		Reasoning:  signal.Reasoning,
+		Components: signal.Components,
+		FillPrice:  order.AvgFillPrice,
+		Idea:       signal.Idea,
+		Strategy:   e.allocationStrategy(signal),
+	})
+	e.advanceIntent(signal.Intent, intent.Managed, "")
+	if e.protection != nil {
+		protection := protect.Position{
+			Symbol:     symbol,
+			Side:       side,
+			Stop:       e.roundPrice(symbol, stopLoss),
+			TakeProfit: e.roundPrice(symbol, takeProfit),
+		}
+		go func() {
+			if err := e.protection.Protect(context.Background(), protection); err != nil {
+				log.Printf("⚠️ Protective orders for %s not placed: %v", symbol, err)
+			}
+		}()
+	}
+
+	e.events.Publish(events.TypeExecution, events.Execution{
+		Symbol:     symbol,
+		Side:       string(side),
+		Quantity:   positionSize,
+		EntryPrice: signal.EntryPrice,
+		StopLoss:   stopLoss,
+		TakeProfit: takeProfit,
+		Confidence: signal.Confidence,
+		Source:     signal.Source,
+		Components: signal.Components,
+	})
+
+	e.auditLogger.LogTrade(map[string]interface{}{
+		"symbol":      symbol,
+		"action":      signal.Action,
+		"size":        positionSize,
+		"entry_price": signal.EntryPrice,
+	})
+
+	e.telegram.SendTemplate(alerting.AlertTradeExecution, alerting.MsgTradeExecuted, map[string]interface{}{
+		"Action":     signal.Action,
+		"Symbol":     symbol,
+		"Price":      signal.EntryPrice,
+		"Confidence": signal.Confidence,
+	})
+	if e.fills != nil {
+		// Chased and time-in-force entries have no single order to read
+		// back; their executors already confirmed the fills.
+		e.fills.Watch(fillcheck.Claim{
+			Symbol:   symbol,
+			OrderID:  order.ID,
+			Side:     side,
+			Quantity: positionSize,
+			Price:    signal.EntryPrice,
+		})
+	}
+
+	published := *signal
+	published.Symbol = symbol
+	published.StopLoss, published.TakeProfit = stopLoss, takeProfit
+	published.Timestamp = time.Now()
+	e.hub.publish(published)
+
+	return true
+}
+
+// shadowEntry settles the paper side of an entry. Entries never sent are
+// forgotten; an abandoned chase or a failed order is a fill paper got and
+// live missed
+func (e *TradingEngine) shadowEntry(id string, order *trade.Order, err error) {
+	switch {
+	case e.paper == nil:
+	case errors.Is(err, orderqueue.ErrStale), errors.Is(err, orderqueue.ErrCancelled):
+		e.paper.Cancel(id)
+	case err != nil:
+		e.paper.Live(id, false, 0, 0)
+	default:
+		e.paper.Live(id, true, order.AvgFillPrice, order.Quantity)
+	}
+}
+
+// checkRiskReward rounds the signal's levels to the symbol's tick size and
+// rejects the entry when the reward/risk net of fees is below the configured
+// minimum. It returns the rounded stop loss and take profit.
+func (e *TradingEngine) checkRiskReward(ctx context.Context, symbol string, side trade.Side, signal *TradingSignal) (float64, float64, bool) {
+	entry, stopLoss, takeProfit := signal.EntryPrice, signal.StopLoss, signal.TakeProfit
+	if e.rules != nil {
+		rulesCtx, cancel := e.calls.Context(ctx, callpolicy.Exchange)
+		rules, err := e.rules.Ensure(rulesCtx, symbol)
+		cancel()
+		if err == nil {
+			entry = rules.RoundPrice(entry)
+			stopLoss = rules.RoundPrice(stopLoss)
+			takeProfit = rules.RoundPrice(takeProfit)
+		} else {
+			log.Printf("No symbol rules for %s, checking R:R on raw levels: %v", symbol, err)
+		}
+	}
+
+	minRR := e.cfg.Trading.GetMinRiskReward()
+	rr := trade.RiskReward(side, entry, stopLoss, takeProfit, e.feeRate(symbol))
+	if rr >= minRR {
+		return stopLoss, takeProfit, true
+	}
+
+	reason := fmt.Sprintf("R:R %.2f below minimum %.2f (entry %.8g, sl %.8g, tp %.8g)", rr, minRR, entry, stopLoss, takeProfit)
+	if rr == 0 {
+		reason = fmt.Sprintf("stop loss %.8g or take profit %.8g on wrong side of entry %.8g, or target within fees", stopLoss, takeProfit, entry)
+	}
+	rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonRiskReward)
+	rec.Scores["risk_reward"] = rr
+	rec.Thresholds = map[string]float64{"min_risk_reward": minRR}
+	rec.Detail = reason
+	e.decide(rec)
+	e.auditLogger.Log("TRADE_REJECTED", map[string]interface{}{
+		"symbol":      symbol,
+		"action":      signal.Action,
+		"risk_reward": rr,
+		"min_rr":      minRR,
+		"entry_price": entry,
+		"stop_loss":   stopLoss,
+		"take_profit": takeProfit,
+		"reason":      reason,
+	})
+	return 0, 0, false
+}
+
+// fitExchangeMinimum rounds size to the symbol's lot step. A size below the
+// exchange's minimum quantity or notional, common on small accounts, is
+// bumped to the minimum when the stop would then lose no more than
+// trading.min_notional_max_risk of capital; otherwise the entry is rejected
+// here instead of by the exchange.
+func (e *TradingEngine) fitExchangeMinimum(symbol string, signal *TradingSignal, size, stopLoss float64) (float64, bool) {
+	if e.rules == nil || signal.EntryPrice <= 0 {
+		return size, true
+	}
+	rules, ok := e.rules.Get(symbol)
+	if !ok {
+		return size, true
+	}
+
+	price := signal.EntryPrice
+	rounded := rules.RoundQuantity(size)
+	minQty := rules.MinQuantity(price)
+	if rounded > 0 && rounded >= minQty*(1-1e-9) {
+		return rounded, true
+	}
+
+	capital := e.stateManager.GetStats().Capital
+	maxRisk := capital * e.cfg.Trading.MinNotionalMaxRisk
+	risk := minQty * math.Abs(price-stopLoss)
+	notional := minQty * price
+	maxNotional := e.cfg.Trading.MaxPositionUSD
+	if maxRisk > 0 && risk <= maxRisk && (maxNotional <= 0 || notional <= maxNotional) {
+		log.Printf("Bumping %s size %.8g to exchange minimum %.8g (%.2f notional, risking %.2f of %.2f allowed)",
+			symbol, rounded, minQty, notional, risk, maxRisk)
+		e.auditLogger.Log("SIZE_BUMPED", map[string]interface{}{
+			"symbol":       symbol,
+			"size":         rounded,
+			"min_quantity": minQty,
+			"notional":     notional,
+			"risk":         risk,
+			"max_risk":     maxRisk,
+		})
+		return minQty, true
+	}
+
+	rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonBelowMinimum)
+	rec.Scores["size"] = rounded
+	rec.Scores["notional"] = rounded * price
+	rec.Scores["min_size_risk"] = risk
+	rec.Thresholds = map[string]float64{
+		"min_qty":      minQty,
+		"min_notional": rules.MinNotional,
+		"max_risk":     maxRisk,
+	}
+	rec.Detail = fmt.Sprintf("size %.8g below exchange minimum %.8g; at the minimum the stop risks %.2f of %.2f allowed", rounded, minQty, risk, maxRisk)
+	e.decide(rec)
+	e.auditLogger.Log("TRADE_REJECTED", map[string]interface{}{
+		"symbol":       symbol,
+		"action":       signal.Action,
+		"size":         rounded,
+		"min_quantity": minQty,
+		"min_notional": rules.MinNotional,
+		"risk":         risk,
+		"max_risk":     maxRisk,
+		"reason":       "size below exchange minimum",
+	})
+	return 0, false
+}
+
+// expectedValue returns the signal's EV in basis points of entry, taking its
+// confidence as the win probability
+func (e *TradingEngine) expectedValue(side trade.Side, signal *TradingSignal, stopLoss, takeProfit, spread float64) float64 {
+	return trade.ExpectedValue(side, signal.EntryPrice, stopLoss, takeProfit, signal.Confidence, e.feeRate(signal.Symbol), spread)
+}
+
+// feeRate returns the fee per side of a round trip on symbol
+func (e *TradingEngine) feeRate(symbol string) float64 {
+	if e.fee != nil {
+		return e.fee(symbol)
+	}
+	return e.cfg.Trading.GetTakerFeeRate()
+}
+
+// checkExpectedValue rejects the entry when its expected value after fees
+// and half the live spread, or the cost model's forecast when it is on, is
+// below trading.min_expected_value_bps
+func (e *TradingEngine) checkExpectedValue(symbol string, side trade.Side, signal *TradingSignal, quantity, stopLoss, takeProfit, bid, ask float64) bool {
+	spread := 0.0
+	if bid > 0 && ask > bid {
+		spread = ask - bid
+	}
+	if e.costs != nil {
+		signal.Cost = e.costs.Forecast(symbol, side, signal.EntryPrice, quantity, bid, ask)
+		signal.ExpectedValue = trade.ExpectedValueNet(side, signal.EntryPrice, stopLoss, takeProfit, signal.Confidence, signal.Cost.TotalBps)
+	} else {
+		signal.ExpectedValue = e.expectedValue(side, signal, stopLoss, takeProfit, spread)
+	}
+
+	minEV := e.cfg.Trading.MinExpectedValueBps
+	if signal.ExpectedValue >= minEV {
+		return true
+	}
+
+	rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonExpectedValue)
+	rec.Scores["spread"] = spread
+	rec.Thresholds = map[string]float64{"min_ev_bps": minEV}
+	e.decide(rec)
+	e.auditLogger.Log("TRADE_REJECTED", map[string]interface{}{
+		"symbol":      symbol,
+		"action":      signal.Action,
+		"confidence":  signal.Confidence,
+		"ev_bps":      signal.ExpectedValue,
+		"min_ev_bps":  minEV,
+		"spread":      spread,
+		"stop_loss":   stopLoss,
+		"take_profit": takeProfit,
+		"reason":      "expected value below minimum",
+	})
+	return false
+}
+
+// submitOrder sends an entry through the order queue, which caps concurrent
+// submissions, lets risk-reducing orders go first and drops the entry if its
+// signal goes stale while waiting. Precision rejections are retried once.
+func (e *TradingEngine) submitOrder(ctx context.Context, order *trade.Order, signalAt time.Time, intentID string) error {
+	send := func(ctx context.Context) error {
+		return e.sendEntry(ctx, order, intentID)
+	}
+	if e.orders == nil {
+		return send(ctx)
+	}
+
+	e.advanceIntent(intentID, intent.Queued, "")
+	return e.orders.Submit(ctx, orderqueue.Request{
+		Symbol:   order.Symbol,
+		Priority: orderqueue.PriorityEntry,
+		SignalAt: signalAt,
+		Submit:   send,
+	})
+}
+
+// sendEntry places the entry, chased or as a market order
+func (e *TradingEngine) sendEntry(ctx context.Context, order *trade.Order, intentID string) error {
+	e.advanceIntent(intentID, intent.Submitted, "")
+	if e.chaser != nil {
+		return e.chaseEntry(ctx, order)
+	}
+	if e.timeInForce != nil {
+		return e.tifEntry(ctx, order)
+	}
+	err := e.createOrder(ctx, order)
+	if binance.IsPrecisionError(err) {
+		err = e.retryWithPrecision(ctx, order, err)
+	}
+	return err
+}
+
+// submitAsync queues the entry without waiting for it. done runs once the
+// order acknowledgement tracker settles it, from the account stream, the
+// REST response or a lookup at the timeout, with order updated to what
+// filled. It runs on its own goroutine so the alerts and journaling of
+// the entry never hold up the account stream that settled it.
+func (e *TradingEngine) submitAsync(ctx context.Context, order *trade.Order, signalAt time.Time, intentID string, done func(error)) {
+	id := binance.NewClientOrderID()
+	order.ClientOrderID = id
+
+	e.mu.Lock()
+	e.inFlight++
+	e.mu.Unlock()
+
+	// The request is sent from a copy so the REST response and the stream
+	// event settling the order never write to it at the same time.
+	var mu sync.Mutex
+	sent := *order
+	e.acks.Expect(id, order.Symbol, func(o orderack.Outcome) {
+		go func() {
+			if o.Err == nil {
+				mu.Lock()
+				order.StopLoss, order.TakeProfit = sent.StopLoss, sent.TakeProfit
+				mu.Unlock()
+				order.ID, order.Status = o.OrderID, o.Status
+				order.Quantity, order.FilledQty, order.AvgFillPrice = o.Filled, o.Filled, o.AvgPrice
+			}
+			done(o.Err)
+
+			// Counted in flight until done has counted a filled entry
+			// towards the day, so the daily limit never sees it twice
+			// or not at all.
+			e.mu.Lock()
+			e.inFlight--
+			e.mu.Unlock()
+		}()
+	})
+
+	send := func(ctx context.Context) error {
+		mu.Lock()
+		s := sent
+		mu.Unlock()
+		err := e.sendEntry(ctx, &s, intentID)
+		mu.Lock()
+		sent.StopLoss, sent.TakeProfit = s.StopLoss, s.TakeProfit
+		mu.Unlock()
+		if err != nil && e.neverPlaced(err) {
+			e.acks.Rejected(id, err)
+		} else {
+			e.acks.Submitted(id, &s, err)
+		}
+		return err
+	}
+	if e.orders == nil {
+		go send(ctx)
+		return
+	}
+
+	e.advanceIntent(intentID, intent.Queued, "")
+	queued, err := e.orders.Enqueue(orderqueue.Request{
+		Symbol:   order.Symbol,
+		Priority: orderqueue.PriorityEntry,
+		SignalAt: signalAt,
+		Submit:   send,
+	})
+	if err != nil {
+		e.acks.Rejected(id, err)
+		return
+	}
+	go func() {
+		// Entries the queue drops are never sent; settle them here.
+		// Sent orders are already settled or pending and this is a no-op.
+		if err := <-queued; err != nil {
+			e.acks.Rejected(id, err)
+		}
+	}()
+}
+
+// neverPlaced reports whether an entry's send error means no order under
+// its client order ID can be on the book: the exchange turned it down, or
+// the chase or time-in-force executor sent the orders under IDs of their
+// own. Those executors settle their orders before returning, except on an
+// unresolved order, which fails the entry with an alert for the operator
+// rather than a lookup under an ID it never used. Any other error leaves
+// the order to be looked up by the order acknowledgement tracker.
+func (e *TradingEngine) neverPlaced(err error) bool {
+	return binance.IsRejection(err) || e.chaser != nil || e.timeInForce != nil
+}
+
+// tradeCount returns the entries made today. It is written from the
+// goroutines settling async entries, so it is only read under e.mu.
+func (e *TradingEngine) tradeCount() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.tradesToday
+}
+
+func (e *TradingEngine) entriesInFlight() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.inFlight
+}
+
+// chaseEntry works the entry as a chased limit and shrinks order to what
+// filled. A chase that fills nothing drops the signal with ErrChaseAbandoned.
+func (e *TradingEngine) chaseEntry(ctx context.Context, order *trade.Order) error {
+	res, err := e.chaser.Execute(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	e.auditLogger.Log("ENTRY_CHASE", map[string]interface{}{
+		"symbol":          order.Symbol,
+		"side":            order.Side,
+		"requested":       order.Quantity,
+		"filled":          res.Filled,
+		"avg_price":       res.AvgPrice,
+		"arrival_price":   res.ArrivalPrice,
+		"improvement_bps": res.ImprovementBps,
+		"repegs":          res.Repegs,
+		"abandoned":       res.Abandoned,
+		"remainder":       res.Remainder,
+		"remainder_fill":  res.RemainderFilled,
+		"duration_ms":     res.Duration.Milliseconds(),
+	})
+	if res.Filled == 0 {
+		return ErrChaseAbandoned
+	}
+
+	order.Quantity = res.Filled
+	order.FilledQty = res.Filled
+	order.AvgFillPrice = res.AvgPrice
+	order.Status = trade.OrderStatusFilled
+	return nil
+}
+
+// tifEntry enters with the configured time in force and shrinks order to
+// what filled. An entry that fills nothing drops the signal with
+// tif.ErrUnfilled.
+func (e *TradingEngine) tifEntry(ctx context.Context, order *trade.Order) error {
+	res, err := e.timeInForce.Execute(ctx, order)
+	e.auditLogger.Log("ENTRY_TIF", map[string]interface{}{
+		"symbol":        order.Symbol,
+		"side":          order.Side,
+		"time_in_force": res.TimeInForce,
+		"requested":     order.Quantity,
+		"filled":        res.Filled,
+		"avg_price":     res.AvgPrice,
+		"attempts":      res.Attempts,
+		"duration_ms":   res.Duration.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+
+	order.Quantity = res.Filled
+	order.FilledQty = res.Filled
+	order.AvgFillPrice = res.AvgPrice
+	order.Status = trade.OrderStatusFilled
+	return nil
+}
+
+func (e *TradingEngine) roundPrice(symbol string, price float64) float64 {
+	if e.rules != nil {
+		if rules, ok := e.rules.Get(symbol); ok {
+			return rules.RoundPrice(price)
+		}
+	}
+	return price
+}
+
+func (e *TradingEngine) roundQuantity(symbol string, qty float64) float64 {
+	if e.rules != nil {
+		if rules, ok := e.rules.Get(symbol); ok {
+			return rules.RoundQuantity(qty)
+		}
+	}
+	return qty
+}
+
+// createOrder places one order under the order call timeout
+func (e *TradingEngine) createOrder(ctx context.Context, order *trade.Order) error {
+	ctx, cancel := e.calls.Context(ctx, callpolicy.Order)
+	defer cancel()
+	_, err := e.binance.CreateOrder(ctx, order)
+	return err
+}
+
+// retryWithPrecision handles a -1111/-4014 rejection by refreshing the
+// symbol's exchange filters, re-rounding quantity and prices to them and
+// resubmitting once. It returns the error of the retry, or the original
+// error when the order cannot be adjusted.
+func (e *TradingEngine) retryWithPrecision(ctx context.Context, order *trade.Order, cause error) error {
+	if e.rules == nil {
+		return cause
+	}
+
+	e.mu.Lock()
+	e.precisionRetries++
+	e.mu.Unlock()
+
+	rulesCtx, cancel := e.calls.Context(ctx, callpolicy.Exchange)
+	defer cancel()
+	if err := e.rules.Refresh(rulesCtx); err != nil {
+		log.Printf("Could not refresh symbol rules for %s: %v", order.Symbol, err)
+	}
+	rules, err := e.rules.Ensure(rulesCtx, order.Symbol)
+	if err != nil {
+		return fmt.Errorf("%w (no symbol rules to adjust with: %v)", cause, err)
+	}
+
+	adjusted := *order
+	adjusted.Quantity = rules.RoundQuantity(order.Quantity)
+	adjusted.StopLoss = rules.RoundPrice(order.StopLoss)
+	adjusted.TakeProfit = rules.RoundPrice(order.TakeProfit)
+	if order.Price > 0 {
+		adjusted.Price = rules.RoundPrice(order.Price)
+	}
+	if err := rules.Validate(adjusted.Price, adjusted.Quantity); err != nil {
+		return fmt.Errorf("%w (adjusted order invalid: %v)", cause, err)
+	}
+
+	log.Printf("Order for %s rejected (%v), retrying with quantity %v -> %v, stop %v -> %v",
+		order.Symbol, cause, order.Quantity, adjusted.Quantity, order.StopLoss, adjusted.StopLoss)
+	if err := e.createOrder(ctx, &adjusted); err != nil {
+		e.auditLogger.Log("PRECISION_RETRY_FAILED", map[string]interface{}{
+			"symbol": order.Symbol,
+			"cause":  cause.Error(),
+			"error":  err.Error(),
+		})
+		return fmt.Errorf("retry with adjusted precision failed: %w", err)
+	}
+
+	e.mu.Lock()
+	e.precisionRecovers++
+	e.mu.Unlock()
+
+	e.auditLogger.Log("PRECISION_RETRY_OK", map[string]interface{}{
+		"symbol":   order.Symbol,
+		"cause":    cause.Error(),
+		"quantity": adjusted.Quantity,
+	})
+	*order = adjusted
+	return nil
+}
+
+// confidentEnough applies the risk mode's minimum confidence, the symbol
+// memory and the symbol throttle: symbols the bot keeps losing on, or whose
+// recent trades no longer pay their fees, need a stronger signal than the
+// minimum.
+func (e *TradingEngine) confidentEnough(symbol string, signal *TradingSignal) bool {
+	required := e.minConfidence()
+	if e.riskModes != nil && signal.Confidence < required {
+		// The analyzer only held the signal to the lowest profile's floor.
+		rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonLowConfidence)
+		rec.Thresholds = map[string]float64{"min_confidence": required}
+		e.decide(rec)
+		return false
+	}
+	if e.memory == nil && e.throttle == nil {
+		return true
+	}
+
+	if e.memory != nil {
+		required = e.memory.RequiredConfidence(symbol, required)
+	}
+	reason, why, scores := decisionlog.ReasonSymbolMemory, "symbol memory", map[string]float64{}
+	if e.throttle != nil {
+		if raised := e.throttle.RequiredConfidence(symbol, required); raised > required && signal.Confidence < raised {
+			required, reason, why = raised, decisionlog.ReasonSymbolThrottle, "symbol throttle"
+			scores["expectancy_bps"], _ = e.throttle.Expectancy(symbol)
+		}
+	}
+	if signal.Confidence >= required {
+		return true
+	}
+	if e.memory != nil {
+		scores["memory_score"] = e.memory.Score(symbol)
+	}
+
+	rec := signalDecision(symbol, signal, decisionlog.ActionReject, reason)
+	audit := map[string]interface{}{
+		"symbol":         symbol,
+		"action":         signal.Action,
+		"confidence":     signal.Confidence,
+		"min_confidence": required,
+		"reason":         why,
+	}
+	for k, v := range scores {
+		rec.Scores[k] = v
+		audit[k] = v
+	}
+	rec.Thresholds = map[string]float64{"min_confidence": required}
+	e.decide(rec)
+	e.auditLogger.Log("TRADE_REJECTED", audit)
+	return false
+}
+
+// calculatePositionSize sizes the entry with the position planner, scaled
+// toward the margin target when one is set, and returns the quantity and
+// leverage. The quantity is zero when the signal's levels cannot be sized
+func (e *TradingEngine) calculatePositionSize(side trade.Side, signal *TradingSignal) (float64, int) {
+	planner := e.planner
+	if planner == nil {
+		planner = trade.NewPositionPlanner(app.SizingLimits(e.cfg))
+	}
+	req := trade.PlanRequest{
+		Side:       side,
+		Entry:      signal.EntryPrice,
+		StopLoss:   signal.StopLoss,
+		TakeProfit: signal.TakeProfit,
+		Equity:     e.stateManager.GetStats().Capital,
+	}
+	if e.margin != nil {
+		req.Multiplier = e.margin.Scale()
+	}
+	plan, err := planner.Plan(req)
+	if err != nil {
+		log.Printf("Cannot size %s %s: %v", signal.Action, signal.Symbol, err)
+		return 0, 0
+	}
+	return plan.Quantity, plan.Leverage
+}
+
+func (e *TradingEngine) canTradeSymbol(symbol string) bool {
+	return e.symbolBlock(symbol) == ""
+}
+
+// symbolBlock returns the reason code that keeps symbol from being traded
+// right now, or "" if it may be
+func (e *TradingEngine) symbolBlock(symbol string) string {
+	stats := e.stateManager.GetStats()
+	if stats.IsHalted {
+		return decisionlog.ReasonHalted
+	}
+	if e.entriesPaused() {
+		return decisionlog.ReasonAccountPaused
+	}
+	if _, rejected := e.keys.Blocked(); rejected {
+		return decisionlog.ReasonAPIKey
+	}
+	if reason := e.closedReason(symbol); reason != "" {
+		return reason
+	}
+	if e.stateManager.IsSuspended(symbol) {
+		return decisionlog.ReasonSuspended
+	}
+	if e.liquidations != nil && e.liquidations.CascadeRisk(symbol) {
+		return decisionlog.ReasonLiquidations
+	}
+
+	e.mu.RLock()
+	cooldown, ok := e.symbolCooldown[symbol]
+	e.mu.RUnlock()
+	if ok && time.Since(cooldown) < e.cfg.Trading.GetSymbolCooldown() {
+		return decisionlog.ReasonCooldown
+	}
+
+	return ""
+}
+
+// tightenStops moves the protective stops of open positions caught in a
+// liquidation cascade, every position for a market-wide one, to
+// risk.cascade_stop_factor of their distance from price. Stops are only
+// moved while the engine runs, so a standby leaves the account alone.
+func (e *TradingEngine) tightenStops(s liquidation.Stats) {
+	e.mu.RLock()
+	running := e.running
+	e.mu.RUnlock()
+	if !running {
+		return
+	}
+
+	factor := e.cfg.Risk.CascadeStopFactor
+	for _, pos := range e.stateManager.Positions() {
+		if s.Symbol != "" && pos.Symbol != s.Symbol {
+			continue
+		}
+		protected, ok := e.protection.Protected(pos.Symbol)
+		if !ok || protected.Stop <= 0 {
+			continue
+		}
+
+		ctx, cancel := e.calls.Context(context.Background(), callpolicy.Exchange)
+		price, err := e.binance.Price(ctx, pos.Symbol)
+		cancel()
+		if err != nil {
+			log.Printf("⚠️ Cannot tighten the %s stop for the liquidation cascade: %v", pos.Symbol, err)
+			continue
+		}
+		stop := liquidation.TightenStop(protected.Side == trade.SideBuy, price, protected.Stop, factor)
+		stop = e.roundPrice(pos.Symbol, stop)
+
+		ctx, cancel = e.calls.Context(context.Background(), callpolicy.Order)
+		moved, err := e.protection.TightenStop(ctx, pos.Symbol, stop)
+		cancel()
+		if err != nil {
+			log.Printf("⚠️ Tightened %s stop not placed: %v", pos.Symbol, err)
+		}
+		if !moved {
+			continue
+		}
+		log.Printf("🌊 Liquidation cascade: %s stop moved from %.8g to %.8g (price %.8g)", pos.Symbol, protected.Stop, stop, price)
+		e.auditLogger.Log("CASCADE_STOP_TIGHTENED", map[string]interface{}{
+			"symbol":   pos.Symbol,
+			"price":    price,
+			"old_stop": protected.Stop,
+			"new_stop": stop,
+		})
+	}
+}
+
+// lockSymbol takes the symbol's position lock, so entries from the trading
+// loop, the webhook and the leader stream never act on one symbol at once. A
+// signal that waited while another one entered the symbol is rejected.
+func (e *TradingEngine) lockSymbol(ctx context.Context, symbol string, signal *TradingSignal) (func(), bool) {
+	waitFrom := time.Now()
+	unlock, err := e.positions.Lock(ctx, symbol)
+	if err != nil {
+		rec := signalDecision(symbol, signal, decisionlog.ActionDrop, decisionlog.ReasonOrderCancelled)
+		rec.Detail = "waiting for position lock: " + err.Error()
+		e.decide(rec)
+		return nil, false
+	}
+
+	e.mu.RLock()
+	entered := e.symbolCooldown[symbol]
+	e.mu.RUnlock()
+	if entered.After(waitFrom) {
+		unlock()
+		rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonCooldown)
+		rec.Detail = "entered by another signal while this one waited"
+		e.decide(rec)
+		return nil, false
+	}
+	return unlock, true
+}
+
+func (e *TradingEngine) shouldTrade() bool {
+	reason := e.tradingBlock()
+	if reason == decisionlog.ReasonDailyLossLimit {
+		e.telegram.SendTemplate(alerting.AlertRiskBreach, alerting.MsgDailyLossLimit, nil)
+	}
+	return reason == ""
+}
+
+// tradingBlock returns the reason code that stops all trading right now, or
+// "" if trading may go ahead
+func (e *TradingEngine) tradingBlock() string {
+	stats := e.stateManager.GetStats()
+
+	if stats.IsHalted {
+		return decisionlog.ReasonHalted
+	}
+
+	if e.entriesPaused() {
+		return decisionlog.ReasonAccountPaused
+	}
+
+	if _, rejected := e.keys.Blocked(); rejected {
+		return decisionlog.ReasonAPIKey
+	}
+
+	if reason := e.closedReason(""); reason != "" {
+		return reason
+	}
+
+	if e.tradeCount() >= e.maxTradesPerDay() {
+		return decisionlog.ReasonMaxTradesPerDay
+	}
+
+	if e.dailyPnL < -e.cfg.Trading.DailyTradeLimit {
+		return decisionlog.ReasonDailyLossLimit
+	}
+
+	if e.profitLock != nil && e.profitLock.Locked() {
+		return decisionlog.ReasonProfitLock
+	}
+
+	return ""
+}
+
+// skipWatchlist records a skip for every watchlist symbol when the whole
+// cycle is blocked
+func (e *TradingEngine) skipWatchlist(reason string) {
+	if reason == "" {
+		return
+	}
+	var thresholds map[string]float64
+	switch reason {
+	case decisionlog.ReasonMaxTradesPerDay:
+		thresholds = map[string]float64{"max_trades_per_day": float64(e.maxTradesPerDay())}
+	case decisionlog.ReasonDailyLossLimit:
+		thresholds = map[string]float64{"daily_loss_limit": e.cfg.Trading.DailyTradeLimit}
+	case decisionlog.ReasonProfitLock:
+		thresholds = map[string]float64{"profit_lock_floor": e.profitLock.Status().Floor}
+	}
+	for _, symbol := range e.cfg.Watchlist.Symbols {
+		e.decide(decisionlog.Record{
+			Symbol:     symbol,
+			Action:     decisionlog.ActionSkip,
+			Reasons:    []string{reason},
+			Scores:     map[string]float64{"trades_today": float64(e.tradeCount()), "daily_pnl": e.dailyPnL},
+			Thresholds: thresholds,
+			Source:     SourceAnalysis,
+		})
+	}
+}
+
+// entriesPaused reports whether the account guard saw activity the bot did
+// not cause and has not been resumed since
+func (e *TradingEngine) entriesPaused() bool {
+	if e.guard == nil {
+		return false
+	}
+	_, paused := e.guard.Paused()
+	return paused
+}
+
+// minConfidence returns the active risk mode's minimum confidence, or the
+// configured one
+func (e *TradingEngine) minConfidence() float64 {
+	if e.riskModes != nil {
+		if p := e.riskModes.Current(); p.MinConfidence > 0 {
+			return p.MinConfidence
+		}
+	}
+	return e.cfg.Trading.MinConfidence
+}
+
+// maxTradesPerDay returns the active risk mode's daily trade limit, or the
+// configured one
+func (e *TradingEngine) maxTradesPerDay() int {
+	if e.riskModes != nil {
+		if p := e.riskModes.Current(); p.MaxTradesPerDay > 0 {
+			return p.MaxTradesPerDay
+		}
+	}
+	return e.cfg.Trading.MaxTradesPerDay
+}
+
+// closedReason reports whether the trading calendar closes entries on
+// symbol, or on the whole exchange when symbol is empty
+func (e *TradingEngine) closedReason(symbol string) string {
+	if e.calendar == nil {
+		return ""
+	}
+	closure, closed := e.calendar.Closed(symbol)
+	switch {
+	case !closed:
+		return ""
+	case closure.Halted:
+		return decisionlog.ReasonSymbolHalted
+	default:
+		return decisionlog.ReasonMaintenance
+	}
+}
+
+func (e *TradingEngine) checkKillSwitch() {
+	killFile := "/tmp/gobot_kill_switch"
+	if _, err := os.Stat(killFile); err == nil {
+		e.stateManager.Halt("Kill switch activated")
+		e.telegram.SendKillSwitch()
+		log.Println("Kill switch file detected - trading halted")
+	}
+}
+
+// HealthCheck reports engine and account state for the health endpoint
+func (e *TradingEngine) HealthCheck() map[string]interface{} {
+	stats := e.stateManager.GetStats()
+
+	e.mu.RLock()
+	retries, recovered := e.precisionRetries, e.precisionRecovers
+	watched := e.watched
+	e.mu.RUnlock()
+
+	var queue orderqueue.Stats
+	if e.orders != nil {
+		queue = e.orders.Stats()
+	}
+
+	phases := map[string]interface{}{}
+	aborted := 0
+	if e.cycle != nil {
+		var timings map[string]PhaseStats
+		timings, aborted = e.cycle.snapshot()
+		for phase, s := range timings {
+			phases[phase] = map[string]interface{}{
+				"budget_ms": s.Budget.Milliseconds(),
+				"last_ms":   s.Last.Milliseconds(),
+				"max_ms":    s.Max.Milliseconds(),
+				"overruns":  s.Overruns,
+			}
+		}
+	}
+
+	health := map[string]interface{}{
+		"running":      e.running,
+		"namespace":    e.cfg.Namespace,
+		"capital":      stats.Capital,
+		"total_trades": stats.TotalTrades,
+		"win_rate":     stats.WinRate,
+		"total_pnl":    stats.TotalPnL,
+		"daily_pnl":    stats.DailyPnL,
+		"trades_today": e.tradeCount(),
+		"is_halted":    stats.IsHalted,
+		"watch_only":   e.cfg.Execution.WatchOnly,
+		"watched":      watched,
+
+		"precision_retries":        retries,
+		"precision_retries_ok":     recovered,
+		"precision_retries_failed": retries - recovered,
+
+		"order_queue_depth":     queue.Queued,
+		"orders_in_flight":      queue.InFlight,
+		"orders_dropped_stale":  queue.Stale,
+		"orders_cancelled":      queue.Cancelled,
+		"orders_rejected_queue": queue.Rejected,
+
+		"cycle_phases":     phases,
+		"cycles_aborted":   aborted,
+		"analysis_workers": e.cfg.Performance.GetAnalysisWorkers(),
+	}
+
+	if e.chaser != nil {
+		chased := e.chaser.Stats()
+		health["chase"] = map[string]interface{}{
+			"chases":              chased.Chases,
+			"filled":              chased.Filled,
+			"partial":             chased.Partial,
+			"abandoned":           chased.Abandoned,
+			"repegs":              chased.Repegs,
+			"market_remainders":   chased.MarketRemainders,
+			"chased_remainders":   chased.ChasedRemainders,
+			"avg_improvement_bps": chased.AvgImprovement,
+			"improvement_usd":     chased.ImprovementUSD,
+		}
+	}
+	if e.entryLimits != nil {
+		health["entry_limits"] = e.entryLimits.Status()
+	}
+	if e.timeInForce != nil {
+		entered := e.timeInForce.Stats()
+		health["time_in_force"] = map[string]interface{}{
+			"time_in_force": entered.TimeInForce,
+			"entries":       entered.Entries,
+			"filled":        entered.Filled,
+			"partial":       entered.Partial,
+			"unfilled":      entered.Unfilled,
+			"attempts":      entered.Attempts,
+			"retries":       entered.Retries,
+			"expired":       entered.Expired,
+			"rejected":      entered.Rejected,
+		}
+	}
+	if e.guard != nil {
+		guarded := e.guard.Stats()
+		health["account_guard"] = map[string]interface{}{
+			"paused":       guarded.Paused,
+			"pause_reason": guarded.PauseReason,
+			"anomalies":    guarded.Anomalies,
+		}
+	}
+	if e.calendar != nil {
+		cal := e.calendar.Stats()
+		closure, closed := e.calendar.Closed("")
+		calendarHealth := map[string]interface{}{
+			"closed":         closed,
+			"reason":         closure.Reason,
+			"halted_symbols": cal.Halted,
+			"poll_failures":  cal.Failures,
+		}
+		if cal.NextWindow != nil {
+			calendarHealth["next_window"] = cal.NextWindow.Start
+		}
+		health["calendar"] = calendarHealth
+	}
+	if e.keys != nil {
+		health["key_health"] = e.keys.Status()
+	}
+	if e.drift != nil {
+		health["drift"] = e.drift.Status()
+	}
+	if e.protection != nil {
+		health["protection"] = e.protection.Stats()
+	}
+	if e.liquidations != nil {
+		health["liquidations"] = e.liquidations.MarketStats()
+	}
+	if e.fees != nil {
+		health["fees"] = e.fees.Status()
+	}
+	if e.ideas != nil {
+		health["ideas"] = e.ideas.Stats()
+	}
+	if e.fills != nil {
+		fills := e.fills.Stats()
+		health["fill_check"] = map[string]interface{}{
+			"pending":       fills.Pending,
+			"confirmed":     fills.Confirmed,
+			"discrepancies": fills.Discrepancies,
+			"last_kind":     fills.LastKind,
+		}
+	}
+	if e.costs != nil {
+		costs := e.costs.Stats()
+		health["cost_model"] = map[string]interface{}{
+			"forecasts":          costs.Forecasts,
+			"calibrations":       costs.Calibrations,
+			"mean_error_bps":     costs.MeanErrorBps,
+			"mean_abs_error_bps": costs.MeanAbsErrorBps,
+			"buckets":            costs.Buckets,
+		}
+	}
+	if e.throttle != nil {
+		stats := e.throttle.Stats()
+		var held []throttle.SymbolStats
+		for _, s := range e.throttle.Symbols() {
+			if s.Throttled || s.Probation {
+				held = append(held, s)
+			}
+		}
+		health["symbol_throttle"] = map[string]interface{}{
+			"symbols":     stats.Symbols,
+			"throttled":   held,
+			"suspensions": stats.Suspensions,
+			"resumes":     stats.Resumes,
+		}
+	}
+	if e.profitLock != nil {
+		health["profit_lock"] = e.profitLock.Status()
+	}
+	if e.paper != nil {
+		health["shadow_fills"] = e.paper.Report()
+	}
+	if e.acks != nil {
+		acks := e.acks.Stats()
+		health["order_acks"] = map[string]interface{}{
+			"pending":         acks.Pending,
+			"filled":          acks.Filled,
+			"failed":          acks.Failed,
+			"timed_out":       acks.TimedOut,
+			"by_source":       acks.BySource,
+			"mean_latency_ms": acks.MeanLatency.Milliseconds(),
+		}
+	}
+	if e.instance != nil {
+		instance := e.instance.Stats()
+		health["instance_lock"] = map[string]interface{}{
+			"instance": instance.Instance,
+			"held":     instance.Held,
+			"since":    instance.Since,
+			"renewals": instance.Renewals,
+			"conflict": instance.Conflict,
+		}
+	}
+	if e.margin != nil {
+		margin := e.margin.Stats()
+		health["margin_target"] = map[string]interface{}{
+			"usage":     margin.Usage,
+			"scale":     margin.Scale,
+			"committed": margin.Committed,
+			"failures":  margin.Failures,
+		}
+	}
+	if e.depth != nil {
+		books := e.depth.Stats()
+		health["order_books"] = map[string]interface{}{
+			"books":    books.Books,
+			"synced":   books.Synced,
+			"resyncs":  books.Resyncs,
+			"gaps":     books.Gaps,
+			"crossed":  books.Crossed,
+			"failures": books.Failures,
+		}
+	}
+	if e.riskModes != nil {
+		mode := e.riskModes.Stats()
+		health["risk_mode"] = map[string]interface{}{
+			"profile":     mode.Profile,
+			"switches":    mode.Switches,
+			"last_switch": mode.LastSwitch,
+			"last_by":     mode.LastBy,
+		}
+	}
+	if e.weights != nil {
+		spent := e.weights.Stats()
+		components := make(map[string]interface{}, len(spent.Components))
+		for name, u := range spent.Components {
+			components[name] = map[string]interface{}{
+				"used":     u.Used,
+				"quota":    u.Quota,
+				"requests": u.Requests,
+				"rejected": u.Rejected,
+			}
+		}
+		health["api_weight"] = map[string]interface{}{
+			"limit":      spent.Limit,
+			"used":       spent.Used,
+			"reported":   spent.Reported,
+			"components": components,
+		}
+	}
+	if e.equity != nil {
+		stats := e.equity.Stats()
+		eq := map[string]interface{}{
+			"snapshots":  stats.Snapshots,
+			"failures":   stats.Failures,
+			"last_error": stats.LastError,
+		}
+		if snap, ok := e.equity.Latest(); ok {
+			eq["equity"] = snap.Equity
+			eq["unrealized_pnl"] = snap.UnrealizedPnL
+			eq["margin_usage_pct"] = snap.MarginUsage
+			eq["positions"] = len(snap.Positions)
+			eq["last_snapshot"] = snap.Time
+		}
+		health["equity"] = eq
+	}
+	if a, ok := e.analyzer.(interface{ BrainStats() BrainStats }); ok {
+		brain := a.BrainStats()
+		health["brain"] = map[string]interface{}{
+			"calls":        brain.Calls,
+			"timeouts":     brain.Timeouts,
+			"degraded":     brain.Degraded,
+			"last_timeout": brain.LastTimeout,
+		}
+	}
+	if e.binance != nil && e.binance.Endpoints() != nil {
+		router := e.binance.Endpoints()
+		endpoints := make([]map[string]interface{}, 0)
+		for _, s := range router.Status() {
+			endpoints = append(endpoints, map[string]interface{}{
+				"url":        s.URL,
+				"latency_ms": s.Latency.Milliseconds(),
+				"healthy":    s.Healthy,
+				"failures":   s.Failures,
+				"last_error": s.LastError,
+				"checked_at": s.CheckedAt,
+			})
+		}
+		health["endpoints"] = map[string]interface{}{
+			"active":    router.Best(),
+			"switches":  router.Switches(),
+			"endpoints": endpoints,
+		}
+	}
+	if e.books != nil {
+		books := e.books.Stats()
+		health["book_archive"] = map[string]interface{}{
+			"archived":   books.Archived,
+			"failures":   books.Failures,
+			"last_error": books.LastError,
+		}
+	}
+	if e.intents != nil {
+		stats := e.intents.Stats()
+		reached := map[string]int{}
+		for s, n := range stats.Reached {
+			reached[string(s)] = n
+		}
+		health["intents"] = map[string]interface{}{
+			"active":  stats.Active,
+			"reached": reached,
+		}
+	}
+	if e.earn != nil {
+		earn := e.earn.Stats()
+		health["earn"] = map[string]interface{}{
+			"in_earn":     earn.InEarn,
+			"rewards":     earn.Rewards,
+			"swept":       earn.Swept,
+			"recalled":    earn.Recalled,
+			"sweeps":      earn.Sweeps,
+			"recalls":     earn.Recalls,
+			"last_action": earn.LastAction,
+			"last_error":  earn.LastError,
+		}
+	}
+	return health
+}
+
+// Handler serves the health check, the trade signal webhook, the stream of
+// executed signals that followers subscribe to, the dashboard's risk and
+// equity views, the idea inbox and, when enabled, GraphQL queries over the
+// journal
+func (e *TradingEngine) Handler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(e.HealthCheck())
+	})
+	mux.HandleFunc("/webhook/trade_signal", func(w http.ResponseWriter, r *http.Request) {
+		var signal TradingSignal
+		if err := json.NewDecoder(r.Body).Decode(&signal); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		signal.Source = SourceWebhook
+		e.executeTrade(ctx, signal.Symbol, &signal)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/signals/stream", e.serveSignalStream)
+	mux.HandleFunc("/risk/heatmap", e.serveRiskHeatmap)
+	if e.equity != nil {
+		mux.HandleFunc("/equity", e.serveEquityCurve)
+	}
+	if e.suspensions != nil {
+		mux.Handle("/suspensions", e.suspensions)
+	}
+	if e.ideas != nil {
+		mux.Handle("/ideas", e.ideas)
+	}
+	if e.cfg != nil && e.cfg.Monitoring.GraphQLEnabled {
+		mux.Handle("/graphql", e.requireKillSwitchPassword(graphql.Handler(e.graphQLSchema())))
+	}
+	return mux
+}
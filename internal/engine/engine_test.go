@@ -0,0 +1,374 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/config"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/infra/binance"
+	"github.com/britej3/gobot/pkg/alerting"
+	"github.com/britej3/gobot/pkg/apiweight"
+	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/costmodel"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/liquidation"
+	"github.com/britej3/gobot/services/symbolrules"
+)
+
+type staticRules []symbolrules.Rules
+
+func (s staticRules) SymbolRules(ctx context.Context) ([]symbolrules.Rules, error) {
+	return s, nil
+}
+
+func TestRetryWithPrecision_ResubmitsRoundedOrder(t *testing.T) {
+	var quantities []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		quantities = append(quantities, r.PostForm.Get("quantity"))
+		if len(quantities) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1111,"msg":"Precision is over the maximum defined for this asset."}`))
+			return
+		}
+		w.Write([]byte(`{"orderId":42,"symbol":"BTCUSDT","status":"NEW"}`))
+	}))
+	defer server.Close()
+
+	e := &TradingEngine{
+		binance:     binance.NewHardenedClient(binance.HardenedConfig{BaseURL: server.URL}),
+		rules:       symbolrules.New(symbolrules.Config{Path: filepath.Join(t.TempDir(), "rules.json")}, staticRules{{Symbol: "BTCUSDT", TickSize: 0.1, StepSize: 0.001}}),
+		auditLogger: alerting.NewAuditLogger(alerting.AuditConfig{}),
+	}
+
+	order := &trade.Order{Symbol: "BTCUSDT", Side: trade.SideBuy, Type: trade.OrderTypeMarket, Quantity: 0.0123456, StopLoss: 99.987}
+	_, err := e.binance.CreateOrder(context.Background(), order)
+	if !binance.IsPrecisionError(err) {
+		t.Fatalf("expected precision error, got %v", err)
+	}
+
+	if err := e.retryWithPrecision(context.Background(), order, err); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if len(quantities) != 2 || quantities[1] != "0.012" {
+		t.Errorf("expected resubmission with quantity 0.012, got %v", quantities)
+	}
+	if order.StopLoss != 100 {
+		t.Errorf("expected stop loss rounded to 100, got %v", order.StopLoss)
+	}
+	if e.precisionRetries != 1 || e.precisionRecovers != 1 {
+		t.Errorf("expected one attempted and recovered retry, got %d/%d", e.precisionRetries, e.precisionRecovers)
+	}
+}
+
+type slowAnalyzer struct{ delay time.Duration }
+
+func (a slowAnalyzer) Analyze(ctx context.Context, symbol string) (*TradingSignal, error) {
+	select {
+	case <-time.After(a.delay):
+		return &TradingSignal{Action: "LONG", Confidence: 0.9}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestTradingCycle_AnalysisOverrunSkipsEntries(t *testing.T) {
+	st, err := state.NewStateManager(state.StateConfig{StateDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.ProductionConfig{}
+	cfg.Watchlist.Symbols = []string{"BTCUSDT", "ETHUSDT"}
+
+	e := &TradingEngine{
+		cfg:            cfg,
+		stateManager:   st,
+		analyzer:       slowAnalyzer{delay: 30 * time.Millisecond},
+		auditLogger:    alerting.NewAuditLogger(alerting.AuditConfig{}),
+		cycle:          newCycleMetrics(map[string]int{PhaseAnalysis: 40}),
+		symbolCooldown: make(map[string]time.Time),
+	}
+
+	// binance is nil, so reaching the entry phase would panic
+	e.executeTradingCycle(context.Background())
+
+	phases, aborted := e.cycle.snapshot()
+	if aborted != 1 || phases[PhaseAnalysis].Overruns != 1 {
+		t.Errorf("expected one aborted cycle with an analysis overrun, got %d aborted, %+v", aborted, phases)
+	}
+	if _, entered := phases[PhaseEntry]; entered {
+		t.Error("entry phase should not run after an analysis overrun")
+	}
+}
+
+// quotaAnalyzer is refused by the weight budget from the third symbol on.
+type quotaAnalyzer struct {
+	mu       sync.Mutex
+	analyzed []string
+}
+
+func (a *quotaAnalyzer) Analyze(ctx context.Context, symbol string) (*TradingSignal, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.analyzed = append(a.analyzed, symbol)
+	if len(a.analyzed) > 2 {
+		return nil, fmt.Errorf("klines: %w", apiweight.ErrQuotaExceeded)
+	}
+	return &TradingSignal{Action: "LONG", Confidence: 0.9}, nil
+}
+
+func TestAnalyzeAll_RunsSymbolsInParallel(t *testing.T) {
+	e := &TradingEngine{analyzer: slowAnalyzer{delay: 50 * time.Millisecond}}
+	symbols := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "BNBUSDT"}
+
+	start := time.Now()
+	results, starved := e.analyzeAll(context.Background(), symbols, 4)
+	if took := time.Since(start); took > 150*time.Millisecond {
+		t.Errorf("four 50ms analyses on four workers took %s", took)
+	}
+	for i, r := range results {
+		if !r.ran || r.signal == nil || starved {
+			t.Errorf("%s: %+v", symbols[i], r)
+		}
+	}
+}
+
+func TestAnalyzeAll_StopsWhenTheWeightBudgetRefuses(t *testing.T) {
+	a := &quotaAnalyzer{}
+	e := &TradingEngine{analyzer: a}
+	symbols := []string{"A", "B", "C", "D", "E", "F"}
+
+	results, starved := e.analyzeAll(context.Background(), symbols, 1)
+	if !starved || len(a.analyzed) != 3 {
+		t.Fatalf("starved %v after %v, want a stop after the refused third symbol", starved, a.analyzed)
+	}
+	if results[3].ran || results[5].ran {
+		t.Error("symbols after the refusal were analyzed")
+	}
+}
+
+func TestCheckQuoteDrift_RejectsMoveAlreadyMade(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"symbol":"BTCUSDT","bidPrice":"100.9","askPrice":"101.0"}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.ProductionConfig{}
+	cfg.Execution.MaxEntryDrift = 0.3
+	e := &TradingEngine{
+		cfg:         cfg,
+		binance:     binance.NewHardenedClient(binance.HardenedConfig{BaseURL: server.URL}),
+		auditLogger: alerting.NewAuditLogger(alerting.AuditConfig{}),
+	}
+
+	// Half of the way to take profit is gone for the long, but a short on
+	// the same book is still at its entry.
+	long := &TradingSignal{Symbol: "BTCUSDT", Action: "LONG", EntryPrice: 100, TakeProfit: 102}
+	bid, ask := e.liveQuote(context.Background(), "BTCUSDT")
+	if e.checkQuoteDrift("BTCUSDT", trade.SideBuy, long, long.TakeProfit, bid, ask) {
+		t.Error("long entry should be rejected after a 50% drift toward take profit")
+	}
+	short := &TradingSignal{Symbol: "BTCUSDT", Action: "SHORT", EntryPrice: 100.9, TakeProfit: 99}
+	if !e.checkQuoteDrift("BTCUSDT", trade.SideSell, short, short.TakeProfit, bid, ask) {
+		t.Error("short entry at the current bid should pass")
+	}
+}
+
+func TestCheckExpectedValue_CountsFeesAndSpread(t *testing.T) {
+	cfg := &config.ProductionConfig{}
+	cfg.Trading.TakerFeeRate = 0.0005
+	e := &TradingEngine{cfg: cfg, auditLogger: alerting.NewAuditLogger(alerting.AuditConfig{})}
+
+	// 2% target, 1% stop at 40% odds: 0.4*200 - 0.6*100 = +20 bps before
+	// costs, which 10 bps of fees and 15 bps of half spread turn negative.
+	signal := &TradingSignal{Symbol: "BTCUSDT", Action: "LONG", Confidence: 0.4, EntryPrice: 100}
+	if !e.checkExpectedValue("BTCUSDT", trade.SideBuy, signal, 1, 99, 102, 0, 0) {
+		t.Errorf("expected positive EV without spread, got %.1f bps", signal.ExpectedValue)
+	}
+	if e.checkExpectedValue("BTCUSDT", trade.SideBuy, signal, 1, 99, 102, 99.85, 100.15) {
+		t.Errorf("expected the spread to make EV negative, got %.1f bps", signal.ExpectedValue)
+	}
+	if math.Abs(signal.ExpectedValue-(-5)) > 1e-9 {
+		t.Errorf("EV = %.4f bps, want -5", signal.ExpectedValue)
+	}
+
+	// The cost model crosses to the ask like the half spread does, until
+	// this symbol and size have filled 30 bps worse on average.
+	e.costs = costmodel.New(costmodel.Config{FeeRate: 0.0005}, nil)
+	e.checkExpectedValue("BTCUSDT", trade.SideBuy, signal, 1, 99, 102, 99.85, 100.15)
+	if math.Abs(signal.ExpectedValue-(-5)) > 1e-9 || math.Abs(signal.Cost.TotalBps-25) > 1e-9 {
+		t.Errorf("EV = %.4f bps with cost %+v, want -5", signal.ExpectedValue, signal.Cost)
+	}
+	for i := 0; i < 3; i++ {
+		e.costs.Observe("BTCUSDT", 100, 30)
+	}
+	e.checkExpectedValue("BTCUSDT", trade.SideBuy, signal, 1, 99, 102, 99.85, 100.15)
+	if math.Abs(signal.ExpectedValue-(-20)) > 1e-9 {
+		t.Errorf("EV = %.4f bps with history, want -20", signal.ExpectedValue)
+	}
+}
+
+func TestFitExchangeMinimum_BumpsOnlyWhenRiskAllows(t *testing.T) {
+	st, err := state.NewStateManager(state.StateConfig{StateDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.UpdateCapital(-74) // a 26 USDT account
+
+	cfg := &config.ProductionConfig{}
+	cfg.Trading.MinNotionalMaxRisk = 0.03
+	e := &TradingEngine{
+		cfg:          cfg,
+		stateManager: st,
+		rules:        symbolrules.New(symbolrules.Config{Path: filepath.Join(t.TempDir(), "rules.json")}, staticRules{{Symbol: "ETHUSDT", TickSize: 0.01, StepSize: 0.001, MinQty: 0.001, MinNotional: 20}}),
+		auditLogger:  alerting.NewAuditLogger(alerting.AuditConfig{}),
+	}
+	if _, err := e.rules.Ensure(context.Background(), "ETHUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	signal := &TradingSignal{Symbol: "ETHUSDT", Action: "LONG", EntryPrice: 2000}
+
+	// At the 0.01 minimum a 1% stop risks 0.20, within 3% of 26.
+	if size, ok := e.fitExchangeMinimum("ETHUSDT", signal, 0.0042, 1980); !ok || math.Abs(size-0.01) > 1e-12 {
+		t.Errorf("expected a bump to 0.01, got %v %v", size, ok)
+	}
+	// A 5% stop would risk 1.00 at the minimum, too much.
+	if size, ok := e.fitExchangeMinimum("ETHUSDT", signal, 0.0042, 1900); ok {
+		t.Errorf("expected a skip, got size %v", size)
+	}
+	// Sizes above the minimum are only rounded.
+	if size, ok := e.fitExchangeMinimum("ETHUSDT", signal, 0.0123, 1900); !ok || math.Abs(size-0.012) > 1e-12 {
+		t.Errorf("expected 0.012, got %v %v", size, ok)
+	}
+}
+
+type slowBrain struct{ delay time.Duration }
+
+func (b slowBrain) MakeTradingDecision(ctx context.Context, signal interface{}) (*brain.TradingDecision, error) {
+	select {
+	case <-time.After(b.delay):
+		return &brain.TradingDecision{Decision: "BUY", Confidence: 0.9}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestConfirm_DegradesForRestOfCycleOnTimeout(t *testing.T) {
+	a := NewPipelineAnalyzer(AnalyzerConfig{DecisionBudget: 10 * time.Millisecond}, nil, slowBrain{delay: time.Second})
+	signal := map[string]interface{}{"symbol": "BTCUSDT"}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if decision, err := a.confirm(context.Background(), signal); decision != nil || err != nil {
+			t.Fatalf("expected a rule-based fallback, got %v %v", decision, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("later setups should not wait on the brain, took %v", elapsed)
+	}
+	if s := a.BrainStats(); s.Calls != 1 || s.Timeouts != 1 || s.Degraded != 3 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	a.BeginCycle()
+	a.confirm(context.Background(), signal)
+	if s := a.BrainStats(); s.Calls != 2 {
+		t.Errorf("expected the brain to be asked again next cycle, got %+v", s)
+	}
+}
+
+func TestExecuteTrade_WatchOnlySendsNothing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fapi/v1/order" {
+			t.Errorf("watch-only mode sent an order")
+		}
+		w.Write([]byte(`{"symbol":"BTCUSDT","bidPrice":"99.9","askPrice":"100.0"}`))
+	}))
+	defer server.Close()
+
+	st, err := state.NewStateManager(state.StateConfig{StateDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.ProductionConfig{}
+	cfg.Execution.WatchOnly = true
+	cfg.Trading.MaxTradesPerDay = 5
+	cfg.Trading.MaxPositionUSD = 1000
+	cfg.Trading.MaxRiskPerTrade = 0.01
+	e := &TradingEngine{
+		cfg:            cfg,
+		stateManager:   st,
+		binance:        binance.NewHardenedClient(binance.HardenedConfig{BaseURL: server.URL, ReadOnly: true}),
+		auditLogger:    alerting.NewAuditLogger(alerting.AuditConfig{}),
+		telegram:       alerting.NewTelegramAlert(alerting.TelegramConfig{}),
+		symbolCooldown: make(map[string]time.Time),
+	}
+
+	signal := &TradingSignal{Action: "LONG", Confidence: 0.9, EntryPrice: 100, StopLoss: 99, TakeProfit: 103, Timestamp: time.Now()}
+	if e.executeTrade(context.Background(), "BTCUSDT", signal) {
+		t.Fatal("watch-only entries must not report a trade")
+	}
+	if e.watched != 1 || e.tradesToday != 1 {
+		t.Errorf("expected the entry to be watched and counted, got watched=%d trades=%d", e.watched, e.tradesToday)
+	}
+	if len(st.CurrentPositions) != 0 {
+		t.Errorf("watch-only mode opened a position")
+	}
+	if _, err := e.binance.CreateOrder(context.Background(), &trade.Order{Symbol: "BTCUSDT"}); !errors.Is(err, binance.ErrReadOnly) {
+		t.Errorf("read-only client accepted an order: %v", err)
+	}
+}
+
+func TestHandler_GraphQLNeedsTheKillSwitchPassword(t *testing.T) {
+	cfg := &config.ProductionConfig{}
+	cfg.Monitoring.GraphQLEnabled = true
+	cfg.Emergency.KillSwitchPassword = "secret"
+	h := (&TradingEngine{cfg: cfg}).Handler(context.Background())
+
+	for _, tc := range []struct {
+		password string
+		want     int
+	}{
+		{"", http.StatusUnauthorized},
+		{"wrong", http.StatusUnauthorized},
+		{"secret", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/graphql?query={nope}", nil)
+		if tc.password != "" {
+			req.Header.Set("X-Kill-Switch-Password", tc.password)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("password %q: status %d, want %d", tc.password, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestSymbolBlock_PausesEntriesDuringLiquidationCascades(t *testing.T) {
+	st, err := state.NewStateManager(state.StateConfig{StateDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cascades := liquidation.New(liquidation.Config{SymbolThreshold: 1000, MarketThreshold: 1e9})
+	e := &TradingEngine{cfg: &config.ProductionConfig{}, stateManager: st, liquidations: cascades, symbolCooldown: make(map[string]time.Time)}
+
+	cascades.Add(liquidation.Event{Symbol: "ETHUSDT", Side: "SELL", Price: 100, Quantity: 20})
+	if got := e.symbolBlock("ETHUSDT"); got != decisionlog.ReasonLiquidations {
+		t.Errorf("ETHUSDT blocked by %q, want %q", got, decisionlog.ReasonLiquidations)
+	}
+	if got := e.symbolBlock("BTCUSDT"); got != "" {
+		t.Errorf("BTCUSDT blocked by %q during an ETHUSDT cascade", got)
+	}
+}
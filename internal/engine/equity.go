@@ -0,0 +1,34 @@
+package engine
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/britej3/gobot/services/equity"
+)
+
+// serveEquityCurve returns the recorded equity curve with running and
+// maximum drawdown. The since query parameter is a duration such as 24h or
+// 168h and defaults to a week.
+func (e *TradingEngine) serveEquityCurve(w http.ResponseWriter, r *http.Request) {
+	window := 7 * 24 * time.Hour
+	if s := r.URL.Query().Get("since"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			http.Error(w, "since must be a positive duration, e.g. 24h", http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+
+	snaps, err := equity.Read(e.equity.Path(), time.Now().Add(-window))
+	if err != nil {
+		log.Printf("Equity curve unavailable: %v", err)
+		http.Error(w, "equity log unavailable", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(equity.BuildCurve(snaps))
+}
@@ -0,0 +1,308 @@
+package engine
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/britej3/gobot/pkg/graphql"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/decisionlog"
+)
+
+// graphQLSchema exposes the trade journal, open positions, the decision log
+// and the account metrics at /graphql, e.g.
+//
+//	{ tradeStats(groupBy: "symbol", since: "168h") { key trades winRate pnl } }
+func (e *TradingEngine) graphQLSchema() graphql.Schema {
+	return graphql.Schema{
+		"trades": {
+			Args:    []string{"symbol", "side", "status", "since", "until", "limit"},
+			Resolve: e.resolveTrades,
+		},
+		"tradeStats": {
+			Args:    []string{"groupBy", "symbol", "side", "status", "since", "until"},
+			Resolve: e.resolveTradeStats,
+		},
+		"positions": {
+			Args:    []string{"symbol"},
+			Resolve: e.resolvePositions,
+		},
+		"signals": {
+			Args:    []string{"symbol", "action", "reason", "since", "until", "limit"},
+			Resolve: e.resolveSignals,
+		},
+		"metrics": {
+			Resolve: e.resolveMetrics,
+		},
+	}
+}
+
+// requireKillSwitchPassword serves h only to requests carrying the
+// emergency kill switch password as X-Kill-Switch-Password, the same
+// credential the suspension endpoint takes. Without a password configured
+// nothing is served.
+func (e *TradingEngine) requireKillSwitchPassword(h http.Handler) http.Handler {
+	password := []byte(e.cfg.Emergency.KillSwitchPassword)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := []byte(r.Header.Get("X-Kill-Switch-Password"))
+		if len(password) == 0 || subtle.ConstantTimeCompare(given, password) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// journal returns the trades matching the symbol, side, status and entry
+// time arguments, oldest first
+func (e *TradingEngine) journal(args *graphql.Args) []state.Trade {
+	symbols := args.Strings("symbol")
+	side, status := args.String("side"), args.String("status")
+	since, until := args.Time("since"), args.Time("until")
+
+	var out []state.Trade
+	for _, t := range e.stateManager.Trades() {
+		if len(symbols) > 0 && !containsString(symbols, t.Symbol) {
+			continue
+		}
+		if (side != "" && t.Side != side) || (status != "" && t.Status != status) {
+			continue
+		}
+		if (!since.IsZero() && t.EntryTime.Before(since)) || (!until.IsZero() && !t.EntryTime.Before(until)) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func (e *TradingEngine) resolveTrades(args *graphql.Args) (interface{}, error) {
+	trades := e.journal(args)
+	if limit := args.Int("limit"); limit > 0 && len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+
+	out := make([]graphql.Object, 0, len(trades))
+	for _, t := range trades {
+		obj := graphql.Object{
+			"symbol":      t.Symbol,
+			"side":        t.Side,
+			"size":        t.Size,
+			"entryPrice":  t.EntryPrice,
+			"fillPrice":   t.FillPrice,
+			"exitPrice":   t.ExitPrice,
+			"pnl":         t.PnL,
+			"pnlPercent":  t.PnLPercent,
+			"stopLoss":    t.StopLoss,
+			"takeProfit":  t.TakeProfit,
+			"confidence":  t.Confidence,
+			"reasoning":   t.Reasoning,
+			"entryTime":   t.EntryTime,
+			"exitTime":    t.ExitTime,
+			"status":      t.Status,
+			"components":  t.Components,
+			"namespace":   t.Namespace,
+			"slippageBps": nil,
+		}
+		if bps, ok := t.SlippageBps(); ok {
+			obj["slippageBps"] = bps
+		}
+		out = append(out, obj)
+	}
+	return out, nil
+}
+
+// resolveTradeStats aggregates the matching trades by symbol, side, status,
+// namespace, exit day, entry hour or signal component; without groupBy it is one group
+// keyed "all". A trade counts toward every component it carried.
+func (e *TradingEngine) resolveTradeStats(args *graphql.Args) (interface{}, error) {
+	groupBy := args.String("groupBy")
+	var keys func(state.Trade) []string
+	switch groupBy {
+	case "":
+		keys = func(state.Trade) []string { return []string{"all"} }
+	case "symbol":
+		keys = func(t state.Trade) []string { return []string{t.Symbol} }
+	case "side":
+		keys = func(t state.Trade) []string { return []string{t.Side} }
+	case "status":
+		keys = func(t state.Trade) []string { return []string{t.Status} }
+	case "namespace":
+		keys = func(t state.Trade) []string { return []string{t.Namespace} }
+	case "day":
+		keys = func(t state.Trade) []string { return []string{t.ExitTime.UTC().Format("2006-01-02")} }
+	case "hour":
+		keys = func(t state.Trade) []string { return []string{fmt.Sprintf("%02d", t.EntryTime.UTC().Hour())} }
+	case "component":
+		keys = func(t state.Trade) []string {
+			out := make([]string, 0, len(t.Components))
+			for name := range t.Components {
+				out = append(out, name)
+			}
+			return out
+		}
+	default:
+		return nil, fmt.Errorf("groupBy must be symbol, side, status, namespace, day, hour or component")
+	}
+
+	type group struct {
+		trades, wins, losses int
+		pnl, best, worst     float64
+	}
+	groups := make(map[string]*group)
+	for _, t := range e.journal(args) {
+		for _, key := range keys(t) {
+			g := groups[key]
+			if g == nil {
+				g = &group{best: t.PnL, worst: t.PnL}
+				groups[key] = g
+			}
+			g.trades++
+			g.pnl += t.PnL
+			if t.PnL > 0 {
+				g.wins++
+			} else if t.PnL < 0 {
+				g.losses++
+			}
+			if t.PnL > g.best {
+				g.best = t.PnL
+			}
+			if t.PnL < g.worst {
+				g.worst = t.PnL
+			}
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for key := range groups {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	out := make([]graphql.Object, 0, len(groups))
+	for _, key := range names {
+		g := groups[key]
+		out = append(out, graphql.Object{
+			"key":      key,
+			"trades":   g.trades,
+			"wins":     g.wins,
+			"losses":   g.losses,
+			"winRate":  float64(g.wins) / float64(g.trades) * 100,
+			"pnl":      g.pnl,
+			"avgPnl":   g.pnl / float64(g.trades),
+			"bestPnl":  g.best,
+			"worstPnl": g.worst,
+		})
+	}
+	return out, nil
+}
+
+func (e *TradingEngine) resolvePositions(args *graphql.Args) (interface{}, error) {
+	symbols := args.Strings("symbol")
+	out := []graphql.Object{}
+	for _, p := range e.stateManager.Positions() {
+		if len(symbols) > 0 && !containsString(symbols, p.Symbol) {
+			continue
+		}
+		out = append(out, graphql.Object{
+			"symbol":     p.Symbol,
+			"side":       p.Side,
+			"size":       p.Size,
+			"entryPrice": p.EntryPrice,
+			"fillPrice":  p.FillPrice,
+			"stopLoss":   p.StopLoss,
+			"takeProfit": p.TakeProfit,
+			"openTime":   p.OpenTime,
+			"confidence": p.Confidence,
+			"reasoning":  p.Reasoning,
+			"components": p.Components,
+		})
+	}
+	return out, nil
+}
+
+// resolveSignals reads the decision log: every signal the engine entered,
+// skipped, rejected or dropped, with the reasons and scores
+func (e *TradingEngine) resolveSignals(args *graphql.Args) (interface{}, error) {
+	if e.decisions == nil {
+		return nil, fmt.Errorf("monitoring.decision_log_path is not set")
+	}
+	records, err := decisionlog.Read(e.decisions.Path(), decisionlog.Query{
+		Symbol: args.String("symbol"),
+		Action: decisionlog.Action(args.String("action")),
+		Reason: args.String("reason"),
+		Since:  args.Time("since"),
+		Until:  args.Time("until"),
+		Limit:  args.Int("limit"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]graphql.Object, 0, len(records))
+	for _, r := range records {
+		obj := graphql.Object{
+			"time":       r.Time,
+			"symbol":     r.Symbol,
+			"side":       r.Candidate,
+			"action":     string(r.Action),
+			"reasons":    r.Reasons,
+			"detail":     r.Detail,
+			"source":     r.Source,
+			"intent":     r.Intent,
+			"confidence": nil,
+			"scores":     r.Scores,
+			"thresholds": r.Thresholds,
+		}
+		if c, ok := r.Scores["confidence"]; ok {
+			obj["confidence"] = c
+		}
+		out = append(out, obj)
+	}
+	return out, nil
+}
+
+func (e *TradingEngine) resolveMetrics(args *graphql.Args) (interface{}, error) {
+	stats := e.stateManager.GetStats()
+	metrics := graphql.Object{
+		"capital":           stats.Capital,
+		"totalTrades":       stats.TotalTrades,
+		"wins":              stats.Wins,
+		"losses":            stats.Losses,
+		"winRate":           stats.WinRate,
+		"totalPnl":          stats.TotalPnL,
+		"dailyPnl":          stats.DailyPnL,
+		"weeklyPnl":         stats.WeeklyPnL,
+		"openPositions":     stats.OpenPositions,
+		"tradesToday":       e.tradeCount(),
+		"maxTradesPerDay":   e.maxTradesPerDay(),
+		"consecutiveLosses": stats.ConsecutiveLosses,
+		"lastTradeTime":     stats.LastTradeTime,
+		"halted":            stats.IsHalted,
+		"haltReason":        stats.HaltReason,
+		"namespace":         e.cfg.Namespace,
+	}
+	if e.profitLock != nil {
+		lock := e.profitLock.Status()
+		metrics["profitLock"] = graphql.Object{
+			"day":     lock.Day,
+			"open":    lock.Open,
+			"peak":    lock.Peak,
+			"gainPct": lock.GainPct,
+			"floor":   lock.Floor,
+			"armed":   lock.Armed,
+			"locked":  lock.Locked,
+		}
+	}
+	return metrics, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
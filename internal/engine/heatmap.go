@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/services/riskmap"
+)
+
+// RiskHeatmap summarizes the open exchange positions and how much of each
+// configured risk limit is in use
+func (e *TradingEngine) RiskHeatmap(ctx context.Context) (riskmap.Heatmap, error) {
+	ctx, cancel := e.calls.Context(ctx, callpolicy.Account)
+	defer cancel()
+
+	rows, err := e.binance.OpenPositions(ctx)
+	if err != nil {
+		return riskmap.Heatmap{}, err
+	}
+	balance, err := e.binance.GetBalance(ctx)
+	if err != nil {
+		return riskmap.Heatmap{}, err
+	}
+
+	positions := make([]riskmap.Position, 0, len(rows))
+	var unrealized float64
+	for _, r := range rows {
+		size := r.PositionAmt
+		if r.PositionSide == "SHORT" && size > 0 {
+			size = -size
+		}
+		positions = append(positions, riskmap.Position{
+			Symbol:           r.Symbol,
+			Size:             size,
+			EntryPrice:       r.EntryPrice,
+			MarkPrice:        r.MarkPrice,
+			Leverage:         r.Leverage,
+			UnrealizedPnL:    r.UnRealizedProfit,
+			LiquidationPrice: r.LiquidationPrice,
+		})
+		unrealized += r.UnRealizedProfit
+	}
+
+	h := riskmap.Build(positions, balance+unrealized, e.cfg.Risk.CorrelationBuckets)
+
+	stats := e.stateManager.GetStats()
+	dailyLoss := math.Max(0, -stats.DailyPnL)
+	h.AddLimit("position_size_usd", h.Largest(), e.cfg.Trading.MaxPositionUSD)
+	h.AddLimit("daily_loss_usd", dailyLoss, e.cfg.Trading.DailyTradeLimit)
+	if stats.Capital > 0 {
+		h.AddLimit("daily_drawdown_pct", dailyLoss/stats.Capital*100, e.cfg.Trading.MaxDailyDrawdown)
+	}
+	h.AddLimit("trades_per_day", float64(e.tradeCount()), float64(e.maxTradesPerDay()))
+	return h, nil
+}
+
+func (e *TradingEngine) serveRiskHeatmap(w http.ResponseWriter, r *http.Request) {
+	h, err := e.RiskHeatmap(r.Context())
+	if err != nil {
+		log.Printf("Risk heatmap unavailable: %v", err)
+		http.Error(w, "positions unavailable", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h)
+}
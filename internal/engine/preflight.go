@@ -0,0 +1,225 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/britej3/gobot/config"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/internal/app"
+	"github.com/britej3/gobot/pkg/indicator"
+	"github.com/britej3/gobot/services/kline"
+)
+
+// ReplayInterval is the candle interval the analyzer trades on and the
+// preflight replay steps through
+const ReplayInterval = "5m"
+
+// ReplayResult summarises a replay of the trading config over history
+type ReplayResult struct {
+	Start          time.Time
+	End            time.Time
+	Trades         int
+	Wins           int
+	PnL            float64
+	FinalEquity    float64
+	MaxDrawdownPct float64
+}
+
+func (r ReplayResult) String() string {
+	winRate := 0.0
+	if r.Trades > 0 {
+		winRate = float64(r.Wins) / float64(r.Trades) * 100
+	}
+	return fmt.Sprintf("%s to %s: %d trades, %.1f%% won, PnL %.2f, max drawdown %.2f%%",
+		r.Start.Format("2006-01-02 15:04"), r.End.Format("2006-01-02 15:04"), r.Trades, winRate, r.PnL, r.MaxDrawdownPct)
+}
+
+// replayPosition is an open simulated position
+type replayPosition struct {
+	side       trade.Side
+	entry      float64
+	quantity   float64
+	stopLoss   float64
+	takeProfit float64
+}
+
+// pnl returns the position's profit at price, net of round-trip fees
+func (p replayPosition) pnl(price, feeRate float64) float64 {
+	move := price - p.entry
+	if p.side == trade.SideSell {
+		move = -move
+	}
+	return move*p.quantity - (p.entry+price)*p.quantity*feeRate
+}
+
+// replaySource serves a fixed window of candles to a kline service
+type replaySource []trade.Kline
+
+func (r replaySource) Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	if limit <= 0 || limit > len(r) {
+		limit = len(r)
+	}
+	return r[len(r)-limit:], nil
+}
+
+// Replay runs the rule-based analyzer and position planner of cfg over
+// history, candles per symbol oldest first, without the brain. Entries fill
+// at the signal candle's close and exit at the first later candle reaching
+// the stop or target, the stop when a candle reaches both. Drawdown is
+// measured on equity marked to each candle's close.
+func Replay(ctx context.Context, cfg *config.ProductionConfig, history map[string][]trade.Kline) (ReplayResult, error) {
+	analyzerCfg := analyzerConfig(cfg)
+	analyzerCfg.Interval = ReplayInterval
+	planner := trade.NewPositionPlanner(app.SizingLimits(cfg))
+	feeRate := cfg.Trading.GetTakerFeeRate()
+	warmUp := indicator.Standard.WarmUp()
+
+	// Step through every candle close seen across the symbols in order.
+	index := make(map[string]map[int64]int, len(history))
+	var closes []int64
+	seen := make(map[int64]bool)
+	for symbol, klines := range history {
+		index[symbol] = make(map[int64]int, len(klines))
+		for i, k := range klines {
+			at := k.CloseTime.UnixMilli()
+			index[symbol][at] = i
+			if i >= warmUp && !seen[at] {
+				seen[at] = true
+				closes = append(closes, at)
+			}
+		}
+	}
+	if len(closes) == 0 {
+		return ReplayResult{}, fmt.Errorf("need more than %d candles per symbol to replay", warmUp)
+	}
+	sort.Slice(closes, func(i, j int) bool { return closes[i] < closes[j] })
+	symbols := make([]string, 0, len(history))
+	for symbol := range history {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	capital := cfg.Trading.InitialCapitalUSD
+	if capital <= 0 {
+		capital = 100
+	}
+	result := ReplayResult{
+		Start: time.UnixMilli(closes[0]),
+		End:   time.UnixMilli(closes[len(closes)-1]),
+	}
+	open := make(map[string]replayPosition)
+	marks := make(map[string]float64)
+	realized, peak := 0.0, capital
+	day, tradesToday := "", 0
+
+	for _, at := range closes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if d := time.UnixMilli(at).UTC().Format("2006-01-02"); d != day {
+			day, tradesToday = d, 0
+		}
+
+		for _, symbol := range symbols {
+			i, ok := index[symbol][at]
+			if !ok {
+				continue
+			}
+			candle := history[symbol][i]
+			marks[symbol] = candle.Close
+
+			if pos, held := open[symbol]; held {
+				exit, closed := replayExit(pos, candle)
+				if !closed {
+					continue
+				}
+				pnl := pos.pnl(exit, feeRate)
+				realized += pnl
+				result.Trades++
+				if pnl > 0 {
+					result.Wins++
+				}
+				delete(open, symbol)
+				continue
+			}
+			if cfg.Trading.MaxTradesPerDay > 0 && tradesToday >= cfg.Trading.MaxTradesPerDay {
+				continue
+			}
+
+			svc := kline.New(kline.Config{Intervals: []string{ReplayInterval}}, replaySource(history[symbol][:i+1]))
+			signal, err := NewPipelineAnalyzer(analyzerCfg, svc, nil).Analyze(ctx, symbol)
+			var rejection *Rejection
+			if errors.As(err, &rejection) || signal == nil {
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+
+			side := trade.SideBuy
+			if signal.Action == "SHORT" {
+				side = trade.SideSell
+			}
+			plan, err := planner.Plan(trade.PlanRequest{
+				Side:       side,
+				Entry:      signal.EntryPrice,
+				StopLoss:   signal.StopLoss,
+				TakeProfit: signal.TakeProfit,
+				Equity:     capital + realized,
+			})
+			if err != nil || plan.Quantity <= 0 {
+				continue
+			}
+			open[symbol] = replayPosition{side: side, entry: signal.EntryPrice, quantity: plan.Quantity, stopLoss: plan.StopLoss, takeProfit: plan.TakeProfit}
+			tradesToday++
+		}
+
+		equity := capital + realized
+		for symbol, pos := range open {
+			equity += pos.pnl(marks[symbol], feeRate)
+		}
+		if equity > peak {
+			peak = equity
+		}
+		if dd := (peak - equity) / peak * 100; dd > result.MaxDrawdownPct {
+			result.MaxDrawdownPct = dd
+		}
+	}
+
+	// Positions still open at the end are closed at the last price.
+	for symbol, pos := range open {
+		pnl := pos.pnl(marks[symbol], feeRate)
+		realized += pnl
+		result.Trades++
+		if pnl > 0 {
+			result.Wins++
+		}
+	}
+	result.PnL = realized
+	result.FinalEquity = capital + realized
+	return result, nil
+}
+
+// replayExit returns where candle takes pos out, if it does
+func replayExit(pos replayPosition, candle trade.Kline) (float64, bool) {
+	if pos.side == trade.SideSell {
+		switch {
+		case candle.High >= pos.stopLoss:
+			return pos.stopLoss, true
+		case pos.takeProfit > 0 && candle.Low <= pos.takeProfit:
+			return pos.takeProfit, true
+		}
+		return 0, false
+	}
+	switch {
+	case candle.Low <= pos.stopLoss:
+		return pos.stopLoss, true
+	case pos.takeProfit > 0 && candle.High >= pos.takeProfit:
+		return pos.takeProfit, true
+	}
+	return 0, false
+}
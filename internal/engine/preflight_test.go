@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/config"
+	"github.com/britej3/gobot/domain/trade"
+)
+
+func TestReplay_MeasuresDrawdownOfTheLiveConfig(t *testing.T) {
+	// A choppy climb the analyzer goes long on, then a slide through the stops.
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	klines := make([]trade.Kline, 400)
+	for i := range klines {
+		price := 100 + float64(i)*0.1
+		if i%3 == 0 {
+			price -= 0.4
+		}
+		if i >= 300 {
+			price = 130 - float64(i-300)*0.5
+		}
+		open := start.Add(time.Duration(i) * 5 * time.Minute)
+		klines[i] = trade.Kline{
+			OpenTime:  open,
+			Open:      price,
+			High:      price + 0.2,
+			Low:       price - 0.2,
+			Close:     price,
+			Volume:    100,
+			CloseTime: open.Add(5*time.Minute - time.Millisecond),
+		}
+	}
+
+	cfg := &config.ProductionConfig{}
+	cfg.Trading.InitialCapitalUSD = 1000
+	cfg.Trading.MaxRiskPerTrade = 0.02
+	cfg.Trading.MaxPositionUSD = 5000
+	cfg.Trading.StopLossPercent = 1
+	cfg.Trading.TakeProfitPercent = 2
+
+	result, err := Replay(context.Background(), cfg, map[string][]trade.Kline{"BTCUSDT": klines})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Trades == 0 || result.Wins == 0 || result.Wins == result.Trades {
+		t.Fatalf("expected winners in the climb and losers in the slide, got %+v", result)
+	}
+	if result.MaxDrawdownPct <= 0 || result.FinalEquity != 1000+result.PnL {
+		t.Fatalf("result = %+v", result)
+	}
+	if !result.End.After(result.Start) {
+		t.Fatalf("replayed %v to %v", result.Start, result.End)
+	}
+}
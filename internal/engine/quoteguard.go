@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"log"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/infra/binance"
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/orderbook"
+)
+
+// bookVenue serves the chaser's top of book from the local order books,
+// falling back to the REST book ticker
+type bookVenue struct {
+	*binance.HardenedClient
+	books *orderbook.Books
+}
+
+func (v bookVenue) BookTop(ctx context.Context, symbol string) (float64, float64, error) {
+	if top, ok := v.books.Top(symbol); ok {
+		return top.Bid, top.Ask, nil
+	}
+	return v.HardenedClient.BookTop(ctx, symbol)
+}
+
+// entryDrift is how far the live touch has already moved from entry toward
+// takeProfit, as a fraction of that distance. A long pays the ask and a short
+// sells the bid; moves against the trade come out negative.
+func entryDrift(side trade.Side, entry, takeProfit, bid, ask float64) float64 {
+	target := takeProfit - entry
+	if target == 0 {
+		return 0
+	}
+	live := ask
+	if side == trade.SideSell {
+		live = bid
+	}
+	return (live - entry) / target
+}
+
+// liveQuote reads the local order book, or the book ticker when the symbol
+// has no synced local book, just before an entry. It returns zeros when the
+// book cannot be read.
+func (e *TradingEngine) liveQuote(ctx context.Context, symbol string) (float64, float64) {
+	if e.depth != nil {
+		if top, ok := e.depth.Top(symbol); ok {
+			return top.Bid, top.Ask
+		}
+	}
+	if e.binance == nil {
+		return 0, 0
+	}
+	bookCtx, cancel := e.calls.Context(ctx, callpolicy.Exchange)
+	bid, ask, err := e.binance.BookTop(bookCtx, symbol)
+	cancel()
+	if err != nil || bid <= 0 || ask <= 0 {
+		log.Printf("No live quote for %s, entering without drift and spread checks: %v", symbol, err)
+		return 0, 0
+	}
+	return bid, ask
+}
+
+// checkQuoteDrift re-prices an entry against the live book before it is
+// sent. Signals are priced off kline snapshots; if the market has already
+// covered more than execution.max_entry_drift of the way to take profit, the
+// move happened without us and the entry is rejected rather than chased.
+// The entry goes ahead when there is no live quote.
+func (e *TradingEngine) checkQuoteDrift(symbol string, side trade.Side, signal *TradingSignal, takeProfit, bid, ask float64) bool {
+	maxDrift := e.cfg.Execution.MaxEntryDrift
+	if maxDrift <= 0 || signal.EntryPrice <= 0 || bid <= 0 || ask <= 0 {
+		return true
+	}
+
+	drift := entryDrift(side, signal.EntryPrice, takeProfit, bid, ask)
+	if drift <= maxDrift {
+		return true
+	}
+
+	rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonPriceMoved)
+	rec.Scores["entry_drift"] = drift
+	rec.Scores["bid"], rec.Scores["ask"] = bid, ask
+	rec.Thresholds = map[string]float64{"max_entry_drift": maxDrift}
+	e.decide(rec)
+	e.auditLogger.Log("TRADE_REJECTED", map[string]interface{}{
+		"symbol":          symbol,
+		"action":          signal.Action,
+		"entry_price":     signal.EntryPrice,
+		"take_profit":     takeProfit,
+		"bid":             bid,
+		"ask":             ask,
+		"entry_drift":     drift,
+		"max_entry_drift": maxDrift,
+		"reason":          "price already moved toward target",
+	})
+	return false
+}
+
+// checkBookDepth rejects an entry when less than
+// execution.min_book_depth_usd rests within book_depth_bps of the mid on the
+// side it takes: the asks for a long, the bids for a short. The entry goes
+// ahead when there is no synced local book.
+func (e *TradingEngine) checkBookDepth(symbol string, side trade.Side, signal *TradingSignal) bool {
+	minDepth := e.cfg.Execution.MinBookDepthUSD
+	if minDepth <= 0 || e.depth == nil {
+		return true
+	}
+	bps := e.cfg.Execution.GetBookDepthBps()
+	bidUSD, askUSD, ok := e.depth.Depth(symbol, bps)
+	if !ok {
+		return true
+	}
+	depth := askUSD
+	if side == trade.SideSell {
+		depth = bidUSD
+	}
+	if depth >= minDepth {
+		return true
+	}
+
+	rec := signalDecision(symbol, signal, decisionlog.ActionReject, decisionlog.ReasonThinBook)
+	rec.Scores["depth_usd"] = depth
+	rec.Thresholds = map[string]float64{"min_book_depth_usd": minDepth, "book_depth_bps": bps}
+	e.decide(rec)
+	e.auditLogger.Log("TRADE_REJECTED", map[string]interface{}{
+		"symbol":             symbol,
+		"action":             signal.Action,
+		"depth_usd":          depth,
+		"min_book_depth_usd": minDepth,
+		"reason":             "order book too thin",
+	})
+	return false
+}
@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/services/decisionlog"
+)
+
+// signalHub fans executed signals out to stream subscribers. Slow
+// subscribers miss signals rather than block the trading loop.
+type signalHub struct {
+	mu   sync.Mutex
+	subs map[chan TradingSignal]struct{}
+}
+
+func (h *signalHub) subscribe() (chan TradingSignal, func()) {
+	ch := make(chan TradingSignal, 16)
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[chan TradingSignal]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+	}
+}
+
+func (h *signalHub) publish(signal TradingSignal) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs {
+		select {
+		case ch <- signal:
+		default:
+		}
+	}
+}
+
+// serveSignalStream streams executed signals as server-sent events
+func (e *TradingEngine) serveSignalStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	signals, unsubscribe := e.hub.subscribe()
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case signal := <-signals:
+			data, err := json.Marshal(signal)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: signal\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
+
+// FollowerConfig controls how a follower subscribes to a leader's signal stream
+type FollowerConfig struct {
+	LeaderURL      string
+	ReconnectDelay time.Duration
+	MaxSignalAge   time.Duration
+	Client         *http.Client
+}
+
+// Follow subscribes to the leader's signal stream and executes every signal
+// on the local account through the same risk gates, sizing and limits as
+// locally generated signals. It reconnects until ctx is cancelled.
+func (e *TradingEngine) Follow(ctx context.Context, cfg FollowerConfig) error {
+	if cfg.LeaderURL == "" {
+		return fmt.Errorf("leader URL is required")
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = 5 * time.Second
+	}
+	if cfg.MaxSignalAge <= 0 {
+		cfg.MaxSignalAge = 30 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{}
+	}
+
+	if e.orders != nil {
+		e.orders.Start(ctx)
+	}
+
+	url := strings.TrimRight(cfg.LeaderURL, "/") + "/signals/stream"
+	log.Printf("Following leader signal stream at %s", url)
+
+	for {
+		err := e.followOnce(ctx, cfg, url)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("Leader stream disconnected: %v, reconnecting in %s", err, cfg.ReconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(cfg.ReconnectDelay):
+		}
+	}
+}
+
+func (e *TradingEngine) followOnce(ctx context.Context, cfg FollowerConfig, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("leader returned %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var signal TradingSignal
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &signal); err != nil {
+			log.Printf("Ignoring malformed leader signal: %v", err)
+			continue
+		}
+		e.followSignal(ctx, cfg, &signal)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream closed by leader")
+}
+
+func (e *TradingEngine) followSignal(ctx context.Context, cfg FollowerConfig, signal *TradingSignal) {
+	signal.Source = SourceLeader
+	if age := time.Since(signal.Timestamp); !signal.Timestamp.IsZero() && age > cfg.MaxSignalAge {
+		rec := signalDecision(signal.Symbol, signal, decisionlog.ActionSkip, decisionlog.ReasonStaleSignal)
+		rec.Scores["age_s"] = age.Seconds()
+		rec.Thresholds = map[string]float64{"max_age_s": cfg.MaxSignalAge.Seconds()}
+		e.decide(rec)
+		return
+	}
+	reason := e.symbolBlock(signal.Symbol)
+	if !e.shouldTrade() {
+		reason = e.tradingBlock()
+	}
+	if reason != "" {
+		e.decide(signalDecision(signal.Symbol, signal, decisionlog.ActionSkip, reason))
+		return
+	}
+
+	// Age against the leader was checked above; the order queue only needs
+	// to measure how long the signal waits locally.
+	signal.Timestamp = time.Now()
+	signal.Reasoning = "leader: " + signal.Reasoning
+	e.executeTrade(ctx, signal.Symbol, signal)
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignalStream_DeliversPublishedSignals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leader := &TradingEngine{}
+	server := httptest.NewServer(leader.Handler(ctx))
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/signals/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	for deadline := time.Now().Add(time.Second); ; {
+		leader.hub.mu.Lock()
+		n := len(leader.hub.subs)
+		leader.hub.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream never subscribed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	leader.hub.publish(TradingSignal{Symbol: "BTCUSDT", Action: "LONG", EntryPrice: 100, Timestamp: time.Now()})
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var got TradingSignal
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Symbol != "BTCUSDT" || got.Action != "LONG" {
+			t.Errorf("unexpected signal %+v", got)
+		}
+		return
+	}
+	t.Fatal("stream ended without a signal")
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/britej3/gobot/pkg/alerting"
+	"github.com/britej3/gobot/services/decisionlog"
+)
+
+// watchEntry stands in for the order in watch-only mode. The entry is
+// recorded, audited and alerted with the size and levels it would have been
+// sent with, and counts toward the daily limit and the symbol's cooldown as
+// an entry would, so the signals reported are the ones live trading takes.
+func (e *TradingEngine) watchEntry(symbol string, signal *TradingSignal, size, stopLoss, takeProfit float64) {
+	rec := signalDecision(symbol, signal, decisionlog.ActionWatch, decisionlog.ReasonWatchOnly)
+	rec.Scores["size"] = size
+	rec.Scores["stop_loss"] = stopLoss
+	rec.Scores["take_profit"] = takeProfit
+	e.decide(rec)
+
+	e.mu.Lock()
+	e.tradesToday++
+	e.lastTrade = time.Now()
+	e.symbolCooldown[symbol] = time.Now()
+	e.watched++
+	e.mu.Unlock()
+
+	e.auditLogger.Log("WATCH_SIGNAL", map[string]interface{}{
+		"symbol":      symbol,
+		"action":      signal.Action,
+		"source":      signal.Source,
+		"size":        size,
+		"entry_price": signal.EntryPrice,
+		"stop_loss":   stopLoss,
+		"take_profit": takeProfit,
+		"confidence":  signal.Confidence,
+		"ev_bps":      signal.ExpectedValue,
+	})
+	e.telegram.SendTemplate(alerting.AlertTradeExecution, alerting.MsgTradeWatched, map[string]interface{}{
+		"Action":     signal.Action,
+		"Symbol":     symbol,
+		"Price":      signal.EntryPrice,
+		"Confidence": signal.Confidence,
+	})
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/britej3/gobot/pkg/apiweight"
+)
+
+// analysis is the outcome of analyzing one symbol. ran is false for a
+// symbol never started.
+type analysis struct {
+	signal *TradingSignal
+	err    error
+	ran    bool
+}
+
+// analyzeAll analyzes symbols on up to workers goroutines, starting them in
+// order so the best ranked go first. No symbol is started once ctx ends or
+// the API weight budget has refused a request; starved reports the latter.
+// Results line up with symbols.
+func (e *TradingEngine) analyzeAll(ctx context.Context, symbols []string, workers int) (results []analysis, starved bool) {
+	results = make([]analysis, len(symbols))
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(symbols) {
+		workers = len(symbols)
+	}
+
+	var refused int32
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				signal, err := e.analyzer.Analyze(ctx, symbols[i])
+				results[i] = analysis{signal: signal, err: err, ran: true}
+				if errors.Is(err, apiweight.ErrQuotaExceeded) {
+					atomic.StoreInt32(&refused, 1)
+				}
+			}
+		}()
+	}
+
+	for i := range symbols {
+		if ctx.Err() != nil || atomic.LoadInt32(&refused) == 1 {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results, atomic.LoadInt32(&refused) == 1
+}
@@ -8,11 +8,15 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/infra/binance"
 	"github.com/britej3/gobot/pkg/brain"
 	"github.com/britej3/gobot/pkg/feedback"
 	"github.com/britej3/gobot/internal/monitoring"
 	"github.com/britej3/gobot/internal/risk"
 	"github.com/britej3/gobot/internal/alerting"
+	"github.com/britej3/gobot/services/kline"
+	"github.com/britej3/gobot/services/liquidation"
+	"github.com/britej3/gobot/services/volspike"
 	"github.com/sirupsen/logrus"
 )
 
@@ -25,6 +29,10 @@ type Platform struct {
 	dashboard    *monitoring.DashboardServer
 	riskManager  *risk.RiskManager
 	alerting     *alerting.AlertingSystem
+	liquidations *liquidation.Monitor
+	liqStream    *binance.LiquidationStream
+	volSpikes    *volspike.Detector
+	klines       *kline.Service
 	isRunning    bool
 }
 
@@ -46,12 +54,13 @@ type Config struct {
 	WatchlistSymbols []string `json:"watchlist_symbols"`
 }
 
-// NewPlatform creates a new platform instance
-func NewPlatform() *Platform {
+// NewPlatform creates a new platform instance on the shared kline service
+func NewPlatform(klines *kline.Service) *Platform {
 	config := loadConfig()
 	
 	return &Platform{
 		config: config,
+		klines: klines,
 	}
 }
 
@@ -99,6 +108,14 @@ func (p *Platform) Stop(ctx context.Context) error {
 	
 	p.isRunning = false
 	
+	if p.liqStream != nil {
+		p.liqStream.Stop()
+	}
+	
+	if p.volSpikes != nil {
+		p.volSpikes.Stop()
+	}
+	
 	// Stop brain engine
 	if p.brain != nil {
 		if err := p.brain.Stop(); err != nil {
@@ -198,8 +215,38 @@ func (p *Platform) initNewComponents() error {
 	// Initialize dashboard
 	p.dashboard = monitoring.NewDashboardServer(p.client, p.feedback, p.brain, p.config.WatchlistSymbols)
 	
+	// Initialize liquidation cascade monitor
+	p.liquidations = liquidation.New(liquidation.Config{})
+	p.liqStream = binance.NewLiquidationStream(p.liquidations)
+	p.liquidations.OnCascade(func(s liquidation.Stats) {
+		scope := s.Symbol
+		if scope == "" {
+			scope = "MARKET"
+		}
+		logrus.WithFields(logrus.Fields{
+			"scope":            scope,
+			"long_liquidated":  s.LongLiquidated,
+			"short_liquidated": s.ShortLiquidated,
+			"until":            s.CascadeUntil,
+		}).Warn("🌊 Liquidation cascade detected - pausing entries")
+	})
+	
+	// Initialize volatility spike throttle on 1m candles
+	p.volSpikes = volspike.New(volspike.Config{}, p.klines)
+	p.volSpikes.Watch(p.config.WatchlistSymbols...)
+	p.volSpikes.OnSpike(func(s volspike.Spike) {
+		logrus.WithFields(logrus.Fields{
+			"symbol":  s.Symbol,
+			"z_score": fmt.Sprintf("%.1f", s.ZScore),
+			"return":  fmt.Sprintf("%.2f%%", s.Return*100),
+			"until":   s.Until.Format(time.Kitchen),
+		}).Warn("⚡ Volatility spike - capping leverage and size account-wide")
+	})
+	
 	// Initialize risk manager
-	p.riskManager = risk.NewRiskManager(p.client, p.feedback, p.config.WatchlistSymbols)
+	p.riskManager = risk.NewRiskManager(p.client, p.feedback, p.config.WatchlistSymbols, p.klines)
+	p.riskManager.SetCascadeMonitor(p.liquidations)
+	p.riskManager.SetLeverageThrottle(p.volSpikes)
 	
 	// Initialize alerting system
 	p.alerting = alerting.NewAlertingSystem(p.client, p.feedback, p.brain, p)
@@ -235,6 +282,18 @@ func (p *Platform) startComponents() error {
 		}
 	}
 	
+	if p.liqStream != nil {
+		if err := p.liqStream.Start(context.Background()); err != nil {
+			return fmt.Errorf("failed to start liquidation stream: %w", err)
+		}
+	}
+	
+	if p.volSpikes != nil {
+		if err := p.volSpikes.Start(context.Background()); err != nil {
+			return fmt.Errorf("failed to start volatility spike detector: %w", err)
+		}
+	}
+	
 	return nil
 }
 
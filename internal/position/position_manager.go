@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/infra/binance"
 	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/services/kline"
 	"github.com/sirupsen/logrus"
 )
 
@@ -15,6 +17,7 @@ import (
 type PositionManager struct {
 	client    *futures.Client
 	brain     *brain.BrainEngine
+	klines    *kline.Service
 	stopChan  chan struct{}
 	isRunning bool
 }
@@ -35,11 +38,13 @@ type PositionState struct {
 	Reasoning     string    `json:"reasoning"`
 }
 
-// NewPositionManager creates a new position manager
-func NewPositionManager(client *futures.Client, brain *brain.BrainEngine) *PositionManager {
+// NewPositionManager creates a new position manager reading candles from
+// the shared kline service
+func NewPositionManager(client *futures.Client, brain *brain.BrainEngine, klines *kline.Service) *PositionManager {
 	return &PositionManager{
 		client:   client,
 		brain:    brain,
+		klines:   klines,
 		stopChan: make(chan struct{}),
 	}
 }
@@ -302,11 +307,7 @@ func (pm *PositionManager) assessPositionHealth(ctx context.Context, symbol, sid
 // getMarketTrend analyzes market trend for a symbol
 func (pm *PositionManager) getMarketTrend(ctx context.Context, symbol string) (string, float64, error) {
 	// Get kline data for trend analysis
-	klines, err := pm.client.NewKlinesService().
-		Symbol(symbol).
-		Interval("5m").
-		Limit(20).
-		Do(ctx)
+	klines, err := pm.klines.Klines(ctx, symbol, "5m", 20)
 
 	if err != nil || len(klines) < 5 {
 		return "NEUTRAL", 0, nil
@@ -315,7 +316,7 @@ func (pm *PositionManager) getMarketTrend(ctx context.Context, symbol string) (s
 	// Calculate simple moving averages
 	var prices []float64
 	for _, k := range klines {
-		prices = append(prices, parseFloat(k.Close))
+		prices = append(prices, k.Close)
 	}
 
 	// Calculate trend based on recent price action
@@ -393,6 +394,7 @@ func (pm *PositionManager) closePosition(ctx context.Context, state *PositionSta
 
 	// Place market order to close
 	_, err := pm.client.NewCreateOrderService().
+		NewClientOrderID(binance.NewClientOrderID()).
 		Symbol(state.Symbol).
 		Side(side).
 		Type(futures.OrderTypeMarket).
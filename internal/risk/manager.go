@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/pkg/callpolicy"
 	"github.com/britej3/gobot/pkg/feedback"
+	"github.com/britej3/gobot/services/kline"
 )
 
 // RiskConfig holds risk management configuration
@@ -23,6 +25,7 @@ type RiskConfig struct {
 	TakeProfitMultiplier  float64 `json:"take_profit_multiplier"`   // Take profit distance multiplier
 	MaxCorrelationRisk    float64 `json:"max_correlation_risk"`     // Maximum correlation risk (0.0-1.0)
 	MinAccountBalance     float64 `json:"min_account_balance"`      // Minimum account balance before stopping
+	CascadeStopFactor     float64 `json:"cascade_stop_factor"`      // Stop distance multiplier during liquidation cascades (e.g., 0.5 = half)
 }
 
 // DefaultRiskConfig returns default risk management configuration
@@ -38,13 +41,36 @@ func DefaultRiskConfig() RiskConfig {
 		TakeProfitMultiplier: 2.0,   // 2:1 risk-reward ratio
 		MaxCorrelationRisk:   0.3,   // Maximum 30% correlation risk
 		MinAccountBalance:    1000,  // Minimum $1000 balance
+		CascadeStopFactor:    0.5,   // Halve stop distance during liquidation storms
 	}
 }
 
+// CascadeSignal reports liquidation storms, market-wide or per symbol
+type CascadeSignal interface {
+	CascadeRisk(symbol string) bool
+	MarketCascade() bool
+}
+
+// LeverageThrottle caps leverage and scales size account-wide while active,
+// e.g. for some minutes after a volatility spike
+type LeverageThrottle interface {
+	Throttle() (maxLeverage int, sizeFactor float64, active bool)
+}
+
+// StrategyBudget caps the notional each strategy may hold open
+type StrategyBudget interface {
+	Reserve(strategy string, notional float64) error
+	Release(strategy string, notional float64)
+}
+
 // RiskManager handles advanced risk management
 type RiskManager struct {
 	config     RiskConfig
 	client     *futures.Client
+	klines     *kline.Service
+	cascade    CascadeSignal
+	budget     StrategyBudget
+	throttle   LeverageThrottle
 	feedback   *feedback.CogneeFeedbackSystem
 	symbols    []string
 	mu         sync.RWMutex
@@ -53,11 +79,13 @@ type RiskManager struct {
 	lastUpdate        time.Time
 }
 
-// NewRiskManager creates a new risk manager
-func NewRiskManager(client *futures.Client, feedback *feedback.CogneeFeedbackSystem, symbols []string) *RiskManager {
+// NewRiskManager creates a new risk manager reading candles from the shared
+// kline service
+func NewRiskManager(client *futures.Client, feedback *feedback.CogneeFeedbackSystem, symbols []string, klines *kline.Service) *RiskManager {
 	return &RiskManager{
 		config:            DefaultRiskConfig(),
 		client:            client,
+		klines:            klines,
 		feedback:          feedback,
 		symbols:           symbols,
 		correlationMatrix: make(map[string]map[string]float64),
@@ -66,6 +94,27 @@ func NewRiskManager(client *futures.Client, feedback *feedback.CogneeFeedbackSys
 	}
 }
 
+// SetCascadeMonitor enables liquidation cascade checks for entries and stops
+func (rm *RiskManager) SetCascadeMonitor(cascade CascadeSignal) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.cascade = cascade
+}
+
+// SetLeverageThrottle lets a spike detector override the confidence-based leverage ladder
+func (rm *RiskManager) SetLeverageThrottle(throttle LeverageThrottle) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.throttle = throttle
+}
+
+// SetStrategyBudget enables per-strategy notional caps from a capital allocator
+func (rm *RiskManager) SetStrategyBudget(budget StrategyBudget) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.budget = budget
+}
+
 // UpdateConfig updates risk management configuration
 func (rm *RiskManager) UpdateConfig(config RiskConfig) {
 	rm.mu.Lock()
@@ -131,6 +180,16 @@ func (rm *RiskManager) CalculatePositionSize(ctx context.Context, symbol string,
 		positionSize *= (1 - correlationRisk)
 	}
 	
+	// Volatility spike throttle overrides the leverage ladder
+	if rm.throttle != nil {
+		if maxLeverage, sizeFactor, active := rm.throttle.Throttle(); active {
+			if optimalLeverage > maxLeverage {
+				optimalLeverage = maxLeverage
+			}
+			positionSize *= sizeFactor
+		}
+	}
+	
 	return positionSize, optimalLeverage, nil
 }
 
@@ -175,6 +234,11 @@ func (rm *RiskManager) CalculateDynamicStopLoss(entryPrice, volatility float64,
 		stopDistance = atr * 1.0
 	}
 	
+	// Tighten stops while liquidations are cascading through the market
+	if rm.cascade != nil && rm.cascade.MarketCascade() && rm.config.CascadeStopFactor > 0 {
+		stopDistance *= rm.config.CascadeStopFactor
+	}
+	
 	if side == "LONG" {
 		return entryPrice - stopDistance
 	} else {
@@ -182,6 +246,23 @@ func (rm *RiskManager) CalculateDynamicStopLoss(entryPrice, volatility float64,
 	}
 }
 
+// TightenStopForCascade pulls an open position's stop toward the current
+// price when its symbol or the market is in a liquidation cascade
+func (rm *RiskManager) TightenStopForCascade(symbol string, currentPrice, stopLoss float64, side string) float64 {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	
+	if rm.cascade == nil || !rm.cascade.CascadeRisk(symbol) || rm.config.CascadeStopFactor <= 0 {
+		return stopLoss
+	}
+	
+	distance := math.Abs(currentPrice-stopLoss) * rm.config.CascadeStopFactor
+	if side == "LONG" {
+		return math.Max(stopLoss, currentPrice-distance)
+	}
+	return math.Min(stopLoss, currentPrice+distance)
+}
+
 // CalculateDynamicTakeProfit calculates dynamic take profit based on risk-reward ratio
 func (rm *RiskManager) CalculateDynamicTakeProfit(entryPrice, stopLoss float64, side string) float64 {
 	rm.mu.RLock()
@@ -212,6 +293,11 @@ func (rm *RiskManager) CheckRiskLimits(ctx context.Context, symbol string, posit
 		return fmt.Errorf("account balance %.2f below minimum threshold %.2f", balance, rm.config.MinAccountBalance)
 	}
 	
+	// Pause entries during liquidation storms
+	if rm.cascade != nil && rm.cascade.CascadeRisk(symbol) {
+		return fmt.Errorf("liquidation cascade in progress for %s, entries paused", symbol)
+	}
+	
 	// Check correlation risk
 	correlationRisk := rm.getCorrelationRisk(symbol)
 	if correlationRisk > rm.config.MaxCorrelationRisk {
@@ -227,6 +313,35 @@ func (rm *RiskManager) CheckRiskLimits(ctx context.Context, symbol string, posit
 	return nil
 }
 
+// CheckStrategyLimits runs the account-wide checks and then books notional
+// against the strategy's allocation. Callers must ReleaseStrategy the same
+// notional when the order fails or the position closes.
+func (rm *RiskManager) CheckStrategyLimits(ctx context.Context, strategy, symbol string, positionSize, notional float64) error {
+	if err := rm.CheckRiskLimits(ctx, symbol, positionSize); err != nil {
+		return err
+	}
+
+	rm.mu.RLock()
+	budget := rm.budget
+	rm.mu.RUnlock()
+
+	if budget == nil {
+		return nil
+	}
+	return budget.Reserve(strategy, notional)
+}
+
+// ReleaseStrategy returns notional booked by CheckStrategyLimits
+func (rm *RiskManager) ReleaseStrategy(strategy string, notional float64) {
+	rm.mu.RLock()
+	budget := rm.budget
+	rm.mu.RUnlock()
+
+	if budget != nil {
+		budget.Release(strategy, notional)
+	}
+}
+
 // UpdateCorrelationMatrix updates the correlation matrix
 func (rm *RiskManager) UpdateCorrelationMatrix(ctx context.Context) error {
 	rm.mu.Lock()
@@ -236,19 +351,14 @@ func (rm *RiskManager) UpdateCorrelationMatrix(ctx context.Context) error {
 	priceData := make(map[string][]float64)
 	
 	for _, symbol := range rm.symbols {
-		klines, err := rm.client.NewKlinesService().
-			Symbol(symbol).
-			Interval("1m").
-			Limit(100).
-			Do(ctx)
-		
+		klines, err := rm.klines.Klines(ctx, symbol, "1m", 100)
 		if err != nil {
 			continue
 		}
 		
 		var prices []float64
-		for _, kline := range klines {
-			prices = append(prices, parseFloatSafe(kline.Close))
+		for _, k := range klines {
+			prices = append(prices, k.Close)
 		}
 		priceData[symbol] = prices
 	}
@@ -304,20 +414,17 @@ func (rm *RiskManager) getVolatility(symbol string) float64 {
 
 // calculateVolatility calculates volatility for a symbol
 func (rm *RiskManager) calculateVolatility(symbol string) float64 {
-	klines, err := rm.client.NewKlinesService().
-		Symbol(symbol).
-		Interval("1m").
-		Limit(50).
-		Do(context.Background())
-	
+	ctx, cancel := callpolicy.Default().Context(context.Background(), callpolicy.Klines)
+	defer cancel()
+	klines, err := rm.klines.Klines(ctx, symbol, "1m", 50)
 	if err != nil {
 		return 0
 	}
 	
 	var returns []float64
 	for i := 1; i < len(klines); i++ {
-		prevClose := parseFloatSafe(klines[i-1].Close)
-		currClose := parseFloatSafe(klines[i].Close)
+		prevClose := klines[i-1].Close
+		currClose := klines[i].Close
 		if prevClose > 0 {
 			returns = append(returns, (currClose-prevClose)/prevClose)
 		}
@@ -0,0 +1,419 @@
+// Package strategyplatform assembles the strategy platform from the app
+// container: the primary and additional strategies, the volume selector,
+// the market executor and the n8n automation, sharing the container's
+// journal, locks, suspensions and allocations with the other modes.
+package strategyplatform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/britej3/gobot/config"
+	"github.com/britej3/gobot/domain/automation"
+	"github.com/britej3/gobot/domain/executor"
+	"github.com/britej3/gobot/domain/platform"
+	"github.com/britej3/gobot/domain/selector"
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/internal/app"
+	"github.com/britej3/gobot/services/allocator"
+	"github.com/britej3/gobot/services/ideas"
+	"github.com/britej3/gobot/services/screenshot"
+	"github.com/britej3/gobot/services/suspension"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultCycleInterval is how often the platform selects assets and runs
+// its strategies over them.
+const DefaultCycleInterval = 2 * time.Minute
+
+// Config names the optional strategy files. Both are JSON.
+type Config struct {
+	// ShadowStrategyPath holds a strategy config evaluated in dry-run next
+	// to the primary one.
+	ShadowStrategyPath string
+	// StrategiesPath lists strategies to run next to the primary one and
+	// how the notional budget is split between them.
+	StrategiesPath string
+	CycleInterval  time.Duration
+}
+
+// Runner owns an initialized platform and the endpoints that go with it.
+type Runner struct {
+	Platform *platform.Platform
+
+	n8n         *config.N8NConfig
+	suspensions *suspension.Controller
+	ideas       *ideas.Inbox
+	interval    time.Duration
+}
+
+// New builds and initializes the platform and registers its lifecycle with
+// the container. Optional components that fail to build are logged and left
+// out, as the platform trades without them.
+func New(ctx context.Context, c *app.Container, cfg Config) (*Runner, error) {
+	n8nCfg, err := config.LoadN8NConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load N8N config: %w", err)
+	}
+
+	p := &platform.Platform{
+		Cfg: platform.PlatformConfig{
+			Name:    "GOBOT",
+			Version: "2.0.0",
+			StrategyConfig: strategy.StrategyConfig{
+				Type:    strategy.StrategyScalper,
+				Name:    "scalper_strategy",
+				Version: "1.0.0",
+				Enabled: true,
+				RiskParameters: strategy.RiskConfig{
+					StopLossPercent:   0.5,
+					TakeProfitPercent: 1.5,
+					RiskPerTrade:      0.02,
+				},
+			},
+			SelectorConfig: selector.SelectorConfig{
+				Type:          selector.SelectorVolume,
+				Name:          "volume_selector",
+				Enabled:       true,
+				MinVolume:     1000000,
+				MaxAssets:     15,
+				MinConfidence: 0.65,
+			},
+			ExecutorConfig: executor.ExecutionConfig{
+				Type:              executor.ExecutionMarket,
+				Name:              "market_executor",
+				Enabled:           true,
+				SlippageTolerance: 0.001,
+				MaxRetries:        3,
+			},
+			AutomationConfig: automation.AutomationConfig{
+				Type:    automation.AutomationN8N,
+				Name:    "n8n_automation",
+				Enabled: true,
+				N8NConfig: automation.N8NConfig{
+					BaseURL:   n8nCfg.BaseURL,
+					APIKey:    n8nCfg.APIKey,
+					Workflows: convertN8NWorkflows(n8nCfg.Workflows),
+				},
+			},
+			ScoreHalfLife: c.Config.Trading.GetScoreHalfLife(),
+			MaxSignalAge:  c.Config.Trading.GetSignalFreshness(),
+		},
+		Engine: c.Engine(),
+		Components: &platform.Components{
+			MarketDataProvider: c.MarketData(),
+		},
+	}
+
+	if cfg.ShadowStrategyPath != "" {
+		shadowCfg, err := loadStrategyConfig(cfg.ShadowStrategyPath)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to load shadow strategy config")
+		} else {
+			p.Cfg.ShadowStrategyConfig = shadowCfg
+			logrus.WithFields(logrus.Fields{"strategy": shadowCfg.Name, "version": shadowCfg.Version}).
+				Info("🧪 Shadow mode: evaluating in dry-run, no orders will be placed for it")
+		}
+	}
+
+	if cfg.StrategiesPath != "" {
+		if err := enableStrategies(c, p, cfg.StrategiesPath); err != nil {
+			logrus.WithError(err).Warn("Failed to load strategies config")
+		}
+	}
+
+	if usesUniverses(p.Cfg) {
+		p.Components.Universes = c.Screener()
+	}
+
+	// Positions are journaled so their close, reported by the account
+	// stream, gives the strategy's allocation back and feeds its returns.
+	if st, err := c.State(); err != nil {
+		logrus.WithError(err).Warn("Positions are not journaled, strategy allocations are never released")
+	} else if _, err := c.Exits(); err != nil {
+		logrus.WithError(err).Warn("Closed positions are not journaled, strategy allocations are never released")
+	} else {
+		p.Components.Journal = st
+		st.OnTrade(p.TradeClosed)
+	}
+	// Entries are sized by the planner every other entry path uses, from
+	// the journaled capital.
+	p.Components.Planner = c.Planner()
+	if st, err := c.State(); err == nil {
+		p.Components.Capital = st
+	}
+	p.Components.PositionLocks = c.PositionLocks()
+	p.Components.Intents = c.Intents()
+	p.Components.EntryLimits = c.EntryLimits()
+
+	suspensions, err := c.Suspensions()
+	if err != nil {
+		logrus.WithError(err).Warn("Strategy and symbol suspensions unavailable")
+	} else {
+		p.Components.Suspensions = suspensions
+	}
+	if rotator, err := c.Regime(); err != nil {
+		logrus.WithError(err).Warn("Strategy rotation by regime unavailable")
+	} else if rotator != nil {
+		logrus.Info("🔄 Rotating strategies with the market regime")
+	}
+
+	p.OnShadowDiff(func(d platform.ShadowDiff) {
+		logrus.Infof("🧪 Shadow diff [%s] %s: live enter=%v size=%.6f sl=%.6f tp=%.6f order=%q | shadow enter=%v size=%.6f sl=%.6f tp=%.6f (%s)",
+			d.Kind, d.Symbol,
+			d.Live.ShouldEnter, d.Live.PositionSize, d.Live.StopLoss, d.Live.TakeProfit, d.LiveOrderID,
+			d.Shadow.ShouldEnter, d.Shadow.PositionSize, d.Shadow.StopLoss, d.Shadow.TakeProfit, d.Shadow.Reason)
+	})
+
+	if err := p.Initialize(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize platform: %w", err)
+	}
+
+	c.Register(app.Hook{
+		Name:    "platform",
+		OnStart: p.Start,
+		OnStop:  func(context.Context) error { return p.Stop() },
+	})
+
+	interval := cfg.CycleInterval
+	if interval <= 0 {
+		interval = DefaultCycleInterval
+	}
+	return &Runner{
+		Platform:    p,
+		n8n:         n8nCfg,
+		suspensions: suspensions,
+		ideas:       c.Ideas(),
+		interval:    interval,
+	}, nil
+}
+
+// Run runs a trading cycle every interval until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Platform.RunCycle(ctx); err != nil {
+				logrus.WithError(err).Warn("Trading cycle error")
+			}
+		}
+	}
+}
+
+// N8NBaseURL is where the n8n workflows the platform triggers live.
+func (r *Runner) N8NBaseURL() string {
+	return r.n8n.BaseURL
+}
+
+// Handler serves health, suspensions, the ideas inbox and the n8n webhooks.
+func (r *Runner) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	if r.suspensions != nil {
+		mux.Handle("/suspensions", r.suspensions)
+	}
+	if r.ideas != nil {
+		mux.Handle("/ideas", r.ideas)
+	}
+
+	for path, name := range map[string]string{
+		"/webhook/trade_signal":    "trade signal",
+		"/webhook/risk-alert":      "risk alert",
+		"/webhook/market-analysis": "market analysis",
+	} {
+		name := name
+		mux.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+			var data map[string]interface{}
+			if err := json.NewDecoder(req.Body).Decode(&data); err != nil {
+				http.Error(w, "Invalid JSON", http.StatusBadRequest)
+				return
+			}
+
+			logrus.WithField("data", data).Infof("Received %s from N8N", name)
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+
+	// TradingView Screenshot endpoint - triggered by GOBOT
+	mux.HandleFunc("/webhook/capture-chart", func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Symbol    string   `json:"symbol"`
+			Intervals []string `json:"intervals,omitempty"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		if body.Symbol == "" {
+			http.Error(w, "Missing symbol", http.StatusBadRequest)
+			return
+		}
+
+		if len(body.Intervals) == 0 {
+			body.Intervals = []string{"1m", "5m", "15m"}
+		}
+
+		logrus.WithFields(logrus.Fields{"symbol": body.Symbol, "intervals": body.Intervals}).Info("📸 Capturing charts")
+
+		result, err := newScreenshotClient().CaptureMulti(body.Symbol, body.Intervals)
+		if err != nil {
+			logrus.WithError(err).Warn("Screenshot failed")
+			http.Error(w, fmt.Sprintf("Screenshot failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		logrus.WithField("symbol", body.Symbol).Infof("✅ Captured %d charts", len(result.Results))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+
+	// Trigger QuantCrawler analysis with screenshots
+	mux.HandleFunc("/webhook/analyze-symbol", func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Symbol         string  `json:"symbol"`
+			AccountBalance float64 `json:"account_balance"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		if body.Symbol == "" {
+			http.Error(w, "Missing symbol", http.StatusBadRequest)
+			return
+		}
+
+		logrus.WithField("symbol", body.Symbol).Info("🎯 Starting analysis workflow")
+
+		var shots map[string]string
+		if result, err := newScreenshotClient().CaptureMulti(body.Symbol, []string{"1m", "5m", "15m"}); err != nil {
+			logrus.WithError(err).Warn("Screenshot failed")
+		} else {
+			shots = result.Results
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"symbol":      body.Symbol,
+			"screenshots": shots,
+			"status":      "ready_for_analysis",
+			"next_step":   "Send to QuantCrawler for AI analysis",
+		})
+	})
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	return mux
+}
+
+func newScreenshotClient() *screenshot.Client {
+	return screenshot.NewClient(screenshot.Config{
+		ServerURL: "http://localhost:3456",
+	}, slog.Default())
+}
+
+func loadStrategyConfig(path string) (*strategy.StrategyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg strategy.StrategyConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// strategiesConfig lists strategies to run next to the primary one and how
+// the notional budget is split between all of them.
+type strategiesConfig struct {
+	Strategies []strategy.StrategyConfig `json:"strategies"`
+	Allocation struct {
+		Method           allocator.Method   `json:"method"`
+		Budget           float64            `json:"budget"`
+		Weights          map[string]float64 `json:"weights"`
+		MinWeight        float64            `json:"min_weight"`
+		RebalanceMinutes int                `json:"rebalance_minutes"`
+	} `json:"allocation"`
+}
+
+func enableStrategies(c *app.Container, p *platform.Platform, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var cfg strategiesConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	weights := map[string]float64{p.Cfg.StrategyConfig.Name: cfg.Allocation.Weights[p.Cfg.StrategyConfig.Name]}
+	for _, s := range cfg.Strategies {
+		weights[s.Name] = cfg.Allocation.Weights[s.Name]
+	}
+
+	alloc := allocator.New(allocator.Config{
+		Method:            cfg.Allocation.Method,
+		Budget:            cfg.Allocation.Budget,
+		Weights:           weights,
+		MinWeight:         cfg.Allocation.MinWeight,
+		RebalanceInterval: time.Duration(cfg.Allocation.RebalanceMinutes) * time.Minute,
+	})
+
+	p.Cfg.Strategies = cfg.Strategies
+	p.Components.Allocator = alloc
+	c.Register(app.Hook{
+		Name:    "allocator",
+		OnStart: alloc.Start,
+		OnStop:  func(context.Context) error { return alloc.Stop() },
+	})
+
+	for _, a := range alloc.Allocations() {
+		logrus.WithField("strategy", a.Strategy).Infof("💰 Weight %.0f%%, notional cap $%.2f", a.Weight*100, a.Cap)
+	}
+	return nil
+}
+
+// usesUniverses reports whether any live strategy is restricted to a
+// universe, in which case the screener has to run to classify symbols.
+func usesUniverses(cfg platform.PlatformConfig) bool {
+	if cfg.StrategyConfig.Universe != "" {
+		return true
+	}
+	for _, s := range cfg.Strategies {
+		if s.Universe != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func convertN8NWorkflows(workflows []config.N8NWorkflow) []automation.N8NWorkflow {
+	result := make([]automation.N8NWorkflow, len(workflows))
+	for i, w := range workflows {
+		result[i] = automation.N8NWorkflow{
+			ID:          w.ID,
+			Name:        w.Name,
+			TriggerType: w.TriggerType,
+			Enabled:     w.Enabled,
+		}
+	}
+	return result
+}
@@ -3,13 +3,22 @@ package striker
 import (
 	"context"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/infra/binance"
 	"github.com/britej3/gobot/internal/platform"
 	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/pkg/entrylimit"
+	"github.com/britej3/gobot/pkg/intent"
+	"github.com/britej3/gobot/services/kline"
+	"github.com/britej3/gobot/services/squeeze"
+	"github.com/britej3/gobot/services/symbolrules"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,15 +26,110 @@ import (
 type Striker struct {
 	client    *futures.Client
 	brain     *brain.BrainEngine
+	klines    *kline.Service
+	rules     *symbolrules.Registry
+	intents   *intent.Tracker
+	squeezes  SqueezeSource
 	isRunning bool
+
+	planner      *trade.PositionPlanner
+	entryLimits  *entrylimit.Limiter
+	maxDataAge   time.Duration
+	maxSignalAge time.Duration
+	staleMu      sync.Mutex
+	staleSkips   map[string]int
 }
 
-// NewStriker creates a new trading striker
-func NewStriker(client *futures.Client, brain *brain.BrainEngine) *Striker {
+// defaultMaxDataAge is how old the newest candle may be before a decision is skipped
+const defaultMaxDataAge = 2 * time.Minute
+
+// NewStriker creates a new trading striker reading candles from the shared
+// kline service and sizing entries with planner, the container's Planner()
+// so the striker sizes like every other entry path
+func NewStriker(client *futures.Client, brain *brain.BrainEngine, klines *kline.Service, planner *trade.PositionPlanner) *Striker {
 	return &Striker{
-		client: client,
-		brain:  brain,
+		client:  client,
+		brain:   brain,
+		klines:  klines,
+		rules:   symbolrules.New(symbolrules.Config{}, binance.NewFuturesSymbolRulesSource(client)),
+		planner: planner,
+
+		maxDataAge: defaultMaxDataAge,
+		staleSkips: make(map[string]int),
+	}
+}
+
+// SetMaxDataAge sets how stale decision inputs may be. Zero disables the check.
+func (s *Striker) SetMaxDataAge(age time.Duration) {
+	s.maxDataAge = age
+}
+
+// SetMaxSignalAge refuses assets scored longer ago than age. Zero disables
+// the check.
+func (s *Striker) SetMaxSignalAge(age time.Duration) {
+	s.maxSignalAge = age
+}
+
+// StaleSkips returns how many decisions were skipped per symbol because
+// their market data was older than the max data age
+func (s *Striker) StaleSkips() map[string]int {
+	s.staleMu.Lock()
+	defer s.staleMu.Unlock()
+
+	skips := make(map[string]int, len(s.staleSkips))
+	for symbol, n := range s.staleSkips {
+		skips[symbol] = n
+	}
+	return skips
+}
+
+// isStale reports whether the newest candle closed longer than maxDataAge ago,
+// meaning the exchange stopped printing or our feed fell behind
+func (s *Striker) isStale(symbol string, klines []trade.Kline) (bool, time.Duration) {
+	if s.maxDataAge <= 0 || len(klines) == 0 {
+		return false, 0
+	}
+
+	age := time.Since(klines[len(klines)-1].CloseTime)
+	if age <= s.maxDataAge {
+		return false, age
+	}
+
+	s.staleMu.Lock()
+	if s.staleSkips == nil {
+		s.staleSkips = make(map[string]int)
 	}
+	s.staleSkips[symbol]++
+	s.staleMu.Unlock()
+	return true, age
+}
+
+// SetSymbolRules replaces the striker's lazily loaded exchange filters with a preloaded registry
+func (s *Striker) SetSymbolRules(rules *symbolrules.Registry) {
+	s.rules = rules
+}
+
+// SqueezeSource reports the recent volatility squeeze release of a symbol
+type SqueezeSource interface {
+	Last(symbol string) (squeeze.Signal, bool)
+}
+
+// SetSqueezes adds released squeezes to the decision inputs, raising the
+// confidence of symbols whose volatility is just expanding
+func (s *Striker) SetSqueezes(source SqueezeSource) {
+	s.squeezes = source
+}
+
+// SetIntents tracks the striker's orders in the lifecycle shared with the
+// other entry paths
+func (s *Striker) SetIntents(intents *intent.Tracker) {
+	s.intents = intents
+}
+
+// SetEntryLimits counts the striker's entries against the session limits
+// shared with the other entry paths
+func (s *Striker) SetEntryLimits(limits *entrylimit.Limiter) {
+	s.entryLimits = limits
 }
 
 // Execute performs real striker analysis and trade execution
@@ -45,6 +149,7 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 	var symbol string
 	var currentPrice float64
 	var confidence float64
+	var scoredAt time.Time
 
 	// Use reflection to access struct fields (ScoredAsset from watcher package)
 	v := reflect.ValueOf(topAsset)
@@ -61,6 +166,9 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 		if confField := v.FieldByName("Confidence"); confField.IsValid() && confField.Kind() == reflect.Float64 {
 			confidence = confField.Float()
 		}
+		if scoredField := v.FieldByName("ScoredAt"); scoredField.IsValid() && scoredField.Type() == reflect.TypeOf(time.Time{}) {
+			scoredAt = scoredField.Interface().(time.Time)
+		}
 
 		if symbol != "" && currentPrice > 0 {
 			logrus.WithFields(logrus.Fields{
@@ -87,6 +195,9 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 		if conf, ok := assetMap["Confidence"].(float64); ok {
 			confidence = conf
 		}
+		if at, ok := assetMap["ScoredAt"].(time.Time); ok {
+			scoredAt = at
+		}
 		logrus.WithField("symbol", symbol).Info("🎯 Processing asset from map")
 	} else {
 		// Log the actual type for debugging
@@ -98,15 +209,44 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 		}, nil
 	}
 
+	if s.maxSignalAge > 0 && !scoredAt.IsZero() && time.Since(scoredAt) > s.maxSignalAge {
+		logrus.WithFields(logrus.Fields{
+			"symbol":  symbol,
+			"age":     time.Since(scoredAt).Round(time.Second),
+			"max_age": s.maxSignalAge,
+		}).Warn("⏸️ Signal too old, skipping decision")
+		return &brain.StrikerDecision{
+			Timestamp:    time.Now().Format(time.RFC3339),
+			TopTargets:   []brain.TargetAsset{},
+			MarketRegime: "RANGING",
+		}, nil
+	}
+
 	// Get market conditions for the asset
 	hasPosition := s.checkPosition(ctx, symbol)
 
 	// Get kline data for volatility calculation
-	klines, err := s.client.NewKlinesService().
-		Symbol(symbol).
-		Interval("5m").
-		Limit(50).
-		Do(ctx)
+	klines, err := s.klines.Klines(ctx, symbol, "5m", 50)
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Warn("⏸️ No kline data, skipping decision")
+		return &brain.StrikerDecision{
+			Timestamp:    time.Now().Format(time.RFC3339),
+			TopTargets:   []brain.TargetAsset{},
+			MarketRegime: "RANGING",
+		}, nil
+	}
+	if stale, age := s.isStale(symbol, klines); stale {
+		logrus.WithFields(logrus.Fields{
+			"symbol":  symbol,
+			"age":     age.Round(time.Second),
+			"max_age": s.maxDataAge,
+		}).Warn("⏸️ Market data stale, skipping decision")
+		return &brain.StrikerDecision{
+			Timestamp:    time.Now().Format(time.RFC3339),
+			TopTargets:   []brain.TargetAsset{},
+			MarketRegime: "RANGING",
+		}, nil
+	}
 
 	volatility := 0.02
 	volumeSpike := false
@@ -114,7 +254,7 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 		// Calculate volatility
 		prices := make([]float64, len(klines))
 		for i, k := range klines {
-			prices[i] = parseFloat(k.Close)
+			prices[i] = k.Close
 		}
 
 		// Simple volatility calculation (standard deviation)
@@ -135,11 +275,11 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 		if len(klines) >= 4 {
 			recentVol := 0.0
 			for i := len(klines) - 4; i < len(klines); i++ {
-				recentVol += parseFloat(klines[i].Volume)
+				recentVol += klines[i].Volume
 			}
 			avgVol := 0.0
 			for i := 0; i < len(klines)-4; i++ {
-				avgVol += parseFloat(klines[i].Volume)
+				avgVol += klines[i].Volume
 			}
 			avgVol = avgVol / float64(len(klines)-4)
 			volumeSpike = recentVol/3 > avgVol*1.5
@@ -158,6 +298,15 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 		}
 	}
 
+	// A released squeeze is an expansion the 24h change has not caught up with yet
+	var released *squeeze.Signal
+	if s.squeezes != nil {
+		if sig, ok := s.squeezes.Last(symbol); ok {
+			released = &sig
+			fvgConfidence = math.Min(1, fvgConfidence+sig.Contribution)
+		}
+	}
+
 	markets := map[string]interface{}{
 		"symbol":         symbol,
 		"current_price":  currentPrice,
@@ -171,6 +320,19 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 		"market_regime":  "VOLATILE",
 	}
 
+	if released != nil {
+		markets["squeeze_direction"] = released.Direction
+		markets["squeeze_score"] = released.Score
+		markets["squeeze_bars"] = released.Bars
+		markets["market_regime"] = "VOLATILE_EXPANSION"
+	}
+
+	if ind, err := s.klines.Indicators(ctx, symbol, "5m"); err == nil {
+		markets["vwap"] = ind.VWAP
+		markets["session_vwap"] = ind.SessionVWAP
+		markets["patterns"] = ind.Patterns
+	}
+
 	// Query AI for trading decision
 	decision, err := s.brain.MakeTradingDecision(ctx, markets)
 	if err != nil {
@@ -180,6 +342,20 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 	// Execute trade if confidence is high (0.0-1.0 scale)
 	// Lowered to 0.65 for aggressive scalping
 	if decision.Confidence > 0.65 && (decision.Decision == "BUY" || decision.Decision == "SELL") {
+		if ok, reason := s.checkEntryQuality(ctx, symbol, decision.Decision, currentPrice); !ok {
+			logrus.WithFields(logrus.Fields{
+				"symbol":   symbol,
+				"decision": decision.Decision,
+				"reason":   reason,
+			}).Info("⏸️ Entry rejected by VWAP filter")
+
+			return &brain.StrikerDecision{
+				Timestamp:    time.Now().Format(time.RFC3339),
+				TopTargets:   []brain.TargetAsset{},
+				MarketRegime: "RANGING",
+			}, nil
+		}
+
 		logrus.WithFields(logrus.Fields{
 			"symbol":     symbol,
 			"decision":   decision.Decision,
@@ -225,6 +401,37 @@ func (s *Striker) Execute(ctx context.Context, topAssets []interface{}) (*brain.
 	}, nil
 }
 
+// maxVWAPExtension is how far price may run past VWAP in the trend direction
+// before an entry is considered a chase
+const maxVWAPExtension = 0.005
+
+// checkEntryQuality rejects trend entries stretched too far from VWAP: longs in
+// an uptrend should be taken near or below VWAP, shorts in a downtrend near or above it
+func (s *Striker) checkEntryQuality(ctx context.Context, symbol, side string, price float64) (bool, string) {
+	ind, err := s.klines.Indicators(ctx, symbol, "5m")
+	if err != nil || price <= 0 {
+		return true, ""
+	}
+
+	vwap := ind.SessionVWAP
+	if vwap == 0 {
+		vwap = ind.VWAP
+	}
+	if vwap == 0 || ind.EMAFast == 0 || ind.EMASlow == 0 {
+		return true, ""
+	}
+
+	extension := (price - vwap) / vwap
+	switch {
+	case side == "BUY" && ind.EMAFast > ind.EMASlow && extension > maxVWAPExtension:
+		return false, fmt.Sprintf("price %.2f%% above VWAP in uptrend", extension*100)
+	case side == "SELL" && ind.EMAFast < ind.EMASlow && extension < -maxVWAPExtension:
+		return false, fmt.Sprintf("price %.2f%% below VWAP in downtrend", -extension*100)
+	}
+
+	return true, ""
+}
+
 // Check if position already exists
 func (s *Striker) checkPosition(ctx context.Context, symbol string) map[string]interface{} {
 	positions, err := s.client.NewGetPositionRiskService().
@@ -313,12 +520,7 @@ func (s *Striker) simulateTradingSignal(ctx context.Context) {
 
 func (s *Striker) getCurrentMarketConditions(ctx context.Context, symbol string) interface{} {
 	// Get recent price data
-	klines, err := s.client.NewKlinesService().
-		Symbol(symbol).
-		Interval("1m").
-		Limit(10).
-		Do(ctx)
-
+	klines, err := s.klines.Klines(ctx, symbol, "1m", 10)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get kline data")
 		return nil
@@ -330,7 +532,7 @@ func (s *Striker) getCurrentMarketConditions(ctx context.Context, symbol string)
 
 	// Calculate current conditions
 	latestKline := klines[len(klines)-1]
-	currentPrice := parseFloat(latestKline.Close)
+	currentPrice := latestKline.Close
 
 	// Get position info
 	positions, err := s.client.NewGetPositionRiskService().
@@ -356,7 +558,7 @@ func (s *Striker) getCurrentMarketConditions(ctx context.Context, symbol string)
 		"current_position": currentPosition,
 		"timestamp":        time.Now(),
 		"volatility":       0.02, // Would be calculated from klines
-		"volume":           parseFloat(latestKline.Volume),
+		"volume":           latestKline.Volume,
 	}
 }
 
@@ -382,8 +584,7 @@ func (s *Striker) executeDecision(ctx context.Context, symbol string, decision *
 }
 
 func (s *Striker) ExecuteBuyOrder(ctx context.Context, symbol string, decision *brain.TradingDecision) {
-	// Calculate order parameters
-	quantity := s.calculateOrderQuantity(decision.RecommendedLeverage)
+	id := s.intents.Propose(symbol, "BUY", "striker")
 
 	// Get current price for market order
 	ticker, err := s.client.NewListPricesService().
@@ -392,33 +593,61 @@ func (s *Striker) ExecuteBuyOrder(ctx context.Context, symbol string, decision *
 
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get current price")
+		s.intents.Advance(id, intent.Rejected, err.Error())
 		return
 	}
 
 	if len(ticker) == 0 {
 		logrus.Error("No price data received")
+		s.intents.Advance(id, intent.Rejected, "no price data")
 		return
 	}
 
 	currentPrice := parseFloat(ticker[0].Price)
 
+	plan, err := s.planOrder(ctx, trade.SideBuy, currentPrice, decision)
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Warn("Cannot size buy order")
+		s.intents.Advance(id, intent.Rejected, err.Error())
+		return
+	}
+
 	// Apply anti-sniffer jitter before order placement
 	// Per reply_unknown.md technical specs: 5-25ms normal distribution
 	logrus.Debug("🎲 Applying anti-sniffer jitter...")
 	platform.ApplyJitter()
 
+	rules, quantity, err := s.prepareOrder(ctx, symbol, currentPrice, plan.Quantity)
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Warn("⚠️ Buy order failed pre-trade filter check")
+		s.intents.Advance(id, intent.Rejected, err.Error())
+		return
+	}
+	release, err := s.entryLimits.Take()
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Warn("⏳ Buy order over the session's entry limit")
+		s.intents.Advance(id, intent.Rejected, err.Error())
+		return
+	}
+	s.intents.Advance(id, intent.Validated, "")
+	s.intents.Advance(id, intent.Submitted, "")
+
 	// Place market buy order
 	order, err := s.client.NewCreateOrderService().
+		NewClientOrderID(binance.NewClientOrderID()).
 		Symbol(symbol).
 		Side(futures.SideTypeBuy).
 		Type(futures.OrderTypeMarket).
-		Quantity(fmt.Sprintf("%.6f", quantity)).
+		Quantity(rules.FormatQuantity(quantity)).
 		Do(ctx)
 
 	if err != nil {
 		logrus.WithError(err).Error("Failed to place buy order")
+		s.intents.Advance(id, intent.Rejected, err.Error())
+		release()
 		return
 	}
+	s.intents.Advance(id, intent.Filled, strconv.FormatInt(order.OrderID, 10))
 
 	// Log successful order
 	logrus.WithFields(logrus.Fields{
@@ -426,17 +655,16 @@ func (s *Striker) ExecuteBuyOrder(ctx context.Context, symbol string, decision *
 		"order_id":   order.OrderID,
 		"quantity":   quantity,
 		"price":      currentPrice,
-		"leverage":   decision.RecommendedLeverage,
+		"leverage":   plan.Leverage,
 		"confidence": decision.Confidence,
 	}).Info("Buy order executed successfully")
 
 	// Set stop loss and take profit
-	s.setRiskManagement(ctx, symbol, currentPrice, decision, "LONG")
+	s.setRiskManagement(ctx, symbol, currentPrice, plan, "LONG")
 }
 
 func (s *Striker) ExecuteSellOrder(ctx context.Context, symbol string, decision *brain.TradingDecision) {
-	// Calculate order parameters
-	quantity := s.calculateOrderQuantity(decision.RecommendedLeverage)
+	id := s.intents.Propose(symbol, "SELL", "striker")
 
 	// Get current price for market order
 	ticker, err := s.client.NewListPricesService().
@@ -445,33 +673,61 @@ func (s *Striker) ExecuteSellOrder(ctx context.Context, symbol string, decision
 
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get current price")
+		s.intents.Advance(id, intent.Rejected, err.Error())
 		return
 	}
 
 	if len(ticker) == 0 {
 		logrus.Error("No price data received")
+		s.intents.Advance(id, intent.Rejected, "no price data")
 		return
 	}
 
 	currentPrice := parseFloat(ticker[0].Price)
 
+	plan, err := s.planOrder(ctx, trade.SideSell, currentPrice, decision)
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Warn("Cannot size sell order")
+		s.intents.Advance(id, intent.Rejected, err.Error())
+		return
+	}
+
 	// Apply anti-sniffer jitter before order placement
 	// Per reply_unknown.md technical specs: 5-25ms normal distribution
 	logrus.Debug("🎲 Applying anti-sniffer jitter...")
 	platform.ApplyJitter()
 
+	rules, quantity, err := s.prepareOrder(ctx, symbol, currentPrice, plan.Quantity)
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Warn("⚠️ Sell order failed pre-trade filter check")
+		s.intents.Advance(id, intent.Rejected, err.Error())
+		return
+	}
+	release, err := s.entryLimits.Take()
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Warn("⏳ Sell order over the session's entry limit")
+		s.intents.Advance(id, intent.Rejected, err.Error())
+		return
+	}
+	s.intents.Advance(id, intent.Validated, "")
+	s.intents.Advance(id, intent.Submitted, "")
+
 	// Place market sell order
 	order, err := s.client.NewCreateOrderService().
+		NewClientOrderID(binance.NewClientOrderID()).
 		Symbol(symbol).
 		Side(futures.SideTypeSell).
 		Type(futures.OrderTypeMarket).
-		Quantity(fmt.Sprintf("%.6f", quantity)).
+		Quantity(rules.FormatQuantity(quantity)).
 		Do(ctx)
 
 	if err != nil {
 		logrus.WithError(err).Error("Failed to place sell order")
+		s.intents.Advance(id, intent.Rejected, err.Error())
+		release()
 		return
 	}
+	s.intents.Advance(id, intent.Filled, strconv.FormatInt(order.OrderID, 10))
 
 	// Log successful order
 	logrus.WithFields(logrus.Fields{
@@ -479,47 +735,81 @@ func (s *Striker) ExecuteSellOrder(ctx context.Context, symbol string, decision
 		"order_id":   order.OrderID,
 		"quantity":   quantity,
 		"price":      currentPrice,
-		"leverage":   decision.RecommendedLeverage,
+		"leverage":   plan.Leverage,
 		"confidence": decision.Confidence,
 	}).Info("Sell order executed successfully")
 
 	// Set stop loss and take profit
-	s.setRiskManagement(ctx, symbol, currentPrice, decision, "SHORT")
+	s.setRiskManagement(ctx, symbol, currentPrice, plan, "SHORT")
 }
 
-func (s *Striker) calculateOrderQuantity(leverage int) float64 {
-	// This is a simplified calculation
-	// In production, this would consider:
-	// - Account balance
-	// - Risk per trade settings
-	// - Current market conditions
-	// - Position sizing rules
-
-	// For demonstration, use a fixed quantity based on leverage
-	baseQuantity := 0.001                          // Base quantity in BTC/ETH terms
-	return baseQuantity * float64(leverage) / 25.0 // Normalize to max leverage
+// planOrder sizes an entry at price with the position planner, risking a
+// share of the futures wallet at the planner's stop. The shared planner
+// applies the account-wide volatility spike throttle.
+func (s *Striker) planOrder(ctx context.Context, side trade.Side, price float64, decision *brain.TradingDecision) (trade.Plan, error) {
+	equity, available, err := s.walletBalance(ctx)
+	if err != nil {
+		return trade.Plan{}, fmt.Errorf("failed to read balance: %w", err)
+	}
+
+	req := trade.PlanRequest{
+		Side:      side,
+		Entry:     price,
+		Leverage:  decision.RecommendedLeverage,
+		Equity:    equity,
+		Available: available,
+	}
+	return s.planner.Plan(req)
 }
 
-func (s *Striker) setRiskManagement(ctx context.Context, symbol string, entryPrice float64, decision *brain.TradingDecision, side string) {
-	// Calculate stop loss and take profit levels
-	var stopLoss, takeProfit float64
+// walletBalance returns the USDT wallet balance and the part of it free for
+// new margin
+func (s *Striker) walletBalance(ctx context.Context) (float64, float64, error) {
+	balances, err := s.client.NewGetBalanceService().Do(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, b := range balances {
+		if b.Asset == "USDT" {
+			return parseFloat(b.Balance), parseFloat(b.AvailableBalance), nil
+		}
+	}
+	return 0, 0, nil
+}
 
-	if side == "LONG" {
-		stopLoss = entryPrice * 0.995   // 0.5% stop loss
-		takeProfit = entryPrice * 1.005 // 0.5% take profit
-	} else {
-		stopLoss = entryPrice * 1.005   // 0.5% stop loss
-		takeProfit = entryPrice * 0.995 // 0.5% take profit
+// prepareOrder rounds quantity to the symbol's lot step and checks the order
+// against the exchange filters so it is not rejected after being sent
+func (s *Striker) prepareOrder(ctx context.Context, symbol string, price, quantity float64) (symbolrules.Rules, float64, error) {
+	rules, err := s.rules.Ensure(ctx, symbol)
+	if err != nil {
+		return rules, 0, err
+	}
+
+	quantity = rules.RoundQuantity(quantity)
+	if err := rules.Validate(rules.RoundPrice(price), quantity); err != nil {
+		return rules, 0, err
+	}
+	return rules, quantity, nil
+}
+
+func (s *Striker) setRiskManagement(ctx context.Context, symbol string, entryPrice float64, plan trade.Plan, side string) {
+	rules, quantity, err := s.prepareOrder(ctx, symbol, entryPrice, plan.Quantity)
+	if err != nil {
+		logrus.WithError(err).WithField("symbol", symbol).Error("Failed to prepare protective orders")
+		return
 	}
+	stopLoss := rules.RoundPrice(plan.StopLoss)
+	takeProfit := rules.RoundPrice(plan.TakeProfit)
 
 	// Set stop loss order
 	// Note: STOP and TAKE_PROFIT are string literals as they're not defined in OrderType constants
 	stopOrder, err := s.client.NewCreateOrderService().
+		NewClientOrderID(binance.NewClientOrderID()).
 		Symbol(symbol).
 		Side(getOppositeSide(side)).
 		Type("STOP").
-		Quantity(fmt.Sprintf("%.6f", s.calculateOrderQuantity(decision.RecommendedLeverage))).
-		StopPrice(fmt.Sprintf("%.2f", stopLoss)).
+		Quantity(rules.FormatQuantity(quantity)).
+		StopPrice(rules.FormatPrice(stopLoss)).
 		Do(ctx)
 
 	if err != nil {
@@ -535,11 +825,12 @@ func (s *Striker) setRiskManagement(ctx context.Context, symbol string, entryPri
 
 	// Set take profit order
 	tpOrder, err := s.client.NewCreateOrderService().
+		NewClientOrderID(binance.NewClientOrderID()).
 		Symbol(symbol).
 		Side(getOppositeSide(side)).
 		Type("TAKE_PROFIT").
-		Quantity(fmt.Sprintf("%.6f", s.calculateOrderQuantity(decision.RecommendedLeverage))).
-		StopPrice(fmt.Sprintf("%.2f", takeProfit)).
+		Quantity(rules.FormatQuantity(quantity)).
+		StopPrice(rules.FormatPrice(takeProfit)).
 		Do(ctx)
 
 	if err != nil {
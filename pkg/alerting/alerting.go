@@ -1,23 +1,55 @@
 package alerting
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
+// telegramMaxLength is the Telegram limit for a single message.
+const telegramMaxLength = 4096
+
 type TelegramConfig struct {
 	Token      string
 	ChatID     string
 	Enabled    bool
 	HTTPClient *http.Client
+	// DigestWindow collates non-critical alerts sent within the window into
+	// one summary message. Zero sends every alert immediately.
+	DigestWindow time.Duration
+	// Templates renders localized message text. Nil uses the English
+	// built-ins.
+	Templates *Templates
+	// DedupWindow collapses identical error and risk alerts (same code and
+	// symbol) raised within the window into the first message plus one
+	// repeat count. Zero disables suppression.
+	DedupWindow time.Duration
+	// Namespace, when set, prefixes every message so alerts from several
+	// bots sharing a chat can be told apart.
+	Namespace string
 }
 
 type TelegramAlert struct {
 	config TelegramConfig
+
+	mu      sync.Mutex
+	pending []digestEntry
+	timer   *time.Timer
+	dedup   *suppressor
+}
+
+type digestEntry struct {
+	alertType AlertType
+	message   string
+	count     int
 }
 
 type AlertType string
@@ -36,10 +68,21 @@ func NewTelegramAlert(cfg TelegramConfig) *TelegramAlert {
 	if cfg.HTTPClient == nil {
 		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
 	}
-	return &TelegramAlert{config: cfg}
+	if cfg.Templates == nil {
+		cfg.Templates = DefaultTemplates()
+	}
+	t := &TelegramAlert{config: cfg}
+	if cfg.DedupWindow > 0 {
+		t.dedup = newSuppressor(cfg.DedupWindow, t.sendRepeats)
+	}
+	return t
 }
 
 func (t *TelegramAlert) Send(alertType AlertType, message string) error {
+	return t.dispatch(alertType, string(alertType)+"|"+message, message)
+}
+
+func (t *TelegramAlert) dispatch(alertType AlertType, fp, message string) error {
 	if !t.config.Enabled {
 		return nil
 	}
@@ -48,42 +91,153 @@ func (t *TelegramAlert) Send(alertType AlertType, message string) error {
 		return nil
 	}
 
-	emoji := ""
+	if t.dedup != nil && alertType.dedupable() && !t.dedup.allow(fp, alertType, message) {
+		return nil
+	}
+
+	return t.post(alertType, message)
+}
+
+func (t *TelegramAlert) post(alertType AlertType, message string) error {
+	if t.config.DigestWindow > 0 && !alertType.Critical() {
+		t.enqueue(alertType, message)
+		return nil
+	}
+
+	return t.deliver(alertEmoji(alertType) + " " + message)
+}
+
+// Critical alerts bypass the digest and are delivered immediately.
+func (a AlertType) Critical() bool {
+	switch a {
+	case AlertTradeExecution, AlertRiskBreach, AlertKillSwitch, AlertDailySummary:
+		return true
+	}
+	return false
+}
+
+func alertEmoji(alertType AlertType) string {
 	switch alertType {
 	case AlertTradeExecution:
-		emoji = "📊"
+		return "📊"
 	case AlertPnLPositive:
-		emoji = "💰"
+		return "💰"
 	case AlertPnLNegative:
-		emoji = "📉"
+		return "📉"
 	case AlertRiskBreach:
-		emoji = "⚠️"
+		return "⚠️"
 	case AlertSystemError:
-		emoji = "❌"
+		return "❌"
 	case AlertDailySummary:
-		emoji = "📋"
+		return "📋"
 	case AlertKillSwitch:
-		emoji = "🛑"
+		return "🛑"
+	}
+	return ""
+}
+
+func (t *TelegramAlert) enqueue(alertType AlertType, message string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.pending {
+		if t.pending[i].alertType == alertType && t.pending[i].message == message {
+			t.pending[i].count++
+			return
+		}
+	}
+	t.pending = append(t.pending, digestEntry{
+		alertType: alertType,
+		message:   message,
+		count:     1,
+	})
+
+	if t.timer == nil {
+		t.timer = time.AfterFunc(t.config.DigestWindow, func() {
+			t.Flush()
+		})
+	}
+}
+
+// sendRepeats reports how often a suppressed alert recurred in its window.
+func (t *TelegramAlert) sendRepeats(alertType AlertType, message string, repeats int) {
+	t.post(alertType, t.config.Templates.Render(MsgRepeated, map[string]interface{}{
+		"Message": message,
+		"Count":   repeats,
+		"Window":  t.config.DedupWindow,
+	}))
+}
+
+// Flush sends any collated alerts now as a single digest message, along with
+// the repeat counts of suppressed alerts.
+func (t *TelegramAlert) Flush() error {
+	if t.dedup != nil {
+		t.dedup.flush()
+	}
+
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = nil
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
 	}
+	t.mu.Unlock()
 
+	if len(pending) == 0 {
+		return nil
+	}
+	return t.deliver(formatDigest(t.config.Templates, pending, t.config.DigestWindow))
+}
+
+func formatDigest(tmpl *Templates, entries []digestEntry, window time.Duration) string {
+	total := 0
+	for _, e := range entries {
+		total += e.count
+	}
+
+	var b strings.Builder
+	b.WriteString(alertEmoji(AlertDailySummary) + " " + tmpl.Render(MsgDigestHeader, map[string]interface{}{
+		"Count":  total,
+		"Window": window,
+	}))
+	for i, e := range entries {
+		line := "\n" + alertEmoji(e.alertType) + " " + e.message
+		if e.count > 1 {
+			line += fmt.Sprintf(" (x%d)", e.count)
+		}
+		if b.Len()+len(line) > telegramMaxLength-64 {
+			b.WriteString("\n" + tmpl.Render(MsgDigestMore, map[string]interface{}{"Count": len(entries) - i}))
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func (t *TelegramAlert) deliver(text string) error {
+	if t.config.Namespace != "" {
+		text = "[" + t.config.Namespace + "] " + text
+	}
 	url := fmt.Sprintf(
 		"https://api.telegram.org/bot%s/sendMessage",
 		t.config.Token,
 	)
 
-	payload := fmt.Sprintf(
-		`{"chat_id":"%s","text":"%s %s","parse_mode":"Markdown"}`,
-		t.config.ChatID,
-		emoji,
-		message,
-	)
+	payload, err := json.Marshal(map[string]string{
+		"chat_id":    t.config.ChatID,
+		"text":       text,
+		"parse_mode": "Markdown",
+	})
+	if err != nil {
+		return err
+	}
 
-	req, err := http.NewRequest("POST", url, nil)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Body = io.NopCloser(strings.NewReader(payload))
 
 	resp, err := t.config.HTTPClient.Do(req)
 	if err != nil {
@@ -98,20 +252,71 @@ func (t *TelegramAlert) Send(alertType AlertType, message string) error {
 	return nil
 }
 
+// SendDocument uploads the file at path to the chat with caption.
+func (t *TelegramAlert) SendDocument(path, caption string) error {
+	if !t.config.Enabled || t.config.Token == "" || t.config.ChatID == "" {
+		return nil
+	}
+	if t.config.Namespace != "" {
+		caption = "[" + t.config.Namespace + "] " + caption
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("chat_id", t.config.ChatID)
+	form.WriteField("caption", caption)
+	part, err := form.CreateFormFile("document", filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendDocument", t.config.Token)
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := t.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram sendDocument returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SendTemplate renders the message for key in the configured locale and
+// sends it as alertType.
+func (t *TelegramAlert) SendTemplate(alertType AlertType, key string, vars map[string]interface{}) error {
+	message := t.config.Templates.Render(key, vars)
+	return t.dispatch(alertType, fingerprint(alertType, key, vars, message), message)
+}
+
 func (t *TelegramAlert) SendTrade(tradeInfo string) error {
 	return t.Send(AlertTradeExecution, tradeInfo)
 }
 
 func (t *TelegramAlert) SendPnL(pnl float64, symbol string) error {
-	sign := "+"
-	if pnl < 0 {
-		sign = ""
-	}
-	msg := fmt.Sprintf("%s%s on %s", sign, formatPnL(pnl), symbol)
+	vars := map[string]interface{}{"PnL": pnl, "Symbol": symbol}
 	if pnl >= 0 {
-		return t.Send(AlertPnLPositive, msg)
+		return t.SendTemplate(AlertPnLPositive, MsgPnL, vars)
 	}
-	return t.Send(AlertPnLNegative, msg)
+	return t.SendTemplate(AlertPnLNegative, MsgPnL, vars)
 }
 
 func (t *TelegramAlert) SendRiskAlert(reason string) error {
@@ -123,24 +328,31 @@ func (t *TelegramAlert) SendError(err string) error {
 }
 
 func (t *TelegramAlert) SendKillSwitch() error {
-	return t.Send(AlertKillSwitch, "🛑 KILL SWITCH ACTIVATED - TRADING HALTED")
-}
-
-func formatPnL(pnl float64) string {
-	return fmt.Sprintf("$%.2f", pnl)
+	return t.SendTemplate(AlertKillSwitch, MsgKillSwitch, nil)
 }
 
 type AuditLogger struct {
 	auditPath string
 	tradePath string
 	enabled   bool
+	namespace string
+
+	mu     sync.Mutex
+	chains map[string]*auditChain
 }
 
+// AuditConfig configures the audit and trade logs. With a SigningKey every
+// record is hash-chained and HMAC-signed for Environment, so the logs can
+// be checked with VerifyAuditLog.
 type AuditConfig struct {
 	AuditLogPath   string
 	TradeLogPath   string
 	Enabled        bool
 	DetailedTrades bool
+	SigningKey     string
+	Environment    string
+	// Namespace is added to every record when set.
+	Namespace string
 }
 
 func NewAuditLogger(cfg AuditConfig) *AuditLogger {
@@ -155,6 +367,13 @@ func NewAuditLogger(cfg AuditConfig) *AuditLogger {
 		auditPath: cfg.AuditLogPath,
 		tradePath: cfg.TradeLogPath,
 		enabled:   cfg.Enabled,
+		namespace: cfg.Namespace,
+	}
+	if cfg.SigningKey != "" {
+		logger.chains = map[string]*auditChain{
+			cfg.AuditLogPath: {key: []byte(cfg.SigningKey), env: cfg.Environment},
+			cfg.TradeLogPath: {key: []byte(cfg.SigningKey), env: cfg.Environment},
+		}
 	}
 
 	if cfg.Enabled {
@@ -176,6 +395,14 @@ func (l *AuditLogger) Log(event string, data map[string]interface{}) {
 		return
 	}
 
+	if l.namespace != "" {
+		labelled := make(map[string]interface{}, len(data)+1)
+		for k, v := range data {
+			labelled[k] = v
+		}
+		labelled["namespace"] = l.namespace
+		data = labelled
+	}
 	entry := fmt.Sprintf("[%s] %s | %v\n", time.Now().Format(time.RFC3339), event, data)
 	l.appendToFile(l.auditPath, entry)
 }
@@ -193,17 +420,46 @@ func (l *AuditLogger) LogTrade(trade map[string]interface{}) {
 		formatTradePnL(trade["pnl"]),
 		trade["status"],
 	)
+	if l.namespace != "" {
+		entry = strings.TrimSuffix(entry, "\n") + " | Namespace:" + l.namespace + "\n"
+	}
 	l.appendToFile(l.tradePath, entry)
 }
 
 func (l *AuditLogger) appendToFile(path, entry string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	chain := l.chains[path]
+	var rec signedRecord
+	if chain != nil {
+		if !chain.loaded {
+			if err := chain.resume(path); err != nil {
+				fmt.Printf("Error reading audit chain from %s: %v\n", path, err)
+			}
+		}
+		var err error
+		if rec, entry, err = chain.sign(strings.TrimSuffix(entry, "\n")); err != nil {
+			fmt.Printf("Error signing audit record: %v\n", err)
+			return
+		}
+	}
+
 	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		fmt.Printf("Error writing to log file: %v\n", err)
 		return
 	}
 	defer f.Close()
-	f.WriteString(entry)
+	if _, err := f.WriteString(entry); err != nil {
+		fmt.Printf("Error writing to log file: %v\n", err)
+		return
+	}
+	// A record that was not written must not be linked to, or every
+	// record after it would fail verification.
+	if chain != nil {
+		chain.commit(rec)
+	}
 }
 
 func formatTradePnL(pnl interface{}) string {
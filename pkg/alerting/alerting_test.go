@@ -0,0 +1,176 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type captureTransport struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (c *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var payload map[string]string
+	json.NewDecoder(req.Body).Decode(&payload)
+
+	c.mu.Lock()
+	c.texts = append(c.texts, payload["text"])
+	c.mu.Unlock()
+
+	return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func TestTelegramAlert_DigestCollatesNonCritical(t *testing.T) {
+	transport := &captureTransport{}
+	tg := NewTelegramAlert(TelegramConfig{
+		Token:        "token",
+		ChatID:       "chat",
+		Enabled:      true,
+		HTTPClient:   &http.Client{Transport: transport},
+		DigestWindow: time.Hour,
+	})
+
+	for i := 0; i < 5; i++ {
+		tg.SendError("order failed: -2019 margin is insufficient")
+	}
+	tg.SendError("websocket reconnect")
+	tg.SendRiskAlert("daily loss limit reached")
+
+	if len(transport.texts) != 1 || !strings.Contains(transport.texts[0], "daily loss limit") {
+		t.Fatalf("expected only the critical alert to be sent immediately, got %q", transport.texts)
+	}
+
+	if err := tg.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.texts) != 2 {
+		t.Fatalf("expected one digest message after flush, got %d messages", len(transport.texts))
+	}
+
+	digest := transport.texts[1]
+	if !strings.Contains(digest, "6 alerts") || !strings.Contains(digest, "(x5)") || !strings.Contains(digest, "websocket reconnect") {
+		t.Errorf("unexpected digest: %q", digest)
+	}
+}
+
+func TestTemplatesRenderLocaleWithEnglishFallback(t *testing.T) {
+	tmpl, err := LoadTemplates("../../config/templates", "pt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := tmpl.Render(MsgTradeExecuted, map[string]interface{}{
+		"Action": "BUY", "Symbol": "BTCUSDT", "Price": 60000.0, "Confidence": 0.82,
+	})
+	if got != "BUY BTCUSDT @ $60000.00 (82% de confiança)" {
+		t.Errorf("pt trade = %q", got)
+	}
+	if got := tmpl.Render(MsgPnL, map[string]interface{}{"PnL": -12.5, "Symbol": "ETHUSDT"}); got != "-$12.50 em ETHUSDT" {
+		t.Errorf("pt pnl = %q", got)
+	}
+
+	// Missing variables fall through to a locale that can render, never to an empty alert.
+	if got := tmpl.Render(MsgOrderFailed, nil); got != MsgOrderFailed {
+		t.Errorf("unrenderable template = %q", got)
+	}
+	if got := DefaultTemplates().Render(MsgKillSwitch, nil); got != "KILL SWITCH ACTIVATED - TRADING HALTED" {
+		t.Errorf("default kill switch = %q", got)
+	}
+}
+
+func TestTelegramAlert_DedupCollapsesRepeatedErrors(t *testing.T) {
+	transport := &captureTransport{}
+	tg := NewTelegramAlert(TelegramConfig{
+		Token:       "token",
+		ChatID:      "chat",
+		Enabled:     true,
+		HTTPClient:  &http.Client{Transport: transport},
+		DedupWindow: time.Hour,
+	})
+
+	for i := 0; i < 4; i++ {
+		tg.SendTemplate(AlertSystemError, MsgOrderFailed, map[string]interface{}{
+			"Error": fmt.Sprintf("binance API error -2019: Margin is insufficient (order %d)", i), "Code": int64(-2019), "Symbol": "BTCUSDT",
+		})
+	}
+	tg.SendTemplate(AlertSystemError, MsgOrderFailed, map[string]interface{}{
+		"Error": "binance API error -2019: Margin is insufficient", "Code": int64(-2019), "Symbol": "ETHUSDT",
+	})
+
+	if len(transport.texts) != 2 {
+		t.Fatalf("expected one alert per symbol, got %q", transport.texts)
+	}
+
+	if err := tg.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.texts) != 3 || !strings.Contains(transport.texts[2], "(order 0) (repeated 3 more times in 1h0m0s)") {
+		t.Fatalf("expected a single repeat summary for BTCUSDT, got %q", transport.texts)
+	}
+}
+
+func TestAuditLogger_ChainDetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	os.WriteFile(path, []byte("[2026-01-01T00:00:00Z] STARTUP | map[]\n"), 0644)
+	cfg := AuditConfig{AuditLogPath: path, TradeLogPath: filepath.Join(dir, "trades.log"), Enabled: true, SigningKey: "k", Environment: "mainnet"}
+
+	l := NewAuditLogger(cfg)
+	l.Log("TRADE_REJECTED", map[string]interface{}{"symbol": "BTCUSDT"})
+	l.Log("TRADE_REJECTED", map[string]interface{}{"symbol": "ETHUSDT"})
+	// A restarted logger continues the same chain.
+	NewAuditLogger(cfg).Log("RISK_MODE_SWITCHED", map[string]interface{}{"to": "aggressive"})
+
+	v, err := VerifyAuditLog(path, "k", "mainnet")
+	if err != nil || v.Records != 3 || v.Unsigned != 1 || v.LastSeq != 3 {
+		t.Fatalf("verification = %+v, %v", v, err)
+	}
+	if _, err := VerifyAuditLog(path, "k", "testnet"); err == nil {
+		t.Fatal("a log signed for mainnet verified as testnet")
+	}
+
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), "ETHUSDT", "SOLUSDT", 1)), 0644)
+	if _, err := VerifyAuditLog(path, "k", "mainnet"); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("edited record not caught: %v", err)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	os.WriteFile(path, []byte(lines[0]+lines[1]+lines[3]), 0644)
+	if _, err := VerifyAuditLog(path, "k", "mainnet"); err == nil {
+		t.Fatal("removed record not caught")
+	}
+}
+
+func TestAuditLogger_ChainSurvivesOddEntriesAndFailedWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	cfg := AuditConfig{AuditLogPath: path, TradeLogPath: filepath.Join(dir, "trades.log"), Enabled: true, SigningKey: "k", Environment: "mainnet"}
+	l := NewAuditLogger(cfg)
+
+	// An entry holding a newline stays one record.
+	l.Log("WEBHOOK", map[string]interface{}{"symbol": "BTCUSDT\n[forged] ALL CLEAR"})
+
+	// A record that fails to write does not break the chain.
+	data, _ := os.ReadFile(path)
+	os.Remove(path)
+	os.Mkdir(path, 0755)
+	l.Log("LOST", nil)
+	os.Remove(path)
+	os.WriteFile(path, data, 0644)
+	l.Log("TRADE_REJECTED", map[string]interface{}{"symbol": "ETHUSDT"})
+
+	v, err := VerifyAuditLog(path, "k", "mainnet")
+	if err != nil || v.Records != 2 || v.Unsigned != 0 || v.LastSeq != 2 {
+		t.Fatalf("verification = %+v, %v", v, err)
+	}
+}
@@ -0,0 +1,214 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// commandPollSeconds is how long each getUpdates call waits for a message.
+const commandPollSeconds = 25
+
+// CommandHandler answers a chat command. It reports false for text that is
+// not a command it knows, which gets no reply.
+type CommandHandler func(text string) (reply string, ok bool)
+
+// Button is an inline keyboard button. Pressing it sends Data back to the
+// handler as if it had been typed.
+type Button struct {
+	Text string
+	Data string
+}
+
+// Reply is a command answer, optionally with rows of inline buttons.
+type Reply struct {
+	Text     string
+	Keyboard [][]Button
+}
+
+// Command is a chat command or a pressed button, with who sent it.
+type Command struct {
+	Text string
+	From string
+}
+
+// InteractiveHandler answers commands with replies that may carry buttons.
+type InteractiveHandler func(cmd Command) (Reply, bool)
+
+type telegramUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+func (u *telegramUser) name() string {
+	if u == nil {
+		return "unknown"
+	}
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	return strconv.FormatInt(u.ID, 10)
+}
+
+type telegramMessage struct {
+	MessageID int64         `json:"message_id"`
+	Text      string        `json:"text"`
+	From      *telegramUser `json:"from"`
+	Chat      struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+}
+
+type telegramUpdate struct {
+	UpdateID      int64            `json:"update_id"`
+	Message       *telegramMessage `json:"message"`
+	CallbackQuery *struct {
+		ID      string           `json:"id"`
+		From    *telegramUser    `json:"from"`
+		Message *telegramMessage `json:"message"`
+		Data    string           `json:"data"`
+	} `json:"callback_query"`
+}
+
+// Commands long-polls the bot for messages and answers those sent from the
+// configured chat with handle's reply, until ctx is cancelled. Messages from
+// any other chat are ignored, so only the alert channel controls the bot.
+func (t *TelegramAlert) Commands(ctx context.Context, handle CommandHandler) error {
+	return t.Interactive(ctx, func(cmd Command) (Reply, bool) {
+		text, ok := handle(cmd.Text)
+		return Reply{Text: text}, ok
+	})
+}
+
+// Interactive is Commands for handlers that answer with inline keyboards.
+// A pressed button is handled like a command and its reply replaces the
+// message the button was on, so a keyboard can only be used once.
+func (t *TelegramAlert) Interactive(ctx context.Context, handle InteractiveHandler) error {
+	if !t.config.Enabled || t.config.Token == "" || t.config.ChatID == "" {
+		return nil
+	}
+
+	// Long polls outlive the alert client's timeout.
+	client := &http.Client{Transport: t.config.HTTPClient.Transport}
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := t.getUpdates(ctx, client, offset)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if q := u.CallbackQuery; q != nil {
+				if q.Message == nil || !t.fromChat(q.Message) {
+					continue
+				}
+				t.call("answerCallbackQuery", map[string]interface{}{"callback_query_id": q.ID})
+				if reply, ok := handle(Command{Text: q.Data, From: q.From.name()}); ok {
+					t.reply("editMessageText", reply, map[string]interface{}{"message_id": q.Message.MessageID})
+				}
+				continue
+			}
+			if u.Message == nil || !t.fromChat(u.Message) {
+				continue
+			}
+			if reply, ok := handle(Command{Text: u.Message.Text, From: u.Message.From.name()}); ok {
+				if len(reply.Keyboard) == 0 {
+					t.deliver(reply.Text)
+					continue
+				}
+				t.reply("sendMessage", reply, nil)
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+func (t *TelegramAlert) fromChat(m *telegramMessage) bool {
+	return strconv.FormatInt(m.Chat.ID, 10) == t.config.ChatID
+}
+
+// reply sends or edits a message with reply's text and keyboard.
+func (t *TelegramAlert) reply(method string, reply Reply, fields map[string]interface{}) error {
+	payload := map[string]interface{}{
+		"chat_id": t.config.ChatID,
+		"text":    reply.Text,
+	}
+	for k, v := range fields {
+		payload[k] = v
+	}
+	rows := make([][]map[string]string, 0, len(reply.Keyboard))
+	for _, row := range reply.Keyboard {
+		buttons := make([]map[string]string, 0, len(row))
+		for _, b := range row {
+			buttons = append(buttons, map[string]string{"text": b.Text, "callback_data": b.Data})
+		}
+		rows = append(rows, buttons)
+	}
+	payload["reply_markup"] = map[string]interface{}{"inline_keyboard": rows}
+	return t.call(method, payload)
+}
+
+// call posts payload to a Bot API method.
+func (t *TelegramAlert) call(method string, payload map[string]interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/%s", t.config.Token, method)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram %s returned status %d", method, resp.StatusCode)
+	}
+	return nil
+}
+
+func (t *TelegramAlert) getUpdates(ctx context.Context, client *http.Client, offset int64) ([]telegramUpdate, error) {
+	url := fmt.Sprintf(
+		"https://api.telegram.org/bot%s/getUpdates?timeout=%d&offset=%d&allowed_updates=%%5B%%22message%%22%%2C%%22callback_query%%22%%5D",
+		t.config.Token, commandPollSeconds, offset,
+	)
+	ctx, cancel := context.WithTimeout(ctx, (commandPollSeconds+10)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		OK     bool             `json:"ok"`
+		Result []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if !body.OK {
+		return nil, fmt.Errorf("telegram getUpdates returned status %d", resp.StatusCode)
+	}
+	return body.Result, nil
+}
@@ -0,0 +1,90 @@
+package alerting
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// suppressor collapses alerts with the same fingerprint raised within window
+// of the first one. The first alert goes out as usual; repeats are only
+// counted and reported once, as a single summary when the window closes.
+type suppressor struct {
+	window time.Duration
+	emit   func(alertType AlertType, message string, repeats int)
+
+	mu     sync.Mutex
+	active map[string]*repeated
+}
+
+type repeated struct {
+	alertType AlertType
+	message   string
+	count     int
+	timer     *time.Timer
+}
+
+func newSuppressor(window time.Duration, emit func(AlertType, string, int)) *suppressor {
+	return &suppressor{window: window, emit: emit, active: make(map[string]*repeated)}
+}
+
+// allow reports whether an alert should be sent now, or records it as a
+// repeat of one already sent within the window.
+func (s *suppressor) allow(fingerprint string, alertType AlertType, message string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.active[fingerprint]; ok {
+		r.count++
+		return false
+	}
+
+	r := &repeated{alertType: alertType, message: message}
+	r.timer = time.AfterFunc(s.window, func() { s.close(fingerprint) })
+	s.active[fingerprint] = r
+	return true
+}
+
+func (s *suppressor) close(fingerprint string) {
+	s.mu.Lock()
+	r, ok := s.active[fingerprint]
+	delete(s.active, fingerprint)
+	s.mu.Unlock()
+
+	if ok && r.count > 0 {
+		s.emit(r.alertType, r.message, r.count)
+	}
+}
+
+// flush closes every open window now, reporting pending repeat counts.
+func (s *suppressor) flush() {
+	s.mu.Lock()
+	active := s.active
+	s.active = make(map[string]*repeated)
+	s.mu.Unlock()
+
+	for _, r := range active {
+		r.timer.Stop()
+		if r.count > 0 {
+			s.emit(r.alertType, r.message, r.count)
+		}
+	}
+}
+
+// dedupable reports whether alerts of this type are error conditions that
+// tend to repeat every cycle while the cause persists.
+func (a AlertType) dedupable() bool {
+	return a == AlertSystemError || a == AlertRiskBreach
+}
+
+// fingerprint identifies a templated alert by its key, exchange error code
+// and symbol, so the same rejection on the same symbol matches even when the
+// rendered text differs (order IDs, timestamps). Without a code the rendered
+// message stands in for it.
+func fingerprint(alertType AlertType, key string, vars map[string]interface{}, message string) string {
+	code, ok := vars["Code"]
+	if !ok || code == nil || code == int64(0) {
+		return fmt.Sprintf("%s|%s|%v|%s", alertType, key, vars["Symbol"], message)
+	}
+	return fmt.Sprintf("%s|%s|%v|%v", alertType, key, vars["Symbol"], code)
+}
@@ -0,0 +1,143 @@
+package alerting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is used when no locale is configured and as the fallback for
+// keys a locale file does not define.
+const DefaultLocale = "en"
+
+// Template keys for the messages the bot sends.
+const (
+	MsgTradeExecuted  = "trade.executed"
+	MsgTradeWatched   = "trade.watched"
+	MsgTradeMismatch  = "trade.mismatch"
+	MsgOrderFailed    = "order.failed"
+	MsgDailyLossLimit = "risk.daily_loss_limit"
+	MsgKillSwitch     = "kill_switch.activated"
+	MsgInstanceLock   = "instance.conflict"
+	MsgPnL            = "pnl"
+	MsgDigestHeader   = "digest.header"
+	MsgDigestMore     = "digest.more"
+	MsgRepeated       = "repeated"
+)
+
+// builtinTemplates keep alerts readable when no template files are installed.
+var builtinTemplates = map[string]string{
+	MsgTradeExecuted:  `{{.Action}} {{.Symbol}} @ {{usd .Price}} ({{pct .Confidence}} confidence)`,
+	MsgTradeWatched:   `[watch] would {{.Action}} {{.Symbol}} @ {{usd .Price}} ({{pct .Confidence}} confidence)`,
+	MsgTradeMismatch:  `Alerted {{.Side}} {{.Symbol}} does not match the exchange: {{.Detail}}`,
+	MsgOrderFailed:    `Order failed: {{.Error}}`,
+	MsgDailyLossLimit: `Daily loss limit reached`,
+	MsgKillSwitch:     `KILL SWITCH ACTIVATED - TRADING HALTED`,
+	MsgInstanceLock:   `Account lock conflict on {{.Instance}}: {{.Detail}}`,
+	MsgPnL:            `{{signed .PnL}} on {{.Symbol}}`,
+	MsgDigestHeader:   `{{.Count}} alerts in the last {{.Window}}`,
+	MsgDigestMore:     `… and {{.Count}} more`,
+	MsgRepeated:       `{{.Message}} (repeated {{.Count}} more times in {{.Window}})`,
+}
+
+var templateFuncs = template.FuncMap{
+	"usd": func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"pct": func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) },
+	"signed": func(v float64) string {
+		if v < 0 {
+			return fmt.Sprintf("-$%.2f", -v)
+		}
+		return fmt.Sprintf("+$%.2f", v)
+	},
+}
+
+// Templates renders notification text for a locale. Lookups fall back from
+// the configured locale to English and then to the built-in defaults.
+type Templates struct {
+	locale  string
+	locales map[string]map[string]*template.Template
+}
+
+// DefaultTemplates returns English templates compiled from the built-ins.
+func DefaultTemplates() *Templates {
+	t := &Templates{locale: DefaultLocale, locales: make(map[string]map[string]*template.Template)}
+	set, err := compileSet(DefaultLocale, builtinTemplates)
+	if err != nil {
+		panic(err)
+	}
+	t.locales[""] = set
+	return t
+}
+
+// LoadTemplates reads every <locale>.yaml file in dir, each a flat map of
+// message key to template, and selects locale for rendering.
+func LoadTemplates(dir, locale string) (*Templates, error) {
+	t := DefaultTemplates()
+	if locale != "" {
+		t.locale = locale
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var raw map[string]string
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		set, err := compileSet(name, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		t.locales[name] = set
+	}
+
+	if _, ok := t.locales[t.locale]; !ok && t.locale != DefaultLocale {
+		return t, fmt.Errorf("no templates for locale %q in %s", t.locale, dir)
+	}
+	return t, nil
+}
+
+// Locale returns the locale messages are rendered in.
+func (t *Templates) Locale() string {
+	return t.locale
+}
+
+// Render executes the template for key with vars. Unknown keys render as
+// the key itself so a missing translation never drops an alert.
+func (t *Templates) Render(key string, vars map[string]interface{}) string {
+	for _, locale := range []string{t.locale, DefaultLocale, ""} {
+		tmpl, ok := t.locales[locale][key]
+		if !ok {
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, vars); err != nil {
+			continue
+		}
+		return b.String()
+	}
+	return key
+}
+
+func compileSet(locale string, raw map[string]string) (map[string]*template.Template, error) {
+	set := make(map[string]*template.Template, len(raw))
+	for key, text := range raw {
+		tmpl, err := template.New(locale + "/" + key).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		set[key] = tmpl
+	}
+	return set, nil
+}
@@ -0,0 +1,193 @@
+// Package apiweight accounts the Binance request weight spent per minute by
+// each component of the bot. Components with a quota are refused once they
+// have spent their share, and everything but the priority components stops
+// short of the account limit, so a busy poller cannot use up the weight
+// order placement needs.
+package apiweight
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned for a request the budget refused.
+var ErrQuotaExceeded = errors.New("api weight quota exceeded")
+
+// Components of the bot that spend weight.
+const (
+	Executor = "executor"
+	Screener = "screener"
+	Klines   = "klines"
+	Monitors = "monitors"
+)
+
+// Config sets the per-minute Limit and the share of it each component may
+// spend. Reserve is the share of Limit only Priority components may use.
+type Config struct {
+	Limit    int
+	Quotas   map[string]float64
+	Reserve  float64
+	Priority []string
+}
+
+// Usage is one component's spend in the current minute.
+type Usage struct {
+	Used     int
+	Quota    int
+	Requests int64
+	Rejected int64
+}
+
+type Stats struct {
+	Limit int
+	// Used is the larger of the weight spent through the budget and the
+	// weight the exchange reported, which also counts other clients on the
+	// same IP.
+	Used       int
+	Reported   int
+	Components map[string]Usage
+}
+
+type Budget struct {
+	cfg      Config
+	mu       sync.Mutex
+	window   time.Time
+	reported int
+	usage    map[string]*Usage
+	now      func() time.Time
+}
+
+func New(cfg Config) *Budget {
+	if cfg.Limit <= 0 {
+		cfg.Limit = 2400
+	}
+	if cfg.Quotas == nil {
+		cfg.Quotas = map[string]float64{
+			Screener: 0.25,
+			Klines:   0.25,
+			Monitors: 0.25,
+		}
+	}
+	if cfg.Reserve <= 0 {
+		cfg.Reserve = 0.2
+	}
+	if len(cfg.Priority) == 0 {
+		cfg.Priority = []string{Executor}
+	}
+
+	return &Budget{
+		cfg:   cfg,
+		usage: make(map[string]*Usage),
+		now:   time.Now,
+	}
+}
+
+// Acquire charges weight to component, or refuses it with ErrQuotaExceeded
+// when that would take the component past its quota or a non-priority
+// component into the reserve. Priority components are only counted.
+func (b *Budget) Acquire(component string, weight int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	u := b.component(component)
+	u.Requests++
+
+	if !b.priority(component) {
+		if u.Quota > 0 && u.Used+weight > u.Quota {
+			u.Rejected++
+			return fmt.Errorf("%w: %s spent %d of its %d this minute", ErrQuotaExceeded, component, u.Used, u.Quota)
+		}
+		ceiling := b.cfg.Limit - int(float64(b.cfg.Limit)*b.cfg.Reserve)
+		if used := b.used(); used+weight > ceiling {
+			u.Rejected++
+			return fmt.Errorf("%w: %d of %d used this minute, rest is reserved", ErrQuotaExceeded, used, b.cfg.Limit)
+		}
+	}
+
+	u.Used += weight
+	return nil
+}
+
+// Observe records the used weight the exchange reported for the current
+// minute.
+func (b *Budget) Observe(used int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	if used > b.reported {
+		b.reported = used
+	}
+}
+
+// Utilization is the share of Limit used in the current minute.
+func (b *Budget) Utilization() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	return float64(b.used()) / float64(b.cfg.Limit)
+}
+
+func (b *Budget) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll()
+	s := Stats{
+		Limit:      b.cfg.Limit,
+		Used:       b.used(),
+		Reported:   b.reported,
+		Components: make(map[string]Usage, len(b.usage)),
+	}
+	for name, u := range b.usage {
+		s.Components[name] = *u
+	}
+	return s
+}
+
+// roll starts a new window at each minute, as the exchange resets its
+// counter.
+func (b *Budget) roll() {
+	window := b.now().Truncate(time.Minute)
+	if window.Equal(b.window) {
+		return
+	}
+	b.window = window
+	b.reported = 0
+	for _, u := range b.usage {
+		u.Used = 0
+	}
+}
+
+func (b *Budget) component(name string) *Usage {
+	u, ok := b.usage[name]
+	if !ok {
+		u = &Usage{Quota: int(float64(b.cfg.Limit) * b.cfg.Quotas[name])}
+		b.usage[name] = u
+	}
+	return u
+}
+
+func (b *Budget) used() int {
+	total := 0
+	for _, u := range b.usage {
+		total += u.Used
+	}
+	if b.reported > total {
+		return b.reported
+	}
+	return total
+}
+
+func (b *Budget) priority(component string) bool {
+	for _, name := range b.cfg.Priority {
+		if name == component {
+			return true
+		}
+	}
+	return false
+}
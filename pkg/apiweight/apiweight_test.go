@@ -0,0 +1,40 @@
+package apiweight
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudget_QuotasAndReserve(t *testing.T) {
+	b := New(Config{Limit: 100, Quotas: map[string]float64{Screener: 0.4, Monitors: 0.5}, Reserve: 0.2})
+	now := time.Date(2026, 1, 1, 12, 0, 10, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	if err := b.Acquire(Screener, 40); err != nil {
+		t.Fatalf("within quota: %v", err)
+	}
+	if err := b.Acquire(Screener, 1); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("past quota: %v", err)
+	}
+	if err := b.Acquire(Monitors, 41); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("into the reserve: %v", err)
+	}
+	if err := b.Acquire(Monitors, 40); err != nil {
+		t.Fatalf("up to the reserve: %v", err)
+	}
+	if err := b.Acquire(Executor, 15); err != nil {
+		t.Fatalf("the executor should reach the reserve: %v", err)
+	}
+
+	b.Observe(97)
+	stats := b.Stats()
+	if stats.Used != 97 || stats.Components[Screener].Rejected != 1 || stats.Components[Executor].Used != 15 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.Acquire(Screener, 40); err != nil {
+		t.Fatalf("the quota should reset with the minute: %v", err)
+	}
+}
@@ -442,6 +442,7 @@ Decision criteria:
 - CVD divergence present
 - Volatility within acceptable range
 - No high-impact news events
+- Candlestick patterns (engulfing, pin_bar, three_bar_reversal: positive bullish, negative bearish; inside_bar: consolidation) agree with the side
 
 Provide your decision in JSON format:
 {
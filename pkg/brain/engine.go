@@ -23,6 +23,10 @@ type BrainConfig struct {
 	RecoveryInterval       time.Duration `json:"recovery_interval"`
 	DecisionTimeout        time.Duration `json:"decision_timeout"`
 	MaxConcurrentDecisions int           `json:"max_concurrent_decisions"`
+
+	// DecisionPrompt replaces the provider's built-in trading decision
+	// prompt when set; see PromptTemplate
+	DecisionPrompt string `json:"decision_prompt,omitempty"`
 }
 
 // BrainEngine is the main AI engine that coordinates all brain functions
@@ -32,6 +36,7 @@ type BrainEngine struct {
 	client   *futures.Client
 
 	config BrainConfig
+	prompt *PromptTemplate
 
 	// State management
 	mu           sync.RWMutex
@@ -67,6 +72,14 @@ func NewBrainEngine(client *futures.Client, feedback interface{}, config BrainCo
 		ComplexityThreshold: 500,
 	}
 
+	var prompt *PromptTemplate
+	if config.DecisionPrompt != "" {
+		var err error
+		if prompt, err = ParsePrompt(config.DecisionPrompt); err != nil {
+			return nil, fmt.Errorf("invalid decision prompt: %w", err)
+		}
+	}
+
 	provider, err := NewLLMProviderWithConfig(providerConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize provider: %w", err)
@@ -77,6 +90,7 @@ func NewBrainEngine(client *futures.Client, feedback interface{}, config BrainCo
 		feedback:     feedback,
 		client:       client,
 		config:       config,
+		prompt:       prompt,
 		shutdownChan: make(chan struct{}),
 		startTime:    time.Now(),
 	}
@@ -158,13 +172,50 @@ func (e *BrainEngine) startBackgroundMonitoring() {
 
 // MakeTradingDecision makes a real-time trading decision
 func (e *BrainEngine) MakeTradingDecision(ctx context.Context, signalData interface{}) (*TradingDecision, error) {
+	start := time.Now()
 	e.mu.Lock()
 	e.decisionsMade++
 	e.mu.Unlock()
 
 	// Create decision prompt
-	prompt := e.provider.TradingDecisionPrompt(signalData)
+	prompt, err := e.DecisionPrompt(signalData)
+	if err != nil {
+		return nil, err
+	}
+
+	decision, err := e.DecideWithPrompt(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"decision":   decision.Decision,
+		"confidence": decision.Confidence,
+		"symbol":     decision.Symbol,
+		"reasoning":  decision.Reasoning,
+		"latency_ms": time.Since(start).Milliseconds(),
+	}).Info("GOBOT LFM2.5 trading decision generated")
+
+	return decision, nil
+}
 
+// DecisionPrompt renders the trading decision prompt for signalData, from
+// the configured template or the provider's built-in prompt
+func (e *BrainEngine) DecisionPrompt(signalData interface{}) (string, error) {
+	if e.prompt == nil {
+		return e.provider.TradingDecisionPrompt(signalData), nil
+	}
+	prompt, err := e.prompt.Render(signalData)
+	if err != nil {
+		return "", fmt.Errorf("failed to render decision prompt: %w", err)
+	}
+	return prompt, nil
+}
+
+// DecideWithPrompt asks for a trading decision on an already rendered
+// prompt. Prompt experiments use it to try templates that are not live;
+// it does not count towards the decisions made.
+func (e *BrainEngine) DecideWithPrompt(ctx context.Context, prompt string) (*TradingDecision, error) {
 	// Generate decision with timeout - faster for LFM2.5
 	ctx, cancel := context.WithTimeout(ctx, e.config.DecisionTimeout)
 	defer cancel()
@@ -178,18 +229,17 @@ func (e *BrainEngine) MakeTradingDecision(ctx context.Context, signalData interf
 	if err := e.validateDecision(&decision); err != nil {
 		return nil, fmt.Errorf("invalid trading decision: %w", err)
 	}
-
-	logrus.WithFields(logrus.Fields{
-		"decision":   decision.Decision,
-		"confidence": decision.Confidence,
-		"symbol":     decision.Symbol,
-		"reasoning":  decision.Reasoning,
-		"latency_ms": time.Since(ctx.Value("start_time").(time.Time)).Milliseconds(),
-	}).Info("GOBOT LFM2.5 trading decision generated")
-
 	return &decision, nil
 }
 
+// Complete sends a prompt of the caller's own to the provider and decodes
+// the JSON answer into response, bounded by the decision timeout.
+func (e *BrainEngine) Complete(ctx context.Context, prompt string, response interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.DecisionTimeout)
+	defer cancel()
+	return e.provider.GenerateStructuredResponse(ctx, prompt, response)
+}
+
 // AnalyzeMarket performs comprehensive market analysis
 func (e *BrainEngine) AnalyzeMarket(ctx context.Context, marketData interface{}) (*MarketAnalysis, error) {
 	// Create analysis prompt
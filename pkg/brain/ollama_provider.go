@@ -365,6 +365,7 @@ Decision criteria for LFM2.5:
 - Volatility within optimal range (0.5-2.0%%)
 - No high-impact news events
 - Market microstructure favorable
+- Candlestick patterns (engulfing, pin_bar, three_bar_reversal: positive bullish, negative bearish; inside_bar: consolidation) agree with the side
 
 Provide your decision in JSON format:
 {
@@ -0,0 +1,64 @@
+package brain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// PromptTemplate is a trading decision prompt written as a text/template.
+// Templates see the signal as {{.Signal}} (its JSON encoding) and its
+// fields as {{.Data.rsi}}, {{.Data.symbol}} and so on. The reply must still
+// be the JSON decision MakeTradingDecision parses, so a template should ask
+// for it the way the built-in prompt does.
+type PromptTemplate struct {
+	text string
+	tmpl *template.Template
+}
+
+// ParsePrompt compiles a decision prompt template. Fields the signal lacks
+// fail at render time rather than rendering as "<no value>".
+func ParsePrompt(text string) (*PromptTemplate, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty prompt template")
+	}
+	tmpl, err := template.New("decision").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &PromptTemplate{text: text, tmpl: tmpl}, nil
+}
+
+func (p *PromptTemplate) String() string {
+	return p.text
+}
+
+// Render fills the template in with signalData.
+func (p *PromptTemplate) Render(signalData interface{}) (string, error) {
+	dataJSON, err := json.Marshal(signalData)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	err = p.tmpl.Execute(&b, struct {
+		Signal string
+		Data   interface{}
+	}{string(dataJSON), signalData})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// SignalData is the signal a setup is confirmed on: its symbol, side and
+// indicator values.
+func SignalData(symbol, side string, inputs map[string]float64) map[string]interface{} {
+	data := make(map[string]interface{}, len(inputs)+2)
+	for name, v := range inputs {
+		data[name] = v
+	}
+	data["symbol"] = symbol
+	data["side"] = side
+	return data
+}
@@ -0,0 +1,81 @@
+package callpolicy
+
+import (
+	"context"
+	"time"
+)
+
+// Call is a class of external call that shares one timeout.
+type Call string
+
+const (
+	Order     Call = "order"
+	Klines    Call = "klines"
+	LLM       Call = "llm"
+	Account   Call = "account"
+	Exchange  Call = "exchange"
+	Reconcile Call = "reconcile"
+)
+
+var defaultTimeouts = map[Call]time.Duration{
+	Order:     5 * time.Second,
+	Klines:    10 * time.Second,
+	LLM:       15 * time.Second,
+	Account:   10 * time.Second,
+	Exchange:  10 * time.Second,
+	Reconcile: 30 * time.Second,
+}
+
+// Policy derives bounded contexts for external calls from the context of the
+// loop making them, so cancelling the loop cancels the call in flight and a
+// hung endpoint cannot stall the loop past the call's timeout.
+type Policy struct {
+	timeouts map[Call]time.Duration
+}
+
+// New builds a policy from per-call timeouts in milliseconds, keyed by Call
+// name. Unset or non-positive entries keep their default.
+func New(timeoutsMS map[string]int) *Policy {
+	timeouts := make(map[Call]time.Duration, len(defaultTimeouts))
+	for call, d := range defaultTimeouts {
+		timeouts[call] = d
+	}
+	for call, ms := range timeoutsMS {
+		if ms > 0 {
+			timeouts[Call(call)] = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return &Policy{timeouts: timeouts}
+}
+
+// Default returns a policy with the built-in timeouts.
+func Default() *Policy {
+	return New(nil)
+}
+
+// Timeout returns the timeout for call, or zero for an unknown class.
+// A nil policy uses the defaults.
+func (p *Policy) Timeout(call Call) time.Duration {
+	if p == nil {
+		return defaultTimeouts[call]
+	}
+	return p.timeouts[call]
+}
+
+// Context returns a child of parent that is cancelled with it or when the
+// call's timeout elapses. A parent deadline that is sooner is kept. Unknown
+// call classes only inherit parent's cancellation.
+func (p *Policy) Context(parent context.Context, call Call) (context.Context, context.CancelFunc) {
+	timeout := p.Timeout(call)
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// Do runs fn with a context bounded by call's timeout.
+func (p *Policy) Do(parent context.Context, call Call, fn func(ctx context.Context) error) error {
+	ctx, cancel := p.Context(parent, call)
+	defer cancel()
+	return fn(ctx)
+}
@@ -0,0 +1,35 @@
+package callpolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestContextBoundedByTimeoutAndParent(t *testing.T) {
+	p := New(map[string]int{"order": 20})
+	if p.Timeout(Klines) != 10*time.Second {
+		t.Fatalf("klines timeout = %v, want default 10s", p.Timeout(Klines))
+	}
+
+	ctx, cancel := p.Context(context.Background(), Order)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("order context outlived its 20ms timeout")
+	}
+
+	parent, stop := context.WithCancel(context.Background())
+	ctx, cancel = p.Context(parent, LLM)
+	defer cancel()
+	stop()
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("cancelling the loop context did not cancel the call: %v", ctx.Err())
+	}
+
+	var nilPolicy *Policy
+	if nilPolicy.Timeout(Order) != 5*time.Second {
+		t.Fatal("nil policy should fall back to the defaults")
+	}
+}
@@ -0,0 +1,190 @@
+// Package entrylimit caps how many new positions open in a rolling window,
+// with a cap per trading session. A cluster of signals in a quiet session,
+// or in the first minutes after a pause lifts, then opens a few positions
+// instead of filling the account at once.
+package entrylimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Session is a daily UTC time range with its own cap: at most MaxEntries
+// new positions within any Window. Start and End are offsets from
+// midnight; an End before Start runs past midnight. Zero MaxEntries leaves
+// the session unlimited.
+type Session struct {
+	Name       string
+	Start      time.Duration
+	End        time.Duration
+	MaxEntries int
+	Window     time.Duration
+}
+
+func (s Session) covers(t time.Time) bool {
+	t = t.UTC()
+	of := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if s.End <= s.Start {
+		return of >= s.Start || of < s.End
+	}
+	return of >= s.Start && of < s.End
+}
+
+// Config lists the Sessions, the first covering a time winning. Default
+// caps the hours no session covers.
+type Config struct {
+	Sessions []Session
+	Default  Session
+}
+
+// LimitError is returned for an entry over its session's cap. Next is when
+// the oldest entry in the window ages out.
+type LimitError struct {
+	Session string
+	Max     int
+	Window  time.Duration
+	Next    time.Time
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%d entries in %s already opened during %s; next at %s",
+		e.Max, e.Window, e.Session, e.Next.UTC().Format("15:04:05"))
+}
+
+// Status is the cap applying now and how much of it is used.
+type Status struct {
+	Session string         `json:"session"`
+	Max     int            `json:"max_entries"`
+	Window  string         `json:"window"`
+	Used    int            `json:"used"`
+	Taken   map[string]int `json:"taken"`
+	Denied  map[string]int `json:"denied"`
+}
+
+// Limiter hands out entry slots. A nil *Limiter allows every entry.
+type Limiter struct {
+	cfg    Config
+	mu     sync.Mutex
+	slots  []*time.Time
+	taken  map[string]int
+	denied map[string]int
+	now    func() time.Time
+}
+
+func New(cfg Config) *Limiter {
+	if cfg.Default.Name == "" {
+		cfg.Default.Name = "default"
+	}
+	for i := range cfg.Sessions {
+		if cfg.Sessions[i].Window <= 0 {
+			cfg.Sessions[i].Window = 10 * time.Minute
+		}
+	}
+	if cfg.Default.Window <= 0 {
+		cfg.Default.Window = 10 * time.Minute
+	}
+	return &Limiter{cfg: cfg, taken: make(map[string]int), denied: make(map[string]int), now: time.Now}
+}
+
+// Session returns the session covering t.
+func (l *Limiter) Session(t time.Time) Session {
+	for _, s := range l.cfg.Sessions {
+		if s.covers(t) {
+			return s
+		}
+	}
+	return l.cfg.Default
+}
+
+// Take reserves a slot for one new position, or returns a *LimitError. The
+// returned func gives the slot back, for an entry that did not open, and
+// may be called any number of times. Every entry counts against the window
+// whichever session it opened in, so a session change is no burst.
+func (l *Limiter) Take() (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	s := l.Session(now)
+	l.prune(now)
+	if s.MaxEntries > 0 {
+		var inWindow []*time.Time
+		for _, at := range l.slots {
+			if now.Sub(*at) < s.Window {
+				inWindow = append(inWindow, at)
+			}
+		}
+		if len(inWindow) >= s.MaxEntries {
+			l.denied[s.Name]++
+			return nil, &LimitError{
+				Session: s.Name,
+				Max:     s.MaxEntries,
+				Window:  s.Window,
+				Next:    inWindow[len(inWindow)-s.MaxEntries].Add(s.Window),
+			}
+		}
+	}
+
+	at := &now
+	l.slots = append(l.slots, at)
+	l.taken[s.Name]++
+	var once sync.Once
+	return func() { once.Do(func() { l.release(at, s.Name) }) }, nil
+}
+
+func (l *Limiter) release(at *time.Time, session string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, slot := range l.slots {
+		if slot == at {
+			l.slots = append(l.slots[:i], l.slots[i+1:]...)
+			l.taken[session]--
+			return
+		}
+	}
+}
+
+// prune drops slots older than every window.
+func (l *Limiter) prune(now time.Time) {
+	longest := l.cfg.Default.Window
+	for _, s := range l.cfg.Sessions {
+		if s.Window > longest {
+			longest = s.Window
+		}
+	}
+	keep := l.slots[:0]
+	for _, at := range l.slots {
+		if now.Sub(*at) < longest {
+			keep = append(keep, at)
+		}
+	}
+	l.slots = keep
+}
+
+// Status reports the session applying now, how many entries its window
+// holds, and the entries taken and denied per session since start.
+func (l *Limiter) Status() Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	s := l.Session(now)
+	st := Status{Session: s.Name, Max: s.MaxEntries, Window: s.Window.String(),
+		Taken: make(map[string]int, len(l.taken)), Denied: make(map[string]int, len(l.denied))}
+	for _, at := range l.slots {
+		if now.Sub(*at) < s.Window {
+			st.Used++
+		}
+	}
+	for k, v := range l.taken {
+		st.Taken[k] = v
+	}
+	for k, v := range l.denied {
+		st.Denied[k] = v
+	}
+	return st
+}
@@ -0,0 +1,76 @@
+package entrylimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func limiter(at *time.Time) *Limiter {
+	l := New(Config{
+		Sessions: []Session{
+			{Name: "dead_zone", Start: 21 * time.Hour, End: time.Hour, MaxEntries: 2, Window: 10 * time.Minute},
+			{Name: "us_open", Start: 13*time.Hour + 30*time.Minute, End: 15 * time.Hour, MaxEntries: 5, Window: 10 * time.Minute},
+		},
+	})
+	l.now = func() time.Time { return *at }
+	return l
+}
+
+func TestTake_CapsEntriesPerSessionWindow(t *testing.T) {
+	now := time.Date(2026, 10, 16, 23, 55, 0, 0, time.UTC)
+	l := limiter(&now)
+
+	for i := 0; i < 2; i++ {
+		if _, err := l.Take(); err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	// Past midnight, still in the dead zone, the window is full.
+	_, err := l.Take()
+	var limit *LimitError
+	if !errors.As(err, &limit) || limit.Session != "dead_zone" || limit.Max != 2 {
+		t.Fatalf("third entry: %v", err)
+	}
+	if want := time.Date(2026, 10, 17, 0, 5, 0, 0, time.UTC); !limit.Next.Equal(want) {
+		t.Errorf("next slot %s, want %s", limit.Next, want)
+	}
+
+	now = now.Add(8 * time.Minute)
+	if _, err := l.Take(); err != nil {
+		t.Errorf("after the first entry aged out: %v", err)
+	}
+	if st := l.Status(); st.Session != "dead_zone" || st.Used != 2 || st.Taken["dead_zone"] != 3 || st.Denied["dead_zone"] != 1 {
+		t.Errorf("status %+v", st)
+	}
+}
+
+func TestTake_ReleaseGivesTheSlotBack(t *testing.T) {
+	now := time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)
+	l := limiter(&now)
+
+	release, _ := l.Take()
+	l.Take()
+	release()
+	release()
+	if _, err := l.Take(); err != nil {
+		t.Errorf("released slot not reusable: %v", err)
+	}
+}
+
+func TestTake_UnlimitedOutsideSessionsAndWhenNil(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	l := limiter(&now)
+	for i := 0; i < 20; i++ {
+		if _, err := l.Take(); err != nil {
+			t.Fatalf("default session limited: %v", err)
+		}
+	}
+
+	var none *Limiter
+	if release, err := none.Take(); err != nil || release == nil {
+		t.Errorf("nil limiter: %v", err)
+	}
+}
@@ -0,0 +1,364 @@
+// Package graphql answers read-only GraphQL queries over a schema of
+// top-level fields. It covers the part of the language dashboards and
+// scripts use: fields, aliases, arguments, variables and nested selections.
+// There is no type system; resolvers return Objects, lists of them and
+// scalars, and the selection picks fields out of them, so a selected field
+// that a resolver does not provide is an error rather than a schema check.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Object is a resolved value with named fields. A field holding an Object
+// or a []Object must be selected from; any other value is a leaf.
+type Object map[string]interface{}
+
+// Field is a top-level query field. Args lists the arguments it accepts.
+type Field struct {
+	Args    []string
+	Resolve func(args *Args) (interface{}, error)
+}
+
+// Schema maps top-level field names to their resolvers.
+type Schema map[string]Field
+
+// Error is a GraphQL error entry. Path names the field it occurred at.
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Response is the result of a query. Data marshals to the selected fields
+// in query order.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Execute runs query against the schema. A field that fails is null in the
+// data and reported in the errors, so one bad field does not lose the rest.
+func (s Schema) Execute(query string, variables map[string]interface{}) Response {
+	doc, err := parse(query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	vars := make(map[string]interface{}, len(doc.defaults)+len(variables))
+	for k, v := range doc.defaults {
+		vars[k] = v
+	}
+	for k, v := range variables {
+		vars[k] = v
+	}
+
+	var resp Response
+	data := &ordered{}
+	for _, sel := range doc.selection {
+		value, err := s.resolve(sel, vars)
+		if err == nil {
+			value, err = project(value, sel, []interface{}{sel.Key()})
+		}
+		if err != nil {
+			var path []interface{}
+			if e, ok := err.(pathError); ok {
+				path, err = e.path, e.err
+			} else {
+				path = []interface{}{sel.Key()}
+			}
+			resp.Errors = append(resp.Errors, Error{Message: err.Error(), Path: path})
+			value = nil
+		}
+		data.set(sel.Key(), value)
+	}
+	resp.Data = data
+	return resp
+}
+
+func (s Schema) resolve(sel Selection, vars map[string]interface{}) (interface{}, error) {
+	field, ok := s[sel.Name]
+	if !ok {
+		return nil, fmt.Errorf("no field %q on Query", sel.Name)
+	}
+	args := &Args{field: sel.Name, values: make(map[string]interface{}, len(sel.Args))}
+	for name, v := range sel.Args {
+		if !contains(field.Args, name) {
+			return nil, fmt.Errorf("unknown argument %q on %s", name, sel.Name)
+		}
+		args.values[name] = substitute(v, vars)
+	}
+	value, err := field.Resolve(args)
+	if err != nil {
+		return nil, err
+	}
+	if args.err != nil {
+		return nil, args.err
+	}
+	return value, nil
+}
+
+type pathError struct {
+	path []interface{}
+	err  error
+}
+
+func (e pathError) Error() string {
+	return e.err.Error()
+}
+
+// project keeps the selected fields of value.
+func project(value interface{}, sel Selection, path []interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case Object:
+		if len(sel.Selection) == 0 {
+			return nil, pathError{path, fmt.Errorf("field %q is an object and needs a selection", sel.Name)}
+		}
+		out := &ordered{}
+		for _, child := range sel.Selection {
+			childPath := append(append([]interface{}(nil), path...), child.Key())
+			if len(child.Args) > 0 {
+				return nil, pathError{childPath, fmt.Errorf("arguments are only accepted on top-level fields")}
+			}
+			fv, ok := v[child.Name]
+			if !ok {
+				return nil, pathError{childPath, fmt.Errorf("no field %q on %s", child.Name, sel.Name)}
+			}
+			pv, err := project(fv, child, childPath)
+			if err != nil {
+				return nil, err
+			}
+			out.set(child.Key(), pv)
+		}
+		return out, nil
+	case []Object:
+		out := make([]interface{}, 0, len(v))
+		for i, item := range v {
+			pv, err := project(item, sel, append(append([]interface{}(nil), path...), i))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, pv)
+		}
+		return out, nil
+	}
+	if len(sel.Selection) > 0 {
+		return nil, pathError{path, fmt.Errorf("field %q is a scalar and has no fields to select", sel.Name)}
+	}
+	if t, ok := value.(time.Time); ok && t.IsZero() {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func substitute(v interface{}, vars map[string]interface{}) interface{} {
+	switch x := v.(type) {
+	case variable:
+		return vars[string(x)]
+	case []interface{}:
+		out := make([]interface{}, len(x))
+		for i, item := range x {
+			out[i] = substitute(item, vars)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(x))
+		for k, item := range x {
+			out[k] = substitute(item, vars)
+		}
+		return out
+	}
+	return v
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// maxBody bounds a request's query and variables.
+const maxBody = 64 << 10
+
+// Handler serves the schema over HTTP: a POST with a JSON body of query and
+// variables, or a GET with query and variables parameters.
+func Handler(schema Schema) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if len(r.URL.RawQuery) > maxBody {
+				http.Error(w, "query too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			req.Query = r.URL.Query().Get("query")
+			if v := r.URL.Query().Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					http.Error(w, "variables must be a JSON object", http.StatusBadRequest)
+					return
+				}
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&req); err != nil {
+				http.Error(w, "body must be JSON with a query", http.StatusBadRequest)
+				return
+			}
+		default:
+			http.Error(w, "use GET or POST", http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.TrimSpace(req.Query) == "" {
+			http.Error(w, "query is required", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(schema.Execute(req.Query, req.Variables))
+	})
+}
+
+// ordered is a JSON object that keeps its fields in selection order.
+type ordered struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *ordered) set(key string, value interface{}) {
+	if o.values == nil {
+		o.values = make(map[string]interface{})
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *ordered) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		value, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Args are the arguments of a field. The typed getters return the zero
+// value for a missing argument; one of the wrong type fails the field.
+type Args struct {
+	field  string
+	values map[string]interface{}
+	err    error
+}
+
+func (a *Args) fail(name, want string) {
+	if a.err == nil {
+		a.err = fmt.Errorf("argument %q on %s must be %s", name, a.field, want)
+	}
+}
+
+// Has reports whether the argument was given and not null.
+func (a *Args) Has(name string) bool {
+	return a.values[name] != nil
+}
+
+func (a *Args) String(name string) string {
+	switch v := a.values[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	a.fail(name, "a string")
+	return ""
+}
+
+func (a *Args) Int(name string) int {
+	switch v := a.values[name].(type) {
+	case nil:
+		return 0
+	case int64:
+		return int(v)
+	case float64:
+		// JSON variables decode as float64.
+		if v == float64(int(v)) {
+			return int(v)
+		}
+	}
+	a.fail(name, "an integer")
+	return 0
+}
+
+func (a *Args) Float(name string) float64 {
+	switch v := a.values[name].(type) {
+	case nil:
+		return 0
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	a.fail(name, "a number")
+	return 0
+}
+
+// Time reads an RFC 3339 time, or a duration such as "24h" meaning that
+// long before now.
+func (a *Args) Time(name string) time.Time {
+	s := a.String(name)
+	if s == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return time.Now().Add(-d)
+	}
+	a.fail(name, "an RFC 3339 time or a duration like 24h")
+	return time.Time{}
+}
+
+// Strings reads a list of strings; a single string is a list of one.
+func (a *Args) Strings(name string) []string {
+	switch v := a.values[name].(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				a.fail(name, "a list of strings")
+				return nil
+			}
+			out = append(out, s)
+		}
+		return out
+	}
+	a.fail(name, "a list of strings")
+	return nil
+}
@@ -0,0 +1,118 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testSchema() Schema {
+	trades := []Object{
+		{"symbol": "BTCUSDT", "pnl": 12.5, "exit": Object{"price": 101.0, "reason": "tp"}},
+		{"symbol": "ETHUSDT", "pnl": -4.0, "exit": nil},
+	}
+	return Schema{
+		"trades": {
+			Args: []string{"symbol", "limit"},
+			Resolve: func(args *Args) (interface{}, error) {
+				symbols := args.Strings("symbol")
+				var out []Object
+				for _, t := range trades {
+					if len(symbols) == 0 || symbols[0] == t["symbol"] {
+						out = append(out, t)
+					}
+				}
+				if n := args.Int("limit"); n > 0 && len(out) > n {
+					out = out[:n]
+				}
+				return out, nil
+			},
+		},
+		"metrics": {
+			Resolve: func(*Args) (interface{}, error) {
+				return Object{"capital": 1000.0, "halted": false}, nil
+			},
+		},
+	}
+}
+
+func run(t *testing.T, query string, vars map[string]interface{}) string {
+	t.Helper()
+	out, err := json.Marshal(testSchema().Execute(query, vars))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestExecute_SelectsFieldsInQueryOrder(t *testing.T) {
+	got := run(t, `
+		query Recent($sym: String = "BTCUSDT") {
+			metrics { halted capital }
+			btc: trades(symbol: $sym) { pnl exit { reason } }
+			all: trades(limit: 5) { symbol }
+		}`, nil)
+	want := `{"data":{"metrics":{"halted":false,"capital":1000},` +
+		`"btc":[{"pnl":12.5,"exit":{"reason":"tp"}}],` +
+		`"all":[{"symbol":"BTCUSDT"},{"symbol":"ETHUSDT"}]}}`
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	got = run(t, `query($sym: String) { trades(symbol: $sym) { symbol } }`, map[string]interface{}{"sym": "ETHUSDT"})
+	if got != `{"data":{"trades":[{"symbol":"ETHUSDT"}]}}` {
+		t.Fatalf("variables: %s", got)
+	}
+}
+
+func TestExecute_ReportsErrorsPerField(t *testing.T) {
+	got := run(t, `{ metrics { capital } trades(limit: "x") { symbol } }`, nil)
+	if !strings.Contains(got, `"metrics":{"capital":1000}`) || !strings.Contains(got, `"trades":null`) ||
+		!strings.Contains(got, `argument \"limit\" on trades must be an integer`) {
+		t.Fatalf("bad argument: %s", got)
+	}
+
+	got = run(t, `{ trades { symbol fees } }`, nil)
+	if !strings.Contains(got, `"path":["trades",0,"fees"]`) {
+		t.Fatalf("unknown field: %s", got)
+	}
+
+	for query, msg := range map[string]string{
+		`{ trades { ...TradeFields } }`:  "fragments are not supported",
+		`mutation { halt }`:              "mutation operations are not supported",
+		`{ trades(symbol: "BTCUSDT" { }`: "unexpected",
+		`{ metrics { capital }`:          "unexpected end of query",
+	} {
+		if got := run(t, query, nil); !strings.Contains(got, msg) {
+			t.Errorf("%s: got %s, want %q", query, got, msg)
+		}
+	}
+
+	// Nesting is capped before it can exhaust the parser's stack.
+	for _, query := range []string{
+		strings.Repeat("{ a ", 100000),
+		`{ trades(symbol: ` + strings.Repeat("[", 100000) + `) }`,
+	} {
+		if got := run(t, query, nil); !strings.Contains(got, "query nests deeper than") {
+			t.Errorf("%.20s...: got %.200s", query, got)
+		}
+	}
+}
+
+func TestHandler_RefusesOversizedRequests(t *testing.T) {
+	h := Handler(testSchema())
+	body := `{"query":"{ metrics { capital } }","variables":{"pad":"` + strings.Repeat("x", maxBody) + `"}}`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("oversized body: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ metrics { capital } }"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"capital":1000`) {
+		t.Errorf("small body: status %d, %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,409 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Selection is one field of a query: its alias, arguments and, for object
+// fields, the fields selected from it.
+type Selection struct {
+	Alias     string
+	Name      string
+	Args      map[string]interface{}
+	Selection []Selection
+}
+
+// Key is the name the field's value is returned under.
+func (s Selection) Key() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// variable is a $name reference in an argument, resolved at execution.
+type variable string
+
+type document struct {
+	defaults  map[string]interface{}
+	selection []Selection
+}
+
+type token struct {
+	kind  byte // 'n' name, 'i' int, 'f' float, 's' string, or the punctuator
+	text  string
+	value interface{}
+	pos   int
+}
+
+// parse reads a query document: one query operation, optionally named and
+// with variable definitions, made of fields, aliases and arguments.
+// Fragments, directives, mutations and subscriptions are not supported.
+func parse(src string) (document, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return document{}, err
+	}
+	p := &parser{toks: toks}
+	return p.document()
+}
+
+// maxDepth bounds how deeply selections and argument values may nest, so
+// a hostile query cannot exhaust the stack.
+const maxDepth = 12
+
+type parser struct {
+	toks  []token
+	i     int
+	depth int
+}
+
+// enter descends one level of nesting; leave must follow.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return fmt.Errorf("query nests deeper than %d levels", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != 0 {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) expect(kind byte) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.unexpected(t)
+	}
+	return t, nil
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == 0 {
+		return fmt.Errorf("unexpected end of query")
+	}
+	return fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+}
+
+func (p *parser) document() (document, error) {
+	doc := document{defaults: make(map[string]interface{})}
+	if t := p.peek(); t.kind == 'n' {
+		switch t.text {
+		case "query":
+			p.next()
+		case "mutation", "subscription":
+			return doc, fmt.Errorf("%s operations are not supported", t.text)
+		default:
+			return doc, p.unexpected(t)
+		}
+		if p.peek().kind == 'n' {
+			p.next()
+		}
+		if p.peek().kind == '(' {
+			if err := p.variables(doc.defaults); err != nil {
+				return doc, err
+			}
+		}
+	}
+
+	sel, err := p.selectionSet()
+	if err != nil {
+		return doc, err
+	}
+	if t := p.peek(); t.kind != 0 {
+		return doc, fmt.Errorf("only one operation per request is supported (offset %d)", t.pos)
+	}
+	doc.selection = sel
+	return doc, nil
+}
+
+// variables reads the definitions of an operation. Types are not checked;
+// only defaults are kept.
+func (p *parser) variables(defaults map[string]interface{}) error {
+	p.next()
+	for p.peek().kind != ')' {
+		if _, err := p.expect('$'); err != nil {
+			return err
+		}
+		name, err := p.expect('n')
+		if err != nil {
+			return err
+		}
+		if _, err := p.expect(':'); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if p.peek().kind == '=' {
+			p.next()
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			defaults[name.text] = v
+		}
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) skipType() error {
+	if err := p.enter(); err != nil {
+		return err
+	}
+	defer p.leave()
+
+	if p.peek().kind == '[' {
+		p.next()
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if _, err := p.expect(']'); err != nil {
+			return err
+		}
+	} else if _, err := p.expect('n'); err != nil {
+		return err
+	}
+	if p.peek().kind == '!' {
+		p.next()
+	}
+	return nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if _, err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	var out []Selection
+	for p.peek().kind != '}' {
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	p.next()
+	if len(out) == 0 {
+		return nil, fmt.Errorf("empty selection")
+	}
+	return out, nil
+}
+
+func (p *parser) field() (Selection, error) {
+	t := p.next()
+	switch t.kind {
+	case 'n':
+	case '.':
+		return Selection{}, fmt.Errorf("fragments are not supported")
+	case '@':
+		return Selection{}, fmt.Errorf("directives are not supported")
+	default:
+		return Selection{}, p.unexpected(t)
+	}
+
+	f := Selection{Name: t.text}
+	if p.peek().kind == ':' {
+		p.next()
+		name, err := p.expect('n')
+		if err != nil {
+			return f, err
+		}
+		f.Alias, f.Name = t.text, name.text
+	}
+	if p.peek().kind == '(' {
+		p.next()
+		f.Args = make(map[string]interface{})
+		for p.peek().kind != ')' {
+			name, err := p.expect('n')
+			if err != nil {
+				return f, err
+			}
+			if _, err := p.expect(':'); err != nil {
+				return f, err
+			}
+			v, err := p.value()
+			if err != nil {
+				return f, err
+			}
+			f.Args[name.text] = v
+		}
+		p.next()
+	}
+	if p.peek().kind == '@' {
+		return f, fmt.Errorf("directives are not supported")
+	}
+	if p.peek().kind == '{' {
+		sel, err := p.selectionSet()
+		if err != nil {
+			return f, err
+		}
+		f.Selection = sel
+	}
+	return f, nil
+}
+
+func (p *parser) value() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	t := p.next()
+	switch t.kind {
+	case 'i', 'f', 's':
+		return t.value, nil
+	case '$':
+		name, err := p.expect('n')
+		if err != nil {
+			return nil, err
+		}
+		return variable(name.text), nil
+	case 'n':
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		// Enum values are passed on as their names.
+		return t.text, nil
+	case '[':
+		list := []interface{}{}
+		for p.peek().kind != ']' {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		p.next()
+		return list, nil
+	case '{':
+		obj := map[string]interface{}{}
+		for p.peek().kind != '}' {
+			name, err := p.expect('n')
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(':'); err != nil {
+				return nil, err
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			obj[name.text] = v
+		}
+		p.next()
+		return obj, nil
+	}
+	return nil, p.unexpected(t)
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("{}()[]:$!=@", c) >= 0:
+			toks = append(toks, token{kind: c, text: string(c), pos: i})
+			i++
+		case c == '.':
+			if !strings.HasPrefix(src[i:], "...") {
+				return nil, fmt.Errorf("unexpected '.' at offset %d", i)
+			}
+			toks = append(toks, token{kind: '.', text: "...", pos: i})
+			i += 3
+		case c == '_' || isLetter(c):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || isLetter(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			toks = append(toks, token{kind: 'n', text: src[i:j], pos: i})
+			i = j
+		case c == '-' || isDigit(c):
+			j := i + 1
+			float := false
+			for j < len(src) && (isDigit(src[j]) || strings.IndexByte(".eE+-", src[j]) >= 0) {
+				if !isDigit(src[j]) {
+					float = true
+				}
+				j++
+			}
+			text := src[i:j]
+			t := token{kind: 'i', text: text, pos: i}
+			if float {
+				v, err := strconv.ParseFloat(text, 64)
+				if err != nil {
+					return nil, fmt.Errorf("bad number %q at offset %d", text, i)
+				}
+				t.kind, t.value = 'f', v
+			} else {
+				v, err := strconv.ParseInt(text, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("bad number %q at offset %d", text, i)
+				}
+				t.value = v
+			}
+			toks = append(toks, t)
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			var s string
+			if err := json.Unmarshal([]byte(src[i:j+1]), &s); err != nil {
+				return nil, fmt.Errorf("bad string at offset %d: %v", i, err)
+			}
+			toks = append(toks, token{kind: 's', text: src[i : j+1], value: s, pos: i})
+			i = j + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+		}
+	}
+	return append(toks, token{pos: len(src)}), nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,109 @@
+package indicator
+
+import "github.com/britej3/gobot/domain/trade"
+
+// Names of the indicators in Standard.
+const (
+	EMAFast = "ema_fast"
+	EMASlow = "ema_slow"
+	RSI14   = "rsi"
+	ATR14   = "atr"
+)
+
+// Spec declares an indicator and its warm-up: the number of candles it is
+// computed over. Recursive indicators (EMA, Wilder's RSI and ATR) depend on
+// where their series starts, so the warm-up is long enough for the seed to
+// have decayed away.
+type Spec struct {
+	Name    string
+	WarmUp  int
+	Compute func(klines []trade.Kline) float64
+}
+
+// EMASpec is an EMA over five periods of history, by which the SMA seed
+// weighs well under 0.1%.
+func EMASpec(name string, period int) Spec {
+	return Spec{Name: name, WarmUp: 5 * period, Compute: func(k []trade.Kline) float64 {
+		return EMA(Closes(k), period)
+	}}
+}
+
+// RSISpec is Wilder's RSI over ten periods of history; Wilder smoothing
+// forgets its seed more slowly than an EMA of the same period.
+func RSISpec(name string, period int) Spec {
+	return Spec{Name: name, WarmUp: 10*period + 1, Compute: func(k []trade.Kline) float64 {
+		return RSI(Closes(k), period)
+	}}
+}
+
+// ATRSpec is Wilder's ATR over ten periods of history.
+func ATRSpec(name string, period int) Spec {
+	return Spec{Name: name, WarmUp: 10*period + 1, Compute: func(k []trade.Kline) float64 {
+		return ATR(k, period)
+	}}
+}
+
+// Values maps indicator names to values.
+type Values map[string]float64
+
+// Engine computes a set of indicators the same way live and in backtests.
+// Every indicator is computed over exactly its last WarmUp candles, so its
+// value depends only on those candles and not on how much history the
+// caller happens to hold.
+type Engine struct {
+	specs  []Spec
+	warmUp int
+}
+
+func NewEngine(specs ...Spec) *Engine {
+	e := &Engine{specs: specs}
+	for _, s := range specs {
+		if s.WarmUp > e.warmUp {
+			e.warmUp = s.WarmUp
+		}
+	}
+	return e
+}
+
+// Standard is the indicator set the kline service serves live.
+var Standard = NewEngine(
+	EMASpec(EMAFast, 9),
+	EMASpec(EMASlow, 21),
+	RSISpec(RSI14, 14),
+	ATRSpec(ATR14, 14),
+)
+
+// WarmUp is the number of candles needed before every indicator is warm, the
+// least history live buffers and backtests must backfill.
+func (e *Engine) WarmUp() int {
+	return e.warmUp
+}
+
+// Compute returns the indicators at the last candle of klines, oldest first.
+// The second result is false when there are fewer than WarmUp candles and
+// some values rest on a truncated series.
+func (e *Engine) Compute(klines []trade.Kline) (Values, bool) {
+	values := make(Values, len(e.specs))
+	for _, s := range e.specs {
+		window := klines
+		if len(window) > s.WarmUp {
+			window = window[len(window)-s.WarmUp:]
+		}
+		values[s.Name] = s.Compute(window)
+	}
+	return values, len(klines) >= e.warmUp
+}
+
+// Replay calls fn with the indicators at every candle of klines from the
+// first warm one on, as a backtest steps through history. Values at index i
+// equal Compute(klines[:i+1]).
+func (e *Engine) Replay(klines []trade.Kline, fn func(i int, values Values)) {
+	start := e.warmUp - 1
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(klines); i++ {
+		values, _ := e.Compute(klines[:i+1])
+		fn(i, values)
+	}
+}
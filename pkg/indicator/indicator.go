@@ -0,0 +1,208 @@
+// Package indicator provides technical indicator math over candle series.
+// All functions are pure and expect klines ordered oldest first.
+package indicator
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// Closes extracts close prices from a kline series
+func Closes(klines []trade.Kline) []float64 {
+	closes := make([]float64, len(klines))
+	for i, k := range klines {
+		closes[i] = k.Close
+	}
+	return closes
+}
+
+// EMA returns the exponential moving average of values, seeded with the SMA
+// of the first period values. Returns 0 when there is not enough data.
+func EMA(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period {
+		return 0
+	}
+
+	ema := 0.0
+	for _, v := range values[:period] {
+		ema += v
+	}
+	ema /= float64(period)
+
+	multiplier := 2.0 / float64(period+1)
+	for _, v := range values[period:] {
+		ema = (v-ema)*multiplier + ema
+	}
+
+	return ema
+}
+
+// RSI returns Wilder's relative strength index. Returns 50 (neutral) when
+// there is not enough data.
+func RSI(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period+1 {
+		return 50
+	}
+
+	var avgGain, avgLoss float64
+	for i := 1; i <= period; i++ {
+		change := values[i] - values[i-1]
+		if change > 0 {
+			avgGain += change
+		} else {
+			avgLoss -= change
+		}
+	}
+	avgGain /= float64(period)
+	avgLoss /= float64(period)
+
+	for i := period + 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		gain, loss := 0.0, 0.0
+		if change > 0 {
+			gain = change
+		} else {
+			loss = -change
+		}
+		avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+	}
+
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+
+	rs := avgGain / avgLoss
+	return 100 - (100 / (1 + rs))
+}
+
+// ATR returns Wilder's average true range. Returns 0 when there is not
+// enough data.
+func ATR(klines []trade.Kline, period int) float64 {
+	if period <= 0 || len(klines) < period+1 {
+		return 0
+	}
+
+	trueRange := func(i int) float64 {
+		prevClose := klines[i-1].Close
+		return math.Max(klines[i].High-klines[i].Low,
+			math.Max(math.Abs(klines[i].High-prevClose), math.Abs(klines[i].Low-prevClose)))
+	}
+
+	atr := 0.0
+	for i := 1; i <= period; i++ {
+		atr += trueRange(i)
+	}
+	atr /= float64(period)
+
+	for i := period + 1; i < len(klines); i++ {
+		atr = (atr*float64(period-1) + trueRange(i)) / float64(period)
+	}
+
+	return atr
+}
+
+// VWAP returns the volume weighted average price of the series using the
+// typical price (high+low+close)/3 of each candle.
+func VWAP(klines []trade.Kline) float64 {
+	var pv, volume float64
+	for _, k := range klines {
+		typical := (k.High + k.Low + k.Close) / 3
+		pv += typical * k.Volume
+		volume += k.Volume
+	}
+
+	if volume == 0 {
+		if len(klines) == 0 {
+			return 0
+		}
+		return klines[len(klines)-1].Close
+	}
+
+	return pv / volume
+}
+
+// AnchoredVWAP returns the VWAP of candles opened at or after anchor.
+// Returns 0 when no candle falls inside the anchored window.
+func AnchoredVWAP(klines []trade.Kline, anchor time.Time) float64 {
+	start := sort.Search(len(klines), func(i int) bool {
+		return !klines[i].OpenTime.Before(anchor)
+	})
+	if start >= len(klines) {
+		return 0
+	}
+	return VWAP(klines[start:])
+}
+
+// SessionVWAP returns the VWAP of the last candle's UTC session. ok is false
+// when the candles do not reach back to the session open, since the VWAP of
+// a later window is not the session's.
+func SessionVWAP(klines []trade.Kline) (vwap float64, ok bool) {
+	if len(klines) == 0 {
+		return 0, false
+	}
+	start := SessionStart(klines[len(klines)-1].OpenTime)
+	if klines[0].OpenTime.After(start) {
+		return 0, false
+	}
+	return AnchoredVWAP(klines, start), true
+}
+
+// SwingLookback is how many candles SwingRange looks back by default
+const SwingLookback = 10
+
+// SwingRange returns the lowest low and highest high of the last lookback
+// candles, the structure stops are placed beyond. Returns zeros when there
+// are no candles.
+func SwingRange(klines []trade.Kline, lookback int) (low, high float64) {
+	if lookback <= 0 || len(klines) == 0 {
+		return 0, 0
+	}
+	if lookback > len(klines) {
+		lookback = len(klines)
+	}
+	for _, k := range klines[len(klines)-lookback:] {
+		if low == 0 || k.Low < low {
+			low = k.Low
+		}
+		if k.High > high {
+			high = k.High
+		}
+	}
+	return low, high
+}
+
+// SessionStart returns the open of the UTC trading day containing t, which
+// is the session boundary Binance futures uses for daily statistics.
+func SessionStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// SpikeAnchor returns the open time of the most recent candle whose volume
+// exceeds multiple times the series average, the usual anchor for VWAP after
+// a breakout. The second result is false when no such candle exists.
+func SpikeAnchor(klines []trade.Kline, multiple float64) (time.Time, bool) {
+	if len(klines) == 0 || multiple <= 0 {
+		return time.Time{}, false
+	}
+
+	avg := 0.0
+	for _, k := range klines {
+		avg += k.Volume
+	}
+	avg /= float64(len(klines))
+
+	for i := len(klines) - 1; i >= 0; i-- {
+		if avg > 0 && klines[i].Volume > avg*multiple {
+			return klines[i].OpenTime, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,148 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+func candles(closes ...float64) []trade.Kline {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	klines := make([]trade.Kline, len(closes))
+	for i, c := range closes {
+		klines[i] = trade.Kline{
+			OpenTime: start.Add(time.Duration(i) * time.Minute),
+			Open:     c,
+			High:     c + 1,
+			Low:      c - 1,
+			Close:    c,
+			Volume:   10,
+		}
+	}
+	return klines
+}
+
+func TestEMA(t *testing.T) {
+	if got := EMA([]float64{1, 2}, 3); got != 0 {
+		t.Errorf("expected 0 for short series, got %f", got)
+	}
+
+	// SMA seed of 2, then (4-2)*0.5+2 = 3
+	if got := EMA([]float64{1, 2, 3, 4}, 3); math.Abs(got-3) > 1e-9 {
+		t.Errorf("expected 3, got %f", got)
+	}
+}
+
+func TestRSI(t *testing.T) {
+	rising := make([]float64, 20)
+	for i := range rising {
+		rising[i] = float64(i + 1)
+	}
+	if got := RSI(rising, 14); got != 100 {
+		t.Errorf("expected 100 for monotonic rise, got %f", got)
+	}
+
+	flat := []float64{5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5}
+	if got := RSI(flat, 14); got != 50 {
+		t.Errorf("expected 50 for flat series, got %f", got)
+	}
+}
+
+func TestATR(t *testing.T) {
+	klines := candles(10, 10, 10, 10, 10)
+	if got := ATR(klines, 3); math.Abs(got-2) > 1e-9 {
+		t.Errorf("expected ATR 2, got %f", got)
+	}
+}
+
+func TestSwingRange(t *testing.T) {
+	low, high := SwingRange(candles(10, 14, 12, 11, 13), 3)
+	if low != 10 || high != 14 {
+		t.Errorf("SwingRange = %v/%v, want 10/14", low, high)
+	}
+	if low, high := SwingRange(candles(10, 14), 5); low != 9 || high != 15 {
+		t.Errorf("lookback beyond the series = %v/%v, want 9/15", low, high)
+	}
+}
+
+func TestVWAP(t *testing.T) {
+	klines := candles(10, 20)
+	klines[1].Volume = 30
+
+	// typical prices equal closes here: (10*10 + 20*30) / 40
+	if got := VWAP(klines); math.Abs(got-17.5) > 1e-9 {
+		t.Errorf("expected VWAP 17.5, got %f", got)
+	}
+}
+
+func TestAnchoredVWAP(t *testing.T) {
+	klines := candles(10, 20, 30)
+
+	if got := AnchoredVWAP(klines, klines[1].OpenTime); math.Abs(got-25) > 1e-9 {
+		t.Errorf("expected anchored VWAP 25, got %f", got)
+	}
+	if got := AnchoredVWAP(klines, klines[2].OpenTime.Add(time.Minute)); got != 0 {
+		t.Errorf("expected 0 for anchor after last candle, got %f", got)
+	}
+}
+
+func TestSessionVWAP(t *testing.T) {
+	klines := candles(10, 20, 30)
+	for i := range klines {
+		klines[i].OpenTime = klines[i].OpenTime.Add(23*time.Hour + 59*time.Minute)
+	}
+
+	// the last two candles open the next session
+	if got, ok := SessionVWAP(klines); !ok || math.Abs(got-25) > 1e-9 {
+		t.Errorf("expected session VWAP 25, got %f (ok %v)", got, ok)
+	}
+	if got, ok := SessionVWAP(klines[2:]); ok || got != 0 {
+		t.Errorf("a buffer starting after the session open has no session VWAP, got %f", got)
+	}
+}
+
+func TestSpikeAnchor(t *testing.T) {
+	klines := candles(10, 11, 12, 13, 14)
+	if _, ok := SpikeAnchor(klines, 3); ok {
+		t.Error("expected no spike on uniform volume")
+	}
+
+	klines[3].Volume = 100
+	anchor, ok := SpikeAnchor(klines, 3)
+	if !ok || !anchor.Equal(klines[3].OpenTime) {
+		t.Errorf("expected spike anchor at candle 3, got %v (%v)", anchor, ok)
+	}
+}
+
+func TestEngineComputesOverWarmUpWindow(t *testing.T) {
+	closes := make([]float64, 300)
+	for i := range closes {
+		closes[i] = 100 + math.Sin(float64(i)/5)*3
+	}
+	klines := candles(closes...)
+
+	if Standard.WarmUp() != 141 {
+		t.Errorf("warm-up = %d, want 141", Standard.WarmUp())
+	}
+	full, warm := Standard.Compute(klines)
+	tail, _ := Standard.Compute(klines[len(klines)-Standard.WarmUp():])
+	if !warm || full[EMASlow] != tail[EMASlow] || full[RSI14] != tail[RSI14] {
+		t.Errorf("values depend on history beyond the warm-up: %v vs %v", full, tail)
+	}
+	if _, warm := Standard.Compute(klines[:100]); warm {
+		t.Error("expected 100 candles to be cold")
+	}
+
+	steps := 0
+	Standard.Replay(klines, func(i int, v Values) {
+		if steps == 0 && i != Standard.WarmUp()-1 {
+			t.Errorf("replay started at %d", i)
+		}
+		steps++
+	})
+	if steps != len(klines)-Standard.WarmUp()+1 {
+		t.Errorf("replay stepped %d times", steps)
+	}
+}
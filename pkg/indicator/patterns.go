@@ -0,0 +1,146 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// Candlestick patterns detected on the last candle of a series
+const (
+	PatternEngulfing = "engulfing"
+	PatternPinBar    = "pin_bar"
+	PatternInsideBar = "inside_bar"
+	PatternThreeBar  = "three_bar_reversal"
+)
+
+// pinWickFraction is the share of a pin bar's range its long wick covers
+const pinWickFraction = 2.0 / 3
+
+// PatternNames lists every pattern PatternFeatures reports
+var PatternNames = []string{PatternEngulfing, PatternPinBar, PatternInsideBar, PatternThreeBar}
+
+// Pattern is a candlestick pattern completed by the last candle. Direction
+// is 1 when bullish, -1 when bearish and 0 for an inside bar, which only
+// marks a pause. Strength runs from 0 to 1.
+type Pattern struct {
+	Name      string
+	Direction int
+	Strength  float64
+}
+
+// DetectPatterns returns the patterns the last candle of klines completes:
+//
+//   - engulfing: its body covers the previous, opposite-coloured body;
+//     strength is how much larger it is
+//   - pin bar: one wick is at least two thirds of its range, pointing away
+//     from the direction; strength is the wick's share of the range
+//   - inside bar: its range sits within the previous candle's; strength is
+//     how much narrower it is
+//   - three-bar reversal: the middle candle makes a new extreme after a
+//     candle moving into it, and the last closes beyond the middle's far
+//     side; strength is how much of the swing into the extreme it retraces
+//
+// Candles without a range are never part of a pattern.
+func DetectPatterns(klines []trade.Kline) []Pattern {
+	n := len(klines)
+	if n == 0 {
+		return nil
+	}
+	cur := klines[n-1]
+	if cur.High <= cur.Low {
+		return nil
+	}
+
+	var found []Pattern
+	if p, ok := pinBar(cur); ok {
+		found = append(found, p)
+	}
+	if n < 2 || klines[n-2].High <= klines[n-2].Low {
+		return found
+	}
+	prev := klines[n-2]
+	if p, ok := engulfing(prev, cur); ok {
+		found = append(found, p)
+	}
+	if p, ok := insideBar(prev, cur); ok {
+		found = append(found, p)
+	}
+	if n >= 3 {
+		if p, ok := threeBarReversal(klines[n-3], prev, cur); ok {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// PatternFeatures reports every pattern in PatternNames as its strength
+// signed by direction, so a bullish engulfing of 0.8 reads 0.8, a bearish
+// one -0.8 and an absent pattern 0. Inside bars are always positive.
+func PatternFeatures(patterns []Pattern) map[string]float64 {
+	features := make(map[string]float64, len(PatternNames))
+	for _, name := range PatternNames {
+		features[name] = 0
+	}
+	for _, p := range patterns {
+		v := p.Strength
+		if p.Direction < 0 {
+			v = -v
+		}
+		features[p.Name] = v
+	}
+	return features
+}
+
+func engulfing(prev, cur trade.Kline) (Pattern, bool) {
+	prevBody, curBody := math.Abs(prev.Close-prev.Open), math.Abs(cur.Close-cur.Open)
+	if prevBody == 0 || curBody <= prevBody {
+		return Pattern{}, false
+	}
+	strength := 1 - prevBody/curBody
+	switch {
+	case prev.Close < prev.Open && cur.Close > cur.Open &&
+		cur.Open <= prev.Close && cur.Close >= prev.Open:
+		return Pattern{Name: PatternEngulfing, Direction: 1, Strength: strength}, true
+	case prev.Close > prev.Open && cur.Close < cur.Open &&
+		cur.Open >= prev.Close && cur.Close <= prev.Open:
+		return Pattern{Name: PatternEngulfing, Direction: -1, Strength: strength}, true
+	}
+	return Pattern{}, false
+}
+
+func pinBar(k trade.Kline) (Pattern, bool) {
+	rng := k.High - k.Low
+	lower := math.Min(k.Open, k.Close) - k.Low
+	upper := k.High - math.Max(k.Open, k.Close)
+	switch {
+	case lower/rng >= pinWickFraction:
+		return Pattern{Name: PatternPinBar, Direction: 1, Strength: lower / rng}, true
+	case upper/rng >= pinWickFraction:
+		return Pattern{Name: PatternPinBar, Direction: -1, Strength: upper / rng}, true
+	}
+	return Pattern{}, false
+}
+
+func insideBar(prev, cur trade.Kline) (Pattern, bool) {
+	if cur.High > prev.High || cur.Low < prev.Low {
+		return Pattern{}, false
+	}
+	strength := 1 - (cur.High-cur.Low)/(prev.High-prev.Low)
+	if strength <= 0 {
+		return Pattern{}, false
+	}
+	return Pattern{Name: PatternInsideBar, Strength: strength}, true
+}
+
+func threeBarReversal(first, mid, last trade.Kline) (Pattern, bool) {
+	switch {
+	case first.Close < first.Open && mid.Low < first.Low && mid.Low < last.Low &&
+		last.Close > last.Open && last.Close > mid.High:
+		return Pattern{Name: PatternThreeBar, Direction: 1, Strength: math.Min(1, (last.Close-mid.Low)/(first.High-mid.Low))}, true
+	case first.Close > first.Open && mid.High > first.High && mid.High > last.High &&
+		last.Close < last.Open && last.Close < mid.Low:
+		return Pattern{Name: PatternThreeBar, Direction: -1, Strength: math.Min(1, (mid.High-last.Close)/(mid.High-first.Low))}, true
+	}
+	return Pattern{}, false
+}
@@ -0,0 +1,96 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+func ohlc(bars ...[4]float64) []trade.Kline {
+	klines := candles(make([]float64, len(bars))...)
+	for i, b := range bars {
+		klines[i].Open, klines[i].High, klines[i].Low, klines[i].Close = b[0], b[1], b[2], b[3]
+	}
+	return klines
+}
+
+func TestDetectPatterns(t *testing.T) {
+	tests := []struct {
+		name   string
+		klines []trade.Kline
+		want   map[string]float64
+	}{
+		{
+			name:   "bullish engulfing",
+			klines: ohlc([4]float64{102, 102.5, 100.5, 101}, [4]float64{100.8, 103.2, 100.6, 103}),
+			want:   map[string]float64{PatternEngulfing: 1 - 1/2.2},
+		},
+		{
+			name:   "bearish engulfing",
+			klines: ohlc([4]float64{100, 101.2, 99.8, 101}, [4]float64{101.5, 101.6, 98.9, 99}),
+			want:   map[string]float64{PatternEngulfing: -(1 - 1/2.5)},
+		},
+		{
+			name:   "hammer",
+			klines: ohlc([4]float64{100, 100.5, 99, 100.4}, [4]float64{99.8, 100.2, 96.2, 100}),
+			want:   map[string]float64{PatternPinBar: 3.6 / 4},
+		},
+		{
+			name:   "shooting star",
+			klines: ohlc([4]float64{100, 101, 99, 99.5}, [4]float64{100.1, 104, 99.9, 100}),
+			want:   map[string]float64{PatternPinBar: -3.9 / 4.1},
+		},
+		{
+			name:   "inside bar",
+			klines: ohlc([4]float64{100, 104, 96, 103}, [4]float64{102, 103, 101, 101.5}),
+			want:   map[string]float64{PatternInsideBar: 0.75},
+		},
+		{
+			name: "bullish three-bar reversal",
+			klines: ohlc(
+				[4]float64{104, 104.5, 101, 101.5},
+				[4]float64{101.5, 102, 99, 100},
+				[4]float64{100, 103.5, 99.5, 103},
+			),
+			want: map[string]float64{PatternThreeBar: 4.0 / 5.5, PatternEngulfing: 1 - 1.5/3},
+		},
+		{
+			name: "bearish three-bar reversal",
+			klines: ohlc(
+				[4]float64{100, 103, 99.5, 102.5},
+				[4]float64{102.5, 105, 102, 104},
+				[4]float64{104, 104.5, 100.5, 101},
+			),
+			want: map[string]float64{PatternThreeBar: -4.0 / 5.5, PatternEngulfing: -(1 - 1.5/3)},
+		},
+		{
+			name:   "trend candles",
+			klines: ohlc([4]float64{100, 101.2, 99.9, 101}, [4]float64{101, 102.2, 100.9, 102}),
+			want:   map[string]float64{},
+		},
+		{
+			name:   "no range",
+			klines: ohlc([4]float64{100, 104, 96, 103}, [4]float64{100, 100, 100, 100}),
+			want:   map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PatternFeatures(DetectPatterns(tt.klines))
+			if len(got) != len(PatternNames) {
+				t.Fatalf("expected a feature per pattern, got %v", got)
+			}
+			for _, name := range PatternNames {
+				if math.Abs(got[name]-tt.want[name]) > 1e-9 {
+					t.Errorf("%s = %f, want %f", name, got[name], tt.want[name])
+				}
+			}
+		})
+	}
+
+	if got := DetectPatterns(nil); got != nil {
+		t.Errorf("expected no patterns for an empty series, got %v", got)
+	}
+}
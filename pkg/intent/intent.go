@@ -0,0 +1,296 @@
+package intent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is a step in a trade's lifecycle. An intent starts proposed and ends
+// either rejected, if it never reached the book, or closed.
+type State string
+
+const (
+	Proposed  State = "proposed"
+	Validated State = "validated"
+	Queued    State = "queued"
+	Submitted State = "submitted"
+	Filled    State = "filled"
+	Rejected  State = "rejected"
+	Managed   State = "managed"
+	Closed    State = "closed"
+)
+
+// transitions lists the states each state may move to. Queueing is
+// optional, and a fill may close before anything starts managing it.
+var transitions = map[State][]State{
+	Proposed:  {Validated, Rejected},
+	Validated: {Queued, Submitted, Rejected},
+	Queued:    {Submitted, Rejected},
+	Submitted: {Filled, Rejected},
+	Filled:    {Managed, Closed},
+	Managed:   {Closed},
+}
+
+// CanTransition reports whether an intent in from may move to to.
+func CanTransition(from, to State) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal reports whether no transition leaves s.
+func (s State) Terminal() bool {
+	return len(transitions[s]) == 0
+}
+
+var (
+	ErrInvalidTransition = errors.New("invalid intent transition")
+	ErrUnknownIntent     = errors.New("unknown intent")
+)
+
+type Transition struct {
+	From   State     `json:"from,omitempty"`
+	To     State     `json:"to"`
+	Time   time.Time `json:"ts"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// Intent is one prospective trade and the transitions it went through.
+type Intent struct {
+	ID      string       `json:"id"`
+	Symbol  string       `json:"symbol"`
+	Side    string       `json:"side,omitempty"`
+	Source  string       `json:"source,omitempty"`
+	State   State        `json:"state"`
+	Created time.Time    `json:"created"`
+	History []Transition `json:"history"`
+}
+
+// Event is the intent v1 payload, published once per transition.
+type Event struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Side   string `json:"side,omitempty"`
+	Source string `json:"source,omitempty"`
+	Transition
+	// AgeMS is the time since the intent was proposed.
+	AgeMS int64 `json:"age_ms"`
+}
+
+// EventType is the event type each transition is published as.
+const EventType = "intent"
+
+// Publisher delivers lifecycle events, typically an *events.Stream.
+type Publisher interface {
+	Publish(eventType string, data interface{})
+}
+
+type Config struct {
+	// Retain is how many finished intents are kept for inspection, 200 by
+	// default.
+	Retain int
+}
+
+type Stats struct {
+	Active int
+	// Reached counts transitions into each state.
+	Reached map[State]int
+}
+
+// Tracker owns the intents of every entry path and enforces their
+// transitions. A nil *Tracker accepts and ignores everything, so callers
+// need not check whether lifecycle tracking is configured.
+type Tracker struct {
+	cfg     Config
+	pub     Publisher
+	started time.Time
+
+	mu       sync.Mutex
+	seq      int
+	active   map[string]*Intent
+	finished []Intent
+	reached  map[State]int
+}
+
+func New(cfg Config, pub Publisher) *Tracker {
+	if cfg.Retain <= 0 {
+		cfg.Retain = 200
+	}
+	return &Tracker{
+		cfg:     cfg,
+		pub:     pub,
+		started: time.Now(),
+		active:  make(map[string]*Intent),
+		reached: make(map[State]int),
+	}
+}
+
+// Propose opens an intent for a trade on symbol and returns its ID.
+func (t *Tracker) Propose(symbol, side, source string) string {
+	if t == nil {
+		return ""
+	}
+	now := time.Now()
+
+	t.mu.Lock()
+	t.seq++
+	in := &Intent{
+		ID:      fmt.Sprintf("%x-%d", t.started.Unix(), t.seq),
+		Symbol:  strings.ToUpper(symbol),
+		Side:    side,
+		Source:  source,
+		State:   Proposed,
+		Created: now,
+		History: []Transition{{To: Proposed, Time: now}},
+	}
+	t.active[in.ID] = in
+	t.reached[Proposed]++
+	ev := event(in)
+	t.mu.Unlock()
+
+	t.publish(ev)
+	return in.ID
+}
+
+// Advance moves intent id to state to. Moves the state machine does not
+// allow are refused with ErrInvalidTransition and leave the intent as it
+// was. An empty id is ignored.
+func (t *Tracker) Advance(id string, to State, reason string) error {
+	if t == nil || id == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	in, ok := t.active[id]
+	if !ok {
+		t.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownIntent, id)
+	}
+	from := in.State
+	if !CanTransition(from, to) {
+		t.mu.Unlock()
+		return fmt.Errorf("%w: %s %s -> %s", ErrInvalidTransition, id, from, to)
+	}
+	ev := t.move(in, to, reason)
+	t.mu.Unlock()
+
+	t.publish(ev)
+	return nil
+}
+
+// CloseSymbol closes every filled or managed intent on symbol.
+func (t *Tracker) CloseSymbol(symbol, reason string) {
+	if t == nil {
+		return
+	}
+	symbol = strings.ToUpper(symbol)
+
+	t.mu.Lock()
+	var evs []Event
+	for _, in := range t.active {
+		if in.Symbol == symbol && CanTransition(in.State, Closed) {
+			evs = append(evs, t.move(in, Closed, reason))
+		}
+	}
+	t.mu.Unlock()
+
+	for _, ev := range evs {
+		t.publish(ev)
+	}
+}
+
+// move records the transition of in to to. t.mu must be held.
+func (t *Tracker) move(in *Intent, to State, reason string) Event {
+	tr := Transition{From: in.State, To: to, Time: time.Now(), Reason: reason}
+	in.State = to
+	in.History = append(in.History, tr)
+	t.reached[to]++
+
+	ev := event(in)
+	if to.Terminal() {
+		delete(t.active, in.ID)
+		t.finished = append(t.finished, *in)
+		if len(t.finished) > t.cfg.Retain {
+			t.finished = t.finished[len(t.finished)-t.cfg.Retain:]
+		}
+	}
+	return ev
+}
+
+func event(in *Intent) Event {
+	tr := in.History[len(in.History)-1]
+	return Event{
+		ID:         in.ID,
+		Symbol:     in.Symbol,
+		Side:       in.Side,
+		Source:     in.Source,
+		Transition: tr,
+		AgeMS:      tr.Time.Sub(in.Created).Milliseconds(),
+	}
+}
+
+func (t *Tracker) publish(ev Event) {
+	if t.pub != nil {
+		t.pub.Publish(EventType, ev)
+	}
+}
+
+// Get returns intent id, whether it is still active or recently finished.
+func (t *Tracker) Get(id string) (Intent, bool) {
+	if t == nil {
+		return Intent{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if in, ok := t.active[id]; ok {
+		return copyIntent(in), true
+	}
+	for i := len(t.finished) - 1; i >= 0; i-- {
+		if t.finished[i].ID == id {
+			return copyIntent(&t.finished[i]), true
+		}
+	}
+	return Intent{}, false
+}
+
+// Active returns the intents that have not yet been rejected or closed.
+func (t *Tracker) Active() []Intent {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]Intent, 0, len(t.active))
+	for _, in := range t.active {
+		out = append(out, copyIntent(in))
+	}
+	return out
+}
+
+func (t *Tracker) Stats() Stats {
+	if t == nil {
+		return Stats{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reached := make(map[State]int, len(t.reached))
+	for s, n := range t.reached {
+		reached[s] = n
+	}
+	return Stats{Active: len(t.active), Reached: reached}
+}
+
+func copyIntent(in *Intent) Intent {
+	out := *in
+	out.History = append([]Transition(nil), in.History...)
+	return out
+}
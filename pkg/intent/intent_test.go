@@ -0,0 +1,56 @@
+package intent
+
+import (
+	"errors"
+	"testing"
+)
+
+type recorder []Event
+
+func (r *recorder) Publish(eventType string, data interface{}) {
+	*r = append(*r, data.(Event))
+}
+
+func TestLifecycle_EnforcesTransitions(t *testing.T) {
+	var events recorder
+	tr := New(Config{}, &events)
+
+	id := tr.Propose("btcusdt", "BUY", "webhook")
+	if err := tr.Advance(id, Filled, ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("proposed -> filled should be refused, got %v", err)
+	}
+	for _, s := range []State{Validated, Queued, Submitted, Filled, Managed} {
+		if err := tr.Advance(id, s, ""); err != nil {
+			t.Fatalf("advance to %s: %v", s, err)
+		}
+	}
+	tr.CloseSymbol("BTCUSDT", "take profit")
+
+	in, ok := tr.Get(id)
+	if !ok || in.State != Closed || len(in.History) != 7 {
+		t.Fatalf("expected a closed intent with 7 transitions, got %+v", in)
+	}
+	if len(events) != 7 || events[6].From != Managed || events[6].Reason != "take profit" {
+		t.Errorf("unexpected events %+v", events)
+	}
+	if err := tr.Advance(id, Rejected, ""); !errors.Is(err, ErrUnknownIntent) {
+		t.Errorf("a closed intent should no longer advance, got %v", err)
+	}
+
+	rejected := tr.Propose("ETHUSDT", "SELL", "analysis")
+	if err := tr.Advance(rejected, Rejected, "risk_reward"); err != nil {
+		t.Fatal(err)
+	}
+	if s := tr.Stats(); s.Active != 0 || s.Reached[Proposed] != 2 || s.Reached[Rejected] != 1 || s.Reached[Closed] != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	var tr *Tracker
+	id := tr.Propose("BTCUSDT", "BUY", "webhook")
+	if err := tr.Advance(id, Validated, ""); err != nil {
+		t.Errorf("nil tracker should ignore transitions, got %v", err)
+	}
+	tr.CloseSymbol("BTCUSDT", "")
+}
@@ -10,11 +10,15 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
+	"github.com/britej3/gobot/infra/binance"
 	"github.com/britej3/gobot/internal/agent"
 	"github.com/britej3/gobot/internal/platform"
 	"github.com/britej3/gobot/internal/position"
 	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/services/kline"
 	"github.com/britej3/gobot/services/screener"
+	"github.com/britej3/gobot/services/symbolrules"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,6 +28,8 @@ type Platform struct {
 	brain          *brain.BrainEngine
 	feedback       *CogneeFeedbackSystem
 	screener       *screener.Screener
+	klines         *kline.Service
+	symbolRules    *symbolrules.Registry
 	positionMgr    *position.PositionManager
 	stateManager   *StateManager
 	reconciler     *agent.Reconciler
@@ -31,6 +37,12 @@ type Platform struct {
 	isRunning      bool
 	stopChan       chan struct{}
 	initialBalance float64
+
+	// ctx is cancelled by Stop so background loops abandon in-flight calls;
+	// calls bounds each of those calls by type.
+	ctx    context.Context
+	cancel context.CancelFunc
+	calls  *callpolicy.Policy
 }
 
 type Config struct {
@@ -59,6 +71,7 @@ type Config struct {
 		Enabled        bool     `json:"enabled"`
 		Interval       duration `json:"interval"`
 		MaxPairs       int      `json:"max_pairs"`
+		MaxDataAge     duration `json:"max_data_age"`
 		MinVolume24h   float64  `json:"min_volume_24h"`
 		MinPriceChange float64  `json:"min_price_change"`
 		IncludeSymbols []string `json:"include_symbols"`
@@ -107,10 +120,13 @@ func (s *CogneeFeedbackSystem) Stop() error {
 	return nil
 }
 
-func NewPlatform() *Platform {
+// NewPlatform creates the platform on the shared kline service, which its
+// owner starts and stops
+func NewPlatform(klines *kline.Service) *Platform {
 	config := loadConfig()
 	return &Platform{
 		config: config,
+		klines: klines,
 	}
 }
 
@@ -118,11 +134,18 @@ func (p *Platform) Start() error {
 	logrus.Info("Starting GOBOT platform with Meme Coin Screener...")
 
 	p.stopChan = make(chan struct{})
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.calls = callpolicy.Default()
 
 	if err := p.initBinanceClient(); err != nil {
 		return fmt.Errorf("failed to initialize Binance client: %w", err)
 	}
 
+	p.klines.Watch(p.config.Screener.IncludeSymbols...)
+
+	p.symbolRules = symbolrules.New(symbolrules.Config{}, binance.NewFuturesSymbolRulesSource(p.client))
+	p.symbolRules.Watch(p.config.Screener.IncludeSymbols...)
+
 	if err := p.initWAL(); err != nil {
 		logrus.WithError(err).Warn("Failed to initialize WAL, continuing without it")
 	}
@@ -144,7 +167,7 @@ func (p *Platform) Start() error {
 	}
 
 	if p.reconciler != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := p.calls.Context(p.ctx, callpolicy.Reconcile)
 		defer cancel()
 
 		logrus.Info("Running startup reconciliation for ghost positions...")
@@ -193,11 +216,18 @@ func (p *Platform) Stop(ctx context.Context) error {
 	if p.stopChan != nil {
 		close(p.stopChan)
 	}
+	if p.cancel != nil {
+		p.cancel()
+	}
 
 	if p.screener != nil {
 		p.screener.Stop()
 	}
 
+	if p.symbolRules != nil {
+		p.symbolRules.Stop()
+	}
+
 	if p.brain != nil {
 		if err := p.brain.Stop(); err != nil {
 			logrus.WithError(err).Error("Failed to stop brain engine")
@@ -243,7 +273,7 @@ func (p *Platform) initBinanceClient() error {
 		p.client = futures.NewClient(apiKey, apiSecret)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := p.calls.Context(p.ctx, callpolicy.Exchange)
 	defer cancel()
 
 	_, err := p.client.NewExchangeInfoService().Do(ctx)
@@ -309,6 +339,7 @@ func (p *Platform) initScreener() error {
 		screener.WithAssetFilter(filter),
 		screener.WithInterval(p.config.Screener.Interval.Duration),
 		screener.WithMaxPairs(p.config.Screener.MaxPairs),
+		screener.WithMaxDataAge(p.config.Screener.MaxDataAge.Duration),
 		screener.WithSortBy("volatility"),
 	)
 
@@ -320,10 +351,18 @@ func (p *Platform) GetScreener() *screener.Screener {
 	return p.screener
 }
 
+func (p *Platform) GetKlineService() *kline.Service {
+	return p.klines
+}
+
+func (p *Platform) GetSymbolRules() *symbolrules.Registry {
+	return p.symbolRules
+}
+
 func (p *Platform) initPositionManager() error {
 	logrus.Info("Initializing position manager...")
 
-	p.positionMgr = position.NewPositionManager(p.client, p.brain)
+	p.positionMgr = position.NewPositionManager(p.client, p.brain, p.klines)
 
 	logrus.Info("Position manager initialized")
 	return nil
@@ -342,6 +381,11 @@ func (p *Platform) startComponents() error {
 		return fmt.Errorf("failed to start brain engine: %w", err)
 	}
 
+	if err := p.symbolRules.Start(p.ctx); err != nil {
+		return fmt.Errorf("failed to preload symbol rules: %w", err)
+	}
+	logrus.WithField("symbols", p.symbolRules.Len()).Info("Symbol trading rules preloaded")
+
 	if p.screener != nil {
 		logrus.Info("Starting screener...")
 	}
@@ -417,6 +461,9 @@ func (p *Platform) screenerStatsLoop() {
 		select {
 		case <-ticker.C:
 			p.logScreenerStats()
+			if p.screener != nil {
+				p.symbolRules.Watch(p.screener.GetActivePairs()...)
+			}
 		case <-p.stopChan:
 			return
 		}
@@ -433,8 +480,16 @@ func (p *Platform) logScreenerStats() {
 			"active_pairs": stats.ActivePairs,
 			"avg_volume":   stats.AvgVolume,
 			"avg_change":   stats.AvgChange,
+			"stale_pairs":  stats.StalePairs,
+			"stale_total":  stats.StaleSkipsTotal,
+			"oldest_age":   stats.OldestDataAge.Round(time.Second),
+			"deferred":     stats.DeferredScans,
 			"pairs":        pairs,
 		}).Debug("Screener stats")
+
+		if len(stats.StalePairs) > 0 {
+			logrus.WithField("symbols", stats.StalePairs).Warn("⏸️ Screener skipped pairs with stale tickers")
+		}
 	}
 }
 
@@ -464,6 +519,7 @@ func loadConfig() *Config {
 	config.Screener.Enabled = getEnvBool("SCREENER_ENABLED", true)
 	config.Screener.Interval.Duration = time.Duration(getEnvInt("SCREENER_INTERVAL_SECONDS", 300)) * time.Second
 	config.Screener.MaxPairs = getEnvInt("SCREENER_MAX_PAIRS", 5)
+	config.Screener.MaxDataAge.Duration = time.Duration(getEnvInt("MAX_DATA_AGE_SECONDS", 120)) * time.Second
 	config.Screener.MinVolume24h = getEnvFloat("SCREENER_MIN_VOLUME_24H", 5000000)
 	config.Screener.MinPriceChange = getEnvFloat("SCREENER_MIN_PRICE_CHANGE", 5.0)
 	config.Screener.IncludeSymbols = getEnvSlice("SCREENER_INCLUDE_SYMBOLS")
@@ -523,8 +579,7 @@ func (p *Platform) startSafeStopMonitor() {
 		"check_interval":    p.config.SafeStop.CheckInterval,
 	}).Info("Starting Safe-Stop balance monitor")
 
-	ctx := context.Background()
-	initialBalance, err := p.getCurrentBalance(ctx)
+	initialBalance, err := p.getCurrentBalance(p.ctx)
 	if err != nil {
 		logrus.WithError(err).Warn("Could not fetch initial balance for Safe-Stop")
 		return
@@ -549,8 +604,7 @@ func (p *Platform) monitorBalance() {
 				return
 			}
 
-			ctx := context.Background()
-			currentBalance, err := p.getCurrentBalance(ctx)
+			currentBalance, err := p.getCurrentBalance(p.ctx)
 			if err != nil {
 				logrus.WithError(err).Error("Failed to fetch balance for Safe-Stop")
 				continue
@@ -594,6 +648,9 @@ func (p *Platform) monitorBalance() {
 }
 
 func (p *Platform) getCurrentBalance(ctx context.Context) (float64, error) {
+	ctx, cancel := p.calls.Context(ctx, callpolicy.Account)
+	defer cancel()
+
 	account, err := p.client.NewGetAccountService().Do(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch account: %w", err)
@@ -649,7 +706,7 @@ func (p *Platform) softReconciliationLoop() {
 		select {
 		case <-ticker.C:
 			if p.reconciler != nil {
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				ctx, cancel := p.calls.Context(p.ctx, callpolicy.Reconcile)
 				if err := p.reconciler.SoftReconcile(ctx); err != nil {
 					logrus.WithError(err).Error("Soft reconciliation failed")
 				}
@@ -0,0 +1,121 @@
+package state
+
+import "sync"
+
+// Exits journals the state's open positions as the exchange closes them.
+// It is fed the account stream: fills of orders against a position's side,
+// such as its protective stop, take profit or a reduce-only close, and the
+// position amounts of account updates. A position is closed at the average
+// price of its closing fills once they cover its size, or once the exchange
+// reports it flat, whichever event arrives first.
+type Exits struct {
+	state *TradingState
+
+	mu    sync.Mutex
+	fills map[string]exitFills
+	flat  map[string]bool
+}
+
+// exitFills sums the closing fills of one position. Fills carry the order's
+// accumulated quantity, so they are kept per order.
+type exitFills map[int64]orderFill
+
+type orderFill struct {
+	qty   float64
+	price float64
+}
+
+func (f exitFills) total() (qty, price float64) {
+	notional := 0.0
+	for _, o := range f {
+		qty += o.qty
+		notional += o.qty * o.price
+	}
+	if qty > 0 {
+		price = notional / qty
+	}
+	return qty, price
+}
+
+// NewExits creates an exit tracker closing positions in s
+func NewExits(s *TradingState) *Exits {
+	return &Exits{
+		state: s,
+		fills: make(map[string]exitFills),
+		flat:  make(map[string]bool),
+	}
+}
+
+// Fill records an order update on symbol: filled is the order's accumulated
+// quantity at avgPrice. Orders on the open position's own side are entries
+// and are ignored.
+func (x *Exits) Fill(symbol string, orderID int64, side string, filled, avgPrice float64) {
+	pos, ok := x.open(symbol)
+	if !ok || filled <= 0 || avgPrice <= 0 || sameSide(pos.Side, side) {
+		return
+	}
+
+	x.mu.Lock()
+	fills := x.fills[symbol]
+	if fills == nil {
+		fills = make(exitFills)
+		x.fills[symbol] = fills
+	}
+	fills[orderID] = orderFill{qty: filled, price: avgPrice}
+	qty, price := fills.total()
+	done := x.flat[symbol] || qty >= pos.Size*(1-1e-9)
+	x.mu.Unlock()
+
+	if done {
+		x.close(symbol, price)
+	}
+}
+
+// Position records the amount the exchange reports held in symbol. At zero
+// the position is closed at its closing fills, or, before any arrived, on
+// the first one that does.
+func (x *Exits) Position(symbol string, amount float64) {
+	if amount != 0 {
+		return
+	}
+	if _, ok := x.open(symbol); !ok {
+		return
+	}
+
+	x.mu.Lock()
+	_, price := x.fills[symbol].total()
+	if price <= 0 {
+		x.flat[symbol] = true
+	}
+	x.mu.Unlock()
+
+	if price > 0 {
+		x.close(symbol, price)
+	}
+}
+
+func (x *Exits) close(symbol string, price float64) {
+	x.mu.Lock()
+	delete(x.fills, symbol)
+	delete(x.flat, symbol)
+	x.mu.Unlock()
+
+	x.state.ClosePosition(symbol, price)
+}
+
+func (x *Exits) open(symbol string) (Position, bool) {
+	x.state.mu.RLock()
+	defer x.state.mu.RUnlock()
+
+	for _, p := range x.state.CurrentPositions {
+		if p.Symbol == symbol {
+			return p, true
+		}
+	}
+	return Position{}, false
+}
+
+func sameSide(position, order string) bool {
+	long := position == "BUY" || position == "LONG"
+	return long == (order == "BUY")
+}
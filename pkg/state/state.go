@@ -1,20 +1,30 @@
 package state
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// storeTimeout bounds a single load or save against the store.
+const storeTimeout = 10 * time.Second
+
 type TradingState struct {
 	mu           sync.RWMutex
-	filePath     string
+	saveMu       sync.Mutex
+	store        Store
 	dirty        bool
 	lastSave     time.Time
 	saveInterval time.Duration
+	onTrade      []func(Trade)
+	namespace    string
 
 	Capital           float64
 	TotalTrades       int
@@ -32,6 +42,34 @@ type TradingState struct {
 	LastAPIErrorTime  time.Time
 	IsHalted          bool
 	HaltReason        string
+
+	// Suspensions stop single strategies or symbols from opening positions
+	// without halting everything, keyed by upper-cased strategy name or symbol.
+	Suspensions map[string]Suspension
+
+	// ProfitLock is the day's intraday profit lock, kept so a restart does
+	// not forget a lock that has already tripped.
+	ProfitLock ProfitLock
+}
+
+// Suspension records why and by what a strategy or symbol was suspended:
+// file, api or telegram.
+type Suspension struct {
+	Reason string    `json:"reason"`
+	Source string    `json:"source"`
+	Since  time.Time `json:"since"`
+}
+
+// ProfitLock tracks one UTC day of account equity against the profit lock:
+// where it opened, its peak, and whether the lock is armed or has tripped.
+type ProfitLock struct {
+	Day      string    `json:"day"`
+	Open     float64   `json:"open"`
+	Peak     float64   `json:"peak"`
+	Armed    bool      `json:"armed"`
+	ArmedAt  time.Time `json:"armed_at,omitempty"`
+	Locked   bool      `json:"locked"`
+	LockedAt time.Time `json:"locked_at,omitempty"`
 }
 
 type Position struct {
@@ -42,6 +80,21 @@ type Position struct {
 	StopLoss   float64   `json:"stop_loss"`
 	TakeProfit float64   `json:"take_profit"`
 	OpenTime   time.Time `json:"open_time"`
+	Confidence float64   `json:"confidence,omitempty"`
+	Reasoning  string    `json:"reasoning,omitempty"`
+
+	// Components holds the signal inputs that drove the entry, keyed by name,
+	// so realized PnL can be attributed to them once the position closes.
+	Components map[string]float64 `json:"components,omitempty"`
+	// FillPrice is the entry's average fill, EntryPrice the price the
+	// signal asked for.
+	FillPrice float64 `json:"fill_price,omitempty"`
+	// Idea names who submitted the external idea the position was entered
+	// on, if any.
+	Idea string `json:"idea,omitempty"`
+	// Strategy names the strategy whose capital allocation the position's
+	// notional, Size at EntryPrice, is booked against, if any.
+	Strategy string `json:"strategy,omitempty"`
 }
 
 type Trade struct {
@@ -59,13 +112,53 @@ type Trade struct {
 	EntryTime  time.Time `json:"entry_time"`
 	ExitTime   time.Time `json:"exit_time"`
 	Status     string    `json:"status"`
+
+	Components map[string]float64 `json:"components,omitempty"`
+	FillPrice  float64            `json:"fill_price,omitempty"`
+	// Namespace names the bot that made the trade.
+	Namespace string `json:"namespace,omitempty"`
+	// Idea is the position's Idea.
+	Idea string `json:"idea,omitempty"`
+	// Strategy is the position's Strategy.
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// SlippageBps returns how much worse than EntryPrice the entry filled, in
+// basis points, and false for trades journaled without their fill.
+func (t Trade) SlippageBps() (float64, bool) {
+	if t.FillPrice <= 0 || t.EntryPrice <= 0 {
+		return 0, false
+	}
+	bps := (t.FillPrice - t.EntryPrice) / t.EntryPrice * 10000
+	if t.Side == "SELL" || t.Side == "SHORT" {
+		bps = -bps
+	}
+	return bps, true
+}
+
+// ComponentStats summarises realized trades by signal component. Correlation
+// is the Pearson correlation between the component's value and trade PnL.
+type ComponentStats struct {
+	Component   string  `json:"component"`
+	Trades      int     `json:"trades"`
+	Wins        int     `json:"wins"`
+	WinRate     float64 `json:"win_rate"`
+	PnL         float64 `json:"pnl"`
+	AvgPnL      float64 `json:"avg_pnl"`
+	Correlation float64 `json:"correlation"`
 }
 
+// StateConfig places the state. It is saved to StateFile in StateDir unless
+// Store is set; any other Store is journaled in StateDir so saves the store
+// missed are not lost.
 type StateConfig struct {
 	StateDir     string
 	StateFile    string
 	SaveInterval time.Duration
 	MaxHistory   int
+	Store        Store
+	// Namespace labels every trade journaled.
+	Namespace string
 }
 
 func NewStateManager(cfg StateConfig) (*TradingState, error) {
@@ -82,17 +175,28 @@ func NewStateManager(cfg StateConfig) (*TradingState, error) {
 		cfg.MaxHistory = 1000
 	}
 
+	if err := os.MkdirAll(cfg.StateDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	var store Store = NewFileStore(filepath.Join(cfg.StateDir, cfg.StateFile))
+	if cfg.Store != nil {
+		store = NewJournaledStore(cfg.Store, journalPath(cfg.StateDir, cfg.StateFile))
+	}
+
 	state := &TradingState{
-		filePath:     filepath.Join(cfg.StateDir, cfg.StateFile),
+		store:        store,
 		saveInterval: cfg.SaveInterval,
+		namespace:    cfg.Namespace,
 		Capital:      100,
 	}
 
-	if err := os.MkdirAll(cfg.StateDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create state directory: %w", err)
-	}
-
 	if err := state.Load(); err != nil {
+		// A shared store that cannot be read may still hold the state other
+		// instances rely on; only a local file is started over.
+		if cfg.Store != nil {
+			return nil, fmt.Errorf("failed to load state: %w", err)
+		}
 		state.Save()
 	}
 
@@ -105,12 +209,14 @@ func (s *TradingState) Load() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	data, err := os.ReadFile(s.filePath)
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	data, err := s.store.Get(ctx)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("failed to read state file: %w", err)
+		return err
+	}
+	if data == nil {
+		return nil
 	}
 
 	if err := json.Unmarshal(data, s); err != nil {
@@ -120,27 +226,75 @@ func (s *TradingState) Load() error {
 	return nil
 }
 
+// Save writes the state to the store. The state is only locked while it is
+// marshaled, so a slow or unreachable store never holds up trading; saves
+// are serialized so an older snapshot never overwrites a newer one.
 func (s *TradingState) Save() error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
 
+	s.mu.Lock()
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
+		s.mu.Unlock()
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
+	// Changes made while the store is written mark the state dirty again.
+	s.dirty = false
+	s.mu.Unlock()
 
-	tmpPath := s.filePath + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write state file: %w", err)
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := s.store.Put(ctx, data); err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return err
 	}
 
-	if err := os.Rename(tmpPath, s.filePath); err != nil {
-		return fmt.Errorf("failed to rename state file: %w", err)
+	s.mu.Lock()
+	s.lastSave = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Snapshot returns the state as JSON for replication to a standby instance.
+func (s *TradingState) Snapshot() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return json.Marshal(s)
+}
+
+// Restore replaces the state with a snapshot taken by Snapshot and marks it
+// for saving, so a standby keeps an up-to-date local copy.
+func (s *TradingState) Restore(data []byte) error {
+	var restored TradingState
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return fmt.Errorf("failed to parse state snapshot: %w", err)
 	}
 
-	s.dirty = false
-	s.lastSave = time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	s.Capital = restored.Capital
+	s.TotalTrades = restored.TotalTrades
+	s.Wins = restored.Wins
+	s.Losses = restored.Losses
+	s.TotalPnL = restored.TotalPnL
+	s.DailyPnL = restored.DailyPnL
+	s.WeeklyPnL = restored.WeeklyPnL
+	s.CurrentPositions = restored.CurrentPositions
+	s.TradeHistory = restored.TradeHistory
+	s.LastTradeTime = restored.LastTradeTime
+	s.LastSignalTime = restored.LastSignalTime
+	s.ConsecutiveLosses = restored.ConsecutiveLosses
+	s.APIErrorCount = restored.APIErrorCount
+	s.LastAPIErrorTime = restored.LastAPIErrorTime
+	s.IsHalted = restored.IsHalted
+	s.HaltReason = restored.HaltReason
+	s.Suspensions = restored.Suspensions
+	s.ProfitLock = restored.ProfitLock
+	s.dirty = true
 	return nil
 }
 
@@ -164,9 +318,39 @@ func (s *TradingState) MarkDirty() {
 	s.mu.Unlock()
 }
 
-func (s *TradingState) AddTrade(trade Trade) {
+// OnTrade registers fn to be called with every trade added to the journal.
+func (s *TradingState) OnTrade(fn func(Trade)) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.onTrade = append(s.onTrade, fn)
+}
+
+// Trades returns a copy of the trade journal, oldest first.
+func (s *TradingState) Trades() []Trade {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	trades := make([]Trade, len(s.TradeHistory))
+	copy(trades, s.TradeHistory)
+	return trades
+}
+
+// Positions returns a copy of the open positions.
+func (s *TradingState) Positions() []Position {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	positions := make([]Position, len(s.CurrentPositions))
+	copy(positions, s.CurrentPositions)
+	return positions
+}
+
+func (s *TradingState) AddTrade(trade Trade) {
+	s.mu.Lock()
+
+	if trade.Namespace == "" {
+		trade.Namespace = s.namespace
+	}
 
 	s.TradeHistory = append(s.TradeHistory, trade)
 	if len(s.TradeHistory) > 1000 {
@@ -188,6 +372,12 @@ func (s *TradingState) AddTrade(trade Trade) {
 
 	s.LastTradeTime = trade.ExitTime
 	s.dirty = true
+	handlers := s.onTrade
+	s.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(trade)
+	}
 }
 
 func (s *TradingState) AddPosition(pos Position) {
@@ -198,17 +388,139 @@ func (s *TradingState) AddPosition(pos Position) {
 	s.dirty = true
 }
 
+// ClosePosition removes the open position for symbol and journals it as a
+// trade closed at exitPrice, keeping its signal components for attribution.
+// PnL is realized on the position's base quantity from its fill, or from
+// EntryPrice when the fill is unknown.
 func (s *TradingState) ClosePosition(symbol string, exitPrice float64) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	for i, pos := range s.CurrentPositions {
-		if pos.Symbol == symbol {
+	var pos Position
+	found := false
+	for i, p := range s.CurrentPositions {
+		if p.Symbol == symbol {
+			pos, found = p, true
 			s.CurrentPositions = append(s.CurrentPositions[:i], s.CurrentPositions[i+1:]...)
 			s.dirty = true
-			return
+			break
 		}
 	}
+	s.mu.Unlock()
+
+	if !found || exitPrice <= 0 || pos.EntryPrice <= 0 {
+		return
+	}
+
+	entry := pos.EntryPrice
+	if pos.FillPrice > 0 {
+		entry = pos.FillPrice
+	}
+	move := exitPrice - entry
+	if pos.Side == "SELL" || pos.Side == "SHORT" {
+		move = -move
+	}
+
+	s.AddTrade(Trade{
+		Symbol:     pos.Symbol,
+		Side:       pos.Side,
+		Size:       pos.Size,
+		EntryPrice: pos.EntryPrice,
+		ExitPrice:  exitPrice,
+		PnL:        move * pos.Size,
+		PnLPercent: move / entry * 100,
+		StopLoss:   pos.StopLoss,
+		TakeProfit: pos.TakeProfit,
+		Confidence: pos.Confidence,
+		Reasoning:  pos.Reasoning,
+		EntryTime:  pos.OpenTime,
+		ExitTime:   time.Now(),
+		Status:     "CLOSED",
+		Components: pos.Components,
+		FillPrice:  pos.FillPrice,
+		Idea:       pos.Idea,
+		Strategy:   pos.Strategy,
+	})
+}
+
+// Attribution reports, for every signal component seen in the trade history,
+// how the trades it contributed to performed and how strongly its value
+// correlates with PnL. Results are sorted by correlation, strongest first.
+func (s *TradingState) Attribution() []ComponentStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var trades []Trade
+	names := make(map[string]bool)
+	for _, t := range s.TradeHistory {
+		if len(t.Components) == 0 {
+			continue
+		}
+		trades = append(trades, t)
+		for name := range t.Components {
+			names[name] = true
+		}
+	}
+
+	pnl := make([]float64, len(trades))
+	for i, t := range trades {
+		pnl[i] = t.PnL
+	}
+
+	stats := make([]ComponentStats, 0, len(names))
+	for name := range names {
+		cs := ComponentStats{Component: name}
+		values := make([]float64, len(trades))
+		for i, t := range trades {
+			values[i] = t.Components[name]
+			if values[i] == 0 {
+				continue
+			}
+			cs.Trades++
+			cs.PnL += t.PnL
+			if t.PnL > 0 {
+				cs.Wins++
+			}
+		}
+		if cs.Trades > 0 {
+			cs.WinRate = float64(cs.Wins) / float64(cs.Trades) * 100
+			cs.AvgPnL = cs.PnL / float64(cs.Trades)
+		}
+		cs.Correlation = correlation(values, pnl)
+		stats = append(stats, cs)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Correlation != stats[j].Correlation {
+			return stats[i].Correlation > stats[j].Correlation
+		}
+		return stats[i].Component < stats[j].Component
+	})
+	return stats
+}
+
+func correlation(x, y []float64) float64 {
+	n := float64(len(x))
+	if n < 2 {
+		return 0
+	}
+
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx, my = mx/n, my/n
+
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
 }
 
 func (s *TradingState) UpdateCapital(pnl float64) {
@@ -274,6 +586,77 @@ func (s *TradingState) Resume() {
 	s.dirty = true
 }
 
+// Suspend stops target, a strategy name or symbol, from opening positions
+// until Unsuspend. Suspending an already suspended target keeps its
+// original time.
+func (s *TradingState) Suspend(target, reason, source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := suspensionKey(target)
+	since := time.Now()
+	if prev, ok := s.Suspensions[key]; ok {
+		since = prev.Since
+	}
+	if s.Suspensions == nil {
+		s.Suspensions = make(map[string]Suspension)
+	}
+	s.Suspensions[key] = Suspension{Reason: reason, Source: source, Since: since}
+	s.dirty = true
+}
+
+// Unsuspend lifts a suspension and reports whether there was one.
+func (s *TradingState) Unsuspend(target string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := suspensionKey(target)
+	if _, ok := s.Suspensions[key]; !ok {
+		return false
+	}
+	delete(s.Suspensions, key)
+	s.dirty = true
+	return true
+}
+
+// IsSuspended reports whether target, a strategy name or symbol, is suspended.
+func (s *TradingState) IsSuspended(target string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.Suspensions[suspensionKey(target)]
+	return ok
+}
+
+// ActiveSuspensions returns a copy of the current suspensions.
+func (s *TradingState) ActiveSuspensions() map[string]Suspension {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string]Suspension, len(s.Suspensions))
+	for k, v := range s.Suspensions {
+		out[k] = v
+	}
+	return out
+}
+
+// DayLock returns the persisted profit lock day.
+func (s *TradingState) DayLock() ProfitLock {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ProfitLock
+}
+
+func (s *TradingState) SetDayLock(l ProfitLock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ProfitLock = l
+	s.dirty = true
+}
+
+func suspensionKey(target string) string {
+	return strings.ToUpper(strings.TrimSpace(target))
+}
+
 func (s *TradingState) GetStats() StateStats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -0,0 +1,120 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+// flakyStore is a remote backend that can be taken down.
+type flakyStore struct {
+	data []byte
+	down bool
+}
+
+func (s *flakyStore) Put(ctx context.Context, data []byte) error {
+	if s.down {
+		return errors.New("connection refused")
+	}
+	s.data = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *flakyStore) Get(ctx context.Context) ([]byte, error) {
+	if s.down {
+		return nil, errors.New("connection refused")
+	}
+	return s.data, nil
+}
+
+func TestJournaledStore_KeepsSavesTheBackendMissed(t *testing.T) {
+	dir := t.TempDir()
+	backend := &flakyStore{}
+	st, err := NewStateManager(StateConfig{StateDir: dir, Store: backend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Halt("first")
+	if err := st.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	backend.down = true
+	st.Halt("second")
+	if err := st.Save(); err == nil {
+		t.Fatal("a failed backend write should be reported")
+	}
+	st.Halt("third")
+	if err := st.Save(); err == nil {
+		t.Fatal("a failed backend write should be reported")
+	}
+	if journal, _ := os.ReadFile(journalPath(dir, "trading_state.json")); bytes.Count(journal, []byte("\n")) != 1 {
+		t.Fatalf("the journal should hold only the newest save, got %q", journal)
+	}
+	if _, err := NewStateManager(StateConfig{StateDir: t.TempDir(), Store: backend}); err == nil {
+		t.Fatal("an unreadable store must not be started over")
+	}
+
+	backend.down = false
+	restarted, err := NewStateManager(StateConfig{StateDir: dir, Store: backend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason := restarted.GetStats().HaltReason; reason != "third" {
+		t.Fatalf("restored halt reason %q, want the journaled save", reason)
+	}
+	if other, _ := NewStateManager(StateConfig{StateDir: t.TempDir(), Store: backend}); other.GetStats().HaltReason != "third" {
+		t.Fatal("the journaled save was not replayed to the backend")
+	}
+}
+
+func TestAttribution_CorrelatesComponentsWithPnL(t *testing.T) {
+	s := &TradingState{}
+	s.AddTrade(Trade{PnL: 10, Components: map[string]float64{"volume_spike": 1, "llm_boost": 0.1}})
+	s.AddTrade(Trade{PnL: 8, Components: map[string]float64{"volume_spike": 1}})
+	s.AddTrade(Trade{PnL: -5, Components: map[string]float64{"llm_boost": 0.2}})
+	s.AddTrade(Trade{PnL: 3})
+
+	stats := s.Attribution()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 components, got %+v", stats)
+	}
+
+	top := stats[0]
+	if top.Component != "volume_spike" || top.Trades != 2 || top.PnL != 18 || top.Correlation <= 0.9 {
+		t.Errorf("unexpected top component: %+v", top)
+	}
+	if stats[1].Component != "llm_boost" || stats[1].Correlation >= 0 {
+		t.Errorf("expected llm_boost to correlate negatively, got %+v", stats[1])
+	}
+}
+
+func TestExits_JournalsPositionsClosedByTheExchange(t *testing.T) {
+	s := &TradingState{}
+	s.AddPosition(Position{Symbol: "DOGEUSDT", Side: "BUY", Size: 100, EntryPrice: 0.1})
+	s.AddPosition(Position{Symbol: "PEPEUSDT", Side: "SELL", Size: 10, EntryPrice: 2})
+	x := NewExits(s)
+
+	x.Fill("DOGEUSDT", 1, "BUY", 100, 0.1) // the entry itself
+	x.Fill("DOGEUSDT", 2, "SELL", 40, 0.12)
+	if len(s.TradeHistory) != 0 {
+		t.Fatal("a partial close must keep the position open")
+	}
+	x.Fill("DOGEUSDT", 2, "SELL", 100, 0.12)
+
+	x.Position("PEPEUSDT", 0)
+	x.Fill("PEPEUSDT", 3, "BUY", 4, 1.5)
+
+	trades := s.TradeHistory
+	if len(trades) != 2 || len(s.CurrentPositions) != 0 {
+		t.Fatalf("expected both positions journaled, got %+v", trades)
+	}
+	if d := trades[0].PnL - 2; d > 1e-9 || d < -1e-9 {
+		t.Errorf("long closed at 0.12 should realize 2, got %+v", trades[0])
+	}
+	if trades[1].ExitPrice != 1.5 || trades[1].PnL != 5 {
+		t.Errorf("short closed flat at 1.5 should realize 5, got %+v", trades[1])
+	}
+}
@@ -0,0 +1,154 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Store persists the serialized trading state. Get returns nil data when
+// nothing was stored yet.
+type Store interface {
+	Put(ctx context.Context, data []byte) error
+	Get(ctx context.Context) ([]byte, error)
+}
+
+// FileStore keeps the state in a single JSON file, replaced atomically on
+// every save.
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Put(ctx context.Context, data []byte) error {
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to rename state file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Get(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return data, nil
+}
+
+// journalEntry is one save waiting to reach the backend.
+type journalEntry struct {
+	Seq  int64           `json:"seq"`
+	At   time.Time       `json:"at"`
+	Data json.RawMessage `json:"data"`
+}
+
+// JournaledStore writes every save to a local journal, synced to disk,
+// before handing it to a remote backend, and clears the journal once the
+// backend has it. A save the backend never acknowledged, because it was
+// down or the process died mid-write, is still in the journal at the next
+// Get, which returns it and retries the write. Only the newest save is
+// kept, so the journal stays one entry however long the backend is down.
+type JournaledStore struct {
+	backend Store
+	path    string
+	seq     int64
+}
+
+func NewJournaledStore(backend Store, path string) *JournaledStore {
+	return &JournaledStore{backend: backend, path: path}
+}
+
+func (s *JournaledStore) Put(ctx context.Context, data []byte) error {
+	s.seq++
+	line, err := json.Marshal(journalEntry{Seq: s.seq, At: time.Now(), Data: json.RawMessage(data)})
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	// The entry replaces the journal rather than being appended to it:
+	// a later save supersedes every earlier one.
+	tmpPath := s.path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open state journal: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write state journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync state journal: %w", err)
+	}
+	f.Close()
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace state journal: %w", err)
+	}
+
+	if err := s.backend.Put(ctx, data); err != nil {
+		return fmt.Errorf("state kept in journal, backend write failed: %w", err)
+	}
+	return os.Truncate(s.path, 0)
+}
+
+func (s *JournaledStore) Get(ctx context.Context) ([]byte, error) {
+	pending, err := s.pending()
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		if err := s.backend.Put(ctx, pending); err == nil {
+			os.Truncate(s.path, 0)
+		}
+		return pending, nil
+	}
+	return s.backend.Get(ctx)
+}
+
+// pending returns the newest journaled save, nil when the journal is empty.
+// Journals written before saves replaced them may hold several entries,
+// and a torn last line from a crash mid-append; the latter is skipped.
+func (s *JournaledStore) pending() ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state journal: %w", err)
+	}
+
+	var last []byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Seq > s.seq {
+			s.seq = entry.Seq
+		}
+		last = entry.Data
+	}
+	return last, nil
+}
+
+// journalPath is where a remote backend's journal lives in dir.
+func journalPath(dir, file string) string {
+	return filepath.Join(dir, file+".journal")
+}
@@ -0,0 +1,93 @@
+package symlock
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Locks serializes work on each symbol's position. Whatever opens, closes or
+// amends a position holds the symbol's lock for the whole operation, so two
+// loops acting on one symbol take turns while different symbols proceed in
+// parallel. A nil *Locks serializes nothing.
+type Locks struct {
+	mu    sync.Mutex
+	locks map[string]*entry
+}
+
+type entry struct {
+	sem  chan struct{}
+	refs int
+}
+
+func New() *Locks {
+	return &Locks{locks: make(map[string]*entry)}
+}
+
+// Lock waits until symbol is free or ctx is done. The returned func releases
+// the lock and must be called exactly once.
+func (l *Locks) Lock(ctx context.Context, symbol string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	key, e := l.acquire(symbol)
+	select {
+	case e.sem <- struct{}{}:
+		return func() { l.release(key, e) }, nil
+	case <-ctx.Done():
+		l.unref(key, e)
+		return nil, ctx.Err()
+	}
+}
+
+// TryLock takes the lock only if symbol is free right now.
+func (l *Locks) TryLock(symbol string) (func(), bool) {
+	if l == nil {
+		return func() {}, true
+	}
+	key, e := l.acquire(symbol)
+	select {
+	case e.sem <- struct{}{}:
+		return func() { l.release(key, e) }, true
+	default:
+		l.unref(key, e)
+		return nil, false
+	}
+}
+
+// Held returns how many symbols are locked or waited on.
+func (l *Locks) Held() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.locks)
+}
+
+func (l *Locks) acquire(symbol string) (string, *entry) {
+	key := strings.ToUpper(symbol)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.locks[key]
+	if e == nil {
+		e = &entry{sem: make(chan struct{}, 1)}
+		l.locks[key] = e
+	}
+	e.refs++
+	return key, e
+}
+
+func (l *Locks) release(key string, e *entry) {
+	<-e.sem
+	l.unref(key, e)
+}
+
+func (l *Locks) unref(key string, e *entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.refs--
+	if e.refs == 0 {
+		delete(l.locks, key)
+	}
+}
@@ -0,0 +1,66 @@
+package symlock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLocksSerializePerSymbol(t *testing.T) {
+	l := New()
+
+	unlock, err := l.Lock(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.TryLock("btcusdt"); ok {
+		t.Fatal("symbol locked twice")
+	}
+	other, ok := l.TryLock("ETHUSDT")
+	if !ok {
+		t.Fatal("a different symbol should not wait")
+	}
+	other()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Lock(ctx, "BTCUSDT"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 1; i <= 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			release, err := l.Lock(context.Background(), "BTCUSDT")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			release()
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	if len(order) != 0 {
+		t.Error("waiter ran while the symbol was held")
+	}
+	mu.Unlock()
+	unlock()
+	wg.Wait()
+
+	if len(order) != 2 || l.Held() != 0 {
+		t.Errorf("order %v, %d locks left", order, l.Held())
+	}
+
+	var none *Locks
+	release, _ := none.Lock(context.Background(), "BTCUSDT")
+	release()
+}
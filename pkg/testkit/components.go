@@ -0,0 +1,185 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/britej3/gobot/domain/executor"
+	"github.com/britej3/gobot/domain/selector"
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// ErrUnknownOrder is returned by Exchange for an order it never took.
+var ErrUnknownOrder = errors.New("testkit: unknown order")
+
+// Universe returns the market of every scenario at its last candle, keyed
+// by symbol, as a selector receives them.
+func Universe(scenarios ...Scenario) map[string]*trade.MarketData {
+	out := make(map[string]*trade.MarketData, len(scenarios))
+	for _, sc := range scenarios {
+		m := sc.Market(len(sc.Klines) - 1)
+		out[sc.Symbol] = &m
+	}
+	return out
+}
+
+// AssertRanksAbove fails unless sel scores symbol better higher than worse
+// in universe.
+func AssertRanksAbove(t testing.TB, sel selector.Selector, universe map[string]*trade.MarketData, better, worse string) {
+	t.Helper()
+	ctx := context.Background()
+	score := func(symbol string) float64 {
+		t.Helper()
+		m, ok := universe[symbol]
+		if !ok {
+			t.Fatalf("%s is not in the universe", symbol)
+		}
+		s, err := sel.GetScore(ctx, m)
+		if err != nil {
+			t.Fatalf("%s: GetScore(%s): %v", sel.Name(), symbol, err)
+		}
+		return s
+	}
+	if b, w := score(better), score(worse); b <= w {
+		t.Errorf("%s: %s scored %v, not above %s at %v", sel.Name(), better, b, worse, w)
+	}
+}
+
+// AssertSelects fails unless sel selects every symbol in want from
+// universe, and never one outside it.
+func AssertSelects(t testing.TB, sel selector.Selector, universe map[string]*trade.MarketData, want ...string) {
+	t.Helper()
+	assets, err := sel.Select(context.Background(), universe)
+	if err != nil {
+		t.Fatalf("%s: Select: %v", sel.Name(), err)
+	}
+	selected := make(map[string]bool, len(assets))
+	for _, a := range assets {
+		if _, ok := universe[a.Symbol]; !ok {
+			t.Errorf("%s selected %s, which is not in the universe", sel.Name(), a.Symbol)
+		}
+		selected[a.Symbol] = true
+	}
+	for _, symbol := range want {
+		if !selected[symbol] {
+			t.Errorf("%s did not select %s", sel.Name(), symbol)
+		}
+	}
+}
+
+// Exchange is an in-memory exchange for executors. It fills every order in
+// full at Mark, or at the order's price when Mark is zero, and records the
+// orders and closed positions.
+type Exchange struct {
+	Mark    float64
+	Balance float64
+
+	mu     sync.Mutex
+	orders []*trade.Order
+	closed []*trade.Position
+}
+
+func (e *Exchange) CreateOrder(ctx context.Context, order *trade.Order) (*trade.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	filled := *order
+	if filled.ID == "" {
+		filled.ID = fmt.Sprintf("testkit-%d", len(e.orders)+1)
+	}
+	filled.Status = trade.OrderStatusFilled
+	filled.FilledQty = filled.Quantity
+	filled.AvgFillPrice = e.Mark
+	if filled.AvgFillPrice == 0 {
+		filled.AvgFillPrice = filled.Price
+	}
+	e.orders = append(e.orders, &filled)
+	out := filled
+	return &out, nil
+}
+
+func (e *Exchange) CancelOrder(ctx context.Context, orderID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, o := range e.orders {
+		if o.ID == orderID {
+			o.Status = trade.OrderStatusCancelled
+			return nil
+		}
+	}
+	return ErrUnknownOrder
+}
+
+func (e *Exchange) GetOrder(ctx context.Context, orderID string) (*trade.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, o := range e.orders {
+		if o.ID == orderID {
+			out := *o
+			return &out, nil
+		}
+	}
+	return nil, ErrUnknownOrder
+}
+
+func (e *Exchange) ClosePosition(ctx context.Context, position *trade.Position) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	closed := *position
+	e.closed = append(e.closed, &closed)
+	return nil
+}
+
+func (e *Exchange) GetBalance(ctx context.Context) (float64, error) {
+	return e.Balance, nil
+}
+
+// Orders returns the orders sent, oldest first.
+func (e *Exchange) Orders() []trade.Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]trade.Order, len(e.orders))
+	for i, o := range e.orders {
+		out[i] = *o
+	}
+	return out
+}
+
+// Closed returns the positions closed, oldest first.
+func (e *Exchange) Closed() []trade.Position {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]trade.Position, len(e.closed))
+	for i, p := range e.closed {
+		out[i] = *p
+	}
+	return out
+}
+
+// AssertExecutes executes signal on market through ex and fails unless the
+// order it returns is on market's symbol with the signal's size, stop loss
+// and take profit.
+func AssertExecutes(t testing.TB, ex executor.Executor, signal strategy.StrategyResult, market trade.MarketData) *trade.Order {
+	t.Helper()
+	order, err := ex.Execute(context.Background(), signal, market)
+	if err != nil {
+		t.Fatalf("%s: Execute: %v", ex.Name(), err)
+	}
+	if order == nil {
+		t.Fatalf("%s: Execute returned no order", ex.Name())
+	}
+	if order.Symbol != market.Symbol {
+		t.Errorf("%s: order on %s, want %s", ex.Name(), order.Symbol, market.Symbol)
+	}
+	if order.Quantity != signal.PositionSize {
+		t.Errorf("%s: order for %v, signal sized %v", ex.Name(), order.Quantity, signal.PositionSize)
+	}
+	if order.StopLoss != signal.StopLoss || order.TakeProfit != signal.TakeProfit {
+		t.Errorf("%s: order stop %v target %v, signal stop %v target %v", ex.Name(), order.StopLoss, order.TakeProfit, signal.StopLoss, signal.TakeProfit)
+	}
+	return order
+}
@@ -0,0 +1,205 @@
+// Package testkit builds synthetic market scenarios and checks how
+// strategies, selectors and executors behave on them, so a new
+// implementation can be tested on a trend, a reversal, chop and a crash
+// before it sees a live market.
+//
+// The scenarios are golden: every builder draws from a fixed seed, so the
+// same call gives the same candles on every run and a test written against
+// them keeps meaning the same thing.
+package testkit
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/indicator"
+)
+
+// Warmup is the number of flat candles every scenario opens with, enough
+// for every indicator in indicator.Standard to be warm when its first
+// phase starts.
+const Warmup = 150
+
+// Start is the open time of every scenario's first one-minute candle.
+var Start = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// Phase is a named stretch of a scenario, the candles From up to but not
+// including To.
+type Phase struct {
+	From int
+	To   int
+}
+
+// Contains reports whether candle i falls in the phase.
+func (p Phase) Contains(i int) bool {
+	return i >= p.From && i < p.To
+}
+
+// Scenario is a series of one-minute candles with its phases marked, e.g.
+// "crash" in FlashCrash. Every scenario has a "warmup" phase first.
+type Scenario struct {
+	Name   string
+	Symbol string
+	Klines []trade.Kline
+	Phases map[string]Phase
+}
+
+// Phase returns the named phase, panicking on a name the scenario lacks so
+// a misspelt expectation cannot pass vacuously.
+func (s Scenario) Phase(name string) Phase {
+	p, ok := s.Phases[name]
+	if !ok {
+		panic("testkit: scenario " + s.Name + " has no phase " + name)
+	}
+	return p
+}
+
+// Market returns what a strategy sees at the close of candle i: the
+// indicators of indicator.Standard, as the kline service serves them live,
+// and the 24h statistics of the candles up to i.
+func (s Scenario) Market(i int) trade.MarketData {
+	window := s.Klines[:i+1]
+	last := window[len(window)-1]
+	values, _ := indicator.Standard.Compute(window)
+
+	day := window
+	if len(day) > 1440 {
+		day = day[len(day)-1440:]
+	}
+	m := trade.MarketData{
+		Symbol:       s.Symbol,
+		CurrentPrice: last.Close,
+		High24h:      last.High,
+		Low24h:       last.Low,
+		RSI:          values[indicator.RSI14],
+		EMAFast:      values[indicator.EMAFast],
+		EMASlow:      values[indicator.EMASlow],
+		ATR:          values[indicator.ATR14],
+		VWAP:         indicator.VWAP(day),
+		Patterns:     indicator.PatternFeatures(indicator.DetectPatterns(window)),
+		Timestamp:    last.CloseTime,
+	}
+	for _, k := range day {
+		m.High24h = math.Max(m.High24h, k.High)
+		m.Low24h = math.Min(m.Low24h, k.Low)
+		m.Volume24h += k.Volume * k.Close
+	}
+	m.SwingLow, m.SwingHigh = indicator.SwingRange(window, indicator.SwingLookback)
+
+	recent := window
+	if len(recent) > 20 {
+		recent = recent[len(recent)-20:]
+	}
+	for _, k := range recent {
+		m.Volatility += (k.High - k.Low) / k.Close * 100
+	}
+	m.Volatility /= float64(len(recent))
+	return m
+}
+
+// Golden returns every scenario the kit builds.
+func Golden() []Scenario {
+	return []Scenario{
+		TrendDay("TRENDUSDT"),
+		VReversal("VREVUSDT"),
+		Chop("CHOPUSDT"),
+		FlashCrash("CRASHUSDT"),
+	}
+}
+
+// TrendDay climbs steadily for three hours, about 12% in all, with shallow
+// pullbacks. Phases: "trend".
+func TrendDay(symbol string) Scenario {
+	b := newBuilder("trend_day", symbol, 1)
+	b.leg("trend", 180, 0.0008, 0.0015, 1)
+	return b.scenario()
+}
+
+// VReversal sells off about 12% over an hour and recovers it over the next.
+// Phases: "selloff", "recovery".
+func VReversal(symbol string) Scenario {
+	b := newBuilder("v_reversal", symbol, 2)
+	b.leg("selloff", 60, -0.0022, 0.0015, 1.5)
+	b.leg("recovery", 60, 0.0022, 0.0015, 1.5)
+	return b.scenario()
+}
+
+// Chop swings within a few percent of where it started for three hours,
+// pulled back whenever it strays. Phases: "chop".
+func Chop(symbol string) Scenario {
+	b := newBuilder("chop", symbol, 3)
+	anchor := b.price
+	phase := Phase{From: len(b.klines)}
+	for i := 0; i < 180; i++ {
+		b.candle((anchor-b.price)/anchor*0.15, 0.003, 1)
+	}
+	phase.To = len(b.klines)
+	b.phases["chop"] = phase
+	return b.scenario()
+}
+
+// FlashCrash drifts up for an hour, drops about 15% in five candles on ten
+// times the volume, and recovers a third of it. Phases: "calm", "crash",
+// "aftermath".
+func FlashCrash(symbol string) Scenario {
+	b := newBuilder("flash_crash", symbol, 4)
+	b.leg("calm", 60, 0.0001, 0.001, 1)
+	b.leg("crash", 5, -0.032, 0.004, 10)
+	b.leg("aftermath", 60, 0.001, 0.002, 2)
+	return b.scenario()
+}
+
+// builder appends candles to a price path from a fixed seed.
+type builder struct {
+	name, symbol string
+	rng          *rand.Rand
+	price        float64
+	klines       []trade.Kline
+	phases       map[string]Phase
+}
+
+func newBuilder(name, symbol string, seed int64) *builder {
+	b := &builder{
+		name:   name,
+		symbol: symbol,
+		rng:    rand.New(rand.NewSource(seed)),
+		price:  100,
+		phases: make(map[string]Phase),
+	}
+	b.leg("warmup", Warmup, 0, 0.001, 1)
+	return b
+}
+
+// leg adds n candles drifting drift per candle with noise as the standard
+// deviation of each return, on volume times the base volume.
+func (b *builder) leg(name string, n int, drift, noise, volume float64) {
+	phase := Phase{From: len(b.klines)}
+	for i := 0; i < n; i++ {
+		b.candle(drift, noise, volume)
+	}
+	phase.To = len(b.klines)
+	b.phases[name] = phase
+}
+
+func (b *builder) candle(drift, noise, volume float64) {
+	open := b.price
+	close := open * (1 + drift + noise*b.rng.NormFloat64())
+	wick := noise / 2
+	at := Start.Add(time.Duration(len(b.klines)) * time.Minute)
+	b.klines = append(b.klines, trade.Kline{
+		OpenTime:  at,
+		Open:      open,
+		High:      math.Max(open, close) * (1 + wick*math.Abs(b.rng.NormFloat64())),
+		Low:       math.Min(open, close) * (1 - wick*math.Abs(b.rng.NormFloat64())),
+		Close:     close,
+		Volume:    1000 * volume * (1 + 0.2*math.Abs(b.rng.NormFloat64())),
+		CloseTime: at.Add(time.Minute - time.Millisecond),
+	})
+	b.price = close
+}
+
+func (b *builder) scenario() Scenario {
+	return Scenario{Name: b.name, Symbol: b.symbol, Klines: b.klines, Phases: b.phases}
+}
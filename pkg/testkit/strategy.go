@@ -0,0 +1,277 @@
+package testkit
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// Balance is the account balance strategies size positions against in Run.
+const Balance = 10_000.0
+
+// Exit reasons Run records besides the strategy's own.
+const (
+	ExitStopLoss   = "stop_loss"
+	ExitTakeProfit = "take_profit"
+	ExitEnd        = "end_of_scenario"
+)
+
+// Trade is one position a strategy held during a scenario. Entry and Exit
+// are candle indexes.
+type Trade struct {
+	Side       trade.Side
+	Entry      int
+	Exit       int
+	EntryPrice float64
+	ExitPrice  float64
+	StopLoss   float64
+	TakeProfit float64
+	Quantity   float64
+	Reason     string
+	ExitReason string
+}
+
+// PnLPercent is the trade's return on its entry price.
+func (t Trade) PnLPercent() float64 {
+	change := (t.ExitPrice - t.EntryPrice) / t.EntryPrice * 100
+	if t.Side == trade.SideSell {
+		return -change
+	}
+	return change
+}
+
+// Result is what a strategy did over a scenario.
+type Result struct {
+	Scenario Scenario
+	Trades   []Trade
+}
+
+// EnteredDuring returns the trades entered in the named phase.
+func (r Result) EnteredDuring(phase string) []Trade {
+	p := r.Scenario.Phase(phase)
+	var out []Trade
+	for _, t := range r.Trades {
+		if p.Contains(t.Entry) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// PnLPercent sums the return of every trade.
+func (r Result) PnLPercent() float64 {
+	total := 0.0
+	for _, t := range r.Trades {
+		total += t.PnLPercent()
+	}
+	return total
+}
+
+// Run walks s through sc candle by candle after the warm-up, holding at
+// most one position. Entries fill at the close of the candle that signalled
+// them, sized against Balance. From the next candle on, a stop loss or take
+// profit the candle's range touched closes the position at that level, or
+// at the open when the candle gapped through it, the stop first when both
+// were touched. Otherwise the position is marked to the close
+// and OnTick and ShouldExit are called. A position still open at the end is
+// closed at the last close. A strategy whose stop sits above the entry is
+// taken to be short. Any error from the strategy fails the test.
+func Run(t testing.TB, s strategy.Strategy, sc Scenario) Result {
+	t.Helper()
+	ctx := context.Background()
+	r := Result{Scenario: sc}
+
+	var pos *trade.Position
+	var open Trade
+	closeAt := func(i int, price float64, reason string) {
+		t.Helper()
+		pos.UpdatePnL(price)
+		if err := s.OnPositionClose(ctx, pos, reason); err != nil {
+			t.Fatalf("%s: OnPositionClose at candle %d: %v", sc.Name, i, err)
+		}
+		open.Exit, open.ExitPrice, open.ExitReason = i, price, reason
+		r.Trades = append(r.Trades, open)
+		pos = nil
+	}
+
+	for i := Warmup; i < len(sc.Klines); i++ {
+		k := sc.Klines[i]
+		market := sc.Market(i)
+
+		if pos != nil {
+			if price, reason, hit := touched(pos, k); hit {
+				closeAt(i, price, reason)
+				continue
+			}
+			pos.UpdatePnL(k.Close)
+			if err := s.OnTick(ctx, pos, market); err != nil {
+				t.Fatalf("%s: OnTick at candle %d: %v", sc.Name, i, err)
+			}
+			exit, reason, err := s.ShouldExit(ctx, pos, market)
+			if err != nil {
+				t.Fatalf("%s: ShouldExit at candle %d: %v", sc.Name, i, err)
+			}
+			if exit {
+				closeAt(i, k.Close, reason)
+			}
+			continue
+		}
+
+		enter, reason, err := s.ShouldEnter(ctx, market)
+		if err != nil {
+			t.Fatalf("%s: ShouldEnter at candle %d: %v", sc.Name, i, err)
+		}
+		if !enter {
+			continue
+		}
+		open = enterAt(t, s, market, i, reason)
+		pos = &trade.Position{
+			Symbol:     sc.Symbol,
+			Side:       open.Side,
+			Quantity:   open.Quantity,
+			EntryPrice: open.EntryPrice,
+			StopLoss:   open.StopLoss,
+			TakeProfit: open.TakeProfit,
+			OpenedAt:   k.CloseTime,
+		}
+		pos.UpdatePnL(k.Close)
+		order := &trade.Order{
+			Symbol:       sc.Symbol,
+			Side:         open.Side,
+			Type:         trade.OrderTypeMarket,
+			Quantity:     open.Quantity,
+			StopLoss:     open.StopLoss,
+			TakeProfit:   open.TakeProfit,
+			Status:       trade.OrderStatusFilled,
+			FilledQty:    open.Quantity,
+			AvgFillPrice: open.EntryPrice,
+			CreatedAt:    k.CloseTime,
+		}
+		if err := s.OnOrderFill(ctx, order, pos); err != nil {
+			t.Fatalf("%s: OnOrderFill at candle %d: %v", sc.Name, i, err)
+		}
+	}
+
+	if pos != nil {
+		last := len(sc.Klines) - 1
+		closeAt(last, sc.Klines[last].Close, ExitEnd)
+	}
+	return r
+}
+
+func enterAt(t testing.TB, s strategy.Strategy, market trade.MarketData, i int, reason string) Trade {
+	t.Helper()
+	ctx := context.Background()
+	entry := market.CurrentPrice
+
+	size, err := s.CalculatePositionSize(ctx, market, Balance)
+	if err != nil {
+		t.Fatalf("%s: CalculatePositionSize at candle %d: %v", market.Symbol, i, err)
+	}
+	stop, err := s.CalculateStopLoss(ctx, entry, market)
+	if err != nil {
+		t.Fatalf("%s: CalculateStopLoss at candle %d: %v", market.Symbol, i, err)
+	}
+	target, err := s.CalculateTakeProfit(ctx, entry, market)
+	if err != nil {
+		t.Fatalf("%s: CalculateTakeProfit at candle %d: %v", market.Symbol, i, err)
+	}
+
+	side := trade.SideBuy
+	if stop > entry {
+		side = trade.SideSell
+	}
+	return Trade{
+		Side:       side,
+		Entry:      i,
+		EntryPrice: entry,
+		StopLoss:   stop,
+		TakeProfit: target,
+		Quantity:   size,
+		Reason:     reason,
+	}
+}
+
+// touched reports whether k reached the position's stop loss or take
+// profit, and the price it closed at.
+func touched(pos *trade.Position, k trade.Kline) (float64, string, bool) {
+	long := pos.Side != trade.SideSell
+	switch {
+	case pos.StopLoss > 0 && long && k.Low <= pos.StopLoss:
+		return math.Min(pos.StopLoss, k.Open), ExitStopLoss, true
+	case pos.StopLoss > 0 && !long && k.High >= pos.StopLoss:
+		return math.Max(pos.StopLoss, k.Open), ExitStopLoss, true
+	case pos.TakeProfit > 0 && long && k.High >= pos.TakeProfit:
+		return math.Max(pos.TakeProfit, k.Open), ExitTakeProfit, true
+	case pos.TakeProfit > 0 && !long && k.Low <= pos.TakeProfit:
+		return math.Min(pos.TakeProfit, k.Open), ExitTakeProfit, true
+	}
+	return 0, "", false
+}
+
+// AssertEntersDuring fails unless r has a trade entered in phase.
+func AssertEntersDuring(t testing.TB, r Result, phase string) {
+	t.Helper()
+	if len(r.EnteredDuring(phase)) == 0 {
+		t.Errorf("%s: no entry during %s; entries at %v", r.Scenario.Name, phase, entries(r))
+	}
+}
+
+// AssertNoEntryDuring fails if r has a trade entered in phase.
+func AssertNoEntryDuring(t testing.TB, r Result, phase string) {
+	t.Helper()
+	if trades := r.EnteredDuring(phase); len(trades) > 0 {
+		t.Errorf("%s: %d entries during %s, first at candle %d (%s)", r.Scenario.Name, len(trades), phase, trades[0].Entry, trades[0].Reason)
+	}
+}
+
+// AssertProtected fails unless every trade had a positive, finite size, a
+// stop loss on its losing side and any take profit on its winning side.
+func AssertProtected(t testing.TB, r Result) {
+	t.Helper()
+	for _, tr := range r.Trades {
+		long := tr.Side != trade.SideSell
+		switch {
+		case tr.Quantity <= 0 || math.IsInf(tr.Quantity, 0) || math.IsNaN(tr.Quantity):
+			t.Errorf("%s: trade at candle %d sized %v", r.Scenario.Name, tr.Entry, tr.Quantity)
+		case tr.StopLoss <= 0 || (long && tr.StopLoss >= tr.EntryPrice) || (!long && tr.StopLoss <= tr.EntryPrice):
+			t.Errorf("%s: %s at %v from candle %d has stop loss %v", r.Scenario.Name, tr.Side, tr.EntryPrice, tr.Entry, tr.StopLoss)
+		case tr.TakeProfit > 0 && ((long && tr.TakeProfit <= tr.EntryPrice) || (!long && tr.TakeProfit >= tr.EntryPrice)):
+			t.Errorf("%s: %s at %v from candle %d has take profit %v", r.Scenario.Name, tr.Side, tr.EntryPrice, tr.Entry, tr.TakeProfit)
+		}
+	}
+}
+
+// AssertOutWithin fails if a position open when phase starts, or entered
+// during it, is still open within candles of the phase's start.
+func AssertOutWithin(t testing.TB, r Result, phase string, candles int) {
+	t.Helper()
+	p := r.Scenario.Phase(phase)
+	deadline := p.From + candles
+	for _, tr := range r.Trades {
+		if tr.Entry < deadline && tr.Exit > deadline {
+			t.Errorf("%s: trade from candle %d held to candle %d, past %d candles into %s", r.Scenario.Name, tr.Entry, tr.Exit, candles, phase)
+		}
+	}
+}
+
+// AssertMaxLoss fails if any trade lost more than pct percent.
+func AssertMaxLoss(t testing.TB, r Result, pct float64) {
+	t.Helper()
+	for _, tr := range r.Trades {
+		if tr.PnLPercent() < -pct {
+			t.Errorf("%s: trade from candle %d lost %.2f%% (%s), more than %.2f%%", r.Scenario.Name, tr.Entry, -tr.PnLPercent(), tr.ExitReason, pct)
+		}
+	}
+}
+
+func entries(r Result) []int {
+	out := make([]int, len(r.Trades))
+	for i, t := range r.Trades {
+		out[i] = t.Entry
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package testkit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+)
+
+func change(sc Scenario, phase string) float64 {
+	p := sc.Phase(phase)
+	return (sc.Klines[p.To-1].Close/sc.Klines[p.From].Open - 1) * 100
+}
+
+func TestGolden_ScenariosKeepTheirShape(t *testing.T) {
+	if !reflect.DeepEqual(TrendDay("X"), TrendDay("X")) {
+		t.Fatal("TrendDay is not deterministic")
+	}
+
+	if c := change(TrendDay("X"), "trend"); c < 8 {
+		t.Errorf("trend day rose %.1f%%", c)
+	}
+	v := VReversal("X")
+	if c := change(v, "selloff"); c > -8 {
+		t.Errorf("V selloff moved %.1f%%", c)
+	}
+	if c := change(v, "recovery"); c < 8 {
+		t.Errorf("V recovery moved %.1f%%", c)
+	}
+	chop := Chop("X")
+	p := chop.Phase("chop")
+	for i := p.From; i < p.To; i++ {
+		if k := chop.Klines[i]; k.High > 104 || k.Low < 96 {
+			t.Fatalf("chop strayed to %.2f-%.2f at candle %d", k.Low, k.High, i)
+		}
+	}
+	if c := change(FlashCrash("X"), "crash"); c > -10 {
+		t.Errorf("flash crash moved %.1f%%", c)
+	}
+
+	for _, sc := range Golden() {
+		if m := sc.Market(Warmup); m.EMASlow == 0 || m.RSI == 0 || m.ATR == 0 {
+			t.Errorf("%s: indicators cold after the warm-up: %+v", sc.Name, m)
+		}
+	}
+}
+
+// bracket buys every candle it is flat with a 1% stop and a 2% target.
+type bracket struct {
+	closed []string
+}
+
+func (b *bracket) Type() strategy.StrategyType                    { return strategy.StrategyCustom }
+func (b *bracket) Name() string                                   { return "bracket" }
+func (b *bracket) Version() string                                { return "test" }
+func (b *bracket) Configure(config strategy.StrategyConfig) error { return nil }
+func (b *bracket) Validate() error                                { return nil }
+func (b *bracket) ShouldEnter(ctx context.Context, market trade.MarketData) (bool, string, error) {
+	return true, "always", nil
+}
+func (b *bracket) ShouldExit(ctx context.Context, position *trade.Position, market trade.MarketData) (bool, string, error) {
+	return false, "", nil
+}
+func (b *bracket) CalculatePositionSize(ctx context.Context, market trade.MarketData, balance float64) (float64, error) {
+	return balance * 0.01 / (market.CurrentPrice * 0.01), nil
+}
+func (b *bracket) CalculateStopLoss(ctx context.Context, entry float64, market trade.MarketData) (float64, error) {
+	return entry * 0.99, nil
+}
+func (b *bracket) CalculateTakeProfit(ctx context.Context, entry float64, market trade.MarketData) (float64, error) {
+	return entry * 1.02, nil
+}
+func (b *bracket) CalculateTrailingStop(ctx context.Context, position *trade.Position, market trade.MarketData) (float64, error) {
+	return 0, nil
+}
+func (b *bracket) OnTick(ctx context.Context, position *trade.Position, market trade.MarketData) error {
+	return nil
+}
+func (b *bracket) OnOrderFill(ctx context.Context, order *trade.Order, position *trade.Position) error {
+	return nil
+}
+func (b *bracket) OnPositionClose(ctx context.Context, position *trade.Position, reason string) error {
+	b.closed = append(b.closed, reason)
+	return nil
+}
+func (b *bracket) GetParameters() map[string]interface{} { return nil }
+
+func TestRun_FillsStopsAndTargetsOnTheCandleRange(t *testing.T) {
+	s := &bracket{}
+	r := Run(t, s, FlashCrash("CRASHUSDT"))
+
+	AssertProtected(t, r)
+	AssertOutWithin(t, r, "crash", 1)
+	if len(s.closed) != len(r.Trades) {
+		t.Errorf("%d trades, OnPositionClose called %d times", len(r.Trades), len(s.closed))
+	}
+	for i, tr := range r.Trades {
+		if tr.Exit <= tr.Entry && tr.ExitReason != ExitEnd {
+			t.Fatalf("trade %d closed on its entry candle", i)
+		}
+		switch tr.ExitReason {
+		case ExitStopLoss:
+			if k := r.Scenario.Klines[tr.Exit]; tr.ExitPrice != tr.StopLoss && tr.ExitPrice != k.Open {
+				t.Errorf("trade %d stopped at %v, stop %v open %v", i, tr.ExitPrice, tr.StopLoss, k.Open)
+			}
+		case ExitTakeProfit:
+			if tr.ExitPrice < tr.TakeProfit {
+				t.Errorf("trade %d took profit at %v under its target %v", i, tr.ExitPrice, tr.TakeProfit)
+			}
+		case ExitEnd:
+		default:
+			t.Errorf("trade %d exited for %q", i, tr.ExitReason)
+		}
+	}
+	if len(r.EnteredDuring("calm")) == 0 {
+		t.Error("no trade during the calm")
+	}
+}
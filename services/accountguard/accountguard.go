@@ -0,0 +1,279 @@
+// Package accountguard watches the account stream for changes the bot did
+// not cause: orders placed by hand or by another program, transfers in or
+// out, positions opened elsewhere. Trading alongside a human or a second
+// bot on the same account ends with both fighting over the same positions,
+// so the first such change pauses new entries until an operator resumes.
+package accountguard
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of foreign activity.
+const (
+	KindOrder    = "foreign_order"
+	KindTransfer = "transfer"
+	KindPosition = "foreign_position"
+)
+
+// transferReasons are the account update reasons that move funds rather
+// than trade them.
+var transferReasons = map[string]bool{
+	"DEPOSIT":            true,
+	"WITHDRAW":           true,
+	"ADMIN_DEPOSIT":      true,
+	"ADMIN_WITHDRAW":     true,
+	"MARGIN_TRANSFER":    true,
+	"ASSET_TRANSFER":     true,
+	"COIN_SWAP_DEPOSIT":  true,
+	"COIN_SWAP_WITHDRAW": true,
+}
+
+// OrderUpdate is an order event from the account stream.
+type OrderUpdate struct {
+	Symbol        string
+	OrderID       int64
+	ClientOrderID string
+	Side          string
+	Type          string
+	Status        string
+	Filled        float64
+	AvgPrice      float64
+	Time          time.Time
+}
+
+// AccountUpdate is a balance and position event from the account stream.
+// Balances holds the change per asset, Positions the new amount per symbol.
+type AccountUpdate struct {
+	Reason    string
+	Balances  map[string]float64
+	Positions map[string]float64
+	Time      time.Time
+}
+
+// Anomaly is one foreign change. Paused is set on the one that paused
+// entries.
+type Anomaly struct {
+	Kind   string
+	Symbol string
+	Detail string
+	At     time.Time
+	Paused bool
+}
+
+func (a Anomaly) String() string {
+	if a.Symbol == "" {
+		return a.Kind + ": " + a.Detail
+	}
+	return fmt.Sprintf("%s on %s: %s", a.Kind, a.Symbol, a.Detail)
+}
+
+// Config tells the bot's orders from the rest. Orders whose client order
+// ID starts with one of OwnPrefixes are the bot's, as are the exchange's
+// own liquidation and settlement orders. A position change on a symbol
+// within OwnWindow of one of those orders is put down to it.
+//
+// IgnoreTransfers is for accounts where the bot moves funds itself, such
+// as the earn sweep. PauseFor lifts the pause on its own after that long;
+// zero keeps it until Resume.
+type Config struct {
+	OwnPrefixes     []string
+	OwnWindow       time.Duration
+	IgnoreTransfers bool
+	PauseFor        time.Duration
+}
+
+type Stats struct {
+	Orders      int
+	Accounts    int
+	Anomalies   int
+	Paused      bool
+	PauseReason string
+	PausedAt    time.Time
+	Last        *Anomaly
+}
+
+type Guard struct {
+	cfg      Config
+	mu       sync.RWMutex
+	own      map[string]time.Time
+	foreign  map[int64]struct{}
+	reason   string
+	pausedAt time.Time
+	stats    Stats
+	handlers []func(Anomaly)
+	now      func() time.Time
+}
+
+func New(cfg Config) *Guard {
+	if len(cfg.OwnPrefixes) == 0 {
+		cfg.OwnPrefixes = []string{"gobot-"}
+	}
+	if cfg.OwnWindow <= 0 {
+		cfg.OwnWindow = time.Minute
+	}
+
+	return &Guard{
+		cfg:     cfg,
+		own:     make(map[string]time.Time),
+		foreign: make(map[int64]struct{}),
+		now:     time.Now,
+	}
+}
+
+// OnAnomaly registers a callback fired for every foreign change.
+func (g *Guard) OnAnomaly(fn func(Anomaly)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.handlers = append(g.handlers, fn)
+}
+
+// Order checks an order event. A foreign order is reported once, on the
+// first event seen for it.
+func (g *Guard) Order(u OrderUpdate) (Anomaly, bool) {
+	at := g.at(u.Time)
+
+	g.mu.Lock()
+	g.stats.Orders++
+	if g.ours(u.ClientOrderID) {
+		g.own[u.Symbol] = at
+		g.mu.Unlock()
+		return Anomaly{}, false
+	}
+	if _, seen := g.foreign[u.OrderID]; seen {
+		g.mu.Unlock()
+		return Anomaly{}, false
+	}
+	g.foreign[u.OrderID] = struct{}{}
+	g.mu.Unlock()
+
+	return g.report(Anomaly{
+		Kind:   KindOrder,
+		Symbol: u.Symbol,
+		Detail: fmt.Sprintf("%s %s order %d (%s) not placed by the bot", u.Side, u.Type, u.OrderID, u.ClientOrderID),
+		At:     at,
+	}), true
+}
+
+// Account checks a balance and position event. Transfers are foreign
+// unless ignored; positions changed by an order are foreign unless the bot
+// traded the symbol within OwnWindow.
+func (g *Guard) Account(u AccountUpdate) (Anomaly, bool) {
+	at := g.at(u.Time)
+
+	g.mu.Lock()
+	g.stats.Accounts++
+	var found *Anomaly
+	switch {
+	case transferReasons[u.Reason]:
+		if g.cfg.IgnoreTransfers {
+			break
+		}
+		parts := make([]string, 0, len(u.Balances))
+		for asset, change := range u.Balances {
+			parts = append(parts, fmt.Sprintf("%+g %s", change, asset))
+		}
+		found = &Anomaly{Kind: KindTransfer, Detail: fmt.Sprintf("%s %s", strings.ToLower(u.Reason), strings.Join(parts, ", ")), At: at}
+	case u.Reason == "ORDER":
+		for symbol, amount := range u.Positions {
+			if last, ok := g.own[symbol]; ok && at.Sub(last) <= g.cfg.OwnWindow {
+				continue
+			}
+			found = &Anomaly{Kind: KindPosition, Symbol: symbol, Detail: fmt.Sprintf("position moved to %g without a bot order", amount), At: at}
+			break
+		}
+	}
+	g.mu.Unlock()
+
+	if found == nil {
+		return Anomaly{}, false
+	}
+	return g.report(*found), true
+}
+
+// ours reports whether clientOrderID is the bot's or the exchange's.
+func (g *Guard) ours(clientOrderID string) bool {
+	for _, prefix := range g.cfg.OwnPrefixes {
+		if strings.HasPrefix(clientOrderID, prefix) {
+			return true
+		}
+	}
+	// autoclose-, adl_autoclose and settlement_autoclose- orders.
+	return strings.Contains(clientOrderID, "autoclose")
+}
+
+// report records a, pausing entries if they are not paused yet, and
+// notifies the handlers.
+func (g *Guard) report(a Anomaly) Anomaly {
+	g.mu.Lock()
+	g.stats.Anomalies++
+	if g.reason == "" || g.expired() {
+		g.reason = a.String()
+		g.pausedAt = a.At
+		a.Paused = true
+	}
+	g.stats.Last = &a
+	handlers := append([]func(Anomaly){}, g.handlers...)
+	g.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(a)
+	}
+	return a
+}
+
+// Paused returns why entries are paused, if they are.
+func (g *Guard) Paused() (string, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.reason == "" || g.expired() {
+		return "", false
+	}
+	return g.reason, true
+}
+
+// Resume lifts the pause. It reports whether entries were paused.
+func (g *Guard) Resume() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	paused := g.reason != "" && !g.expired()
+	g.reason = ""
+	g.pausedAt = time.Time{}
+	return paused
+}
+
+// Command handles the "/resume" chat command.
+func (g *Guard) Command(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || strings.ToLower(strings.SplitN(fields[0], "@", 2)[0]) != "/resume" {
+		return "", false
+	}
+	if !g.Resume() {
+		return "Entries were not paused", true
+	}
+	return "Entries resumed", true
+}
+
+func (g *Guard) Stats() Stats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	s := g.stats
+	if g.reason != "" && !g.expired() {
+		s.Paused, s.PauseReason, s.PausedAt = true, g.reason, g.pausedAt
+	}
+	return s
+}
+
+func (g *Guard) expired() bool {
+	return g.cfg.PauseFor > 0 && g.now().Sub(g.pausedAt) >= g.cfg.PauseFor
+}
+
+func (g *Guard) at(t time.Time) time.Time {
+	if t.IsZero() {
+		return g.now()
+	}
+	return t
+}
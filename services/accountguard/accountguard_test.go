@@ -0,0 +1,78 @@
+package accountguard
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuard_PausesOnForeignActivity(t *testing.T) {
+	g := New(Config{})
+	now := time.Now()
+
+	var alerts []Anomaly
+	g.OnAnomaly(func(a Anomaly) { alerts = append(alerts, a) })
+
+	if _, ok := g.Order(OrderUpdate{Symbol: "BTCUSDT", OrderID: 1, ClientOrderID: "gobot-3f9a", Time: now}); ok {
+		t.Fatal("the bot's own order was reported")
+	}
+	if _, ok := g.Account(AccountUpdate{Reason: "ORDER", Positions: map[string]float64{"BTCUSDT": 0.01}, Time: now.Add(time.Second)}); ok {
+		t.Fatal("a position the bot just traded was reported")
+	}
+	if _, ok := g.Order(OrderUpdate{Symbol: "ETHUSDT", OrderID: 2, ClientOrderID: "autoclose-1700000000", Time: now}); ok {
+		t.Fatal("an exchange liquidation order was reported")
+	}
+	if _, ok := g.Account(AccountUpdate{Reason: "FUNDING_FEE", Balances: map[string]float64{"USDT": -0.4}, Time: now}); ok {
+		t.Fatal("a funding fee was reported")
+	}
+	if _, paused := g.Paused(); paused {
+		t.Fatal("paused without foreign activity")
+	}
+
+	a, ok := g.Order(OrderUpdate{Symbol: "SOLUSDT", OrderID: 3, ClientOrderID: "web_Xk2pQ", Side: "BUY", Type: "LIMIT", Time: now})
+	if !ok || a.Kind != KindOrder || !a.Paused {
+		t.Fatalf("manual order = %+v, %v", a, ok)
+	}
+	if _, ok := g.Order(OrderUpdate{Symbol: "SOLUSDT", OrderID: 3, ClientOrderID: "web_Xk2pQ", Status: "FILLED", Time: now}); ok {
+		t.Fatal("the same foreign order was reported twice")
+	}
+	w, ok := g.Account(AccountUpdate{Reason: "WITHDRAW", Balances: map[string]float64{"USDT": -500}, Time: now})
+	if !ok || w.Kind != KindTransfer || w.Paused {
+		t.Fatalf("withdrawal = %+v, %v", w, ok)
+	}
+	if reason, paused := g.Paused(); !paused || !strings.Contains(reason, "SOLUSDT") {
+		t.Fatalf("Paused = %q, %v", reason, paused)
+	}
+	if len(alerts) != 2 || !alerts[0].Paused {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+
+	if reply, ok := g.Command("/resume@gobot_bot"); !ok || reply != "Entries resumed" {
+		t.Fatalf("reply = %q, %v", reply, ok)
+	}
+	if _, paused := g.Paused(); paused {
+		t.Fatal("still paused after /resume")
+	}
+	p, ok := g.Account(AccountUpdate{Reason: "ORDER", Positions: map[string]float64{"XRPUSDT": -300}, Time: now})
+	if !ok || p.Kind != KindPosition || !p.Paused {
+		t.Fatalf("foreign position = %+v, %v", p, ok)
+	}
+}
+
+func TestGuard_PauseExpiresAndTransfersCanBeIgnored(t *testing.T) {
+	g := New(Config{IgnoreTransfers: true, PauseFor: time.Hour})
+	now := time.Now()
+	g.now = func() time.Time { return now }
+
+	if _, ok := g.Account(AccountUpdate{Reason: "DEPOSIT", Balances: map[string]float64{"USDT": 100}}); ok {
+		t.Fatal("an ignored transfer was reported")
+	}
+	g.Order(OrderUpdate{Symbol: "BTCUSDT", OrderID: 7, ClientOrderID: "ios_a1"})
+	if _, paused := g.Paused(); !paused {
+		t.Fatal("a manual order should pause entries")
+	}
+	now = now.Add(time.Hour)
+	if _, paused := g.Paused(); paused {
+		t.Fatal("the pause outlived PauseFor")
+	}
+}
@@ -0,0 +1,171 @@
+package accountsetup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	MarginIsolated = "ISOLATED"
+	MarginCrossed  = "CROSSED"
+)
+
+type SymbolSettings struct {
+	Symbol     string
+	Leverage   int
+	MarginType string
+}
+
+type Exchange interface {
+	PositionMode(ctx context.Context) (dualSide bool, err error)
+	SetPositionMode(ctx context.Context, dualSide bool) error
+	MultiAssetsMode(ctx context.Context) (bool, error)
+	SetMultiAssetsMode(ctx context.Context, enabled bool) error
+	SymbolSettings(ctx context.Context) ([]SymbolSettings, error)
+	SetLeverage(ctx context.Context, symbol string, leverage int) error
+	SetMarginType(ctx context.Context, symbol, marginType string) error
+}
+
+type Config struct {
+	DualSide    bool
+	MultiAssets bool
+	MarginType  string
+	Leverage    int
+	Symbols     []string
+}
+
+type Mismatch struct {
+	Setting string
+	Symbol  string
+	Current string
+	Desired string
+	Fixed   bool
+	Err     error
+}
+
+func (m Mismatch) String() string {
+	target := "account"
+	if m.Symbol != "" {
+		target = m.Symbol
+	}
+	s := fmt.Sprintf("%s %s: %s -> %s", target, m.Setting, m.Current, m.Desired)
+	switch {
+	case m.Err != nil:
+		s += fmt.Sprintf(" (failed: %v)", m.Err)
+	case m.Fixed:
+		s += " (fixed)"
+	}
+	return s
+}
+
+type Fixer struct {
+	cfg Config
+	ex  Exchange
+}
+
+func New(cfg Config, ex Exchange) *Fixer {
+	cfg.MarginType = strings.ToUpper(cfg.MarginType)
+	if cfg.MarginType == "CROSS" {
+		cfg.MarginType = MarginCrossed
+	}
+	if cfg.MarginType == "" {
+		cfg.MarginType = MarginIsolated
+	}
+	return &Fixer{cfg: cfg, ex: ex}
+}
+
+// Check reports every setting that differs from the desired configuration
+// without changing anything.
+func (f *Fixer) Check(ctx context.Context) ([]Mismatch, error) {
+	return f.run(ctx, false)
+}
+
+// Fix applies the desired settings. Multi-assets mode goes first because
+// Binance rejects isolated margin while it is on; position mode changes
+// fail while positions or orders are open and are reported per mismatch.
+func (f *Fixer) Fix(ctx context.Context) ([]Mismatch, error) {
+	return f.run(ctx, true)
+}
+
+func (f *Fixer) run(ctx context.Context, apply bool) ([]Mismatch, error) {
+	var out []Mismatch
+
+	multi, err := f.ex.MultiAssetsMode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read multi-assets mode: %w", err)
+	}
+	if multi != f.cfg.MultiAssets {
+		m := Mismatch{Setting: "multi_assets_margin", Current: strconv.FormatBool(multi), Desired: strconv.FormatBool(f.cfg.MultiAssets)}
+		if apply {
+			m.Err = f.ex.SetMultiAssetsMode(ctx, f.cfg.MultiAssets)
+			m.Fixed = m.Err == nil
+		}
+		out = append(out, m)
+	}
+
+	dual, err := f.ex.PositionMode(ctx)
+	if err != nil {
+		return out, fmt.Errorf("failed to read position mode: %w", err)
+	}
+	if dual != f.cfg.DualSide {
+		m := Mismatch{Setting: "position_mode", Current: positionMode(dual), Desired: positionMode(f.cfg.DualSide)}
+		if apply {
+			m.Err = f.ex.SetPositionMode(ctx, f.cfg.DualSide)
+			m.Fixed = m.Err == nil
+		}
+		out = append(out, m)
+	}
+
+	if len(f.cfg.Symbols) == 0 {
+		return out, nil
+	}
+
+	settings, err := f.ex.SymbolSettings(ctx)
+	if err != nil {
+		return out, fmt.Errorf("failed to read symbol settings: %w", err)
+	}
+	bySymbol := make(map[string]SymbolSettings, len(settings))
+	for _, s := range settings {
+		if _, ok := bySymbol[s.Symbol]; !ok {
+			bySymbol[s.Symbol] = s
+		}
+	}
+
+	for _, symbol := range f.cfg.Symbols {
+		cur, ok := bySymbol[symbol]
+		if !ok {
+			out = append(out, Mismatch{Setting: "symbol", Symbol: symbol, Current: "missing", Desired: "tradable",
+				Err: fmt.Errorf("symbol not found in position risk")})
+			continue
+		}
+
+		if cur.MarginType != f.cfg.MarginType {
+			m := Mismatch{Setting: "margin_type", Symbol: symbol, Current: cur.MarginType, Desired: f.cfg.MarginType}
+			if apply {
+				m.Err = f.ex.SetMarginType(ctx, symbol, f.cfg.MarginType)
+				m.Fixed = m.Err == nil
+			}
+			out = append(out, m)
+		}
+
+		if f.cfg.Leverage > 0 && cur.Leverage != f.cfg.Leverage {
+			m := Mismatch{Setting: "leverage", Symbol: symbol, Current: strconv.Itoa(cur.Leverage), Desired: strconv.Itoa(f.cfg.Leverage)}
+			if apply {
+				m.Err = f.ex.SetLeverage(ctx, symbol, f.cfg.Leverage)
+				m.Fixed = m.Err == nil
+			}
+			out = append(out, m)
+		}
+	}
+
+	return out, nil
+}
+
+func positionMode(dualSide bool) string {
+	if dualSide {
+		return "hedge"
+	}
+	return "one_way"
+}
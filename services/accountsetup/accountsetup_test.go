@@ -0,0 +1,74 @@
+package accountsetup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockExchange struct {
+	dual, multi bool
+	settings    []SymbolSettings
+	dualErr     error
+	calls       []string
+}
+
+func (m *mockExchange) PositionMode(ctx context.Context) (bool, error) { return m.dual, nil }
+func (m *mockExchange) SetPositionMode(ctx context.Context, dual bool) error {
+	m.calls = append(m.calls, "position_mode")
+	return m.dualErr
+}
+func (m *mockExchange) MultiAssetsMode(ctx context.Context) (bool, error) { return m.multi, nil }
+func (m *mockExchange) SetMultiAssetsMode(ctx context.Context, enabled bool) error {
+	m.calls = append(m.calls, "multi_assets")
+	return nil
+}
+func (m *mockExchange) SymbolSettings(ctx context.Context) ([]SymbolSettings, error) {
+	return m.settings, nil
+}
+func (m *mockExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	m.calls = append(m.calls, "leverage:"+symbol)
+	return nil
+}
+func (m *mockExchange) SetMarginType(ctx context.Context, symbol, marginType string) error {
+	m.calls = append(m.calls, "margin:"+symbol)
+	return nil
+}
+
+func TestFixer_FixAppliesMismatchesInOrder(t *testing.T) {
+	ex := &mockExchange{
+		dual:    true,
+		multi:   true,
+		dualErr: errors.New("-4068 position side cannot be changed if there exists position"),
+		settings: []SymbolSettings{
+			{Symbol: "BTCUSDT", Leverage: 20, MarginType: MarginCrossed},
+			{Symbol: "ETHUSDT", Leverage: 5, MarginType: MarginIsolated},
+		},
+	}
+	f := New(Config{MarginType: "isolated", Leverage: 5, Symbols: []string{"BTCUSDT", "ETHUSDT"}}, ex)
+
+	checked, err := f.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checked) != 4 || len(ex.calls) != 0 {
+		t.Fatalf("check should report 4 mismatches without changes, got %v and calls %v", checked, ex.calls)
+	}
+
+	fixed, err := f.Fix(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"multi_assets", "position_mode", "margin:BTCUSDT", "leverage:BTCUSDT"}
+	if len(ex.calls) != len(want) {
+		t.Fatalf("expected calls %v, got %v", want, ex.calls)
+	}
+	for i := range want {
+		if ex.calls[i] != want[i] {
+			t.Fatalf("expected calls %v, got %v", want, ex.calls)
+		}
+	}
+	if fixed[1].Setting != "position_mode" || fixed[1].Fixed || fixed[1].Err == nil {
+		t.Errorf("expected position mode failure to be reported, got %+v", fixed[1])
+	}
+}
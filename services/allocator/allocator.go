@@ -0,0 +1,312 @@
+package allocator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Method string
+
+const (
+	MethodFixed            Method = "fixed"
+	MethodVolatilityParity Method = "volatility_parity"
+	MethodPerformance      Method = "performance"
+)
+
+var (
+	ErrUnknownStrategy = errors.New("strategy has no allocation")
+	ErrBudgetExceeded  = errors.New("strategy notional cap exceeded")
+)
+
+type Config struct {
+	Method            Method
+	Budget            float64
+	Weights           map[string]float64
+	MinWeight         float64
+	RebalanceInterval time.Duration
+	ReturnWindow      int
+}
+
+type Allocation struct {
+	Strategy string
+	Weight   float64
+	Cap      float64
+	Used     float64
+}
+
+type Allocator struct {
+	cfg          Config
+	mu           sync.RWMutex
+	running      bool
+	weights      map[string]float64
+	used         map[string]float64
+	returns      map[string][]float64
+	rebalancedAt time.Time
+	stopCh       chan struct{}
+}
+
+func New(cfg Config) *Allocator {
+	if cfg.Method == "" {
+		cfg.Method = MethodFixed
+	}
+	if cfg.MinWeight <= 0 {
+		cfg.MinWeight = 0.05
+	}
+	if cfg.RebalanceInterval <= 0 {
+		cfg.RebalanceInterval = time.Hour
+	}
+	if cfg.ReturnWindow <= 0 {
+		cfg.ReturnWindow = 50
+	}
+
+	a := &Allocator{
+		cfg:     cfg,
+		used:    make(map[string]float64),
+		returns: make(map[string][]float64),
+		stopCh:  make(chan struct{}),
+	}
+	a.weights = normalize(a.baseWeights(), 0)
+	return a
+}
+
+func (a *Allocator) Start(ctx context.Context) error {
+	a.mu.Lock()
+	if a.running {
+		a.mu.Unlock()
+		return nil
+	}
+	a.running = true
+	a.mu.Unlock()
+
+	a.Rebalance()
+	go a.run(ctx)
+	return nil
+}
+
+func (a *Allocator) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+	a.running = false
+	close(a.stopCh)
+	return nil
+}
+
+func (a *Allocator) run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.RebalanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.stopCh:
+			return
+		case <-ticker.C:
+			a.Rebalance()
+		}
+	}
+}
+
+// Rebalance recomputes strategy weights from the configured method. Open
+// notional is left in place; a strategy whose cap shrinks below its usage
+// simply cannot open more until positions are released.
+func (a *Allocator) Rebalance() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	base := a.baseWeights()
+	raw := make(map[string]float64, len(base))
+	for name, w := range base {
+		rets := a.returns[name]
+		switch a.cfg.Method {
+		case MethodVolatilityParity:
+			// Inverse volatility; strategies without enough history keep their base weight.
+			if vol := stddev(rets); len(rets) >= 2 && vol > 0 {
+				raw[name] = w / vol
+				continue
+			}
+			raw[name] = w / meanVolatility(a.returns)
+		case MethodPerformance:
+			// Scale by 1 + cumulative return, floored so a losing strategy keeps a
+			// minimum stake and can earn its way back.
+			raw[name] = w * math.Max(1+sum(rets)/100, 0.1)
+		default:
+			raw[name] = w
+		}
+	}
+
+	a.weights = normalize(raw, a.cfg.MinWeight)
+	a.rebalancedAt = time.Now()
+}
+
+func (a *Allocator) SetBudget(budget float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cfg.Budget = budget
+}
+
+// Reserve books notional against a strategy's cap and fails without
+// booking anything if the cap would be exceeded.
+func (a *Allocator) Reserve(strategy string, notional float64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	weight, ok := a.weights[strategy]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownStrategy, strategy)
+	}
+
+	limit := weight * a.cfg.Budget
+	if a.used[strategy]+notional > limit {
+		return fmt.Errorf("%w: %s needs %.2f, %.2f of %.2f in use", ErrBudgetExceeded, strategy, notional, a.used[strategy], limit)
+	}
+	a.used[strategy] += notional
+	return nil
+}
+
+func (a *Allocator) Release(strategy string, notional float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.used[strategy] -= notional
+	if a.used[strategy] < 0 {
+		a.used[strategy] = 0
+	}
+}
+
+// RecordReturn adds a closed trade's percentage return to the strategy's
+// rolling window used by the volatility parity and performance methods.
+func (a *Allocator) RecordReturn(strategy string, returnPct float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rets := append(a.returns[strategy], returnPct)
+	if len(rets) > a.cfg.ReturnWindow {
+		rets = rets[len(rets)-a.cfg.ReturnWindow:]
+	}
+	a.returns[strategy] = rets
+}
+
+func (a *Allocator) Cap(strategy string) float64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.weights[strategy] * a.cfg.Budget
+}
+
+func (a *Allocator) Allocations() []Allocation {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	out := make([]Allocation, 0, len(a.weights))
+	for name, w := range a.weights {
+		out = append(out, Allocation{
+			Strategy: name,
+			Weight:   w,
+			Cap:      w * a.cfg.Budget,
+			Used:     a.used[name],
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Strategy < out[j].Strategy })
+	return out
+}
+
+func (a *Allocator) RebalancedAt() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.rebalancedAt
+}
+
+func (a *Allocator) baseWeights() map[string]float64 {
+	base := make(map[string]float64, len(a.cfg.Weights))
+	for name, w := range a.cfg.Weights {
+		if w <= 0 {
+			w = 1
+		}
+		base[name] = w
+	}
+	return base
+}
+
+// normalize scales weights to sum to one, lifting any below floor to the
+// floor and taking the difference pro rata from the others.
+func normalize(raw map[string]float64, floor float64) map[string]float64 {
+	total := 0.0
+	for _, w := range raw {
+		total += w
+	}
+
+	out := make(map[string]float64, len(raw))
+	if total <= 0 {
+		return out
+	}
+	for name, w := range raw {
+		out[name] = w / total
+	}
+
+	if floor <= 0 || floor*float64(len(out)) >= 1 {
+		return out
+	}
+
+	lifted, rest := 0.0, 0.0
+	for _, w := range out {
+		if w < floor {
+			lifted += floor - w
+		} else {
+			rest += w
+		}
+	}
+	if lifted == 0 {
+		return out
+	}
+	for name, w := range out {
+		if w < floor {
+			out[name] = floor
+		} else {
+			out[name] = w - lifted*w/rest
+		}
+	}
+	return out
+}
+
+func sum(xs []float64) float64 {
+	total := 0.0
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}
+
+func stddev(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	mean := sum(xs) / float64(len(xs))
+	v := 0.0
+	for _, x := range xs {
+		v += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(v / float64(len(xs)-1))
+}
+
+func meanVolatility(returns map[string][]float64) float64 {
+	total, n := 0.0, 0
+	for _, rets := range returns {
+		if vol := stddev(rets); vol > 0 {
+			total += vol
+			n++
+		}
+	}
+	if n == 0 {
+		return 1
+	}
+	return total / float64(n)
+}
@@ -0,0 +1,41 @@
+package allocator
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAllocator_FixedWeightsEnforceCaps(t *testing.T) {
+	a := New(Config{Budget: 1000, Weights: map[string]float64{"scalper": 3, "momentum": 1}})
+
+	if c := a.Cap("scalper"); math.Abs(c-750) > 1e-9 {
+		t.Fatalf("expected scalper cap 750, got %f", c)
+	}
+	if err := a.Reserve("momentum", 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Reserve("momentum", 100); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	a.Release("momentum", 200)
+	if err := a.Reserve("momentum", 250); err != nil {
+		t.Errorf("expected released notional to be reusable, got %v", err)
+	}
+	if err := a.Reserve("grid", 1); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expected ErrUnknownStrategy, got %v", err)
+	}
+}
+
+func TestAllocator_VolatilityParityFavoursSteadyStrategy(t *testing.T) {
+	a := New(Config{Method: MethodVolatilityParity, Budget: 1000, Weights: map[string]float64{"steady": 1, "wild": 1}})
+	for _, r := range []float64{1, -1, 1, -1} {
+		a.RecordReturn("steady", r)
+		a.RecordReturn("wild", r*4)
+	}
+	a.Rebalance()
+
+	if steady, wild := a.Cap("steady"), a.Cap("wild"); steady <= wild*3 {
+		t.Errorf("expected steady cap to be ~4x wild, got %f vs %f", steady, wild)
+	}
+}
@@ -0,0 +1,298 @@
+// Package bookarchive records L2 order book snapshots around entries, so
+// slippage and fills can be checked afterwards against the liquidity that
+// was actually on the book.
+package bookarchive
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/pkg/callpolicy"
+)
+
+type Level struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"qty"`
+}
+
+// Book is the top of an order book, best levels first.
+type Book struct {
+	Bids []Level `json:"bids"`
+	Asks []Level `json:"asks"`
+}
+
+// Source reads the book of a symbol to the given depth.
+type Source interface {
+	Depth(ctx context.Context, symbol string, limit int) (Book, error)
+}
+
+const (
+	PhaseBefore = "before"
+	PhaseAfter  = "after"
+)
+
+// Snapshot is one archived book. OffsetMS is its time relative to the
+// entry fill, negative before it. Mid, SpreadBps and the notionals
+// summarize the captured levels.
+type Snapshot struct {
+	Time        time.Time `json:"ts"`
+	Symbol      string    `json:"symbol"`
+	Intent      string    `json:"intent,omitempty"`
+	Phase       string    `json:"phase"`
+	OffsetMS    int64     `json:"offset_ms"`
+	Side        string    `json:"side"`
+	FillPrice   float64   `json:"fill_price,omitempty"`
+	Quantity    float64   `json:"quantity"`
+	Mid         float64   `json:"mid"`
+	SpreadBps   float64   `json:"spread_bps"`
+	BidNotional float64   `json:"bid_notional"`
+	AskNotional float64   `json:"ask_notional"`
+	Book
+}
+
+// Entry identifies the fill snapshots are archived around.
+type Entry struct {
+	Symbol    string
+	Intent    string
+	Side      string
+	FillPrice float64
+	Quantity  float64
+	At        time.Time
+}
+
+type Config struct {
+	Path  string
+	Depth int
+	// Lead is how old a pre-entry capture may be and still be archived
+	// with the entry.
+	Lead  time.Duration
+	After []time.Duration
+	Calls *callpolicy.Policy
+}
+
+type Stats struct {
+	Archived  int
+	Failures  int
+	LastError string
+}
+
+type capture struct {
+	at    time.Time
+	book  Book
+	ready bool
+}
+
+// Archiver keeps the latest pre-entry capture per symbol in memory and,
+// once an entry fills, appends it and the post-entry captures to a
+// JSON-lines file. Captures for candidates that never fill are discarded.
+type Archiver struct {
+	cfg    Config
+	source Source
+	wg     sync.WaitGroup
+	stopCh chan struct{}
+
+	mu      sync.Mutex
+	file    *os.File
+	pending map[string]capture
+	stats   Stats
+	stopped bool
+}
+
+func New(cfg Config, source Source) (*Archiver, error) {
+	if cfg.Depth <= 0 {
+		cfg.Depth = 20
+	}
+	if cfg.Lead <= 0 {
+		cfg.Lead = 30 * time.Second
+	}
+	if len(cfg.After) == 0 {
+		cfg.After = []time.Duration{5 * time.Second}
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Archiver{
+		cfg:     cfg,
+		source:  source,
+		file:    f,
+		pending: make(map[string]capture),
+		stopCh:  make(chan struct{}),
+	}, nil
+}
+
+func (a *Archiver) Path() string {
+	return a.cfg.Path
+}
+
+// Capture snapshots symbol's book in the background as the pre-entry view.
+// A capture younger than Lead is kept rather than replaced, so the
+// archived view stays the one from when the candidate first appeared.
+// A nil Archiver captures nothing.
+func (a *Archiver) Capture(ctx context.Context, symbol string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	if a.stopped {
+		a.mu.Unlock()
+		return
+	}
+	if c, ok := a.pending[symbol]; ok && time.Since(c.at) <= a.cfg.Lead {
+		a.mu.Unlock()
+		return
+	}
+	// Reserve the slot so a second Capture before this one lands is a no-op.
+	a.pending[symbol] = capture{at: time.Now()}
+	a.wg.Add(1)
+	a.mu.Unlock()
+
+	go func() {
+		defer a.wg.Done()
+		at := time.Now()
+		book, err := a.depth(ctx, symbol)
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if err != nil {
+			delete(a.pending, symbol)
+			return
+		}
+		a.pending[symbol] = capture{at: at, book: book, ready: true}
+	}()
+}
+
+// Record archives the pending pre-entry capture of e.Symbol, if recent
+// enough, and schedules the post-entry captures.
+func (a *Archiver) Record(e Entry) {
+	if a == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+
+	a.mu.Lock()
+	if a.stopped {
+		a.mu.Unlock()
+		return
+	}
+	c, ok := a.pending[e.Symbol]
+	delete(a.pending, e.Symbol)
+	if ok && c.ready && e.At.Sub(c.at) <= a.cfg.Lead {
+		a.appendLocked(snapshot(e, PhaseBefore, c.at, c.book))
+	}
+	a.wg.Add(1)
+	a.mu.Unlock()
+
+	go func() {
+		defer a.wg.Done()
+		for _, delay := range a.cfg.After {
+			select {
+			case <-a.stopCh:
+				return
+			case <-time.After(time.Until(e.At.Add(delay))):
+			}
+
+			at := time.Now()
+			book, err := a.depth(context.Background(), e.Symbol)
+			a.mu.Lock()
+			if err == nil {
+				a.appendLocked(snapshot(e, PhaseAfter, at, book))
+			}
+			a.mu.Unlock()
+		}
+	}()
+}
+
+func (a *Archiver) Stats() Stats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stats
+}
+
+// Stop abandons scheduled captures, waits for those in flight and closes
+// the archive.
+func (a *Archiver) Stop() error {
+	a.mu.Lock()
+	if a.stopped {
+		a.mu.Unlock()
+		return nil
+	}
+	a.stopped = true
+	close(a.stopCh)
+	a.mu.Unlock()
+
+	a.wg.Wait()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+func (a *Archiver) depth(ctx context.Context, symbol string) (Book, error) {
+	callCtx, cancel := a.cfg.Calls.Context(ctx, callpolicy.Exchange)
+	defer cancel()
+
+	book, err := a.source.Depth(callCtx, symbol, a.cfg.Depth)
+	if err != nil {
+		a.mu.Lock()
+		a.stats.Failures++
+		a.stats.LastError = err.Error()
+		a.mu.Unlock()
+	}
+	return book, err
+}
+
+func (a *Archiver) appendLocked(s Snapshot) {
+	if a.file == nil {
+		return
+	}
+	line, err := json.Marshal(s)
+	if err == nil {
+		_, err = a.file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		a.stats.Failures++
+		a.stats.LastError = err.Error()
+		return
+	}
+	a.stats.Archived++
+}
+
+func snapshot(e Entry, phase string, at time.Time, book Book) Snapshot {
+	s := Snapshot{
+		Time:      at,
+		Symbol:    e.Symbol,
+		Intent:    e.Intent,
+		Phase:     phase,
+		OffsetMS:  at.Sub(e.At).Milliseconds(),
+		Side:      e.Side,
+		FillPrice: e.FillPrice,
+		Quantity:  e.Quantity,
+		Book:      book,
+	}
+	for _, l := range book.Bids {
+		s.BidNotional += l.Price * l.Quantity
+	}
+	for _, l := range book.Asks {
+		s.AskNotional += l.Price * l.Quantity
+	}
+	if len(book.Bids) > 0 && len(book.Asks) > 0 {
+		bid, ask := book.Bids[0].Price, book.Asks[0].Price
+		s.Mid = (bid + ask) / 2
+		if s.Mid > 0 {
+			s.SpreadBps = (ask - bid) / s.Mid * 10000
+		}
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package bookarchive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	calls int32
+}
+
+func (f *fakeSource) Depth(ctx context.Context, symbol string, limit int) (Book, error) {
+	n := float64(atomic.AddInt32(&f.calls, 1))
+	return Book{
+		Bids: []Level{{Price: 99 + n, Quantity: 2}},
+		Asks: []Level{{Price: 101 + n, Quantity: 3}},
+	}, nil
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestArchivesCapturesAroundEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "books.jsonl")
+	src := &fakeSource{}
+	a, err := New(Config{Path: path, After: []time.Duration{10 * time.Millisecond}}, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.Capture(context.Background(), "BTCUSDT")
+	a.Capture(context.Background(), "BTCUSDT")
+	a.Capture(context.Background(), "ETHUSDT")
+	waitFor(t, func() bool { return atomic.LoadInt32(&src.calls) == 2 })
+
+	a.Record(Entry{Symbol: "BTCUSDT", Intent: "int-1", Side: "BUY", FillPrice: 101.5, Quantity: 0.1})
+	waitFor(t, func() bool { return a.Stats().Archived == 2 })
+	if err := a.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var snaps []Snapshot
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatal(err)
+		}
+		snaps = append(snaps, s)
+	}
+
+	if len(snaps) != 2 {
+		t.Fatalf("archived %d snapshots, want before and after", len(snaps))
+	}
+	before, after := snaps[0], snaps[1]
+	if before.Phase != PhaseBefore || before.OffsetMS > 0 || before.Intent != "int-1" {
+		t.Errorf("unexpected before snapshot %+v", before)
+	}
+	if after.Phase != PhaseAfter || after.OffsetMS < 10 || after.FillPrice != 101.5 {
+		t.Errorf("unexpected after snapshot %+v", after)
+	}
+	if before.SpreadBps <= 0 || before.BidNotional <= 0 || len(before.Asks) != 1 {
+		t.Errorf("book summary missing %+v", before)
+	}
+}
@@ -0,0 +1,262 @@
+// Package calendar knows when the exchange is not open for entries:
+// scheduled maintenance windows from the config, maintenance the exchange
+// reports through its system status, and symbols whose contract is not in
+// TRADING status (halted, settling, pending listing or delivery). Checking it
+// before an entry turns a maintenance window into a pause instead of a burst
+// of rejected orders.
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Window is a scheduled maintenance period. Without Symbols it closes the
+// whole exchange.
+type Window struct {
+	Start   time.Time
+	End     time.Time
+	Symbols []string
+	Reason  string
+}
+
+func (w Window) covers(symbol string) bool {
+	if len(w.Symbols) == 0 {
+		return true
+	}
+	for _, s := range w.Symbols {
+		if strings.EqualFold(s, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// SystemStatus is the exchange-wide status.
+type SystemStatus struct {
+	Maintenance bool
+	Message     string
+}
+
+// Source reads the exchange's system status and the status of every
+// contract, keyed by symbol.
+type Source interface {
+	SystemStatus(ctx context.Context) (SystemStatus, error)
+	SymbolStatuses(ctx context.Context) (map[string]string, error)
+}
+
+// Config holds the scheduled Windows. Entries pause Lead before a window
+// starts, so no position is opened just to be stuck through it, and stay
+// paused Grace after it ends while the exchange settles.
+type Config struct {
+	Windows      []Window
+	PollInterval time.Duration
+	Lead         time.Duration
+	Grace        time.Duration
+}
+
+// Closure explains why entries are closed.
+type Closure struct {
+	Reason string
+	Until  time.Time
+	// Halted is set when the symbol itself is not trading, rather than the
+	// exchange being in maintenance.
+	Halted bool
+}
+
+type Stats struct {
+	Polls        int
+	Failures     int
+	LastPoll     time.Time
+	Maintenance  bool
+	Halted       int
+	NextWindow   *Window
+	ActiveWindow *Window
+}
+
+type Calendar struct {
+	cfg     Config
+	source  Source
+	mu      sync.RWMutex
+	running bool
+	system  SystemStatus
+	symbols map[string]string
+	stats   Stats
+	stopCh  chan struct{}
+	now     func() time.Time
+}
+
+func New(cfg Config, source Source) *Calendar {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Minute
+	}
+	if cfg.Lead < 0 {
+		cfg.Lead = 0
+	}
+	if cfg.Grace < 0 {
+		cfg.Grace = 0
+	}
+	sort.Slice(cfg.Windows, func(i, j int) bool { return cfg.Windows[i].Start.Before(cfg.Windows[j].Start) })
+
+	return &Calendar{
+		cfg:     cfg,
+		source:  source,
+		symbols: make(map[string]string),
+		stopCh:  make(chan struct{}),
+		now:     time.Now,
+	}
+}
+
+func (c *Calendar) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	c.Refresh(ctx)
+	go c.run(ctx)
+	return nil
+}
+
+func (c *Calendar) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return nil
+	}
+	c.running = false
+	close(c.stopCh)
+	return nil
+}
+
+func (c *Calendar) run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh polls the source. A failed poll keeps the last known statuses.
+func (c *Calendar) Refresh(ctx context.Context) error {
+	if c.source == nil {
+		return nil
+	}
+	system, sysErr := c.source.SystemStatus(ctx)
+	symbols, symErr := c.source.SymbolStatuses(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Polls++
+	c.stats.LastPoll = c.now()
+	if sysErr == nil {
+		c.system = system
+	}
+	if symErr == nil {
+		c.symbols = symbols
+	}
+	if sysErr != nil {
+		c.stats.Failures++
+		return fmt.Errorf("system status: %w", sysErr)
+	}
+	if symErr != nil {
+		c.stats.Failures++
+		return fmt.Errorf("symbol status: %w", symErr)
+	}
+	return nil
+}
+
+// Closed reports whether entries on symbol are closed, and why. An empty
+// symbol asks about the exchange as a whole. Symbols the exchange did not
+// list are left open; the symbol rules catch those.
+func (c *Calendar) Closed(symbol string) (Closure, bool) {
+	now := c.now()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.system.Maintenance {
+		reason := "exchange maintenance"
+		if c.system.Message != "" {
+			reason += ": " + c.system.Message
+		}
+		return Closure{Reason: reason}, true
+	}
+	if w, ok := c.window(symbol, now); ok {
+		reason := "scheduled maintenance"
+		if w.Reason != "" {
+			reason += ": " + w.Reason
+		}
+		return Closure{Reason: reason, Until: w.End.Add(c.cfg.Grace)}, true
+	}
+	if symbol != "" {
+		if status, ok := c.symbols[strings.ToUpper(symbol)]; ok && status != "TRADING" {
+			return Closure{Reason: fmt.Sprintf("%s is %s", symbol, strings.ToLower(status)), Halted: true}, true
+		}
+	}
+	return Closure{}, false
+}
+
+// window returns the window, widened by Lead and Grace, that covers symbol
+// at now. An empty symbol only matches exchange-wide windows.
+func (c *Calendar) window(symbol string, now time.Time) (Window, bool) {
+	for _, w := range c.cfg.Windows {
+		if now.Before(w.Start.Add(-c.cfg.Lead)) || !now.Before(w.End.Add(c.cfg.Grace)) {
+			continue
+		}
+		if symbol == "" && len(w.Symbols) > 0 {
+			continue
+		}
+		if w.covers(symbol) {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+func (c *Calendar) Stats() Stats {
+	now := c.now()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s := c.stats
+	s.Maintenance = c.system.Maintenance
+	for _, status := range c.symbols {
+		if status != "TRADING" {
+			s.Halted++
+		}
+	}
+	for i := range c.cfg.Windows {
+		w := c.cfg.Windows[i]
+		if !now.Before(w.End.Add(c.cfg.Grace)) {
+			continue
+		}
+		if now.Before(w.Start.Add(-c.cfg.Lead)) {
+			if s.NextWindow == nil {
+				s.NextWindow = &w
+			}
+			continue
+		}
+		if s.ActiveWindow == nil {
+			s.ActiveWindow = &w
+		}
+	}
+	return s
+}
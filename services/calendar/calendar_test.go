@@ -0,0 +1,76 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	system  SystemStatus
+	symbols map[string]string
+	err     error
+}
+
+func (f *fakeSource) SystemStatus(context.Context) (SystemStatus, error) {
+	return f.system, f.err
+}
+
+func (f *fakeSource) SymbolStatuses(context.Context) (map[string]string, error) {
+	return f.symbols, f.err
+}
+
+func TestCalendar_ClosesForWindowsMaintenanceAndHalts(t *testing.T) {
+	start := time.Date(2026, 11, 3, 2, 0, 0, 0, time.UTC)
+	source := &fakeSource{symbols: map[string]string{"BTCUSDT": "TRADING", "LUNAUSDT": "SETTLING"}}
+	c := New(Config{
+		Windows: []Window{
+			{Start: start, End: start.Add(2 * time.Hour), Reason: "futures upgrade"},
+			{Start: start.Add(24 * time.Hour), End: start.Add(25 * time.Hour), Symbols: []string{"ETHUSDT"}},
+		},
+		Lead:  10 * time.Minute,
+		Grace: 5 * time.Minute,
+	}, source)
+	now := start.Add(-time.Hour)
+	c.now = func() time.Time { return now }
+	c.Refresh(context.Background())
+
+	if _, closed := c.Closed("BTCUSDT"); closed {
+		t.Fatal("closed an hour before the window")
+	}
+	if cl, closed := c.Closed("LUNAUSDT"); !closed || !cl.Halted {
+		t.Fatalf("settling symbol = %+v, %v", cl, closed)
+	}
+
+	now = start.Add(-5 * time.Minute)
+	if cl, closed := c.Closed(""); !closed || !cl.Until.Equal(start.Add(2*time.Hour+5*time.Minute)) {
+		t.Fatalf("inside the lead = %+v, %v", cl, closed)
+	}
+	now = start.Add(2*time.Hour + 5*time.Minute)
+	if _, closed := c.Closed("BTCUSDT"); closed {
+		t.Fatal("still closed after the grace period")
+	}
+
+	now = start.Add(24*time.Hour + time.Minute)
+	if _, closed := c.Closed("ETHUSDT"); !closed {
+		t.Fatal("a symbol window did not close its symbol")
+	}
+	if _, closed := c.Closed(""); closed {
+		t.Fatal("a symbol window closed the whole exchange")
+	}
+
+	source.system = SystemStatus{Maintenance: true, Message: "system maintenance"}
+	c.Refresh(context.Background())
+	if _, closed := c.Closed("BTCUSDT"); !closed {
+		t.Fatal("reported maintenance did not close entries")
+	}
+
+	source.err = errors.New("timeout")
+	if err := c.Refresh(context.Background()); err == nil {
+		t.Fatal("a failed poll was not reported")
+	}
+	if _, closed := c.Closed(""); !closed {
+		t.Fatal("a failed poll dropped the last known status")
+	}
+}
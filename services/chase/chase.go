@@ -0,0 +1,447 @@
+package chase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/callpolicy"
+)
+
+var ErrNoBook = errors.New("no top of book to peg to")
+
+// ErrUnresolved is returned when a resting order could be neither
+// cancelled nor read back. It may still be on the book or have filled
+// beyond what the result reports, so the chase stops rather than re-peg.
+var ErrUnresolved = errors.New("chased order state unknown")
+
+// What a chase does with the unfilled remainder of a partial fill once its
+// re-pegs or time run out.
+const (
+	RemainderCancel = "cancel"
+	RemainderMarket = "market"
+	RemainderChase  = "chase"
+)
+
+// Venue is the exchange surface a chase needs. CancelOrder returns the final
+// state of the order, so fills that raced the cancel are still counted.
+type Venue interface {
+	BookTop(ctx context.Context, symbol string) (bid, ask float64, err error)
+	CreateOrder(ctx context.Context, order *trade.Order) (*trade.Order, error)
+	GetOrder(ctx context.Context, orderID, symbol string) (*trade.Order, error)
+	CancelOrder(ctx context.Context, symbol, orderID string) (*trade.Order, error)
+}
+
+// Config controls a chase. The entry rests at the near touch (best bid for a
+// buy, best ask for a sell) and is re-pegged whenever the touch moves away,
+// at most MaxRepegs times and for at most MaxWait in total. Whatever has not
+// filled by then is abandoned. RoundQuantity, if set, rounds the remaining
+// quantity after partial fills to the symbol's step size.
+//
+// Remainder decides what happens when the chase runs out with part of the
+// entry filled: RemainderCancel (default) keeps the partial position,
+// RemainderMarket takes the rest at market unless the far touch has moved
+// more than RemainderMaxSlippageBps against the arrival price, and
+// RemainderChase re-pegs the rest up to RemainderRepegs more times within
+// another MaxWait.
+type Config struct {
+	MaxRepegs     int
+	MaxWait       time.Duration
+	PollInterval  time.Duration
+	RoundQuantity func(symbol string, qty float64) float64
+	Calls         *callpolicy.Policy
+
+	Remainder               string
+	RemainderMaxSlippageBps float64
+	RemainderRepegs         int
+}
+
+// Fill is an order event from the account stream. Status is the exchange's
+// order status and Filled the cumulative quantity.
+type Fill struct {
+	OrderID  string
+	Status   string
+	Filled   float64
+	AvgPrice float64
+}
+
+// Result is the outcome of one chase. ArrivalPrice is the far touch when the
+// chase began, i.e. what a market entry would have paid; ImprovementBps is
+// how much better the average fill was, in basis points of ArrivalPrice.
+// Remainder is what was done with the rest of a partial fill, and
+// RemainderFilled how much of Filled that added.
+type Result struct {
+	Filled          float64
+	AvgPrice        float64
+	ArrivalPrice    float64
+	ImprovementBps  float64
+	Repegs          int
+	Abandoned       bool
+	Remainder       string
+	RemainderFilled float64
+	Duration        time.Duration
+}
+
+type Stats struct {
+	Chases           int
+	Filled           int
+	Partial          int
+	Abandoned        int
+	Repegs           int
+	MarketRemainders int
+	ChasedRemainders int
+	ImprovementUSD   float64
+	AvgImprovement   float64 // basis points, over chases with fills
+}
+
+type Chaser struct {
+	cfg   Config
+	venue Venue
+	mu    sync.RWMutex
+	stats Stats
+	bps   float64
+	// fills holds the latest stream event of each resting order
+	fills map[string]Fill
+}
+
+func New(cfg Config, venue Venue) *Chaser {
+	if cfg.MaxRepegs <= 0 {
+		cfg.MaxRepegs = 3
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = 15 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 500 * time.Millisecond
+	}
+	if cfg.Remainder == "" {
+		cfg.Remainder = RemainderCancel
+	}
+	if cfg.RemainderRepegs <= 0 {
+		cfg.RemainderRepegs = cfg.MaxRepegs
+	}
+	return &Chaser{cfg: cfg, venue: venue, fills: make(map[string]Fill)}
+}
+
+// Update feeds an account stream event. Events for orders the chaser is not
+// resting are ignored.
+func (c *Chaser) Update(f Fill) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.fills[f.OrderID]; ok && f.Filled >= last.Filled {
+		c.fills[f.OrderID] = f
+	}
+}
+
+func (c *Chaser) streamed(orderID string) Fill {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fills[orderID]
+}
+
+func (c *Chaser) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stats
+}
+
+// Execute works order's quantity as a chased limit entry and returns what
+// filled. An abandoned chase with no fill returns a zero Filled and no error.
+// With ErrUnresolved the result holds only what is known to have filled.
+// Only the entry is chased; order's stop loss and take profit are left to the
+// caller.
+func (c *Chaser) Execute(ctx context.Context, order *trade.Order) (Result, error) {
+	start := time.Now()
+	deadline := start.Add(c.cfg.MaxWait)
+
+	bid, ask, err := c.bookTop(ctx, order.Symbol)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{ArrivalPrice: far(order.Side, bid, ask)}
+	var notional float64
+	remaining := order.Quantity
+	maxRepegs := c.cfg.MaxRepegs
+	extended := false
+	var unresolved error
+
+	for {
+		price := near(order.Side, bid, ask)
+		resting, err := c.place(ctx, order, remaining, price)
+		if err != nil {
+			if res.Filled > 0 {
+				break
+			}
+			return res, err
+		}
+
+		filled, avg, moved, err := c.work(ctx, order, resting, deadline)
+		res.Filled += filled
+		notional += filled * avg
+		if err != nil {
+			unresolved = err
+			break
+		}
+		remaining = c.round(order.Symbol, order.Quantity-res.Filled)
+
+		if remaining <= 0 {
+			break
+		}
+		if !moved || res.Repegs >= maxRepegs || time.Now().After(deadline) || ctx.Err() != nil {
+			if res.Filled > 0 && !extended && c.cfg.Remainder == RemainderChase && ctx.Err() == nil {
+				extended = true
+				res.Remainder = RemainderChase
+				maxRepegs = res.Repegs + c.cfg.RemainderRepegs
+				deadline = time.Now().Add(c.cfg.MaxWait)
+				if !moved {
+					// Resting longer at the same price is the re-peg.
+					maxRepegs++
+				}
+			} else {
+				res.Abandoned = true
+				break
+			}
+		}
+
+		if bid, ask, err = c.bookTop(ctx, order.Symbol); err != nil {
+			res.Abandoned = true
+			break
+		}
+		res.Repegs++
+	}
+
+	if res.Abandoned && res.Filled > 0 && remaining > 0 {
+		switch c.cfg.Remainder {
+		case RemainderMarket:
+			res.Remainder = RemainderMarket
+			if filled, avg := c.takeRemainder(ctx, order, remaining, res.ArrivalPrice); filled > 0 {
+				res.Filled += filled
+				res.RemainderFilled = filled
+				notional += filled * avg
+				res.Abandoned = c.round(order.Symbol, order.Quantity-res.Filled) > 0
+			}
+		case RemainderCancel:
+			res.Remainder = RemainderCancel
+		}
+	}
+	if res.Filled > 0 {
+		res.AvgPrice = notional / res.Filled
+		res.ImprovementBps = improvement(order.Side, res.ArrivalPrice, res.AvgPrice)
+	}
+	res.Duration = time.Since(start)
+	c.record(res)
+	return res, unresolved
+}
+
+// takeRemainder sends qty at market unless the far touch has moved more
+// than RemainderMaxSlippageBps against arrival. It returns what filled.
+func (c *Chaser) takeRemainder(ctx context.Context, order *trade.Order, qty, arrival float64) (float64, float64) {
+	if ctx.Err() != nil {
+		return 0, 0
+	}
+	bid, ask, err := c.bookTop(ctx, order.Symbol)
+	if err != nil {
+		return 0, 0
+	}
+	price := far(order.Side, bid, ask)
+	if limit := c.cfg.RemainderMaxSlippageBps; limit > 0 && -improvement(order.Side, arrival, price) > limit {
+		return 0, 0
+	}
+
+	ctx, cancel := c.cfg.Calls.Context(ctx, callpolicy.Order)
+	defer cancel()
+	placed, err := c.venue.CreateOrder(ctx, &trade.Order{
+		Symbol:   order.Symbol,
+		Side:     order.Side,
+		Type:     trade.OrderTypeMarket,
+		Quantity: qty,
+	})
+	if err != nil {
+		return 0, 0
+	}
+	filled := placed.FilledQty
+	if filled <= 0 && placed.Status == trade.OrderStatusFilled {
+		filled = qty
+	}
+	return filled, fillPrice(placed, price)
+}
+
+// work waits on a resting order until it fills, the touch moves away from
+// it, or the deadline passes. Anything still open is cancelled. It returns
+// the quantity filled, its average price and whether the touch moved.
+// Account stream events settle the order as soon as they arrive, and cover
+// fills the REST calls fail to report. An order that can be neither
+// cancelled nor read back, and that the stream has not reported done,
+// fails with ErrUnresolved.
+func (c *Chaser) work(ctx context.Context, order, resting *trade.Order, deadline time.Time) (float64, float64, bool, error) {
+	ticker := time.NewTicker(c.cfg.PollInterval)
+	defer ticker.Stop()
+
+	c.mu.Lock()
+	c.fills[resting.ID] = Fill{OrderID: resting.ID}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.fills, resting.ID)
+		c.mu.Unlock()
+	}()
+
+	moved := false
+	for !moved && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		if f := c.streamed(resting.ID); f.Status == string(trade.OrderStatusFilled) {
+			return f.Filled, fillPrice(&trade.Order{AvgFillPrice: f.AvgPrice}, resting.Price), false, nil
+		}
+		if o, err := c.getOrder(ctx, resting); err == nil && o.Status == trade.OrderStatusFilled {
+			return o.FilledQty, fillPrice(o, resting.Price), false, nil
+		}
+		if bid, ask, err := c.bookTop(ctx, order.Symbol); err == nil {
+			moved = near(order.Side, bid, ask) != resting.Price
+		}
+	}
+
+	// The loop context may already be cancelled; the cancel must still go out.
+	cancelCtx, cancel := c.cfg.Calls.Context(context.Background(), callpolicy.Order)
+	defer cancel()
+	final, cancelErr := c.venue.CancelOrder(cancelCtx, order.Symbol, resting.ID)
+	if cancelErr != nil {
+		// Most likely it filled before the cancel landed; ask for the final state.
+		var err error
+		if final, err = c.venue.GetOrder(cancelCtx, resting.ID, order.Symbol); err != nil {
+			f := c.streamed(resting.ID)
+			if !streamDone(f) {
+				return f.Filled, fillPrice(&trade.Order{AvgFillPrice: f.AvgPrice}, resting.Price), moved,
+					fmt.Errorf("%w: order %s: cancel: %v; lookup: %v", ErrUnresolved, resting.ID, cancelErr, err)
+			}
+			final = &trade.Order{}
+		}
+	}
+	if f := c.streamed(resting.ID); f.Filled > final.FilledQty {
+		final = &trade.Order{FilledQty: f.Filled, AvgFillPrice: f.AvgPrice}
+	}
+	return final.FilledQty, fillPrice(final, resting.Price), moved, nil
+}
+
+// streamDone reports whether a stream event leaves its order off the book.
+func streamDone(f Fill) bool {
+	switch f.Status {
+	case "FILLED", "CANCELED", "EXPIRED", "REJECTED":
+		return true
+	}
+	return false
+}
+
+func (c *Chaser) place(ctx context.Context, order *trade.Order, qty, price float64) (*trade.Order, error) {
+	ctx, cancel := c.cfg.Calls.Context(ctx, callpolicy.Order)
+	defer cancel()
+
+	child := &trade.Order{
+		Symbol:   order.Symbol,
+		Side:     order.Side,
+		Type:     trade.OrderTypeLimit,
+		Quantity: qty,
+		Price:    price,
+	}
+	placed, err := c.venue.CreateOrder(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+	placed.Price = price
+	return placed, nil
+}
+
+func (c *Chaser) getOrder(ctx context.Context, resting *trade.Order) (*trade.Order, error) {
+	ctx, cancel := c.cfg.Calls.Context(ctx, callpolicy.Order)
+	defer cancel()
+	return c.venue.GetOrder(ctx, resting.ID, resting.Symbol)
+}
+
+func (c *Chaser) bookTop(ctx context.Context, symbol string) (float64, float64, error) {
+	ctx, cancel := c.cfg.Calls.Context(ctx, callpolicy.Exchange)
+	defer cancel()
+
+	bid, ask, err := c.venue.BookTop(ctx, symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	if bid <= 0 || ask <= 0 {
+		return 0, 0, ErrNoBook
+	}
+	return bid, ask, nil
+}
+
+func (c *Chaser) round(symbol string, qty float64) float64 {
+	if c.cfg.RoundQuantity != nil {
+		return c.cfg.RoundQuantity(symbol, qty)
+	}
+	return qty
+}
+
+func (c *Chaser) record(res Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Chases++
+	c.stats.Repegs += res.Repegs
+	switch {
+	case res.RemainderFilled > 0:
+		c.stats.MarketRemainders++
+	case res.Remainder == RemainderChase:
+		c.stats.ChasedRemainders++
+	}
+	switch {
+	case res.Filled == 0:
+		c.stats.Abandoned++
+		return
+	case res.Abandoned:
+		c.stats.Partial++
+	default:
+		c.stats.Filled++
+	}
+
+	c.stats.ImprovementUSD += res.ImprovementBps / 1e4 * res.ArrivalPrice * res.Filled
+	c.bps += res.ImprovementBps
+	c.stats.AvgImprovement = c.bps / float64(c.stats.Filled+c.stats.Partial)
+}
+
+func near(side trade.Side, bid, ask float64) float64 {
+	if side == trade.SideBuy {
+		return bid
+	}
+	return ask
+}
+
+func far(side trade.Side, bid, ask float64) float64 {
+	if side == trade.SideBuy {
+		return ask
+	}
+	return bid
+}
+
+func improvement(side trade.Side, arrival, avg float64) float64 {
+	if arrival <= 0 {
+		return 0
+	}
+	if side == trade.SideBuy {
+		return (arrival - avg) / arrival * 1e4
+	}
+	return (avg - arrival) / arrival * 1e4
+}
+
+func fillPrice(o *trade.Order, fallback float64) float64 {
+	if o.AvgFillPrice > 0 {
+		return o.AvgFillPrice
+	}
+	return fallback
+}
@@ -0,0 +1,210 @@
+package chase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// fakeVenue fills a resting buy once the ask trades down to its price.
+type fakeVenue struct {
+	mu       sync.Mutex
+	bid, ask float64
+	orders   map[string]*trade.Order
+	seq      int
+	placed   []float64
+	// partial is filled on a resting order when it is cancelled
+	partial float64
+	// blind fails cancels and order lookups
+	blind bool
+}
+
+func (v *fakeVenue) BookTop(context.Context, string) (float64, float64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.bid, v.ask, nil
+}
+
+func (v *fakeVenue) CreateOrder(_ context.Context, o *trade.Order) (*trade.Order, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.seq++
+	placed := *o
+	placed.ID = strconv.Itoa(v.seq)
+	placed.Status = trade.OrderStatusSubmitted
+	if o.Type == trade.OrderTypeMarket {
+		placed.Status, placed.FilledQty, placed.AvgFillPrice = trade.OrderStatusFilled, o.Quantity, v.ask
+	}
+	v.orders[placed.ID] = &placed
+	v.placed = append(v.placed, o.Price)
+	return &placed, nil
+}
+
+func (v *fakeVenue) GetOrder(_ context.Context, id, _ string) (*trade.Order, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.blind {
+		return nil, errors.New("lookup failed")
+	}
+	o := *v.orders[id]
+	if o.Status != trade.OrderStatusCancelled && v.ask <= o.Price {
+		o.Status, o.FilledQty, o.AvgFillPrice = trade.OrderStatusFilled, o.Quantity, o.Price
+		*v.orders[id] = o
+	}
+	return &o, nil
+}
+
+func (v *fakeVenue) CancelOrder(_ context.Context, _, id string) (*trade.Order, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.blind {
+		return nil, errors.New("cancel failed")
+	}
+	o := v.orders[id]
+	o.Status = trade.OrderStatusCancelled
+	if o.FilledQty == 0 && v.partial > 0 {
+		o.FilledQty, o.AvgFillPrice = v.partial, o.Price
+	}
+	cp := *o
+	return &cp, nil
+}
+
+func (v *fakeVenue) move(bid, ask float64) {
+	v.mu.Lock()
+	v.bid, v.ask = bid, ask
+	v.mu.Unlock()
+}
+
+func TestChaseRepegsAndRecordsImprovement(t *testing.T) {
+	venue := &fakeVenue{bid: 100, ask: 100.2, orders: make(map[string]*trade.Order)}
+	c := New(Config{MaxRepegs: 2, MaxWait: time.Second, PollInterval: time.Millisecond}, venue)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		venue.move(100.1, 100.3) // touch moves away: re-peg to 100.1
+		time.Sleep(10 * time.Millisecond)
+		venue.move(100.0, 100.1) // offer trades down to the resting bid
+	}()
+
+	res, err := c.Execute(context.Background(), &trade.Order{Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Filled != 1 || res.AvgPrice != 100.1 || res.Repegs != 1 || res.Abandoned {
+		t.Fatalf("unexpected result %+v (placed at %v)", res, venue.placed)
+	}
+	// A market buy would have paid the 100.2 ask.
+	if res.ImprovementBps < 9.9 || res.ImprovementBps > 10.1 {
+		t.Errorf("improvement = %.2f bps, want ~10", res.ImprovementBps)
+	}
+}
+
+func TestChaseAbandonsAfterMaxRepegs(t *testing.T) {
+	venue := &fakeVenue{bid: 100, ask: 100.2, orders: make(map[string]*trade.Order)}
+	c := New(Config{MaxRepegs: 1, MaxWait: time.Second, PollInterval: time.Millisecond}, venue)
+
+	done := make(chan struct{})
+	go func() {
+		for p := 100.0; ; p += 0.1 {
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+				venue.move(p, p+0.2)
+			}
+		}
+	}()
+	defer close(done)
+
+	res, err := c.Execute(context.Background(), &trade.Order{Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Filled != 0 || !res.Abandoned || res.Repegs != 1 {
+		t.Fatalf("expected an abandoned chase after one re-peg, got %+v", res)
+	}
+	if st := c.Stats(); st.Abandoned != 1 || st.Chases != 1 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestChaseTakesRemainderAtMarket(t *testing.T) {
+	venue := &fakeVenue{bid: 100, ask: 100.2, orders: make(map[string]*trade.Order), partial: 0.4}
+	c := New(Config{MaxRepegs: 1, MaxWait: 20 * time.Millisecond, PollInterval: time.Millisecond, Remainder: RemainderMarket}, venue)
+
+	res, err := c.Execute(context.Background(), &trade.Order{Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Filled != 1 || res.RemainderFilled != 0.6 || res.Abandoned || res.Remainder != RemainderMarket {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if want := (0.4*100 + 0.6*100.2) / 1; res.AvgPrice < want-1e-9 || res.AvgPrice > want+1e-9 {
+		t.Errorf("avg price = %v, want %v", res.AvgPrice, want)
+	}
+	if st := c.Stats(); st.MarketRemainders != 1 || st.Filled != 1 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestChaseKeepsPartialWhenMarketHasRunAway(t *testing.T) {
+	venue := &fakeVenue{bid: 100, ask: 100.2, orders: make(map[string]*trade.Order), partial: 0.4}
+	c := New(Config{MaxRepegs: 1, MaxWait: 20 * time.Millisecond, PollInterval: time.Millisecond,
+		Remainder: RemainderMarket, RemainderMaxSlippageBps: 5}, venue)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		venue.move(100.5, 100.7) // 50 bps above arrival
+	}()
+
+	res, err := c.Execute(context.Background(), &trade.Order{Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.RemainderFilled != 0 || !res.Abandoned || res.Filled < 0.4 {
+		t.Fatalf("expected the partial fill kept, got %+v", res)
+	}
+}
+
+func TestChaseCountsStreamedFillsRESTMissed(t *testing.T) {
+	venue := &fakeVenue{bid: 100, ask: 100.2, orders: make(map[string]*trade.Order), blind: true}
+	c := New(Config{MaxRepegs: 1, MaxWait: 20 * time.Millisecond, PollInterval: time.Millisecond}, venue)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		c.Update(Fill{OrderID: "1", Status: "PARTIALLY_FILLED", Filled: 0.3, AvgPrice: 100})
+		c.Update(Fill{OrderID: "1", Status: "CANCELED", Filled: 0.3, AvgPrice: 100})
+	}()
+
+	res, err := c.Execute(context.Background(), &trade.Order{Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Filled != 0.3 || res.AvgPrice != 100 || !res.Abandoned || res.Remainder != RemainderCancel {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestChaseStopsOnAnOrderItCannotResolve(t *testing.T) {
+	venue := &fakeVenue{bid: 100, ask: 100.2, orders: make(map[string]*trade.Order), blind: true}
+	c := New(Config{MaxRepegs: 3, MaxWait: time.Second, PollInterval: time.Millisecond}, venue)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		c.Update(Fill{OrderID: "1", Status: "PARTIALLY_FILLED", Filled: 0.3, AvgPrice: 100})
+		venue.move(100.1, 100.3) // would re-peg, but the first order may still be working
+	}()
+
+	res, err := c.Execute(context.Background(), &trade.Order{Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 1})
+	if !errors.Is(err, ErrUnresolved) {
+		t.Fatalf("err = %v, want ErrUnresolved", err)
+	}
+	if res.Filled != 0.3 || res.Repegs != 0 || len(venue.placed) != 1 {
+		t.Fatalf("unexpected result %+v (placed at %v)", res, venue.placed)
+	}
+}
@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"sort"
+	"time"
+
+	"github.com/britej3/gobot/services/riskmap"
+)
+
+// Candidate is a signal considered for entry.
+type Candidate struct {
+	Symbol string
+	Side   string
+	Score  float64
+	Time   time.Time
+}
+
+// Cluster is a group of candidates on correlated symbols, in the same
+// direction and within Window of the first, which most likely share one
+// market-wide move. Indices refer to the slice passed to Detect.
+type Cluster struct {
+	Bucket  string
+	Side    string
+	Winner  int
+	Members []int
+}
+
+type Config struct {
+	Window time.Duration
+	// Buckets groups correlated symbols. Symbols in no bucket never cluster.
+	Buckets riskmap.Buckets
+}
+
+// Detector finds clusters of correlated entries so only one of each is taken.
+type Detector struct {
+	cfg Config
+}
+
+func New(cfg Config) *Detector {
+	return &Detector{cfg: cfg}
+}
+
+// Detect returns the clusters of two or more candidates, ordered by their
+// first member. The winner is the highest score, the earliest on a tie.
+func (d *Detector) Detect(cands []Candidate) []Cluster {
+	if d.cfg.Window <= 0 || len(cands) < 2 {
+		return nil
+	}
+
+	order := make([]int, len(cands))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return cands[order[a]].Time.Before(cands[order[b]].Time)
+	})
+
+	type open struct {
+		start time.Time
+		idx   int // into clusters
+	}
+	var clusters []Cluster
+	current := make(map[string]open)
+	for _, i := range order {
+		c := cands[i]
+		bucket := d.cfg.Buckets.Of(c.Symbol)
+		if bucket == riskmap.DefaultBucket {
+			continue
+		}
+		key := bucket + "/" + c.Side
+		if o, ok := current[key]; ok && c.Time.Sub(o.start) <= d.cfg.Window {
+			cl := &clusters[o.idx]
+			cl.Members = append(cl.Members, i)
+			if c.Score > cands[cl.Winner].Score {
+				cl.Winner = i
+			}
+			continue
+		}
+		current[key] = open{start: c.Time, idx: len(clusters)}
+		clusters = append(clusters, Cluster{Bucket: bucket, Side: c.Side, Winner: i, Members: []int{i}})
+	}
+
+	out := clusters[:0]
+	for _, cl := range clusters {
+		if len(cl.Members) > 1 {
+			out = append(out, cl)
+		}
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/services/riskmap"
+)
+
+func TestDetectKeepsBestOfCorrelatedBurst(t *testing.T) {
+	d := New(Config{
+		Window: 10 * time.Second,
+		Buckets: riskmap.Buckets{
+			"majors": {"BTCUSDT", "ETHUSDT"},
+			"memes":  {"DOGEUSDT", "WIFUSDT"},
+		},
+	})
+	t0 := time.Now()
+	cands := []Candidate{
+		{Symbol: "BTCUSDT", Side: "BUY", Score: 0.8, Time: t0},
+		{Symbol: "DOGEUSDT", Side: "BUY", Score: 0.9, Time: t0.Add(time.Second)},
+		{Symbol: "ETHUSDT", Side: "BUY", Score: 0.85, Time: t0.Add(2 * time.Second)},
+		{Symbol: "WIFUSDT", Side: "SELL", Score: 0.95, Time: t0.Add(3 * time.Second)}, // opposite side
+		{Symbol: "SOLUSDT", Side: "BUY", Score: 0.99, Time: t0.Add(3 * time.Second)},  // no bucket
+		{Symbol: "BTCUSDT", Side: "BUY", Score: 0.99, Time: t0.Add(30 * time.Second)}, // outside window
+		{Symbol: "ETHUSDT", Side: "BUY", Score: 0.7, Time: t0.Add(35 * time.Second)},
+	}
+
+	got := d.Detect(cands)
+	if len(got) != 2 {
+		t.Fatalf("got %d clusters: %+v", len(got), got)
+	}
+	if got[0].Bucket != "majors" || got[0].Winner != 2 || len(got[0].Members) != 2 {
+		t.Errorf("first cluster = %+v", got[0])
+	}
+	if got[1].Winner != 5 || len(got[1].Members) != 2 || got[1].Members[1] != 6 {
+		t.Errorf("second cluster = %+v", got[1])
+	}
+
+	if New(Config{}).Detect(cands) != nil {
+		t.Error("a zero window should disable clustering")
+	}
+}
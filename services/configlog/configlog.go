@@ -0,0 +1,242 @@
+package configlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a config change.
+const (
+	SourceStartup   = "startup"
+	SourceReload    = "reload"
+	SourceOptimizer = "optimizer"
+	SourceRollback  = "rollback"
+)
+
+// Redacted replaces secret values in snapshots.
+const Redacted = "[redacted]"
+
+// Settings is a config flattened to dotted keys, e.g. trading.min_confidence_threshold.
+type Settings map[string]interface{}
+
+type Change struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Version is one effective config. Every version carries the full snapshot,
+// so any of them can be restored, and the changes from its predecessor.
+type Version struct {
+	Version  int       `json:"version"`
+	Time     time.Time `json:"ts"`
+	Source   string    `json:"source"`
+	Note     string    `json:"note,omitempty"`
+	Changes  []Change  `json:"changes,omitempty"`
+	Settings Settings  `json:"settings"`
+}
+
+// Journal appends a new version to a JSON-lines file whenever the effective
+// config differs from the last one recorded.
+type Journal struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+	last *Version
+}
+
+// Open opens the journal at path, continuing its version numbering.
+func Open(path string) (*Journal, error) {
+	versions, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{path: path, file: f}
+	if n := len(versions); n > 0 {
+		j.last = &versions[n-1]
+	}
+	return j, nil
+}
+
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record journals settings as a new version when they differ from the
+// current one. The second result reports whether a version was written.
+func (j *Journal) Record(source, note string, settings Settings) (Version, bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return Version{}, false, os.ErrClosed
+	}
+
+	v := Version{Version: 1, Time: time.Now(), Source: source, Note: note, Settings: settings}
+	if j.last != nil {
+		v.Changes = Diff(j.last.Settings, settings)
+		if len(v.Changes) == 0 {
+			return *j.last, false, nil
+		}
+		v.Version = j.last.Version + 1
+	}
+
+	line, err := json.Marshal(v)
+	if err != nil {
+		return Version{}, false, err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return Version{}, false, err
+	}
+	// Keep the settings as they read back from the file, so later diffs
+	// compare like with like.
+	json.Unmarshal(line, &v)
+	j.last = &v
+	return v, true, nil
+}
+
+// Current returns the latest version, false before anything is recorded.
+func (j *Journal) Current() (Version, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.last == nil {
+		return Version{}, false
+	}
+	return *j.last, true
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Read returns every version in the journal at path, oldest first.
+func Read(path string) ([]Version, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Version
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var v Version
+		if json.Unmarshal(scanner.Bytes(), &v) == nil {
+			out = append(out, v)
+		}
+	}
+	return out, scanner.Err()
+}
+
+// At returns the version in effect at t, false if t predates the journal.
+func At(versions []Version, t time.Time) (Version, bool) {
+	i := sort.Search(len(versions), func(i int) bool {
+		return versions[i].Time.After(t)
+	})
+	if i == 0 {
+		return Version{}, false
+	}
+	return versions[i-1], true
+}
+
+// Flatten turns a yaml-tagged config struct into Settings, redacting keys,
+// secrets, tokens and passwords.
+func Flatten(cfg interface{}) (Settings, error) {
+	raw, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+	out := make(Settings)
+	flatten("", tree, out)
+
+	// Round-trip through JSON so snapshots compare equal to what Read
+	// returns, e.g. every number as float64.
+	line, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(Settings)
+	return settings, json.Unmarshal(line, &settings)
+}
+
+func flatten(prefix string, v interface{}, out Settings) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		if secret(prefix) && v != nil && v != "" {
+			v = Redacted
+		}
+		out[prefix] = v
+		return
+	}
+	if len(m) == 0 && prefix != "" {
+		out[prefix] = m
+		return
+	}
+	for k, child := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flatten(key, child, out)
+	}
+}
+
+func secret(key string) bool {
+	leaf := key[strings.LastIndex(key, ".")+1:]
+	for _, suffix := range []string{"key", "secret", "password", "token"} {
+		if leaf == suffix || strings.HasSuffix(leaf, "_"+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the keys whose values differ between old and new, sorted.
+func Diff(old, new Settings) []Change {
+	var changes []Change
+	for k, nv := range new {
+		ov, ok := old[k]
+		if !ok || !reflect.DeepEqual(ov, nv) {
+			changes = append(changes, Change{Key: k, Old: ov, New: nv})
+		}
+	}
+	for k, ov := range old {
+		if _, ok := new[k]; !ok {
+			changes = append(changes, Change{Key: k, Old: ov})
+		}
+	}
+	sort.Slice(changes, func(a, b int) bool { return changes[a].Key < changes[b].Key })
+	return changes
+}
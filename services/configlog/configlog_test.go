@@ -0,0 +1,82 @@
+package configlog
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Trading struct {
+		MinConfidence float64  `yaml:"min_confidence_threshold"`
+		Symbols       []string `yaml:"symbols"`
+	} `yaml:"trading"`
+	Binance struct {
+		APIKey string `yaml:"api_key"`
+	} `yaml:"binance"`
+}
+
+func TestRecordVersionsOnlyOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "config.jsonl")
+	j, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg testConfig
+	cfg.Trading.MinConfidence = 0.75
+	cfg.Trading.Symbols = []string{"BTCUSDT"}
+	cfg.Binance.APIKey = "live-key"
+
+	record := func(source string) (Version, bool) {
+		settings, err := Flatten(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, changed, err := j.Record(source, "", settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v, changed
+	}
+
+	v1, changed := record(SourceStartup)
+	if !changed || v1.Version != 1 || v1.Settings["binance.api_key"] != Redacted {
+		t.Fatalf("first version = %+v", v1)
+	}
+	if _, changed := record(SourceStartup); changed {
+		t.Error("unchanged config recorded a new version")
+	}
+
+	cfg.Trading.MinConfidence = 0.8
+	cfg.Binance.APIKey = "rotated-key"
+	v2, changed := record(SourceOptimizer)
+	if !changed || v2.Version != 2 || len(v2.Changes) != 1 {
+		t.Fatalf("second version = %+v", v2)
+	}
+	if c := v2.Changes[0]; c.Key != "trading.min_confidence_threshold" || c.Old != 0.75 || c.New != 0.8 {
+		t.Errorf("unexpected change %+v", c)
+	}
+	j.Close()
+
+	// Reopening continues the numbering and the diff base.
+	j, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, changed := record(SourceStartup); changed {
+		t.Error("reopened journal recorded an unchanged config")
+	}
+	j.Close()
+
+	versions, err := Read(path)
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("read %d versions, %v", len(versions), err)
+	}
+	if v, ok := At(versions, v2.Time.Add(time.Second)); !ok || v.Version != 2 {
+		t.Errorf("version at %v = %d", v2.Time, v.Version)
+	}
+	if _, ok := At(versions, v1.Time.Add(-time.Second)); ok {
+		t.Error("expected no version before the journal started")
+	}
+}
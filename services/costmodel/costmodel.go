@@ -0,0 +1,280 @@
+// Package costmodel forecasts what an entry will cost to execute before it
+// is sent. Slippage is read from the book the order would walk, or from the
+// quote when there is no local book, and compared with the slippage entries
+// of the same symbol and size have realized before; the larger of the two
+// is the forecast, so a book that looks deep but has filled badly is not
+// trusted. Each fill is scored against its forecast to calibrate the model.
+package costmodel
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/orderbook"
+)
+
+// Book returns the best levels of a local order book.
+type Book interface {
+	Levels(symbol string, n int) (bids, asks []orderbook.Level, ok bool)
+}
+
+type Config struct {
+	// FeeRate is the taker fee per side; the forecast counts both sides.
+	FeeRate float64
+	// Fee, when set, returns the live fee per side for a symbol in place
+	// of FeeRate.
+	Fee func(symbol string) float64
+	// Levels is how deep the book is walked.
+	Levels int
+	// MinSamples is how many fills a symbol and size bucket needs before
+	// its history counts.
+	MinSamples int
+	// Window is how many recent fills are kept per bucket.
+	Window int
+}
+
+// Forecast is the expected cost of one entry in basis points of the price
+// the signal expects to enter at. SlippageBps is the larger of BookBps and,
+// once the bucket has enough Samples, HistoryBps.
+type Forecast struct {
+	Symbol      string
+	Side        trade.Side
+	Entry       float64
+	Notional    float64
+	Bucket      string
+	BookBps     float64
+	HistoryBps  float64
+	Samples     int
+	SlippageBps float64
+	FeeBps      float64
+	TotalBps    float64
+}
+
+func (f Forecast) String() string {
+	return fmt.Sprintf("%s %s %s: %.1f bps (slippage %.1f, fees %.1f)", f.Side, f.Symbol, f.Bucket, f.TotalBps, f.SlippageBps, f.FeeBps)
+}
+
+// Calibration scores a forecast against its fill. ErrorBps is realized
+// minus forecast slippage, positive when the fill was worse than forecast.
+type Calibration struct {
+	Forecast    Forecast
+	RealizedBps float64
+	ErrorBps    float64
+}
+
+type Stats struct {
+	Forecasts    int
+	Calibrations int
+	// MeanErrorBps is the average of realized minus forecast slippage and
+	// MeanAbsErrorBps its absolute value: the bias and the accuracy.
+	MeanErrorBps    float64
+	MeanAbsErrorBps float64
+	Buckets         int
+}
+
+type history struct {
+	fills []float64
+	next  int
+}
+
+func (h *history) add(bps float64, window int) {
+	if len(h.fills) < window {
+		h.fills = append(h.fills, bps)
+		return
+	}
+	h.fills[h.next] = bps
+	h.next = (h.next + 1) % window
+}
+
+func (h *history) mean() float64 {
+	sum := 0.0
+	for _, v := range h.fills {
+		sum += v
+	}
+	return sum / float64(len(h.fills))
+}
+
+type Model struct {
+	cfg     Config
+	book    Book
+	mu      sync.Mutex
+	history map[string]*history
+	stats   Stats
+	errSum  float64
+	absSum  float64
+}
+
+// New creates a model. book may be nil, in which case slippage is read from
+// the quote passed to Forecast.
+func New(cfg Config, book Book) *Model {
+	if cfg.Levels <= 0 {
+		cfg.Levels = 20
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 3
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 50
+	}
+
+	return &Model{
+		cfg:     cfg,
+		book:    book,
+		history: make(map[string]*history),
+	}
+}
+
+// Bucket names the size band of notional.
+func Bucket(notional float64) string {
+	switch {
+	case notional < 100:
+		return "<100"
+	case notional < 1_000:
+		return "<1k"
+	case notional < 10_000:
+		return "<10k"
+	}
+	return "10k+"
+}
+
+func (m *Model) feeRate(symbol string) float64 {
+	if m.cfg.Fee != nil {
+		return m.cfg.Fee(symbol)
+	}
+	return m.cfg.FeeRate
+}
+
+// Forecast estimates the cost of entering quantity at entry on side, with
+// bid and ask the live quote.
+func (m *Model) Forecast(symbol string, side trade.Side, entry, quantity, bid, ask float64) Forecast {
+	notional := quantity * entry
+	f := Forecast{
+		Symbol:   symbol,
+		Side:     side,
+		Entry:    entry,
+		Notional: notional,
+		Bucket:   Bucket(notional),
+		FeeBps:   2 * m.feeRate(symbol) * 1e4,
+	}
+	if entry <= 0 {
+		return f
+	}
+
+	price := ask
+	if side == trade.SideSell {
+		price = bid
+	}
+	if m.book != nil {
+		if vwap, ok := m.walk(symbol, side, quantity); ok {
+			price = vwap
+		}
+	}
+	if price > 0 {
+		f.BookBps = slippage(side, entry, price)
+	}
+	f.SlippageBps = f.BookBps
+
+	m.mu.Lock()
+	m.stats.Forecasts++
+	if h := m.history[key(symbol, f.Bucket)]; h != nil {
+		f.Samples = len(h.fills)
+		f.HistoryBps = h.mean()
+		if f.Samples >= m.cfg.MinSamples && f.HistoryBps > f.SlippageBps {
+			f.SlippageBps = f.HistoryBps
+		}
+	}
+	m.mu.Unlock()
+
+	f.TotalBps = f.SlippageBps + f.FeeBps
+	return f
+}
+
+// walk returns the average price of taking quantity from the side of the
+// book an order on side would hit. A book too thin to fill it prices the
+// remainder at its last level.
+func (m *Model) walk(symbol string, side trade.Side, quantity float64) (float64, bool) {
+	bids, asks, ok := m.book.Levels(symbol, m.cfg.Levels)
+	levels := asks
+	if side == trade.SideSell {
+		levels = bids
+	}
+	if !ok || len(levels) == 0 || quantity <= 0 {
+		return 0, false
+	}
+
+	left, cost := quantity, 0.0
+	for _, l := range levels {
+		take := math.Min(left, l.Quantity)
+		cost += take * l.Price
+		left -= take
+		if left <= 0 {
+			break
+		}
+	}
+	if left > 0 {
+		cost += left * levels[len(levels)-1].Price
+	}
+	return cost / quantity, true
+}
+
+// Observe adds a realized slippage to the history of symbol's size bucket.
+func (m *Model) Observe(symbol string, notional, realizedBps float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observeLocked(key(symbol, Bucket(notional)), realizedBps)
+}
+
+func (m *Model) observeLocked(k string, bps float64) {
+	h := m.history[k]
+	if h == nil {
+		h = &history{}
+		m.history[k] = h
+	}
+	h.add(bps, m.cfg.Window)
+}
+
+// Calibrate records the fill of a forecast entry: its realized slippage
+// joins the bucket's history and the forecast error the model's stats.
+func (m *Model) Calibrate(f Forecast, fillPrice float64) (Calibration, bool) {
+	if f.Entry <= 0 || fillPrice <= 0 {
+		return Calibration{}, false
+	}
+	c := Calibration{Forecast: f, RealizedBps: slippage(f.Side, f.Entry, fillPrice)}
+	c.ErrorBps = c.RealizedBps - f.SlippageBps
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observeLocked(key(f.Symbol, f.Bucket), c.RealizedBps)
+	m.stats.Calibrations++
+	m.errSum += c.ErrorBps
+	m.absSum += math.Abs(c.ErrorBps)
+	return c, true
+}
+
+func (m *Model) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.stats
+	s.Buckets = len(m.history)
+	if s.Calibrations > 0 {
+		s.MeanErrorBps = m.errSum / float64(s.Calibrations)
+		s.MeanAbsErrorBps = m.absSum / float64(s.Calibrations)
+	}
+	return s
+}
+
+// slippage is how much worse than entry price is for side, in basis points.
+func slippage(side trade.Side, entry, price float64) float64 {
+	bps := (price - entry) / entry * 1e4
+	if side == trade.SideSell {
+		bps = -bps
+	}
+	return bps
+}
+
+func key(symbol, bucket string) string {
+	return symbol + "|" + bucket
+}
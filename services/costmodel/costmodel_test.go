@@ -0,0 +1,62 @@
+package costmodel
+
+import (
+	"math"
+	"testing"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/orderbook"
+)
+
+type book struct {
+	bids, asks []orderbook.Level
+}
+
+func (b book) Levels(symbol string, n int) ([]orderbook.Level, []orderbook.Level, bool) {
+	return b.bids, b.asks, symbol == "BTCUSDT"
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestForecast_WalksBookAndTrustsWorseHistory(t *testing.T) {
+	b := book{
+		bids: []orderbook.Level{{Price: 99.9, Quantity: 10}},
+		asks: []orderbook.Level{{Price: 100.1, Quantity: 1}, {Price: 100.3, Quantity: 1}},
+	}
+	m := New(Config{FeeRate: 0.0004, MinSamples: 2}, b)
+
+	// Two units cost 100.2 on average: 20 bps over an entry of 100.
+	f := m.Forecast("BTCUSDT", trade.SideBuy, 100, 2, 99.9, 100.1)
+	if !near(f.BookBps, 20) || !near(f.SlippageBps, 20) || !near(f.FeeBps, 8) || !near(f.TotalBps, 28) || f.Bucket != "<1k" {
+		t.Fatalf("forecast = %+v", f)
+	}
+
+	// Without a local book the quote is the price.
+	f = m.Forecast("ETHUSDT", trade.SideSell, 100, 1, 99.9, 100.1)
+	if !near(f.BookBps, 10) {
+		t.Fatalf("quote forecast = %+v", f)
+	}
+
+	// Fills worse than the book said take over once there are enough.
+	c, ok := m.Calibrate(m.Forecast("BTCUSDT", trade.SideBuy, 100, 2, 99.9, 100.1), 100.5)
+	if !ok || !near(c.RealizedBps, 50) || !near(c.ErrorBps, 30) {
+		t.Fatalf("calibration = %+v", c)
+	}
+	if f := m.Forecast("BTCUSDT", trade.SideBuy, 100, 2, 99.9, 100.1); f.Samples != 1 || !near(f.SlippageBps, 20) {
+		t.Fatalf("one fill should not count yet: %+v", f)
+	}
+	m.Observe("BTCUSDT", 200, 30)
+	if f := m.Forecast("BTCUSDT", trade.SideBuy, 100, 2, 99.9, 100.1); f.Samples != 2 || !near(f.SlippageBps, 40) {
+		t.Fatalf("history should win: %+v", f)
+	}
+	// A different size bucket has its own history.
+	if f := m.Forecast("BTCUSDT", trade.SideBuy, 100, 0.5, 99.9, 100.1); f.Samples != 0 || !near(f.SlippageBps, 10) {
+		t.Fatalf("small bucket = %+v", f)
+	}
+
+	if s := m.Stats(); s.Calibrations != 1 || !near(s.MeanErrorBps, 30) || s.Buckets != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
@@ -0,0 +1,221 @@
+package decisionlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type Action string
+
+const (
+	ActionEnter  Action = "enter"
+	ActionSkip   Action = "skip"   // no candidate, or not allowed to look
+	ActionReject Action = "reject" // a candidate failed a check
+	ActionDrop   Action = "drop"   // an accepted candidate never reached the book
+	ActionWatch  Action = "watch"  // an accepted candidate watch-only mode did not send
+)
+
+// Reason codes. They are stable identifiers meant for querying; free-form
+// context goes in Record.Detail.
+const (
+	ReasonNoSetup         = "no_setup"
+	ReasonAnalysisError   = "analysis_error"
+	ReasonBrainVeto       = "brain_veto"
+	ReasonLowConfidence   = "low_confidence"
+	ReasonSymbolMemory    = "symbol_memory"
+	ReasonSymbolThrottle  = "symbol_throttle"
+	ReasonCooldown        = "cooldown"
+	ReasonHalted          = "halted"
+	ReasonSuspended       = "suspended"
+	ReasonAccountPaused   = "account_paused"
+	ReasonAPIKey          = "api_key"
+	ReasonMaintenance     = "maintenance"
+	ReasonSymbolHalted    = "symbol_halted"
+	ReasonLiquidations    = "liquidation_cascade"
+	ReasonMaxTradesPerDay = "max_trades_per_day"
+	ReasonDailyLossLimit  = "daily_loss_limit"
+	ReasonProfitLock      = "profit_lock"
+	ReasonZeroSize        = "zero_size"
+	ReasonBelowMinimum    = "below_exchange_minimum"
+	ReasonRiskReward      = "risk_reward"
+	ReasonPriceMoved      = "price_moved"
+	ReasonThinBook        = "thin_book"
+	ReasonExpectedValue   = "expected_value"
+	ReasonClustered       = "clustered"
+	ReasonEntryRate       = "entry_rate"
+	ReasonAllocation      = "allocation"
+	ReasonCycleBudget     = "cycle_budget"
+	ReasonStaleSignal     = "stale_signal"
+	ReasonOrderCancelled  = "order_cancelled"
+	ReasonChaseAbandoned  = "chase_abandoned"
+	ReasonUnfilled        = "unfilled"
+	ReasonOrderFailed     = "order_failed"
+	ReasonExecuted        = "executed"
+	ReasonWatchOnly       = "watch_only"
+)
+
+// Record is one trading decision about one candidate. Scores are the inputs
+// the decision was made on (confidence, components, R:R); Thresholds the
+// limits they were compared against.
+type Record struct {
+	Time       time.Time          `json:"ts"`
+	Symbol     string             `json:"symbol"`
+	Candidate  string             `json:"candidate,omitempty"`
+	Action     Action             `json:"action"`
+	Reasons    []string           `json:"reasons"`
+	Scores     map[string]float64 `json:"scores,omitempty"`
+	Thresholds map[string]float64 `json:"thresholds,omitempty"`
+	Detail     string             `json:"detail,omitempty"`
+	Source     string             `json:"source,omitempty"`
+	// Intent links the record to the lifecycle of the trade it decided.
+	Intent string `json:"intent,omitempty"`
+	// Inputs are the indicator values the brain was asked to confirm,
+	// kept so a changed prompt can be replayed against them.
+	Inputs map[string]float64 `json:"inputs,omitempty"`
+	// Namespace names the bot that made the decision when several write to
+	// shared storage.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Log appends decision records to a JSON-lines file.
+type Log struct {
+	path      string
+	namespace string
+	mu        sync.Mutex
+	file      *os.File
+}
+
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{path: path, file: f}, nil
+}
+
+func (l *Log) Path() string {
+	return l.path
+}
+
+// SetNamespace labels every record appended from now on with namespace.
+func (l *Log) SetNamespace(namespace string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.namespace = namespace
+}
+
+// Append writes r, stamping it with the current time and the log's
+// namespace if unset.
+func (l *Log) Append(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	l.mu.Lock()
+	if r.Namespace == "" {
+		r.Namespace = l.namespace
+	}
+	l.mu.Unlock()
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return os.ErrClosed
+	}
+	_, err = l.file.Write(append(line, '\n'))
+	return err
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Query selects records. Zero fields match everything.
+type Query struct {
+	Symbol    string
+	Namespace string
+	Action    Action
+	Reason    string
+	Since     time.Time
+	Until     time.Time
+	Limit     int // most recent matches only
+}
+
+func (q Query) matches(r Record) bool {
+	if q.Symbol != "" && r.Symbol != q.Symbol {
+		return false
+	}
+	if q.Action != "" && r.Action != q.Action {
+		return false
+	}
+	if q.Namespace != "" && r.Namespace != q.Namespace {
+		return false
+	}
+	if !q.Since.IsZero() && r.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && r.Time.After(q.Until) {
+		return false
+	}
+	if q.Reason == "" {
+		return true
+	}
+	for _, reason := range r.Reasons {
+		if reason == q.Reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Read returns the records in the log at path matching q, oldest first.
+// Lines that do not parse are skipped.
+func Read(path string, q Query) ([]Record, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if json.Unmarshal(scanner.Bytes(), &r) != nil {
+			continue
+		}
+		if q.matches(r) {
+			out = append(out, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return out, err
+	}
+
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package decisionlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "decisions.jsonl")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	records := []Record{
+		{Time: start, Symbol: "BTCUSDT", Action: ActionSkip, Reasons: []string{ReasonNoSetup}},
+		{Time: start.Add(time.Minute), Symbol: "ETHUSDT", Candidate: "LONG", Action: ActionReject, Reasons: []string{ReasonRiskReward},
+			Scores: map[string]float64{"risk_reward": 1.1}, Thresholds: map[string]float64{"min_risk_reward": 1.5}},
+		{Time: start.Add(2 * time.Minute), Symbol: "BTCUSDT", Candidate: "SHORT", Action: ActionEnter, Reasons: []string{ReasonExecuted}},
+		{Symbol: "BTCUSDT", Candidate: "LONG", Action: ActionReject, Reasons: []string{ReasonLowConfidence}},
+	}
+	for i, r := range records {
+		if i == 3 {
+			l.SetNamespace("alts")
+		}
+		if err := l.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Append(records[0]); err != os.ErrClosed {
+		t.Errorf("append after close = %v, want os.ErrClosed", err)
+	}
+
+	all, err := Read(path, Query{})
+	if err != nil || len(all) != 4 || all[3].Time.IsZero() {
+		t.Fatalf("read all = %d records, %v", len(all), err)
+	}
+
+	rejected, _ := Read(path, Query{Action: ActionReject, Reason: ReasonRiskReward})
+	if len(rejected) != 1 || rejected[0].Symbol != "ETHUSDT" || rejected[0].Thresholds["min_risk_reward"] != 1.5 {
+		t.Errorf("unexpected risk_reward rejections %+v", rejected)
+	}
+
+	btc, _ := Read(path, Query{Symbol: "BTCUSDT", Since: start.Add(30 * time.Second), Limit: 1})
+	if len(btc) != 1 || btc[0].Reasons[0] != ReasonLowConfidence {
+		t.Errorf("expected only the latest BTC decision, got %+v", btc)
+	}
+
+	if alts, _ := Read(path, Query{Namespace: "alts"}); len(alts) != 1 || alts[0].Reasons[0] != ReasonLowConfidence {
+		t.Errorf("expected only the record appended after SetNamespace, got %+v", alts)
+	}
+
+	if missing, err := Read(filepath.Join(t.TempDir(), "none.jsonl"), Query{}); err != nil || missing != nil {
+		t.Errorf("missing log = %v, %v", missing, err)
+	}
+}
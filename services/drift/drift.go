@@ -0,0 +1,322 @@
+// Package drift checks that the live account still matches what the config
+// intends. It looks at each symbol's leverage, at the stop order behind
+// every open position, and at how many positions are open. Settings changed
+// on the exchange UI, a stop cancelled by hand or a failed retry all look
+// healthy from inside the bot until they cost money; the checker finds them
+// and says how to put them right.
+package drift
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of drift.
+const (
+	KindLeverage  = "leverage"
+	KindStop      = "stop"
+	KindPositions = "positions"
+)
+
+// Position is one position-risk row. Size is negative for a short; flat
+// rows have zero Size and still carry the symbol's leverage.
+type Position struct {
+	Symbol   string
+	Size     float64
+	Leverage int
+}
+
+// Stop is an open stop order that closes or reduces a position. Side is
+// the order side: SELL protects a long, BUY a short.
+type Stop struct {
+	Symbol string
+	Side   string
+}
+
+// Source reads the live account.
+type Source interface {
+	Positions(ctx context.Context) ([]Position, error)
+	Stops(ctx context.Context) ([]Stop, error)
+}
+
+// Config is the intent to check against. Leverage is the leverage every
+// watched symbol should have, MaxLeverage the most any position may use
+// and MaxPositions the most positions open at once; zero skips a check.
+// RequireStops checks every open position for a stop order on the exchange.
+type Config struct {
+	Interval     time.Duration
+	Symbols      []string
+	Leverage     int
+	MaxLeverage  int
+	MaxPositions int
+	RequireStops bool
+	// Confirm is how many checks in a row must see a drift before it is
+	// reported, so a stop placed just after its entry is not one. Default 2.
+	Confirm int
+}
+
+// Drift is one way the account differs from the config, with the fix.
+type Drift struct {
+	Kind     string    `json:"kind"`
+	Symbol   string    `json:"symbol,omitempty"`
+	Detail   string    `json:"detail"`
+	Remedy   string    `json:"remedy"`
+	Since    time.Time `json:"since"`
+	Resolved bool      `json:"resolved,omitempty"`
+}
+
+func (d Drift) key() string { return d.Kind + "/" + d.Symbol }
+
+func (d Drift) String() string {
+	if d.Resolved {
+		return fmt.Sprintf("Drift resolved: %s", d.Detail)
+	}
+	return fmt.Sprintf("Drift: %s. Fix: %s", d.Detail, d.Remedy)
+}
+
+type Status struct {
+	Checks    int       `json:"checks"`
+	Failures  int       `json:"failures"`
+	LastCheck time.Time `json:"last_check"`
+	Drifts    []Drift   `json:"drifts"`
+}
+
+type Checker struct {
+	cfg      Config
+	source   Source
+	mu       sync.RWMutex
+	running  bool
+	seen     map[string]int
+	reported map[string]Drift
+	status   Status
+	handlers []func(Drift)
+	stopCh   chan struct{}
+	now      func() time.Time
+}
+
+func New(cfg Config, source Source) *Checker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.Confirm <= 0 {
+		cfg.Confirm = 2
+	}
+	return &Checker{
+		cfg:      cfg,
+		source:   source,
+		seen:     make(map[string]int),
+		reported: make(map[string]Drift),
+		stopCh:   make(chan struct{}),
+		now:      time.Now,
+	}
+}
+
+// OnDrift registers fn for every drift once it is confirmed, and again with
+// Resolved set when it clears.
+func (c *Checker) OnDrift(fn func(Drift)) {
+	c.mu.Lock()
+	c.handlers = append(c.handlers, fn)
+	c.mu.Unlock()
+}
+
+func (c *Checker) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	c.Check(ctx)
+	go c.run(ctx)
+	return nil
+}
+
+func (c *Checker) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return nil
+	}
+	c.running = false
+	close(c.stopCh)
+	return nil
+}
+
+func (c *Checker) run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+// Check reads the account and compares it with the config. A failed read
+// keeps the drifts of the last check.
+func (c *Checker) Check(ctx context.Context) error {
+	if c.source == nil {
+		return nil
+	}
+	positions, err := c.source.Positions(ctx)
+	var stops []Stop
+	if err == nil && c.cfg.RequireStops {
+		stops, err = c.source.Stops(ctx)
+	}
+
+	c.mu.Lock()
+	c.status.Checks++
+	c.status.LastCheck = c.now()
+	if err != nil {
+		c.status.Failures++
+		c.mu.Unlock()
+		return fmt.Errorf("drift: %w", err)
+	}
+	changed := c.apply(c.compare(positions, stops))
+	handlers := c.handlers
+	c.mu.Unlock()
+
+	for _, d := range changed {
+		for _, fn := range handlers {
+			fn(d)
+		}
+	}
+	return nil
+}
+
+// compare lists every drift in one reading of the account.
+func (c *Checker) compare(positions []Position, stops []Stop) []Drift {
+	var out []Drift
+	leverage := make(map[string]int, len(positions))
+	var open []Position
+	for _, p := range positions {
+		if _, ok := leverage[p.Symbol]; !ok {
+			leverage[p.Symbol] = p.Leverage
+		}
+		if p.Size != 0 {
+			open = append(open, p)
+		}
+	}
+
+	overLimit := make(map[string]bool)
+	protected := make(map[string]bool, len(stops))
+	for _, s := range stops {
+		protected[s.Symbol+"/"+s.Side] = true
+	}
+	for _, p := range open {
+		if c.cfg.MaxLeverage > 0 && p.Leverage > c.cfg.MaxLeverage {
+			overLimit[p.Symbol] = true
+			out = append(out, Drift{
+				Kind:   KindLeverage,
+				Symbol: p.Symbol,
+				Detail: fmt.Sprintf("%s position runs at %dx, above the %dx maximum", p.Symbol, p.Leverage, c.cfg.MaxLeverage),
+				Remedy: fmt.Sprintf("lower %s leverage to %dx or less on the exchange, or close the position", p.Symbol, c.cfg.MaxLeverage),
+			})
+		}
+		if c.cfg.RequireStops {
+			side, closing := "long", "SELL"
+			if p.Size < 0 {
+				side, closing = "short", "BUY"
+			}
+			if !protected[p.Symbol+"/"+closing] {
+				out = append(out, Drift{
+					Kind:   KindStop,
+					Symbol: p.Symbol,
+					Detail: fmt.Sprintf("%s %s has no stop order", p.Symbol, side),
+					Remedy: fmt.Sprintf("place a reduce-only %s stop for %s or close the position", closing, p.Symbol),
+				})
+			}
+		}
+	}
+
+	// A position over the maximum is already reported with the more urgent
+	// fix.
+	if c.cfg.Leverage > 0 {
+		for _, symbol := range c.cfg.Symbols {
+			lev, ok := leverage[symbol]
+			if !ok || lev == c.cfg.Leverage || overLimit[symbol] {
+				continue
+			}
+			out = append(out, Drift{
+				Kind:   KindLeverage,
+				Symbol: symbol,
+				Detail: fmt.Sprintf("%s leverage is %dx, config says %dx", symbol, lev, c.cfg.Leverage),
+				Remedy: "run gobot fix-account",
+			})
+		}
+	}
+
+	if c.cfg.MaxPositions > 0 && len(open) > c.cfg.MaxPositions {
+		out = append(out, Drift{
+			Kind:   KindPositions,
+			Detail: fmt.Sprintf("%d positions open, cap is %d", len(open), c.cfg.MaxPositions),
+			Remedy: fmt.Sprintf("close %d positions", len(open)-c.cfg.MaxPositions),
+		})
+	}
+	return out
+}
+
+// apply counts each drift towards its confirmation and returns the drifts
+// newly confirmed or resolved. Callers hold c.mu.
+func (c *Checker) apply(drifts []Drift) []Drift {
+	now := c.now()
+	var changed []Drift
+	current := make(map[string]bool, len(drifts))
+	for _, d := range drifts {
+		k := d.key()
+		if current[k] {
+			// Both legs of a hedge-mode symbol count once.
+			continue
+		}
+		current[k] = true
+		c.seen[k]++
+		if prev, ok := c.reported[k]; ok {
+			d.Since = prev.Since
+			c.reported[k] = d
+			continue
+		}
+		if c.seen[k] >= c.cfg.Confirm {
+			d.Since = now
+			c.reported[k] = d
+			changed = append(changed, d)
+		}
+	}
+	for k := range c.seen {
+		if current[k] {
+			continue
+		}
+		delete(c.seen, k)
+		if d, ok := c.reported[k]; ok {
+			delete(c.reported, k)
+			d.Resolved = true
+			changed = append(changed, d)
+		}
+	}
+
+	c.status.Drifts = c.status.Drifts[:0]
+	for _, d := range c.reported {
+		c.status.Drifts = append(c.status.Drifts, d)
+	}
+	sort.Slice(c.status.Drifts, func(i, j int) bool { return c.status.Drifts[i].key() < c.status.Drifts[j].key() })
+	return changed
+}
+
+func (c *Checker) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	st := c.status
+	st.Drifts = append([]Drift(nil), c.status.Drifts...)
+	return st
+}
@@ -0,0 +1,79 @@
+package drift
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type fakeSource struct {
+	positions []Position
+	stops     []Stop
+}
+
+func (s *fakeSource) Positions(context.Context) ([]Position, error) { return s.positions, nil }
+
+func (s *fakeSource) Stops(context.Context) ([]Stop, error) { return s.stops, nil }
+
+func TestCheck_ReportsConfirmedDriftWithRemedy(t *testing.T) {
+	src := &fakeSource{positions: []Position{
+		{Symbol: "BTCUSDT", Size: 0.1, Leverage: 20},
+		{Symbol: "ETHUSDT", Size: -2, Leverage: 5},
+		{Symbol: "SOLUSDT", Leverage: 10},
+	}, stops: []Stop{{Symbol: "ETHUSDT", Side: "BUY"}}}
+	c := New(Config{
+		Symbols:      []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"},
+		Leverage:     5,
+		MaxLeverage:  10,
+		MaxPositions: 1,
+		RequireStops: true,
+	}, src)
+	var got []Drift
+	c.OnDrift(func(d Drift) { got = append(got, d) })
+
+	c.Check(context.Background())
+	if len(got) != 0 {
+		t.Fatalf("drift reported before it was confirmed: %v", got)
+	}
+	c.Check(context.Background())
+
+	want := map[string]bool{
+		"leverage/BTCUSDT": true, // 20x, not 5x, and above the 10x maximum: one key
+		"leverage/SOLUSDT": true,
+		"stop/BTCUSDT":     true,
+		"positions/":       true,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for _, d := range got {
+		if !want[d.key()] || d.Remedy == "" {
+			t.Errorf("unexpected drift %+v", d)
+		}
+	}
+	if st := c.Status(); len(st.Drifts) != 4 || st.Checks != 2 {
+		t.Errorf("status %+v", st)
+	}
+}
+
+func TestCheck_ResolvesClearedDrift(t *testing.T) {
+	src := &fakeSource{positions: []Position{{Symbol: "BTCUSDT", Size: 1, Leverage: 5}}}
+	c := New(Config{RequireStops: true, Confirm: 1}, src)
+	var got []Drift
+	c.OnDrift(func(d Drift) { got = append(got, d) })
+
+	c.Check(context.Background())
+	src.stops = []Stop{{Symbol: "BTCUSDT", Side: "SELL"}}
+	c.Check(context.Background())
+	c.Check(context.Background())
+
+	if len(got) != 2 || got[0].Resolved || !got[1].Resolved {
+		t.Fatalf("got %v, want the missing stop and then its resolution", got)
+	}
+	if !strings.Contains(got[0].String(), "reduce-only SELL stop") {
+		t.Errorf("remedy %q", got[0])
+	}
+	if st := c.Status(); len(st.Drifts) != 0 {
+		t.Errorf("resolved drift still listed: %+v", st.Drifts)
+	}
+}
@@ -0,0 +1,232 @@
+package earnsweep
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type Margin struct {
+	Wallet    float64
+	Available float64
+}
+
+type EarnPosition struct {
+	Amount  float64
+	Rewards float64
+}
+
+// Account moves funds between futures margin and a flexible earn product.
+// Sweep and Recall must be complete round trips: the amount either lands in
+// earn (or back in futures margin) or the call fails.
+type Account interface {
+	FuturesMargin(ctx context.Context, asset string) (Margin, error)
+	EarnPosition(ctx context.Context, asset string) (EarnPosition, error)
+	Sweep(ctx context.Context, asset string, amount float64) error
+	Recall(ctx context.Context, asset string, amount float64) error
+}
+
+// Config sizes the free-margin reserve kept in futures. The reserve is
+// KeepFreePercent of total capital (futures wallet plus earn), at least
+// MinFreeMargin. Available margin above the reserve by MinSweep or more for
+// IdleFor is swept into earn; once available margin falls below RecallBelow
+// of the reserve, the shortfall is pulled back.
+type Config struct {
+	Asset           string
+	KeepFreePercent float64
+	MinFreeMargin   float64
+	MinSweep        float64
+	IdleFor         time.Duration
+	RecallBelow     float64
+	Interval        time.Duration
+}
+
+type Stats struct {
+	InEarn     float64
+	Rewards    float64
+	Swept      float64
+	Recalled   float64
+	Sweeps     int
+	Recalls    int
+	LastAction time.Time
+	LastError  string
+}
+
+type Sweeper struct {
+	cfg       Config
+	account   Account
+	mu        sync.RWMutex
+	running   bool
+	idleSince time.Time
+	stats     Stats
+	now       func() time.Time
+	stopCh    chan struct{}
+}
+
+func New(cfg Config, account Account) *Sweeper {
+	if cfg.Asset == "" {
+		cfg.Asset = "USDT"
+	}
+	if cfg.KeepFreePercent <= 0 {
+		cfg.KeepFreePercent = 50
+	}
+	if cfg.MinSweep <= 0 {
+		cfg.MinSweep = 50
+	}
+	if cfg.IdleFor <= 0 {
+		cfg.IdleFor = 6 * time.Hour
+	}
+	if cfg.RecallBelow <= 0 || cfg.RecallBelow > 1 {
+		cfg.RecallBelow = 0.8
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+
+	return &Sweeper{
+		cfg:     cfg,
+		account: account,
+		now:     time.Now,
+	}
+}
+
+func (s *Sweeper) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+	s.stopCh = make(chan struct{})
+	go s.run(ctx, s.stopCh)
+	return nil
+}
+
+func (s *Sweeper) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+func (s *Sweeper) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats
+}
+
+func (s *Sweeper) run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check evaluates margin once and sweeps or recalls as needed.
+func (s *Sweeper) Check(ctx context.Context) error {
+	margin, err := s.account.FuturesMargin(ctx, s.cfg.Asset)
+	if err != nil {
+		return s.fail(err)
+	}
+	earn, err := s.account.EarnPosition(ctx, s.cfg.Asset)
+	if err != nil {
+		return s.fail(err)
+	}
+
+	s.mu.Lock()
+	s.stats.InEarn = earn.Amount
+	s.stats.Rewards = earn.Rewards
+	s.mu.Unlock()
+
+	reserve := math.Max(s.cfg.MinFreeMargin, (margin.Wallet+earn.Amount)*s.cfg.KeepFreePercent/100)
+
+	if margin.Available < reserve*s.cfg.RecallBelow && earn.Amount > 0 {
+		s.mu.Lock()
+		s.idleSince = time.Time{}
+		s.mu.Unlock()
+
+		amount := floorCents(math.Min(reserve-margin.Available, earn.Amount))
+		if amount <= 0 {
+			return nil
+		}
+		if err := s.account.Recall(ctx, s.cfg.Asset, amount); err != nil {
+			return s.fail(err)
+		}
+		s.record(func(st *Stats) {
+			st.Recalled += amount
+			st.Recalls++
+			st.InEarn -= amount
+		})
+		return nil
+	}
+
+	excess := margin.Available - reserve
+	now := s.now()
+
+	s.mu.Lock()
+	if excess < s.cfg.MinSweep {
+		s.idleSince = time.Time{}
+		s.mu.Unlock()
+		return nil
+	}
+	if s.idleSince.IsZero() {
+		s.idleSince = now
+	}
+	idle := now.Sub(s.idleSince)
+	s.mu.Unlock()
+
+	if idle < s.cfg.IdleFor {
+		return nil
+	}
+
+	amount := floorCents(excess)
+	if err := s.account.Sweep(ctx, s.cfg.Asset, amount); err != nil {
+		return s.fail(err)
+	}
+	s.record(func(st *Stats) {
+		st.Swept += amount
+		st.Sweeps++
+		st.InEarn += amount
+	})
+
+	s.mu.Lock()
+	s.idleSince = time.Time{}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Sweeper) record(fn func(*Stats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.stats)
+	s.stats.LastAction = s.now()
+	s.stats.LastError = ""
+}
+
+func (s *Sweeper) fail(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.LastError = err.Error()
+	return err
+}
+
+func floorCents(v float64) float64 {
+	return math.Floor(v*100) / 100
+}
@@ -0,0 +1,62 @@
+package earnsweep
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeAccount struct {
+	margin Margin
+	earn   EarnPosition
+}
+
+func (a *fakeAccount) FuturesMargin(context.Context, string) (Margin, error) { return a.margin, nil }
+
+func (a *fakeAccount) EarnPosition(context.Context, string) (EarnPosition, error) { return a.earn, nil }
+
+func (a *fakeAccount) Sweep(_ context.Context, _ string, amount float64) error {
+	a.margin.Wallet -= amount
+	a.margin.Available -= amount
+	a.earn.Amount += amount
+	return nil
+}
+
+func (a *fakeAccount) Recall(_ context.Context, _ string, amount float64) error {
+	a.margin.Wallet += amount
+	a.margin.Available += amount
+	a.earn.Amount -= amount
+	return nil
+}
+
+func TestSweepsPersistentIdleMarginAndRecallsOnUsage(t *testing.T) {
+	acct := &fakeAccount{margin: Margin{Wallet: 1000, Available: 900}}
+	s := New(Config{KeepFreePercent: 50, MinSweep: 50, IdleFor: time.Hour}, acct)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.Check(ctx)
+	if acct.earn.Amount != 0 {
+		t.Fatal("swept before margin was idle for IdleFor")
+	}
+
+	now = now.Add(2 * time.Hour)
+	s.Check(ctx)
+	// Reserve is half of the 1000 total; the 400 above it is swept.
+	if acct.earn.Amount != 400 || acct.margin.Available != 500 {
+		t.Fatalf("earn=%v available=%v, want 400/500", acct.earn.Amount, acct.margin.Available)
+	}
+
+	// Positions open and take 300 of margin.
+	acct.margin.Available -= 300
+	s.Check(ctx)
+	if acct.margin.Available != 500 || acct.earn.Amount != 100 {
+		t.Fatalf("earn=%v available=%v, want recall back to the 500 reserve", acct.earn.Amount, acct.margin.Available)
+	}
+
+	st := s.Stats()
+	if st.Sweeps != 1 || st.Recalls != 1 || st.Swept != 400 || st.Recalled != 300 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
@@ -0,0 +1,265 @@
+package equity
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/pkg/callpolicy"
+)
+
+type Position struct {
+	Symbol        string  `json:"symbol"`
+	Side          string  `json:"side"`
+	Size          float64 `json:"size"`
+	EntryPrice    float64 `json:"entry_price"`
+	Notional      float64 `json:"notional"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Leverage      float64 `json:"leverage,omitempty"`
+}
+
+// Snapshot is the account at one point in time. Equity is the wallet
+// balance plus unrealized PnL; MarginUsage is initial margin over equity,
+// in percent.
+type Snapshot struct {
+	Time          time.Time  `json:"ts"`
+	Balance       float64    `json:"balance"`
+	Equity        float64    `json:"equity"`
+	UnrealizedPnL float64    `json:"unrealized_pnl"`
+	InitialMargin float64    `json:"initial_margin"`
+	MaintMargin   float64    `json:"maint_margin"`
+	Available     float64    `json:"available"`
+	MarginUsage   float64    `json:"margin_usage_pct"`
+	Positions     []Position `json:"positions"`
+	Namespace     string     `json:"namespace,omitempty"`
+}
+
+// Source reads the current account state.
+type Source interface {
+	Snapshot(ctx context.Context) (Snapshot, error)
+}
+
+type Config struct {
+	Path     string
+	Interval time.Duration
+	Calls    *callpolicy.Policy
+	// Namespace labels every snapshot written.
+	Namespace string
+}
+
+type Stats struct {
+	Snapshots int
+	Failures  int
+	LastError string
+}
+
+// Recorder appends a snapshot of the account to a JSON-lines journal every
+// Interval, so the equity curve and drawdowns come from recorded history
+// rather than being rebuilt from closed trades.
+type Recorder struct {
+	cfg    Config
+	source Source
+	stopCh chan struct{}
+
+	mu      sync.RWMutex
+	file    *os.File
+	latest  Snapshot
+	stats   Stats
+	running bool
+}
+
+func New(cfg Config, source Source) (*Recorder, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{cfg: cfg, source: source, file: f, stopCh: make(chan struct{})}, nil
+}
+
+func (r *Recorder) Path() string {
+	return r.cfg.Path
+}
+
+func (r *Recorder) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return nil
+	}
+	r.running = true
+	go r.run(ctx)
+	return nil
+}
+
+// Stop ends the snapshot loop and closes the journal.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		close(r.stopCh)
+		r.running = false
+	}
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *Recorder) run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.Record(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Record takes a snapshot now and appends it to the journal.
+func (r *Recorder) Record(ctx context.Context) (Snapshot, error) {
+	callCtx, cancel := r.cfg.Calls.Context(ctx, callpolicy.Account)
+	s, err := r.source.Snapshot(callCtx)
+	cancel()
+	if err != nil {
+		r.fail(err)
+		return Snapshot{}, err
+	}
+
+	if s.Time.IsZero() {
+		s.Time = time.Now()
+	}
+	if s.MarginUsage == 0 && s.Equity > 0 {
+		s.MarginUsage = s.InitialMargin / s.Equity * 100
+	}
+	if s.Namespace == "" {
+		s.Namespace = r.cfg.Namespace
+	}
+	line, err := json.Marshal(s)
+	if err != nil {
+		r.fail(err)
+		return s, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return s, os.ErrClosed
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		r.stats.Failures++
+		r.stats.LastError = err.Error()
+		return s, err
+	}
+	r.latest = s
+	r.stats.Snapshots++
+	return s, nil
+}
+
+func (r *Recorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.Failures++
+	r.stats.LastError = err.Error()
+}
+
+// Latest returns the most recent snapshot recorded by this process.
+func (r *Recorder) Latest() (Snapshot, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.latest, !r.latest.Time.IsZero()
+}
+
+func (r *Recorder) Stats() Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.stats
+}
+
+// Read returns the snapshots in the journal at path taken at or after since,
+// oldest first. Lines that do not parse are skipped.
+func Read(path string, since time.Time) ([]Snapshot, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var s Snapshot
+		if json.Unmarshal(scanner.Bytes(), &s) != nil || s.Time.Before(since) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, scanner.Err()
+}
+
+// Point is one step of the equity curve. Drawdown is the fall from the
+// running peak, in percent of the peak.
+type Point struct {
+	Time          time.Time `json:"ts"`
+	Equity        float64   `json:"equity"`
+	UnrealizedPnL float64   `json:"unrealized_pnl"`
+	MarginUsage   float64   `json:"margin_usage_pct"`
+	Peak          float64   `json:"peak"`
+	Drawdown      float64   `json:"drawdown_pct"`
+}
+
+type Curve struct {
+	Points          []Point `json:"points"`
+	Peak            float64 `json:"peak"`
+	MaxDrawdown     float64 `json:"max_drawdown_pct"`
+	CurrentDrawdown float64 `json:"current_drawdown_pct"`
+}
+
+// BuildCurve turns snapshots, oldest first, into an equity curve with
+// running drawdowns.
+func BuildCurve(snaps []Snapshot) Curve {
+	c := Curve{Points: make([]Point, 0, len(snaps))}
+	for _, s := range snaps {
+		if s.Equity > c.Peak {
+			c.Peak = s.Equity
+		}
+		p := Point{
+			Time:          s.Time,
+			Equity:        s.Equity,
+			UnrealizedPnL: s.UnrealizedPnL,
+			MarginUsage:   s.MarginUsage,
+			Peak:          c.Peak,
+		}
+		if c.Peak > 0 {
+			p.Drawdown = (c.Peak - s.Equity) / c.Peak * 100
+		}
+		if p.Drawdown > c.MaxDrawdown {
+			c.MaxDrawdown = p.Drawdown
+		}
+		c.CurrentDrawdown = p.Drawdown
+		c.Points = append(c.Points, p)
+	}
+	return c
+}
@@ -0,0 +1,69 @@
+package equity
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	snaps []Snapshot
+	err   error
+}
+
+func (f *fakeSource) Snapshot(ctx context.Context) (Snapshot, error) {
+	if f.err != nil {
+		return Snapshot{}, f.err
+	}
+	s := f.snaps[0]
+	f.snaps = f.snaps[1:]
+	return s, nil
+}
+
+func TestRecordReadAndCurve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "equity.jsonl")
+	start := time.Now().Add(-time.Hour)
+	src := &fakeSource{snaps: []Snapshot{
+		{Time: start, Equity: 1000, InitialMargin: 100},
+		{Time: start.Add(time.Minute), Equity: 1100, Positions: []Position{{Symbol: "BTCUSDT", Side: "LONG", Size: 0.01}}},
+		{Time: start.Add(2 * time.Minute), Equity: 880},
+		{Time: start.Add(3 * time.Minute), Equity: 990},
+	}}
+	r, err := New(Config{Path: path}, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range src.snaps {
+		if _, err := r.Record(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src.err = errors.New("account unavailable")
+	if _, err := r.Record(context.Background()); err == nil {
+		t.Fatal("expected source error")
+	}
+	if st := r.Stats(); st.Snapshots != 4 || st.Failures != 1 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+	if latest, ok := r.Latest(); !ok || latest.Equity != 990 {
+		t.Errorf("latest = %+v, %v", latest, ok)
+	}
+	r.Stop()
+
+	snaps, err := Read(path, start.Add(30*time.Second))
+	if err != nil || len(snaps) != 3 || len(snaps[0].Positions) != 1 {
+		t.Fatalf("read %d snapshots, %v", len(snaps), err)
+	}
+	all, _ := Read(path, time.Time{})
+	if all[0].MarginUsage != 10 {
+		t.Errorf("margin usage = %v, want 10", all[0].MarginUsage)
+	}
+
+	c := BuildCurve(all)
+	if c.Peak != 1100 || math.Abs(c.MaxDrawdown-20) > 1e-9 || math.Abs(c.CurrentDrawdown-10) > 1e-9 {
+		t.Errorf("unexpected curve peak %v max %v current %v", c.Peak, c.MaxDrawdown, c.CurrentDrawdown)
+	}
+}
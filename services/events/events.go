@@ -0,0 +1,226 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/pkg/intent"
+)
+
+// Event types. Each is published on its own subject, suffixed with the
+// schema version, e.g. gobot.decision.v1. A breaking change to a payload
+// bumps its version and is published on the new subject.
+const (
+	TypeScreenerRefresh = "screener.refresh"
+	TypeDecision        = "decision"
+	TypeExecution       = "execution"
+	TypeIntent          = intent.EventType
+)
+
+// Version is the schema version of every payload below.
+const Version = 1
+
+// Envelope wraps every payload. Consumers dispatch on Type and Version.
+type Envelope struct {
+	Type      string          `json:"type"`
+	Version   int             `json:"version"`
+	Time      time.Time       `json:"ts"`
+	Instance  string          `json:"instance,omitempty"`
+	Namespace string          `json:"namespace,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// ScreenerRefresh is the screener.refresh v1 payload.
+type ScreenerRefresh struct {
+	Active []string `json:"active"`
+	Pairs  []Pair   `json:"pairs"`
+}
+
+type Pair struct {
+	Symbol         string  `json:"symbol"`
+	Volume24h      float64 `json:"volume_24h"`
+	PriceChangePct float64 `json:"price_change_pct"`
+	OIChangePct    float64 `json:"oi_change_pct"`
+	Breakout       bool    `json:"breakout"`
+	BreakoutLevel  float64 `json:"breakout_level,omitempty"`
+	SqueezeRisk    bool    `json:"squeeze_risk"`
+	Universe       string  `json:"universe,omitempty"`
+}
+
+// Execution is the execution v1 payload, one per entry that reached the book.
+type Execution struct {
+	Symbol     string             `json:"symbol"`
+	Side       string             `json:"side"`
+	Quantity   float64            `json:"quantity"`
+	EntryPrice float64            `json:"entry_price"`
+	StopLoss   float64            `json:"stop_loss"`
+	TakeProfit float64            `json:"take_profit"`
+	Confidence float64            `json:"confidence"`
+	Source     string             `json:"source,omitempty"`
+	Components map[string]float64 `json:"components,omitempty"`
+}
+
+// The decision v1 payload is a decisionlog.Record, and the intent v1 payload
+// an intent.Event.
+
+// Publisher delivers an encoded envelope on subject.
+type Publisher interface {
+	Publish(ctx context.Context, subject string, payload []byte) error
+	Close() error
+}
+
+type Config struct {
+	// Prefix is prepended to every subject, "gobot" by default.
+	Prefix   string
+	Instance string
+	// Namespace names the bot, which Instance alone does not when a
+	// standby takes over or several bots share a broker.
+	Namespace string
+	// Buffer is how many events may wait for the publisher; beyond that new
+	// events are dropped so a slow broker never stalls trading.
+	Buffer  int
+	Timeout time.Duration
+}
+
+type Stats struct {
+	Published int
+	Dropped   int
+	Failed    int
+}
+
+type message struct {
+	subject string
+	payload []byte
+}
+
+// Stream publishes events in the background.
+type Stream struct {
+	cfg   Config
+	pub   Publisher
+	queue chan message
+	done  chan struct{}
+
+	mu      sync.Mutex
+	stats   Stats
+	started bool
+	closed  bool
+}
+
+func New(cfg Config, pub Publisher) *Stream {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "gobot"
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1024
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	return &Stream{
+		cfg:   cfg,
+		pub:   pub,
+		queue: make(chan message, cfg.Buffer),
+		done:  make(chan struct{}),
+	}
+}
+
+// Subject returns the subject events of type are published on.
+func (s *Stream) Subject(eventType string) string {
+	return fmt.Sprintf("%s.%s.v%d", s.cfg.Prefix, eventType, Version)
+}
+
+// Publish queues data as an event of eventType. It never blocks; a nil
+// stream discards the event.
+func (s *Stream) Publish(eventType string, data interface{}) {
+	if s == nil {
+		return
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		s.count(func(st *Stats) { st.Failed++ })
+		return
+	}
+	payload, err := json.Marshal(Envelope{
+		Type:      eventType,
+		Version:   Version,
+		Time:      time.Now(),
+		Instance:  s.cfg.Instance,
+		Namespace: s.cfg.Namespace,
+		Data:      raw,
+	})
+	if err != nil {
+		s.count(func(st *Stats) { st.Failed++ })
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- message{subject: s.Subject(eventType), payload: payload}:
+	default:
+		s.stats.Dropped++
+	}
+}
+
+// Start runs the publishing loop until Close.
+func (s *Stream) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return nil
+	}
+	s.started = true
+	go s.run()
+	return nil
+}
+
+func (s *Stream) run() {
+	defer close(s.done)
+	for m := range s.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+		err := s.pub.Publish(ctx, m.subject, m.payload)
+		cancel()
+		if err != nil {
+			s.count(func(st *Stats) { st.Failed++ })
+			continue
+		}
+		s.count(func(st *Stats) { st.Published++ })
+	}
+}
+
+// Close stops accepting events, publishes what is queued and closes the
+// publisher.
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	started := s.started
+	close(s.queue)
+	s.mu.Unlock()
+
+	if started {
+		<-s.done
+	}
+	return s.pub.Close()
+}
+
+func (s *Stream) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+func (s *Stream) count(fn func(*Stats)) {
+	s.mu.Lock()
+	fn(&s.stats)
+	s.mu.Unlock()
+}
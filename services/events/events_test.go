@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+type recorder struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+	closed   bool
+}
+
+func (r *recorder) Publish(ctx context.Context, subject string, payload []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subjects = append(r.subjects, subject)
+	r.payloads = append(r.payloads, payload)
+	return nil
+}
+
+func (r *recorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestStreamPublishesVersionedEnvelopes(t *testing.T) {
+	pub := &recorder{}
+	s := New(Config{Instance: "host-a", Buffer: 2}, pub)
+
+	// Not started yet, so the third event overflows the buffer.
+	s.Publish(TypeExecution, Execution{Symbol: "BTCUSDT", Side: "BUY", Quantity: 0.01})
+	s.Publish(TypeScreenerRefresh, ScreenerRefresh{Active: []string{"BTCUSDT"}})
+	s.Publish(TypeDecision, map[string]string{"symbol": "ETHUSDT"})
+
+	s.Start(context.Background())
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.Stats(); got.Published != 2 || got.Dropped != 1 {
+		t.Errorf("unexpected stats %+v", got)
+	}
+	if !pub.closed || len(pub.subjects) != 2 || pub.subjects[0] != "gobot.execution.v1" || pub.subjects[1] != "gobot.screener.refresh.v1" {
+		t.Fatalf("unexpected subjects %v", pub.subjects)
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(pub.payloads[0], &env); err != nil {
+		t.Fatal(err)
+	}
+	var exec Execution
+	json.Unmarshal(env.Data, &exec)
+	if env.Type != TypeExecution || env.Version != Version || env.Instance != "host-a" || exec.Symbol != "BTCUSDT" {
+		t.Errorf("unexpected envelope %+v with %+v", env, exec)
+	}
+
+	s.Publish(TypeDecision, nil) // after Close: ignored, must not panic
+	var nilStream *Stream
+	nilStream.Publish(TypeDecision, nil)
+}
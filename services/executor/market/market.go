@@ -2,6 +2,7 @@ package market
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/britej3/gobot/domain/executor"
@@ -9,6 +10,8 @@ import (
 	"github.com/britej3/gobot/domain/trade"
 )
 
+var ErrNoExchange = errors.New("market executor has no exchange client")
+
 type MarketExecutor struct {
 	cfg    executor.ExecutionConfig
 	client ExchangeClient
@@ -26,6 +29,10 @@ func NewMarketExecutor() *MarketExecutor {
 	return &MarketExecutor{}
 }
 
+func NewMarketExecutorWithClient(client ExchangeClient) *MarketExecutor {
+	return &MarketExecutor{client: client}
+}
+
 func (e *MarketExecutor) Type() executor.ExecutionType {
 	return executor.ExecutionMarket
 }
@@ -36,10 +43,13 @@ func (e *MarketExecutor) Name() string {
 
 func (e *MarketExecutor) Configure(config executor.ExecutionConfig) error {
 	e.cfg = config
-	return nil
+	return e.Validate()
 }
 
 func (e *MarketExecutor) Validate() error {
+	if e.client == nil {
+		return ErrNoExchange
+	}
 	return nil
 }
 
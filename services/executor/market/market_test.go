@@ -0,0 +1,35 @@
+package market
+
+import (
+	"context"
+	"testing"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/testkit"
+)
+
+func TestMarketExecutor_ExecutesTheSignal(t *testing.T) {
+	sc := testkit.TrendDay("TRENDUSDT")
+	market := sc.Market(len(sc.Klines) - 1)
+	exchange := &testkit.Exchange{Mark: market.CurrentPrice}
+	ex := NewMarketExecutorWithClient(exchange)
+
+	order := testkit.AssertExecutes(t, ex, strategy.StrategyResult{
+		ShouldEnter:  true,
+		PositionSize: 2,
+		StopLoss:     market.CurrentPrice * 0.99,
+		TakeProfit:   market.CurrentPrice * 1.02,
+	}, market)
+	if order.Side != trade.SideBuy || order.Type != trade.OrderTypeMarket {
+		t.Errorf("order %s %s", order.Side, order.Type)
+	}
+
+	pos := &trade.Position{Symbol: market.Symbol, Side: trade.SideBuy, Quantity: 2}
+	if err := ex.ClosePosition(context.Background(), pos, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if orders, closed := exchange.Orders(), exchange.Closed(); len(orders) != 1 || len(closed) != 1 {
+		t.Errorf("%d orders and %d closes reached the exchange", len(orders), len(closed))
+	}
+}
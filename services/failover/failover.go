@@ -0,0 +1,271 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type Role string
+
+const (
+	RoleStandby Role = "standby"
+	RoleLeader  Role = "leader"
+)
+
+var ErrNoInstanceID = errors.New("failover instance id is required")
+
+// Lock is a lease held by at most one instance. Acquire and Renew report
+// whether id holds the lease afterwards; Renew fails once another instance
+// has taken it over.
+type Lock interface {
+	Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	Renew(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, id string) error
+}
+
+// SnapshotStore carries the leader's state to the standby.
+type SnapshotStore interface {
+	Put(ctx context.Context, data []byte) error
+	Get(ctx context.Context) ([]byte, error)
+}
+
+// State is the replicated trading state.
+type State interface {
+	Snapshot() ([]byte, error)
+	Restore(data []byte) error
+}
+
+// Config times the lease. Heartbeat must be well below LeaseTTL: a standby
+// takes over at most LeaseTTL plus one heartbeat after the leader stops.
+type Config struct {
+	InstanceID string
+	LeaseTTL   time.Duration
+	Heartbeat  time.Duration
+}
+
+type Status struct {
+	InstanceID  string
+	Role        Role
+	Since       time.Time
+	LastSync    time.Time
+	SyncErrors  int
+	Promotions  int
+	LastLockErr string
+}
+
+type Elector struct {
+	cfg     Config
+	lock    Lock
+	store   SnapshotStore
+	state   State
+	mu      sync.RWMutex
+	running bool
+	status  Status
+	promote []func(ctx context.Context)
+	demote  []func()
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+func New(cfg Config, lock Lock, store SnapshotStore, state State) (*Elector, error) {
+	if cfg.InstanceID == "" {
+		return nil, ErrNoInstanceID
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = 10 * time.Second
+	}
+	if cfg.Heartbeat <= 0 || cfg.Heartbeat >= cfg.LeaseTTL {
+		cfg.Heartbeat = cfg.LeaseTTL / 4
+	}
+
+	return &Elector{
+		cfg:    cfg,
+		lock:   lock,
+		store:  store,
+		state:  state,
+		status: Status{InstanceID: cfg.InstanceID, Role: RoleStandby, Since: time.Now()},
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}, nil
+}
+
+// OnPromote registers fn to run when this instance becomes leader. The
+// context is cancelled when leadership is lost.
+func (e *Elector) OnPromote(fn func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.promote = append(e.promote, fn)
+}
+
+// OnDemote registers fn to run when this instance loses leadership.
+func (e *Elector) OnDemote(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.demote = append(e.demote, fn)
+}
+
+func (e *Elector) Start(ctx context.Context) error {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return nil
+	}
+	e.running = true
+	e.mu.Unlock()
+
+	go e.run(ctx)
+	return nil
+}
+
+// Stop releases the lease if held so the standby can take over without
+// waiting for it to expire.
+func (e *Elector) Stop() error {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return nil
+	}
+	e.running = false
+	close(e.stopCh)
+	e.mu.Unlock()
+
+	<-e.doneCh
+	return nil
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.status.Role == RoleLeader
+}
+
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.status
+}
+
+func (e *Elector) run(ctx context.Context) {
+	defer close(e.doneCh)
+
+	ticker := time.NewTicker(e.cfg.Heartbeat)
+	defer ticker.Stop()
+
+	var cancelTerm context.CancelFunc
+	defer func() {
+		if cancelTerm != nil {
+			e.stepDown(cancelTerm)
+			e.lock.Release(context.Background(), e.cfg.InstanceID)
+		}
+	}()
+
+	for {
+		cancelTerm = e.tick(ctx, cancelTerm)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick runs one heartbeat: the leader renews its lease and publishes a
+// snapshot, the standby pulls the latest snapshot and tries to take the
+// lease. It returns the cancel func of the current leadership term, nil
+// while standing by.
+func (e *Elector) tick(ctx context.Context, cancelTerm context.CancelFunc) context.CancelFunc {
+	if cancelTerm != nil {
+		held, err := e.lock.Renew(ctx, e.cfg.InstanceID, e.cfg.LeaseTTL)
+		e.recordLockErr(err)
+		if err != nil || !held {
+			e.stepDown(cancelTerm)
+			return nil
+		}
+		e.sync(ctx, true)
+		return cancelTerm
+	}
+
+	e.sync(ctx, false)
+
+	acquired, err := e.lock.Acquire(ctx, e.cfg.InstanceID, e.cfg.LeaseTTL)
+	e.recordLockErr(err)
+	if err != nil || !acquired {
+		return nil
+	}
+
+	// Pull once more now that the old leader can no longer write.
+	e.sync(ctx, false)
+	return e.stepUp(ctx)
+}
+
+func (e *Elector) stepUp(ctx context.Context) context.CancelFunc {
+	termCtx, cancel := context.WithCancel(ctx)
+
+	e.mu.Lock()
+	e.status.Role = RoleLeader
+	e.status.Since = time.Now()
+	e.status.Promotions++
+	handlers := append([]func(context.Context){}, e.promote...)
+	e.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(termCtx)
+	}
+	return cancel
+}
+
+func (e *Elector) stepDown(cancel context.CancelFunc) {
+	cancel()
+
+	e.mu.Lock()
+	e.status.Role = RoleStandby
+	e.status.Since = time.Now()
+	handlers := append([]func(){}, e.demote...)
+	e.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn()
+	}
+}
+
+func (e *Elector) sync(ctx context.Context, leader bool) {
+	if e.store == nil || e.state == nil {
+		return
+	}
+
+	var err error
+	if leader {
+		var data []byte
+		if data, err = e.state.Snapshot(); err == nil {
+			err = e.store.Put(ctx, data)
+		}
+	} else {
+		var data []byte
+		if data, err = e.store.Get(ctx); err == nil && len(data) > 0 {
+			err = e.state.Restore(data)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		e.status.SyncErrors++
+		return
+	}
+	e.status.LastSync = time.Now()
+}
+
+func (e *Elector) recordLockErr(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		e.status.LastLockErr = err.Error()
+	} else {
+		e.status.LastLockErr = ""
+	}
+}
@@ -0,0 +1,74 @@
+package failover
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type memState struct {
+	data string
+}
+
+func (s *memState) Snapshot() ([]byte, error) { return []byte(s.data), nil }
+
+func (s *memState) Restore(data []byte) error {
+	s.data = string(data)
+	return nil
+}
+
+func TestStandbyTakesOverWithLeaderState(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	ttl := 50 * time.Millisecond
+
+	newElector := func(id string, st State) *Elector {
+		e, err := New(Config{InstanceID: id, LeaseTTL: ttl},
+			NewFileLock(filepath.Join(dir, "leader.lock")),
+			NewFileSnapshotStore(filepath.Join(dir, "state.json")), st)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	primaryState := &memState{data: "positions:BTCUSDT"}
+	standbyState := &memState{}
+	primary := newElector("primary", primaryState)
+	standby := newElector("standby", standbyState)
+
+	var promotedCtx context.Context
+	standby.OnPromote(func(ctx context.Context) { promotedCtx = ctx })
+
+	primaryTerm := primary.tick(ctx, nil)
+	if primaryTerm == nil || !primary.IsLeader() {
+		t.Fatal("first instance should take the free lease")
+	}
+	primaryTerm = primary.tick(ctx, primaryTerm)
+
+	if term := standby.tick(ctx, nil); term != nil || standby.IsLeader() {
+		t.Fatal("standby took a lease that is still held")
+	}
+	if standbyState.data != primaryState.data {
+		t.Fatalf("standby state = %q, want leader's %q", standbyState.data, primaryState.data)
+	}
+
+	// Primary stops heartbeating; the lease lapses.
+	time.Sleep(2 * ttl)
+	standbyTerm := standby.tick(ctx, nil)
+	if standbyTerm == nil || !standby.IsLeader() || promotedCtx == nil {
+		t.Fatal("standby should take over once the lease expires")
+	}
+
+	demoted := false
+	primary.OnDemote(func() { demoted = true })
+	if term := primary.tick(ctx, primaryTerm); term != nil || !demoted || primary.IsLeader() {
+		t.Fatal("old primary should step down once its lease is taken")
+	}
+
+	standby.stepDown(standbyTerm)
+	if promotedCtx.Err() == nil {
+		t.Fatal("leadership context should be cancelled on step down")
+	}
+}
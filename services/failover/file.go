@@ -0,0 +1,130 @@
+package failover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileLock is a lease kept in a file on storage both instances can reach,
+// such as an NFS mount. Writes go through a rename so readers never see a
+// partial lease, but two instances racing for an expired lease can both
+// succeed within the same instant; use RedisLock where that matters.
+type FileLock struct {
+	path string
+}
+
+type fileLease struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+func (l *FileLock) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	lease, err := l.read()
+	if err != nil {
+		return false, err
+	}
+	if lease.Holder != "" && lease.Holder != id && time.Now().Before(lease.ExpiresAt) {
+		return false, nil
+	}
+	if err := l.write(fileLease{Holder: id, ExpiresAt: time.Now().Add(ttl)}); err != nil {
+		return false, err
+	}
+
+	// Re-read to lose gracefully if another instance wrote after us.
+	lease, err = l.read()
+	return err == nil && lease.Holder == id, err
+}
+
+func (l *FileLock) Renew(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	lease, err := l.read()
+	if err != nil {
+		return false, err
+	}
+	if lease.Holder != id {
+		return false, nil
+	}
+	return true, l.write(fileLease{Holder: id, ExpiresAt: time.Now().Add(ttl)})
+}
+
+func (l *FileLock) Release(ctx context.Context, id string) error {
+	lease, err := l.read()
+	if err != nil || lease.Holder != id {
+		return err
+	}
+	return l.write(fileLease{})
+}
+
+// Holder returns the instance holding an unexpired lease, or "".
+func (l *FileLock) Holder(ctx context.Context) (string, error) {
+	lease, err := l.read()
+	if err != nil || !time.Now().Before(lease.ExpiresAt) {
+		return "", err
+	}
+	return lease.Holder, nil
+}
+
+func (l *FileLock) read() (fileLease, error) {
+	var lease fileLease
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return lease, nil
+	}
+	if err != nil {
+		return lease, fmt.Errorf("failed to read lease: %w", err)
+	}
+	if len(data) == 0 {
+		return lease, nil
+	}
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return lease, fmt.Errorf("failed to parse lease: %w", err)
+	}
+	return lease, nil
+}
+
+func (l *FileLock) write(lease fileLease) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(l.path, data)
+}
+
+// FileSnapshotStore keeps the leader's state snapshot next to the lease.
+type FileSnapshotStore struct {
+	path string
+}
+
+func NewFileSnapshotStore(path string) *FileSnapshotStore {
+	return &FileSnapshotStore{path: path}
+}
+
+func (s *FileSnapshotStore) Put(ctx context.Context, data []byte) error {
+	return writeAtomic(s.path, data)
+}
+
+func (s *FileSnapshotStore) Get(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
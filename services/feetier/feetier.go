@@ -0,0 +1,222 @@
+// Package feetier tracks what the account actually pays to trade. Binance
+// sets maker and taker commission by VIP tier, and the tier moves with
+// volume and BNB holdings, so a fee hard-coded in the config drifts from the
+// truth: the EV gate and the cost model then either pass trades whose edge
+// the fees eat or refuse ones that would have paid.
+//
+// The tracker reads the tier and each symbol's rates at start and on an
+// interval. Until a rate has been read the configured default stands in.
+package feetier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Rate is a commission per side as a fraction of notional.
+type Rate struct {
+	Maker float64 `json:"maker"`
+	Taker float64 `json:"taker"`
+}
+
+// Source reads the account's fee tier and its rates for a symbol.
+type Source interface {
+	Tier(ctx context.Context) (int, error)
+	Rate(ctx context.Context, symbol string) (Rate, error)
+}
+
+type Config struct {
+	// Interval between refreshes. Default 1h.
+	Interval time.Duration
+	// Symbols whose rates are read. Others get the rate of the first
+	// symbol read, which is the account's standard rate.
+	Symbols []string
+	// Default is used until the first rate has been read.
+	Default Rate
+}
+
+// Change is passed to OnChange handlers when the tier moves.
+type Change struct {
+	From int
+	To   int
+	Rate Rate
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("Fee tier changed from VIP %d to VIP %d: maker %.4f%%, taker %.4f%%", c.From, c.To, c.Rate.Maker*100, c.Rate.Taker*100)
+}
+
+// Status is what the tracker last read.
+type Status struct {
+	Known       bool            `json:"known"`
+	Tier        int             `json:"tier"`
+	Rate        Rate            `json:"rate"`
+	Symbols     map[string]Rate `json:"symbols,omitempty"`
+	LastRefresh time.Time       `json:"last_refresh,omitempty"`
+	Refreshes   int             `json:"refreshes"`
+	Failures    int             `json:"failures"`
+}
+
+type Tracker struct {
+	cfg      Config
+	source   Source
+	mu       sync.RWMutex
+	running  bool
+	known    bool
+	tier     int
+	standard Rate
+	rates    map[string]Rate
+	status   Status
+	handlers []func(Change)
+	stopCh   chan struct{}
+	now      func() time.Time
+}
+
+func New(cfg Config, source Source) *Tracker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	return &Tracker{
+		cfg:      cfg,
+		source:   source,
+		standard: cfg.Default,
+		rates:    make(map[string]Rate),
+		stopCh:   make(chan struct{}),
+		now:      time.Now,
+	}
+}
+
+// OnChange registers fn for every change of tier after the first read.
+// Handlers run on the refreshing goroutine and must not block.
+func (t *Tracker) OnChange(fn func(Change)) {
+	t.mu.Lock()
+	t.handlers = append(t.handlers, fn)
+	t.mu.Unlock()
+}
+
+func (t *Tracker) Start(ctx context.Context) error {
+	t.mu.Lock()
+	if t.running {
+		t.mu.Unlock()
+		return nil
+	}
+	t.running = true
+	t.mu.Unlock()
+
+	t.Refresh(ctx)
+	go t.run(ctx)
+	return nil
+}
+
+func (t *Tracker) Stop() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.running {
+		return nil
+	}
+	t.running = false
+	close(t.stopCh)
+	return nil
+}
+
+func (t *Tracker) run(ctx context.Context) {
+	ticker := time.NewTicker(t.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+			t.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh reads the tier and the rates of every configured symbol. Rates
+// that could not be read keep their last value; the first error is
+// returned.
+func (t *Tracker) Refresh(ctx context.Context) error {
+	tier, err := t.source.Tier(ctx)
+	rates := make(map[string]Rate, len(t.cfg.Symbols))
+	for _, symbol := range t.cfg.Symbols {
+		if err != nil {
+			break
+		}
+		var r Rate
+		if r, err = t.source.Rate(ctx, symbol); err == nil {
+			rates[symbol] = r
+		}
+	}
+
+	t.mu.Lock()
+	t.status.Refreshes++
+	t.status.LastRefresh = t.now()
+	for symbol, r := range rates {
+		t.rates[symbol] = r
+	}
+	if len(t.cfg.Symbols) > 0 {
+		if r, ok := t.rates[t.cfg.Symbols[0]]; ok {
+			t.standard = r
+		}
+	}
+	if err != nil {
+		t.status.Failures++
+		t.mu.Unlock()
+		return err
+	}
+
+	var changes []Change
+	if t.known && tier != t.tier {
+		changes = append(changes, Change{From: t.tier, To: tier, Rate: t.standard})
+	}
+	t.known, t.tier = true, tier
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	for _, c := range changes {
+		for _, fn := range handlers {
+			fn(c)
+		}
+	}
+	return nil
+}
+
+// Rate returns the commission for symbol: its own rate once read, else the
+// account's standard rate, else the default.
+func (t *Tracker) Rate(symbol string) Rate {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if r, ok := t.rates[symbol]; ok {
+		return r
+	}
+	return t.standard
+}
+
+// PerSide returns the average fee per side of a round trip on symbol that
+// exits at taker, entering at maker when passive.
+func (t *Tracker) PerSide(symbol string, passive bool) float64 {
+	r := t.Rate(symbol)
+	entry := r.Taker
+	if passive {
+		entry = r.Maker
+	}
+	return (entry + r.Taker) / 2
+}
+
+func (t *Tracker) Status() Status {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	st := t.status
+	st.Known, st.Tier, st.Rate = t.known, t.tier, t.standard
+	st.Symbols = make(map[string]Rate, len(t.rates))
+	for symbol, r := range t.rates {
+		st.Symbols[symbol] = r
+	}
+	return st
+}
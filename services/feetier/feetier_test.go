@@ -0,0 +1,70 @@
+package feetier
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeSource struct {
+	tier  int
+	rates map[string]Rate
+	err   error
+}
+
+func (s *fakeSource) Tier(context.Context) (int, error) { return s.tier, s.err }
+
+func (s *fakeSource) Rate(_ context.Context, symbol string) (Rate, error) {
+	return s.rates[symbol], s.err
+}
+
+func TestTracker_UsesLiveRatesAndAlertsTierChange(t *testing.T) {
+	src := &fakeSource{rates: map[string]Rate{
+		"BTCUSDT": {Maker: 0.0002, Taker: 0.0005},
+		"ETHUSDT": {Maker: 0.0001, Taker: 0.0004},
+	}}
+	tr := New(Config{Symbols: []string{"BTCUSDT", "ETHUSDT"}, Default: Rate{Taker: 0.0004}}, src)
+	var changes []Change
+	tr.OnChange(func(c Change) { changes = append(changes, c) })
+
+	if got := tr.Rate("BTCUSDT").Taker; got != 0.0004 {
+		t.Fatalf("taker before first read = %v, want the default", got)
+	}
+	if err := tr.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := tr.Rate("ETHUSDT"); got.Taker != 0.0004 || got.Maker != 0.0001 {
+		t.Errorf("ETHUSDT rate = %+v", got)
+	}
+	if got := tr.Rate("SOLUSDT").Taker; got != 0.0005 {
+		t.Errorf("unread symbol taker = %v, want the account's standard rate", got)
+	}
+	if got := tr.PerSide("BTCUSDT", true); got != 0.00035 {
+		t.Errorf("passive per side = %v, want maker in and taker out", got)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("first read alerted: %v", changes)
+	}
+
+	src.tier = 1
+	src.rates["BTCUSDT"] = Rate{Maker: 0.00016, Taker: 0.0004}
+	tr.Refresh(context.Background())
+	if len(changes) != 1 || changes[0].From != 0 || changes[0].To != 1 || changes[0].Rate.Taker != 0.0004 {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestTracker_KeepsRatesWhenARefreshFails(t *testing.T) {
+	src := &fakeSource{tier: 2, rates: map[string]Rate{"BTCUSDT": {Maker: 0.00014, Taker: 0.00035}}}
+	tr := New(Config{Symbols: []string{"BTCUSDT"}, Default: Rate{Taker: 0.0004}}, src)
+	tr.Refresh(context.Background())
+
+	src.err = errors.New("timeout")
+	if err := tr.Refresh(context.Background()); err == nil {
+		t.Fatal("failed refresh not returned")
+	}
+	st := tr.Status()
+	if st.Tier != 2 || st.Failures != 1 || tr.Rate("BTCUSDT").Taker != 0.00035 {
+		t.Errorf("status %+v after a failed refresh", st)
+	}
+}
@@ -0,0 +1,262 @@
+// Package fillcheck confirms that every trade the operator was told about
+// really filled. Each "trade executed" alert is registered as a claim and
+// its order is read back from the exchange until it shows the claimed fill.
+// An order that ends cancelled, rejected or expired, fills a different
+// quantity, or is still open when the timeout runs out, is reported as a
+// discrepancy.
+package fillcheck
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// Kinds of discrepancy.
+const (
+	KindNotFilled  = "not_filled" // the order ended without filling
+	KindQuantity   = "quantity"   // it filled a different quantity
+	KindSide       = "side"       // it filled on the other side
+	KindOpen       = "open"       // still not filled at the timeout
+	KindUnverified = "unverified" // the order could not be read before the timeout
+)
+
+// Claim is what an alert told the operator.
+type Claim struct {
+	Symbol   string
+	OrderID  string
+	Side     trade.Side
+	Quantity float64
+	Price    float64
+	At       time.Time
+}
+
+type Discrepancy struct {
+	Claim  Claim
+	Kind   string
+	Status string
+	Filled float64
+	Detail string
+}
+
+func (d Discrepancy) String() string {
+	return fmt.Sprintf("%s %s %s order %s: %s", d.Claim.Side, d.Claim.Symbol, d.Kind, d.Claim.OrderID, d.Detail)
+}
+
+// Orders reads an order's current state from the exchange.
+type Orders interface {
+	GetOrder(ctx context.Context, orderID, symbol string) (*trade.Order, error)
+}
+
+type Config struct {
+	// Timeout is how long a claimed order has to show its fill.
+	Timeout time.Duration
+	// Interval is how often pending claims are checked.
+	Interval time.Duration
+	// Tolerance is the fraction the filled quantity may differ by.
+	Tolerance     float64
+	OnDiscrepancy func(Discrepancy)
+}
+
+type Stats struct {
+	Pending       int
+	Confirmed     int
+	Discrepancies int
+	LastKind      string
+	LastAt        time.Time
+}
+
+type Checker struct {
+	cfg     Config
+	orders  Orders
+	mu      sync.Mutex
+	running bool
+	pending []*Claim
+	stats   Stats
+	stopCh  chan struct{}
+	now     func() time.Time
+}
+
+func New(cfg Config, orders Orders) *Checker {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Minute
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = 0.01
+	}
+
+	return &Checker{
+		cfg:    cfg,
+		orders: orders,
+		stopCh: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+func (c *Checker) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return nil
+	}
+	c.running = true
+	go c.run(ctx)
+	return nil
+}
+
+func (c *Checker) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return nil
+	}
+	c.running = false
+	close(c.stopCh)
+	return nil
+}
+
+func (c *Checker) run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+// Watch registers an alerted trade to be confirmed against the exchange.
+// Claims without an order ID cannot be looked up and are ignored.
+func (c *Checker) Watch(claim Claim) {
+	if claim.OrderID == "" {
+		return
+	}
+	if claim.At.IsZero() {
+		claim.At = c.now()
+	}
+	c.mu.Lock()
+	c.pending = append(c.pending, &claim)
+	c.mu.Unlock()
+}
+
+// Check reads every pending claim's order once, settling those that
+// filled as claimed and reporting those that did not.
+func (c *Checker) Check(ctx context.Context) {
+	c.mu.Lock()
+	claims := append([]*Claim(nil), c.pending...)
+	c.mu.Unlock()
+
+	var settled []*Claim
+	var found []Discrepancy
+	for _, p := range claims {
+		done, d := c.check(ctx, p)
+		if !done {
+			continue
+		}
+		settled = append(settled, p)
+		if d != nil {
+			found = append(found, *d)
+		}
+	}
+
+	c.mu.Lock()
+	kept := c.pending[:0]
+	for _, p := range c.pending {
+		if !containsPending(settled, p) {
+			kept = append(kept, p)
+		}
+	}
+	c.pending = kept
+	c.stats.Confirmed += len(settled) - len(found)
+	c.stats.Discrepancies += len(found)
+	if len(found) > 0 {
+		c.stats.LastKind = found[len(found)-1].Kind
+		c.stats.LastAt = c.now()
+	}
+	c.mu.Unlock()
+
+	if c.cfg.OnDiscrepancy != nil {
+		for _, d := range found {
+			c.cfg.OnDiscrepancy(d)
+		}
+	}
+}
+
+// check reports whether the claim is settled, and the discrepancy if it
+// settled badly.
+func (c *Checker) check(ctx context.Context, p *Claim) (bool, *Discrepancy) {
+	claim := *p
+	expired := c.now().Sub(claim.At) >= c.cfg.Timeout
+
+	order, err := c.orders.GetOrder(ctx, claim.OrderID, claim.Symbol)
+	if err != nil {
+		if !expired {
+			return false, nil
+		}
+		return true, &Discrepancy{Claim: claim, Kind: KindUnverified, Detail: err.Error()}
+	}
+
+	d := &Discrepancy{Claim: claim, Status: string(order.Status), Filled: order.FilledQty}
+	switch order.Status {
+	case trade.OrderStatusFilled:
+	case trade.OrderStatusPartially, "NEW", trade.OrderStatusPending, trade.OrderStatusSubmitted:
+		if !expired {
+			return false, nil
+		}
+		d.Kind = KindOpen
+		d.Detail = fmt.Sprintf("still %s with %v of %v filled after %s", order.Status, order.FilledQty, claim.Quantity, c.cfg.Timeout)
+		return true, d
+	default:
+		// Cancelled, rejected and expired orders may have part-filled first.
+		if order.FilledQty <= 0 {
+			d.Kind = KindNotFilled
+			d.Detail = fmt.Sprintf("order ended %s with nothing filled", order.Status)
+			return true, d
+		}
+	}
+
+	if order.Side != "" && claim.Side != "" && order.Side != claim.Side {
+		d.Kind = KindSide
+		d.Detail = fmt.Sprintf("alerted %s, exchange shows %s", claim.Side, order.Side)
+		return true, d
+	}
+	if claim.Quantity > 0 && math.Abs(order.FilledQty-claim.Quantity) > claim.Quantity*c.cfg.Tolerance {
+		d.Kind = KindQuantity
+		d.Detail = fmt.Sprintf("alerted %v, exchange filled %v (%s)", claim.Quantity, order.FilledQty, order.Status)
+		return true, d
+	}
+	return true, nil
+}
+
+func (c *Checker) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.stats
+	s.Pending = len(c.pending)
+	return s
+}
+
+func containsPending(list []*Claim, p *Claim) bool {
+	for _, item := range list {
+		if item == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package fillcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+type fakeOrders map[string]*trade.Order
+
+func (f fakeOrders) GetOrder(ctx context.Context, orderID, symbol string) (*trade.Order, error) {
+	if o, ok := f[orderID]; ok {
+		return o, nil
+	}
+	return nil, errors.New("order does not exist")
+}
+
+func TestChecker_ReportsAlertsThatDidNotFill(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	orders := fakeOrders{
+		"1": {Status: "NEW", Side: trade.SideBuy},
+		"2": {Status: "CANCELED", Side: trade.SideBuy},
+		"3": {Status: trade.OrderStatusFilled, Side: trade.SideSell, FilledQty: 0.5},
+	}
+	var found []Discrepancy
+	c := New(Config{Timeout: time.Minute, OnDiscrepancy: func(d Discrepancy) { found = append(found, d) }}, orders)
+	c.now = func() time.Time { return now }
+
+	c.Watch(Claim{Symbol: "BTCUSDT", OrderID: "1", Side: trade.SideBuy, Quantity: 0.01})
+	c.Watch(Claim{Symbol: "ETHUSDT", OrderID: "2", Side: trade.SideBuy, Quantity: 0.2})
+	c.Watch(Claim{Symbol: "SOLUSDT", OrderID: "3", Side: trade.SideSell, Quantity: 1})
+	c.Watch(Claim{Symbol: "XRPUSDT", OrderID: "4", Side: trade.SideBuy, Quantity: 10})
+	c.Watch(Claim{Symbol: "DOGEUSDT", Side: trade.SideBuy, Quantity: 10})
+
+	c.Check(context.Background())
+	if len(found) != 2 || found[0].Kind != KindNotFilled || found[1].Kind != KindQuantity {
+		t.Fatalf("found = %+v", found)
+	}
+	if st := c.Stats(); st.Pending != 2 {
+		t.Fatalf("the open order and the unreadable one should still be pending: %+v", st)
+	}
+
+	// The open order fills in time; the unreadable one times out.
+	orders["1"] = &trade.Order{Status: trade.OrderStatusFilled, Side: trade.SideBuy, FilledQty: 0.01}
+	now = now.Add(time.Minute)
+	c.Check(context.Background())
+	if len(found) != 3 || found[2].Kind != KindUnverified || found[2].Claim.Symbol != "XRPUSDT" {
+		t.Fatalf("found = %+v", found)
+	}
+	if st := c.Stats(); st.Pending != 0 || st.Confirmed != 1 || st.Discrepancies != 3 {
+		t.Fatalf("stats = %+v", st)
+	}
+}
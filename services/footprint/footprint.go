@@ -0,0 +1,295 @@
+package footprint
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Trade struct {
+	Symbol     string
+	Price      float64
+	Quantity   float64
+	BuyerMaker bool
+	Time       time.Time
+}
+
+// Level holds traded volume at one price bucket. BidVolume is aggressive
+// selling into the bid, AskVolume is aggressive buying from the ask.
+type Level struct {
+	Price     float64
+	BidVolume float64
+	AskVolume float64
+}
+
+func (l Level) Total() float64 {
+	return l.BidVolume + l.AskVolume
+}
+
+type Candle struct {
+	Symbol   string
+	OpenTime time.Time
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	levels   map[int64]*Level
+	tickSize float64
+}
+
+type Features struct {
+	OpenTime       time.Time
+	BuyVolume      float64
+	SellVolume     float64
+	Delta          float64
+	DeltaPercent   float64
+	POC            float64
+	BuyImbalances  int
+	SellImbalances int
+	StackedBuy     bool
+	StackedSell    bool
+	BidAbsorption  bool
+	AskAbsorption  bool
+}
+
+type Config struct {
+	Interval           time.Duration
+	TickSize           float64
+	ImbalanceRatio     float64
+	StackedLevels      int
+	AbsorptionMultiple float64
+	MaxCandles         int
+}
+
+type Builder struct {
+	cfg       Config
+	mu        sync.RWMutex
+	tickSizes map[string]float64
+	current   map[string]*Candle
+	history   map[string][]*Candle
+}
+
+func NewBuilder(cfg Config) *Builder {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.ImbalanceRatio <= 0 {
+		cfg.ImbalanceRatio = 3
+	}
+	if cfg.StackedLevels <= 0 {
+		cfg.StackedLevels = 3
+	}
+	if cfg.AbsorptionMultiple <= 0 {
+		cfg.AbsorptionMultiple = 2.5
+	}
+	if cfg.MaxCandles <= 0 {
+		cfg.MaxCandles = 60
+	}
+
+	return &Builder{
+		cfg:       cfg,
+		tickSizes: make(map[string]float64),
+		current:   make(map[string]*Candle),
+		history:   make(map[string][]*Candle),
+	}
+}
+
+// SetTickSize overrides the price bucket for a symbol. Without one the
+// builder falls back to Config.TickSize, then to a bucket derived from price.
+func (b *Builder) SetTickSize(symbol string, tickSize float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tickSizes[symbol] = tickSize
+}
+
+func (b *Builder) Add(t Trade) {
+	if t.Price <= 0 || t.Quantity <= 0 {
+		return
+	}
+
+	openTime := t.Time.Truncate(b.cfg.Interval)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.current[t.Symbol]
+	if c == nil || openTime.After(c.OpenTime) {
+		if c != nil {
+			b.closeCandle(c)
+		}
+		c = &Candle{
+			Symbol:   t.Symbol,
+			OpenTime: openTime,
+			Open:     t.Price,
+			High:     t.Price,
+			Low:      t.Price,
+			levels:   make(map[int64]*Level),
+			tickSize: b.tickSize(t.Symbol, t.Price),
+		}
+		b.current[t.Symbol] = c
+	} else if openTime.Before(c.OpenTime) {
+		return
+	}
+
+	c.Close = t.Price
+	if t.Price > c.High {
+		c.High = t.Price
+	}
+	if t.Price < c.Low {
+		c.Low = t.Price
+	}
+
+	key := int64(math.Floor(t.Price / c.tickSize))
+	lvl := c.levels[key]
+	if lvl == nil {
+		lvl = &Level{Price: float64(key) * c.tickSize}
+		c.levels[key] = lvl
+	}
+	if t.BuyerMaker {
+		lvl.BidVolume += t.Quantity
+	} else {
+		lvl.AskVolume += t.Quantity
+	}
+}
+
+func (b *Builder) closeCandle(c *Candle) {
+	hist := append(b.history[c.Symbol], c)
+	if len(hist) > b.cfg.MaxCandles {
+		hist = hist[len(hist)-b.cfg.MaxCandles:]
+	}
+	b.history[c.Symbol] = hist
+}
+
+func (b *Builder) tickSize(symbol string, price float64) float64 {
+	if ts := b.tickSizes[symbol]; ts > 0 {
+		return ts
+	}
+	if b.cfg.TickSize > 0 {
+		return b.cfg.TickSize
+	}
+	// Roughly 2 bps buckets keep level counts comparable across price scales.
+	return math.Pow(10, math.Floor(math.Log10(price*0.0002)))
+}
+
+// Levels returns the candle's price levels sorted from low to high.
+func (c *Candle) Levels() []Level {
+	levels := make([]Level, 0, len(c.levels))
+	for _, l := range c.levels {
+		levels = append(levels, *l)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Price < levels[j].Price
+	})
+	return levels
+}
+
+func (b *Builder) Current(symbol string) (Features, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	c := b.current[symbol]
+	if c == nil {
+		return Features{}, false
+	}
+	return analyze(c, b.cfg), true
+}
+
+// Last returns features of the most recently completed candle.
+func (b *Builder) Last(symbol string) (Features, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	hist := b.history[symbol]
+	if len(hist) == 0 {
+		return Features{}, false
+	}
+	return analyze(hist[len(hist)-1], b.cfg), true
+}
+
+func (b *Builder) History(symbol string, n int) []Features {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	hist := b.history[symbol]
+	if n <= 0 || n > len(hist) {
+		n = len(hist)
+	}
+
+	result := make([]Features, 0, n)
+	for _, c := range hist[len(hist)-n:] {
+		result = append(result, analyze(c, b.cfg))
+	}
+	return result
+}
+
+// analyze derives delta, point of control, diagonal imbalances and
+// absorption at the candle extremes.
+func analyze(c *Candle, cfg Config) Features {
+	levels := c.Levels()
+	f := Features{OpenTime: c.OpenTime}
+	if len(levels) == 0 {
+		return f
+	}
+
+	var maxVol, total float64
+	for _, l := range levels {
+		f.BuyVolume += l.AskVolume
+		f.SellVolume += l.BidVolume
+		if l.Total() > maxVol {
+			maxVol = l.Total()
+			f.POC = l.Price
+		}
+		total += l.Total()
+	}
+	f.Delta = f.BuyVolume - f.SellVolume
+	if total > 0 {
+		f.DeltaPercent = f.Delta / total * 100
+	}
+
+	// Diagonal comparison: buyers lifting level i against sellers hitting
+	// level i-1, and the mirror for sellers.
+	buyRun, sellRun := 0, 0
+	for i := 1; i < len(levels); i++ {
+		if imbalanced(levels[i].AskVolume, levels[i-1].BidVolume, cfg.ImbalanceRatio) {
+			f.BuyImbalances++
+			buyRun++
+			if buyRun >= cfg.StackedLevels {
+				f.StackedBuy = true
+			}
+		} else {
+			buyRun = 0
+		}
+
+		if imbalanced(levels[i-1].BidVolume, levels[i].AskVolume, cfg.ImbalanceRatio) {
+			f.SellImbalances++
+			sellRun++
+			if sellRun >= cfg.StackedLevels {
+				f.StackedSell = true
+			}
+		} else {
+			sellRun = 0
+		}
+	}
+
+	// Absorption: heavy aggression into an extreme that price failed to
+	// extend past, i.e. the candle closed back away from that level.
+	avg := total / float64(len(levels))
+	low, high := levels[0], levels[len(levels)-1]
+	if len(levels) > 1 {
+		f.BidAbsorption = low.BidVolume >= avg*cfg.AbsorptionMultiple && low.BidVolume > low.AskVolume && c.Close > low.Price+c.tickSize
+		f.AskAbsorption = high.AskVolume >= avg*cfg.AbsorptionMultiple && high.AskVolume > high.BidVolume && c.Close < high.Price
+	}
+
+	return f
+}
+
+func imbalanced(aggressive, opposite, ratio float64) bool {
+	if aggressive <= 0 {
+		return false
+	}
+	if opposite <= 0 {
+		return true
+	}
+	return aggressive/opposite >= ratio
+}
@@ -0,0 +1,70 @@
+package footprint
+
+import (
+	"testing"
+	"time"
+)
+
+var base = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func add(b *Builder, offset time.Duration, price, qty float64, buyerMaker bool) {
+	b.Add(Trade{Symbol: "BTCUSDT", Price: price, Quantity: qty, BuyerMaker: buyerMaker, Time: base.Add(offset)})
+}
+
+func TestBuilder_RollsCandlesAndComputesDelta(t *testing.T) {
+	b := NewBuilder(Config{TickSize: 1})
+
+	add(b, time.Second, 100, 2, false)
+	add(b, 2*time.Second, 100, 1, true)
+	add(b, 61*time.Second, 101, 1, false)
+
+	last, ok := b.Last("BTCUSDT")
+	if !ok {
+		t.Fatal("expected a completed candle")
+	}
+	if last.BuyVolume != 2 || last.SellVolume != 1 || last.Delta != 1 {
+		t.Errorf("unexpected volumes: %+v", last)
+	}
+	if last.POC != 100 {
+		t.Errorf("expected POC 100, got %f", last.POC)
+	}
+
+	cur, ok := b.Current("BTCUSDT")
+	if !ok || cur.BuyVolume != 1 {
+		t.Errorf("unexpected current candle: %+v", cur)
+	}
+}
+
+func TestBuilder_StackedBuyImbalance(t *testing.T) {
+	b := NewBuilder(Config{TickSize: 1, StackedLevels: 3})
+
+	for i, price := range []float64{100, 101, 102, 103} {
+		add(b, time.Duration(i)*time.Second, price, 1, true)
+		add(b, time.Duration(i)*time.Second, price, 10, false)
+	}
+
+	f, _ := b.Current("BTCUSDT")
+	if f.BuyImbalances != 3 || !f.StackedBuy {
+		t.Errorf("expected 3 stacked buy imbalances, got %+v", f)
+	}
+	if f.StackedSell {
+		t.Error("did not expect stacked sell imbalance")
+	}
+}
+
+func TestBuilder_BidAbsorption(t *testing.T) {
+	b := NewBuilder(Config{TickSize: 1})
+
+	add(b, 0, 102, 1, false)
+	add(b, time.Second, 100, 20, true)
+	add(b, 2*time.Second, 101, 1, false)
+	add(b, 3*time.Second, 102, 1, false)
+
+	f, _ := b.Current("BTCUSDT")
+	if !f.BidAbsorption {
+		t.Errorf("expected bid absorption at the low, got %+v", f)
+	}
+	if f.AskAbsorption {
+		t.Error("did not expect ask absorption")
+	}
+}
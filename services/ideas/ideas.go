@@ -0,0 +1,286 @@
+// Package ideas is an inbox for trade ideas from outside the bot. A human or
+// an external research system posts a symbol with its thesis; for a limited
+// window the symbol is screened and analyzed ahead of the rest, and the
+// trades entered on it are tagged with who submitted it so their outcomes
+// can be reported apart from the bot's own picks.
+//
+// An idea only raises a symbol's priority among those the bot already
+// trades. It never adds a symbol to them, is analyzed and gated like any
+// other candidate and may well not be entered.
+package ideas
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoSymbol  = errors.New("no symbol given")
+	ErrNoThesis  = errors.New("no thesis given")
+	ErrNoAuthor  = errors.New("no submitter given")
+	ErrInboxFull = errors.New("too many active ideas")
+	// ErrUnknownSymbol is wrapped by Config.Symbols for a symbol the
+	// exchange does not list.
+	ErrUnknownSymbol = errors.New("symbol not listed")
+	ErrUnverified    = errors.New("symbol listings unavailable")
+)
+
+// maxBody caps a submission's JSON body.
+const maxBody = 16 << 10
+
+type Config struct {
+	// Token must be sent as "Authorization: Bearer <token>". Without one
+	// every request is refused.
+	Token string
+	// Window is how long an idea stays active, and the most a submitter
+	// may ask for. Default 4h.
+	Window time.Duration
+	// MaxActive caps the ideas active at once. Default 10.
+	MaxActive int
+	// Symbols checks a submitted symbol against the exchange's listings,
+	// wrapping ErrUnknownSymbol for one it does not list. Nil accepts any.
+	Symbols func(ctx context.Context, symbol string) error
+}
+
+// Idea is one submitted symbol and why it is worth a look.
+type Idea struct {
+	ID        int       `json:"id"`
+	Symbol    string    `json:"symbol"`
+	Thesis    string    `json:"thesis"`
+	Submitter string    `json:"submitter"`
+	Submitted time.Time `json:"submitted"`
+	Expires   time.Time `json:"expires"`
+}
+
+// Request is the body of a submission. Hours shortens the window.
+type Request struct {
+	Symbol    string  `json:"symbol"`
+	Thesis    string  `json:"thesis"`
+	Submitter string  `json:"submitter"`
+	Hours     float64 `json:"hours,omitempty"`
+}
+
+type Stats struct {
+	Active    []Idea `json:"active"`
+	Submitted int    `json:"submitted"`
+	Refused   int    `json:"refused"`
+}
+
+type Inbox struct {
+	cfg       Config
+	mu        sync.RWMutex
+	ideas     []Idea
+	nextID    int
+	submitted int
+	refused   int
+	now       func() time.Time
+}
+
+func New(cfg Config) *Inbox {
+	if cfg.Window <= 0 {
+		cfg.Window = 4 * time.Hour
+	}
+	if cfg.MaxActive <= 0 {
+		cfg.MaxActive = 10
+	}
+	return &Inbox{cfg: cfg, nextID: 1, now: time.Now}
+}
+
+// Submit adds an idea. A new idea on a symbol that already has one replaces
+// it, so a thesis can be updated or its window renewed.
+func (in *Inbox) Submit(ctx context.Context, req Request) (Idea, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	thesis := strings.TrimSpace(req.Thesis)
+	submitter := strings.TrimSpace(req.Submitter)
+
+	var err error
+	switch {
+	case symbol == "":
+		err = ErrNoSymbol
+	case !validSymbol(symbol):
+		err = fmt.Errorf("%w: %q", ErrUnknownSymbol, symbol)
+	case thesis == "":
+		err = ErrNoThesis
+	case submitter == "":
+		err = ErrNoAuthor
+	case in.cfg.Symbols != nil:
+		if err = in.cfg.Symbols(ctx, symbol); err != nil && !errors.Is(err, ErrUnknownSymbol) {
+			err = fmt.Errorf("%w: %v", ErrUnverified, err)
+		}
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if err != nil {
+		in.refused++
+		return Idea{}, err
+	}
+
+	now := in.now()
+	in.prune(now)
+	kept := in.ideas[:0]
+	for _, idea := range in.ideas {
+		if idea.Symbol != symbol {
+			kept = append(kept, idea)
+		}
+	}
+	in.ideas = kept
+	if len(in.ideas) >= in.cfg.MaxActive {
+		in.refused++
+		return Idea{}, ErrInboxFull
+	}
+
+	window := in.cfg.Window
+	if req.Hours > 0 && time.Duration(req.Hours*float64(time.Hour)) < window {
+		window = time.Duration(req.Hours * float64(time.Hour))
+	}
+	idea := Idea{
+		ID:        in.nextID,
+		Symbol:    symbol,
+		Thesis:    thesis,
+		Submitter: submitter,
+		Submitted: now,
+		Expires:   now.Add(window),
+	}
+	in.nextID++
+	in.submitted++
+	in.ideas = append(in.ideas, idea)
+	return idea, nil
+}
+
+// validSymbol reports whether symbol could be an exchange symbol at all,
+// so junk is refused before it is looked up.
+func validSymbol(symbol string) bool {
+	if len(symbol) > 20 {
+		return false
+	}
+	for _, r := range symbol {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Withdraw removes the active idea on symbol, reporting whether there was one.
+func (in *Inbox) Withdraw(symbol string) bool {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	for i, idea := range in.ideas {
+		if idea.Symbol == symbol {
+			in.ideas = append(in.ideas[:i], in.ideas[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// prune drops expired ideas. Callers hold in.mu.
+func (in *Inbox) prune(now time.Time) {
+	kept := in.ideas[:0]
+	for _, idea := range in.ideas {
+		if now.Before(idea.Expires) {
+			kept = append(kept, idea)
+		}
+	}
+	in.ideas = kept
+}
+
+// Active returns the unexpired ideas, newest first.
+func (in *Inbox) Active() []Idea {
+	if in == nil {
+		return nil
+	}
+	now := in.now()
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	var active []Idea
+	for _, idea := range in.ideas {
+		if now.Before(idea.Expires) {
+			active = append(active, idea)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].ID > active[j].ID })
+	return active
+}
+
+// Lookup returns the active idea on symbol.
+func (in *Inbox) Lookup(symbol string) (Idea, bool) {
+	for _, idea := range in.Active() {
+		if idea.Symbol == symbol {
+			return idea, true
+		}
+	}
+	return Idea{}, false
+}
+
+// Boosted reports whether symbol has an active idea.
+func (in *Inbox) Boosted(symbol string) bool {
+	_, ok := in.Lookup(symbol)
+	return ok
+}
+
+func (in *Inbox) Stats() Stats {
+	active := in.Active()
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	return Stats{Active: active, Submitted: in.submitted, Refused: in.refused}
+}
+
+func (in *Inbox) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return in.cfg.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(in.cfg.Token)) == 1
+}
+
+// ServeHTTP lists active ideas on GET, submits one on POST with a JSON
+// Request and withdraws one on DELETE ?symbol=. Every method needs the token.
+func (in *Inbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !in.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(in.Active())
+	case http.MethodPost:
+		var req Request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		idea, err := in.Submit(r.Context(), req)
+		if err != nil {
+			status := http.StatusBadRequest
+			switch {
+			case errors.Is(err, ErrInboxFull):
+				status = http.StatusTooManyRequests
+			case errors.Is(err, ErrUnverified):
+				status = http.StatusServiceUnavailable
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(idea)
+	case http.MethodDelete:
+		if !in.Withdraw(r.URL.Query().Get("symbol")) {
+			http.Error(w, "No active idea", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,99 @@
+package ideas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInbox_IdeasExpireAfterTheirWindow(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	in := New(Config{Token: "t", Window: 4 * time.Hour})
+	in.now = func() time.Time { return now }
+
+	if _, err := in.Submit(context.Background(), Request{Symbol: "solusdt", Thesis: "ETF flows", Submitter: "desk"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Submit(context.Background(), Request{Symbol: "ETHUSDT", Thesis: "upgrade", Submitter: "desk", Hours: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Submit(context.Background(), Request{Symbol: "BTCUSDT", Submitter: "desk"}); err != ErrNoThesis {
+		t.Errorf("idea without thesis: err = %v", err)
+	}
+	if idea, ok := in.Lookup("SOLUSDT"); !ok || idea.Submitter != "desk" {
+		t.Fatalf("SOLUSDT idea = %+v, %v", idea, ok)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if in.Boosted("ETHUSDT") || !in.Boosted("SOLUSDT") {
+		t.Errorf("active after 2h = %+v, want only SOLUSDT", in.Active())
+	}
+	now = now.Add(2 * time.Hour)
+	if len(in.Active()) != 0 {
+		t.Errorf("active after 4h = %+v", in.Active())
+	}
+	if st := in.Stats(); st.Submitted != 2 || st.Refused != 1 {
+		t.Errorf("stats %+v", st)
+	}
+}
+
+func TestInbox_RequiresTheToken(t *testing.T) {
+	in := New(Config{Token: "secret"})
+	body := `{"symbol":"SOLUSDT","thesis":"ETF flows","submitter":"desk"}`
+
+	for _, tc := range []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusCreated},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/ideas", strings.NewReader(body))
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		in.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("auth %q: status %d, want %d", tc.auth, rec.Code, tc.want)
+		}
+	}
+	if !in.Boosted("SOLUSDT") {
+		t.Error("authorized idea not active")
+	}
+}
+
+func TestInbox_RefusesSymbolsTheExchangeDoesNotList(t *testing.T) {
+	in := New(Config{Token: "secret", Symbols: func(ctx context.Context, symbol string) error {
+		if symbol != "SOLUSDT" {
+			return fmt.Errorf("%w: %s", ErrUnknownSymbol, symbol)
+		}
+		return nil
+	}})
+
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"symbol":"FAKEUSDT","thesis":"moon","submitter":"desk"}`, http.StatusBadRequest},
+		{`{"symbol":"SOL/USDT","thesis":"moon","submitter":"desk"}`, http.StatusBadRequest},
+		{`{"symbol":"SOLUSDT","thesis":"` + strings.Repeat("x", maxBody) + `","submitter":"desk"}`, http.StatusBadRequest},
+		{`{"symbol":"SOLUSDT","thesis":"ETF flows","submitter":"desk"}`, http.StatusCreated},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/ideas", strings.NewReader(tc.body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		in.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%.40s: status %d, want %d", tc.body, rec.Code, tc.want)
+		}
+	}
+	if active := in.Active(); len(active) != 1 || active[0].Symbol != "SOLUSDT" {
+		t.Errorf("active %+v, want only SOLUSDT", active)
+	}
+}
@@ -0,0 +1,225 @@
+// Package instancelock keeps two bots from trading the same account. The
+// first instance to start takes a lease keyed by its API key and renews it
+// while it runs; a second instance finds the lease held and refuses to
+// start unless forced. A crashed instance's lease expires after its TTL,
+// so a restart may have to wait that long.
+package instancelock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Lock is a lease held by at most one instance, such as the failover
+// file and Redis locks.
+type Lock interface {
+	Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	Renew(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, id string) error
+	Holder(ctx context.Context) (string, error)
+}
+
+// ErrHeld is returned by Acquire when another instance holds the lock.
+var ErrHeld = errors.New("another instance holds the account lock")
+
+// Conflict describes another instance found holding the lock, or taking
+// it from this one.
+type Conflict struct {
+	Instance string
+	Holder   string
+	Forced   bool
+	Lost     bool
+}
+
+func (c Conflict) String() string {
+	switch {
+	case c.Lost:
+		return fmt.Sprintf("lost the account lock to %s", c.Holder)
+	case c.Forced:
+		return fmt.Sprintf("started with -force while %s holds the account lock", c.Holder)
+	}
+	return fmt.Sprintf("refused to start: %s holds the account lock", c.Holder)
+}
+
+type Config struct {
+	// Instance identifies this process in the lease.
+	Instance  string
+	TTL       time.Duration
+	Heartbeat time.Duration
+	// OnConflict is called when the lock is held at start or lost later.
+	OnConflict func(Conflict)
+}
+
+type Stats struct {
+	Instance string
+	Held     bool
+	Since    time.Time
+	Renewals int
+	Conflict string
+}
+
+type Guard struct {
+	cfg     Config
+	lock    Lock
+	mu      sync.Mutex
+	running bool
+	stats   Stats
+	stopCh  chan struct{}
+	now     func() time.Time
+}
+
+// Key derives the lock key from an API key without exposing it.
+func Key(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+func New(cfg Config, lock Lock) *Guard {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 30 * time.Second
+	}
+	if cfg.Heartbeat <= 0 || cfg.Heartbeat >= cfg.TTL {
+		cfg.Heartbeat = cfg.TTL / 3
+	}
+
+	return &Guard{
+		cfg:    cfg,
+		lock:   lock,
+		stats:  Stats{Instance: cfg.Instance},
+		stopCh: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// Acquire takes the lock. When another instance holds it, Acquire returns
+// ErrHeld, or with force logs the conflict and carries on without the lock,
+// taking it once the other instance lets it go.
+func (g *Guard) Acquire(ctx context.Context, force bool) error {
+	ok, err := g.lock.Acquire(ctx, g.cfg.Instance, g.cfg.TTL)
+	if err != nil {
+		return fmt.Errorf("account lock: %w", err)
+	}
+	if ok {
+		g.mu.Lock()
+		g.stats.Held, g.stats.Since = true, g.now()
+		g.mu.Unlock()
+		return nil
+	}
+
+	holder, _ := g.lock.Holder(ctx)
+	if holder == "" {
+		holder = "an unknown instance"
+	}
+	c := Conflict{Instance: g.cfg.Instance, Holder: holder, Forced: force}
+	g.conflict(c)
+	if !force {
+		return fmt.Errorf("%w (%s); stop it, wait up to %s for its lease to expire, or start with -force", ErrHeld, holder, g.cfg.TTL)
+	}
+	return nil
+}
+
+func (g *Guard) Start(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.running {
+		return nil
+	}
+	g.running = true
+	go g.run(ctx)
+	return nil
+}
+
+// Stop ends the heartbeat and releases the lock if this instance holds it,
+// whether or not the heartbeat was started.
+func (g *Guard) Stop() error {
+	g.mu.Lock()
+	if g.running {
+		g.running = false
+		close(g.stopCh)
+	}
+	held := g.stats.Held
+	g.stats.Held = false
+	g.mu.Unlock()
+
+	if !held {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return g.lock.Release(ctx, g.cfg.Instance)
+}
+
+func (g *Guard) run(ctx context.Context) {
+	ticker := time.NewTicker(g.cfg.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-g.stopCh:
+			return
+		case <-ticker.C:
+			g.Renew(ctx)
+		}
+	}
+}
+
+// Renew extends the lease, or retries taking it when this instance was
+// forced to start without it. A lease taken over by another instance is
+// reported as a conflict.
+func (g *Guard) Renew(ctx context.Context) {
+	g.mu.Lock()
+	held := g.stats.Held
+	g.mu.Unlock()
+
+	var ok bool
+	var err error
+	if held {
+		ok, err = g.lock.Renew(ctx, g.cfg.Instance, g.cfg.TTL)
+	} else {
+		ok, err = g.lock.Acquire(ctx, g.cfg.Instance, g.cfg.TTL)
+	}
+	if err != nil {
+		// The store being unreachable is not evidence of another instance.
+		return
+	}
+
+	g.mu.Lock()
+	switch {
+	case ok && held:
+		g.stats.Renewals++
+	case ok:
+		g.stats.Held, g.stats.Since = true, g.now()
+		g.stats.Conflict = ""
+	case held:
+		g.stats.Held = false
+	}
+	g.mu.Unlock()
+
+	if held && !ok {
+		holder, _ := g.lock.Holder(ctx)
+		g.conflict(Conflict{Instance: g.cfg.Instance, Holder: holder, Lost: true})
+	}
+}
+
+func (g *Guard) conflict(c Conflict) {
+	g.mu.Lock()
+	g.stats.Conflict = c.String()
+	g.mu.Unlock()
+	if g.cfg.OnConflict != nil {
+		g.cfg.OnConflict(c)
+	}
+}
+
+func (g *Guard) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stats
+}
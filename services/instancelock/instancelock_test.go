@@ -0,0 +1,54 @@
+package instancelock
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/services/failover"
+)
+
+func TestGuard_RefusesSecondInstanceUnlessForced(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "instance-"+Key("api-key")+".lock")
+
+	first := New(Config{Instance: "host-a:1", TTL: time.Minute}, failover.NewFileLock(path))
+	if err := first.Acquire(ctx, false); err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+
+	var conflicts []Conflict
+	second := New(Config{Instance: "host-b:2", TTL: time.Minute, OnConflict: func(c Conflict) { conflicts = append(conflicts, c) }}, failover.NewFileLock(path))
+	if err := second.Acquire(ctx, false); !errors.Is(err, ErrHeld) {
+		t.Fatalf("second acquire = %v, want ErrHeld", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Holder != "host-a:1" || conflicts[0].Forced {
+		t.Fatalf("conflicts = %+v", conflicts)
+	}
+
+	if err := second.Acquire(ctx, true); err != nil {
+		t.Fatalf("forced acquire: %v", err)
+	}
+	if s := second.Stats(); s.Held || s.Conflict == "" {
+		t.Fatalf("forced instance should run without the lock: %+v", s)
+	}
+
+	// Once the first instance stops, the forced one takes the lock.
+	if err := first.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	second.Renew(ctx)
+	if s := second.Stats(); !s.Held || s.Conflict != "" {
+		t.Fatalf("forced instance should take the released lock: %+v", s)
+	}
+
+	// Now the first instance is the one turned away.
+	if err := first.Acquire(ctx, false); !errors.Is(err, ErrHeld) {
+		t.Fatalf("first reacquire = %v, want ErrHeld", err)
+	}
+	if Key("api-key") == Key("other-key") || len(Key("api-key")) != 16 {
+		t.Fatalf("key = %q", Key("api-key"))
+	}
+}
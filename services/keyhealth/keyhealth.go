@@ -0,0 +1,280 @@
+// Package keyhealth watches the API key the bot trades with. A cheap signed
+// call on every check catches a key that was revoked, lost its futures
+// permission or is called from an address outside its IP whitelist before
+// an entry or a stop runs into the rejection; the key's permission metadata,
+// where the exchange exposes it, adds the futures flag, the IP restriction
+// and the expiry of the trading authority.
+//
+// While the key is rejected the monitor is in safe mode: Blocked reports it,
+// and callers stop opening positions until a check passes again.
+package keyhealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRejected marks a Source error as the exchange refusing the key itself
+// (invalid key, signature, permissions or IP), as opposed to a call that
+// failed on the way.
+var ErrRejected = errors.New("api key rejected")
+
+// Permissions is the key's metadata. Known is false when the source could
+// not read it, as on testnet; the other fields are then unset. A zero
+// Expires means the key does not expire.
+type Permissions struct {
+	Known        bool
+	Futures      bool
+	Reading      bool
+	IPRestricted bool
+	Created      time.Time
+	Expires      time.Time
+}
+
+// Source makes the signed calls. Errors wrapping ErrRejected put the
+// monitor in safe mode; other errors are counted and leave it as it was.
+type Source interface {
+	Ping(ctx context.Context) error
+	Permissions(ctx context.Context) (Permissions, error)
+}
+
+type Config struct {
+	// Interval between checks. Default 5m.
+	Interval time.Duration
+	// ExpiryWarning is how long before the key expires to warn. Default
+	// 7 days.
+	ExpiryWarning time.Duration
+}
+
+// EventKind names a change in the key's health.
+type EventKind string
+
+const (
+	EventInvalid   EventKind = "invalid"
+	EventRecovered EventKind = "recovered"
+	EventExpiring  EventKind = "expiring"
+)
+
+// Event is passed to OnEvent handlers.
+type Event struct {
+	Kind    EventKind
+	Reason  string
+	Expires time.Time
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case EventExpiring:
+		return fmt.Sprintf("API key expires %s", e.Expires.UTC().Format("2006-01-02 15:04 MST"))
+	case EventRecovered:
+		return "API key accepted again"
+	default:
+		return "API key rejected: " + e.Reason
+	}
+}
+
+// Status is the outcome of the checks so far. Since is when the key last
+// changed between healthy and rejected.
+type Status struct {
+	Healthy      bool      `json:"healthy"`
+	Reason       string    `json:"reason,omitempty"`
+	Futures      bool      `json:"futures"`
+	IPRestricted bool      `json:"ip_restricted"`
+	Expires      time.Time `json:"expires,omitempty"`
+	LastCheck    time.Time `json:"last_check"`
+	Checks       int       `json:"checks"`
+	Failures     int       `json:"failures"`
+	Since        time.Time `json:"since"`
+}
+
+type Monitor struct {
+	cfg      Config
+	source   Source
+	mu       sync.RWMutex
+	running  bool
+	status   Status
+	perms    Permissions
+	warned   time.Time
+	handlers []func(Event)
+	stopCh   chan struct{}
+	now      func() time.Time
+}
+
+func New(cfg Config, source Source) *Monitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.ExpiryWarning <= 0 {
+		cfg.ExpiryWarning = 7 * 24 * time.Hour
+	}
+	return &Monitor{
+		cfg:    cfg,
+		source: source,
+		status: Status{Healthy: true},
+		stopCh: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// OnEvent registers fn for every change in the key's health. Handlers run
+// on the checking goroutine and must not block.
+func (m *Monitor) OnEvent(fn func(Event)) {
+	m.mu.Lock()
+	m.handlers = append(m.handlers, fn)
+	m.mu.Unlock()
+}
+
+func (m *Monitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	m.Check(ctx)
+	go m.run(ctx)
+	return nil
+}
+
+func (m *Monitor) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return nil
+	}
+	m.running = false
+	close(m.stopCh)
+	return nil
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// Check pings the key and reads its permissions. A call that failed without
+// the exchange rejecting the key is returned and leaves the state as it was.
+func (m *Monitor) Check(ctx context.Context) error {
+	if m.source == nil {
+		return nil
+	}
+	var reason string
+	err := m.source.Ping(ctx)
+	if errors.Is(err, ErrRejected) {
+		reason, err = err.Error(), nil
+	}
+
+	var perms Permissions
+	var permErr error
+	if err == nil && reason == "" {
+		perms, permErr = m.source.Permissions(ctx)
+		if errors.Is(permErr, ErrRejected) {
+			reason, permErr = permErr.Error(), nil
+		}
+	}
+
+	m.mu.Lock()
+	m.status.Checks++
+	m.status.LastCheck = m.now()
+	if err != nil || permErr != nil {
+		m.status.Failures++
+		m.mu.Unlock()
+		if err != nil {
+			return fmt.Errorf("ping: %w", err)
+		}
+		return fmt.Errorf("permissions: %w", permErr)
+	}
+
+	if perms.Known {
+		m.perms = perms
+		m.status.Futures = perms.Futures
+		m.status.IPRestricted = perms.IPRestricted
+		m.status.Expires = perms.Expires
+	}
+	if reason == "" {
+		reason = m.permissionProblem()
+	}
+	events := m.transition(reason)
+	handlers := m.handlers
+	m.mu.Unlock()
+
+	for _, ev := range events {
+		for _, fn := range handlers {
+			fn(ev)
+		}
+	}
+	return nil
+}
+
+// permissionProblem returns why the last known permissions leave the key
+// unusable for futures trading, or "".
+func (m *Monitor) permissionProblem() string {
+	if !m.perms.Known {
+		return ""
+	}
+	if !m.perms.Futures {
+		return "futures trading is not enabled for the key"
+	}
+	if !m.perms.Expires.IsZero() && !m.now().Before(m.perms.Expires) {
+		return "trading authority expired " + m.perms.Expires.UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// transition applies the check's outcome and returns the events it caused.
+// Callers hold m.mu.
+func (m *Monitor) transition(reason string) []Event {
+	var events []Event
+	now := m.now()
+	switch {
+	case reason != "" && m.status.Healthy:
+		m.status.Healthy, m.status.Reason, m.status.Since = false, reason, now
+		events = append(events, Event{Kind: EventInvalid, Reason: reason})
+	case reason != "":
+		m.status.Reason = reason
+	case !m.status.Healthy:
+		m.status.Healthy, m.status.Reason, m.status.Since = true, "", now
+		events = append(events, Event{Kind: EventRecovered})
+	}
+
+	// Warn once per expiry date, so a renewed key warns again.
+	expires := m.perms.Expires
+	if m.status.Healthy && !expires.IsZero() && expires.Sub(now) <= m.cfg.ExpiryWarning && !expires.Equal(m.warned) {
+		m.warned = expires
+		events = append(events, Event{Kind: EventExpiring, Expires: expires})
+	}
+	return events
+}
+
+// Blocked reports whether the key is in safe mode, and why.
+func (m *Monitor) Blocked() (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Reason, !m.status.Healthy
+}
+
+func (m *Monitor) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
@@ -0,0 +1,98 @@
+package keyhealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	ping  error
+	perms Permissions
+}
+
+func (s *fakeSource) Ping(context.Context) error { return s.ping }
+
+func (s *fakeSource) Permissions(context.Context) (Permissions, error) { return s.perms, nil }
+
+func monitor(src *fakeSource, now time.Time) (*Monitor, *[]Event) {
+	m := New(Config{}, src)
+	m.now = func() time.Time { return now }
+	var events []Event
+	m.OnEvent(func(e Event) { events = append(events, e) })
+	return m, &events
+}
+
+func TestCheck_RejectedKeyBlocksUntilItRecovers(t *testing.T) {
+	src := &fakeSource{perms: Permissions{Known: true, Futures: true, IPRestricted: true}}
+	m, events := monitor(src, time.Now())
+
+	m.Check(context.Background())
+	if _, blocked := m.Blocked(); blocked {
+		t.Fatal("healthy key blocked")
+	}
+
+	src.ping = fmt.Errorf("%w: code=-2015", ErrRejected)
+	m.Check(context.Background())
+	if reason, blocked := m.Blocked(); !blocked || reason == "" {
+		t.Fatalf("rejected key not blocked: %q", reason)
+	}
+
+	// A network error neither blocks nor clears safe mode.
+	src.ping = errors.New("connection reset")
+	if err := m.Check(context.Background()); err == nil {
+		t.Error("transport error not returned")
+	}
+	if _, blocked := m.Blocked(); !blocked {
+		t.Error("transport error cleared safe mode")
+	}
+
+	src.ping = nil
+	m.Check(context.Background())
+	if _, blocked := m.Blocked(); blocked {
+		t.Error("accepted key still blocked")
+	}
+	if len(*events) != 2 || (*events)[0].Kind != EventInvalid || (*events)[1].Kind != EventRecovered {
+		t.Errorf("events %+v", *events)
+	}
+	if st := m.Status(); st.Checks != 4 || st.Failures != 1 {
+		t.Errorf("status %+v", st)
+	}
+}
+
+func TestCheck_MissingFuturesPermissionBlocks(t *testing.T) {
+	src := &fakeSource{perms: Permissions{Known: true, Reading: true}}
+	m, _ := monitor(src, time.Now())
+
+	m.Check(context.Background())
+	if _, blocked := m.Blocked(); !blocked {
+		t.Error("key without futures permission not blocked")
+	}
+
+	// Unknown permissions, as on testnet, rely on the ping alone.
+	m, _ = monitor(&fakeSource{}, time.Now())
+	m.Check(context.Background())
+	if _, blocked := m.Blocked(); blocked {
+		t.Error("unknown permissions blocked")
+	}
+}
+
+func TestCheck_WarnsOnceBeforeExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{perms: Permissions{Known: true, Futures: true, Expires: now.Add(72 * time.Hour)}}
+	m, events := monitor(src, now)
+
+	m.Check(context.Background())
+	m.Check(context.Background())
+	if len(*events) != 1 || (*events)[0].Kind != EventExpiring {
+		t.Fatalf("events %+v, want one expiry warning", *events)
+	}
+
+	src.perms.Expires = now.Add(-time.Minute)
+	m.Check(context.Background())
+	if _, blocked := m.Blocked(); !blocked {
+		t.Error("expired key not blocked")
+	}
+}
@@ -0,0 +1,485 @@
+package kline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/indicator"
+)
+
+var (
+	ErrNoData          = errors.New("no kline data")
+	ErrInvalidInterval = errors.New("invalid kline interval")
+)
+
+type Source interface {
+	Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error)
+}
+
+type Config struct {
+	Intervals       []string
+	Limit           int
+	MaxLimit        int
+	IncrementalSize int
+	SpikeMultiple   float64
+	RefreshInterval time.Duration
+}
+
+type Indicators struct {
+	LastClose   float64
+	EMAFast     float64
+	EMASlow     float64
+	RSI         float64
+	ATR         float64
+	VWAP        float64
+	SessionVWAP float64
+	SpikeVWAP   float64
+	SpikeAnchor time.Time
+	SwingLow    float64
+	SwingHigh   float64
+	// Patterns holds the candlestick patterns of the last candle as
+	// indicator.PatternFeatures reports them.
+	Patterns  map[string]float64
+	UpdatedAt time.Time
+	// Warm is false while fewer candles than indicator.Standard.WarmUp are
+	// buffered, e.g. for a newly listed symbol.
+	Warm bool
+}
+
+type series struct {
+	klines     []trade.Kline
+	indicators Indicators
+	fetchedAt  time.Time
+}
+
+type Service struct {
+	cfg     Config
+	source  Source
+	mu      sync.RWMutex
+	running bool
+	watched map[string]struct{}
+	buffers map[string]map[string]*series
+	stopCh  chan struct{}
+}
+
+func New(cfg Config, source Source) *Service {
+	if len(cfg.Intervals) == 0 {
+		cfg.Intervals = []string{"1m", "5m", "15m", "1h"}
+	}
+	if cfg.Limit <= 0 {
+		cfg.Limit = 200
+	}
+	// Buffers backfill at least the warm-up so live indicators are computed
+	// on the same history a backtest would use.
+	if cfg.Limit < indicator.Standard.WarmUp() {
+		cfg.Limit = indicator.Standard.WarmUp()
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = 1500
+	}
+	if cfg.SpikeMultiple <= 0 {
+		cfg.SpikeMultiple = 3
+	}
+	if cfg.IncrementalSize <= 0 {
+		cfg.IncrementalSize = 5
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 5 * time.Second
+	}
+
+	return &Service{
+		cfg:     cfg,
+		source:  source,
+		watched: make(map[string]struct{}),
+		buffers: make(map[string]map[string]*series),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func (s *Service) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	go s.run(ctx)
+	return nil
+}
+
+func (s *Service) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+func (s *Service) run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	s.refreshWatched(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.refreshWatched(ctx)
+		}
+	}
+}
+
+func (s *Service) Watch(symbols ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sym := range symbols {
+		s.watched[sym] = struct{}{}
+	}
+}
+
+func (s *Service) Unwatch(symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.watched, symbol)
+	delete(s.buffers, symbol)
+}
+
+func (s *Service) Watched() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]string, 0, len(s.watched))
+	for sym := range s.watched {
+		result = append(result, sym)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (s *Service) refreshWatched(ctx context.Context) {
+	for _, sym := range s.Watched() {
+		for _, interval := range s.cfg.Intervals {
+			if !s.isStale(sym, interval, 0) {
+				continue
+			}
+			s.refresh(ctx, sym, interval, 0)
+		}
+	}
+}
+
+// Klines returns the most recent limit candles for symbol, serving from the
+// shared buffer while it is fresh and fetching from the exchange otherwise.
+func (s *Service) Klines(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	if limit <= 0 {
+		limit = s.cfg.Limit
+	}
+
+	if s.isStale(symbol, interval, limit) {
+		if err := s.refresh(ctx, symbol, interval, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser := s.buffers[symbol][interval]
+	if ser == nil || len(ser.klines) == 0 {
+		return nil, ErrNoData
+	}
+
+	start := len(ser.klines) - limit
+	if start < 0 {
+		start = 0
+	}
+	result := make([]trade.Kline, len(ser.klines)-start)
+	copy(result, ser.klines[start:])
+	return result, nil
+}
+
+func (s *Service) Indicators(ctx context.Context, symbol, interval string) (Indicators, error) {
+	if s.isStale(symbol, interval, 0) {
+		if err := s.refresh(ctx, symbol, interval, 0); err != nil {
+			return Indicators{}, err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser := s.buffers[symbol][interval]
+	if ser == nil || len(ser.klines) == 0 {
+		return Indicators{}, ErrNoData
+	}
+	return ser.indicators, nil
+}
+
+// Cached returns the buffered candles of symbol at interval, oldest first,
+// and their indicators, without fetching anything. ok is false when nothing
+// is buffered.
+func (s *Service) Cached(symbol, interval string) (klines []trade.Kline, ind Indicators, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser := s.buffers[symbol][interval]
+	if ser == nil || len(ser.klines) == 0 {
+		return nil, Indicators{}, false
+	}
+	klines = make([]trade.Kline, len(ser.klines))
+	copy(klines, ser.klines)
+	return klines, ser.indicators, true
+}
+
+// Buffered returns the intervals buffered for each symbol, sorted.
+func (s *Service) Buffered() map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string][]string, len(s.buffers))
+	for symbol, intervals := range s.buffers {
+		for interval, ser := range intervals {
+			if len(ser.klines) > 0 {
+				out[symbol] = append(out[symbol], interval)
+			}
+		}
+		sort.Strings(out[symbol])
+	}
+	return out
+}
+
+// AnchoredVWAP returns the VWAP of symbol since anchor, pulling enough
+// history to cover the anchored window up to MaxLimit candles.
+func (s *Service) AnchoredVWAP(ctx context.Context, symbol, interval string, anchor time.Time) (float64, error) {
+	d, err := parseInterval(interval)
+	if err != nil {
+		return 0, err
+	}
+
+	limit := int(time.Since(anchor)/d) + 1
+	if limit > s.cfg.MaxLimit {
+		limit = s.cfg.MaxLimit
+	}
+
+	klines, err := s.Klines(ctx, symbol, interval, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	vwap := indicator.AnchoredVWAP(klines, anchor)
+	if vwap == 0 {
+		return 0, ErrNoData
+	}
+	return vwap, nil
+}
+
+func (s *Service) isStale(symbol, interval string, limit int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser := s.buffers[symbol][interval]
+	if ser == nil || len(ser.klines) < limit {
+		return true
+	}
+	return time.Since(ser.fetchedAt) >= maxAge(interval)
+}
+
+// refresh fetches the full window on first use (or when a caller needs more
+// history than is buffered, or the buffer misses the session open) and only
+// the last few candles afterwards, merging them into the rolling buffer by
+// open time.
+func (s *Service) refresh(ctx context.Context, symbol, interval string, limit int) error {
+	d, err := parseInterval(interval)
+	if err != nil {
+		return err
+	}
+	session := s.sessionCandles(d)
+
+	s.mu.RLock()
+	ser := s.buffers[symbol][interval]
+	size := s.cfg.Limit
+	if limit > size {
+		size = limit
+	}
+	if ser != nil && len(ser.klines) >= size && !s.hasGap(ser, interval) && coversSession(ser.klines, session, size) {
+		size = s.cfg.IncrementalSize
+	} else if session > size {
+		size = session
+	}
+	s.mu.RUnlock()
+
+	fetched, err := s.source.Kline(ctx, symbol, interval, size)
+	if err != nil {
+		return err
+	}
+	if len(fetched) == 0 {
+		return ErrNoData
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.buffers[symbol] == nil {
+		s.buffers[symbol] = make(map[string]*series)
+	}
+	ser = s.buffers[symbol][interval]
+	if ser == nil {
+		ser = &series{}
+		s.buffers[symbol][interval] = ser
+	}
+
+	capacity := s.cfg.Limit
+	if limit > capacity {
+		capacity = limit
+	}
+	if session > capacity {
+		capacity = session
+	}
+	ser.klines = merge(ser.klines, fetched, capacity)
+	ser.fetchedAt = time.Now()
+	ser.indicators = compute(ser.klines, s.cfg.SpikeMultiple)
+	return nil
+}
+
+// sessionCandles is how many candles of duration d the buffer holds to reach
+// back to the open of the current UTC session, or 0 when that takes more
+// than MaxLimit and the session VWAP is left unavailable.
+func (s *Service) sessionCandles(d time.Duration) int {
+	now := time.Now()
+	n := int(now.Sub(indicator.SessionStart(now))/d) + 1
+	if n > s.cfg.MaxLimit {
+		return 0
+	}
+	return n
+}
+
+// coversSession reports whether klines reach back to the session open, or
+// whether they cannot within size candles anyway.
+func coversSession(klines []trade.Kline, session, size int) bool {
+	if session <= size || len(klines) == 0 {
+		return true
+	}
+	last := klines[len(klines)-1].OpenTime
+	return !klines[0].OpenTime.After(indicator.SessionStart(last))
+}
+
+// hasGap reports whether more candles have closed since the last fetch than
+// an incremental refresh would pull, which would leave a hole in the buffer.
+func (s *Service) hasGap(ser *series, interval string) bool {
+	d, err := parseInterval(interval)
+	if err != nil {
+		return true
+	}
+	return time.Since(ser.fetchedAt) >= time.Duration(s.cfg.IncrementalSize-1)*d
+}
+
+func merge(existing, fetched []trade.Kline, capacity int) []trade.Kline {
+	byOpen := make(map[int64]int, len(existing))
+	merged := make([]trade.Kline, len(existing), len(existing)+len(fetched))
+	copy(merged, existing)
+	for i, k := range merged {
+		byOpen[k.OpenTime.UnixMilli()] = i
+	}
+
+	for _, k := range fetched {
+		key := k.OpenTime.UnixMilli()
+		if i, ok := byOpen[key]; ok {
+			merged[i] = k
+			continue
+		}
+		byOpen[key] = len(merged)
+		merged = append(merged, k)
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].OpenTime.Before(merged[j].OpenTime)
+	})
+
+	if len(merged) > capacity {
+		merged = merged[len(merged)-capacity:]
+	}
+	return merged
+}
+
+func compute(klines []trade.Kline, spikeMultiple float64) Indicators {
+	last := klines[len(klines)-1]
+	values, warm := indicator.Standard.Compute(klines)
+
+	ind := Indicators{
+		LastClose: last.Close,
+		EMAFast:   values[indicator.EMAFast],
+		EMASlow:   values[indicator.EMASlow],
+		RSI:       values[indicator.RSI14],
+		ATR:       values[indicator.ATR14],
+		VWAP:      indicator.VWAP(klines),
+		UpdatedAt: time.Now(),
+		Warm:      warm,
+	}
+	// Left at 0 when the buffer misses the start of the session.
+	ind.SessionVWAP, _ = indicator.SessionVWAP(klines)
+	ind.SwingLow, ind.SwingHigh = indicator.SwingRange(klines, indicator.SwingLookback)
+	ind.Patterns = indicator.PatternFeatures(indicator.DetectPatterns(klines))
+
+	if anchor, ok := indicator.SpikeAnchor(klines, spikeMultiple); ok {
+		ind.SpikeAnchor = anchor
+		ind.SpikeVWAP = indicator.AnchoredVWAP(klines, anchor)
+	}
+
+	return ind
+}
+
+// maxAge is how long a buffered series is served before it is refreshed:
+// a quarter of the candle duration, bounded to keep 1m data responsive and
+// hourly data from going quiet for too long.
+func maxAge(interval string) time.Duration {
+	d, err := parseInterval(interval)
+	if err != nil {
+		return 0
+	}
+	age := d / 4
+	if age < 5*time.Second {
+		age = 5 * time.Second
+	}
+	if age > 5*time.Minute {
+		age = 5 * time.Minute
+	}
+	return age
+}
+
+func parseInterval(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, ErrInvalidInterval
+	}
+
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, ErrInvalidInterval
+	}
+
+	switch interval[len(interval)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute, nil
+	case 'h':
+		return time.Duration(n) * time.Hour, nil
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, nil
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, nil
+	}
+	return 0, ErrInvalidInterval
+}
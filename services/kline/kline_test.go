@@ -0,0 +1,81 @@
+package kline
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/indicator"
+)
+
+type historySource struct {
+	klines []trade.Kline
+}
+
+func (h *historySource) Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	if limit > len(h.klines) {
+		limit = len(h.klines)
+	}
+	return h.klines[len(h.klines)-limit:], nil
+}
+
+func history(n int) []trade.Kline {
+	start := time.Now().Truncate(time.Minute).Add(-time.Duration(n) * time.Minute)
+	klines := make([]trade.Kline, n)
+	price := 100.0
+	for i := range klines {
+		price += math.Sin(float64(i)/7)*0.8 + 0.05
+		klines[i] = trade.Kline{
+			OpenTime: start.Add(time.Duration(i) * time.Minute),
+			Open:     price - 0.2,
+			High:     price + 0.5 + math.Abs(math.Cos(float64(i)/3)),
+			Low:      price - 0.6,
+			Close:    price,
+			Volume:   10 + float64(i%5),
+		}
+	}
+	return klines
+}
+
+// Live indicators must match a backtest stepping through the same candles,
+// whatever history the live buffer holds.
+func TestLiveIndicatorsMatchBacktest(t *testing.T) {
+	src := &historySource{klines: history(800)}
+
+	var backtest indicator.Values
+	indicator.Standard.Replay(src.klines, func(i int, v indicator.Values) {
+		if i == len(src.klines)-1 {
+			backtest = v
+		}
+	})
+
+	for _, limit := range []int{0, 200, 750} {
+		svc := New(Config{Limit: limit, RefreshInterval: time.Hour}, src)
+		live, err := svc.Indicators(context.Background(), "BTCUSDT", "1m")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !live.Warm {
+			t.Errorf("limit %d: indicators not warm", limit)
+		}
+		got := indicator.Values{
+			indicator.EMAFast: live.EMAFast,
+			indicator.EMASlow: live.EMASlow,
+			indicator.RSI14:   live.RSI,
+			indicator.ATR14:   live.ATR,
+		}
+		for name, want := range backtest {
+			if got[name] != want {
+				t.Errorf("limit %d: live %s = %v, backtest %v", limit, name, got[name], want)
+			}
+		}
+	}
+
+	// A buffer shorter than the warm-up is reported cold.
+	short := New(Config{}, &historySource{klines: history(50)})
+	if ind, _ := short.Indicators(context.Background(), "NEWUSDT", "1m"); ind.Warm {
+		t.Error("expected a 50 candle buffer to be cold")
+	}
+}
@@ -0,0 +1,51 @@
+package kline
+
+import (
+	"context"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// Momentum is the short-horizon move of a symbol, from its 1m candles.
+// Returns are in percent over the last 1, 5 and 15 minutes. Acceleration is
+// the last 5 minutes' rate minus the rate of the 10 before them, in percent
+// per minute: positive while the move speeds up.
+type Momentum struct {
+	Return1m     float64
+	Return5m     float64
+	Return15m    float64
+	Acceleration float64
+}
+
+// Momentum computes symbol's short-horizon momentum from the 1m cache.
+func (s *Service) Momentum(ctx context.Context, symbol string) (Momentum, error) {
+	klines, err := s.Klines(ctx, symbol, "1m", 16)
+	if err != nil {
+		return Momentum{}, err
+	}
+	m, ok := ComputeMomentum(klines)
+	if !ok {
+		return Momentum{}, ErrNoData
+	}
+	return m, nil
+}
+
+// ComputeMomentum derives Momentum from at least 16 consecutive 1m candles,
+// the last being the most recent.
+func ComputeMomentum(klines []trade.Kline) (Momentum, bool) {
+	if len(klines) < 16 {
+		return Momentum{}, false
+	}
+	last := len(klines) - 1
+	ret := func(minutes int) float64 {
+		from := klines[last-minutes].Close
+		if from <= 0 {
+			return 0
+		}
+		return (klines[last].Close - from) / from * 100
+	}
+
+	m := Momentum{Return1m: ret(1), Return5m: ret(5), Return15m: ret(15)}
+	m.Acceleration = m.Return5m/5 - (m.Return15m-m.Return5m)/10
+	return m, klines[last].Close > 0 && klines[last-15].Close > 0
+}
@@ -0,0 +1,297 @@
+// Package levels finds support and resistance. Swing highs and lows and the
+// high-volume nodes of the window's volume profile are points; points
+// within a fraction of an ATR of each other cluster into one level, which is
+// as strong as the swings and volume it gathered. The levels feed breakout
+// detection, stop placement and the brain's context.
+package levels
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/indicator"
+)
+
+type KlineSource interface {
+	Klines(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error)
+}
+
+// Config sets the window and the clustering. A swing is a candle whose high
+// (or low) is the extreme of Pivot candles either side; a high-volume node
+// is a volume profile bin, of Bins across the window's range, holding at
+// least NodeMultiple times the average bin's volume. Points within
+// Tolerance ATRs merge.
+type Config struct {
+	Interval     string
+	Limit        int
+	Pivot        int
+	Bins         int
+	NodeMultiple float64
+	Tolerance    float64
+	// MaxLevels keeps only the strongest levels.
+	MaxLevels int
+	// MinStrength is the strength a level needs to count as support or
+	// resistance for breakouts and stops.
+	MinStrength float64
+}
+
+// Level is a price zone from Low to High around Price, the weighted centre
+// of its points. Touches counts its swings and Volume is the share of the
+// window's volume traded at its nodes. Strength is relative to the
+// strongest level of the window, which has 1.
+type Level struct {
+	Price    float64 `json:"price"`
+	Low      float64 `json:"low"`
+	High     float64 `json:"high"`
+	Touches  int     `json:"touches"`
+	Volume   float64 `json:"volume"`
+	Strength float64 `json:"strength"`
+}
+
+// Set is the levels of a window, lowest first, and the window's last close
+// and ATR.
+type Set struct {
+	Price  float64
+	ATR    float64
+	Levels []Level
+}
+
+// Support returns the nearest level wholly below price with at least
+// minStrength.
+func (s Set) Support(minStrength float64) (Level, bool) {
+	for i := len(s.Levels) - 1; i >= 0; i-- {
+		if l := s.Levels[i]; l.High < s.Price && l.Strength >= minStrength {
+			return l, true
+		}
+	}
+	return Level{}, false
+}
+
+// Resistance returns the nearest level wholly above price with at least
+// minStrength.
+func (s Set) Resistance(minStrength float64) (Level, bool) {
+	for _, l := range s.Levels {
+		if l.Low > s.Price && l.Strength >= minStrength {
+			return l, true
+		}
+	}
+	return Level{}, false
+}
+
+// Breakout is a close through a level: Direction is 1 through resistance
+// and -1 through support.
+type Breakout struct {
+	Direction int
+	Level     Level
+	Close     float64
+}
+
+type point struct {
+	price  float64
+	weight float64
+	swing  bool
+	volume float64
+}
+
+// Find clusters the levels of klines, oldest first. It needs more than
+// 2*cfg.Pivot candles; fewer yield an empty set.
+func Find(klines []trade.Kline, cfg Config) Set {
+	cfg = withDefaults(cfg)
+	if len(klines) == 0 {
+		return Set{}
+	}
+	set := Set{Price: klines[len(klines)-1].Close, ATR: indicator.ATR(klines, 14)}
+	if len(klines) <= 2*cfg.Pivot {
+		return set
+	}
+
+	nodes, total := volumeNodes(klines, cfg.Bins, cfg.NodeMultiple)
+	points := append(swings(klines, cfg.Pivot), nodes...)
+	if len(points) == 0 {
+		return set
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].price < points[j].price })
+
+	tol := cfg.Tolerance * set.ATR
+	if tol <= 0 {
+		tol = set.Price * 0.002
+	}
+
+	var levels []Level
+	var weights []float64
+	for _, p := range points {
+		n := len(levels)
+		if n > 0 && p.price-levels[n-1].Price <= tol {
+			l, w := &levels[n-1], weights[n-1]
+			l.Price = (l.Price*w + p.price*p.weight) / (w + p.weight)
+			l.High = p.price
+			weights[n-1] += p.weight
+			add(l, p, total)
+			continue
+		}
+		l := Level{Price: p.price, Low: p.price, High: p.price}
+		add(&l, p, total)
+		levels = append(levels, l)
+		weights = append(weights, p.weight)
+	}
+
+	strongest := 0.0
+	for _, w := range weights {
+		strongest = math.Max(strongest, w)
+	}
+	for i := range levels {
+		levels[i].Strength = weights[i] / strongest
+	}
+	if len(levels) > cfg.MaxLevels {
+		sort.Slice(levels, func(i, j int) bool { return levels[i].Strength > levels[j].Strength })
+		levels = levels[:cfg.MaxLevels]
+		sort.Slice(levels, func(i, j int) bool { return levels[i].Price < levels[j].Price })
+	}
+	set.Levels = levels
+	return set
+}
+
+func add(l *Level, p point, total float64) {
+	if p.swing {
+		l.Touches++
+	}
+	if total > 0 {
+		l.Volume += p.volume / total
+	}
+}
+
+// DetectBreakout reports whether the last candle of klines closed through a
+// level of at least minStrength found on the candles before it.
+func DetectBreakout(klines []trade.Kline, cfg Config, minStrength float64) (Breakout, bool) {
+	if len(klines) < 2 {
+		return Breakout{}, false
+	}
+	last, prev := klines[len(klines)-1], klines[len(klines)-2]
+	set := Find(klines[:len(klines)-1], cfg)
+	if r, ok := set.Resistance(minStrength); ok && prev.Close <= r.High && last.Close > r.High {
+		return Breakout{Direction: 1, Level: r, Close: last.Close}, true
+	}
+	if s, ok := set.Support(minStrength); ok && prev.Close >= s.Low && last.Close < s.Low {
+		return Breakout{Direction: -1, Level: s, Close: last.Close}, true
+	}
+	return Breakout{}, false
+}
+
+// swings returns the pivot highs and lows, one point each.
+func swings(klines []trade.Kline, pivot int) []point {
+	var out []point
+	for i := pivot; i < len(klines)-pivot; i++ {
+		high, low := true, true
+		for j := i - pivot; j <= i+pivot; j++ {
+			if j == i {
+				continue
+			}
+			high = high && klines[j].High < klines[i].High
+			low = low && klines[j].Low > klines[i].Low
+		}
+		if high {
+			out = append(out, point{price: klines[i].High, weight: 1, swing: true})
+		}
+		if low {
+			out = append(out, point{price: klines[i].Low, weight: 1, swing: true})
+		}
+	}
+	return out
+}
+
+// volumeNodes bins each candle's volume at its typical price and returns
+// the bins holding at least multiple times the average, weighted by how
+// many averages they hold, with the window's total volume.
+func volumeNodes(klines []trade.Kline, bins int, multiple float64) ([]point, float64) {
+	lo, hi := klines[0].Low, klines[0].High
+	for _, k := range klines {
+		lo, hi = math.Min(lo, k.Low), math.Max(hi, k.High)
+	}
+	if hi <= lo {
+		return nil, 0
+	}
+
+	width := (hi - lo) / float64(bins)
+	volume := make([]float64, bins)
+	total := 0.0
+	for _, k := range klines {
+		i := int(((k.High+k.Low+k.Close)/3 - lo) / width)
+		if i >= bins {
+			i = bins - 1
+		}
+		volume[i] += k.Volume
+		total += k.Volume
+	}
+	if total <= 0 {
+		return nil, 0
+	}
+
+	avg := total / float64(bins)
+	var out []point
+	for i, v := range volume {
+		if v >= multiple*avg {
+			out = append(out, point{price: lo + width*(float64(i)+0.5), weight: v / avg, volume: v})
+		}
+	}
+	return out, total
+}
+
+func withDefaults(cfg Config) Config {
+	if cfg.Interval == "" {
+		cfg.Interval = "15m"
+	}
+	if cfg.Limit <= 0 {
+		cfg.Limit = 300
+	}
+	if cfg.Pivot <= 0 {
+		cfg.Pivot = 3
+	}
+	if cfg.Bins <= 0 {
+		cfg.Bins = 48
+	}
+	if cfg.NodeMultiple <= 0 {
+		cfg.NodeMultiple = 1.5
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = 0.5
+	}
+	if cfg.MaxLevels <= 0 {
+		cfg.MaxLevels = 10
+	}
+	if cfg.MinStrength <= 0 {
+		cfg.MinStrength = 0.3
+	}
+	return cfg
+}
+
+// Service finds levels on the shared candle cache.
+type Service struct {
+	cfg    Config
+	klines KlineSource
+}
+
+func New(cfg Config, klines KlineSource) *Service {
+	return &Service{cfg: withDefaults(cfg), klines: klines}
+}
+
+// Levels finds the current levels of symbol.
+func (s *Service) Levels(ctx context.Context, symbol string) (Set, error) {
+	klines, err := s.klines.Klines(ctx, symbol, s.cfg.Interval, s.cfg.Limit)
+	if err != nil {
+		return Set{}, err
+	}
+	return Find(klines, s.cfg), nil
+}
+
+// Breakout reports whether symbol's last candle closed through a level of
+// at least MinStrength.
+func (s *Service) Breakout(ctx context.Context, symbol string) (Breakout, bool, error) {
+	klines, err := s.klines.Klines(ctx, symbol, s.cfg.Interval, s.cfg.Limit+1)
+	if err != nil {
+		return Breakout{}, false, err
+	}
+	b, ok := DetectBreakout(klines, s.cfg, s.cfg.MinStrength)
+	return b, ok, nil
+}
@@ -0,0 +1,85 @@
+package levels
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// ranging swings between a low of 100 and a high of 110 every 8 candles,
+// ending on a close of 105. volumeAt105 is the volume of the candles that
+// close at 105, the others trading 1.
+func ranging(n int, volumeAt105 float64) []trade.Kline {
+	closes := []float64{101, 103, 105, 107, 109, 107, 105, 103}
+	start := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	klines := make([]trade.Kline, n)
+	for i := range klines {
+		c := closes[i%len(closes)]
+		v := 1.0
+		if c == 105 {
+			v = volumeAt105
+		}
+		klines[i] = trade.Kline{OpenTime: start.Add(time.Duration(i) * 15 * time.Minute), Open: c, High: c + 1, Low: c - 1, Close: c, Volume: v}
+	}
+	return klines
+}
+
+func TestFind_ClustersSwingsIntoSupportAndResistance(t *testing.T) {
+	cfg := Config{NodeMultiple: 1000}
+	set := Find(ranging(195, 1), cfg)
+	if set.Price != 105 || len(set.Levels) != 2 {
+		t.Fatalf("set = %+v", set)
+	}
+
+	support, ok := set.Support(0.3)
+	if !ok || support.Price != 100 || support.Touches < 20 || support.Strength < 0.9 {
+		t.Errorf("support = %+v, %v", support, ok)
+	}
+	resistance, ok := set.Resistance(0.3)
+	if !ok || resistance.Price != 110 || resistance.Touches < 20 {
+		t.Errorf("resistance = %+v, %v", resistance, ok)
+	}
+
+	breakout := append(ranging(195, 1), trade.Kline{Open: 105, High: 112.5, Low: 104.5, Close: 112, Volume: 1})
+	b, ok := DetectBreakout(breakout, cfg, 0.3)
+	if !ok || b.Direction != 1 || b.Level.Price != 110 || b.Close != 112 {
+		t.Errorf("breakout = %+v, %v", b, ok)
+	}
+	if _, ok := DetectBreakout(ranging(196, 1), cfg, 0.3); ok {
+		t.Error("a close inside the range is not a breakout")
+	}
+}
+
+func TestFind_HighVolumeNode(t *testing.T) {
+	set := Find(ranging(195, 100), Config{})
+	var node *Level
+	for i, l := range set.Levels {
+		if math.Abs(l.Price-105) < 0.5 {
+			node = &set.Levels[i]
+		}
+	}
+	if node == nil || node.Touches != 0 || node.Volume < 0.9 || node.Strength != 1 {
+		t.Fatalf("expected the 105 volume node as the strongest level, got %+v", set.Levels)
+	}
+}
+
+type fakeKlines []trade.Kline
+
+func (f fakeKlines) Klines(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	if len(f) > limit {
+		return f[len(f)-limit:], nil
+	}
+	return f, nil
+}
+
+func TestService_Breakout(t *testing.T) {
+	klines := append(ranging(195, 1), trade.Kline{Open: 105, High: 105.5, Low: 97.5, Close: 98, Volume: 1})
+	svc := New(Config{NodeMultiple: 1000}, fakeKlines(klines))
+	b, ok, err := svc.Breakout(context.Background(), "ETHUSDT")
+	if err != nil || !ok || b.Direction != -1 || b.Level.Price != 100 {
+		t.Fatalf("breakout = %+v, %v, %v", b, ok, err)
+	}
+}
@@ -0,0 +1,197 @@
+package liquidation
+
+import (
+	"sync"
+	"time"
+)
+
+// Side of the forced order: SELL liquidates a long, BUY liquidates a short.
+type Event struct {
+	Symbol   string
+	Side     string
+	Price    float64
+	Quantity float64
+	Time     time.Time
+}
+
+func (e Event) Notional() float64 {
+	return e.Price * e.Quantity
+}
+
+type Config struct {
+	Window          time.Duration
+	SymbolThreshold float64
+	MarketThreshold float64
+	Cooldown        time.Duration
+}
+
+type Stats struct {
+	Symbol          string
+	LongLiquidated  float64
+	ShortLiquidated float64
+	Count           int
+	Cascade         bool
+	CascadeUntil    time.Time
+}
+
+func (s Stats) Total() float64 {
+	return s.LongLiquidated + s.ShortLiquidated
+}
+
+type Monitor struct {
+	cfg         Config
+	mu          sync.RWMutex
+	events      []Event
+	symbolUntil map[string]time.Time
+	marketUntil time.Time
+	handlers    []func(Stats)
+}
+
+func New(cfg Config) *Monitor {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.SymbolThreshold <= 0 {
+		cfg.SymbolThreshold = 1_000_000
+	}
+	if cfg.MarketThreshold <= 0 {
+		cfg.MarketThreshold = 10_000_000
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 5 * time.Minute
+	}
+
+	return &Monitor{
+		cfg:         cfg,
+		symbolUntil: make(map[string]time.Time),
+	}
+}
+
+// OnCascade registers a callback fired when a symbol (or the market, with an
+// empty Symbol) crosses its liquidation threshold.
+func (m *Monitor) OnCascade(fn func(Stats)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, fn)
+}
+
+func (m *Monitor) Add(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	m.mu.Lock()
+	m.events = append(m.events, e)
+	m.prune(e.Time)
+
+	symbol := m.stats(e.Symbol)
+	market := m.stats("")
+
+	var triggered []Stats
+	until := e.Time.Add(m.cfg.Cooldown)
+	if symbol.Total() >= m.cfg.SymbolThreshold {
+		if m.symbolUntil[e.Symbol].Before(e.Time) {
+			symbol.Cascade, symbol.CascadeUntil = true, until
+			triggered = append(triggered, symbol)
+		}
+		m.symbolUntil[e.Symbol] = until
+	}
+	if market.Total() >= m.cfg.MarketThreshold {
+		if m.marketUntil.Before(e.Time) {
+			market.Cascade, market.CascadeUntil = true, until
+			triggered = append(triggered, market)
+		}
+		m.marketUntil = until
+	}
+
+	handlers := make([]func(Stats), len(m.handlers))
+	copy(handlers, m.handlers)
+	m.mu.Unlock()
+
+	for _, s := range triggered {
+		for _, fn := range handlers {
+			fn(s)
+		}
+	}
+}
+
+func (m *Monitor) prune(now time.Time) {
+	cutoff := now.Add(-m.cfg.Window)
+	i := 0
+	for i < len(m.events) && m.events[i].Time.Before(cutoff) {
+		i++
+	}
+	m.events = m.events[i:]
+}
+
+// stats aggregates the current window for symbol, or the whole market when
+// symbol is empty. Callers must hold the lock.
+func (m *Monitor) stats(symbol string) Stats {
+	s := Stats{Symbol: symbol}
+	for _, e := range m.events {
+		if symbol != "" && e.Symbol != symbol {
+			continue
+		}
+		if e.Side == "SELL" {
+			s.LongLiquidated += e.Notional()
+		} else {
+			s.ShortLiquidated += e.Notional()
+		}
+		s.Count++
+	}
+
+	if symbol == "" {
+		s.CascadeUntil = m.marketUntil
+	} else {
+		s.CascadeUntil = m.symbolUntil[symbol]
+	}
+	s.Cascade = time.Now().Before(s.CascadeUntil)
+	return s
+}
+
+// CascadeRisk reports whether new entries in symbol should be paused, either
+// because the symbol itself or the market as a whole is in a liquidation storm.
+func (m *Monitor) CascadeRisk(symbol string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	return now.Before(m.marketUntil) || now.Before(m.symbolUntil[symbol])
+}
+
+func (m *Monitor) MarketCascade() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return time.Now().Before(m.marketUntil)
+}
+
+func (m *Monitor) SymbolStats(symbol string) Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stats(symbol)
+}
+
+func (m *Monitor) MarketStats() Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stats("")
+}
+
+// TightenStop moves a position's stop toward price so it is factor of its
+// former distance away, for a long when long is set and a short otherwise.
+// It never loosens the stop, and leaves a stop price already went through.
+func TightenStop(long bool, price, stop, factor float64) float64 {
+	if factor <= 0 || factor >= 1 || price <= 0 || stop <= 0 {
+		return stop
+	}
+	if long {
+		if stop >= price {
+			return stop
+		}
+		return price - (price-stop)*factor
+	}
+	if stop <= price {
+		return stop
+	}
+	return price + (stop-price)*factor
+}
@@ -0,0 +1,86 @@
+package liquidation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_SymbolCascade(t *testing.T) {
+	m := New(Config{SymbolThreshold: 1000, MarketThreshold: 100000})
+
+	var alerts []Stats
+	m.OnCascade(func(s Stats) {
+		alerts = append(alerts, s)
+	})
+
+	now := time.Now()
+	m.Add(Event{Symbol: "ETHUSDT", Side: "SELL", Price: 100, Quantity: 5, Time: now})
+	if m.CascadeRisk("ETHUSDT") {
+		t.Fatal("cascade flagged below threshold")
+	}
+
+	m.Add(Event{Symbol: "ETHUSDT", Side: "SELL", Price: 100, Quantity: 6, Time: now})
+	if !m.CascadeRisk("ETHUSDT") {
+		t.Fatal("expected cascade risk after crossing threshold")
+	}
+	if m.CascadeRisk("BTCUSDT") || m.MarketCascade() {
+		t.Error("symbol cascade should not flag other symbols or the market")
+	}
+
+	stats := m.SymbolStats("ETHUSDT")
+	if stats.LongLiquidated != 1100 || stats.Count != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	m.Add(Event{Symbol: "ETHUSDT", Side: "SELL", Price: 100, Quantity: 1, Time: now})
+	if len(alerts) != 1 {
+		t.Errorf("expected a single cascade alert while flag is active, got %d", len(alerts))
+	}
+}
+
+func TestMonitor_MarketCascadeFlagsAllSymbols(t *testing.T) {
+	m := New(Config{SymbolThreshold: 1e9, MarketThreshold: 1000})
+
+	now := time.Now()
+	m.Add(Event{Symbol: "AUSDT", Side: "BUY", Price: 10, Quantity: 60, Time: now})
+	m.Add(Event{Symbol: "BUSDT", Side: "SELL", Price: 10, Quantity: 60, Time: now})
+
+	if !m.MarketCascade() || !m.CascadeRisk("CUSDT") {
+		t.Error("expected market-wide cascade to flag every symbol")
+	}
+
+	stats := m.MarketStats()
+	if stats.ShortLiquidated != 600 || stats.LongLiquidated != 600 {
+		t.Errorf("unexpected market stats: %+v", stats)
+	}
+}
+
+func TestMonitor_WindowPrunesOldEvents(t *testing.T) {
+	m := New(Config{Window: time.Minute, SymbolThreshold: 1000})
+
+	now := time.Now()
+	m.Add(Event{Symbol: "ETHUSDT", Side: "SELL", Price: 100, Quantity: 6, Time: now.Add(-2 * time.Minute)})
+	m.Add(Event{Symbol: "ETHUSDT", Side: "SELL", Price: 100, Quantity: 6, Time: now})
+
+	if m.CascadeRisk("ETHUSDT") {
+		t.Error("events outside the window should not count toward the threshold")
+	}
+}
+
+func TestTightenStop(t *testing.T) {
+	for _, tc := range []struct {
+		long                bool
+		price, stop, factor float64
+		want                float64
+	}{
+		{true, 100, 90, 0.5, 95},
+		{false, 100, 110, 0.5, 105},
+		{true, 100, 101, 0.5, 101},
+		{true, 100, 90, 0, 90},
+		{true, 100, 90, 1.5, 90},
+	} {
+		if got := TightenStop(tc.long, tc.price, tc.stop, tc.factor); got != tc.want {
+			t.Errorf("TightenStop(%v, %v, %v, %v) = %v, want %v", tc.long, tc.price, tc.stop, tc.factor, got, tc.want)
+		}
+	}
+}
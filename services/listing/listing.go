@@ -0,0 +1,320 @@
+package listing
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventNewListing EventType = "new_listing"
+	EventDelisting  EventType = "delisting"
+	// EventFlattenFailed reports a delisted symbol whose position could not
+	// be closed. It is retried on every refresh until it succeeds.
+	EventFlattenFailed EventType = "flatten_failed"
+)
+
+type Symbol struct {
+	Symbol       string
+	ContractType string
+	QuoteAsset   string
+	Status       string
+	OnboardDate  time.Time
+	DeliveryDate time.Time
+}
+
+type Event struct {
+	Type         EventType
+	Symbol       string
+	Reason       string
+	DeliveryDate time.Time
+	DetectedAt   time.Time
+}
+
+type SymbolSource interface {
+	GetSymbols(ctx context.Context) ([]Symbol, error)
+}
+
+type PositionCloser interface {
+	ClosePosition(ctx context.Context, symbol string) error
+}
+
+type Config struct {
+	Interval         time.Duration
+	ContractType     string
+	QuoteAsset       string
+	NewListingWindow time.Duration
+	DelistingHorizon time.Duration
+	FlattenOnDelist  bool
+}
+
+type Monitor struct {
+	cfg        Config
+	source     SymbolSource
+	closer     PositionCloser
+	mu         sync.RWMutex
+	running    bool
+	seeded     bool
+	known      map[string]Symbol
+	newListing map[string]time.Time
+	blacklist  map[string]Event
+	unflat     map[string]bool
+	handlers   []func(Event)
+	stopCh     chan struct{}
+}
+
+func New(cfg Config, source SymbolSource, closer PositionCloser) *Monitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.ContractType == "" {
+		cfg.ContractType = "PERPETUAL"
+	}
+	if cfg.QuoteAsset == "" {
+		cfg.QuoteAsset = "USDT"
+	}
+	if cfg.NewListingWindow <= 0 {
+		cfg.NewListingWindow = 72 * time.Hour
+	}
+	if cfg.DelistingHorizon <= 0 {
+		cfg.DelistingHorizon = 30 * 24 * time.Hour
+	}
+
+	return &Monitor{
+		cfg:        cfg,
+		source:     source,
+		closer:     closer,
+		known:      make(map[string]Symbol),
+		newListing: make(map[string]time.Time),
+		blacklist:  make(map[string]Event),
+		unflat:     make(map[string]bool),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+func (m *Monitor) OnEvent(fn func(Event)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, fn)
+}
+
+func (m *Monitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	if _, err := m.Refresh(ctx); err != nil {
+		m.mu.Lock()
+		m.running = false
+		m.mu.Unlock()
+		return err
+	}
+
+	go m.run(ctx)
+	return nil
+}
+
+func (m *Monitor) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return nil
+	}
+
+	m.running = false
+	close(m.stopCh)
+	return nil
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh pulls the current symbol list and diffs it against the previous
+// snapshot. On the first call only symbols whose onboard date falls inside
+// NewListingWindow are reported as new, so a restart does not flag the whole
+// exchange as freshly listed.
+func (m *Monitor) Refresh(ctx context.Context) ([]Event, error) {
+	symbols, err := m.source.GetSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// An empty exchange is a bad response, not every symbol delisted.
+	if len(symbols) == 0 {
+		return nil, errors.New("symbol source returned no symbols")
+	}
+
+	now := time.Now()
+	events := make([]Event, 0)
+
+	m.mu.Lock()
+	current := make(map[string]Symbol, len(symbols))
+	for _, s := range symbols {
+		if !m.tracks(s) {
+			continue
+		}
+		current[s.Symbol] = s
+
+		if _, listed := m.blacklist[s.Symbol]; !listed {
+			if reason, delisting := m.isDelisting(s, now); delisting {
+				ev := Event{Type: EventDelisting, Symbol: s.Symbol, Reason: reason, DeliveryDate: s.DeliveryDate, DetectedAt: now}
+				m.blacklist[s.Symbol] = ev
+				delete(m.newListing, s.Symbol)
+				events = append(events, ev)
+				continue
+			}
+		}
+
+		if _, seen := m.known[s.Symbol]; seen || s.Status != "TRADING" {
+			continue
+		}
+		if m.seeded || (!s.OnboardDate.IsZero() && now.Sub(s.OnboardDate) < m.cfg.NewListingWindow) {
+			m.newListing[s.Symbol] = now
+			events = append(events, Event{Type: EventNewListing, Symbol: s.Symbol, Reason: "symbol added to exchangeInfo", DetectedAt: now})
+		}
+	}
+
+	for sym := range m.known {
+		if _, ok := current[sym]; ok {
+			continue
+		}
+		if _, listed := m.blacklist[sym]; listed {
+			continue
+		}
+		ev := Event{Type: EventDelisting, Symbol: sym, Reason: "symbol removed from exchangeInfo", DetectedAt: now}
+		m.blacklist[sym] = ev
+		delete(m.newListing, sym)
+		events = append(events, ev)
+	}
+
+	for sym, addedAt := range m.newListing {
+		if now.Sub(addedAt) >= m.cfg.NewListingWindow {
+			delete(m.newListing, sym)
+		}
+	}
+
+	flatten := make([]string, 0)
+	if m.cfg.FlattenOnDelist && m.closer != nil {
+		for sym := range m.unflat {
+			flatten = append(flatten, sym)
+		}
+		sort.Strings(flatten)
+		for _, ev := range events {
+			if ev.Type == EventDelisting && !m.unflat[ev.Symbol] {
+				flatten = append(flatten, ev.Symbol)
+			}
+		}
+	}
+
+	m.known = current
+	m.seeded = true
+	handlers := make([]func(Event), len(m.handlers))
+	copy(handlers, m.handlers)
+	m.mu.Unlock()
+
+	for _, sym := range flatten {
+		err := m.closer.ClosePosition(ctx, sym)
+		m.mu.Lock()
+		if err != nil {
+			m.unflat[sym] = true
+		} else {
+			delete(m.unflat, sym)
+		}
+		m.mu.Unlock()
+		if err != nil {
+			events = append(events, Event{Type: EventFlattenFailed, Symbol: sym, Reason: err.Error(), DetectedAt: now})
+		}
+	}
+
+	for _, ev := range events {
+		for _, fn := range handlers {
+			fn(ev)
+		}
+	}
+
+	return events, nil
+}
+
+func (m *Monitor) tracks(s Symbol) bool {
+	if m.cfg.ContractType != "" && s.ContractType != m.cfg.ContractType {
+		return false
+	}
+	if m.cfg.QuoteAsset != "" && s.QuoteAsset != m.cfg.QuoteAsset {
+		return false
+	}
+	return true
+}
+
+func (m *Monitor) isDelisting(s Symbol, now time.Time) (string, bool) {
+	prev, seen := m.known[s.Symbol]
+	if seen && prev.Status == "TRADING" && s.Status != "TRADING" {
+		return "status changed to " + s.Status, true
+	}
+	// Perpetuals carry a far-future delivery date; Binance pulls it in to the
+	// settlement time once a delisting is announced.
+	if !s.DeliveryDate.IsZero() && s.DeliveryDate.Sub(now) < m.cfg.DelistingHorizon {
+		return "delivery date scheduled for " + s.DeliveryDate.UTC().Format(time.RFC3339), true
+	}
+	return "", false
+}
+
+func (m *Monitor) IsBlacklisted(symbol string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.blacklist[symbol]
+	return ok
+}
+
+func (m *Monitor) IsNewListing(symbol string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.newListing[symbol]
+	return ok
+}
+
+func (m *Monitor) NewListings() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]string, 0, len(m.newListing))
+	for sym := range m.newListing {
+		result = append(result, sym)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (m *Monitor) Blacklist() []Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Event, 0, len(m.blacklist))
+	for _, ev := range m.blacklist {
+		result = append(result, ev)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
@@ -0,0 +1,184 @@
+package listing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockSymbolSource struct {
+	symbols []Symbol
+	err     error
+}
+
+func (m *mockSymbolSource) GetSymbols(ctx context.Context) ([]Symbol, error) {
+	return m.symbols, m.err
+}
+
+type mockCloser struct {
+	closed []string
+	err    error
+}
+
+func (m *mockCloser) ClosePosition(ctx context.Context, symbol string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.closed = append(m.closed, symbol)
+	return nil
+}
+
+func perp(symbol, status string) Symbol {
+	return Symbol{
+		Symbol:       symbol,
+		ContractType: "PERPETUAL",
+		QuoteAsset:   "USDT",
+		Status:       status,
+		OnboardDate:  time.Now().Add(-365 * 24 * time.Hour),
+		DeliveryDate: time.Date(2100, 12, 25, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestMonitor_FirstRefreshOnlyFlagsRecentOnboards(t *testing.T) {
+	fresh := perp("NEWUSDT", "TRADING")
+	fresh.OnboardDate = time.Now().Add(-2 * time.Hour)
+
+	source := &mockSymbolSource{symbols: []Symbol{perp("BTCUSDT", "TRADING"), fresh}}
+	m := New(Config{}, source, nil)
+
+	events, err := m.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	if len(events) != 1 || events[0].Type != EventNewListing || events[0].Symbol != "NEWUSDT" {
+		t.Fatalf("expected single new listing for NEWUSDT, got %+v", events)
+	}
+	if !m.IsNewListing("NEWUSDT") || m.IsNewListing("BTCUSDT") {
+		t.Errorf("unexpected new listing bucket: %v", m.NewListings())
+	}
+}
+
+func TestMonitor_DetectsAddedSymbol(t *testing.T) {
+	source := &mockSymbolSource{symbols: []Symbol{perp("BTCUSDT", "TRADING")}}
+	m := New(Config{}, source, nil)
+
+	if _, err := m.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	source.symbols = append(source.symbols, perp("ABCUSDT", "TRADING"), Symbol{Symbol: "ABCUSDC", ContractType: "PERPETUAL", QuoteAsset: "USDC", Status: "TRADING"})
+
+	events, err := m.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	if len(events) != 1 || events[0].Symbol != "ABCUSDT" {
+		t.Fatalf("expected new listing for ABCUSDT only, got %+v", events)
+	}
+}
+
+func TestMonitor_DelistingBlacklistsAndFlattens(t *testing.T) {
+	source := &mockSymbolSource{symbols: []Symbol{
+		perp("BTCUSDT", "TRADING"),
+		perp("OLDUSDT", "TRADING"),
+		perp("GONEUSDT", "TRADING"),
+	}}
+	closer := &mockCloser{}
+	m := New(Config{FlattenOnDelist: true}, source, closer)
+
+	if _, err := m.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	scheduled := perp("OLDUSDT", "TRADING")
+	scheduled.DeliveryDate = time.Now().Add(48 * time.Hour)
+	source.symbols = []Symbol{perp("BTCUSDT", "TRADING"), scheduled}
+
+	var notified []Event
+	m.OnEvent(func(ev Event) {
+		notified = append(notified, ev)
+	})
+
+	events, err := m.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	if len(events) != 2 || len(notified) != 2 {
+		t.Fatalf("expected 2 delisting events, got %+v", events)
+	}
+	for _, sym := range []string{"OLDUSDT", "GONEUSDT"} {
+		if !m.IsBlacklisted(sym) {
+			t.Errorf("expected %s to be blacklisted", sym)
+		}
+	}
+	if m.IsBlacklisted("BTCUSDT") {
+		t.Error("BTCUSDT should not be blacklisted")
+	}
+	if len(closer.closed) != 2 {
+		t.Errorf("expected 2 positions flattened, got %v", closer.closed)
+	}
+
+	events, _ = m.Refresh(context.Background())
+	if len(events) != 0 {
+		t.Errorf("blacklisted symbols should only be reported once, got %+v", events)
+	}
+}
+
+func TestMonitor_RetriesFailedFlattensAndRejectsEmptyExchange(t *testing.T) {
+	source := &mockSymbolSource{symbols: []Symbol{perp("BTCUSDT", "TRADING"), perp("OLDUSDT", "TRADING")}}
+	closer := &mockCloser{err: errors.New("timeout")}
+	m := New(Config{FlattenOnDelist: true}, source, closer)
+	if _, err := m.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	source.symbols = []Symbol{perp("BTCUSDT", "TRADING"), perp("OLDUSDT", "BREAK")}
+	events, err := m.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if len(events) != 2 || events[1].Type != EventFlattenFailed || events[1].Symbol != "OLDUSDT" {
+		t.Fatalf("expected the delisting and its failed flatten, got %+v", events)
+	}
+
+	closer.err = nil
+	if _, err := m.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if len(closer.closed) != 1 || closer.closed[0] != "OLDUSDT" {
+		t.Errorf("expected the failed flatten retried, got %v", closer.closed)
+	}
+
+	source.symbols = nil
+	if _, err := m.Refresh(context.Background()); err == nil {
+		t.Fatal("an empty exchange must be rejected")
+	}
+	if m.IsBlacklisted("BTCUSDT") {
+		t.Error("an empty exchange must not blacklist anything")
+	}
+}
+
+func TestMonitor_StartCanBeRetriedAfterAFailedRefresh(t *testing.T) {
+	source := &mockSymbolSource{err: errors.New("timeout")}
+	m := New(Config{}, source, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := m.Start(ctx); err == nil {
+		t.Fatal("expected the failed first refresh to be returned")
+	}
+
+	source.err = nil
+	source.symbols = []Symbol{perp("BTCUSDT", "TRADING")}
+	if err := m.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	defer m.Stop()
+	if _, err := m.Refresh(ctx); err != nil || m.IsBlacklisted("BTCUSDT") {
+		t.Errorf("expected the monitor running after the retry, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package margintarget steers the account's initial margin toward a target
+// share of equity by scaling the size of new entries: they grow while the
+// account sits below the target and shrink as it passes it. Margin taken by
+// entries since the last account snapshot counts straight away, so a burst
+// of signals between polls does not size every entry off the same idle
+// account.
+package margintarget
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/services/equity"
+)
+
+// Source reads the current account state.
+type Source interface {
+	Snapshot(ctx context.Context) (equity.Snapshot, error)
+}
+
+type Config struct {
+	// Target is the initial margin to hold, as a fraction of equity.
+	Target float64
+	// MinScale and MaxScale bound the size multiplier. MinScale must stay
+	// above zero; entries are shrunk, never blocked.
+	MinScale float64
+	MaxScale float64
+	Interval time.Duration
+	Calls    *callpolicy.Policy
+}
+
+type Stats struct {
+	Usage     float64
+	Scale     float64
+	Committed float64
+	Snapshots int
+	Failures  int
+	LastError string
+}
+
+type commit struct {
+	margin float64
+	at     time.Time
+}
+
+type Controller struct {
+	cfg     Config
+	source  Source
+	mu      sync.RWMutex
+	running bool
+	snap    equity.Snapshot
+	polled  time.Time
+	commits []commit
+	stats   Stats
+	stopCh  chan struct{}
+	now     func() time.Time
+}
+
+func New(cfg Config, source Source) *Controller {
+	if cfg.Target <= 0 {
+		cfg.Target = 0.4
+	}
+	if cfg.MinScale <= 0 {
+		cfg.MinScale = 0.25
+	}
+	if cfg.MaxScale <= 0 {
+		cfg.MaxScale = 1.5
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+
+	return &Controller{
+		cfg:    cfg,
+		source: source,
+		stopCh: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+func (c *Controller) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return nil
+	}
+	c.running = true
+	go c.run(ctx)
+	return nil
+}
+
+func (c *Controller) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return nil
+	}
+	c.running = false
+	close(c.stopCh)
+	return nil
+}
+
+func (c *Controller) run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll reads the account's margin and equity. Commits made before the read
+// started are part of it and stop counting separately.
+func (c *Controller) Poll(ctx context.Context) error {
+	started := c.now()
+	callCtx, cancel := c.cfg.Calls.Context(ctx, callpolicy.Account)
+	snap, err := c.source.Snapshot(callCtx)
+	cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		c.stats.Failures++
+		c.stats.LastError = err.Error()
+		return err
+	}
+	c.snap, c.polled = snap, started
+	c.stats.Snapshots++
+
+	kept := c.commits[:0]
+	for _, cm := range c.commits {
+		if !cm.at.Before(started) {
+			kept = append(kept, cm)
+		}
+	}
+	c.commits = kept
+	return nil
+}
+
+// Commit counts margin taken by an entry until the next poll sees it.
+func (c *Controller) Commit(margin float64) {
+	if margin <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.commits = append(c.commits, commit{margin: margin, at: c.now()})
+	c.mu.Unlock()
+}
+
+// Scale returns the size multiplier for the next entry: 1 at the target,
+// rising toward MaxScale as usage falls to zero and falling toward MinScale
+// as it reaches twice the target. It is 1 while there is no recent snapshot
+// to steer by.
+func (c *Controller) Scale() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scaleLocked()
+}
+
+func (c *Controller) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s := c.stats
+	s.Usage, _ = c.usageLocked()
+	s.Scale = c.scaleLocked()
+	s.Committed = c.committedLocked()
+	return s
+}
+
+func (c *Controller) scaleLocked() float64 {
+	usage, ok := c.usageLocked()
+	if !ok {
+		return 1
+	}
+	scale := 2 - usage/c.cfg.Target
+	if scale > c.cfg.MaxScale {
+		scale = c.cfg.MaxScale
+	}
+	if scale < c.cfg.MinScale {
+		scale = c.cfg.MinScale
+	}
+	return scale
+}
+
+// usageLocked returns initial margin, including uncounted commits, over
+// equity. It is not ok without a snapshot from the last three intervals.
+func (c *Controller) usageLocked() (float64, bool) {
+	if c.polled.IsZero() || c.snap.Equity <= 0 || c.now().Sub(c.polled) > 3*c.cfg.Interval {
+		return 0, false
+	}
+	return (c.snap.InitialMargin + c.committedLocked()) / c.snap.Equity, true
+}
+
+func (c *Controller) committedLocked() float64 {
+	total := 0.0
+	for _, cm := range c.commits {
+		total += cm.margin
+	}
+	return total
+}
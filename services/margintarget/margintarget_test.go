@@ -0,0 +1,58 @@
+package margintarget
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/services/equity"
+)
+
+type fakeSource struct {
+	snap equity.Snapshot
+}
+
+func (f *fakeSource) Snapshot(ctx context.Context) (equity.Snapshot, error) {
+	return f.snap, nil
+}
+
+func TestController_ScalesTowardTarget(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{snap: equity.Snapshot{Equity: 1000, InitialMargin: 100}}
+	c := New(Config{Target: 0.4, MinScale: 0.25, MaxScale: 1.5}, src)
+	c.now = func() time.Time { return now }
+
+	if s := c.Scale(); s != 1 {
+		t.Fatalf("scale before any snapshot = %v", s)
+	}
+	c.Poll(context.Background())
+	if s := c.Scale(); s != 1.5 {
+		t.Fatalf("10%% usage should scale up to the cap, got %v", s)
+	}
+
+	// A cluster of entries between polls counts before the account shows it.
+	now = now.Add(time.Second)
+	c.Commit(200)
+	c.Commit(100)
+	if s := c.Scale(); math.Abs(s-1) > 1e-9 {
+		t.Fatalf("at the target the scale should be 1, got %v", s)
+	}
+	c.Commit(400)
+	if s := c.Scale(); s != 0.25 {
+		t.Fatalf("far over the target should floor at MinScale, got %v", s)
+	}
+
+	// The next poll includes those entries, so the commits stop counting.
+	src.snap.InitialMargin = 500
+	now = now.Add(time.Second)
+	c.Poll(context.Background())
+	if st := c.Stats(); st.Committed != 0 || math.Abs(st.Usage-0.5) > 1e-9 || math.Abs(st.Scale-0.75) > 1e-9 {
+		t.Fatalf("stats = %+v", st)
+	}
+
+	now = now.Add(5 * time.Minute)
+	if s := c.Scale(); s != 1 {
+		t.Fatalf("a stale snapshot should not steer, got %v", s)
+	}
+}
@@ -0,0 +1,352 @@
+// Package orderack resolves orders submitted without waiting for them. An
+// order is registered under its client order ID before it is sent, and its
+// callback runs once when the first of these settles it: the account
+// stream reporting it filled, cancelled, expired or rejected; the REST
+// response already carrying a final state or a definite rejection; or,
+// when neither has arrived within the timeout, a lookup of the order on
+// the exchange by its client order ID, cancelling it if it is still open.
+package orderack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// Sources of an Outcome.
+const (
+	SourceStream   = "stream"
+	SourceResponse = "response"
+	SourceLookup   = "lookup"
+	SourceTimeout  = "timeout"
+)
+
+var (
+	ErrUnacknowledged = errors.New("order not acknowledged")
+	// ErrNotFound is wrapped by a Venue when the exchange has no order
+	// under the client order ID asked for.
+	ErrNotFound = errors.New("order not found")
+)
+
+// Venue reads back and cancels orders by the client order ID they were
+// sent with, which is known whether or not the submission got a response.
+type Venue interface {
+	GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*trade.Order, error)
+	CancelOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*trade.Order, error)
+}
+
+type Config struct {
+	// Timeout is how long an order sent to the exchange may go without an
+	// outcome before it is looked up and, if still open, cancelled. An
+	// order whose lookup and cancel both fail stays pending and is tried
+	// again on the next check. Orders not yet sent wait for their REST
+	// response.
+	Timeout       time.Duration
+	CheckInterval time.Duration
+}
+
+// Update is an order event from the account stream.
+type Update struct {
+	Symbol        string
+	OrderID       int64
+	ClientOrderID string
+	Status        string
+	Filled        float64
+	AvgPrice      float64
+}
+
+// Outcome is how a submitted order ended. Err is set when nothing filled;
+// an order cancelled or expired after a partial fill reports what filled.
+type Outcome struct {
+	ClientOrderID string
+	OrderID       string
+	Symbol        string
+	Status        trade.OrderStatus
+	Filled        float64
+	AvgPrice      float64
+	Err           error
+	Source        string
+	Latency       time.Duration
+}
+
+type Stats struct {
+	Pending  int
+	Filled   int
+	Failed   int
+	TimedOut int
+	// BySource counts outcomes by what settled them.
+	BySource map[string]int
+	// MeanLatency is the average time from submission to outcome.
+	MeanLatency time.Duration
+	// CancelFailures counts overdue orders that could be neither read
+	// back nor cancelled, and were left pending to try again.
+	CancelFailures int
+}
+
+type pending struct {
+	symbol  string
+	orderID string
+	at      time.Time
+	sent    time.Time
+	// err is a submission error that left the order's fate unknown.
+	err  error
+	done func(Outcome)
+}
+
+type Tracker struct {
+	cfg     Config
+	venue   Venue
+	mu      sync.Mutex
+	running bool
+	orders  map[string]*pending
+	stats   Stats
+	latency time.Duration
+	stopCh  chan struct{}
+	now     func() time.Time
+}
+
+// New creates a tracker. venue may be nil, in which case an order without
+// an outcome at the timeout fails without being looked up or cancelled.
+func New(cfg Config, venue Venue) *Tracker {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Second
+	}
+
+	return &Tracker{
+		cfg:    cfg,
+		venue:  venue,
+		orders: make(map[string]*pending),
+		stats:  Stats{BySource: make(map[string]int)},
+		stopCh: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+func (t *Tracker) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running {
+		return nil
+	}
+	t.running = true
+	go t.run(ctx)
+	return nil
+}
+
+func (t *Tracker) Stop() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.running {
+		return nil
+	}
+	t.running = false
+	close(t.stopCh)
+	return nil
+}
+
+func (t *Tracker) run(ctx context.Context) {
+	ticker := time.NewTicker(t.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+			t.Check(ctx)
+		}
+	}
+}
+
+// Expect registers an order about to be submitted. done runs once, on
+// whichever goroutine settles the order.
+func (t *Tracker) Expect(clientOrderID, symbol string, done func(Outcome)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.orders[clientOrderID] = &pending{symbol: symbol, at: t.now(), done: done}
+}
+
+// Submitted records the REST response to an order. A final state settles
+// it. An error is not taken as a rejection, since a timed out or failed
+// request may still have placed the order: like an accepted order, it is
+// left to the account stream and looked up at the timeout. Use Rejected
+// for errors the order definitely did not survive.
+func (t *Tracker) Submitted(clientOrderID string, order *trade.Order, err error) {
+	if err == nil && order == nil {
+		return
+	}
+	if err == nil && normalize(order.Status).IsTerminal() {
+		t.settle(clientOrderID, fromOrder(order, SourceResponse))
+		return
+	}
+
+	t.mu.Lock()
+	if p := t.orders[clientOrderID]; p != nil {
+		p.sent, p.err = t.now(), err
+		if err == nil {
+			p.orderID = order.ID
+		}
+	}
+	t.mu.Unlock()
+}
+
+// Rejected fails an order that never reached the book: the exchange
+// turned it down, or it was never sent.
+func (t *Tracker) Rejected(clientOrderID string, err error) {
+	t.settle(clientOrderID, Outcome{Err: err, Source: SourceResponse})
+}
+
+// Update settles an order on its final event from the account stream.
+// Events for orders the tracker is not waiting on are ignored.
+func (t *Tracker) Update(u Update) {
+	status := normalize(trade.OrderStatus(u.Status))
+	if !status.IsTerminal() {
+		return
+	}
+	o := Outcome{
+		OrderID:  fmt.Sprint(u.OrderID),
+		Status:   status,
+		Filled:   u.Filled,
+		AvgPrice: u.AvgPrice,
+		Source:   SourceStream,
+	}
+	if o.Filled <= 0 {
+		o.Err = fmt.Errorf("order %s", status)
+	}
+	t.settle(u.ClientOrderID, o)
+}
+
+// Check resolves the sent orders that have waited longer than the timeout.
+// Each is looked up by its client order ID: a final state settles it and
+// an order the exchange never took fails. One still open, or whose lookup
+// failed, is cancelled and settles with what filled before the cancel; if
+// the cancel fails too it stays pending, since it may still fill.
+func (t *Tracker) Check(ctx context.Context) {
+	type overdue struct {
+		id, symbol string
+		err        error
+	}
+
+	now := t.now()
+	t.mu.Lock()
+	var late []overdue
+	for id, p := range t.orders {
+		if !p.sent.IsZero() && now.Sub(p.sent) >= t.cfg.Timeout {
+			late = append(late, overdue{id, p.symbol, p.err})
+		}
+	}
+	t.mu.Unlock()
+
+	for _, o := range late {
+		unacked := fmt.Errorf("%w within %s", ErrUnacknowledged, t.cfg.Timeout)
+		if o.err != nil {
+			unacked = fmt.Errorf("%w within %s after %v", ErrUnacknowledged, t.cfg.Timeout, o.err)
+		}
+		if t.venue == nil {
+			t.settle(o.id, Outcome{Err: unacked, Source: SourceTimeout})
+			continue
+		}
+
+		order, err := t.venue.GetOrderByClientID(ctx, o.symbol, o.id)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			t.settle(o.id, Outcome{Err: unacked, Source: SourceLookup})
+			continue
+		case err == nil && normalize(order.Status).IsTerminal():
+			t.settle(o.id, fromOrder(order, SourceLookup))
+			continue
+		}
+
+		order, err = t.venue.CancelOrderByClientID(ctx, o.symbol, o.id)
+		if err != nil {
+			t.mu.Lock()
+			t.stats.CancelFailures++
+			t.mu.Unlock()
+			continue
+		}
+		out := fromOrder(order, SourceTimeout)
+		if out.Err != nil {
+			out.Err = unacked
+		}
+		t.settle(o.id, out)
+	}
+}
+
+func (t *Tracker) settle(clientOrderID string, o Outcome) {
+	t.mu.Lock()
+	p := t.orders[clientOrderID]
+	if p == nil {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.orders, clientOrderID)
+
+	o.ClientOrderID, o.Symbol = clientOrderID, p.symbol
+	if o.OrderID == "" {
+		o.OrderID = p.orderID
+	}
+	o.Latency = t.now().Sub(p.at)
+	switch {
+	case o.Source == SourceTimeout:
+		t.stats.TimedOut++
+	case o.Err != nil:
+		t.stats.Failed++
+	default:
+		t.stats.Filled++
+	}
+	t.stats.BySource[o.Source]++
+	t.latency += o.Latency
+	t.mu.Unlock()
+
+	p.done(o)
+}
+
+func (t *Tracker) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.stats
+	s.Pending = len(t.orders)
+	s.BySource = make(map[string]int, len(t.stats.BySource))
+	for k, v := range t.stats.BySource {
+		s.BySource[k] = v
+	}
+	if settled := s.Filled + s.Failed + s.TimedOut; settled > 0 {
+		s.MeanLatency = t.latency / time.Duration(settled)
+	}
+	return s
+}
+
+func fromOrder(order *trade.Order, source string) Outcome {
+	o := Outcome{
+		OrderID:  order.ID,
+		Status:   normalize(order.Status),
+		Filled:   order.FilledQty,
+		AvgPrice: order.AvgFillPrice,
+		Source:   source,
+	}
+	if o.Filled <= 0 {
+		o.Err = fmt.Errorf("order %s", o.Status)
+	}
+	return o
+}
+
+// normalize maps the exchange's spelling of a cancelled order to
+// OrderStatusCancelled.
+func normalize(s trade.OrderStatus) trade.OrderStatus {
+	if s == "CANCELED" {
+		return trade.OrderStatusCancelled
+	}
+	return s
+}
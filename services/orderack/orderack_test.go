@@ -0,0 +1,160 @@
+package orderack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// venue holds orders by client order ID. Lookups of orders it lacks fail
+// with ErrNotFound, or with lookupErr when set; cancels fail with
+// cancelErr when set.
+type venue struct {
+	orders    map[string]*trade.Order
+	lookupErr error
+	cancelErr error
+	cancelled []string
+}
+
+func (v *venue) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*trade.Order, error) {
+	if o, ok := v.orders[clientOrderID]; ok {
+		return o, nil
+	}
+	if v.lookupErr != nil {
+		return nil, v.lookupErr
+	}
+	return nil, ErrNotFound
+}
+
+func (v *venue) CancelOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*trade.Order, error) {
+	if v.cancelErr != nil {
+		return nil, v.cancelErr
+	}
+	v.cancelled = append(v.cancelled, clientOrderID)
+	o := v.orders[clientOrderID]
+	o.Status = trade.OrderStatusCancelled
+	return o, nil
+}
+
+func TestTracker_SettlesOnceFromFirstSource(t *testing.T) {
+	at := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	tr := New(Config{Timeout: 30 * time.Second}, &venue{orders: map[string]*trade.Order{
+		"d": {ID: "3", Status: trade.OrderStatusFilled, FilledQty: 2, AvgFillPrice: 101},
+		"e": {ID: "4", Status: trade.OrderStatusSubmitted},
+	}})
+	tr.now = func() time.Time { return at }
+
+	outcomes := make(map[string][]Outcome)
+	expect := func(id string) {
+		tr.Expect(id, "BTCUSDT", func(o Outcome) { outcomes[id] = append(outcomes[id], o) })
+	}
+
+	// The stream reports the fill before the REST response returns.
+	expect("a")
+	tr.Update(Update{ClientOrderID: "a", OrderID: 1, Status: "PARTIALLY_FILLED", Filled: 0.5})
+	if len(outcomes["a"]) != 0 {
+		t.Fatal("a partial fill is not an outcome")
+	}
+	at = at.Add(200 * time.Millisecond)
+	tr.Update(Update{ClientOrderID: "a", OrderID: 1, Status: "FILLED", Filled: 1, AvgPrice: 100})
+	tr.Submitted("a", &trade.Order{ID: "1", Status: trade.OrderStatusSubmitted}, nil)
+	if got := outcomes["a"]; len(got) != 1 || got[0].Err != nil || got[0].Filled != 1 || got[0].Source != SourceStream || got[0].Latency != 200*time.Millisecond {
+		t.Fatalf("a = %+v", got)
+	}
+
+	// A rejected submission and a cancel with nothing filled both fail.
+	expect("b")
+	tr.Rejected("b", errors.New("insufficient margin"))
+	expect("c")
+	tr.Submitted("c", &trade.Order{ID: "2", Status: trade.OrderStatusSubmitted}, nil)
+	tr.Update(Update{ClientOrderID: "c", OrderID: 2, Status: "CANCELED"})
+	if outcomes["b"][0].Err == nil || outcomes["c"][0].Err == nil || outcomes["c"][0].Status != trade.OrderStatusCancelled {
+		t.Fatalf("b = %+v, c = %+v", outcomes["b"], outcomes["c"])
+	}
+
+	// Accepted orders the stream never reports are looked up at the
+	// timeout and cancelled if still open; one still queued is left alone.
+	expect("d")
+	tr.Submitted("d", &trade.Order{ID: "3", Status: trade.OrderStatusSubmitted}, nil)
+	expect("e")
+	tr.Submitted("e", &trade.Order{ID: "4", Status: trade.OrderStatusSubmitted}, nil)
+	expect("queued")
+	at = at.Add(31 * time.Second)
+	tr.Check(context.Background())
+	if got := outcomes["d"]; len(got) != 1 || got[0].Source != SourceLookup || got[0].AvgPrice != 101 {
+		t.Fatalf("d = %+v", got)
+	}
+	if got := outcomes["e"]; len(got) != 1 || !errors.Is(got[0].Err, ErrUnacknowledged) {
+		t.Fatalf("e = %+v", got)
+	}
+	if len(outcomes["queued"]) != 0 {
+		t.Fatal("an order without a response must not time out")
+	}
+
+	s := tr.Stats()
+	if s.Pending != 1 || s.Filled != 2 || s.Failed != 2 || s.TimedOut != 1 || s.BySource[SourceStream] != 2 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestTracker_CancelsOverdueOrdersBeforeFailingThem(t *testing.T) {
+	at := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	v := &venue{orders: map[string]*trade.Order{
+		"open":    {ID: "1", Status: trade.OrderStatusSubmitted},
+		"partial": {ID: "2", Status: trade.OrderStatusPartially, FilledQty: 0.4, AvgFillPrice: 100},
+	}}
+	tr := New(Config{Timeout: 30 * time.Second}, v)
+	tr.now = func() time.Time { return at }
+
+	outcomes := make(map[string][]Outcome)
+	for _, id := range []string{"open", "partial", "timeout", "stuck"} {
+		id := id
+		tr.Expect(id, "BTCUSDT", func(o Outcome) { outcomes[id] = append(outcomes[id], o) })
+	}
+
+	// A request that timed out may still have placed the order, so it is
+	// not failed on the error alone.
+	tr.Submitted("open", &trade.Order{ID: "1", Status: trade.OrderStatusSubmitted}, nil)
+	tr.Submitted("partial", nil, errors.New("503 service unavailable"))
+	tr.Submitted("timeout", nil, context.DeadlineExceeded)
+	if len(outcomes["partial"]) != 0 || len(outcomes["timeout"]) != 0 {
+		t.Fatal("an error with an unknown outcome must not settle the order")
+	}
+
+	at = at.Add(31 * time.Second)
+	tr.Check(context.Background())
+	if got := outcomes["open"]; len(got) != 1 || !errors.Is(got[0].Err, ErrUnacknowledged) || got[0].Source != SourceTimeout {
+		t.Fatalf("open = %+v", got)
+	}
+	if got := outcomes["partial"]; len(got) != 1 || got[0].Err != nil || got[0].Filled != 0.4 {
+		t.Fatalf("a cancelled partial fill reports what filled, got %+v", got)
+	}
+	if got := outcomes["timeout"]; len(got) != 1 || !errors.Is(got[0].Err, ErrUnacknowledged) || got[0].Source != SourceLookup {
+		t.Fatalf("an order the exchange never took fails, got %+v", got)
+	}
+	if len(v.cancelled) != 2 {
+		t.Errorf("expected the open and partial orders cancelled, got %v", v.cancelled)
+	}
+
+	// An order that can be neither read back nor cancelled stays pending.
+	v.lookupErr, v.cancelErr = errors.New("connection reset"), errors.New("connection reset")
+	tr.Submitted("stuck", nil, errors.New("connection reset"))
+	at = at.Add(31 * time.Second)
+	tr.Check(context.Background())
+	if len(outcomes["stuck"]) != 0 {
+		t.Fatalf("an order that may still be open must not settle, got %+v", outcomes["stuck"])
+	}
+	if s := tr.Stats(); s.Pending != 1 || s.CancelFailures != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+
+	v.lookupErr, v.cancelErr = nil, nil
+	v.orders["stuck"] = &trade.Order{ID: "5", Status: trade.OrderStatusFilled, FilledQty: 1, AvgFillPrice: 99}
+	tr.Check(context.Background())
+	if got := outcomes["stuck"]; len(got) != 1 || got[0].Err != nil || got[0].OrderID != "5" {
+		t.Fatalf("stuck = %+v", got)
+	}
+}
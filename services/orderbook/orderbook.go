@@ -0,0 +1,423 @@
+// Package orderbook maintains local order books from the depth diff stream,
+// so best bid/ask, spread and depth are read from memory instead of a REST
+// call per entry. A book starts from a REST snapshot and applies every diff
+// after it; a gap in the update IDs or a crossed book means the local copy
+// can no longer be trusted, so it is dropped and rebuilt from a new
+// snapshot. Until then readers fall back to whatever they used before.
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Level is a price and the quantity resting at it.
+type Level struct {
+	Price    float64
+	Quantity float64
+}
+
+// Snapshot is a REST depth snapshot.
+type Snapshot struct {
+	LastUpdateID int64
+	Bids         []Level
+	Asks         []Level
+}
+
+// Diff is one depth update event. FirstID and FinalID bound the updates it
+// covers, and PrevFinalID is the FinalID of the event before it on the same
+// stream. A zero quantity removes the level.
+type Diff struct {
+	Symbol      string
+	FirstID     int64
+	FinalID     int64
+	PrevFinalID int64
+	Bids        []Level
+	Asks        []Level
+}
+
+// SnapshotSource fetches a depth snapshot.
+type SnapshotSource interface {
+	Snapshot(ctx context.Context, symbol string, limit int) (Snapshot, error)
+}
+
+type Config struct {
+	// SnapshotDepth is the number of levels per side fetched on a resync.
+	SnapshotDepth int
+	// MaxAge is how long a book may go without an update before it is
+	// treated as stale.
+	MaxAge time.Duration
+	// MaxBuffered caps the diffs kept per symbol while it resyncs.
+	MaxBuffered int
+}
+
+// Top is the best bid and ask of a synced book.
+type Top struct {
+	Bid       float64
+	BidQty    float64
+	Ask       float64
+	AskQty    float64
+	UpdatedAt time.Time
+}
+
+// Spread returns the ask minus the bid.
+func (t Top) Spread() float64 {
+	return t.Ask - t.Bid
+}
+
+// Mid returns the midpoint of the bid and ask.
+func (t Top) Mid() float64 {
+	return (t.Bid + t.Ask) / 2
+}
+
+type Stats struct {
+	Books    int
+	Synced   int
+	Events   int
+	Resyncs  int
+	Gaps     int
+	Crossed  int
+	Failures int
+}
+
+type book struct {
+	synced    bool
+	fresh     bool
+	pending   bool
+	lastID    int64
+	bids      map[float64]float64
+	asks      map[float64]float64
+	buffer    []Diff
+	updatedAt time.Time
+}
+
+type Books struct {
+	cfg     Config
+	source  SnapshotSource
+	mu      sync.RWMutex
+	running bool
+	books   map[string]*book
+	stats   Stats
+	resync  chan string
+	stopCh  chan struct{}
+	now     func() time.Time
+}
+
+func New(cfg Config, source SnapshotSource) *Books {
+	if cfg.SnapshotDepth <= 0 {
+		cfg.SnapshotDepth = 1000
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 5 * time.Second
+	}
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = 1000
+	}
+
+	return &Books{
+		cfg:    cfg,
+		source: source,
+		books:  make(map[string]*book),
+		resync: make(chan string, 64),
+		stopCh: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// Start rebuilds books from snapshots in the background as they fall out of
+// sync.
+func (b *Books) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		return nil
+	}
+	b.running = true
+	go b.run(ctx)
+	return nil
+}
+
+func (b *Books) Stop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.running {
+		return nil
+	}
+	b.running = false
+	close(b.stopCh)
+	return nil
+}
+
+func (b *Books) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.stopCh:
+			return
+		case symbol := <-b.resync:
+			if err := b.Sync(ctx, symbol); err != nil {
+				// Retry after a pause rather than hammering the snapshot
+				// endpoint; the symbol stays unsynced meanwhile.
+				select {
+				case <-ctx.Done():
+					return
+				case <-b.stopCh:
+					return
+				case <-time.After(time.Second):
+				}
+				b.mu.Lock()
+				if bk := b.books[symbol]; bk != nil && !bk.synced {
+					bk.pending = false
+					b.requestLocked(symbol, bk)
+				}
+				b.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Apply feeds one diff from the stream. Diffs for a book that is not synced
+// are buffered until its snapshot arrives.
+func (b *Books) Apply(d Diff) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.Events++
+	bk := b.books[d.Symbol]
+	if bk == nil {
+		bk = &book{}
+		b.books[d.Symbol] = bk
+		b.stats.Books++
+	}
+
+	if !bk.synced {
+		if len(bk.buffer) >= b.cfg.MaxBuffered {
+			bk.buffer = bk.buffer[1:]
+		}
+		bk.buffer = append(bk.buffer, d)
+		b.requestLocked(d.Symbol, bk)
+		return
+	}
+
+	if err := b.applyLocked(bk, d); err != nil {
+		b.desyncLocked(d.Symbol, bk)
+	}
+}
+
+// Sync rebuilds symbol's book from a fresh snapshot and the diffs buffered
+// since it fell out of sync.
+func (b *Books) Sync(ctx context.Context, symbol string) error {
+	snap, err := b.source.Snapshot(ctx, symbol, b.cfg.SnapshotDepth)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bk := b.books[symbol]
+	if bk == nil {
+		bk = &book{}
+		b.books[symbol] = bk
+		b.stats.Books++
+	}
+	if err != nil {
+		b.stats.Failures++
+		return fmt.Errorf("snapshot %s: %w", symbol, err)
+	}
+
+	bk.bids, bk.asks = levels(snap.Bids), levels(snap.Asks)
+	bk.lastID = snap.LastUpdateID
+	bk.fresh = true
+	bk.synced = true
+	bk.pending = false
+	bk.updatedAt = b.now()
+	b.stats.Resyncs++
+
+	buffered := bk.buffer
+	bk.buffer = nil
+	for _, d := range buffered {
+		if err := b.applyLocked(bk, d); err != nil {
+			b.desyncLocked(symbol, bk)
+			return err
+		}
+	}
+	return nil
+}
+
+// applyLocked applies d to a synced book. The first diff after a snapshot
+// must span the snapshot's update ID; every later one must continue from
+// the diff before it.
+func (b *Books) applyLocked(bk *book, d Diff) error {
+	if d.FinalID < bk.lastID || (d.FinalID == bk.lastID && !bk.fresh) {
+		return nil
+	}
+	if bk.fresh {
+		if d.FirstID > bk.lastID {
+			b.stats.Gaps++
+			return fmt.Errorf("first diff %d-%d does not cover snapshot %d", d.FirstID, d.FinalID, bk.lastID)
+		}
+	} else if d.PrevFinalID != bk.lastID {
+		b.stats.Gaps++
+		return fmt.Errorf("diff follows %d, book is at %d", d.PrevFinalID, bk.lastID)
+	}
+
+	for _, l := range d.Bids {
+		update(bk.bids, l)
+	}
+	for _, l := range d.Asks {
+		update(bk.asks, l)
+	}
+	bk.lastID = d.FinalID
+	bk.fresh = false
+	bk.updatedAt = b.now()
+
+	if bid, ask := best(bk.bids, true), best(bk.asks, false); bid > 0 && ask > 0 && bid >= ask {
+		b.stats.Crossed++
+		return fmt.Errorf("book crossed at %v/%v", bid, ask)
+	}
+	return nil
+}
+
+func (b *Books) desyncLocked(symbol string, bk *book) {
+	bk.synced = false
+	bk.bids, bk.asks = nil, nil
+	b.requestLocked(symbol, bk)
+}
+
+func (b *Books) requestLocked(symbol string, bk *book) {
+	if bk.pending {
+		return
+	}
+	select {
+	case b.resync <- symbol:
+		bk.pending = true
+	default:
+	}
+}
+
+// Top returns the best bid and ask of symbol's book, if it is synced and
+// was updated within MaxAge.
+func (b *Books) Top(symbol string) (Top, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	bk, ok := b.usable(symbol)
+	if !ok {
+		return Top{}, false
+	}
+	top := Top{Bid: best(bk.bids, true), Ask: best(bk.asks, false), UpdatedAt: bk.updatedAt}
+	if top.Bid <= 0 || top.Ask <= 0 {
+		return Top{}, false
+	}
+	top.BidQty, top.AskQty = bk.bids[top.Bid], bk.asks[top.Ask]
+	return top, true
+}
+
+// Depth returns the quote value resting on each side within bps basis
+// points of the mid price.
+func (b *Books) Depth(symbol string, bps float64) (bidUSD, askUSD float64, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	bk, ok := b.usable(symbol)
+	if !ok {
+		return 0, 0, false
+	}
+	bid, ask := best(bk.bids, true), best(bk.asks, false)
+	if bid <= 0 || ask <= 0 {
+		return 0, 0, false
+	}
+	mid := (bid + ask) / 2
+	band := mid * bps / 10000
+	for price, qty := range bk.bids {
+		if price >= mid-band {
+			bidUSD += price * qty
+		}
+	}
+	for price, qty := range bk.asks {
+		if price <= mid+band {
+			askUSD += price * qty
+		}
+	}
+	return bidUSD, askUSD, true
+}
+
+// Levels returns up to n best levels per side of symbol's book.
+func (b *Books) Levels(symbol string, n int) (bids, asks []Level, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	bk, ok := b.usable(symbol)
+	if !ok {
+		return nil, nil, false
+	}
+	return sorted(bk.bids, true, n), sorted(bk.asks, false, n), true
+}
+
+func (b *Books) Stats() Stats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	s := b.stats
+	for _, bk := range b.books {
+		if bk.synced {
+			s.Synced++
+		}
+	}
+	return s
+}
+
+func (b *Books) usable(symbol string) (*book, bool) {
+	bk := b.books[symbol]
+	if bk == nil || !bk.synced || b.now().Sub(bk.updatedAt) > b.cfg.MaxAge {
+		return nil, false
+	}
+	return bk, true
+}
+
+func levels(in []Level) map[float64]float64 {
+	out := make(map[float64]float64, len(in))
+	for _, l := range in {
+		update(out, l)
+	}
+	return out
+}
+
+func update(side map[float64]float64, l Level) {
+	if l.Quantity == 0 {
+		delete(side, l.Price)
+		return
+	}
+	side[l.Price] = l.Quantity
+}
+
+func best(side map[float64]float64, highest bool) float64 {
+	found := 0.0
+	for price := range side {
+		if found == 0 || (highest && price > found) || (!highest && price < found) {
+			found = price
+		}
+	}
+	return found
+}
+
+func sorted(side map[float64]float64, descending bool, n int) []Level {
+	out := make([]Level, 0, len(side))
+	for price, qty := range side {
+		out = append(out, Level{Price: price, Quantity: qty})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if descending {
+			return out[i].Price > out[j].Price
+		}
+		return out[i].Price < out[j].Price
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
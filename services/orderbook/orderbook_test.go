@@ -0,0 +1,71 @@
+package orderbook
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeSnapshots struct {
+	snaps []Snapshot
+	calls int
+}
+
+func (f *fakeSnapshots) Snapshot(ctx context.Context, symbol string, limit int) (Snapshot, error) {
+	s := f.snaps[f.calls]
+	f.calls++
+	return s, nil
+}
+
+func TestBooks_SyncsAndResyncsOnGap(t *testing.T) {
+	source := &fakeSnapshots{snaps: []Snapshot{
+		{LastUpdateID: 100, Bids: []Level{{100, 2}, {99.9, 5}}, Asks: []Level{{100.1, 1}, {100.2, 4}}},
+		{LastUpdateID: 200, Bids: []Level{{101, 1}}, Asks: []Level{{101.1, 1}}},
+	}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	b := New(Config{}, source)
+	b.now = func() time.Time { return now }
+
+	// Diffs arriving before the snapshot are buffered; the stale one is
+	// dropped and the one spanning the snapshot applied.
+	b.Apply(Diff{Symbol: "BTCUSDT", FirstID: 90, FinalID: 95, PrevFinalID: 89, Bids: []Level{{50, 1}}})
+	b.Apply(Diff{Symbol: "BTCUSDT", FirstID: 96, FinalID: 105, PrevFinalID: 95, Bids: []Level{{100, 0}}})
+	if _, ok := b.Top("BTCUSDT"); ok {
+		t.Fatal("book should not be readable before its snapshot")
+	}
+	if err := b.Sync(context.Background(), "BTCUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	b.Apply(Diff{Symbol: "BTCUSDT", FirstID: 106, FinalID: 110, PrevFinalID: 105, Asks: []Level{{100.05, 3}}})
+
+	top, ok := b.Top("BTCUSDT")
+	if !ok || top.Bid != 99.9 || top.Ask != 100.05 || top.AskQty != 3 {
+		t.Fatalf("top = %+v, %v", top, ok)
+	}
+	bidUSD, askUSD, _ := b.Depth("BTCUSDT", 20)
+	if bidUSD != 99.9*5 || askUSD != 100.05*3+100.1 {
+		t.Fatalf("depth within 20bps = %v / %v", bidUSD, askUSD)
+	}
+
+	// A diff that does not continue from the last one desyncs the book.
+	b.Apply(Diff{Symbol: "BTCUSDT", FirstID: 120, FinalID: 125, PrevFinalID: 119})
+	if _, ok := b.Top("BTCUSDT"); ok {
+		t.Fatal("book should be unusable after a gap")
+	}
+	if symbol := <-b.resync; symbol != "BTCUSDT" {
+		t.Fatalf("resync requested for %q", symbol)
+	}
+	b.Apply(Diff{Symbol: "BTCUSDT", FirstID: 195, FinalID: 201, PrevFinalID: 194, Bids: []Level{{100.9, 2}}})
+	if err := b.Sync(context.Background(), "BTCUSDT"); err != nil {
+		t.Fatal(err)
+	}
+	if top, ok := b.Top("BTCUSDT"); !ok || top.Bid != 101 {
+		t.Fatalf("top after resync = %+v, %v", top, ok)
+	}
+
+	// A diff that crosses the book desyncs it too.
+	b.Apply(Diff{Symbol: "BTCUSDT", FirstID: 202, FinalID: 203, PrevFinalID: 201, Bids: []Level{{101.2, 1}}})
+	if st := b.Stats(); st.Gaps != 1 || st.Crossed != 1 || st.Resyncs != 2 || st.Synced != 0 {
+		t.Fatalf("stats = %+v", st)
+	}
+}
@@ -0,0 +1,285 @@
+package orderqueue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type Priority int
+
+const (
+	PriorityEntry Priority = iota
+	PriorityExit
+	PriorityRiskReduction
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityRiskReduction:
+		return "risk_reduction"
+	case PriorityExit:
+		return "exit"
+	default:
+		return "entry"
+	}
+}
+
+var (
+	ErrQueueFull = errors.New("order queue full")
+	ErrStale     = errors.New("signal too old to execute")
+	ErrCancelled = errors.New("queued order cancelled")
+	ErrStopped   = errors.New("order queue stopped")
+)
+
+// Config bounds the queue. Workers caps how many orders are in flight at
+// once; MaxQueued applies to entries only, so risk-reducing orders are never
+// turned away. Entries whose signal is older than MaxSignalAge when a
+// worker picks them up are dropped with ErrStale.
+type Config struct {
+	Workers      int
+	MaxQueued    int
+	MaxSignalAge time.Duration
+}
+
+type Request struct {
+	Symbol   string
+	Priority Priority
+	SignalAt time.Time
+	Submit   func(ctx context.Context) error
+}
+
+type Stats struct {
+	Queued    int
+	InFlight  int
+	Executed  int
+	Failed    int
+	Stale     int
+	Cancelled int
+	Rejected  int
+}
+
+type item struct {
+	req  Request
+	seq  uint64
+	done chan error
+}
+
+type Queue struct {
+	cfg      Config
+	mu       sync.Mutex
+	cond     *sync.Cond
+	running  bool
+	items    itemHeap
+	seq      uint64
+	inFlight int
+	stats    Stats
+	wg       sync.WaitGroup
+}
+
+func New(cfg Config) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 2
+	}
+	if cfg.MaxQueued <= 0 {
+		cfg.MaxQueued = 100
+	}
+	if cfg.MaxSignalAge <= 0 {
+		cfg.MaxSignalAge = 10 * time.Second
+	}
+
+	q := &Queue{cfg: cfg}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *Queue) Start(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.running {
+		return nil
+	}
+	q.running = true
+
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker(ctx)
+	}
+	go func() {
+		<-ctx.Done()
+		q.Stop()
+	}()
+	return nil
+}
+
+// Stop fails everything still queued with ErrStopped and waits for orders
+// already in flight to finish.
+func (q *Queue) Stop() error {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return nil
+	}
+	q.running = false
+	for q.items.Len() > 0 {
+		it := heap.Pop(&q.items).(*item)
+		it.done <- ErrStopped
+	}
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	q.wg.Wait()
+	return nil
+}
+
+// Enqueue adds an order and returns a channel that receives its outcome.
+// A risk-reduction order cancels entries still queued for the same symbol.
+func (q *Queue) Enqueue(req Request) (<-chan error, error) {
+	if req.SignalAt.IsZero() {
+		req.SignalAt = time.Now()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.running {
+		return nil, ErrStopped
+	}
+	if req.Priority == PriorityEntry && q.entries() >= q.cfg.MaxQueued {
+		q.stats.Rejected++
+		return nil, ErrQueueFull
+	}
+	if req.Priority == PriorityRiskReduction {
+		q.cancelEntries(req.Symbol)
+	}
+
+	q.seq++
+	it := &item{req: req, seq: q.seq, done: make(chan error, 1)}
+	heap.Push(&q.items, it)
+	q.cond.Signal()
+	return it.done, nil
+}
+
+// Submit enqueues an order and waits for its outcome or ctx.
+func (q *Queue) Submit(ctx context.Context, req Request) error {
+	done, err := q.Enqueue(req)
+	if err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CancelSymbol drops queued entries for symbol and returns how many.
+func (q *Queue) CancelSymbol(symbol string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.cancelEntries(symbol)
+}
+
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s := q.stats
+	s.Queued = q.items.Len()
+	s.InFlight = q.inFlight
+	return s
+}
+
+func (q *Queue) worker(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		q.mu.Lock()
+		for q.running && q.items.Len() == 0 {
+			q.cond.Wait()
+		}
+		if !q.running {
+			q.mu.Unlock()
+			return
+		}
+		it := heap.Pop(&q.items).(*item)
+
+		if it.req.Priority == PriorityEntry && time.Since(it.req.SignalAt) > q.cfg.MaxSignalAge {
+			q.stats.Stale++
+			q.mu.Unlock()
+			it.done <- ErrStale
+			continue
+		}
+		q.inFlight++
+		q.mu.Unlock()
+
+		err := it.req.Submit(ctx)
+
+		q.mu.Lock()
+		q.inFlight--
+		if err != nil {
+			q.stats.Failed++
+		} else {
+			q.stats.Executed++
+		}
+		q.mu.Unlock()
+
+		it.done <- err
+	}
+}
+
+func (q *Queue) entries() int {
+	n := 0
+	for _, it := range q.items {
+		if it.req.Priority == PriorityEntry {
+			n++
+		}
+	}
+	return n
+}
+
+func (q *Queue) cancelEntries(symbol string) int {
+	kept := q.items[:0]
+	cancelled := 0
+	for _, it := range q.items {
+		if it.req.Priority == PriorityEntry && it.req.Symbol == symbol {
+			it.done <- ErrCancelled
+			cancelled++
+			continue
+		}
+		kept = append(kept, it)
+	}
+	q.items = kept
+	heap.Init(&q.items)
+	q.stats.Cancelled += cancelled
+	return cancelled
+}
+
+// itemHeap orders by priority, then first in first out.
+type itemHeap []*item
+
+func (h itemHeap) Len() int { return len(h) }
+
+func (h itemHeap) Less(i, j int) bool {
+	if h[i].req.Priority != h[j].req.Priority {
+		return h[i].req.Priority > h[j].req.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h itemHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *itemHeap) Push(x interface{}) { *h = append(*h, x.(*item)) }
+
+func (h *itemHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return it
+}
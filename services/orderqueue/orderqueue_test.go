@@ -0,0 +1,85 @@
+package orderqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue_RiskReductionJumpsEntriesAndCancelsSameSymbol(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(Config{Workers: 1})
+	q.Start(ctx)
+
+	gate := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	blocker, _ := q.Enqueue(Request{Symbol: "ETHUSDT", Submit: func(context.Context) error {
+		<-gate
+		return nil
+	}})
+	for q.Stats().InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	entryA, _ := q.Enqueue(Request{Symbol: "BTCUSDT", Submit: record("entry BTC")})
+	entryB, _ := q.Enqueue(Request{Symbol: "SOLUSDT", Submit: record("entry SOL")})
+	reduce, _ := q.Enqueue(Request{Symbol: "SOLUSDT", Priority: PriorityRiskReduction, Submit: record("reduce SOL")})
+	close(gate)
+
+	<-blocker
+	if err := <-reduce; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-entryA; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-entryB; !errors.Is(err, ErrCancelled) {
+		t.Errorf("expected queued SOL entry to be cancelled, got %v", err)
+	}
+
+	if len(order) != 2 || order[0] != "reduce SOL" || order[1] != "entry BTC" {
+		t.Errorf("expected risk reduction before entry, got %v", order)
+	}
+	if s := q.Stats(); s.Executed != 3 || s.Cancelled != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestQueue_DropsStaleEntries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(Config{MaxSignalAge: time.Second})
+	q.Start(ctx)
+
+	called := false
+	err := q.Submit(ctx, Request{Symbol: "BTCUSDT", SignalAt: time.Now().Add(-time.Minute), Submit: func(context.Context) error {
+		called = true
+		return nil
+	}})
+	if !errors.Is(err, ErrStale) || called {
+		t.Errorf("expected stale entry to be dropped unsent, got err=%v called=%v", err, called)
+	}
+
+	err = q.Submit(ctx, Request{Symbol: "BTCUSDT", Priority: PriorityRiskReduction, SignalAt: time.Now().Add(-time.Minute), Submit: func(context.Context) error {
+		called = true
+		return nil
+	}})
+	if err != nil || !called {
+		t.Errorf("expected old risk reduction to still execute, got err=%v called=%v", err, called)
+	}
+}
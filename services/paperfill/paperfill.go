@@ -0,0 +1,283 @@
+// Package paperfill runs the paper fill simulator in the shadow of live
+// execution. Every live entry is also filled on paper from the quote it
+// was sent against, and every trade the live side closes is closed on paper
+// at the same exit price. Comparing the two shows how far the simulator's
+// fills, missed fills and PnL are from the exchange's, and so whether its
+// slippage is set honestly.
+package paperfill
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/state"
+)
+
+type Config struct {
+	// SlippageBps is what the simulator charges a market fill beyond the
+	// touch, and every exit beyond its price. Default 2.
+	SlippageBps float64
+	// Window is how many completed entries the report covers. Default 500.
+	Window int
+}
+
+// Entry is a live entry as it is sent. Passive entries rest at the touch,
+// like a chased limit, and fill on paper without slippage.
+type Entry struct {
+	ID       string
+	Symbol   string
+	Side     trade.Side
+	Quantity float64
+	Bid      float64
+	Ask      float64
+	// Expected is the price the signal expected, used when there is no
+	// quote.
+	Expected float64
+	Passive  bool
+}
+
+// Record pairs a paper entry with its live outcome. Prices are zero until
+// known; LiveFilled is false for a live entry that ended without a fill.
+type Record struct {
+	ID         string     `json:"id"`
+	Symbol     string     `json:"symbol"`
+	Side       trade.Side `json:"side"`
+	Quantity   float64    `json:"quantity"`
+	PaperFill  float64    `json:"paper_fill"`
+	LiveFill   float64    `json:"live_fill,omitempty"`
+	LiveFilled bool       `json:"live_filled"`
+	// SlippageBps is how much worse than paper the live entry filled.
+	SlippageBps float64   `json:"slippage_bps"`
+	PaperPnL    float64   `json:"paper_pnl,omitempty"`
+	LivePnL     float64   `json:"live_pnl,omitempty"`
+	Closed      bool      `json:"closed"`
+	OpenedAt    time.Time `json:"opened_at"`
+}
+
+// Divergence summarizes a set of records.
+type Divergence struct {
+	Key     string `json:"key,omitempty"`
+	Entries int    `json:"entries"`
+	// MissedFills counts entries the paper side filled and the live side
+	// did not.
+	MissedFills     int     `json:"missed_fills"`
+	MeanSlippageBps float64 `json:"mean_slippage_bps"`
+	P95SlippageBps  float64 `json:"p95_slippage_bps"`
+	Closed          int     `json:"closed"`
+	PaperPnL        float64 `json:"paper_pnl"`
+	LivePnL         float64 `json:"live_pnl"`
+	// PnLDelta is live minus paper PnL over the closed trades.
+	PnLDelta float64 `json:"pnl_delta"`
+}
+
+// Report is the divergence overall and per symbol, worst PnL delta first.
+type Report struct {
+	Divergence
+	SlippageBps float64      `json:"simulated_slippage_bps"`
+	Open        int          `json:"open"`
+	Symbols     []Divergence `json:"symbols,omitempty"`
+}
+
+type Shadow struct {
+	cfg     Config
+	mu      sync.Mutex
+	pending map[string]*Record
+	// open holds the paper positions of filled live entries by symbol,
+	// waiting for the live trade to close.
+	open map[string]*Record
+	done []Record
+	now  func() time.Time
+}
+
+func New(cfg Config) *Shadow {
+	if cfg.SlippageBps <= 0 {
+		cfg.SlippageBps = 2
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 500
+	}
+	return &Shadow{
+		cfg:     cfg,
+		pending: make(map[string]*Record),
+		open:    make(map[string]*Record),
+		now:     time.Now,
+	}
+}
+
+// Open fills e on paper and waits for its live outcome.
+func (s *Shadow) Open(e Entry) Record {
+	price := e.Expected
+	if e.Side == trade.SideBuy && e.Ask > 0 {
+		price = e.Ask
+		if e.Passive && e.Bid > 0 {
+			price = e.Bid
+		}
+	}
+	if e.Side == trade.SideSell && e.Bid > 0 {
+		price = e.Bid
+		if e.Passive && e.Ask > 0 {
+			price = e.Ask
+		}
+	}
+	if !e.Passive {
+		price = s.slip(e.Side, price)
+	}
+
+	r := &Record{ID: e.ID, Symbol: e.Symbol, Side: e.Side, Quantity: e.Quantity, PaperFill: price, OpenedAt: s.now()}
+	s.mu.Lock()
+	s.pending[e.ID] = r
+	s.mu.Unlock()
+	return *r
+}
+
+// Cancel forgets an entry that was never sent, such as one dropped from
+// the order queue.
+func (s *Shadow) Cancel(id string) {
+	s.mu.Lock()
+	delete(s.pending, id)
+	s.mu.Unlock()
+}
+
+// Live records how the live entry id ended. price is its average fill, or
+// zero when unknown, in which case no slippage is measured; qty, when set,
+// is what filled, and the paper position is cut to it so the PnL compare
+// like for like.
+func (s *Shadow) Live(id string, filled bool, price, qty float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.pending[id]
+	if !ok {
+		return
+	}
+	delete(s.pending, id)
+	r.LiveFilled = filled
+	if !filled {
+		s.complete(*r)
+		return
+	}
+	if qty > 0 {
+		r.Quantity = qty
+	}
+	if price > 0 && r.PaperFill > 0 {
+		r.LiveFill = price
+		r.SlippageBps = adverseBps(r.Side, r.PaperFill, price)
+	}
+	s.open[r.Symbol] = r
+}
+
+// Close settles the paper position of a trade the live side journaled.
+// The paper side exits at the same price less the simulated slippage.
+func (s *Shadow) Close(t state.Trade) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.open[t.Symbol]
+	if !ok || t.ExitPrice <= 0 {
+		return
+	}
+	delete(s.open, t.Symbol)
+
+	liveEntry := r.LiveFill
+	if liveEntry <= 0 {
+		liveEntry = t.FillPrice
+	}
+	if liveEntry <= 0 {
+		liveEntry = t.EntryPrice
+	}
+	exit := trade.SideSell
+	if r.Side == trade.SideSell {
+		exit = trade.SideBuy
+	}
+	r.LivePnL = pnl(r.Side, liveEntry, t.ExitPrice, r.Quantity)
+	r.PaperPnL = pnl(r.Side, r.PaperFill, s.slip(exit, t.ExitPrice), r.Quantity)
+	r.Closed = true
+	s.complete(*r)
+}
+
+func (s *Shadow) complete(r Record) {
+	s.done = append(s.done, r)
+	if len(s.done) > s.cfg.Window {
+		s.done = s.done[len(s.done)-s.cfg.Window:]
+	}
+}
+
+// Report summarizes the completed entries.
+func (s *Shadow) Report() Report {
+	s.mu.Lock()
+	records := make([]Record, len(s.done))
+	copy(records, s.done)
+	open := len(s.open)
+	s.mu.Unlock()
+
+	bySymbol := make(map[string][]Record)
+	for _, r := range records {
+		bySymbol[r.Symbol] = append(bySymbol[r.Symbol], r)
+	}
+	rep := Report{Divergence: diverge("", records), SlippageBps: s.cfg.SlippageBps, Open: open}
+	for symbol, rs := range bySymbol {
+		rep.Symbols = append(rep.Symbols, diverge(symbol, rs))
+	}
+	sort.Slice(rep.Symbols, func(i, j int) bool {
+		return math.Abs(rep.Symbols[i].PnLDelta) > math.Abs(rep.Symbols[j].PnLDelta)
+	})
+	return rep
+}
+
+func diverge(key string, records []Record) Divergence {
+	d := Divergence{Key: key, Entries: len(records)}
+	var slips []float64
+	for _, r := range records {
+		if !r.LiveFilled {
+			d.MissedFills++
+			continue
+		}
+		if r.LiveFill > 0 {
+			slips = append(slips, r.SlippageBps)
+		}
+		if r.Closed {
+			d.Closed++
+			d.PaperPnL += r.PaperPnL
+			d.LivePnL += r.LivePnL
+		}
+	}
+	d.PnLDelta = d.LivePnL - d.PaperPnL
+	if len(slips) > 0 {
+		sum := 0.0
+		for _, v := range slips {
+			sum += v
+		}
+		d.MeanSlippageBps = sum / float64(len(slips))
+		sort.Float64s(slips)
+		d.P95SlippageBps = slips[int(math.Ceil(0.95*float64(len(slips))))-1]
+	}
+	return d
+}
+
+// slip moves price against a fill on side by the simulated slippage.
+func (s *Shadow) slip(side trade.Side, price float64) float64 {
+	if side == trade.SideSell {
+		return price * (1 - s.cfg.SlippageBps/10000)
+	}
+	return price * (1 + s.cfg.SlippageBps/10000)
+}
+
+// adverseBps is how much worse live filled than paper for side, in basis
+// points of the paper price.
+func adverseBps(side trade.Side, paper, live float64) float64 {
+	bps := (live - paper) / paper * 10000
+	if side == trade.SideSell {
+		bps = -bps
+	}
+	return bps
+}
+
+func pnl(side trade.Side, entry, exit, qty float64) float64 {
+	if side == trade.SideSell {
+		return (entry - exit) * qty
+	}
+	return (exit - entry) * qty
+}
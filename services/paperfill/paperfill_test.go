@@ -0,0 +1,77 @@
+package paperfill
+
+import (
+	"math"
+	"testing"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/state"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+func TestShadow_PaperFillsAtTheTouch(t *testing.T) {
+	s := New(Config{SlippageBps: 10})
+
+	buy := s.Open(Entry{ID: "1", Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 1, Bid: 99, Ask: 100})
+	if !near(buy.PaperFill, 100.1) {
+		t.Errorf("market buy fill = %v, want ask plus slippage", buy.PaperFill)
+	}
+	sell := s.Open(Entry{ID: "2", Symbol: "ETHUSDT", Side: trade.SideSell, Quantity: 1, Bid: 99, Ask: 100, Passive: true})
+	if sell.PaperFill != 100 {
+		t.Errorf("passive sell fill = %v, want the ask", sell.PaperFill)
+	}
+	blind := s.Open(Entry{ID: "3", Symbol: "SOLUSDT", Side: trade.SideBuy, Quantity: 1, Expected: 50})
+	if !near(blind.PaperFill, 50.05) {
+		t.Errorf("fill without a quote = %v, want the expected price plus slippage", blind.PaperFill)
+	}
+}
+
+func TestShadow_ReportsSlippageMissedFillsAndPnL(t *testing.T) {
+	s := New(Config{SlippageBps: 10})
+
+	// Live fills 10 bps worse than paper's 100.1 and exits at 110.
+	s.Open(Entry{ID: "a", Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 2, Bid: 99, Ask: 100})
+	s.Live("a", true, 100.2001, 0)
+	s.Close(state.Trade{Symbol: "BTCUSDT", ExitPrice: 110})
+
+	// Paper fills, live misses.
+	s.Open(Entry{ID: "b", Symbol: "ETHUSDT", Side: trade.SideSell, Quantity: 1, Bid: 99, Ask: 100})
+	s.Live("b", false, 0, 0)
+
+	// Dropped before it was sent: not counted.
+	s.Open(Entry{ID: "c", Symbol: "ETHUSDT", Side: trade.SideSell, Quantity: 1, Bid: 99, Ask: 100})
+	s.Cancel("c")
+
+	// Filled but still open.
+	s.Open(Entry{ID: "d", Symbol: "SOLUSDT", Side: trade.SideBuy, Quantity: 1, Bid: 9, Ask: 10})
+	s.Live("d", true, 10.01, 0)
+
+	r := s.Report()
+	if r.Entries != 2 || r.MissedFills != 1 || r.Closed != 1 || r.Open != 1 {
+		t.Fatalf("report = %+v", r)
+	}
+	if !near(r.MeanSlippageBps, 10) || !near(r.P95SlippageBps, 10) {
+		t.Errorf("slippage mean %v p95 %v, want 10 bps", r.MeanSlippageBps, r.P95SlippageBps)
+	}
+	wantLive := (110 - 100.2001) * 2
+	wantPaper := (110*0.999 - 100.1) * 2
+	if !near(r.LivePnL, wantLive) || !near(r.PaperPnL, wantPaper) || !near(r.PnLDelta, wantLive-wantPaper) {
+		t.Errorf("pnl live %v paper %v delta %v", r.LivePnL, r.PaperPnL, r.PnLDelta)
+	}
+	if len(r.Symbols) != 2 || r.Symbols[0].Key != "BTCUSDT" {
+		t.Errorf("symbols = %+v", r.Symbols)
+	}
+}
+
+func TestShadow_PartialFillSizesThePaperPosition(t *testing.T) {
+	s := New(Config{SlippageBps: 1})
+	s.Open(Entry{ID: "a", Symbol: "BTCUSDT", Side: trade.SideSell, Quantity: 4, Bid: 100, Ask: 101, Passive: true})
+	s.Live("a", true, 101, 1)
+	s.Close(state.Trade{Symbol: "BTCUSDT", ExitPrice: 91})
+
+	r := s.Report()
+	if !near(r.LivePnL, 10) || !near(r.PaperPnL, 101-91*1.0001) || r.MeanSlippageBps != 0 {
+		t.Errorf("report = %+v", r.Divergence)
+	}
+}
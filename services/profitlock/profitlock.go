@@ -0,0 +1,313 @@
+// Package profitlock keeps a good day from turning into a bad one. Account
+// equity is followed through each UTC day from where it opened. Once it has
+// gained ArmPercent the lock is armed and trails the day's peak: giving back
+// Giveback of the peak gain trips it, which stops new entries until the
+// next day and, with Flatten, closes the open positions. The day is
+// persisted so a restart keeps a tripped lock.
+package profitlock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/equity"
+)
+
+// Source reads the current account state.
+type Source interface {
+	Snapshot(ctx context.Context) (equity.Snapshot, error)
+}
+
+// Closer closes a position at market.
+type Closer interface {
+	ClosePosition(ctx context.Context, p equity.Position) error
+}
+
+// Store persists the day, typically the trading state.
+type Store interface {
+	DayLock() state.ProfitLock
+	SetDayLock(state.ProfitLock)
+}
+
+type Config struct {
+	// ArmPercent is the gain over the day's opening equity, in percent,
+	// that arms the lock.
+	ArmPercent float64
+	// Giveback is the fraction of the day's peak gain that may be given
+	// back once armed.
+	Giveback float64
+	Flatten  bool
+	Interval time.Duration
+	Calls    *callpolicy.Policy
+	// OnChange is called when the lock arms, trips or reopens on a new day.
+	OnChange func(Change)
+}
+
+// Actions of a Change.
+const (
+	ActionArm    = "arm"
+	ActionLock   = "lock"
+	ActionReopen = "reopen"
+)
+
+// Change is one transition of the lock.
+type Change struct {
+	Action string
+	Day    string
+	Open   float64
+	Peak   float64
+	Equity float64
+	Floor  float64
+	// Closed lists the symbols flattened when the lock tripped, and
+	// Errors the ones that could not be.
+	Closed []string
+	Errors []string
+	At     time.Time
+}
+
+func (c Change) String() string {
+	switch c.Action {
+	case ActionArm:
+		return fmt.Sprintf("profit lock armed: equity %.2f is %.2f%% above the day's open %.2f", c.Equity, pct(c.Equity, c.Open), c.Open)
+	case ActionReopen:
+		return fmt.Sprintf("new day %s: entries reopen from equity %.2f", c.Day, c.Open)
+	}
+	s := fmt.Sprintf("profit lock tripped: equity %.2f fell to the floor %.2f from the day's peak %.2f (open %.2f); no new entries until tomorrow", c.Equity, c.Floor, c.Peak, c.Open)
+	if len(c.Closed) > 0 {
+		s += fmt.Sprintf(", closed %v", c.Closed)
+	}
+	if len(c.Errors) > 0 {
+		s += fmt.Sprintf(", could not close %v", c.Errors)
+	}
+	return s
+}
+
+// Status is the lock's standing for reports.
+type Status struct {
+	Day       string    `json:"day"`
+	Open      float64   `json:"open"`
+	Peak      float64   `json:"peak"`
+	Equity    float64   `json:"equity"`
+	GainPct   float64   `json:"gain_pct"`
+	PeakPct   float64   `json:"peak_pct"`
+	Floor     float64   `json:"floor,omitempty"`
+	Armed     bool      `json:"armed"`
+	Locked    bool      `json:"locked"`
+	LockedAt  time.Time `json:"locked_at,omitempty"`
+	Polls     int       `json:"polls"`
+	Failures  int       `json:"failures"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+type Lock struct {
+	cfg     Config
+	source  Source
+	closer  Closer
+	store   Store
+	mu      sync.RWMutex
+	running bool
+	day     state.ProfitLock
+	equity  float64
+	polls   int
+	fails   int
+	lastErr string
+	stopCh  chan struct{}
+	now     func() time.Time
+}
+
+// New creates a lock and restores the day from store. closer may be nil,
+// in which case a tripped lock only stops entries.
+func New(cfg Config, source Source, closer Closer, store Store) *Lock {
+	if cfg.ArmPercent <= 0 {
+		cfg.ArmPercent = 2
+	}
+	if cfg.Giveback <= 0 || cfg.Giveback >= 1 {
+		cfg.Giveback = 0.5
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+
+	l := &Lock{
+		cfg:    cfg,
+		source: source,
+		closer: closer,
+		store:  store,
+		now:    time.Now,
+	}
+	if store != nil {
+		l.day = store.DayLock()
+	}
+	return l
+}
+
+func (l *Lock) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running {
+		return nil
+	}
+	l.running = true
+	l.stopCh = make(chan struct{})
+	go l.run(ctx, l.stopCh)
+	return nil
+}
+
+func (l *Lock) Stop() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.running {
+		return nil
+	}
+	l.running = false
+	close(l.stopCh)
+	return nil
+}
+
+func (l *Lock) run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(l.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		l.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll reads the account's equity and moves the lock along: a new UTC day
+// starts afresh from it, a new peak raises the floor, and equity at or
+// under the floor of an armed lock trips it.
+func (l *Lock) Poll(ctx context.Context) error {
+	callCtx, cancel := l.cfg.Calls.Context(ctx, callpolicy.Account)
+	snap, err := l.source.Snapshot(callCtx)
+	cancel()
+
+	now := l.now()
+	l.mu.Lock()
+	if err != nil {
+		l.fails++
+		l.lastErr = err.Error()
+		l.mu.Unlock()
+		return err
+	}
+	l.polls++
+	l.equity = snap.Equity
+	if snap.Equity <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	var changes []Change
+	d := l.day
+	if today := now.UTC().Format("2006-01-02"); d.Day != today {
+		if d.Locked {
+			changes = append(changes, Change{Action: ActionReopen, Day: today, Open: snap.Equity, Peak: snap.Equity, Equity: snap.Equity, At: now})
+		}
+		d = state.ProfitLock{Day: today, Open: snap.Equity, Peak: snap.Equity}
+	}
+	if snap.Equity > d.Peak {
+		d.Peak = snap.Equity
+	}
+	change := Change{Day: d.Day, Open: d.Open, Peak: d.Peak, Equity: snap.Equity, At: now}
+	if !d.Armed && pct(d.Peak, d.Open) >= l.cfg.ArmPercent {
+		d.Armed, d.ArmedAt = true, now
+		change.Action = ActionArm
+		changes = append(changes, change)
+	}
+	if d.Armed && !d.Locked {
+		if change.Floor = l.floor(d); snap.Equity <= change.Floor {
+			d.Locked, d.LockedAt = true, now
+			change.Action = ActionLock
+			changes = append(changes, change)
+		}
+	}
+	changed := d != l.day
+	l.day = d
+	l.mu.Unlock()
+
+	if changed && l.store != nil {
+		l.store.SetDayLock(d)
+	}
+	for _, ch := range changes {
+		if ch.Action == ActionLock && l.cfg.Flatten && l.closer != nil {
+			ch.Closed, ch.Errors = l.flatten(ctx, snap.Positions)
+		}
+		if l.cfg.OnChange != nil {
+			l.cfg.OnChange(ch)
+		}
+	}
+	return nil
+}
+
+func (l *Lock) flatten(ctx context.Context, positions []equity.Position) (closed, failed []string) {
+	for _, p := range positions {
+		if p.Size == 0 {
+			continue
+		}
+		callCtx, cancel := l.cfg.Calls.Context(ctx, callpolicy.Order)
+		err := l.closer.ClosePosition(callCtx, p)
+		cancel()
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", p.Symbol, err))
+			continue
+		}
+		closed = append(closed, p.Symbol)
+	}
+	return closed, failed
+}
+
+// Locked reports whether the lock has tripped today.
+func (l *Lock) Locked() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.day.Locked && l.day.Day == l.now().UTC().Format("2006-01-02")
+}
+
+func (l *Lock) Status() Status {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	d := l.day
+	s := Status{
+		Day:       d.Day,
+		Open:      d.Open,
+		Peak:      d.Peak,
+		Equity:    l.equity,
+		GainPct:   pct(l.equity, d.Open),
+		PeakPct:   pct(d.Peak, d.Open),
+		Armed:     d.Armed,
+		Locked:    d.Locked,
+		LockedAt:  d.LockedAt,
+		Polls:     l.polls,
+		Failures:  l.fails,
+		LastError: l.lastErr,
+	}
+	if d.Armed {
+		s.Floor = l.floor(d)
+	}
+	return s
+}
+
+// floor is the equity at which an armed lock trips.
+func (l *Lock) floor(d state.ProfitLock) float64 {
+	return d.Open + (d.Peak-d.Open)*(1-l.cfg.Giveback)
+}
+
+func pct(v, base float64) float64 {
+	if base <= 0 {
+		return 0
+	}
+	return (v - base) / base * 100
+}
@@ -0,0 +1,94 @@
+package profitlock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/equity"
+)
+
+type fakeSource struct {
+	snap equity.Snapshot
+}
+
+func (f *fakeSource) Snapshot(ctx context.Context) (equity.Snapshot, error) {
+	return f.snap, nil
+}
+
+type fakeCloser struct {
+	closed []string
+}
+
+func (f *fakeCloser) ClosePosition(ctx context.Context, p equity.Position) error {
+	f.closed = append(f.closed, p.Symbol)
+	return nil
+}
+
+type fakeStore struct {
+	day   state.ProfitLock
+	saves int
+}
+
+func (f *fakeStore) DayLock() state.ProfitLock { return f.day }
+
+func (f *fakeStore) SetDayLock(d state.ProfitLock) {
+	f.day = d
+	f.saves++
+}
+
+func TestLock_ArmsTrailsAndTripsOnGiveback(t *testing.T) {
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	src := &fakeSource{snap: equity.Snapshot{Equity: 1000}}
+	closer := &fakeCloser{}
+	store := &fakeStore{}
+	var changes []Change
+	l := New(Config{ArmPercent: 2, Giveback: 0.5, Flatten: true, OnChange: func(c Change) { changes = append(changes, c) }}, src, closer, store)
+	l.now = func() time.Time { return now }
+
+	poll := func(eq float64) {
+		src.snap.Equity = eq
+		l.Poll(context.Background())
+	}
+
+	poll(1000)
+	poll(1015)
+	if l.Status().Armed {
+		t.Fatal("armed below 2%")
+	}
+	poll(1030)
+	poll(1040)
+	if s := l.Status(); !s.Armed || s.Floor != 1020 || len(changes) != 1 || changes[0].Action != ActionArm {
+		t.Fatalf("status = %+v, changes = %+v", s, changes)
+	}
+
+	// Giving back less than half of the 40 gained keeps trading open.
+	poll(1025)
+	if l.Locked() {
+		t.Fatal("locked above the floor")
+	}
+	src.snap.Positions = []equity.Position{{Symbol: "ETHUSDT", Size: 1}, {Symbol: "SOLUSDT"}}
+	poll(1019)
+	if !l.Locked() || len(changes) != 2 || changes[1].Action != ActionLock {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if len(closer.closed) != 1 || closer.closed[0] != "ETHUSDT" {
+		t.Fatalf("closed = %v", closer.closed)
+	}
+
+	// A restart keeps the tripped lock; the next day reopens from its equity.
+	restarted := New(Config{ArmPercent: 2}, src, nil, store)
+	restarted.now = l.now
+	if !restarted.Locked() {
+		t.Fatal("restart forgot the lock")
+	}
+	now = now.Add(24 * time.Hour)
+	if restarted.Locked() {
+		t.Fatal("lock carried into the next day")
+	}
+	restarted.Poll(context.Background())
+	if d := store.day; d.Day != "2026-05-05" || d.Open != 1019 || d.Locked {
+		t.Fatalf("new day = %+v", d)
+	}
+}
@@ -0,0 +1,239 @@
+// Package promptlab trials a changed brain prompt without trading on it.
+// It replays setups the brain was asked to confirm, taken from the decision
+// log, against the live and a candidate prompt, and reports where the two
+// decide differently and how those setups would have played out.
+package promptlab
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/pkg/callpolicy"
+	"github.com/britej3/gobot/services/decisionlog"
+)
+
+// Decider asks the brain about a rendered prompt.
+type Decider interface {
+	DecideWithPrompt(ctx context.Context, prompt string) (*brain.TradingDecision, error)
+}
+
+// Prompt renders the decision prompt for a signal.
+type Prompt func(signalData interface{}) (string, error)
+
+// KlineSource reads the most recent candles of a symbol.
+type KlineSource interface {
+	Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error)
+}
+
+type Config struct {
+	// Sample is how many of the most recent replayable records are used.
+	Sample int
+	// MinConfidence is the brain confidence an entry needs, as live.
+	MinConfidence float64
+	// StopLossPercent and TakeProfitPercent place the simulated exits,
+	// in percent of the entry price.
+	StopLossPercent   float64
+	TakeProfitPercent float64
+	// Horizon closes a simulated trade that hit neither exit.
+	Horizon  time.Duration
+	Interval string
+	Calls    *callpolicy.Policy
+}
+
+// Verdict is one prompt's answer for a setup.
+type Verdict struct {
+	Decision   string  `json:"decision,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Enter      bool    `json:"enter"`
+	Err        string  `json:"error,omitempty"`
+}
+
+// Outcome is how the setup would have played out had it been entered.
+// ReturnPct is in the setup's direction; Exit is "take_profit",
+// "stop_loss" or "horizon". Known is false when no candles after the
+// decision were available.
+type Outcome struct {
+	Known     bool    `json:"known"`
+	Exit      string  `json:"exit,omitempty"`
+	ReturnPct float64 `json:"return_pct"`
+}
+
+type Case struct {
+	Time      time.Time `json:"ts"`
+	Symbol    string    `json:"symbol"`
+	Side      string    `json:"side"`
+	Live      Verdict   `json:"live"`
+	Candidate Verdict   `json:"candidate"`
+	Outcome   Outcome   `json:"outcome"`
+}
+
+// Differs reports whether the prompts disagreed on the setup. A prompt
+// that failed to answer disagrees with nothing.
+func (c Case) Differs() bool {
+	if c.Live.Err != "" || c.Candidate.Err != "" {
+		return false
+	}
+	return c.Live.Enter != c.Candidate.Enter || !strings.EqualFold(c.Live.Decision, c.Candidate.Decision)
+}
+
+// Tally sums one prompt's simulated entries. ReturnPct only counts
+// entries with a known outcome.
+type Tally struct {
+	Entries   int     `json:"entries"`
+	Wins      int     `json:"wins"`
+	Errors    int     `json:"errors"`
+	ReturnPct float64 `json:"return_pct"`
+}
+
+type Report struct {
+	Cases     []Case `json:"cases"`
+	Differ    int    `json:"differ"`
+	Live      Tally  `json:"live"`
+	Candidate Tally  `json:"candidate"`
+}
+
+// Replayable returns the records Run can replay: those carrying the inputs
+// the brain was asked about.
+func Replayable(records []decisionlog.Record) []decisionlog.Record {
+	var out []decisionlog.Record
+	for _, r := range records {
+		if len(r.Inputs) > 0 && r.Inputs["price"] > 0 && (r.Candidate == "LONG" || r.Candidate == "SHORT") {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Run replays the most recent replayable records against both prompts.
+// A prompt failing on a setup is counted in its tally, not returned.
+func Run(ctx context.Context, cfg Config, records []decisionlog.Record, live, candidate Prompt, decider Decider, klines KlineSource) (Report, error) {
+	if cfg.Sample <= 0 {
+		cfg.Sample = 20
+	}
+	if cfg.Horizon <= 0 {
+		cfg.Horizon = time.Hour
+	}
+	if cfg.Interval == "" {
+		cfg.Interval = "1m"
+	}
+
+	records = Replayable(records)
+	if len(records) > cfg.Sample {
+		records = records[len(records)-cfg.Sample:]
+	}
+
+	var report Report
+	candles := make(map[string][]trade.Kline)
+	for _, r := range records {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if _, ok := candles[r.Symbol]; !ok {
+			callCtx, cancel := cfg.Calls.Context(ctx, callpolicy.Klines)
+			ks, err := klines.Kline(callCtx, r.Symbol, cfg.Interval, 1500)
+			cancel()
+			if err != nil {
+				return report, fmt.Errorf("failed to load klines for %s: %w", r.Symbol, err)
+			}
+			candles[r.Symbol] = ks
+		}
+
+		signal := brain.SignalData(r.Symbol, r.Candidate, r.Inputs)
+		c := Case{
+			Time:      r.Time,
+			Symbol:    r.Symbol,
+			Side:      r.Candidate,
+			Live:      ask(ctx, cfg, live, decider, r.Candidate, signal),
+			Candidate: ask(ctx, cfg, candidate, decider, r.Candidate, signal),
+			Outcome:   simulate(cfg, r, candles[r.Symbol]),
+		}
+		tally(&report.Live, c.Live, c.Outcome)
+		tally(&report.Candidate, c.Candidate, c.Outcome)
+		if c.Differs() {
+			report.Differ++
+		}
+		report.Cases = append(report.Cases, c)
+	}
+	return report, nil
+}
+
+func ask(ctx context.Context, cfg Config, prompt Prompt, decider Decider, side string, signal map[string]interface{}) Verdict {
+	text, err := prompt(signal)
+	if err != nil {
+		return Verdict{Err: err.Error()}
+	}
+	callCtx, cancel := cfg.Calls.Context(ctx, callpolicy.LLM)
+	defer cancel()
+	decision, err := decider.DecideWithPrompt(callCtx, text)
+	if err != nil {
+		return Verdict{Err: err.Error()}
+	}
+
+	want := "BUY"
+	if side == "SHORT" {
+		want = "SELL"
+	}
+	return Verdict{
+		Decision:   decision.Decision,
+		Confidence: decision.Confidence,
+		Enter:      strings.EqualFold(decision.Decision, want) && decision.Confidence >= cfg.MinConfidence,
+	}
+}
+
+func tally(t *Tally, v Verdict, o Outcome) {
+	if v.Err != "" {
+		t.Errors++
+		return
+	}
+	if !v.Enter {
+		return
+	}
+	t.Entries++
+	if o.Known {
+		t.ReturnPct += o.ReturnPct
+		if o.ReturnPct > 0 {
+			t.Wins++
+		}
+	}
+}
+
+// simulate walks the candles after the decision until the stop or target
+// is touched or the horizon passes, closing at the last candle seen when
+// neither is. A candle touching both counts as the stop, since the order
+// within it is unknown.
+func simulate(cfg Config, r decisionlog.Record, klines []trade.Kline) Outcome {
+	entry := r.Inputs["price"]
+	dir := 1.0
+	if r.Candidate == "SHORT" {
+		dir = -1
+	}
+	stop := entry * (1 - dir*cfg.StopLossPercent/100)
+	target := entry * (1 + dir*cfg.TakeProfitPercent/100)
+	end := r.Time.Add(cfg.Horizon)
+
+	var last *trade.Kline
+	for i := range klines {
+		k := &klines[i]
+		if k.OpenTime.Before(r.Time) {
+			continue
+		}
+		if k.OpenTime.After(end) {
+			break
+		}
+		last = k
+		if cfg.StopLossPercent > 0 && (dir > 0 && k.Low <= stop || dir < 0 && k.High >= stop) {
+			return Outcome{Known: true, Exit: "stop_loss", ReturnPct: -cfg.StopLossPercent}
+		}
+		if cfg.TakeProfitPercent > 0 && (dir > 0 && k.High >= target || dir < 0 && k.Low <= target) {
+			return Outcome{Known: true, Exit: "take_profit", ReturnPct: cfg.TakeProfitPercent}
+		}
+	}
+	if last == nil {
+		return Outcome{}
+	}
+	return Outcome{Known: true, Exit: "horizon", ReturnPct: dir * (last.Close - entry) / entry * 100}
+}
@@ -0,0 +1,81 @@
+package promptlab
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/brain"
+	"github.com/britej3/gobot/services/decisionlog"
+)
+
+// fakeBrain buys whatever the candidate prompt asks about and holds on
+// everything else.
+type fakeBrain struct{}
+
+func (fakeBrain) DecideWithPrompt(ctx context.Context, prompt string) (*brain.TradingDecision, error) {
+	if strings.HasPrefix(prompt, "candidate") {
+		return &brain.TradingDecision{Decision: "BUY", Confidence: 0.8}, nil
+	}
+	return &brain.TradingDecision{Decision: "HOLD", Confidence: 0.8}, nil
+}
+
+type fakeKlines struct {
+	klines []trade.Kline
+}
+
+func (f fakeKlines) Kline(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	return f.klines, nil
+}
+
+func TestRun_ReportsDifferencesAndOutcomes(t *testing.T) {
+	at := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	records := []decisionlog.Record{
+		{Time: at, Symbol: "BTCUSDT", Candidate: "LONG", Inputs: map[string]float64{"price": 100, "rsi": 55}},
+		{Time: at, Symbol: "BTCUSDT", Candidate: "LONG"}, // logged before inputs were recorded
+		{Time: at.Add(time.Minute), Symbol: "BTCUSDT", Candidate: "SHORT", Inputs: map[string]float64{"price": 100}},
+	}
+	klines := fakeKlines{[]trade.Kline{
+		{OpenTime: at.Add(-time.Minute), High: 110, Low: 90, Close: 100},
+		{OpenTime: at, High: 100.5, Low: 99.5, Close: 100},
+		{OpenTime: at.Add(time.Minute), High: 102.5, Low: 100, Close: 102},
+	}}
+	live, err := brain.ParsePrompt("live {{.Data.symbol}} {{.Signal}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidate, err := brain.ParsePrompt("candidate {{.Data.side}} rsi {{.Data.rsi}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(context.Background(), Config{MinConfidence: 0.7, StopLossPercent: 1, TakeProfitPercent: 2},
+		records, live.Render, candidate.Render, fakeBrain{}, klines)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Cases) != 2 || report.Differ != 1 {
+		t.Fatalf("replayed %d cases with %d differing, want 2 and 1", len(report.Cases), report.Differ)
+	}
+	long, short := report.Cases[0], report.Cases[1]
+	if long.Live.Enter || !long.Candidate.Enter {
+		t.Errorf("long verdicts = %+v / %+v, want only the candidate entering", long.Live, long.Candidate)
+	}
+	if long.Outcome.Exit != "take_profit" || long.Outcome.ReturnPct != 2 {
+		t.Errorf("long outcome = %+v, want the target hit", long.Outcome)
+	}
+	// The short setup has no rsi, so the candidate template fails on it
+	// instead of rendering a blank.
+	if short.Candidate.Err == "" || short.Outcome.Exit != "stop_loss" {
+		t.Errorf("short case = %+v", short)
+	}
+	if report.Candidate.Entries != 1 || report.Candidate.Wins != 1 || report.Candidate.Errors != 1 {
+		t.Errorf("candidate tally = %+v", report.Candidate)
+	}
+	if report.Live.Entries != 0 || report.Live.Errors != 0 {
+		t.Errorf("live tally = %+v", report.Live)
+	}
+}
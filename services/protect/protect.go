@@ -0,0 +1,524 @@
+// Package protect keeps a stop loss and take profit on the exchange behind
+// every position the bot opens. The orders close the whole position, so
+// they hold whatever fills later, and the bot's own stops no longer depend
+// on it staying up.
+//
+// Orders cancelled, expired or rejected while their position is open are
+// re-placed at once from the account stream, and a periodic reconcile
+// against the open orders catches what the stream missed. A position left
+// without its orders for longer than AlertAfter is alerted, and alerted
+// again when it is covered.
+package protect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/callpolicy"
+)
+
+// Kinds of protective order.
+const (
+	KindStop       = "stop"
+	KindTakeProfit = "take_profit"
+)
+
+// Position is an open position and its trigger prices. Side is the side
+// that opened it; a zero trigger places no order of that kind.
+type Position struct {
+	Symbol     string
+	Side       trade.Side
+	Stop       float64
+	TakeProfit float64
+}
+
+func (p Position) trigger(kind string) float64 {
+	if kind == KindStop {
+		return p.Stop
+	}
+	return p.TakeProfit
+}
+
+// Order is an open protective order on the exchange.
+type Order struct {
+	ID     string
+	Symbol string
+	Kind   string
+}
+
+// Venue places and lists protective orders. Positions returns the signed
+// size of every open position by symbol.
+type Venue interface {
+	Place(ctx context.Context, p Position, kind string, trigger float64) (id string, err error)
+	Cancel(ctx context.Context, symbol, id string) error
+	Open(ctx context.Context) ([]Order, error)
+	Positions(ctx context.Context) (map[string]float64, error)
+}
+
+// Update is a protective order's status from the account stream.
+type Update struct {
+	ID     string
+	Symbol string
+	Status string
+}
+
+// Statuses an Update may carry. Only lost orders are re-placed; a
+// triggered one is closing its position.
+const (
+	StatusCanceled  = "CANCELED"
+	StatusExpired   = "EXPIRED"
+	StatusRejected  = "REJECTED"
+	StatusTriggered = "TRIGGERED"
+	StatusFinished  = "FINISHED"
+)
+
+type Config struct {
+	// Interval between reconciles against the exchange. Default 30s.
+	Interval time.Duration
+	// AlertAfter is how long a position may go without its orders before
+	// it is alerted. Default 5s.
+	AlertAfter time.Duration
+	Calls      *callpolicy.Policy
+}
+
+// Alert reports a position without its protective orders, or, with
+// Restored, one covered again.
+type Alert struct {
+	Symbol   string
+	Missing  []string
+	For      time.Duration
+	Restored bool
+	Err      error
+}
+
+func (a Alert) String() string {
+	if a.Restored {
+		return fmt.Sprintf("%s is protected again after %s", a.Symbol, a.For.Round(time.Second))
+	}
+	s := fmt.Sprintf("%s has been without its %v order for %s", a.Symbol, a.Missing, a.For.Round(time.Second))
+	if a.Err != nil {
+		s += fmt.Sprintf(" (last attempt: %v)", a.Err)
+	}
+	return s
+}
+
+// Stats counts the orders placed and lost since start. Unprotected lists
+// the positions missing an order now.
+type Stats struct {
+	Tracked     int      `json:"tracked"`
+	Placed      int      `json:"placed"`
+	Lost        int      `json:"lost"`
+	Replaced    int      `json:"replaced"`
+	Failures    int      `json:"failures"`
+	Alerts      int      `json:"alerts"`
+	Unprotected []string `json:"unprotected"`
+}
+
+type tracked struct {
+	pos     Position
+	orders  map[string]string
+	missing time.Time
+	lastErr error
+	alerted bool
+	closing bool
+	// placing is set while ensure has orders in flight, placedAt when they
+	// landed, so a reconcile does not place them twice.
+	placing  bool
+	placedAt time.Time
+	// since is when Protect took the position on.
+	since time.Time
+}
+
+type Guard struct {
+	cfg       Config
+	venue     Venue
+	mu        sync.Mutex
+	running   bool
+	positions map[string]*tracked
+	handlers  []func(Alert)
+	stats     Stats
+	stopCh    chan struct{}
+	now       func() time.Time
+}
+
+func New(cfg Config, venue Venue) *Guard {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.AlertAfter <= 0 {
+		cfg.AlertAfter = 5 * time.Second
+	}
+	return &Guard{
+		cfg:       cfg,
+		venue:     venue,
+		positions: make(map[string]*tracked),
+		now:       time.Now,
+	}
+}
+
+// OnAlert registers fn for unprotected and restored positions.
+func (g *Guard) OnAlert(fn func(Alert)) {
+	g.mu.Lock()
+	g.handlers = append(g.handlers, fn)
+	g.mu.Unlock()
+}
+
+func (g *Guard) Start(ctx context.Context) error {
+	g.mu.Lock()
+	if g.running {
+		g.mu.Unlock()
+		return nil
+	}
+	g.running = true
+	g.stopCh = make(chan struct{})
+	stop := g.stopCh
+	g.mu.Unlock()
+
+	g.Reconcile(ctx)
+	go g.run(ctx, stop)
+	return nil
+}
+
+func (g *Guard) Stop() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.running {
+		return nil
+	}
+	g.running = false
+	close(g.stopCh)
+	return nil
+}
+
+// run reconciles every Interval and checks for overdue alerts every second,
+// which costs no request.
+func (g *Guard) run(ctx context.Context, stop <-chan struct{}) {
+	reconcile := time.NewTicker(g.cfg.Interval)
+	defer reconcile.Stop()
+	alerts := time.NewTicker(time.Second)
+	defer alerts.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-reconcile.C:
+			g.Reconcile(ctx)
+		case <-alerts.C:
+			g.alert()
+		}
+	}
+}
+
+// Protect places p's orders, replacing those of an earlier position on the
+// symbol.
+func (g *Guard) Protect(ctx context.Context, p Position) error {
+	g.mu.Lock()
+	old := g.positions[p.Symbol]
+	now := g.now()
+	g.positions[p.Symbol] = &tracked{pos: p, orders: make(map[string]string), missing: now, since: now}
+	g.mu.Unlock()
+
+	if old != nil {
+		g.cancel(ctx, p.Symbol, old.orders)
+	}
+	return g.ensure(ctx, p.Symbol)
+}
+
+// Protected returns the position tracked for symbol.
+func (g *Guard) Protected(symbol string) (Position, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t := g.positions[symbol]
+	if t == nil {
+		return Position{}, false
+	}
+	return t.pos, true
+}
+
+// TightenStop moves symbol's stop to stop and replaces its stop order. A
+// stop that would sit further from the position than the current one, or
+// a position closing or with orders in flight, is left alone; it reports
+// whether the stop moved.
+func (g *Guard) TightenStop(ctx context.Context, symbol string, stop float64) (bool, error) {
+	g.mu.Lock()
+	t := g.positions[symbol]
+	if t == nil || t.closing || t.placing || stop <= 0 {
+		g.mu.Unlock()
+		return false, nil
+	}
+	current := t.pos.Stop
+	if current > 0 && (t.pos.Side == trade.SideBuy) != (stop > current) {
+		g.mu.Unlock()
+		return false, nil
+	}
+	t.pos.Stop = stop
+	old := make(map[string]string, 1)
+	if id, ok := t.orders[KindStop]; ok {
+		old[KindStop] = id
+		delete(t.orders, KindStop)
+	}
+	if t.missing.IsZero() {
+		t.missing = g.now()
+	}
+	g.mu.Unlock()
+
+	g.cancel(ctx, symbol, old)
+	return true, g.ensure(ctx, symbol)
+}
+
+// Release stops protecting symbol and cancels its orders, which would
+// otherwise close the next position opened on it.
+func (g *Guard) Release(ctx context.Context, symbol string) {
+	g.release(ctx, symbol, nil)
+}
+
+// release releases symbol if it is still tracked as want, or whatever is
+// tracked for it when want is nil.
+func (g *Guard) release(ctx context.Context, symbol string, want *tracked) {
+	g.mu.Lock()
+	t := g.positions[symbol]
+	if t == nil || (want != nil && t != want) {
+		g.mu.Unlock()
+		return
+	}
+	delete(g.positions, symbol)
+	g.mu.Unlock()
+
+	g.cancel(ctx, symbol, t.orders)
+}
+
+// Update handles a protective order's status from the account stream,
+// re-placing an order that was lost without holding up the stream.
+func (g *Guard) Update(u Update) {
+	g.mu.Lock()
+	t := g.positions[u.Symbol]
+	kind := ""
+	if t != nil {
+		for k, id := range t.orders {
+			if id == u.ID {
+				kind = k
+			}
+		}
+	}
+	if kind == "" {
+		g.mu.Unlock()
+		return
+	}
+
+	lost := false
+	switch u.Status {
+	case StatusCanceled, StatusExpired, StatusRejected:
+		delete(t.orders, kind)
+		g.stats.Lost++
+		if t.missing.IsZero() {
+			t.missing = g.now()
+		}
+		lost = !t.closing
+	case StatusTriggered, StatusFinished:
+		delete(t.orders, kind)
+		t.closing = true
+	}
+	g.mu.Unlock()
+
+	if lost {
+		g.count(func(s *Stats) { s.Replaced++ })
+		go func() {
+			ctx, cancel := g.cfg.Calls.Context(context.Background(), callpolicy.Order)
+			defer cancel()
+			g.ensure(ctx, u.Symbol)
+		}()
+	}
+}
+
+// Reconcile compares the tracked positions with the exchange: positions
+// that closed are released, and orders no longer open are placed again.
+func (g *Guard) Reconcile(ctx context.Context) error {
+	started := g.now()
+	callCtx, cancel := g.cfg.Calls.Context(ctx, callpolicy.Reconcile)
+	defer cancel()
+	sizes, err := g.venue.Positions(callCtx)
+	if err != nil {
+		g.count(func(s *Stats) { s.Failures++ })
+		return fmt.Errorf("protect: positions: %w", err)
+	}
+	open, err := g.venue.Open(callCtx)
+	if err != nil {
+		g.count(func(s *Stats) { s.Failures++ })
+		return fmt.Errorf("protect: open orders: %w", err)
+	}
+	live := make(map[string]bool, len(open))
+	for _, o := range open {
+		live[o.ID] = true
+	}
+
+	var unprotected []string
+	closed := make(map[string]*tracked)
+	g.mu.Lock()
+	for symbol, t := range g.positions {
+		// A position Protect is placing orders for, or took on after the
+		// sizes were read, may be missing from them: judging it closed
+		// would cancel the orders just placed. The next pass sees it.
+		if t.placing || t.placedAt.After(started) || t.since.After(started) {
+			continue
+		}
+		size := sizes[symbol]
+		if size == 0 || (size > 0) != (t.pos.Side == trade.SideBuy) {
+			closed[symbol] = t
+			continue
+		}
+		t.closing = false
+		for kind, id := range t.orders {
+			if !live[id] {
+				delete(t.orders, kind)
+				g.stats.Lost++
+			}
+		}
+		if len(g.missingKinds(t)) > 0 {
+			if t.missing.IsZero() {
+				t.missing = g.now()
+			}
+			unprotected = append(unprotected, symbol)
+		}
+	}
+	g.mu.Unlock()
+
+	for symbol, t := range closed {
+		g.release(ctx, symbol, t)
+	}
+	for _, symbol := range unprotected {
+		g.count(func(s *Stats) { s.Replaced++ })
+		orderCtx, cancel := g.cfg.Calls.Context(ctx, callpolicy.Order)
+		g.ensure(orderCtx, symbol)
+		cancel()
+	}
+	g.alert()
+	return nil
+}
+
+// ensure places whichever of symbol's orders are missing.
+func (g *Guard) ensure(ctx context.Context, symbol string) error {
+	g.mu.Lock()
+	t := g.positions[symbol]
+	if t == nil || t.closing || t.placing {
+		g.mu.Unlock()
+		return nil
+	}
+	pos, missing := t.pos, g.missingKinds(t)
+	t.placing = true
+	g.mu.Unlock()
+
+	var firstErr error
+	placed := make(map[string]string, len(missing))
+	for _, kind := range missing {
+		id, err := g.venue.Place(ctx, pos, kind, pos.trigger(kind))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s %s: %w", symbol, kind, err)
+			}
+			continue
+		}
+		placed[kind] = id
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t.placing, t.placedAt = false, g.now()
+	g.stats.Placed += len(placed)
+	if firstErr != nil {
+		g.stats.Failures++
+	}
+	if g.positions[symbol] != t {
+		// Released or replaced while the orders went out.
+		go g.cancel(context.Background(), symbol, placed)
+		return firstErr
+	}
+	for kind, id := range placed {
+		t.orders[kind] = id
+	}
+	t.lastErr = firstErr
+	if len(g.missingKinds(t)) == 0 && !t.missing.IsZero() {
+		if t.alerted {
+			g.emit(Alert{Symbol: symbol, For: g.now().Sub(t.missing), Restored: true})
+		}
+		t.missing, t.alerted = time.Time{}, false
+	}
+	return firstErr
+}
+
+// alert reports every position unprotected for longer than AlertAfter,
+// once per gap.
+func (g *Guard) alert() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	for symbol, t := range g.positions {
+		if t.missing.IsZero() || t.alerted || t.closing || now.Sub(t.missing) < g.cfg.AlertAfter {
+			continue
+		}
+		t.alerted = true
+		g.stats.Alerts++
+		g.emit(Alert{Symbol: symbol, Missing: g.missingKinds(t), For: now.Sub(t.missing), Err: t.lastErr})
+	}
+}
+
+// emit runs the handlers on their own goroutine, so they may call back into
+// the guard. Callers hold g.mu.
+func (g *Guard) emit(a Alert) {
+	handlers := g.handlers
+	go func() {
+		for _, fn := range handlers {
+			fn(a)
+		}
+	}()
+}
+
+// missingKinds lists the kinds t should have and does not. Callers hold
+// g.mu.
+func (g *Guard) missingKinds(t *tracked) []string {
+	var missing []string
+	for _, kind := range []string{KindStop, KindTakeProfit} {
+		if _, ok := t.orders[kind]; !ok && t.pos.trigger(kind) > 0 {
+			missing = append(missing, kind)
+		}
+	}
+	return missing
+}
+
+func (g *Guard) cancel(ctx context.Context, symbol string, orders map[string]string) {
+	ctx, cancel := g.cfg.Calls.Context(ctx, callpolicy.Order)
+	defer cancel()
+	for _, id := range orders {
+		g.venue.Cancel(ctx, symbol, id)
+	}
+}
+
+func (g *Guard) count(fn func(*Stats)) {
+	g.mu.Lock()
+	fn(&g.stats)
+	g.mu.Unlock()
+}
+
+func (g *Guard) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	st := g.stats
+	st.Tracked = len(g.positions)
+	st.Unprotected = nil
+	for symbol, t := range g.positions {
+		if !t.missing.IsZero() && !t.closing {
+			st.Unprotected = append(st.Unprotected, symbol)
+		}
+	}
+	sort.Strings(st.Unprotected)
+	return st
+}
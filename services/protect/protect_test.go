@@ -0,0 +1,213 @@
+package protect
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+type fakeVenue struct {
+	mu        sync.Mutex
+	open      map[string]Order
+	sizes     map[string]float64
+	placed    []Order
+	cancelled []string
+	fail      bool
+	// afterPositions runs once the sizes are read, outside the lock.
+	afterPositions func()
+}
+
+func newVenue() *fakeVenue {
+	return &fakeVenue{open: make(map[string]Order), sizes: map[string]float64{"BTCUSDT": 0.5}}
+}
+
+func (v *fakeVenue) Place(_ context.Context, p Position, kind string, _ float64) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.fail {
+		return "", errors.New("would immediately trigger")
+	}
+	o := Order{ID: strconv.Itoa(len(v.placed) + 1), Symbol: p.Symbol, Kind: kind}
+	v.placed = append(v.placed, o)
+	v.open[o.ID] = o
+	return o.ID, nil
+}
+
+func (v *fakeVenue) Cancel(_ context.Context, _, id string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.cancelled = append(v.cancelled, id)
+	delete(v.open, id)
+	return nil
+}
+
+func (v *fakeVenue) Open(context.Context) ([]Order, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var out []Order
+	for _, o := range v.open {
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+func (v *fakeVenue) Positions(context.Context) (map[string]float64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	out := make(map[string]float64, len(v.sizes))
+	for k, s := range v.sizes {
+		out[k] = s
+	}
+	if fn := v.afterPositions; fn != nil {
+		v.afterPositions = nil
+		v.mu.Unlock()
+		fn()
+		v.mu.Lock()
+	}
+	return out, nil
+}
+
+func (v *fakeVenue) placedCount() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.placed)
+}
+
+var long = Position{Symbol: "BTCUSDT", Side: trade.SideBuy, Stop: 95, TakeProfit: 110}
+
+func TestGuard_ReplacesLostOrders(t *testing.T) {
+	v := newVenue()
+	g := New(Config{}, v)
+	if err := g.Protect(context.Background(), long); err != nil {
+		t.Fatal(err)
+	}
+	if v.placedCount() != 2 {
+		t.Fatalf("placed %v, want a stop and a take profit", v.placed)
+	}
+
+	// Cancelled by hand, seen on the stream.
+	g.Update(Update{ID: "1", Symbol: "BTCUSDT", Status: StatusCanceled})
+	deadline := time.Now().Add(time.Second)
+	for v.placedCount() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if v.placedCount() != 3 || v.placed[2].Kind != KindStop {
+		t.Fatalf("placed %v, want the stop placed again", v.placed)
+	}
+
+	// Gone without a stream event, found by the reconcile.
+	v.mu.Lock()
+	delete(v.open, "2")
+	v.mu.Unlock()
+	g.Reconcile(context.Background())
+	if v.placedCount() != 4 || v.placed[3].Kind != KindTakeProfit {
+		t.Fatalf("placed %v, want the take profit placed again", v.placed)
+	}
+	if st := g.Stats(); st.Lost != 2 || len(st.Unprotected) != 0 {
+		t.Errorf("stats %+v", st)
+	}
+}
+
+func TestGuard_AlertsUnprotectedPositionAndRestore(t *testing.T) {
+	v := newVenue()
+	v.fail = true
+	now := time.Now()
+	g := New(Config{AlertAfter: 5 * time.Second}, v)
+	g.now = func() time.Time { return now }
+	alerts := make(chan Alert, 2)
+	g.OnAlert(func(a Alert) { alerts <- a })
+
+	if err := g.Protect(context.Background(), long); err == nil {
+		t.Fatal("failed placement not returned")
+	}
+	g.alert()
+	select {
+	case a := <-alerts:
+		t.Fatalf("alerted before AlertAfter: %v", a)
+	default:
+	}
+
+	now = now.Add(6 * time.Second)
+	g.alert()
+	if a := <-alerts; a.Restored || len(a.Missing) != 2 || a.Err == nil {
+		t.Errorf("alert %+v", a)
+	}
+
+	v.fail = false
+	g.Reconcile(context.Background())
+	if a := <-alerts; !a.Restored {
+		t.Errorf("alert %+v, want restored", a)
+	}
+}
+
+func TestGuard_ReleasesClosedPositions(t *testing.T) {
+	v := newVenue()
+	g := New(Config{}, v)
+	g.Protect(context.Background(), long)
+
+	v.mu.Lock()
+	v.sizes = map[string]float64{}
+	v.mu.Unlock()
+	g.Reconcile(context.Background())
+
+	if st := g.Stats(); st.Tracked != 0 {
+		t.Errorf("closed position still tracked: %+v", st)
+	}
+	if len(v.cancelled) != 2 {
+		t.Errorf("cancelled %v, want both leftover orders", v.cancelled)
+	}
+}
+
+func TestGuard_KeepsPositionsProtectedDuringReconcile(t *testing.T) {
+	v := newVenue()
+	v.sizes = map[string]float64{}
+	now := time.Now()
+	g := New(Config{}, v)
+	g.now = func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}
+
+	// The entry fills and is protected after the reconcile read the
+	// sizes, which do not have it yet.
+	v.afterPositions = func() {
+		if err := g.Protect(context.Background(), long); err != nil {
+			t.Error(err)
+		}
+	}
+	g.Reconcile(context.Background())
+
+	if st := g.Stats(); st.Tracked != 1 {
+		t.Errorf("position protected during the reconcile was released: %+v", st)
+	}
+	if len(v.cancelled) != 0 {
+		t.Errorf("cancelled %v, want the new orders kept", v.cancelled)
+	}
+}
+
+func TestGuard_TightensStopsOnly(t *testing.T) {
+	v := newVenue()
+	g := New(Config{}, v)
+	if err := g.Protect(context.Background(), long); err != nil {
+		t.Fatal(err)
+	}
+
+	if moved, _ := g.TightenStop(context.Background(), "BTCUSDT", 90); moved {
+		t.Error("a lower stop on a long was taken as tighter")
+	}
+	moved, err := g.TightenStop(context.Background(), "BTCUSDT", 98)
+	if err != nil || !moved {
+		t.Fatalf("moved %v, err %v; want the stop raised to 98", moved, err)
+	}
+	if p, _ := g.Protected("BTCUSDT"); p.Stop != 98 || p.TakeProfit != 110 {
+		t.Errorf("protected %+v, want the stop at 98 and the target kept", p)
+	}
+	if len(v.cancelled) != 1 || v.cancelled[0] != "1" || v.placedCount() != 3 || v.placed[2].Kind != KindStop {
+		t.Errorf("cancelled %v, placed %v; want the old stop replaced", v.cancelled, v.placed)
+	}
+}
@@ -0,0 +1,358 @@
+// Package regime rotates strategies with the market regime. A reference
+// market is classified as trending, choppy or in a volatility squeeze, and
+// each regime's rule turns strategies on or off through the suspension
+// controller: breakout strategies in a trend, mean reversion in chop, the
+// scalper off in a squeeze. A new regime has to be read on several checks
+// in a row, and the last switch has to be old enough, before strategies are
+// rotated, so a market hovering at a threshold does not flap them.
+package regime
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/squeeze"
+	"github.com/britej3/gobot/services/suspension"
+)
+
+// Regimes.
+const (
+	Trend   = "trend"
+	Chop    = "chop"
+	Squeeze = "squeeze"
+)
+
+// Source marks suspensions made by the rotator. Only those are lifted
+// again; a strategy suspended by an operator stays suspended.
+const Source = "regime"
+
+type KlineSource interface {
+	Klines(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error)
+}
+
+// Switcher turns strategies on and off, typically the suspension controller.
+type Switcher interface {
+	Suspend(target, reason, source string) error
+	Resume(target string) bool
+	List() []suspension.Entry
+}
+
+// Rule lists the strategies a regime turns on and off. Strategies in
+// neither list are left as they are.
+type Rule struct {
+	Enable  []string
+	Disable []string
+}
+
+// DefaultRules runs the momentum breakout strategy in a trend and the grid
+// in chop, and stands the scalper down while volatility is squeezed.
+func DefaultRules() map[string]Rule {
+	return map[string]Rule{
+		Trend:   {Enable: []string{"momentum_strategy", "scalper_strategy"}, Disable: []string{"grid_strategy"}},
+		Chop:    {Enable: []string{"grid_strategy", "scalper_strategy"}, Disable: []string{"momentum_strategy"}},
+		Squeeze: {Disable: []string{"scalper_strategy"}},
+	}
+}
+
+// Config sets how the regime is read and how eagerly it switches. The
+// efficiency ratio is the net move over Length candles divided by the sum
+// of the candle-to-candle moves: at or above TrendEfficiency the market is
+// trending, at or below ChopEfficiency it is chopping, and in between the
+// current regime holds. A squeeze (Bollinger Bands inside the Keltner
+// Channel) overrides both.
+type Config struct {
+	Symbol          string
+	Interval        string
+	Length          int
+	TrendEfficiency float64
+	ChopEfficiency  float64
+	// Confirm is how many checks in a row must read a new regime.
+	Confirm int
+	// MinDwell is the least time between two switches.
+	MinDwell      time.Duration
+	CheckInterval time.Duration
+	Rules         map[string]Rule
+	// OnDecision is called for every strategy the rotator turns on or off,
+	// or leaves alone because an operator suspended it.
+	OnDecision func(Decision)
+}
+
+// Reading is one classification of the reference market. Regime is empty
+// when the efficiency falls between the thresholds and there is no squeeze.
+type Reading struct {
+	Regime     string
+	Efficiency float64
+	Squeeze    bool
+	At         time.Time
+}
+
+// Decision is one strategy activation. Applied is false when the strategy
+// was already in the wanted state or was suspended by someone else.
+type Decision struct {
+	Regime   string
+	Previous string
+	Strategy string
+	Enable   bool
+	Applied  bool
+	Detail   string
+	At       time.Time
+}
+
+func (d Decision) String() string {
+	verb := "disable"
+	if d.Enable {
+		verb = "enable"
+	}
+	return fmt.Sprintf("%s -> %s: %s %s (%s)", d.Previous, d.Regime, verb, d.Strategy, d.Detail)
+}
+
+type Stats struct {
+	Regime     string
+	Since      time.Time
+	Efficiency float64
+	Candidate  string
+	Streak     int
+	Switches   int
+	Checks     int
+	Errors     int
+	LastError  string
+}
+
+type Rotator struct {
+	cfg      Config
+	source   KlineSource
+	switcher Switcher
+	mu       sync.Mutex
+	running  bool
+	stats    Stats
+	stopCh   chan struct{}
+	now      func() time.Time
+}
+
+func New(cfg Config, source KlineSource, sw Switcher) *Rotator {
+	if cfg.Symbol == "" {
+		cfg.Symbol = "BTCUSDT"
+	}
+	if cfg.Interval == "" {
+		cfg.Interval = "1h"
+	}
+	if cfg.Length <= 1 {
+		cfg.Length = 20
+	}
+	if cfg.TrendEfficiency <= 0 {
+		cfg.TrendEfficiency = 0.35
+	}
+	if cfg.ChopEfficiency <= 0 {
+		cfg.ChopEfficiency = 0.2
+	}
+	if cfg.Confirm <= 0 {
+		cfg.Confirm = 3
+	}
+	if cfg.MinDwell <= 0 {
+		cfg.MinDwell = time.Hour
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Minute
+	}
+	if len(cfg.Rules) == 0 {
+		cfg.Rules = DefaultRules()
+	}
+
+	return &Rotator{
+		cfg:      cfg,
+		source:   source,
+		switcher: sw,
+		stopCh:   make(chan struct{}),
+		now:      time.Now,
+	}
+}
+
+func (r *Rotator) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return nil
+	}
+	r.running = true
+	go r.run(ctx)
+	return nil
+}
+
+func (r *Rotator) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.running {
+		return nil
+	}
+	r.running = false
+	close(r.stopCh)
+	return nil
+}
+
+func (r *Rotator) run(ctx context.Context) {
+	r.Check(ctx)
+
+	ticker := time.NewTicker(r.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.Check(ctx)
+		}
+	}
+}
+
+// Check reads the reference market once and rotates strategies if the
+// reading confirms a new regime.
+func (r *Rotator) Check(ctx context.Context) {
+	klines, err := r.source.Klines(ctx, r.cfg.Symbol, r.cfg.Interval, 3*r.cfg.Length)
+	if err != nil {
+		r.mu.Lock()
+		r.stats.Checks++
+		r.stats.Errors++
+		r.stats.LastError = err.Error()
+		r.mu.Unlock()
+		return
+	}
+	reading, ok := Classify(klines, r.cfg)
+	if !ok {
+		r.mu.Lock()
+		r.stats.Checks++
+		r.stats.Errors++
+		r.stats.LastError = fmt.Sprintf("%d %s candles are not enough to classify %s", len(klines), r.cfg.Interval, r.cfg.Symbol)
+		r.mu.Unlock()
+		return
+	}
+	reading.At = r.now()
+	r.Observe(reading)
+}
+
+// Observe applies one reading: it counts toward the streak of its regime
+// and switches once the streak reaches Confirm and MinDwell has passed.
+func (r *Rotator) Observe(reading Reading) {
+	r.mu.Lock()
+	r.stats.Checks++
+	r.stats.Efficiency = reading.Efficiency
+	current := r.stats.Regime
+	if reading.Regime == "" || reading.Regime == current {
+		r.stats.Candidate, r.stats.Streak = "", 0
+		r.mu.Unlock()
+		return
+	}
+	if reading.Regime == r.stats.Candidate {
+		r.stats.Streak++
+	} else {
+		r.stats.Candidate, r.stats.Streak = reading.Regime, 1
+	}
+	if r.stats.Streak < r.cfg.Confirm || (current != "" && reading.At.Sub(r.stats.Since) < r.cfg.MinDwell) {
+		r.mu.Unlock()
+		return
+	}
+	r.stats.Regime, r.stats.Since = reading.Regime, reading.At
+	r.stats.Candidate, r.stats.Streak = "", 0
+	r.stats.Switches++
+	r.mu.Unlock()
+
+	r.apply(current, reading)
+}
+
+// apply enables and disables the strategies of the new regime's rule.
+func (r *Rotator) apply(previous string, reading Reading) {
+	rule := r.cfg.Rules[reading.Regime]
+	suspended := make(map[string]suspension.Entry)
+	for _, e := range r.switcher.List() {
+		suspended[e.Target] = e
+	}
+
+	detail := fmt.Sprintf("efficiency %.2f over %d %s candles of %s", reading.Efficiency, r.cfg.Length, r.cfg.Interval, r.cfg.Symbol)
+	if reading.Squeeze {
+		detail = fmt.Sprintf("volatility squeeze on %s %s", r.cfg.Symbol, r.cfg.Interval)
+	}
+
+	var decisions []Decision
+	for _, name := range rule.Enable {
+		d := Decision{Regime: reading.Regime, Previous: previous, Strategy: name, Enable: true, Detail: detail, At: reading.At}
+		e, ok := suspended[name]
+		switch {
+		case !ok:
+			d.Detail = "already enabled"
+		case e.Source != Source:
+			d.Detail = fmt.Sprintf("left suspended by %s: %s", e.Source, e.Reason)
+		default:
+			d.Applied = r.switcher.Resume(name)
+		}
+		decisions = append(decisions, d)
+	}
+	for _, name := range rule.Disable {
+		d := Decision{Regime: reading.Regime, Previous: previous, Strategy: name, Detail: detail, At: reading.At}
+		if _, ok := suspended[name]; ok {
+			d.Detail = "already suspended"
+		} else if err := r.switcher.Suspend(name, "regime "+reading.Regime+": "+detail, Source); err != nil {
+			d.Detail = err.Error()
+		} else {
+			d.Applied = true
+		}
+		decisions = append(decisions, d)
+	}
+
+	if r.cfg.OnDecision != nil {
+		for _, d := range decisions {
+			r.cfg.OnDecision(d)
+		}
+	}
+}
+
+// Classify reads the regime at the last of klines. It needs 2*Length
+// candles.
+func Classify(klines []trade.Kline, cfg Config) (Reading, bool) {
+	n := cfg.Length
+	if n <= 1 || len(klines) < 2*n {
+		return Reading{}, false
+	}
+
+	sq, ok := squeeze.Measure(klines, squeeze.Config{Length: n, BBMult: 2, KCMult: 1.5})
+	if !ok {
+		return Reading{}, false
+	}
+	er := efficiency(klines[len(klines)-n-1:])
+	reading := Reading{Efficiency: er, Squeeze: sq.On}
+	switch {
+	case sq.On:
+		reading.Regime = Squeeze
+	case er >= cfg.TrendEfficiency:
+		reading.Regime = Trend
+	case er <= cfg.ChopEfficiency:
+		reading.Regime = Chop
+	}
+	return reading, true
+}
+
+// efficiency is Kaufman's efficiency ratio of the closes: 1 for a straight
+// line, near 0 for a market going nowhere.
+func efficiency(klines []trade.Kline) float64 {
+	var path float64
+	for i := 1; i < len(klines); i++ {
+		path += math.Abs(klines[i].Close - klines[i-1].Close)
+	}
+	if path == 0 {
+		return 0
+	}
+	return math.Abs(klines[len(klines)-1].Close-klines[0].Close) / path
+}
+
+func (r *Rotator) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
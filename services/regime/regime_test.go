@@ -0,0 +1,121 @@
+package regime
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/suspension"
+)
+
+type switches struct {
+	entries map[string]suspension.Entry
+}
+
+func (s *switches) Suspend(target, reason, source string) error {
+	s.entries[target] = suspension.Entry{Target: target, Reason: reason, Source: source}
+	return nil
+}
+
+func (s *switches) Resume(target string) bool {
+	_, ok := s.entries[target]
+	delete(s.entries, target)
+	return ok
+}
+
+func (s *switches) List() []suspension.Entry {
+	out := make([]suspension.Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e)
+	}
+	return out
+}
+
+func TestClassify(t *testing.T) {
+	cfg := Config{Length: 20, TrendEfficiency: 0.35, ChopEfficiency: 0.2}
+
+	trend := make([]trade.Kline, 60)
+	chop := make([]trade.Kline, 60)
+	for i := range trend {
+		// A steady climb; the bands widen well past the channel.
+		c := 100 + float64(i)
+		trend[i] = trade.Kline{Open: c, High: c + 0.6, Low: c - 0.6, Close: c}
+		// Swings back and forth, wide enough that the bands stay outside too.
+		c = 100 + 5*math.Sin(float64(i)*math.Pi/4)
+		chop[i] = trade.Kline{Open: c, High: c + 0.1, Low: c - 0.1, Close: c}
+	}
+	if r, ok := Classify(trend, cfg); !ok || r.Regime != Trend {
+		t.Errorf("trend classified as %+v", r)
+	}
+	if r, ok := Classify(chop, cfg); !ok || r.Regime != Chop {
+		t.Errorf("chop classified as %+v", r)
+	}
+
+	flat := make([]trade.Kline, 60)
+	for i := range flat {
+		c := 100 + float64(i%2)*0.01
+		flat[i] = trade.Kline{Open: c, High: c + 1, Low: c - 1, Close: c}
+	}
+	if r, ok := Classify(flat, cfg); !ok || r.Regime != Squeeze || !r.Squeeze {
+		t.Errorf("squeeze classified as %+v", r)
+	}
+	if _, ok := Classify(flat[:30], cfg); ok {
+		t.Error("too few candles should not classify")
+	}
+}
+
+func TestRotator_ConfirmsAndDwellsBeforeSwitching(t *testing.T) {
+	sw := &switches{entries: map[string]suspension.Entry{
+		"grid_strategy": {Target: "grid_strategy", Reason: "manual", Source: suspension.SourceTelegram},
+	}}
+	var decisions []Decision
+	r := New(Config{Confirm: 2, MinDwell: time.Hour, OnDecision: func(d Decision) { decisions = append(decisions, d) }}, nil, sw)
+
+	at := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	read := func(regime string) {
+		at = at.Add(5 * time.Minute)
+		r.Observe(Reading{Regime: regime, At: at})
+	}
+
+	read(Chop)
+	if r.Stats().Regime != "" || len(decisions) != 0 {
+		t.Fatalf("one reading should not switch: %+v", r.Stats())
+	}
+	read(Chop)
+	if r.Stats().Regime != Chop {
+		t.Fatalf("two readings should switch to chop: %+v", r.Stats())
+	}
+	if _, ok := sw.entries["momentum_strategy"]; !ok {
+		t.Error("chop should suspend momentum")
+	}
+	if e := sw.entries["grid_strategy"]; e.Source != suspension.SourceTelegram {
+		t.Error("a strategy suspended by an operator must stay suspended")
+	}
+	for _, d := range decisions {
+		if d.Strategy == "grid_strategy" && d.Applied {
+			t.Errorf("grid enable should not apply: %v", d)
+		}
+	}
+
+	// Trend is confirmed but the hour since the last switch has not passed.
+	read(Trend)
+	read(Trend)
+	if r.Stats().Regime != Chop {
+		t.Fatalf("switched within the dwell time: %+v", r.Stats())
+	}
+	// A reading between the thresholds breaks the streak.
+	read("")
+	at = at.Add(time.Hour)
+	read(Trend)
+	if r.Stats().Regime != Chop {
+		t.Fatalf("streak should have restarted: %+v", r.Stats())
+	}
+	read(Trend)
+	if s := r.Stats(); s.Regime != Trend || s.Switches != 2 {
+		t.Fatalf("trend not applied: %+v", s)
+	}
+	if _, ok := sw.entries["momentum_strategy"]; ok {
+		t.Error("trend should resume momentum")
+	}
+}
@@ -0,0 +1,458 @@
+// Package report renders a run's trades as a self-contained HTML page: the
+// equity curve and its drawdowns, histograms of trade returns and holding
+// times, and tables per symbol and per trading session. Charts are inline
+// SVG, so the file opens offline and can be sent as a chat attachment.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/equity"
+)
+
+// Curve sources.
+const (
+	SourceAccount  = "account"
+	SourceRealized = "realized"
+)
+
+type Config struct {
+	Title string
+	// Capital is the balance the realized equity curve starts from when
+	// there are no account snapshots.
+	Capital float64
+	// Bins is how many buckets the return histogram has, 12 by default.
+	Bins int
+}
+
+type Summary struct {
+	Trades       int
+	Wins         int
+	Losses       int
+	WinRate      float64
+	PnL          float64
+	AvgWin       float64
+	AvgLoss      float64
+	ProfitFactor float64
+	Best         float64
+	Worst        float64
+	MaxDrawdown  float64
+	From         time.Time
+	To           time.Time
+}
+
+// Row is one line of a per-symbol or per-session table.
+type Row struct {
+	Key     string
+	Trades  int
+	Wins    int
+	WinRate float64
+	PnL     float64
+	AvgPnL  float64
+}
+
+// Bucket is one histogram bar.
+type Bucket struct {
+	Label string
+	Count int
+}
+
+type Report struct {
+	Title     string
+	Generated time.Time
+	Summary   Summary
+	Curve     equity.Curve
+	// CurveSource is SourceAccount when the curve comes from recorded
+	// account snapshots, SourceRealized when rebuilt from closed trades.
+	CurveSource string
+	Returns     []Bucket
+	Holding     []Bucket
+	Symbols     []Row
+	Sessions    []Row
+	// Ideas splits trades by who submitted the idea they were entered on,
+	// the bot's own picks under "bot". Empty when no trade came from one.
+	Ideas []Row
+}
+
+// Session returns the trading session t falls in, by UTC hour: asia before
+// 08:00, europe until 16:00, us after.
+func Session(t time.Time) string {
+	switch h := t.UTC().Hour(); {
+	case h < 8:
+		return "asia"
+	case h < 16:
+		return "europe"
+	}
+	return "us"
+}
+
+// Build reports on the closed trades among trades. snaps, oldest first,
+// draw the equity curve; without them it is rebuilt from cfg.Capital and
+// the trades' realized PnL.
+func Build(cfg Config, trades []state.Trade, snaps []equity.Snapshot) Report {
+	if cfg.Bins <= 0 {
+		cfg.Bins = 12
+	}
+	if cfg.Title == "" {
+		cfg.Title = "Trading report"
+	}
+
+	closed := make([]state.Trade, 0, len(trades))
+	for _, t := range trades {
+		if !t.ExitTime.IsZero() {
+			closed = append(closed, t)
+		}
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i].ExitTime.Before(closed[j].ExitTime) })
+
+	r := Report{Title: cfg.Title, Generated: time.Now(), CurveSource: SourceAccount}
+	if len(snaps) == 0 {
+		r.CurveSource = SourceRealized
+		snaps = realized(cfg.Capital, closed)
+	}
+	r.Curve = equity.BuildCurve(snaps)
+	r.Summary = summarize(closed)
+	r.Summary.MaxDrawdown = r.Curve.MaxDrawdown
+	r.Returns = returns(closed, cfg.Bins)
+	r.Holding = holding(closed)
+	r.Symbols = group(closed, func(t state.Trade) string { return t.Symbol })
+	r.Sessions = group(closed, func(t state.Trade) string { return Session(t.EntryTime) })
+	for _, t := range closed {
+		if t.Idea != "" {
+			r.Ideas = group(closed, ideaSource)
+			break
+		}
+	}
+	return r
+}
+
+// ideaSource is who picked a trade: the idea's submitter, or the bot.
+func ideaSource(t state.Trade) string {
+	if t.Idea == "" {
+		return "bot"
+	}
+	return "idea: " + t.Idea
+}
+
+// realized turns closed trades into equity points starting from capital.
+func realized(capital float64, closed []state.Trade) []equity.Snapshot {
+	if len(closed) == 0 {
+		return nil
+	}
+	eq := capital
+	out := []equity.Snapshot{{Time: closed[0].EntryTime, Equity: eq}}
+	for _, t := range closed {
+		eq += t.PnL
+		out = append(out, equity.Snapshot{Time: t.ExitTime, Equity: eq})
+	}
+	return out
+}
+
+func summarize(closed []state.Trade) Summary {
+	var s Summary
+	var won, lost float64
+	for i, t := range closed {
+		s.Trades++
+		s.PnL += t.PnL
+		if t.PnL > 0 {
+			s.Wins++
+			won += t.PnL
+		} else {
+			s.Losses++
+			lost -= t.PnL
+		}
+		if i == 0 || t.PnL > s.Best {
+			s.Best = t.PnL
+		}
+		if i == 0 || t.PnL < s.Worst {
+			s.Worst = t.PnL
+		}
+		if s.From.IsZero() || t.EntryTime.Before(s.From) {
+			s.From = t.EntryTime
+		}
+		s.To = t.ExitTime
+	}
+	if s.Trades > 0 {
+		s.WinRate = float64(s.Wins) / float64(s.Trades) * 100
+	}
+	if s.Wins > 0 {
+		s.AvgWin = won / float64(s.Wins)
+	}
+	if s.Losses > 0 {
+		s.AvgLoss = -lost / float64(s.Losses)
+	}
+	if lost > 0 {
+		s.ProfitFactor = won / lost
+	}
+	return s
+}
+
+// returns buckets the trades' PnL percent into bins of equal width.
+func returns(closed []state.Trade, bins int) []Bucket {
+	if len(closed) == 0 {
+		return nil
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, t := range closed {
+		lo, hi = math.Min(lo, t.PnLPercent), math.Max(hi, t.PnLPercent)
+	}
+	if hi == lo {
+		return []Bucket{{Label: fmt.Sprintf("%+.2f%%", lo), Count: len(closed)}}
+	}
+	width := (hi - lo) / float64(bins)
+	out := make([]Bucket, bins)
+	for i := range out {
+		out[i].Label = fmt.Sprintf("%+.2f%%", lo+width*(float64(i)+0.5))
+	}
+	for _, t := range closed {
+		i := int((t.PnLPercent - lo) / width)
+		if i >= bins {
+			i = bins - 1
+		}
+		out[i].Count++
+	}
+	return out
+}
+
+var holdingBuckets = []struct {
+	label string
+	max   time.Duration
+}{
+	{"<5m", 5 * time.Minute},
+	{"5-15m", 15 * time.Minute},
+	{"15-60m", time.Hour},
+	{"1-4h", 4 * time.Hour},
+	{"4-24h", 24 * time.Hour},
+	{">1d", math.MaxInt64},
+}
+
+func holding(closed []state.Trade) []Bucket {
+	if len(closed) == 0 {
+		return nil
+	}
+	out := make([]Bucket, len(holdingBuckets))
+	for i, b := range holdingBuckets {
+		out[i].Label = b.label
+	}
+	for _, t := range closed {
+		held := t.ExitTime.Sub(t.EntryTime)
+		for i, b := range holdingBuckets {
+			if held < b.max {
+				out[i].Count++
+				break
+			}
+		}
+	}
+	return out
+}
+
+// group totals the trades by key, highest PnL first.
+func group(closed []state.Trade, key func(state.Trade) string) []Row {
+	rows := make(map[string]*Row)
+	for _, t := range closed {
+		k := key(t)
+		r := rows[k]
+		if r == nil {
+			r = &Row{Key: k}
+			rows[k] = r
+		}
+		r.Trades++
+		r.PnL += t.PnL
+		if t.PnL > 0 {
+			r.Wins++
+		}
+	}
+	out := make([]Row, 0, len(rows))
+	for _, r := range rows {
+		r.WinRate = float64(r.Wins) / float64(r.Trades) * 100
+		r.AvgPnL = r.PnL / float64(r.Trades)
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PnL > out[j].PnL })
+	return out
+}
+
+// Write renders r into dir as <name>-<time>.html and returns the file's
+// path.
+func Write(dir, name string, r Report) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.html", name, r.Generated.UTC().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := r.WriteHTML(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+func (r Report) WriteHTML(w io.Writer) error {
+	return page.Execute(w, r)
+}
+
+// Chart geometry, in SVG user units.
+const (
+	chartW = 720
+	chartH = 220
+	pad    = 8
+)
+
+// line returns the polyline points of values scaled into the chart.
+func line(values []float64, invert bool) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	var b strings.Builder
+	for i, v := range values {
+		x := float64(pad)
+		if len(values) > 1 {
+			x += float64(i) / float64(len(values)-1) * (chartW - 2*pad)
+		}
+		frac := (v - lo) / (hi - lo)
+		if invert {
+			frac = 1 - frac
+		}
+		y := pad + (1-frac)*(chartH-2*pad)
+		fmt.Fprintf(&b, "%.1f,%.1f ", x, y)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+type bar struct {
+	X, Y, W, H float64
+	Label      string
+	Count      int
+}
+
+func bars(buckets []Bucket) []bar {
+	max := 0
+	for _, b := range buckets {
+		if b.Count > max {
+			max = b.Count
+		}
+	}
+	if max == 0 {
+		return nil
+	}
+	slot := float64(chartW-2*pad) / float64(len(buckets))
+	out := make([]bar, len(buckets))
+	for i, b := range buckets {
+		h := float64(b.Count) / float64(max) * (chartH - 2*pad - 16)
+		out[i] = bar{
+			X:     pad + float64(i)*slot + 2,
+			Y:     chartH - pad - 16 - h,
+			W:     slot - 4,
+			H:     h,
+			Label: b.Label,
+			Count: b.Count,
+		}
+	}
+	return out
+}
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"equityLine": func(c equity.Curve) string {
+		v := make([]float64, len(c.Points))
+		for i, p := range c.Points {
+			v[i] = p.Equity
+		}
+		return line(v, false)
+	},
+	"drawdownLine": func(c equity.Curve) string {
+		v := make([]float64, len(c.Points))
+		for i, p := range c.Points {
+			v[i] = p.Drawdown
+		}
+		return line(v, true)
+	},
+	"bars": bars,
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.UTC().Format("2006-01-02 15:04")
+	},
+	"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"pct":   func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"sign": func(v float64) string {
+		if v < 0 {
+			return "neg"
+		}
+		return "pos"
+	},
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;margin:24px;color:#222;max-width:780px}
+h1{font-size:22px;margin-bottom:4px}h2{font-size:16px;margin-top:28px}
+.meta{color:#777;font-size:13px}
+table{border-collapse:collapse;width:100%;font-size:13px}
+td,th{padding:4px 8px;border-bottom:1px solid #eee;text-align:right}
+td:first-child,th:first-child{text-align:left}
+.pos{color:#1a7f37}.neg{color:#cf222e}
+svg{background:#fafafa;border:1px solid #eee}
+svg text{font-size:10px;fill:#555}
+</style></head><body>
+<h1>{{.Title}}</h1>
+<div class="meta">{{time .Summary.From}} to {{time .Summary.To}} UTC, generated {{time .Generated}}</div>
+
+<h2>Summary</h2>
+<table>
+<tr><td>Trades</td><td>{{.Summary.Trades}} ({{.Summary.Wins}} won, {{.Summary.Losses}} lost)</td></tr>
+<tr><td>Win rate</td><td>{{pct .Summary.WinRate}}</td></tr>
+<tr><td>Net PnL</td><td class="{{sign .Summary.PnL}}">{{money .Summary.PnL}}</td></tr>
+<tr><td>Average win / loss</td><td>{{money .Summary.AvgWin}} / {{money .Summary.AvgLoss}}</td></tr>
+<tr><td>Profit factor</td><td>{{printf "%.2f" .Summary.ProfitFactor}}</td></tr>
+<tr><td>Best / worst trade</td><td>{{money .Summary.Best}} / {{money .Summary.Worst}}</td></tr>
+<tr><td>Max drawdown</td><td>{{pct .Summary.MaxDrawdown}}</td></tr>
+</table>
+
+<h2>Equity ({{.CurveSource}})</h2>
+{{if .Curve.Points}}<svg viewBox="0 0 720 220" width="720" height="220"><polyline fill="none" stroke="#0969da" stroke-width="1.5" points="{{equityLine .Curve}}"/></svg>
+<div class="meta">peak {{money .Curve.Peak}}, now {{pct .Curve.CurrentDrawdown}} below it</div>{{else}}<p class="meta">No equity data.</p>{{end}}
+
+<h2>Drawdown</h2>
+{{if .Curve.Points}}<svg viewBox="0 0 720 220" width="720" height="220"><polyline fill="none" stroke="#cf222e" stroke-width="1.5" points="{{drawdownLine .Curve}}"/></svg>{{else}}<p class="meta">No equity data.</p>{{end}}
+
+<h2>Trade returns</h2>
+{{with bars .Returns}}<svg viewBox="0 0 720 220" width="720" height="220">{{range .}}<rect x="{{.X}}" y="{{.Y}}" width="{{.W}}" height="{{.H}}" fill="#8250df"><title>{{.Label}}: {{.Count}}</title></rect><text x="{{.X}}" y="212">{{.Label}}</text>{{end}}</svg>{{else}}<p class="meta">No closed trades.</p>{{end}}
+
+<h2>Holding time</h2>
+{{with bars .Holding}}<svg viewBox="0 0 720 220" width="720" height="220">{{range .}}<rect x="{{.X}}" y="{{.Y}}" width="{{.W}}" height="{{.H}}" fill="#bf8700"><title>{{.Label}}: {{.Count}}</title></rect><text x="{{.X}}" y="212">{{.Label}} ({{.Count}})</text>{{end}}</svg>{{else}}<p class="meta">No closed trades.</p>{{end}}
+
+<h2>By symbol</h2>
+<table><tr><th>Symbol</th><th>Trades</th><th>Win rate</th><th>PnL</th><th>Avg</th></tr>
+{{range .Symbols}}<tr><td>{{.Key}}</td><td>{{.Trades}}</td><td>{{pct .WinRate}}</td><td class="{{sign .PnL}}">{{money .PnL}}</td><td>{{money .AvgPnL}}</td></tr>
+{{end}}</table>
+
+<h2>By session (entry time, UTC)</h2>
+<table><tr><th>Session</th><th>Trades</th><th>Win rate</th><th>PnL</th><th>Avg</th></tr>
+{{range .Sessions}}<tr><td>{{.Key}}</td><td>{{.Trades}}</td><td>{{pct .WinRate}}</td><td class="{{sign .PnL}}">{{money .PnL}}</td><td>{{money .AvgPnL}}</td></tr>
+{{end}}</table>
+{{if .Ideas}}
+<h2>By source (external ideas)</h2>
+<table><tr><th>Source</th><th>Trades</th><th>Win rate</th><th>PnL</th><th>Avg</th></tr>
+{{range .Ideas}}<tr><td>{{.Key}}</td><td>{{.Trades}}</td><td>{{pct .WinRate}}</td><td class="{{sign .PnL}}">{{money .PnL}}</td><td>{{money .AvgPnL}}</td></tr>
+{{end}}</table>{{end}}
+</body></html>
+`))
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/pkg/state"
+)
+
+func TestBuild_SummarizesClosedTrades(t *testing.T) {
+	day := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	trade := func(symbol string, entryHour int, held time.Duration, pnl, pct float64) state.Trade {
+		entry := day.Add(time.Duration(entryHour) * time.Hour)
+		return state.Trade{Symbol: symbol, EntryTime: entry, ExitTime: entry.Add(held), PnL: pnl, PnLPercent: pct}
+	}
+	trades := []state.Trade{
+		trade("ETHUSDT", 10, 20*time.Minute, -5, -1),
+		trade("BTCUSDT", 2, 3*time.Minute, 10, 2),
+		trade("BTCUSDT", 18, 2*time.Hour, 20, 4),
+		{Symbol: "SOLUSDT", EntryTime: day.Add(20 * time.Hour)},
+	}
+
+	r := Build(Config{Title: "May 4", Capital: 1000, Bins: 5}, trades, nil)
+
+	s := r.Summary
+	if s.Trades != 3 || s.Wins != 2 || s.PnL != 25 || s.ProfitFactor != 6 || s.Best != 20 || s.Worst != -5 {
+		t.Fatalf("summary = %+v", s)
+	}
+	if r.CurveSource != SourceRealized || len(r.Curve.Points) != 4 || r.Curve.Points[3].Equity != 1025 {
+		t.Fatalf("curve = %s %+v", r.CurveSource, r.Curve.Points)
+	}
+	if r.Curve.Points[2].Equity != 1005 || r.Summary.MaxDrawdown == 0 {
+		t.Fatalf("expected the ETH loss to draw down the curve, got %+v", r.Curve)
+	}
+
+	if len(r.Returns) != 5 || r.Returns[0].Count != 1 || r.Returns[3].Count != 1 || r.Returns[4].Count != 1 {
+		t.Errorf("returns = %+v", r.Returns)
+	}
+	if r.Holding[0].Count != 1 || r.Holding[2].Count != 1 || r.Holding[3].Count != 1 {
+		t.Errorf("holding = %+v", r.Holding)
+	}
+	if len(r.Ideas) != 0 {
+		t.Errorf("ideas = %+v without any idea trades", r.Ideas)
+	}
+	if len(r.Symbols) != 2 || r.Symbols[0].Key != "BTCUSDT" || r.Symbols[0].Trades != 2 || r.Symbols[0].WinRate != 100 {
+		t.Errorf("symbols = %+v", r.Symbols)
+	}
+	sessions := map[string]int{}
+	for _, row := range r.Sessions {
+		sessions[row.Key] = row.Trades
+	}
+	if sessions["asia"] != 1 || sessions["europe"] != 1 || sessions["us"] != 1 {
+		t.Errorf("sessions = %+v", r.Sessions)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{"May 4", "<svg", "<polyline", "ETHUSDT", "europe"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+}
+
+func TestBuild_SplitsIdeaTrades(t *testing.T) {
+	exit := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	trades := []state.Trade{
+		{Symbol: "SOLUSDT", ExitTime: exit, PnL: 12, Idea: "desk"},
+		{Symbol: "BTCUSDT", ExitTime: exit, PnL: -3},
+		{Symbol: "ETHUSDT", ExitTime: exit, PnL: 4},
+	}
+	r := Build(Config{}, trades, nil)
+	if len(r.Ideas) != 2 || r.Ideas[0].Key != "idea: desk" || r.Ideas[0].PnL != 12 || r.Ideas[1].Key != "bot" || r.Ideas[1].Trades != 2 {
+		t.Errorf("ideas = %+v", r.Ideas)
+	}
+}
+
+func TestWrite_NamesFileByTime(t *testing.T) {
+	r := Build(Config{}, nil, nil)
+	path, err := Write(t.TempDir(), "report", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, r.Generated.UTC().Format("20060102-150405")+".html") {
+		t.Errorf("path = %s", path)
+	}
+}
@@ -0,0 +1,271 @@
+// Package research serves what the live bot sees to research notebooks: the
+// candles in the shared kline cache with the indicators computed on them,
+// and the trade and decision journals. The API is read-only and listens on
+// loopback; it only reads the cache, so a notebook never adds exchange
+// requests or changes what the bot trades on.
+//
+// Candles carry the indicators of indicator.Standard replayed candle by
+// candle, so features for model training are computed by the same code as
+// live, on the same data.
+package research
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/indicator"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/decisionlog"
+	"github.com/britej3/gobot/services/kline"
+)
+
+// ErrNotLoopback refuses an address other machines could reach.
+var ErrNotLoopback = errors.New("research API must listen on a loopback address")
+
+// Candles reads the kline cache without fetching.
+type Candles interface {
+	Cached(symbol, interval string) ([]trade.Kline, kline.Indicators, bool)
+	Buffered() map[string][]string
+}
+
+// Journal returns the journaled trades, oldest first.
+type Journal interface {
+	Trades() []state.Trade
+}
+
+type Config struct {
+	// Addr is the loopback address to listen on. Default 127.0.0.1:8091.
+	Addr string
+	// DecisionLog is the decision log's path; without one /decisions is
+	// empty.
+	DecisionLog string
+}
+
+// indicatorColumns are the Standard indicators in column order.
+var indicatorColumns = []string{indicator.EMAFast, indicator.EMASlow, indicator.RSI14, indicator.ATR14}
+
+// Candle is one cached candle with the indicators at its close. Indicators
+// is empty until enough candles precede it to warm them up.
+type Candle struct {
+	OpenTime   time.Time        `json:"open_time"`
+	Open       float64          `json:"open"`
+	High       float64          `json:"high"`
+	Low        float64          `json:"low"`
+	Close      float64          `json:"close"`
+	Volume     float64          `json:"volume"`
+	Indicators indicator.Values `json:"indicators,omitempty"`
+}
+
+type Server struct {
+	cfg     Config
+	candles Candles
+	journal Journal
+	server  *http.Server
+}
+
+func New(cfg Config, candles Candles, journal Journal) *Server {
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:8091"
+	}
+	return &Server{cfg: cfg, candles: candles, journal: journal}
+}
+
+// Start listens on Addr and serves in the background.
+func (s *Server) Start(ctx context.Context) error {
+	if err := checkAddr(s.cfg.Addr); err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 5 * time.Second}
+	go s.server.Serve(ln)
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
+}
+
+// ServeHTTP routes GET requests. Every endpoint answers JSON, and /klines
+// and /trades answer CSV with ?format=csv.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Read-only API", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	since, err := parseTime(q.Get("since"))
+	if err != nil {
+		http.Error(w, "since must be RFC 3339", http.StatusBadRequest)
+		return
+	}
+	csvOut := q.Get("format") == "csv"
+
+	switch r.URL.Path {
+	case "/symbols":
+		writeJSON(w, s.candles.Buffered())
+	case "/klines":
+		candles, ok := s.klines(q.Get("symbol"), q.Get("interval"), since)
+		if !ok {
+			http.Error(w, "Not cached", http.StatusNotFound)
+			return
+		}
+		if csvOut {
+			writeCSV(w, candleRows(candles))
+			return
+		}
+		writeJSON(w, candles)
+	case "/indicators":
+		_, ind, ok := s.candles.Cached(q.Get("symbol"), q.Get("interval"))
+		if !ok {
+			http.Error(w, "Not cached", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, ind)
+	case "/trades":
+		trades := s.trades(q.Get("symbol"), since)
+		if csvOut {
+			writeCSV(w, tradeRows(trades))
+			return
+		}
+		writeJSON(w, trades)
+	case "/decisions":
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		records, err := s.decisions(decisionlog.Query{
+			Symbol: q.Get("symbol"),
+			Reason: q.Get("reason"),
+			Action: decisionlog.Action(q.Get("action")),
+			Since:  since,
+			Limit:  limit,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, records)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// klines returns the cached candles of symbol at interval opening at or
+// after since, each with the indicators replayed up to it.
+func (s *Server) klines(symbol, interval string, since time.Time) ([]Candle, bool) {
+	cached, _, ok := s.candles.Cached(symbol, interval)
+	if !ok {
+		return nil, false
+	}
+	candles := make([]Candle, len(cached))
+	for i, k := range cached {
+		candles[i] = Candle{OpenTime: k.OpenTime, Open: k.Open, High: k.High, Low: k.Low, Close: k.Close, Volume: k.Volume}
+	}
+	indicator.Standard.Replay(cached, func(i int, values indicator.Values) {
+		candles[i].Indicators = values
+	})
+
+	first := 0
+	for first < len(candles) && candles[first].OpenTime.Before(since) {
+		first++
+	}
+	return candles[first:], true
+}
+
+func (s *Server) trades(symbol string, since time.Time) []state.Trade {
+	var out []state.Trade
+	for _, t := range s.journal.Trades() {
+		if (symbol == "" || t.Symbol == symbol) && !t.EntryTime.Before(since) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (s *Server) decisions(q decisionlog.Query) ([]decisionlog.Record, error) {
+	if s.cfg.DecisionLog == "" {
+		return nil, nil
+	}
+	return decisionlog.Read(s.cfg.DecisionLog, q)
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func candleRows(candles []Candle) [][]string {
+	header := append([]string{"open_time", "open", "high", "low", "close", "volume"}, indicatorColumns...)
+	rows := [][]string{header}
+	for _, c := range candles {
+		row := []string{c.OpenTime.UTC().Format(time.RFC3339), num(c.Open), num(c.High), num(c.Low), num(c.Close), num(c.Volume)}
+		for _, name := range indicatorColumns {
+			v, ok := c.Indicators[name]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, num(v))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func tradeRows(trades []state.Trade) [][]string {
+	rows := [][]string{{"symbol", "side", "size", "entry_price", "fill_price", "exit_price", "pnl", "pnl_percent", "stop_loss", "take_profit", "confidence", "entry_time", "exit_time", "status", "idea"}}
+	for _, t := range trades {
+		exit := ""
+		if !t.ExitTime.IsZero() {
+			exit = t.ExitTime.UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, []string{
+			t.Symbol, t.Side, num(t.Size), num(t.EntryPrice), num(t.FillPrice), num(t.ExitPrice), num(t.PnL), num(t.PnLPercent),
+			num(t.StopLoss), num(t.TakeProfit), num(t.Confidence), t.EntryTime.UTC().Format(time.RFC3339), exit, t.Status, t.Idea,
+		})
+	}
+	return rows
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeCSV(w http.ResponseWriter, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv")
+	cw := csv.NewWriter(w)
+	cw.WriteAll(rows)
+}
+
+// checkAddr returns ErrNotLoopback unless addr's host is a loopback address
+// or localhost.
+func checkAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return ErrNotLoopback
+}
@@ -0,0 +1,87 @@
+package research
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/indicator"
+	"github.com/britej3/gobot/pkg/state"
+	"github.com/britej3/gobot/services/kline"
+)
+
+type fakeCandles []trade.Kline
+
+func (f fakeCandles) Cached(symbol, interval string) ([]trade.Kline, kline.Indicators, bool) {
+	if symbol != "BTCUSDT" || interval != "1m" {
+		return nil, kline.Indicators{}, false
+	}
+	return f, kline.Indicators{LastClose: f[len(f)-1].Close}, true
+}
+
+func (f fakeCandles) Buffered() map[string][]string {
+	return map[string][]string{"BTCUSDT": {"1m"}}
+}
+
+type fakeJournal []state.Trade
+
+func (f fakeJournal) Trades() []state.Trade { return f }
+
+func candles(n int) fakeCandles {
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	out := make(fakeCandles, n)
+	for i := range out {
+		price := 100 + float64(i%7)
+		out[i] = trade.Kline{OpenTime: start.Add(time.Duration(i) * time.Minute), Open: price, High: price + 1, Low: price - 1, Close: price + 0.5, Volume: 10}
+	}
+	return out
+}
+
+func TestServer_KlinesCarryTheLiveIndicators(t *testing.T) {
+	n := indicator.Standard.WarmUp() + 5
+	cached := candles(n)
+	s := New(Config{}, cached, fakeJournal(nil))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/klines?symbol=BTCUSDT&interval=1m&format=csv", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != n+1 || rows[0][6] != indicator.EMAFast {
+		t.Fatalf("got %d rows, header %v", len(rows), rows[0])
+	}
+	if rows[1][6] != "" {
+		t.Errorf("cold candle has ema_fast %q", rows[1][6])
+	}
+	live, _ := indicator.Standard.Compute(cached)
+	if got := rows[n][6]; got != num(live[indicator.EMAFast]) {
+		t.Errorf("last ema_fast %s, live computes %v", got, live[indicator.EMAFast])
+	}
+}
+
+func TestServer_IsReadOnlyAndLoopbackOnly(t *testing.T) {
+	s := New(Config{}, candles(3), fakeJournal{{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/trades", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trades?symbol=ETHUSDT&format=csv", nil))
+	if rows, _ := csv.NewReader(rec.Body).ReadAll(); len(rows) != 2 || rows[1][0] != "ETHUSDT" {
+		t.Errorf("trades = %v", rows)
+	}
+
+	if err := New(Config{Addr: "0.0.0.0:8091"}, nil, nil).Start(context.Background()); err != ErrNotLoopback {
+		t.Errorf("public address: err = %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package riskmap
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultBucket holds symbols not assigned to any correlation bucket.
+const DefaultBucket = "other"
+
+// Position is an open exchange position. Size is signed: negative for shorts.
+type Position struct {
+	Symbol           string
+	Size             float64
+	EntryPrice       float64
+	MarkPrice        float64
+	Leverage         float64
+	UnrealizedPnL    float64
+	LiquidationPrice float64
+}
+
+// Exposure is one heatmap row. LiquidationDistance is how far mark price can
+// move against the position before liquidation, in percent of mark; zero
+// when the exchange reports no liquidation price.
+type Exposure struct {
+	Symbol              string  `json:"symbol"`
+	Side                string  `json:"side"`
+	Size                float64 `json:"size"`
+	EntryPrice          float64 `json:"entry_price"`
+	MarkPrice           float64 `json:"mark_price"`
+	Notional            float64 `json:"notional"`
+	Leverage            float64 `json:"leverage"`
+	UnrealizedPnL       float64 `json:"unrealized_pnl"`
+	LiquidationPrice    float64 `json:"liquidation_price"`
+	LiquidationDistance float64 `json:"liquidation_distance_pct"`
+	Bucket              string  `json:"bucket"`
+}
+
+type Bucket struct {
+	Name          string  `json:"name"`
+	Positions     int     `json:"positions"`
+	Notional      float64 `json:"notional"`
+	NetNotional   float64 `json:"net_notional"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Share         float64 `json:"share_pct"`
+}
+
+// Limit is a configured risk limit with how much of it is in use.
+// Utilization is Used/Max in percent; limits with no Max are omitted.
+type Limit struct {
+	Name        string  `json:"name"`
+	Used        float64 `json:"used"`
+	Max         float64 `json:"max"`
+	Utilization float64 `json:"utilization_pct"`
+}
+
+type Heatmap struct {
+	GeneratedAt   time.Time  `json:"generated_at"`
+	Equity        float64    `json:"equity"`
+	Notional      float64    `json:"notional"`
+	Leverage      float64    `json:"effective_leverage"`
+	UnrealizedPnL float64    `json:"unrealized_pnl"`
+	Positions     []Exposure `json:"positions"`
+	Buckets       []Bucket   `json:"buckets"`
+	Limits        []Limit    `json:"limits"`
+}
+
+// Buckets maps symbols to correlation buckets, e.g. majors, L1s or memes.
+type Buckets map[string][]string
+
+// Of returns the bucket symbol belongs to, or DefaultBucket.
+func (b Buckets) Of(symbol string) string {
+	for name, symbols := range b {
+		for _, s := range symbols {
+			if s == symbol {
+				return name
+			}
+		}
+	}
+	return DefaultBucket
+}
+
+// Build summarizes open positions into a heatmap, rows and buckets sorted by
+// notional, largest first. Limits are added afterwards with AddLimit.
+func Build(positions []Position, equity float64, buckets Buckets) Heatmap {
+	h := Heatmap{
+		GeneratedAt: time.Now(),
+		Equity:      equity,
+		Positions:   make([]Exposure, 0, len(positions)),
+		Buckets:     []Bucket{},
+		Limits:      []Limit{},
+	}
+
+	byBucket := make(map[string]*Bucket)
+	for _, p := range positions {
+		if p.Size == 0 {
+			continue
+		}
+		side := "LONG"
+		if p.Size < 0 {
+			side = "SHORT"
+		}
+		notional := math.Abs(p.Size) * p.MarkPrice
+
+		e := Exposure{
+			Symbol:           p.Symbol,
+			Side:             side,
+			Size:             math.Abs(p.Size),
+			EntryPrice:       p.EntryPrice,
+			MarkPrice:        p.MarkPrice,
+			Notional:         notional,
+			Leverage:         p.Leverage,
+			UnrealizedPnL:    p.UnrealizedPnL,
+			LiquidationPrice: p.LiquidationPrice,
+			Bucket:           buckets.Of(p.Symbol),
+		}
+		if p.LiquidationPrice > 0 && p.MarkPrice > 0 {
+			e.LiquidationDistance = math.Abs(p.MarkPrice-p.LiquidationPrice) / p.MarkPrice * 100
+		}
+		h.Positions = append(h.Positions, e)
+		h.Notional += notional
+		h.UnrealizedPnL += p.UnrealizedPnL
+
+		b, ok := byBucket[e.Bucket]
+		if !ok {
+			b = &Bucket{Name: e.Bucket}
+			byBucket[e.Bucket] = b
+		}
+		b.Positions++
+		b.Notional += notional
+		b.UnrealizedPnL += p.UnrealizedPnL
+		if p.Size < 0 {
+			b.NetNotional -= notional
+		} else {
+			b.NetNotional += notional
+		}
+	}
+
+	sort.Slice(h.Positions, func(i, j int) bool { return h.Positions[i].Notional > h.Positions[j].Notional })
+
+	for _, b := range byBucket {
+		if h.Notional > 0 {
+			b.Share = b.Notional / h.Notional * 100
+		}
+		h.Buckets = append(h.Buckets, *b)
+	}
+	sort.Slice(h.Buckets, func(i, j int) bool { return h.Buckets[i].Notional > h.Buckets[j].Notional })
+
+	if equity > 0 {
+		h.Leverage = h.Notional / equity
+	}
+	return h
+}
+
+// AddLimit records the utilization of a risk limit. Limits without a
+// positive max are not configured and are skipped.
+func (h *Heatmap) AddLimit(name string, used, max float64) {
+	if max <= 0 {
+		return
+	}
+	h.Limits = append(h.Limits, Limit{Name: name, Used: used, Max: max, Utilization: used / max * 100})
+}
+
+// Largest returns the notional of the biggest position.
+func (h Heatmap) Largest() float64 {
+	if len(h.Positions) == 0 {
+		return 0
+	}
+	return h.Positions[0].Notional
+}
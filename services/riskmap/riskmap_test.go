@@ -0,0 +1,39 @@
+package riskmap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBuildGroupsExposureAndLimits(t *testing.T) {
+	buckets := Buckets{"majors": {"BTCUSDT", "ETHUSDT"}}
+	h := Build([]Position{
+		{Symbol: "BTCUSDT", Size: 0.01, EntryPrice: 60000, MarkPrice: 61000, Leverage: 10, UnrealizedPnL: 10, LiquidationPrice: 55000},
+		{Symbol: "ETHUSDT", Size: -0.5, EntryPrice: 3000, MarkPrice: 3100, Leverage: 5, UnrealizedPnL: -50},
+		{Symbol: "WIFUSDT", Size: 100, EntryPrice: 2, MarkPrice: 2.5, Leverage: 3, UnrealizedPnL: 50},
+	}, 1000, buckets)
+
+	if len(h.Positions) != 3 || h.Positions[0].Symbol != "ETHUSDT" || h.Positions[0].Side != "SHORT" {
+		t.Fatalf("rows not sorted by notional: %+v", h.Positions)
+	}
+	if d := h.Positions[1].LiquidationDistance; math.Abs(d-9.836) > 0.01 {
+		t.Errorf("BTC liquidation distance = %.3f%%, want ~9.84%%", d)
+	}
+	if h.Notional != 2410 || h.UnrealizedPnL != 10 || math.Abs(h.Leverage-2.41) > 1e-9 {
+		t.Errorf("totals notional=%v pnl=%v leverage=%v", h.Notional, h.UnrealizedPnL, h.Leverage)
+	}
+
+	majors := h.Buckets[0]
+	if majors.Name != "majors" || majors.Positions != 2 || majors.NetNotional != 610-1550 {
+		t.Errorf("unexpected majors bucket %+v", majors)
+	}
+	if h.Buckets[1].Name != DefaultBucket {
+		t.Errorf("unlisted symbol should land in %q, got %+v", DefaultBucket, h.Buckets[1])
+	}
+
+	h.AddLimit("position_size_usd", h.Largest(), 2000)
+	h.AddLimit("unset", 1, 0)
+	if len(h.Limits) != 1 || h.Limits[0].Utilization != 77.5 {
+		t.Errorf("unexpected limits %+v", h.Limits)
+	}
+}
@@ -0,0 +1,273 @@
+// Package riskmode switches the bot between named risk profiles at runtime.
+// A profile bundles the screener and sizing parameters that together decide
+// how hard the bot trades, so an operator can step down to conservative in a
+// choppy session without editing the config and restarting. Switches are
+// made from the alert chat, confirmed before they apply, and audit logged
+// with who made them.
+package riskmode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Built-in profile names, from least to most risk.
+const (
+	Conservative = "conservative"
+	Moderate     = "moderate"
+	Aggressive   = "aggressive"
+	High         = "high"
+)
+
+// Profile is one bundle of parameters. Zero fields leave the configured
+// value in place.
+type Profile struct {
+	Name            string
+	MinConfidence   float64
+	RiskPerTrade    float64
+	MaxLeverage     int
+	MaxTradesPerDay int
+	MinVolume24h    float64
+	MaxPairs        int
+}
+
+func (p Profile) String() string {
+	return fmt.Sprintf("confidence >= %.2f, risk %.2f%%/trade, leverage <= %dx, %d trades/day, volume >= %.0fM, %d pairs",
+		p.MinConfidence, p.RiskPerTrade*100, p.MaxLeverage, p.MaxTradesPerDay, p.MinVolume24h/1e6, p.MaxPairs)
+}
+
+// Defaults returns the built-in profiles, least risk first.
+func Defaults() []Profile {
+	return []Profile{
+		{Name: Conservative, MinConfidence: 0.80, RiskPerTrade: 0.005, MaxLeverage: 5, MaxTradesPerDay: 5, MinVolume24h: 100_000_000, MaxPairs: 3},
+		{Name: Moderate, MinConfidence: 0.70, RiskPerTrade: 0.01, MaxLeverage: 10, MaxTradesPerDay: 10, MinVolume24h: 50_000_000, MaxPairs: 5},
+		{Name: Aggressive, MinConfidence: 0.65, RiskPerTrade: 0.015, MaxLeverage: 20, MaxTradesPerDay: 20, MinVolume24h: 20_000_000, MaxPairs: 8},
+		{Name: High, MinConfidence: 0.60, RiskPerTrade: 0.02, MaxLeverage: 25, MaxTradesPerDay: 30, MinVolume24h: 10_000_000, MaxPairs: 10},
+	}
+}
+
+// Auditor records switches.
+type Auditor interface {
+	Log(event string, data map[string]interface{})
+}
+
+// Button is an inline keyboard button whose Data is sent back as a command.
+type Button struct {
+	Text string
+	Data string
+}
+
+// Reply is a chat answer with optional rows of buttons.
+type Reply struct {
+	Text     string
+	Keyboard [][]Button
+}
+
+type Config struct {
+	// Profiles are the selectable bundles in the order they are offered.
+	Profiles []Profile
+	// Initial is the profile applied at start.
+	Initial string
+	// ConfirmWindow is how long a requested switch waits for confirmation.
+	ConfirmWindow time.Duration
+	// OnSwitch applies each confirmed switch. The initial profile is left
+	// to the caller to apply.
+	OnSwitch func(Profile)
+}
+
+// Stats is the active profile and the last switch.
+type Stats struct {
+	Profile    string
+	Switches   int
+	LastSwitch time.Time
+	LastBy     string
+}
+
+type pending struct {
+	profile string
+	at      time.Time
+}
+
+// Switch holds the active profile.
+type Switch struct {
+	cfg     Config
+	audit   Auditor
+	now     func() time.Time
+	mu      sync.RWMutex
+	current Profile
+	pending *pending
+	stats   Stats
+}
+
+// New creates a switch starting on cfg.Initial, moderate by default.
+func New(cfg Config, audit Auditor) (*Switch, error) {
+	if len(cfg.Profiles) == 0 {
+		cfg.Profiles = Defaults()
+	}
+	if cfg.Initial == "" {
+		cfg.Initial = Moderate
+	}
+	if cfg.ConfirmWindow <= 0 {
+		cfg.ConfirmWindow = time.Minute
+	}
+
+	s := &Switch{cfg: cfg, audit: audit, now: time.Now}
+	p, ok := s.profile(cfg.Initial)
+	if !ok {
+		return nil, fmt.Errorf("unknown initial risk mode %q", cfg.Initial)
+	}
+	s.current = p
+	s.stats.Profile = p.Name
+	return s, nil
+}
+
+// Current returns the active profile.
+func (s *Switch) Current() Profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// MinConfidence returns the lowest confidence any profile accepts, the floor
+// signals must clear before the active profile is consulted.
+func (s *Switch) MinConfidence() float64 {
+	min := 0.0
+	for i, p := range s.cfg.Profiles {
+		if i == 0 || p.MinConfidence < min {
+			min = p.MinConfidence
+		}
+	}
+	return min
+}
+
+// Set switches to the named profile, applying and audit logging it.
+func (s *Switch) Set(name, by string) error {
+	p, ok := s.profile(name)
+	if !ok {
+		return fmt.Errorf("unknown risk mode %q", name)
+	}
+
+	s.mu.Lock()
+	from := s.current.Name
+	s.current = p
+	s.pending = nil
+	s.stats.Profile = p.Name
+	s.stats.Switches++
+	s.stats.LastSwitch = s.now()
+	s.stats.LastBy = by
+	at := s.stats.LastSwitch
+	s.mu.Unlock()
+
+	if s.cfg.OnSwitch != nil {
+		s.cfg.OnSwitch(p)
+	}
+	if s.audit != nil {
+		s.audit.Log("RISK_MODE_SWITCHED", map[string]interface{}{
+			"from": from,
+			"to":   p.Name,
+			"by":   by,
+			"at":   at.UTC().Format(time.RFC3339),
+		})
+	}
+	return nil
+}
+
+// Command handles "/risk", which offers the profiles as buttons, and the
+// "/risk <profile>", "/risk confirm <profile>" and "/risk cancel" commands
+// the buttons send. A switch only applies once confirmed within the
+// confirm window.
+func (s *Switch) Command(text, from string) (Reply, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || strings.ToLower(strings.SplitN(fields[0], "@", 2)[0]) != "/risk" {
+		return Reply{}, false
+	}
+	if len(fields) == 1 {
+		return s.menu(), true
+	}
+
+	arg := strings.ToLower(fields[1])
+	switch arg {
+	case "cancel":
+		s.mu.Lock()
+		s.pending = nil
+		s.mu.Unlock()
+		return Reply{Text: fmt.Sprintf("Risk mode stays %s", s.Current().Name)}, true
+	case "confirm":
+		if len(fields) < 3 {
+			return Reply{Text: "Usage: /risk confirm <profile>"}, true
+		}
+		name := strings.ToLower(fields[2])
+		s.mu.Lock()
+		p := s.pending
+		confirmed := p != nil && p.profile == name && s.now().Sub(p.at) < s.cfg.ConfirmWindow
+		s.mu.Unlock()
+		if !confirmed {
+			return Reply{Text: "No pending switch to " + name + ", send /risk again"}, true
+		}
+		if err := s.Set(name, from); err != nil {
+			return Reply{Text: err.Error()}, true
+		}
+		return Reply{Text: fmt.Sprintf("Risk mode switched to %s by %s", name, from)}, true
+	}
+
+	p, ok := s.profile(arg)
+	if !ok {
+		return Reply{Text: fmt.Sprintf("Unknown risk mode %q, one of %s", arg, strings.Join(s.names(), ", "))}, true
+	}
+	if p.Name == s.Current().Name {
+		return Reply{Text: "Risk mode is already " + p.Name}, true
+	}
+	s.mu.Lock()
+	s.pending = &pending{profile: p.Name, at: s.now()}
+	s.mu.Unlock()
+	return Reply{
+		Text: fmt.Sprintf("Switch risk mode from %s to %s?\n%s", s.Current().Name, p.Name, p),
+		Keyboard: [][]Button{{
+			{Text: "Confirm", Data: "/risk confirm " + p.Name},
+			{Text: "Cancel", Data: "/risk cancel"},
+		}},
+	}, true
+}
+
+func (s *Switch) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats
+}
+
+func (s *Switch) menu() Reply {
+	current := s.Current()
+	row := make([]Button, 0, len(s.cfg.Profiles))
+	for _, p := range s.cfg.Profiles {
+		label := p.Name
+		if p.Name == current.Name {
+			label = "• " + label
+		}
+		row = append(row, Button{Text: label, Data: "/risk " + p.Name})
+	}
+	return Reply{
+		Text:     fmt.Sprintf("Risk mode: %s\n%s", current.Name, current),
+		Keyboard: [][]Button{row},
+	}
+}
+
+func (s *Switch) profile(name string) (Profile, bool) {
+	for _, p := range s.cfg.Profiles {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+func (s *Switch) names() []string {
+	names := make([]string, 0, len(s.cfg.Profiles))
+	for _, p := range s.cfg.Profiles {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,58 @@
+package riskmode
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type auditLog struct {
+	events []map[string]interface{}
+}
+
+func (a *auditLog) Log(event string, data map[string]interface{}) {
+	a.events = append(a.events, data)
+}
+
+func TestSwitch_ConfirmsBeforeApplying(t *testing.T) {
+	var applied []string
+	audit := &auditLog{}
+	s, err := New(Config{OnSwitch: func(p Profile) { applied = append(applied, p.Name) }}, audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	menu, ok := s.Command("/risk", "@ops")
+	if !ok || len(menu.Keyboard) != 1 || len(menu.Keyboard[0]) != 4 || menu.Keyboard[0][1].Text != "• moderate" {
+		t.Fatalf("menu = %+v", menu)
+	}
+
+	ask, _ := s.Command(menu.Keyboard[0][2].Data, "@ops")
+	if len(ask.Keyboard) != 1 || s.Current().Name != Moderate {
+		t.Fatalf("choosing a profile should only ask for confirmation: %+v", ask)
+	}
+	confirm := ask.Keyboard[0][0].Data
+
+	// A confirmation arriving after the window is refused.
+	now = now.Add(2 * time.Minute)
+	if reply, _ := s.Command(confirm, "@ops"); !strings.Contains(reply.Text, "No pending") {
+		t.Fatalf("late confirmation: %q", reply.Text)
+	}
+
+	s.Command("/risk aggressive", "@ops")
+	reply, _ := s.Command(confirm, "@ops")
+	if s.Current().Name != Aggressive || !strings.Contains(reply.Text, "by @ops") {
+		t.Fatalf("confirmed switch: %q, current %s", reply.Text, s.Current().Name)
+	}
+	if len(applied) != 1 || applied[0] != Aggressive {
+		t.Fatalf("applied = %v", applied)
+	}
+	if len(audit.events) != 1 || audit.events[0]["by"] != "@ops" || audit.events[0]["from"] != Moderate {
+		t.Fatalf("audit = %+v", audit.events)
+	}
+	if st := s.Stats(); st.Switches != 1 || st.LastBy != "@ops" || !st.LastSwitch.Equal(now) {
+		t.Fatalf("stats = %+v", st)
+	}
+}
@@ -8,13 +8,115 @@ import (
 	"time"
 
 	"github.com/britej3/gobot/domain/asset"
+	"github.com/britej3/gobot/services/kline"
+	"github.com/britej3/gobot/services/levels"
 )
 
 type Config struct {
-	Interval time.Duration
-	MaxPairs int
-	SortBy   string
-	Filter   AssetFilter
+	Interval     time.Duration
+	MaxPairs     int
+	SortBy       string
+	Filter       AssetFilter
+	Blacklist    SymbolBlacklist
+	OpenInterest OpenInterestSource
+	Breakout     BreakoutConfig
+	Levels       LevelSource
+	MaxDataAge   time.Duration
+	Universes    []Universe
+	Active       []string
+	MarketCaps   MarketCapSource
+	NewListings  NewListingSource
+	Memory       SymbolMemory
+	OnRefresh    func(pairs []ExchangeInfo, active []string)
+
+	// ScoreHalfLife decays scores by the age of their ticker so a stale
+	// high score loses priority. Zero disables decay.
+	ScoreHalfLife time.Duration
+
+	// Momentum adds short-horizon returns to the 24h change, each percent
+	// of them counting MomentumWeight percent of 24h change (default 3).
+	Momentum       MomentumSource
+	MomentumWeight float64
+
+	// Sentiment scales the rank by 1 + SentimentWeight times the symbol's
+	// news sentiment (-1..1), when it has one.
+	Sentiment       SentimentSource
+	SentimentWeight float64
+
+	// Ideas ranks symbols with an active external idea ahead of the rest
+	// that pass the filters. It never lets a symbol through them.
+	Ideas IdeaSource
+
+	// Weight defers periodic scans while more than BackoffAbove of the
+	// request weight is used (default 0.7), retrying after 15s and doubling,
+	// until the last scan is MaxDeferral old (default three intervals).
+	Weight       WeightGauge
+	BackoffAbove float64
+	MaxDeferral  time.Duration
+}
+
+// WeightGauge reports the share of the request weight limit used this
+// minute.
+type WeightGauge interface {
+	Utilization() float64
+}
+
+// ScanCounts is how an exchange client has scanned the market: full scans
+// of every ticker, incremental ones from a stream, and symbols fetched one
+// by one because the stream had not reported them.
+type ScanCounts struct {
+	Full        int64
+	Incremental int64
+	Paged       int64
+}
+
+// scanCounter is implemented by exchange clients that scan incrementally.
+type scanCounter interface {
+	Scans() ScanCounts
+}
+
+// SentimentSource returns a symbol's recent news sentiment from -1 to 1.
+type SentimentSource interface {
+	Score(symbol string) (float64, bool)
+}
+
+// IdeaSource reports whether a symbol has an active trade idea.
+type IdeaSource interface {
+	Boosted(symbol string) bool
+}
+
+// MomentumSource measures a symbol's move over the last minutes, which the
+// 24h change only reflects once it has run for a while.
+type MomentumSource interface {
+	Momentum(ctx context.Context, symbol string) (kline.Momentum, error)
+}
+
+// SymbolMemory biases ranking by the bot's own realized results on a symbol;
+// Multiplier returns 1 for symbols without history.
+type SymbolMemory interface {
+	Multiplier(symbol string) float64
+}
+
+// BreakoutConfig sets when a price move counts as a breakout. With an open
+// interest source configured, the move must be backed by OI expansion;
+// rising price on falling OI is flagged as squeeze risk instead.
+type BreakoutConfig struct {
+	MinPriceChange float64
+	MinOIChange    float64
+}
+
+// LevelSource reports whether a symbol's last candle closed through a
+// support or resistance level.
+type LevelSource interface {
+	Breakout(ctx context.Context, symbol string) (levels.Breakout, bool, error)
+}
+
+type OpenInterestSource interface {
+	OpenInterestChange(ctx context.Context, symbol string) (float64, error)
+}
+
+type SymbolBlacklist interface {
+	IsBlacklisted(symbol string) bool
 }
 
 type AssetFilter struct {
@@ -35,7 +137,17 @@ type ExchangeInfo struct {
 	Status         string
 	Volume24h      float64
 	PriceChangePct float64
-	LastUpdated    time.Time
+	OIChangePct    float64
+	BreakoutSignal bool
+	// BreakoutLevel is the resistance a breakout closed through, when a
+	// level source is configured.
+	BreakoutLevel float64
+	SqueezeRisk   bool
+	Universe      string
+	LastUpdated   time.Time
+
+	// Momentum is zero unless a momentum source is configured.
+	Momentum kline.Momentum
 }
 
 type ExchangeClient interface {
@@ -47,6 +159,12 @@ type Screener struct {
 	client      ExchangeClient
 	pairs       []ExchangeInfo
 	activePairs []string
+	stale       []string
+	staleTotal  int
+	oldestAge   time.Duration
+	lastScan    time.Time
+	deferrals   int
+	deferred    int
 	mu          sync.RWMutex
 	running     bool
 	stopCh      chan struct{}
@@ -67,11 +185,25 @@ func NewScreener(client ExchangeClient, opts ...Option) *Screener {
 			MinPriceChange: 5.0,
 			Status:         "TRADING",
 		},
+		Breakout: BreakoutConfig{
+			MinPriceChange: 10.0,
+			MinOIChange:    2.0,
+		},
+		MaxDataAge: 2 * time.Minute,
 	}
 
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	if cfg.MomentumWeight <= 0 {
+		cfg.MomentumWeight = 3
+	}
+	if cfg.BackoffAbove <= 0 {
+		cfg.BackoffAbove = 0.7
+	}
+	if cfg.MaxDeferral <= 0 {
+		cfg.MaxDeferral = 3 * cfg.Interval
+	}
 
 	return &Screener{
 		cfg:    cfg,
@@ -99,6 +231,118 @@ func WithMaxPairs(max int) Option {
 	}
 }
 
+func WithBlacklist(blacklist SymbolBlacklist) Option {
+	return func(c *Config) {
+		c.Blacklist = blacklist
+	}
+}
+
+func WithOpenInterest(source OpenInterestSource) Option {
+	return func(c *Config) {
+		c.OpenInterest = source
+	}
+}
+
+func WithBreakout(breakout BreakoutConfig) Option {
+	return func(c *Config) {
+		c.Breakout = breakout
+	}
+}
+
+// WithMaxDataAge skips pairs whose ticker is older than age. Zero disables
+// the check.
+func WithMaxDataAge(age time.Duration) Option {
+	return func(c *Config) {
+		c.MaxDataAge = age
+	}
+}
+
+// WithScoreHalfLife halves a pair's score for every halfLife its ticker has
+// aged.
+func WithScoreHalfLife(halfLife time.Duration) Option {
+	return func(c *Config) {
+		c.ScoreHalfLife = halfLife
+	}
+}
+
+// WithUniverses classifies every pair into the first matching universe.
+// When active names are given, only pairs in those universes are kept.
+func WithUniverses(universes []Universe, active ...string) Option {
+	return func(c *Config) {
+		c.Universes = universes
+		c.Active = active
+	}
+}
+
+func WithMarketCaps(source MarketCapSource) Option {
+	return func(c *Config) {
+		c.MarketCaps = source
+	}
+}
+
+// WithNewListings lets NewListings universes claim the symbols source
+// reports as freshly listed.
+func WithNewListings(source NewListingSource) Option {
+	return func(c *Config) {
+		c.NewListings = source
+	}
+}
+
+// WithLevels requires a breakout to close through a resistance level as
+// well as make a breakout-sized move.
+func WithLevels(source LevelSource) Option {
+	return func(c *Config) {
+		c.Levels = source
+	}
+}
+
+func WithMemory(memory SymbolMemory) Option {
+	return func(c *Config) {
+		c.Memory = memory
+	}
+}
+
+// WithOnRefresh calls fn with the filtered pairs and the selection after
+// every refresh.
+func WithOnRefresh(fn func(pairs []ExchangeInfo, active []string)) Option {
+	return func(c *Config) {
+		c.OnRefresh = fn
+	}
+}
+
+// WithMomentum ranks pairs on their short-horizon momentum as well as the
+// 24h change. weight <= 0 keeps the default.
+func WithMomentum(source MomentumSource, weight float64) Option {
+	return func(c *Config) {
+		c.Momentum = source
+		c.MomentumWeight = weight
+	}
+}
+
+func WithSentiment(source SentimentSource, weight float64) Option {
+	return func(c *Config) {
+		c.Sentiment = source
+		c.SentimentWeight = weight
+	}
+}
+
+// WithIdeas screens symbols with an active idea first.
+func WithIdeas(source IdeaSource) Option {
+	return func(c *Config) {
+		c.Ideas = source
+	}
+}
+
+// WithWeightBackoff defers periodic scans while gauge reads above above,
+// for at most maxDeferral. Zero values keep the defaults.
+func WithWeightBackoff(gauge WeightGauge, above float64, maxDeferral time.Duration) Option {
+	return func(c *Config) {
+		c.Weight = gauge
+		c.BackoffAbove = above
+		c.MaxDeferral = maxDeferral
+	}
+}
+
 func WithSortBy(sortBy string) Option {
 	return func(c *Config) {
 		c.SortBy = sortBy
@@ -119,6 +363,7 @@ func (s *Screener) Initialize(ctx context.Context) error {
 }
 
 func (s *Screener) run(ctx context.Context) {
+	var retry <-chan time.Time
 	for {
 		select {
 		case <-ctx.Done():
@@ -126,27 +371,108 @@ func (s *Screener) run(ctx context.Context) {
 		case <-s.stopCh:
 			return
 		case <-s.ticker.C:
-			s.refresh(ctx)
+			if retry == nil {
+				retry = s.scan(ctx)
+			}
+		case <-retry:
+			retry = s.scan(ctx)
 		}
 	}
 }
 
+// scan refreshes unless the weight budget is running hot, in which case it
+// returns when to try again.
+func (s *Screener) scan(ctx context.Context) <-chan time.Time {
+	if wait, ok := s.deferral(time.Now()); ok {
+		return time.After(wait)
+	}
+	s.refresh(ctx)
+	return nil
+}
+
+// deferral returns how long to put off the scan due at now: 15s after the
+// first deferral, doubling with each one after it, but never past
+// MaxDeferral since the last scan. The first scan is never deferred.
+func (s *Screener) deferral(now time.Time) (time.Duration, bool) {
+	if s.cfg.Weight == nil || s.cfg.Weight.Utilization() < s.cfg.BackoffAbove {
+		return 0, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overdue := s.lastScan.Add(s.cfg.MaxDeferral)
+	if s.lastScan.IsZero() || !now.Before(overdue) {
+		return 0, false
+	}
+	shift := s.deferrals
+	if shift > 6 {
+		shift = 6
+	}
+	wait := 15 * time.Second << shift
+	if left := overdue.Sub(now); wait > left {
+		wait = left
+	}
+	s.deferrals++
+	s.deferred++
+	return wait, true
+}
+
 func (s *Screener) refresh(ctx context.Context) error {
 	pairs, err := s.client.GetExchangeInfo(ctx)
 	if err != nil {
 		return err
 	}
 
-	filtered := s.applyFilters(pairs)
+	fresh, stale, oldest := s.splitStale(pairs)
+	filtered := s.applyFilters(fresh)
+	s.detectBreakouts(ctx, filtered)
+	s.measureMomentum(ctx, filtered)
 
 	s.mu.Lock()
 	s.pairs = filtered
 	s.activePairs = s.selectTopPairs(filtered)
+	s.stale = stale
+	s.staleTotal += len(stale)
+	s.oldestAge = oldest
+	s.lastScan = time.Now()
+	s.deferrals = 0
+	active := s.activePairs
 	s.mu.Unlock()
 
+	if s.cfg.OnRefresh != nil {
+		s.cfg.OnRefresh(filtered, active)
+	}
 	return nil
 }
 
+// splitStale drops pairs whose exchange timestamp is older than MaxDataAge so
+// a frozen ticker can never be ranked. Pairs without a timestamp are kept.
+func (s *Screener) splitStale(pairs []ExchangeInfo) ([]ExchangeInfo, []string, time.Duration) {
+	now := time.Now()
+	fresh := make([]ExchangeInfo, 0, len(pairs))
+	var stale []string
+	var oldest time.Duration
+
+	for _, p := range pairs {
+		if p.LastUpdated.IsZero() {
+			fresh = append(fresh, p)
+			continue
+		}
+		age := now.Sub(p.LastUpdated)
+		if age > oldest {
+			oldest = age
+		}
+		if s.cfg.MaxDataAge > 0 && age > s.cfg.MaxDataAge {
+			stale = append(stale, p.Symbol)
+			continue
+		}
+		fresh = append(fresh, p)
+	}
+
+	return fresh, stale, oldest
+}
+
 func (s *Screener) applyFilters(pairs []ExchangeInfo) []ExchangeInfo {
 	filtered := make([]ExchangeInfo, 0, len(pairs))
 
@@ -154,6 +480,10 @@ func (s *Screener) applyFilters(pairs []ExchangeInfo) []ExchangeInfo {
 		if !s.matchFilter(p) {
 			continue
 		}
+		p.Universe = classify(s.cfg.Universes, p, s.cfg.MarketCaps, s.cfg.NewListings)
+		if !s.activeUniverse(p.Universe) {
+			continue
+		}
 		filtered = append(filtered, p)
 	}
 
@@ -161,7 +491,9 @@ func (s *Screener) applyFilters(pairs []ExchangeInfo) []ExchangeInfo {
 }
 
 func (s *Screener) matchFilter(p ExchangeInfo) bool {
+	s.mu.RLock()
 	f := s.cfg.Filter
+	s.mu.RUnlock()
 
 	if f.ContractType != "" && p.ContractType != f.ContractType {
 		return false
@@ -206,15 +538,105 @@ func (s *Screener) matchFilter(p ExchangeInfo) bool {
 		}
 	}
 
+	if s.cfg.Blacklist != nil && s.cfg.Blacklist.IsBlacklisted(p.Symbol) {
+		return false
+	}
+
 	return true
 }
 
+func (s *Screener) activeUniverse(name string) bool {
+	if len(s.cfg.Active) == 0 {
+		return true
+	}
+	for _, a := range s.cfg.Active {
+		if strings.EqualFold(a, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectBreakouts polls levels and open interest only for candidates that
+// already made a breakout-sized move, keeping the request count
+// proportional to signals. With levels, a move that has not closed through
+// resistance is no breakout.
+func (s *Screener) detectBreakouts(ctx context.Context, pairs []ExchangeInfo) {
+	b := s.cfg.Breakout
+	if b.MinPriceChange <= 0 {
+		return
+	}
+
+	for i := range pairs {
+		p := &pairs[i]
+		if p.PriceChangePct < b.MinPriceChange {
+			continue
+		}
+
+		if s.cfg.Levels != nil {
+			br, ok, err := s.cfg.Levels.Breakout(ctx, p.Symbol)
+			if err != nil || !ok || br.Direction < 0 {
+				continue
+			}
+			p.BreakoutLevel = br.Level.Price
+		}
+
+		if s.cfg.OpenInterest == nil {
+			p.BreakoutSignal = true
+			continue
+		}
+
+		change, err := s.cfg.OpenInterest.OpenInterestChange(ctx, p.Symbol)
+		if err != nil {
+			continue
+		}
+		p.OIChangePct = change
+
+		switch {
+		case change >= b.MinOIChange:
+			p.BreakoutSignal = true
+		case change < 0:
+			p.SqueezeRisk = true
+		}
+	}
+}
+
+// measureMomentum fills in the short-horizon momentum of pairs. A symbol
+// whose candles cannot be loaded ranks on its 24h change alone.
+func (s *Screener) measureMomentum(ctx context.Context, pairs []ExchangeInfo) {
+	if s.cfg.Momentum == nil {
+		return
+	}
+	for i := range pairs {
+		if m, err := s.cfg.Momentum.Momentum(ctx, pairs[i].Symbol); err == nil {
+			pairs[i].Momentum = m
+		}
+	}
+}
+
+// momentum is the momentum component of a pair's score: the 24h change
+// plus the recent returns, weighted up. The returns overlap, so the latest
+// minute counts three times and the last five twice, and five minutes of
+// acceleration are added on top.
+func (s *Screener) momentum(p ExchangeInfo) float64 {
+	m := p.Momentum
+	recent := m.Return1m + m.Return5m + m.Return15m + 5*m.Acceleration
+	return p.PriceChangePct + s.cfg.MomentumWeight*recent
+}
+
 func (s *Screener) selectTopPairs(pairs []ExchangeInfo) []string {
+	now := time.Now()
+	boosted := make(map[string]bool)
+	if s.cfg.Ideas != nil {
+		for _, p := range pairs {
+			boosted[p.Symbol] = s.cfg.Ideas.Boosted(p.Symbol)
+		}
+	}
 	sort.Slice(pairs, func(i, j int) bool {
-		if s.cfg.SortBy == "volume" {
-			return pairs[i].Volume24h > pairs[j].Volume24h
+		if bi, bj := boosted[pairs[i].Symbol], boosted[pairs[j].Symbol]; bi != bj {
+			return bi
 		}
-		return pairs[i].PriceChangePct > pairs[j].PriceChangePct
+		return s.rankScore(pairs[i], now) > s.rankScore(pairs[j], now)
 	})
 
 	maxPairs := s.cfg.MaxPairs
@@ -234,6 +656,19 @@ func (s *Screener) selectTopPairs(pairs []ExchangeInfo) []string {
 	return result
 }
 
+// Tune changes the minimum 24h volume and the number of pairs selected from
+// the next refresh on. Zero leaves a setting as it is.
+func (s *Screener) Tune(minVolume24h float64, maxPairs int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if minVolume24h > 0 {
+		s.cfg.Filter.MinVolume24h = minVolume24h
+	}
+	if maxPairs > 0 {
+		s.cfg.MaxPairs = maxPairs
+	}
+}
+
 func (s *Screener) GetActivePairs() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -264,21 +699,56 @@ func (s *Screener) IsMonitoring(symbol string) bool {
 	return false
 }
 
+// UniverseOf returns the universe a screened pair was assigned to, or ""
+// when the pair is not screened or belongs to no universe.
+func (s *Screener) UniverseOf(symbol string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.pairs {
+		if p.Symbol == symbol {
+			return p.Universe
+		}
+	}
+	return ""
+}
+
 func (s *Screener) GetScore(symbol string) float64 {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	for _, p := range s.pairs {
 		if p.Symbol == symbol {
-			if s.cfg.SortBy == "volume" {
-				return p.Volume24h
-			}
-			return p.PriceChangePct
+			return s.rankScore(p, time.Now())
 		}
 	}
 	return 0
 }
 
+// rankScore is the sort key, scaled by the symbol memory so previously
+// profitable symbols rank higher and repeated losers lower, by news
+// sentiment, and by the freshness of the ticker so old scores rank lower.
+func (s *Screener) rankScore(p ExchangeInfo, now time.Time) float64 {
+	score := s.momentum(p)
+	if s.cfg.SortBy == "volume" {
+		score = p.Volume24h
+	}
+
+	mult := asset.Freshness(p.LastUpdated, now, s.cfg.ScoreHalfLife)
+	if s.cfg.Memory != nil {
+		mult *= s.cfg.Memory.Multiplier(p.Symbol)
+	}
+	if s.cfg.Sentiment != nil {
+		if sentiment, ok := s.cfg.Sentiment.Score(p.Symbol); ok {
+			mult *= 1 + s.cfg.SentimentWeight*sentiment
+		}
+	}
+	if score < 0 && mult > 0 {
+		return score / mult
+	}
+	return score * mult
+}
+
 func (s *Screener) ToAssets() []asset.Asset {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -292,6 +762,7 @@ func (s *Screener) ToAssets() []asset.Asset {
 			ScoredAt:   p.LastUpdated,
 		})
 	}
+	asset.RankFresh(assets, time.Now(), s.cfg.ScoreHalfLife)
 	return assets
 }
 
@@ -306,9 +777,9 @@ func (s *Screener) calculateConfidence(p ExchangeInfo) float64 {
 		score += 0.2
 	}
 
-	if p.PriceChangePct >= 10 {
+	if change := s.momentum(p); change >= 10 {
 		score += 0.4
-	} else if p.PriceChangePct >= 5 {
+	} else if change >= 5 {
 		score += 0.3
 	} else {
 		score += 0.2
@@ -323,6 +794,19 @@ func (s *Screener) calculateConfidence(p ExchangeInfo) float64 {
 		}
 	}
 
+	if p.BreakoutSignal {
+		score += 0.1
+	}
+	if p.SqueezeRisk {
+		score -= 0.2
+	}
+	if s.cfg.Memory != nil {
+		score *= s.cfg.Memory.Multiplier(p.Symbol)
+	}
+
+	if score > 1.0 {
+		score = 1.0
+	}
 	return score
 }
 
@@ -351,23 +835,52 @@ func (s *Screener) Stats() ScreenerStats {
 		avgChange /= float64(len(s.pairs))
 	}
 
+	stale := make([]string, len(s.stale))
+	copy(stale, s.stale)
+
+	universes := make(map[string]int)
+	for _, p := range s.pairs {
+		universes[p.Universe]++
+	}
+
+	var scans ScanCounts
+	if counter, ok := s.client.(scanCounter); ok {
+		scans = counter.Scans()
+	}
+
 	return ScreenerStats{
-		TotalPairs:  len(s.pairs),
-		ActivePairs: len(s.activePairs),
-		AvgVolume:   avgVolume,
-		AvgChange:   avgChange,
-		LastUpdated: time.Now(),
+		TotalPairs:      len(s.pairs),
+		ActivePairs:     len(s.activePairs),
+		AvgVolume:       avgVolume,
+		AvgChange:       avgChange,
+		StalePairs:      stale,
+		StaleSkipsTotal: s.staleTotal,
+		OldestDataAge:   s.oldestAge,
+		Universes:       universes,
+		LastUpdated:     time.Now(),
+		DeferredScans:   s.deferred,
+		Scans:           scans,
 	}
 }
 
 type ScreenerStats struct {
-	TotalPairs  int
-	ActivePairs int
-	AvgVolume   float64
-	AvgChange   float64
-	LastUpdated time.Time
+	TotalPairs      int
+	ActivePairs     int
+	AvgVolume       float64
+	AvgChange       float64
+	StalePairs      []string
+	StaleSkipsTotal int
+	OldestDataAge   time.Duration
+	Universes       map[string]int
+	LastUpdated     time.Time
+
+	// DeferredScans counts scans put off while the weight budget ran hot.
+	DeferredScans int
+	Scans         ScanCounts
 }
 
+// DefaultMemeCoinFilter is the base filter for meme screening; combine it
+// with WithUniverses(DefaultUniverses(), "meme") to restrict the symbols.
 func DefaultMemeCoinFilter() AssetFilter {
 	return AssetFilter{
 		ContractType:   "PERPETUAL",
@@ -375,25 +888,6 @@ func DefaultMemeCoinFilter() AssetFilter {
 		MinVolume24h:   5_000_000,
 		MinPriceChange: 5.0,
 		Status:         "TRADING",
-		IncludeSymbols: []string{
-			"1000PEPEUSDT",
-			"WIFUSDT",
-			"POPCATUSDT",
-			"TURBOUSDT",
-			"MOGUSDT",
-			"FWOGUSDT",
-			"MEWUSDT",
-			"ACTUSDT",
-			"LUNAUSDT",
-			"NEIROUSDT",
-		},
-		ExcludeSymbols: []string{
-			"BTCUSDT",
-			"ETHUSDT",
-			"BNBUSDT",
-			"SOLUSDT",
-			"XRPUSDT",
-		},
 	}
 }
 
@@ -4,6 +4,9 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/britej3/gobot/services/kline"
+	"github.com/britej3/gobot/services/levels"
 )
 
 type mockExchangeClient struct {
@@ -183,6 +186,92 @@ func TestScreener_GetScore(t *testing.T) {
 	}
 }
 
+func TestScreener_StaleScoresLosePriority(t *testing.T) {
+	now := time.Now()
+	client := &mockExchangeClient{
+		info: []ExchangeInfo{
+			{Symbol: "OLDUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 15000000, PriceChangePct: 20.0, LastUpdated: now.Add(-40 * time.Second)},
+			{Symbol: "NEWUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 15000000, PriceChangePct: 12.0, LastUpdated: now},
+		},
+	}
+
+	screener := NewScreener(client, WithSortBy("volatility"), WithScoreHalfLife(20*time.Second))
+	_ = screener.refresh(context.Background())
+
+	if active := screener.GetActivePairs(); active[0] != "NEWUSDT" {
+		t.Errorf("expected the fresh pair first, got %v", active)
+	}
+	if score := screener.GetScore("OLDUSDT"); score > 5.1 {
+		t.Errorf("expected a 40s old score to decay to a quarter, got %f", score)
+	}
+}
+
+type mockIdeas map[string]bool
+
+func (m mockIdeas) Boosted(symbol string) bool { return m[symbol] }
+
+func TestScreener_IdeasRankFirst(t *testing.T) {
+	client := &mockExchangeClient{
+		info: []ExchangeInfo{
+			{Symbol: "1000PEPEUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 8000000, PriceChangePct: 15.0},
+			{Symbol: "WIFUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 6000000, PriceChangePct: 8.5},
+			{Symbol: "SOLUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 25000000, PriceChangePct: -2.0},
+			{Symbol: "THINUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 100000, PriceChangePct: 1.0},
+		},
+	}
+	s := NewScreener(client,
+		WithAssetFilter(AssetFilter{MinVolume24h: 5_000_000, MinPriceChange: 5.0}),
+		WithMaxPairs(2),
+		WithIdeas(mockIdeas{"WIFUSDT": true, "SOLUSDT": true, "THINUSDT": true}),
+	)
+	if err := s.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// WIFUSDT leads the stronger 1000PEPEUSDT; ideas never let a symbol
+	// past the filters, so SOLUSDT and THINUSDT stay out.
+	pairs := s.GetActivePairs()
+	if len(pairs) != 2 || pairs[0] != "WIFUSDT" || pairs[1] != "1000PEPEUSDT" {
+		t.Errorf("active pairs = %v", pairs)
+	}
+}
+
+type mockMomentum map[string]kline.Momentum
+
+func (m mockMomentum) Momentum(ctx context.Context, symbol string) (kline.Momentum, error) {
+	if mom, ok := m[symbol]; ok {
+		return mom, nil
+	}
+	return kline.Momentum{}, kline.ErrNoData
+}
+
+func TestScreener_RecentMomentumOutranksLaggingChange(t *testing.T) {
+	client := &mockExchangeClient{
+		info: []ExchangeInfo{
+			{Symbol: "FADINGUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 15000000, PriceChangePct: 14.0},
+			{Symbol: "WAKINGUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 15000000, PriceChangePct: 6.0},
+			{Symbol: "NOKLINESUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 15000000, PriceChangePct: 9.0},
+		},
+	}
+	momentum := mockMomentum{
+		"FADINGUSDT": {Return1m: -0.3, Return5m: -0.8, Return15m: -1.0, Acceleration: -0.14},
+		"WAKINGUSDT": {Return1m: 0.4, Return5m: 1.2, Return15m: 1.5, Acceleration: 0.21},
+	}
+
+	screener := NewScreener(client, WithSortBy("volatility"), WithMomentum(momentum, 0))
+	_ = screener.refresh(context.Background())
+
+	want := []string{"WAKINGUSDT", "NOKLINESUSDT", "FADINGUSDT"}
+	for i, symbol := range screener.GetActivePairs() {
+		if symbol != want[i] {
+			t.Fatalf("ranking = %v, want %v", screener.GetActivePairs(), want)
+		}
+	}
+	if score := screener.GetScore("NOKLINESUSDT"); score != 9.0 {
+		t.Errorf("a pair without candles should rank on its 24h change, got %f", score)
+	}
+}
+
 func TestScreener_SortByVolume(t *testing.T) {
 	client := &mockExchangeClient{
 		info: []ExchangeInfo{
@@ -297,11 +386,73 @@ func TestDefaultMemeCoinFilter(t *testing.T) {
 	if filter.MinVolume24h != 5_000_000 {
 		t.Errorf("expected min volume 5000000, got %f", filter.MinVolume24h)
 	}
-	if len(filter.IncludeSymbols) == 0 {
-		t.Error("expected include symbols to be set")
+}
+
+func TestScreener_UniversesClassifyAndRestrict(t *testing.T) {
+	client := &mockExchangeClient{
+		info: []ExchangeInfo{
+			{Symbol: "BTCUSDT", Volume24h: 900_000_000, PriceChangePct: 6},
+			{Symbol: "1000PEPEUSDT", Volume24h: 80_000_000, PriceChangePct: 15},
+			{Symbol: "WIFUSDT", Volume24h: 2_000_000, PriceChangePct: 20},
+			{Symbol: "LINKUSDT", Volume24h: 60_000_000, PriceChangePct: 7},
+			{Symbol: "TINYUSDT", Volume24h: 1_000_000, PriceChangePct: 30},
+		},
+	}
+
+	s := NewScreener(client,
+		WithAssetFilter(AssetFilter{MinPriceChange: 5}),
+		WithUniverses(DefaultUniverses(), "meme", "midcaps"),
+		WithMaxPairs(10),
+	)
+	if err := s.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, p := range s.GetPairsInfo() {
+		got[p.Symbol] = p.Universe
+	}
+	want := map[string]string{"1000PEPEUSDT": "meme", "LINKUSDT": "midcaps"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for sym, u := range want {
+		if got[sym] != u {
+			t.Errorf("expected %s in %q, got %q", sym, u, got[sym])
+		}
+	}
+	if u := s.UniverseOf("LINKUSDT"); u != "midcaps" {
+		t.Errorf("expected UniverseOf(LINKUSDT) midcaps, got %q", u)
+	}
+	if n := s.Stats().Universes["meme"]; n != 1 {
+		t.Errorf("expected 1 meme pair in stats, got %d", n)
+	}
+}
+
+type newListings map[string]bool
+
+func (n newListings) IsNewListing(symbol string) bool { return n[symbol] }
+
+func TestScreener_NewListingsUniverseClaimsFreshSymbols(t *testing.T) {
+	client := &mockExchangeClient{
+		info: []ExchangeInfo{
+			{Symbol: "FRESHUSDT", Volume24h: 30_000_000, PriceChangePct: 40},
+			{Symbol: "LINKUSDT", Volume24h: 60_000_000, PriceChangePct: 7},
+		},
+	}
+
+	s := NewScreener(client,
+		WithAssetFilter(AssetFilter{}),
+		WithUniverses(DefaultUniverses(), "new_listings"),
+		WithNewListings(newListings{"FRESHUSDT": true}),
+	)
+	if err := s.refresh(context.Background()); err != nil {
+		t.Fatal(err)
 	}
-	if len(filter.ExcludeSymbols) == 0 {
-		t.Error("expected exclude symbols to be set")
+
+	pairs := s.GetPairsInfo()
+	if len(pairs) != 1 || pairs[0].Symbol != "FRESHUSDT" || pairs[0].Universe != "new_listings" {
+		t.Errorf("expected only FRESHUSDT in new_listings, got %+v", pairs)
 	}
 }
 
@@ -364,3 +515,154 @@ func TestScreener_ConfidenceCalculation(t *testing.T) {
 		}
 	}
 }
+
+type mockOpenInterest struct {
+	changes map[string]float64
+	calls   []string
+}
+
+func (m *mockOpenInterest) OpenInterestChange(ctx context.Context, symbol string) (float64, error) {
+	m.calls = append(m.calls, symbol)
+	return m.changes[symbol], nil
+}
+
+func TestScreener_BreakoutRequiresOIExpansion(t *testing.T) {
+	client := &mockExchangeClient{
+		info: []ExchangeInfo{
+			{Symbol: "CONFIRMEDUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 10000000, PriceChangePct: 15.0},
+			{Symbol: "SQUEEZEUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 10000000, PriceChangePct: 15.0},
+			{Symbol: "QUIETUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 10000000, PriceChangePct: 6.0},
+		},
+	}
+	oi := &mockOpenInterest{changes: map[string]float64{
+		"CONFIRMEDUSDT": 5.0,
+		"SQUEEZEUSDT":   -3.0,
+	}}
+
+	screener := NewScreener(client,
+		WithMaxPairs(10),
+		WithOpenInterest(oi),
+		WithBreakout(BreakoutConfig{MinPriceChange: 10.0, MinOIChange: 2.0}),
+	)
+	_ = screener.refresh(context.Background())
+
+	if len(oi.calls) != 2 {
+		t.Errorf("expected OI polled only for breakout candidates, got %v", oi.calls)
+	}
+
+	pairs := make(map[string]ExchangeInfo)
+	for _, p := range screener.GetPairsInfo() {
+		pairs[p.Symbol] = p
+	}
+
+	if !pairs["CONFIRMEDUSDT"].BreakoutSignal {
+		t.Error("expected breakout confirmed by OI expansion")
+	}
+	if pairs["SQUEEZEUSDT"].BreakoutSignal || !pairs["SQUEEZEUSDT"].SqueezeRisk {
+		t.Error("expected rising price on falling OI to be flagged as squeeze risk")
+	}
+
+	confirmed := screener.calculateConfidence(pairs["CONFIRMEDUSDT"])
+	squeeze := screener.calculateConfidence(pairs["SQUEEZEUSDT"])
+	if confirmed <= squeeze {
+		t.Errorf("confirmed breakout should outscore squeeze: %f vs %f", confirmed, squeeze)
+	}
+}
+
+type mockLevels map[string]levels.Breakout
+
+func (m mockLevels) Breakout(ctx context.Context, symbol string) (levels.Breakout, bool, error) {
+	b, ok := m[symbol]
+	return b, ok, nil
+}
+
+func TestScreener_BreakoutMustCloseThroughResistance(t *testing.T) {
+	client := &mockExchangeClient{
+		info: []ExchangeInfo{
+			{Symbol: "THROUGHUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 10000000, PriceChangePct: 15.0},
+			{Symbol: "BELOWUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 10000000, PriceChangePct: 15.0},
+		},
+	}
+	lv := mockLevels{"THROUGHUSDT": {Direction: 1, Level: levels.Level{Price: 1.25}, Close: 1.3}}
+
+	screener := NewScreener(client, WithMaxPairs(10), WithLevels(lv), WithBreakout(BreakoutConfig{MinPriceChange: 10.0}))
+	_ = screener.refresh(context.Background())
+
+	pairs := make(map[string]ExchangeInfo)
+	for _, p := range screener.GetPairsInfo() {
+		pairs[p.Symbol] = p
+	}
+	if p := pairs["THROUGHUSDT"]; !p.BreakoutSignal || p.BreakoutLevel != 1.25 {
+		t.Errorf("expected a breakout through 1.25, got %+v", p)
+	}
+	if pairs["BELOWUSDT"].BreakoutSignal {
+		t.Error("a move still under resistance is no breakout")
+	}
+}
+
+func TestScreener_SkipsStaleTickers(t *testing.T) {
+	client := &mockExchangeClient{
+		info: []ExchangeInfo{
+			{Symbol: "PEPEUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 10000000, PriceChangePct: 10.0, LastUpdated: time.Now()},
+			{Symbol: "WIFUSDT", ContractType: "PERPETUAL", QuoteAsset: "USDT", Status: "TRADING", Volume24h: 8000000, PriceChangePct: 20.0, LastUpdated: time.Now().Add(-10 * time.Minute)},
+		},
+	}
+
+	screener := NewScreener(client, WithMaxPairs(10), WithMaxDataAge(time.Minute))
+	if err := screener.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if pairs := screener.GetActivePairs(); len(pairs) != 1 || pairs[0] != "PEPEUSDT" {
+		t.Errorf("expected only the fresh pair, got %v", pairs)
+	}
+
+	stats := screener.Stats()
+	if len(stats.StalePairs) != 1 || stats.StalePairs[0] != "WIFUSDT" || stats.OldestDataAge < 10*time.Minute {
+		t.Errorf("unexpected staleness stats: %+v", stats)
+	}
+}
+
+type fakeGauge float64
+
+func (g *fakeGauge) Utilization() float64 { return float64(*g) }
+
+func TestScreener_DefersScansWhileWeightIsHot(t *testing.T) {
+	gauge := fakeGauge(0.9)
+	s := NewScreener(&mockExchangeClient{}, WithInterval(time.Minute), WithWeightBackoff(&gauge, 0.7, 2*time.Minute))
+	now := time.Now()
+
+	if _, ok := s.deferral(now); ok {
+		t.Fatal("first scan deferred")
+	}
+	s.refresh(context.Background())
+	now = s.lastScan.Add(time.Minute)
+
+	var waits []time.Duration
+	for {
+		wait, ok := s.deferral(now)
+		if !ok {
+			break
+		}
+		waits = append(waits, wait)
+		now = now.Add(wait)
+	}
+	want := []time.Duration{15 * time.Second, 30 * time.Second, 15 * time.Second}
+	if len(waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", waits, want)
+	}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("wait %d = %v, want %v", i, waits[i], want[i])
+		}
+	}
+	if got := s.Stats().DeferredScans; got != 3 {
+		t.Errorf("DeferredScans = %d", got)
+	}
+
+	s.refresh(context.Background())
+	gauge = 0.5
+	if _, ok := s.deferral(s.lastScan.Add(time.Minute)); ok {
+		t.Error("deferred with room in the budget")
+	}
+}
@@ -0,0 +1,140 @@
+package screener
+
+import (
+	"path"
+	"strings"
+)
+
+// Universe is a named group of symbols a strategy can be restricted to. A
+// pair belongs to it when it is listed in Symbols or matches one of the
+// Patterns (path.Match globs such as "*PEPE*"), and its volume and, when a
+// MarketCapSource is configured, market cap fall within the bounds. With
+// neither Symbols nor Patterns set, the bounds alone decide membership.
+// A NewListings universe further only takes symbols the listing monitor
+// reports as recently listed.
+type Universe struct {
+	Name         string
+	Symbols      []string
+	Patterns     []string
+	Exclude      []string
+	MinVolume24h float64
+	MaxVolume24h float64
+	MinMarketCap float64
+	MaxMarketCap float64
+	NewListings  bool
+}
+
+type MarketCapSource interface {
+	MarketCap(symbol string) (float64, bool)
+}
+
+// NewListingSource reports symbols listed within the listing window.
+type NewListingSource interface {
+	IsNewListing(symbol string) bool
+}
+
+// DefaultUniverses replaces the old hard-coded major and meme lists. Order
+// matters: a pair is assigned to the first universe it matches, so majors
+// are claimed before the volume-only mid-cap tier, and fresh listings before
+// either.
+func DefaultUniverses() []Universe {
+	return []Universe{
+		{
+			Name:        "new_listings",
+			NewListings: true,
+		},
+		{
+			Name:    "majors",
+			Symbols: []string{"BTCUSDT", "ETHUSDT", "BNBUSDT", "SOLUSDT", "XRPUSDT"},
+		},
+		{
+			Name: "meme",
+			Patterns: []string{
+				"*PEPE*", "*WIF*", "*POPCAT*", "*TURBO*", "*MOG*", "*FWOG*", "*MEW*", "*ACT*",
+				"*LUNA*", "*NEIRO*", "*BOME*", "*SLERF*", "*BONK*", "*WEN*", "*MEME*",
+				"*JUP*", "*DOGE*", "*SHIB*", "*FLOKI*", "*ARBIT*", "*STRK*", "*SUNDOG*",
+				"*PIPPIN*", "*GRIFFAIN*", "*NEG*",
+			},
+			MinVolume24h: 5_000_000,
+		},
+		{
+			Name:         "midcaps",
+			MinVolume24h: 20_000_000,
+		},
+	}
+}
+
+// FindUniverse returns the universe with the given name.
+func FindUniverse(universes []Universe, name string) (Universe, bool) {
+	for _, u := range universes {
+		if strings.EqualFold(u.Name, name) {
+			return u, true
+		}
+	}
+	return Universe{}, false
+}
+
+// MatchSymbol reports whether the symbol is listed or matches a pattern,
+// ignoring the volume and market cap bounds.
+func (u Universe) MatchSymbol(symbol string) bool {
+	symbol = strings.ToUpper(symbol)
+	for _, s := range u.Exclude {
+		if strings.ToUpper(s) == symbol {
+			return false
+		}
+	}
+	if len(u.Symbols) == 0 && len(u.Patterns) == 0 {
+		return true
+	}
+	for _, s := range u.Symbols {
+		if strings.ToUpper(s) == symbol {
+			return true
+		}
+	}
+	for _, p := range u.Patterns {
+		if ok, _ := path.Match(strings.ToUpper(p), symbol); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (u Universe) Match(p ExchangeInfo, caps MarketCapSource) bool {
+	if !u.MatchSymbol(p.Symbol) {
+		return false
+	}
+	if u.MinVolume24h > 0 && p.Volume24h < u.MinVolume24h {
+		return false
+	}
+	if u.MaxVolume24h > 0 && p.Volume24h > u.MaxVolume24h {
+		return false
+	}
+	if caps == nil || (u.MinMarketCap <= 0 && u.MaxMarketCap <= 0) {
+		return true
+	}
+	cap, ok := caps.MarketCap(p.Symbol)
+	if !ok {
+		return false
+	}
+	if u.MinMarketCap > 0 && cap < u.MinMarketCap {
+		return false
+	}
+	if u.MaxMarketCap > 0 && cap > u.MaxMarketCap {
+		return false
+	}
+	return true
+}
+
+// classify returns the name of the first universe the pair belongs to, or
+// "" when it belongs to none. Without a listing source no pair is new.
+func classify(universes []Universe, p ExchangeInfo, caps MarketCapSource, listings NewListingSource) string {
+	for _, u := range universes {
+		if u.NewListings && (listings == nil || !listings.IsNewListing(p.Symbol)) {
+			continue
+		}
+		if u.Match(p, caps) {
+			return u.Name
+		}
+	}
+	return ""
+}
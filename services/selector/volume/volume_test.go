@@ -0,0 +1,17 @@
+package volume
+
+import (
+	"testing"
+
+	"github.com/britej3/gobot/domain/selector"
+	"github.com/britej3/gobot/pkg/testkit"
+)
+
+func TestVolumeSelector_PrefersOrderlyMarkets(t *testing.T) {
+	s := &VolumeSelector{}
+	s.Configure(selector.SelectorConfig{MaxAssets: 4})
+	universe := testkit.Universe(testkit.Golden()...)
+
+	testkit.AssertSelects(t, s, universe, "TRENDUSDT", "CHOPUSDT")
+	testkit.AssertRanksAbove(t, s, universe, "CHOPUSDT", "VREVUSDT")
+}
@@ -0,0 +1,310 @@
+// Package sentiment scores recent news on the screened symbols with the LLM.
+// Headlines for several symbols go out in one prompt, calls are capped per
+// hour, and each score is kept for a TTL so a symbol is rescored at most
+// that often. Scores run from -1 (bearish) to 1 (bullish).
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headline is one news item or social snippet about a symbol.
+type Headline struct {
+	Symbol    string
+	Title     string
+	Source    string
+	Published time.Time
+}
+
+// NewsSource returns headlines about symbols published since a time.
+type NewsSource interface {
+	Headlines(ctx context.Context, symbols []string, since time.Time) ([]Headline, error)
+}
+
+// Model answers a prompt with JSON decoded into response.
+type Model interface {
+	Complete(ctx context.Context, prompt string, response interface{}) error
+}
+
+type Config struct {
+	Interval        time.Duration
+	TTL             time.Duration
+	BatchSize       int
+	MaxHeadlines    int
+	MaxCallsPerHour int
+}
+
+type Stats struct {
+	Scored      int
+	Calls       int
+	Failures    int
+	OverBudget  int
+	LastRefresh time.Time
+}
+
+type score struct {
+	value float64
+	at    time.Time
+}
+
+type Service struct {
+	cfg     Config
+	news    NewsSource
+	model   Model
+	mu      sync.RWMutex
+	running bool
+	symbols []string
+	scores  map[string]score
+	calls   []time.Time
+	stats   Stats
+	stopCh  chan struct{}
+	now     func() time.Time
+}
+
+func New(cfg Config, news NewsSource, model Model) *Service {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 15 * time.Minute
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 10
+	}
+	if cfg.MaxHeadlines <= 0 {
+		cfg.MaxHeadlines = 5
+	}
+	if cfg.MaxCallsPerHour <= 0 {
+		cfg.MaxCallsPerHour = 8
+	}
+
+	return &Service{
+		cfg:    cfg,
+		news:   news,
+		model:  model,
+		scores: make(map[string]score),
+		stopCh: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// Track replaces the symbols that are scored.
+func (s *Service) Track(symbols []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.symbols = append([]string(nil), symbols...)
+}
+
+func (s *Service) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	go func() {
+		s.Refresh(ctx)
+		s.run(ctx)
+	}()
+	return nil
+}
+
+func (s *Service) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+	close(s.stopCh)
+	return nil
+}
+
+func (s *Service) run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh scores the tracked symbols whose score is missing or older than
+// the TTL, a batch per LLM call, until the hourly budget is spent. Symbols
+// without headlines score 0 without a call.
+func (s *Service) Refresh(ctx context.Context) error {
+	now := s.now()
+	due := s.due(now)
+	defer func() {
+		s.mu.Lock()
+		s.stats.LastRefresh = now
+		s.mu.Unlock()
+	}()
+	if len(due) == 0 {
+		return nil
+	}
+
+	headlines, err := s.news.Headlines(ctx, due, now.Add(-s.cfg.TTL))
+	if err != nil {
+		s.mu.Lock()
+		s.stats.Failures++
+		s.mu.Unlock()
+		return fmt.Errorf("headlines: %w", err)
+	}
+	bySymbol := make(map[string][]Headline)
+	for _, h := range headlines {
+		bySymbol[h.Symbol] = append(bySymbol[h.Symbol], h)
+	}
+
+	var quiet, batch []string
+	for _, symbol := range due {
+		if len(bySymbol[symbol]) == 0 {
+			quiet = append(quiet, symbol)
+		} else {
+			batch = append(batch, symbol)
+		}
+	}
+	s.store(now, quiet, nil)
+
+	for len(batch) > 0 {
+		n := s.cfg.BatchSize
+		if n > len(batch) {
+			n = len(batch)
+		}
+		if !s.spend(now) {
+			s.mu.Lock()
+			s.stats.OverBudget += len(batch)
+			s.mu.Unlock()
+			return nil
+		}
+
+		var answer struct {
+			Scores map[string]float64 `json:"scores"`
+		}
+		if err := s.model.Complete(ctx, s.prompt(batch[:n], bySymbol), &answer); err != nil {
+			s.mu.Lock()
+			s.stats.Failures++
+			s.mu.Unlock()
+			return fmt.Errorf("scoring: %w", err)
+		}
+		s.store(now, batch[:n], answer.Scores)
+		batch = batch[n:]
+	}
+	return nil
+}
+
+// Score returns the symbol's sentiment if it was scored within the TTL.
+func (s *Service) Score(symbol string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc, ok := s.scores[symbol]
+	if !ok || s.now().Sub(sc.at) >= s.cfg.TTL {
+		return 0, false
+	}
+	return sc.value, true
+}
+
+func (s *Service) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats
+}
+
+// due returns the symbols to rescore, never scored first and then by the
+// age of their score, so a budget that runs out does not starve the same
+// symbols every refresh.
+func (s *Service) due(now time.Time) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	due := make([]string, 0, len(s.symbols))
+	for _, symbol := range s.symbols {
+		if sc, ok := s.scores[symbol]; !ok || now.Sub(sc.at) >= s.cfg.TTL {
+			due = append(due, symbol)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		a, b := s.scores[due[i]].at, s.scores[due[j]].at
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return due[i] < due[j]
+	})
+	return due
+}
+
+// spend takes a call from the hourly budget if one is left.
+func (s *Service) spend(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.calls[:0]
+	for _, at := range s.calls {
+		if now.Sub(at) < time.Hour {
+			kept = append(kept, at)
+		}
+	}
+	s.calls = kept
+	if len(s.calls) >= s.cfg.MaxCallsPerHour {
+		return false
+	}
+	s.calls = append(s.calls, now)
+	s.stats.Calls++
+	return true
+}
+
+// store caches scores for symbols, clamped to -1..1; symbols the model left
+// out score 0.
+func (s *Service) store(now time.Time, symbols []string, scores map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, symbol := range symbols {
+		v := scores[symbol]
+		if v > 1 {
+			v = 1
+		}
+		if v < -1 {
+			v = -1
+		}
+		s.scores[symbol] = score{value: v, at: now}
+		s.stats.Scored++
+	}
+}
+
+func (s *Service) prompt(symbols []string, headlines map[string][]Headline) string {
+	var b strings.Builder
+	b.WriteString("Rate the news sentiment for each crypto futures symbol below from -1 (strongly bearish) to 1 (strongly bullish), 0 when neutral or unclear. ")
+	b.WriteString(`Answer only with JSON like {"scores": {"BTCUSDT": 0.2}} covering every symbol.`)
+	for _, symbol := range symbols {
+		items := headlines[symbol]
+		sort.Slice(items, func(i, j int) bool { return items[i].Published.After(items[j].Published) })
+		if len(items) > s.cfg.MaxHeadlines {
+			items = items[:s.cfg.MaxHeadlines]
+		}
+		fmt.Fprintf(&b, "\n\n%s:", symbol)
+		for _, h := range items {
+			fmt.Fprintf(&b, "\n- %s", h.Title)
+			if h.Source != "" {
+				fmt.Fprintf(&b, " (%s)", h.Source)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package sentiment
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeNews []Headline
+
+func (f fakeNews) Headlines(ctx context.Context, symbols []string, since time.Time) ([]Headline, error) {
+	return f, nil
+}
+
+type fakeModel struct {
+	prompts []string
+}
+
+func (m *fakeModel) Complete(ctx context.Context, prompt string, response interface{}) error {
+	m.prompts = append(m.prompts, prompt)
+	answer := response.(*struct {
+		Scores map[string]float64 `json:"scores"`
+	})
+	answer.Scores = map[string]float64{"BTCUSDT": 0.4, "ETHUSDT": -3, "SOLUSDT": 0.9}
+	return nil
+}
+
+func TestService_BatchesWithinBudget(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	news := fakeNews{
+		{Symbol: "BTCUSDT", Title: "ETF inflows hit record", Source: "coindesk", Published: now.Add(-time.Minute)},
+		{Symbol: "ETHUSDT", Title: "Exchange hack drains hot wallet", Published: now.Add(-2 * time.Minute)},
+		{Symbol: "SOLUSDT", Title: "Network upgrade ships", Published: now.Add(-3 * time.Minute)},
+	}
+	model := &fakeModel{}
+	s := New(Config{BatchSize: 2, MaxCallsPerHour: 1}, news, model)
+	s.now = func() time.Time { return now }
+	s.Track([]string{"SOLUSDT", "BTCUSDT", "ETHUSDT", "DOGEUSDT"})
+
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(model.prompts) != 1 || !strings.Contains(model.prompts[0], "ETF inflows hit record (coindesk)") {
+		t.Fatalf("prompts = %q", model.prompts)
+	}
+	if v, ok := s.Score("BTCUSDT"); !ok || v != 0.4 {
+		t.Fatalf("BTCUSDT = %v, %v", v, ok)
+	}
+	if v, _ := s.Score("ETHUSDT"); v != -1 {
+		t.Fatalf("ETHUSDT should clamp to -1, got %v", v)
+	}
+	if v, ok := s.Score("DOGEUSDT"); !ok || v != 0 {
+		t.Fatalf("a symbol without news should score 0, got %v, %v", v, ok)
+	}
+	if _, ok := s.Score("SOLUSDT"); ok {
+		t.Fatal("the second batch is over the hourly budget")
+	}
+	if st := s.Stats(); st.Calls != 1 || st.OverBudget != 1 || st.Scored != 3 {
+		t.Fatalf("stats = %+v", st)
+	}
+
+	// An hour on, the scores have expired and the budget is back.
+	now = now.Add(time.Hour)
+	if _, ok := s.Score("BTCUSDT"); ok {
+		t.Fatal("score outlived its TTL")
+	}
+	s.Refresh(context.Background())
+	if _, ok := s.Score("SOLUSDT"); !ok || len(model.prompts) != 2 {
+		t.Fatalf("expected SOLUSDT scored on the next refresh, prompts %d", len(model.prompts))
+	}
+}
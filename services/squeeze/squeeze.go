@@ -0,0 +1,374 @@
+// Package squeeze detects volatility squeezes: Bollinger Bands contracting
+// inside the Keltner Channel. A squeeze that releases with momentum behind
+// it is an expansion starting, usually before the 24h price change shows
+// it, so released squeezes are emitted as signals of their own.
+package squeeze
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+type KlineSource interface {
+	Klines(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error)
+}
+
+// Config sets the bands and what counts as a release. Bollinger Bands are
+// BBMult standard deviations and the Keltner Channel KCMult average true
+// ranges around the Length-candle mean; a squeeze is on while the former
+// fits inside the latter. A release fires once the squeeze has held for
+// MinBars candles.
+type Config struct {
+	Interval string
+	Length   int
+	BBMult   float64
+	KCMult   float64
+	MinBars  int
+	// Weight is the most a signal adds to a decision's confidence.
+	Weight float64
+	// TTL is how long a signal stays usable after the release.
+	TTL           time.Duration
+	CheckInterval time.Duration
+}
+
+// Reading is the squeeze state at the last candle. Momentum is the linear
+// regression of close against the channel midline, in percent of price;
+// its sign is the breakout direction. Bars counts the squeeze candles
+// leading up to the last one.
+type Reading struct {
+	On       bool
+	Bars     int
+	Momentum float64
+	Rising   bool
+}
+
+// Signal is a released squeeze. Contribution is Score scaled by the
+// configured weight, ready to add to a confidence.
+type Signal struct {
+	Symbol       string
+	Direction    string
+	Score        float64
+	Contribution float64
+	Momentum     float64
+	Bars         int
+	Price        float64
+	At           time.Time
+}
+
+// Asset returns the signal in the form the striker takes its targets in.
+func (s Signal) Asset() map[string]interface{} {
+	return map[string]interface{}{
+		"Symbol":       s.Symbol,
+		"CurrentPrice": s.Price,
+		"Confidence":   s.Score,
+		"ScoredAt":     s.At,
+		"Source":       "squeeze",
+	}
+}
+
+type Stats struct {
+	Checks  int
+	Signals int
+	Errors  int
+}
+
+type Detector struct {
+	cfg      Config
+	source   KlineSource
+	mu       sync.RWMutex
+	running  bool
+	watched  map[string]struct{}
+	signals  map[string]Signal
+	stats    Stats
+	handlers []func(Signal)
+	stopCh   chan struct{}
+}
+
+func New(cfg Config, source KlineSource) *Detector {
+	if cfg.Interval == "" {
+		cfg.Interval = "5m"
+	}
+	if cfg.Length <= 1 {
+		cfg.Length = 20
+	}
+	if cfg.BBMult <= 0 {
+		cfg.BBMult = 2
+	}
+	if cfg.KCMult <= 0 {
+		cfg.KCMult = 1.5
+	}
+	if cfg.MinBars <= 0 {
+		cfg.MinBars = 6
+	}
+	if cfg.Weight <= 0 {
+		cfg.Weight = 0.15
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 15 * time.Minute
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = time.Minute
+	}
+
+	return &Detector{
+		cfg:     cfg,
+		source:  source,
+		watched: make(map[string]struct{}),
+		signals: make(map[string]Signal),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func (d *Detector) OnSignal(fn func(Signal)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, fn)
+}
+
+func (d *Detector) Watch(symbols ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, s := range symbols {
+		d.watched[s] = struct{}{}
+	}
+}
+
+func (d *Detector) Start(ctx context.Context) error {
+	d.mu.Lock()
+	if d.running {
+		d.mu.Unlock()
+		return nil
+	}
+	d.running = true
+	d.mu.Unlock()
+
+	go d.run(ctx)
+	return nil
+}
+
+func (d *Detector) Stop() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.running {
+		return nil
+	}
+	d.running = false
+	close(d.stopCh)
+	return nil
+}
+
+func (d *Detector) run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.Check(ctx)
+		}
+	}
+}
+
+// Check scans every watched symbol for released squeezes.
+func (d *Detector) Check(ctx context.Context) {
+	d.mu.RLock()
+	symbols := make([]string, 0, len(d.watched))
+	for s := range d.watched {
+		symbols = append(symbols, s)
+	}
+	d.mu.RUnlock()
+
+	for _, symbol := range symbols {
+		klines, err := d.source.Klines(ctx, symbol, d.cfg.Interval, 3*d.cfg.Length+d.cfg.MinBars)
+		d.mu.Lock()
+		d.stats.Checks++
+		if err != nil {
+			d.stats.Errors++
+		}
+		d.mu.Unlock()
+		if err != nil {
+			continue
+		}
+		d.Observe(symbol, klines)
+	}
+}
+
+// Observe evaluates one symbol's closed candles and reports the signal
+// when the last one released a squeeze. Longer squeezes score higher; a
+// release whose momentum is already fading scores half.
+func (d *Detector) Observe(symbol string, klines []trade.Kline) (Signal, bool) {
+	r, ok := Measure(klines, d.cfg)
+	if !ok || r.On || r.Bars < d.cfg.MinBars || r.Momentum == 0 {
+		return Signal{}, false
+	}
+
+	score := math.Min(1, float64(r.Bars)/float64(2*d.cfg.MinBars))
+	if !r.Rising {
+		score /= 2
+	}
+	direction := "LONG"
+	if r.Momentum < 0 {
+		direction = "SHORT"
+	}
+	signal := Signal{
+		Symbol:       symbol,
+		Direction:    direction,
+		Score:        score,
+		Contribution: score * d.cfg.Weight,
+		Momentum:     r.Momentum,
+		Bars:         r.Bars,
+		Price:        klines[len(klines)-1].Close,
+		At:           time.Now(),
+	}
+
+	d.mu.Lock()
+	if prev, ok := d.signals[symbol]; ok && time.Since(prev.At) < d.cfg.TTL && prev.Direction == direction {
+		// Same release seen again on the next check.
+		d.mu.Unlock()
+		return prev, false
+	}
+	d.signals[symbol] = signal
+	d.stats.Signals++
+	handlers := append([]func(Signal){}, d.handlers...)
+	d.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(signal)
+	}
+	return signal, true
+}
+
+// Last returns symbol's signal if it is younger than the TTL.
+func (d *Detector) Last(symbol string) (Signal, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	s, ok := d.signals[symbol]
+	if !ok || time.Since(s.At) >= d.cfg.TTL {
+		return Signal{}, false
+	}
+	return s, true
+}
+
+// Assets returns the live signals as striker targets, best first.
+func (d *Detector) Assets() []interface{} {
+	d.mu.RLock()
+	live := make([]Signal, 0, len(d.signals))
+	for _, s := range d.signals {
+		if time.Since(s.At) < d.cfg.TTL {
+			live = append(live, s)
+		}
+	}
+	d.mu.RUnlock()
+
+	sort.Slice(live, func(i, j int) bool { return live[i].Score > live[j].Score })
+	assets := make([]interface{}, len(live))
+	for i, s := range live {
+		assets[i] = s.Asset()
+	}
+	return assets
+}
+
+func (d *Detector) Stats() Stats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.stats
+}
+
+// Measure computes the squeeze state at the last of klines. It needs
+// 2*Length candles for the momentum regression and more to count Bars.
+func Measure(klines []trade.Kline, cfg Config) (Reading, bool) {
+	n := cfg.Length
+	if n <= 1 || len(klines) < 2*n {
+		return Reading{}, false
+	}
+	last := len(klines) - 1
+
+	r := Reading{On: squeezed(klines, last, cfg)}
+	for i := last - 1; i >= n && squeezed(klines, i, cfg); i-- {
+		r.Bars++
+	}
+
+	mom := momentum(klines, last, n)
+	prev := momentum(klines, last-1, n)
+	price := klines[last].Close
+	if price <= 0 {
+		return Reading{}, false
+	}
+	r.Momentum = mom / price * 100
+	r.Rising = math.Abs(mom) > math.Abs(prev)
+	return r, true
+}
+
+// squeezed reports whether the Bollinger Bands fit inside the Keltner
+// Channel at candle i.
+func squeezed(klines []trade.Kline, i int, cfg Config) bool {
+	n := cfg.Length
+	window := klines[i-n+1 : i+1]
+
+	mean := 0.0
+	for _, k := range window {
+		mean += k.Close
+	}
+	mean /= float64(n)
+	variance := 0.0
+	for _, k := range window {
+		variance += (k.Close - mean) * (k.Close - mean)
+	}
+	std := math.Sqrt(variance / float64(n))
+
+	atr := 0.0
+	for j := i - n + 1; j <= i; j++ {
+		k := klines[j]
+		tr := k.High - k.Low
+		if j > 0 {
+			prevClose := klines[j-1].Close
+			tr = math.Max(tr, math.Max(math.Abs(k.High-prevClose), math.Abs(k.Low-prevClose)))
+		}
+		atr += tr
+	}
+	atr /= float64(n)
+
+	return atr > 0 && cfg.BBMult*std < cfg.KCMult*atr
+}
+
+// momentum is the end value of a least-squares line through close minus
+// the channel midline (the mean of the Donchian middle and the SMA) over
+// the n candles ending at i.
+func momentum(klines []trade.Kline, i, n int) float64 {
+	delta := make([]float64, n)
+	for j := 0; j < n; j++ {
+		at := i - n + 1 + j
+		window := klines[at-n+1 : at+1]
+		high, low, sum := window[0].High, window[0].Low, 0.0
+		for _, k := range window {
+			high = math.Max(high, k.High)
+			low = math.Min(low, k.Low)
+			sum += k.Close
+		}
+		mid := ((high+low)/2 + sum/float64(n)) / 2
+		delta[j] = klines[at].Close - mid
+	}
+
+	var sx, sy, sxy, sxx float64
+	for x, y := range delta {
+		fx := float64(x)
+		sx += fx
+		sy += y
+		sxy += fx * y
+		sxx += fx * fx
+	}
+	fn := float64(n)
+	slope := (fn*sxy - sx*sy) / (fn*sxx - sx*sx)
+	intercept := (sy - slope*sx) / fn
+	return intercept + slope*(fn-1)
+}
@@ -0,0 +1,59 @@
+package squeeze
+
+import (
+	"testing"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+// coiled returns candles with wide ranges but flat closes, squeezed
+// throughout, followed by one candle closing at breakout.
+func coiled(n int, breakout float64) []trade.Kline {
+	out := make([]trade.Kline, 0, n+1)
+	for i := 0; i < n; i++ {
+		c := 100 + 0.05*float64(i%2)
+		out = append(out, trade.Kline{Open: c, High: c + 1, Low: c - 1, Close: c})
+	}
+	high, low := breakout, 100.0
+	if breakout < 100 {
+		high, low = 100, breakout
+	}
+	return append(out, trade.Kline{Open: 100, High: high, Low: low, Close: breakout})
+}
+
+func TestDetector_EmitsReleasedSqueezes(t *testing.T) {
+	d := New(Config{}, nil)
+
+	if r, ok := Measure(coiled(65, 100)[:65], d.cfg); !ok || !r.On {
+		t.Fatalf("flat closes inside wide candles should be squeezed, got %+v", r)
+	}
+	if _, ok := d.Observe("BTCUSDT", coiled(65, 100)[:65]); ok {
+		t.Fatal("a squeeze that has not released is not a signal")
+	}
+
+	var notified []Signal
+	d.OnSignal(func(s Signal) { notified = append(notified, s) })
+
+	up, ok := d.Observe("BTCUSDT", coiled(65, 110))
+	if !ok || up.Direction != "LONG" || up.Score != 1 || up.Contribution != 0.15 {
+		t.Fatalf("upside release = %+v, %v", up, ok)
+	}
+	if _, ok := d.Observe("BTCUSDT", coiled(65, 110)); ok {
+		t.Fatal("the same release was emitted twice")
+	}
+	down, ok := d.Observe("ETHUSDT", coiled(65, 90))
+	if !ok || down.Direction != "SHORT" {
+		t.Fatalf("downside release = %+v, %v", down, ok)
+	}
+
+	if len(notified) != 2 {
+		t.Fatalf("notified %d signals, want 2", len(notified))
+	}
+	if last, ok := d.Last("BTCUSDT"); !ok || last.Direction != "LONG" {
+		t.Fatalf("Last = %+v, %v", last, ok)
+	}
+	assets := d.Assets()
+	if len(assets) != 2 || assets[0].(map[string]interface{})["Source"] != "squeeze" {
+		t.Fatalf("assets = %v", assets)
+	}
+}
@@ -0,0 +1,110 @@
+// Package external loads strategies compiled as Go plugins from a
+// directory, so they can be added without changing the core.
+//
+// A module is built with `go build -buildmode=plugin` against the same
+// gobot version as the bot and exports two symbols:
+//
+//	var GobotABI = external.ABIVersion
+//	func NewStrategy() strategy.Strategy
+//
+// The strategy's Type() is the name strategy configs select it by.
+package external
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"sort"
+
+	"github.com/britej3/gobot/domain/strategy"
+)
+
+// ABIVersion is the version of the module contract. It changes whenever the
+// exported symbols or the Strategy interface change incompatibly.
+const ABIVersion = 1
+
+// Exported symbol names
+const (
+	SymbolABI = "GobotABI"
+	SymbolNew = "NewStrategy"
+)
+
+var (
+	ErrABIMismatch = errors.New("strategy module built for another ABI version")
+	ErrBadSymbol   = errors.New("strategy module symbol has the wrong type")
+)
+
+// Module is one loaded strategy module.
+type Module struct {
+	Path    string
+	Type    strategy.StrategyType
+	Name    string
+	Version string
+	Factory strategy.StrategyFactory
+}
+
+// Load opens every *.so file in dir, in name order. A module that fails to
+// load is reported in errs and skipped, so one broken file does not keep
+// the others out. A missing directory loads nothing.
+func Load(dir string) (modules []Module, errs []error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil, []error{err}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		m, err := open(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		modules = append(modules, m)
+	}
+	return modules, errs
+}
+
+func open(path string) (Module, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return Module{}, err
+	}
+	return fromSymbols(path, p.Lookup)
+}
+
+// fromSymbols checks a module's exports and builds its factory.
+func fromSymbols(path string, lookup func(string) (plugin.Symbol, error)) (Module, error) {
+	sym, err := lookup(SymbolABI)
+	if err != nil {
+		return Module{}, err
+	}
+	abi, ok := sym.(*int)
+	if !ok {
+		return Module{}, fmt.Errorf("%w: %s is %T, want *int", ErrBadSymbol, SymbolABI, sym)
+	}
+	if *abi != ABIVersion {
+		return Module{}, fmt.Errorf("%w: module has %d, bot has %d", ErrABIMismatch, *abi, ABIVersion)
+	}
+
+	sym, err = lookup(SymbolNew)
+	if err != nil {
+		return Module{}, err
+	}
+	newStrategy, ok := sym.(func() strategy.Strategy)
+	if !ok {
+		return Module{}, fmt.Errorf("%w: %s is %T, want func() strategy.Strategy", ErrBadSymbol, SymbolNew, sym)
+	}
+
+	probe := newStrategy()
+	if probe == nil || probe.Type() == "" {
+		return Module{}, fmt.Errorf("%w: %s returned no strategy type", ErrBadSymbol, SymbolNew)
+	}
+	return Module{
+		Path:    path,
+		Type:    probe.Type(),
+		Name:    probe.Name(),
+		Version: probe.Version(),
+		Factory: newStrategy,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"plugin"
+	"testing"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/services/strategy/momentum"
+)
+
+func exports(abi int, newStrategy interface{}) func(string) (plugin.Symbol, error) {
+	return func(name string) (plugin.Symbol, error) {
+		switch name {
+		case SymbolABI:
+			return &abi, nil
+		case SymbolNew:
+			return newStrategy, nil
+		}
+		return nil, fmt.Errorf("symbol %s not found", name)
+	}
+}
+
+func TestFromSymbols(t *testing.T) {
+	newMomentum := func() strategy.Strategy { return &momentum.MomentumStrategy{} }
+
+	m, err := fromSymbols("momentum.so", exports(ABIVersion, newMomentum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != strategy.StrategyMomentum || m.Factory() == nil {
+		t.Errorf("unexpected module %+v", m)
+	}
+
+	if _, err := fromSymbols("old.so", exports(ABIVersion+1, newMomentum)); !errors.Is(err, ErrABIMismatch) {
+		t.Errorf("expected an ABI mismatch, got %v", err)
+	}
+	if _, err := fromSymbols("bad.so", exports(ABIVersion, func() interface{} { return nil })); !errors.Is(err, ErrBadSymbol) {
+		t.Errorf("expected a bad symbol error, got %v", err)
+	}
+}
+
+func TestLoad_MissingDirectory(t *testing.T) {
+	modules, errs := Load(t.TempDir() + "/missing")
+	if len(modules) != 0 || len(errs) != 0 {
+		t.Errorf("expected nothing loaded, got %v %v", modules, errs)
+	}
+}
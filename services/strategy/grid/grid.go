@@ -0,0 +1,206 @@
+// Package grid buys a ladder of price levels on ranging symbols, taking
+// profit one level up, instead of waiting for a directional setup. A
+// strategy config of type "grid" carries
+//
+//	"grid": {"levels": 10, "level_size": 25, "max_trend_percent": 0.4}
+//
+// and is usually limited to a universe of range-bound pairs. Entries go
+// through the platform like any other strategy's, so the allocator, the
+// position locks and the intent journal apply to every level bought.
+package grid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+)
+
+type GridStrategy struct {
+	cfg strategy.StrategyConfig
+
+	mu sync.Mutex
+	// last is the price each symbol was seen at, so a level is bought when
+	// price crosses down through it rather than on every tick below it.
+	last map[string]float64
+	// lines is the level each symbol's latest entry bought.
+	lines map[string]float64
+}
+
+func (s *GridStrategy) Type() strategy.StrategyType {
+	return strategy.StrategyGrid
+}
+
+func (s *GridStrategy) Name() string {
+	if s.cfg.Name != "" {
+		return s.cfg.Name
+	}
+	return "grid_strategy"
+}
+
+func (s *GridStrategy) Version() string {
+	if s.cfg.Version != "" {
+		return s.cfg.Version
+	}
+	return "1.0.0"
+}
+
+func (s *GridStrategy) Configure(config strategy.StrategyConfig) error {
+	g := config.Grid
+	if g.Levels < 2 {
+		return fmt.Errorf("%w: grid strategy %q needs at least 2 levels", strategy.ErrInvalidConfig, config.Name)
+	}
+	if g.LevelSize <= 0 {
+		return fmt.Errorf("%w: grid strategy %q has no level size", strategy.ErrInvalidConfig, config.Name)
+	}
+	if (g.Lower != 0 || g.Upper != 0) && (g.Lower <= 0 || g.Upper <= g.Lower) {
+		return fmt.Errorf("%w: grid strategy %q range %g-%g is empty", strategy.ErrInvalidConfig, config.Name, g.Lower, g.Upper)
+	}
+	if g.MaxTrendPercent <= 0 {
+		config.Grid.MaxTrendPercent = 0.5
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = config
+	s.last = make(map[string]float64)
+	s.lines = make(map[string]float64)
+	return nil
+}
+
+func (s *GridStrategy) Validate() error {
+	if s.cfg.Grid.Levels < 2 || s.cfg.Grid.LevelSize <= 0 {
+		return strategy.ErrInvalidConfig
+	}
+	return nil
+}
+
+// bounds returns the grid's range for market and the spacing of its lines.
+func (s *GridStrategy) bounds(market trade.MarketData) (lower, upper, step float64, ok bool) {
+	lower, upper = s.cfg.Grid.Lower, s.cfg.Grid.Upper
+	if lower == 0 && upper == 0 {
+		lower, upper = market.Low24h, market.High24h
+	}
+	if lower <= 0 || upper <= lower {
+		return 0, 0, 0, false
+	}
+	return lower, upper, (upper - lower) / float64(s.cfg.Grid.Levels), true
+}
+
+// ranging reports whether market is flat enough to grid: the fast and slow
+// EMAs within MaxTrendPercent of each other.
+func (s *GridStrategy) ranging(market trade.MarketData) bool {
+	if market.CurrentPrice <= 0 || market.EMAFast <= 0 || market.EMASlow <= 0 {
+		return false
+	}
+	spread := math.Abs(market.EMAFast-market.EMASlow) / market.CurrentPrice * 100
+	return spread <= s.cfg.Grid.MaxTrendPercent
+}
+
+func (s *GridStrategy) ShouldEnter(ctx context.Context, market trade.MarketData) (bool, string, error) {
+	if s.last == nil {
+		return false, "", strategy.ErrInvalidConfig
+	}
+	price := market.CurrentPrice
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.last[market.Symbol]
+	s.last[market.Symbol] = price
+
+	if !s.ranging(market) {
+		return false, "Not ranging", nil
+	}
+	lower, upper, step, ok := s.bounds(market)
+	if !ok || price < lower || price >= upper {
+		return false, "Outside grid range", nil
+	}
+	if prev <= price {
+		return false, "No grid level crossed", nil
+	}
+
+	// Of the lines crossed since prev, the lowest is bought.
+	level := math.Ceil((price - lower) / step)
+	line := lower + level*step
+	if line >= prev || level >= float64(s.cfg.Grid.Levels) {
+		return false, "No grid level crossed", nil
+	}
+	s.lines[market.Symbol] = line
+	return true, fmt.Sprintf("Crossed grid level %d/%d at %.6f", int(level)+1, s.cfg.Grid.Levels, line), nil
+}
+
+// ShouldExit closes positions once price breaks below the range or the
+// market starts trending, leaving the take profit one level up to close
+// them otherwise.
+func (s *GridStrategy) ShouldExit(ctx context.Context, position *trade.Position, market trade.MarketData) (bool, string, error) {
+	lower, _, _, ok := s.bounds(market)
+	if ok && market.CurrentPrice < lower {
+		return true, "Price broke below grid range", nil
+	}
+	if !s.ranging(market) {
+		return true, "Market no longer ranging", nil
+	}
+	return false, "", nil
+}
+
+func (s *GridStrategy) CalculatePositionSize(ctx context.Context, market trade.MarketData, balance float64) (float64, error) {
+	if market.CurrentPrice <= 0 {
+		return 0, nil
+	}
+	return s.cfg.Grid.LevelSize / market.CurrentPrice, nil
+}
+
+// CalculateStopLoss places the stop one level below the range, shared by
+// every level bought.
+func (s *GridStrategy) CalculateStopLoss(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	lower, _, step, ok := s.bounds(market)
+	if !ok {
+		return entryPrice * (1 - s.cfg.RiskParameters.StopLossPercent), nil
+	}
+	return lower - step, nil
+}
+
+func (s *GridStrategy) CalculateTakeProfit(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	_, _, step, ok := s.bounds(market)
+	if !ok {
+		return entryPrice * (1 + s.cfg.RiskParameters.TakeProfitPercent), nil
+	}
+	s.mu.Lock()
+	line, bought := s.lines[market.Symbol]
+	s.mu.Unlock()
+	if !bought {
+		line = entryPrice
+	}
+	return line + step, nil
+}
+
+// CalculateTrailingStop keeps the range stop: a grid expects price to come
+// back down through its levels, so trailing would cut it short.
+func (s *GridStrategy) CalculateTrailingStop(ctx context.Context, position *trade.Position, market trade.MarketData) (float64, error) {
+	return s.CalculateStopLoss(ctx, position.EntryPrice, market)
+}
+
+func (s *GridStrategy) OnTick(ctx context.Context, position *trade.Position, market trade.MarketData) error {
+	return nil
+}
+
+func (s *GridStrategy) OnOrderFill(ctx context.Context, order *trade.Order, position *trade.Position) error {
+	return nil
+}
+
+func (s *GridStrategy) OnPositionClose(ctx context.Context, position *trade.Position, reason string) error {
+	return nil
+}
+
+func (s *GridStrategy) GetParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"lower":             s.cfg.Grid.Lower,
+		"upper":             s.cfg.Grid.Upper,
+		"levels":            s.cfg.Grid.Levels,
+		"level_size":        s.cfg.Grid.LevelSize,
+		"max_trend_percent": s.cfg.Grid.MaxTrendPercent,
+	}
+}
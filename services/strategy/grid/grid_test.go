@@ -0,0 +1,85 @@
+package grid
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+)
+
+func flat(price float64) trade.MarketData {
+	return trade.MarketData{Symbol: "SOLUSDT", CurrentPrice: price, EMAFast: 100, EMASlow: 100.1}
+}
+
+func TestGridStrategy_BuysLevelsCrossedDownward(t *testing.T) {
+	ctx := context.Background()
+	s := &GridStrategy{}
+	err := s.Configure(strategy.StrategyConfig{
+		Type: strategy.StrategyGrid,
+		Grid: strategy.GridConfig{Lower: 90, Upper: 110, Levels: 4, LevelSize: 50},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines sit at 90, 95, 100 and 105.
+	for _, step := range []struct {
+		price float64
+		enter bool
+	}{
+		{103, false}, // first sighting only arms the grid
+		{101, false},
+		{99.5, true}, // crossed 100
+		{99, false},
+		{104, false}, // crossing upwards buys nothing
+		{94, true},   // crossed 100 and 95, buys 95
+		{120, false}, // above the range
+	} {
+		enter, reason, err := s.ShouldEnter(ctx, flat(step.price))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enter != step.enter {
+			t.Fatalf("price %v: enter = %v (%s), want %v", step.price, enter, reason, step.enter)
+		}
+		if !enter {
+			continue
+		}
+
+		tp, _ := s.CalculateTakeProfit(ctx, step.price, flat(step.price))
+		sl, _ := s.CalculateStopLoss(ctx, step.price, flat(step.price))
+		size, _ := s.CalculatePositionSize(ctx, flat(step.price), 0)
+		want := map[float64]float64{99.5: 105, 94: 100}[step.price]
+		if math.Abs(tp-want) > 1e-9 || sl != 85 || math.Abs(size*step.price-50) > 1e-9 {
+			t.Errorf("price %v: tp=%v sl=%v size=%v", step.price, tp, sl, size)
+		}
+	}
+
+	trending := flat(99)
+	trending.EMAFast = 103
+	if enter, _, _ := s.ShouldEnter(ctx, trending); enter {
+		t.Error("grid entered a trending market")
+	}
+	if exit, _, _ := s.ShouldExit(ctx, &trade.Position{}, trending); !exit {
+		t.Error("grid kept positions open in a trending market")
+	}
+	if exit, _, _ := s.ShouldExit(ctx, &trade.Position{}, flat(89)); !exit {
+		t.Error("grid kept positions open below its range")
+	}
+}
+
+func TestGridStrategy_RejectsIncompleteConfig(t *testing.T) {
+	for _, g := range []strategy.GridConfig{
+		{Levels: 1, LevelSize: 10},
+		{Levels: 5},
+		{Lower: 110, Upper: 90, Levels: 5, LevelSize: 10},
+	} {
+		err := (&GridStrategy{}).Configure(strategy.StrategyConfig{Grid: g})
+		if !errors.Is(err, strategy.ErrInvalidConfig) {
+			t.Errorf("%+v: err = %v, want ErrInvalidConfig", g, err)
+		}
+	}
+}
@@ -80,11 +80,17 @@ func (s *MomentumStrategy) CalculatePositionSize(ctx context.Context, market tra
 }
 
 func (s *MomentumStrategy) CalculateStopLoss(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	if stopLoss, _, ok := s.cfg.RiskParameters.ATRLevels(trade.SideBuy, entryPrice, market); ok {
+		return stopLoss, nil
+	}
 	stopLossPercent := s.cfg.RiskParameters.StopLossPercent * 1.5
 	return entryPrice * (1 - stopLossPercent), nil
 }
 
 func (s *MomentumStrategy) CalculateTakeProfit(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	if _, takeProfit, ok := s.cfg.RiskParameters.ATRLevels(trade.SideBuy, entryPrice, market); ok {
+		return takeProfit, nil
+	}
 	takeProfitPercent := s.cfg.RiskParameters.TakeProfitPercent * 2
 	return entryPrice * (1 + takeProfitPercent), nil
 }
@@ -92,9 +98,9 @@ func (s *MomentumStrategy) CalculateTakeProfit(ctx context.Context, entryPrice f
 func (s *MomentumStrategy) CalculateTrailingStop(ctx context.Context, position *trade.Position, market trade.MarketData) (float64, error) {
 	trailingPercent := 0.5
 	if position.Side == trade.SideBuy {
-		return market.CurrentPrice * (1 - trailingPercent), nil
+		return market.TightenToVWAP(position.Side, market.CurrentPrice*(1-trailingPercent)), nil
 	}
-	return market.CurrentPrice * (1 + trailingPercent), nil
+	return market.TightenToVWAP(position.Side, market.CurrentPrice*(1+trailingPercent)), nil
 }
 
 func (s *MomentumStrategy) OnTick(ctx context.Context, position *trade.Position, market trade.MarketData) error {
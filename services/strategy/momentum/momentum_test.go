@@ -0,0 +1,41 @@
+package momentum
+
+import (
+	"testing"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/pkg/testkit"
+)
+
+func configured() *MomentumStrategy {
+	s := &MomentumStrategy{}
+	s.Configure(strategy.StrategyConfig{
+		Type:           strategy.StrategyMomentum,
+		RiskParameters: strategy.RiskConfig{RiskPerTrade: 0.01, StopLossPercent: 0.01, TakeProfitPercent: 0.015},
+	})
+	return s
+}
+
+func TestMomentumStrategy_Golden(t *testing.T) {
+	trend := testkit.Run(t, configured(), testkit.TrendDay("TRENDUSDT"))
+	testkit.AssertEntersDuring(t, trend, "trend")
+	if trend.PnLPercent() <= 0 {
+		t.Errorf("lost %.2f%% riding a trend day", -trend.PnLPercent())
+	}
+
+	v := testkit.Run(t, configured(), testkit.VReversal("VREVUSDT"))
+	testkit.AssertNoEntryDuring(t, v, "selloff")
+	testkit.AssertEntersDuring(t, v, "recovery")
+
+	chop := testkit.Run(t, configured(), testkit.Chop("CHOPUSDT"))
+	testkit.AssertNoEntryDuring(t, chop, "chop")
+
+	crash := testkit.Run(t, configured(), testkit.FlashCrash("CRASHUSDT"))
+	testkit.AssertOutWithin(t, crash, "crash", 1)
+	testkit.AssertNoEntryDuring(t, crash, "crash")
+
+	for _, r := range []testkit.Result{trend, v, chop, crash} {
+		testkit.AssertProtected(t, r)
+		testkit.AssertMaxLoss(t, r, 2)
+	}
+}
@@ -0,0 +1,414 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError reports where an expression failed to compile.
+type SyntaxError struct {
+	Expr string
+	Pos  int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d in %q", e.Msg, e.Pos+1, e.Expr)
+}
+
+// Env holds the variable values an expression is evaluated against.
+type Env map[string]float64
+
+// Expr is a compiled boolean expression.
+type Expr struct {
+	src  string
+	vars []string
+	eval func(Env) float64
+}
+
+func (x *Expr) String() string {
+	return x.src
+}
+
+// Vars returns the variables the expression reads, sorted.
+func (x *Expr) Vars() []string {
+	return x.vars
+}
+
+// Eval reports whether the expression holds for env. Variables the
+// expression was compiled against but env lacks read as zero.
+func (x *Expr) Eval(env Env) bool {
+	return x.eval(env) != 0
+}
+
+// Compile parses src into an expression over vars. It fails on syntax
+// errors, unknown variables, type mismatches (`rsi && 3`) and on
+// expressions that are not a condition (`rsi + 1`).
+//
+// Supported: numbers, variables, parentheses, + - * /, the comparisons
+// < <= > >= == !=, and ! && || with the usual precedence.
+func Compile(src string, vars []string) (*Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		known[v] = true
+	}
+
+	p := &parser{src: src, toks: toks, vars: known, used: make(map[string]bool)}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	if !n.boolean {
+		return nil, &SyntaxError{Expr: src, Pos: 0, Msg: "expression is not a condition"}
+	}
+	used := make([]string, 0, len(p.used))
+	for v := range p.used {
+		used = append(used, v)
+	}
+	sort.Strings(used)
+	return &Expr{src: src, vars: used, eval: n.eval}, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+	num  float64
+}
+
+var operators = []string{"&&", "||", "<=", ">=", "==", "!=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			j := i
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			v, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, &SyntaxError{Expr: src, Pos: i, Msg: fmt.Sprintf("bad number %q", src[i:j])}
+			}
+			toks = append(toks, token{kind: tokNum, text: src[i:j], pos: i, num: v})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_') {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, &SyntaxError{Expr: src, Pos: i, Msg: fmt.Sprintf("unexpected %q", string(c))}
+			}
+			toks = append(toks, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: tokEOF, text: "end of expression", pos: len(src)}), nil
+}
+
+// node is a type-checked subexpression. Conditions evaluate to 1 or 0.
+type node struct {
+	boolean bool
+	eval    func(Env) float64
+}
+
+type parser struct {
+	src  string
+	toks []token
+	i    int
+	vars map[string]bool
+	used map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) accept(ops ...string) (token, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return t, false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return p.next(), true
+		}
+	}
+	return t, false
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return &SyntaxError{Expr: p.src, Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) want(t token, n node, boolean bool) error {
+	if n.boolean == boolean {
+		return nil
+	}
+	if boolean {
+		return p.errorf(t, "%q needs conditions on both sides", t.text)
+	}
+	return p.errorf(t, "%q needs numbers on both sides", t.text)
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		op, ok := p.accept("||")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.and()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.want(op, left, true); err != nil {
+			return node{}, err
+		}
+		if err := p.want(op, right, true); err != nil {
+			return node{}, err
+		}
+		l, r := left.eval, right.eval
+		left = node{boolean: true, eval: func(env Env) float64 { return truth(l(env) != 0 || r(env) != 0) }}
+	}
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		op, ok := p.accept("&&")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.not()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.want(op, left, true); err != nil {
+			return node{}, err
+		}
+		if err := p.want(op, right, true); err != nil {
+			return node{}, err
+		}
+		l, r := left.eval, right.eval
+		left = node{boolean: true, eval: func(env Env) float64 { return truth(l(env) != 0 && r(env) != 0) }}
+	}
+}
+
+func (p *parser) not() (node, error) {
+	op, ok := p.accept("!")
+	if !ok {
+		return p.comparison()
+	}
+	operand, err := p.not()
+	if err != nil {
+		return node{}, err
+	}
+	if !operand.boolean {
+		return node{}, p.errorf(op, "%q needs a condition", op.text)
+	}
+	e := operand.eval
+	return node{boolean: true, eval: func(env Env) float64 { return truth(e(env) == 0) }}, nil
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.sum()
+	if err != nil {
+		return node{}, err
+	}
+	op, ok := p.accept("<", "<=", ">", ">=", "==", "!=")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.sum()
+	if err != nil {
+		return node{}, err
+	}
+	if err := p.want(op, left, false); err != nil {
+		return node{}, err
+	}
+	if err := p.want(op, right, false); err != nil {
+		return node{}, err
+	}
+
+	l, r := left.eval, right.eval
+	var cmp func(a, b float64) bool
+	switch op.text {
+	case "<":
+		cmp = func(a, b float64) bool { return a < b }
+	case "<=":
+		cmp = func(a, b float64) bool { return a <= b }
+	case ">":
+		cmp = func(a, b float64) bool { return a > b }
+	case ">=":
+		cmp = func(a, b float64) bool { return a >= b }
+	case "==":
+		cmp = func(a, b float64) bool { return a == b }
+	default:
+		cmp = func(a, b float64) bool { return a != b }
+	}
+	return node{boolean: true, eval: func(env Env) float64 { return truth(cmp(l(env), r(env))) }}, nil
+}
+
+func (p *parser) sum() (node, error) {
+	left, err := p.product()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.product()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.want(op, left, false); err != nil {
+			return node{}, err
+		}
+		if err := p.want(op, right, false); err != nil {
+			return node{}, err
+		}
+		l, r := left.eval, right.eval
+		if op.text == "+" {
+			left = node{eval: func(env Env) float64 { return l(env) + r(env) }}
+		} else {
+			left = node{eval: func(env Env) float64 { return l(env) - r(env) }}
+		}
+	}
+}
+
+func (p *parser) product() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.want(op, left, false); err != nil {
+			return node{}, err
+		}
+		if err := p.want(op, right, false); err != nil {
+			return node{}, err
+		}
+		l, r := left.eval, right.eval
+		if op.text == "*" {
+			left = node{eval: func(env Env) float64 { return l(env) * r(env) }}
+		} else {
+			left = node{eval: func(env Env) float64 { return l(env) / r(env) }}
+		}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	op, ok := p.accept("-")
+	if !ok {
+		return p.primary()
+	}
+	operand, err := p.unary()
+	if err != nil {
+		return node{}, err
+	}
+	if operand.boolean {
+		return node{}, p.errorf(op, "%q needs a number", op.text)
+	}
+	e := operand.eval
+	return node{eval: func(env Env) float64 { return -e(env) }}, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNum:
+		v := t.num
+		return node{eval: func(Env) float64 { return v }}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return node{boolean: true, eval: func(Env) float64 { return 1 }}, nil
+		case "false":
+			return node{boolean: true, eval: func(Env) float64 { return 0 }}, nil
+		}
+		if !p.vars[t.text] {
+			return node{}, p.errorf(t, "unknown variable %q", t.text)
+		}
+		name := t.text
+		p.used[name] = true
+		return node{eval: func(env Env) float64 { return env[name] }}, nil
+	case tokOp:
+		if t.text == "(" {
+			n, err := p.or()
+			if err != nil {
+				return node{}, err
+			}
+			if closing := p.next(); closing.text != ")" || closing.kind != tokOp {
+				return node{}, p.errorf(closing, "expected \")\", got %q", closing.text)
+			}
+			return n, nil
+		}
+	}
+	return node{}, p.errorf(t, "unexpected %q", t.text)
+}
+
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,136 @@
+package rules
+
+import (
+	"context"
+	"strings"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/kline"
+)
+
+// FeatureSource serves candles and indicators from the shared candle cache.
+// kline.Service satisfies it.
+type FeatureSource interface {
+	Klines(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error)
+	Indicators(ctx context.Context, symbol, interval string) (kline.Indicators, error)
+}
+
+// FeatureIntervals are the candle intervals of the per-interval variables.
+var FeatureIntervals = []string{"1m", "5m", "15m", "1h"}
+
+// intervalIndicators are the indicators available per interval, as
+// `rsi_1m`, `ema_fast_5m` and so on.
+var intervalIndicators = []string{"rsi", "ema_fast", "ema_slow", "vwap", "atr"}
+
+// MomentumVars are the 1m momentum variables: the percent change over the
+// last 1, 5 and 15 minutes, the acceleration of the move, and volume_spike,
+// the last 1m candle's volume over the average of the hour before it.
+var MomentumVars = []string{"change_1m", "change_5m", "change_15m", "acceleration", "volume_spike"}
+
+// FeatureVars are the variables read from the candle cache, in both rules:
+// MomentumVars followed by the per-interval indicators.
+var FeatureVars = featureVars()
+
+// volumeLookback is how many 1m candles volume_spike averages over.
+const volumeLookback = 60
+
+func featureVars() []string {
+	vars := append([]string{}, MomentumVars...)
+	for _, interval := range FeatureIntervals {
+		for _, name := range intervalIndicators {
+			vars = append(vars, name+"_"+interval)
+		}
+	}
+	return vars
+}
+
+// features loads the feature variables among vars into env. It returns
+// false while any of them is not available yet, e.g. because the cache holds
+// fewer candles than the indicators warm up on, so the rule is skipped
+// rather than evaluated against zeros.
+func features(ctx context.Context, source FeatureSource, symbol string, vars []string, env Env) bool {
+	momentum := false
+	intervals := make(map[string]bool)
+	for _, v := range vars {
+		if isMomentumVar(v) {
+			momentum = true
+		} else if interval, ok := featureInterval(v); ok {
+			intervals[interval] = true
+		}
+	}
+
+	if momentum {
+		klines, err := source.Klines(ctx, symbol, "1m", volumeLookback+1)
+		if err != nil || len(klines) < volumeLookback+1 {
+			return false
+		}
+		m, ok := kline.ComputeMomentum(klines)
+		if !ok {
+			return false
+		}
+		var volume float64
+		for _, k := range klines[:volumeLookback] {
+			volume += k.Volume
+		}
+		if volume <= 0 {
+			return false
+		}
+		env["change_1m"], env["change_5m"], env["change_15m"] = m.Return1m, m.Return5m, m.Return15m
+		env["acceleration"] = m.Acceleration
+		env["volume_spike"] = klines[volumeLookback].Volume / (volume / volumeLookback)
+	}
+
+	for _, interval := range FeatureIntervals {
+		if !intervals[interval] {
+			continue
+		}
+		ind, err := source.Indicators(ctx, symbol, interval)
+		if err != nil || !ind.Warm {
+			return false
+		}
+		env["rsi_"+interval] = ind.RSI
+		env["ema_fast_"+interval] = ind.EMAFast
+		env["ema_slow_"+interval] = ind.EMASlow
+		env["vwap_"+interval] = ind.VWAP
+		env["atr_"+interval] = ind.ATR
+	}
+	return true
+}
+
+func isMomentumVar(name string) bool {
+	for _, v := range MomentumVars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// featureInterval returns the interval of a per-interval variable.
+func featureInterval(name string) (string, bool) {
+	for _, interval := range FeatureIntervals {
+		if !strings.HasSuffix(name, "_"+interval) {
+			continue
+		}
+		base := strings.TrimSuffix(name, "_"+interval)
+		for _, ind := range intervalIndicators {
+			if ind == base {
+				return interval, true
+			}
+		}
+	}
+	return "", false
+}
+
+// usesFeatures reports whether any of vars is read from the candle cache.
+func usesFeatures(vars []string) bool {
+	for _, v := range vars {
+		if isMomentumVar(v) {
+			return true
+		}
+		if _, ok := featureInterval(v); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+// Package rules runs strategies written as conditions over the indicator
+// snapshot instead of Go code. A strategy config of type "rules" carries
+//
+//	"rules": {"entry": "rsi < 70 && ema_fast > ema_slow * 1.002", "exit": "pnl_percent < -1 || rsi > 80"}
+//
+// Both are compiled when the strategy is configured, so a typo or an
+// unknown variable fails at startup rather than on the first tick. Every
+// key of the config's parameters is available as a variable too, unless it
+// would shadow a market variable. With a FeatureSource, the 1m momentum,
+// volume spike and per-interval indicators of FeatureVars can be used as
+// well, e.g. `volume_spike > 8 && change_5m > 3 && rsi_1m < 70`.
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/indicator"
+)
+
+// MarketVars are the indicator snapshot variables, in both rules. The
+// candlestick patterns of indicator.PatternNames follow them, e.g.
+// `engulfing > 0.5 || pin_bar > 0.7`.
+var MarketVars = append([]string{
+	"price", "high_24h", "low_24h", "volume_24h", "volatility",
+	"rsi", "ema_fast", "ema_slow", "vwap", "atr", "swing_low", "swing_high",
+}, indicator.PatternNames...)
+
+// PositionVars are the open position variables, in exit rules only.
+var PositionVars = []string{"pnl_percent", "pnl", "hold_minutes"}
+
+type RulesStrategy struct {
+	cfg      strategy.StrategyConfig
+	entry    *Expr
+	exit     *Expr
+	features FeatureSource
+}
+
+// NewRulesStrategy creates a rules strategy whose rules can read the
+// FeatureVars from features, the shared candle cache.
+func NewRulesStrategy(features FeatureSource) *RulesStrategy {
+	return &RulesStrategy{features: features}
+}
+
+func (s *RulesStrategy) Type() strategy.StrategyType {
+	return strategy.StrategyRules
+}
+
+func (s *RulesStrategy) Name() string {
+	if s.cfg.Name != "" {
+		return s.cfg.Name
+	}
+	return "rules_strategy"
+}
+
+func (s *RulesStrategy) Version() string {
+	if s.cfg.Version != "" {
+		return s.cfg.Version
+	}
+	return "1.0.0"
+}
+
+func (s *RulesStrategy) Configure(config strategy.StrategyConfig) error {
+	if config.Rules.Entry == "" {
+		return fmt.Errorf("%w: rules strategy %q has no entry rule", strategy.ErrInvalidConfig, config.Name)
+	}
+
+	market := append(append([]string{}, MarketVars...), FeatureVars...)
+	reserved := make(map[string]bool)
+	for _, name := range append(append([]string{}, market...), PositionVars...) {
+		reserved[name] = true
+	}
+	params := make([]string, 0, len(config.Parameters))
+	for name := range config.Parameters {
+		if reserved[name] {
+			return fmt.Errorf("%w: parameter %q of %q shadows a market variable", strategy.ErrInvalidConfig, name, config.Name)
+		}
+		params = append(params, name)
+	}
+	sort.Strings(params)
+
+	entry, err := Compile(config.Rules.Entry, append(append([]string{}, market...), params...))
+	if err != nil {
+		return fmt.Errorf("%w: entry rule of %q: %v", strategy.ErrInvalidConfig, config.Name, err)
+	}
+	var exit *Expr
+	if config.Rules.Exit != "" {
+		vars := append(append(append([]string{}, market...), PositionVars...), params...)
+		if exit, err = Compile(config.Rules.Exit, vars); err != nil {
+			return fmt.Errorf("%w: exit rule of %q: %v", strategy.ErrInvalidConfig, config.Name, err)
+		}
+	}
+	if s.features == nil && (usesFeatures(entry.Vars()) || (exit != nil && usesFeatures(exit.Vars()))) {
+		return fmt.Errorf("%w: rules of %q read the candle cache, which this strategy was created without", strategy.ErrInvalidConfig, config.Name)
+	}
+
+	s.cfg, s.entry, s.exit = config, entry, exit
+	return nil
+}
+
+func (s *RulesStrategy) Validate() error {
+	if s.entry == nil {
+		return strategy.ErrInvalidConfig
+	}
+	return nil
+}
+
+func (s *RulesStrategy) ShouldEnter(ctx context.Context, market trade.MarketData) (bool, string, error) {
+	if s.entry == nil {
+		return false, "", strategy.ErrInvalidConfig
+	}
+	env, warm := s.env(ctx, s.entry, market, nil)
+	if !warm {
+		return false, "Indicators warming up", nil
+	}
+	if s.entry.Eval(env) {
+		return true, "Entry rule matched: " + s.entry.String(), nil
+	}
+	return false, "Entry rule not matched", nil
+}
+
+func (s *RulesStrategy) ShouldExit(ctx context.Context, position *trade.Position, market trade.MarketData) (bool, string, error) {
+	if s.exit == nil {
+		return false, "", nil
+	}
+	env, warm := s.env(ctx, s.exit, market, position)
+	if warm && s.exit.Eval(env) {
+		return true, "Exit rule matched: " + s.exit.String(), nil
+	}
+	return false, "", nil
+}
+
+// env builds the variables x reads. warm is false while a feature variable
+// it reads is not available yet.
+func (s *RulesStrategy) env(ctx context.Context, x *Expr, market trade.MarketData, position *trade.Position) (env Env, warm bool) {
+	env = Env{
+		"price":      market.CurrentPrice,
+		"high_24h":   market.High24h,
+		"low_24h":    market.Low24h,
+		"volume_24h": market.Volume24h,
+		"volatility": market.Volatility,
+		"rsi":        market.RSI,
+		"ema_fast":   market.EMAFast,
+		"ema_slow":   market.EMASlow,
+		"vwap":       market.VWAP,
+		"atr":        market.ATR,
+		"swing_low":  market.SwingLow,
+		"swing_high": market.SwingHigh,
+	}
+	for _, name := range indicator.PatternNames {
+		env[name] = market.Patterns[name]
+	}
+	if position != nil {
+		env["pnl_percent"] = position.PnLPercent
+		env["pnl"] = position.PnL
+		if !position.OpenedAt.IsZero() {
+			env["hold_minutes"] = time.Since(position.OpenedAt).Minutes()
+		}
+	}
+	for name, v := range s.cfg.Parameters {
+		env[name] = v
+	}
+	if s.features != nil && usesFeatures(x.Vars()) {
+		return env, features(ctx, s.features, market.Symbol, x.Vars(), env)
+	}
+	return env, true
+}
+
+func (s *RulesStrategy) CalculatePositionSize(ctx context.Context, market trade.MarketData, balance float64) (float64, error) {
+	stopLossDistance := market.CurrentPrice * s.cfg.RiskParameters.StopLossPercent
+	if stopLossDistance <= 0 {
+		return 0, nil
+	}
+	return balance * s.cfg.RiskParameters.RiskPerTrade / stopLossDistance, nil
+}
+
+func (s *RulesStrategy) CalculateStopLoss(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	if stopLoss, _, ok := s.cfg.RiskParameters.ATRLevels(trade.SideBuy, entryPrice, market); ok {
+		return stopLoss, nil
+	}
+	return entryPrice * (1 - s.cfg.RiskParameters.StopLossPercent), nil
+}
+
+func (s *RulesStrategy) CalculateTakeProfit(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	if _, takeProfit, ok := s.cfg.RiskParameters.ATRLevels(trade.SideBuy, entryPrice, market); ok {
+		return takeProfit, nil
+	}
+	return entryPrice * (1 + s.cfg.RiskParameters.TakeProfitPercent), nil
+}
+
+func (s *RulesStrategy) CalculateTrailingStop(ctx context.Context, position *trade.Position, market trade.MarketData) (float64, error) {
+	trailingPercent := s.cfg.RiskParameters.TrailingStopPercent
+	if position.Side == trade.SideBuy {
+		return market.TightenToVWAP(position.Side, market.CurrentPrice*(1-trailingPercent)), nil
+	}
+	return market.TightenToVWAP(position.Side, market.CurrentPrice*(1+trailingPercent)), nil
+}
+
+func (s *RulesStrategy) OnTick(ctx context.Context, position *trade.Position, market trade.MarketData) error {
+	return nil
+}
+
+func (s *RulesStrategy) OnOrderFill(ctx context.Context, order *trade.Order, position *trade.Position) error {
+	return nil
+}
+
+func (s *RulesStrategy) OnPositionClose(ctx context.Context, position *trade.Position, reason string) error {
+	return nil
+}
+
+func (s *RulesStrategy) GetParameters() map[string]interface{} {
+	params := map[string]interface{}{
+		"entry_rule":          s.cfg.Rules.Entry,
+		"exit_rule":           s.cfg.Rules.Exit,
+		"risk_per_trade":      s.cfg.RiskParameters.RiskPerTrade,
+		"stop_loss_percent":   s.cfg.RiskParameters.StopLossPercent,
+		"take_profit_percent": s.cfg.RiskParameters.TakeProfitPercent,
+	}
+	for name, v := range s.cfg.Parameters {
+		params[name] = v
+	}
+	return params
+}
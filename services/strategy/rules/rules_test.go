@@ -0,0 +1,144 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/britej3/gobot/domain/strategy"
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/kline"
+)
+
+func TestCompile(t *testing.T) {
+	vars := []string{"rsi", "ema_fast", "ema_slow", "volume_spike"}
+	cases := []struct {
+		src  string
+		env  Env
+		want bool
+	}{
+		{"rsi < 70", Env{"rsi": 65}, true},
+		{"volume_spike > 8 && rsi < 70", Env{"volume_spike": 9, "rsi": 71}, false},
+		{"ema_fast > ema_slow * 1.01 || rsi <= 30", Env{"ema_fast": 101, "ema_slow": 100, "rsi": 30}, true},
+		{"!(rsi > 50) && -rsi < -10", Env{"rsi": 40}, true},
+		{"(rsi - 50) / 2 >= 5", Env{"rsi": 58}, false},
+	}
+	for _, c := range cases {
+		x, err := Compile(c.src, vars)
+		if err != nil {
+			t.Fatalf("%q: %v", c.src, err)
+		}
+		if got := x.Eval(c.env); got != c.want {
+			t.Errorf("%q on %v = %v, want %v", c.src, c.env, got, c.want)
+		}
+	}
+
+	for src, msg := range map[string]string{
+		"rsi_1m < 70":     `unknown variable "rsi_1m"`,
+		"rsi + 1":         "not a condition",
+		"rsi < 70 &&":     "unexpected",
+		"rsi && ema_fast": "needs conditions",
+		"true < 1":        "needs numbers",
+		"(rsi < 70":       `expected ")"`,
+		"rsi # 3":         "unexpected",
+	} {
+		_, err := Compile(src, vars)
+		var syntax *SyntaxError
+		if !errors.As(err, &syntax) || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q: got %v, want %q", src, err, msg)
+		}
+	}
+}
+
+func TestRulesStrategy(t *testing.T) {
+	s := &RulesStrategy{}
+	err := s.Configure(strategy.StrategyConfig{
+		Name:       "dip_buyer",
+		Parameters: map[string]float64{"max_rsi": 35},
+		Rules:      strategy.RuleConfig{Entry: "rsi < max_rsi && price > vwap", Exit: "pnl_percent < -1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enter, _, err := s.ShouldEnter(context.Background(), trade.MarketData{RSI: 30, CurrentPrice: 101, VWAP: 100})
+	if err != nil || !enter {
+		t.Errorf("expected entry, got %v, %v", enter, err)
+	}
+	exit, _, _ := s.ShouldExit(context.Background(), &trade.Position{PnLPercent: -1.5}, trade.MarketData{})
+	if !exit {
+		t.Error("expected the exit rule to fire")
+	}
+
+	hammer := trade.MarketData{RSI: 30, CurrentPrice: 101, VWAP: 100, Patterns: map[string]float64{"pin_bar": 0.8}}
+	if err := s.Configure(strategy.StrategyConfig{Rules: strategy.RuleConfig{Entry: "pin_bar > 0.7 && engulfing >= 0"}}); err != nil {
+		t.Fatal(err)
+	}
+	if enter, _, _ := s.ShouldEnter(context.Background(), hammer); !enter {
+		t.Error("expected the pattern rule to match a hammer")
+	}
+	if enter, _, _ := s.ShouldEnter(context.Background(), trade.MarketData{}); enter {
+		t.Error("a missing pattern should read 0")
+	}
+
+	err = s.Configure(strategy.StrategyConfig{Name: "typo", Rules: strategy.RuleConfig{Entry: "pnl_percent > 1"}})
+	if !errors.Is(err, strategy.ErrInvalidConfig) {
+		t.Errorf("position variables in an entry rule should fail to configure, got %v", err)
+	}
+}
+
+// stubFeatures serves 61 flat 1m candles rising 4% over the last five, the
+// last on 10 times the volume, and indicators that are warm unless cold.
+type stubFeatures struct {
+	cold bool
+}
+
+func (f stubFeatures) Klines(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error) {
+	klines := make([]trade.Kline, limit)
+	for i := range klines {
+		klines[i] = trade.Kline{Close: 100, Volume: 10}
+		if i >= limit-5 {
+			klines[i].Close = 100 + float64(i-limit+6)*0.8
+		}
+	}
+	klines[limit-1].Volume = 100
+	return klines, nil
+}
+
+func (f stubFeatures) Indicators(ctx context.Context, symbol, interval string) (kline.Indicators, error) {
+	return kline.Indicators{RSI: 60, Warm: !f.cold}, nil
+}
+
+func TestRulesStrategy_FeatureVariables(t *testing.T) {
+	cfg := strategy.StrategyConfig{
+		Name:  "spike_chaser",
+		Rules: strategy.RuleConfig{Entry: "volume_spike > 8 && change_5m > 3 && rsi_1m < 70"},
+	}
+	market := trade.MarketData{Symbol: "BTCUSDT"}
+
+	s := NewRulesStrategy(stubFeatures{})
+	if err := s.Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if enter, reason, err := s.ShouldEnter(context.Background(), market); err != nil || !enter {
+		t.Errorf("expected the spike rule to match, got %v (%s), %v", enter, reason, err)
+	}
+
+	cold := NewRulesStrategy(stubFeatures{cold: true})
+	if err := cold.Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if enter, reason, _ := cold.ShouldEnter(context.Background(), market); enter || reason != "Indicators warming up" {
+		t.Errorf("a rule over cold indicators must not be evaluated, got %v (%s)", enter, reason)
+	}
+
+	if err := (&RulesStrategy{}).Configure(cfg); !errors.Is(err, strategy.ErrInvalidConfig) {
+		t.Errorf("feature rules without a candle cache should fail to configure, got %v", err)
+	}
+
+	cfg.Parameters = map[string]float64{"rsi": 30}
+	if err := s.Configure(cfg); !errors.Is(err, strategy.ErrInvalidConfig) {
+		t.Errorf("a parameter named after a market variable should fail to configure, got %v", err)
+	}
+}
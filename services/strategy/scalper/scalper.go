@@ -5,10 +5,22 @@ import (
 
 	"github.com/britej3/gobot/domain/strategy"
 	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/services/footprint"
 )
 
 type ScalperStrategy struct {
-	cfg strategy.StrategyConfig
+	cfg       strategy.StrategyConfig
+	footprint FootprintSource
+}
+
+type FootprintSource interface {
+	Last(symbol string) (footprint.Features, bool)
+}
+
+const adverseDeltaPercent = -30
+
+func (s *ScalperStrategy) SetFootprintSource(source FootprintSource) {
+	s.footprint = source
 }
 
 func (s *ScalperStrategy) Type() strategy.StrategyType {
@@ -33,6 +45,20 @@ func (s *ScalperStrategy) Validate() error {
 }
 
 func (s *ScalperStrategy) ShouldEnter(ctx context.Context, market trade.MarketData) (bool, string, error) {
+	if s.footprint != nil {
+		if f, ok := s.footprint.Last(market.Symbol); ok {
+			if f.AskAbsorption {
+				return false, "Sellers absorbing at candle high", nil
+			}
+			if f.StackedSell || f.DeltaPercent < adverseDeltaPercent {
+				return false, "Aggressive selling in order flow", nil
+			}
+			if f.StackedBuy || f.BidAbsorption {
+				return true, "Order flow confirms buyers", nil
+			}
+		}
+	}
+
 	if market.RSI > 30 && market.RSI < 70 {
 		if market.EMAFast > market.EMASlow {
 			return true, "RSI in range with bullish trend", nil
@@ -70,11 +96,17 @@ func (s *ScalperStrategy) CalculatePositionSize(ctx context.Context, market trad
 }
 
 func (s *ScalperStrategy) CalculateStopLoss(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	if stopLoss, _, ok := s.cfg.RiskParameters.ATRLevels(trade.SideBuy, entryPrice, market); ok {
+		return stopLoss, nil
+	}
 	stopLossPercent := s.cfg.RiskParameters.StopLossPercent
 	return entryPrice * (1 - stopLossPercent), nil
 }
 
 func (s *ScalperStrategy) CalculateTakeProfit(ctx context.Context, entryPrice float64, market trade.MarketData) (float64, error) {
+	if _, takeProfit, ok := s.cfg.RiskParameters.ATRLevels(trade.SideBuy, entryPrice, market); ok {
+		return takeProfit, nil
+	}
 	takeProfitPercent := s.cfg.RiskParameters.TakeProfitPercent
 	return entryPrice * (1 + takeProfitPercent), nil
 }
@@ -82,9 +114,9 @@ func (s *ScalperStrategy) CalculateTakeProfit(ctx context.Context, entryPrice fl
 func (s *ScalperStrategy) CalculateTrailingStop(ctx context.Context, position *trade.Position, market trade.MarketData) (float64, error) {
 	trailingPercent := 0.3
 	if position.Side == trade.SideBuy {
-		return market.CurrentPrice * (1 - trailingPercent), nil
+		return market.TightenToVWAP(position.Side, market.CurrentPrice*(1-trailingPercent)), nil
 	}
-	return market.CurrentPrice * (1 + trailingPercent), nil
+	return market.TightenToVWAP(position.Side, market.CurrentPrice*(1+trailingPercent)), nil
 }
 
 func (s *ScalperStrategy) OnTick(ctx context.Context, position *trade.Position, market trade.MarketData) error {
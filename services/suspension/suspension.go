@@ -0,0 +1,229 @@
+package suspension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/britej3/gobot/pkg/state"
+)
+
+// Sources a suspension can come from. File suspensions are lifted when their
+// file goes away; the others stay until explicitly lifted.
+const (
+	SourceFile     = "file"
+	SourceAPI      = "api"
+	SourceTelegram = "telegram"
+)
+
+var ErrNoTarget = errors.New("no strategy or symbol given")
+
+// Store persists suspensions, typically the trading state.
+type Store interface {
+	Suspend(target, reason, source string)
+	Unsuspend(target string) bool
+	IsSuspended(target string) bool
+	ActiveSuspensions() map[string]state.Suspension
+}
+
+// Config controls the file and API surfaces. Every file in Dir suspends the
+// strategy or symbol it is named after; its contents, if any, are the
+// reason. Password, if set, must be sent as X-Kill-Switch-Password to change
+// suspensions over HTTP.
+type Config struct {
+	Dir          string
+	PollInterval time.Duration
+	Password     string
+}
+
+// Entry is one active suspension.
+type Entry struct {
+	Target string    `json:"target"`
+	Reason string    `json:"reason"`
+	Source string    `json:"source"`
+	Since  time.Time `json:"since"`
+}
+
+// Controller suspends and resumes single strategies or symbols from files,
+// HTTP and chat commands, next to the global kill switch.
+type Controller struct {
+	cfg   Config
+	store Store
+}
+
+func New(cfg Config, store Store) *Controller {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	return &Controller{cfg: cfg, store: store}
+}
+
+func (c *Controller) Suspend(target, reason, source string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return ErrNoTarget
+	}
+	if reason == "" {
+		reason = "suspended via " + source
+	}
+	c.store.Suspend(target, reason, source)
+	return nil
+}
+
+func (c *Controller) Resume(target string) bool {
+	return c.store.Unsuspend(target)
+}
+
+func (c *Controller) IsSuspended(target string) bool {
+	return c.store.IsSuspended(target)
+}
+
+// List returns the active suspensions sorted by target.
+func (c *Controller) List() []Entry {
+	active := c.store.ActiveSuspensions()
+	out := make([]Entry, 0, len(active))
+	for target, s := range active {
+		out = append(out, Entry{Target: target, Reason: s.Reason, Source: s.Source, Since: s.Since})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Target < out[j].Target })
+	return out
+}
+
+// Command handles a chat command: "/disable <target> [reason]",
+// "/enable <target>" or "/suspended". It returns the reply and whether text
+// was one of these commands.
+func (c *Controller) Command(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", false
+	}
+	// Group chats address commands as /disable@botname.
+	cmd := strings.ToLower(strings.SplitN(fields[0], "@", 2)[0])
+
+	switch cmd {
+	case "/disable":
+		if len(fields) < 2 {
+			return "Usage: /disable <strategy|symbol> [reason]", true
+		}
+		reason := strings.Join(fields[2:], " ")
+		if err := c.Suspend(fields[1], reason, SourceTelegram); err != nil {
+			return err.Error(), true
+		}
+		return fmt.Sprintf("%s suspended", strings.ToUpper(fields[1])), true
+	case "/enable":
+		if len(fields) < 2 {
+			return "Usage: /enable <strategy|symbol>", true
+		}
+		if !c.Resume(fields[1]) {
+			return fmt.Sprintf("%s was not suspended", strings.ToUpper(fields[1])), true
+		}
+		return fmt.Sprintf("%s resumed", strings.ToUpper(fields[1])), true
+	case "/suspended":
+		entries := c.List()
+		if len(entries) == 0 {
+			return "Nothing suspended", true
+		}
+		lines := make([]string, 0, len(entries))
+		for _, e := range entries {
+			lines = append(lines, fmt.Sprintf("%s since %s (%s: %s)", e.Target, e.Since.Format("Jan 2 15:04"), e.Source, e.Reason))
+		}
+		return strings.Join(lines, "\n"), true
+	}
+	return "", false
+}
+
+// SyncDir suspends every target with a file in the directory and resumes
+// file suspensions whose file was removed.
+func (c *Controller) SyncDir() error {
+	if c.cfg.Dir == "" {
+		return nil
+	}
+	files, err := os.ReadDir(c.cfg.Dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	present := make(map[string]bool, len(files))
+	active := c.store.ActiveSuspensions()
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		target := strings.ToUpper(f.Name())
+		present[target] = true
+		if _, ok := active[target]; ok {
+			continue
+		}
+		reason, _ := os.ReadFile(filepath.Join(c.cfg.Dir, f.Name()))
+		c.Suspend(target, strings.TrimSpace(string(reason)), SourceFile)
+	}
+
+	for target, s := range active {
+		if s.Source == SourceFile && !present[target] {
+			c.store.Unsuspend(target)
+		}
+	}
+	return nil
+}
+
+// Run syncs the suspension directory until ctx is cancelled.
+func (c *Controller) Run(ctx context.Context) {
+	if c.cfg.Dir == "" {
+		return
+	}
+	ticker := time.NewTicker(c.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		c.SyncDir()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ServeHTTP lists suspensions on GET, suspends on POST with a JSON body of
+// {"target", "reason"} and resumes on DELETE ?target=.
+func (c *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && c.cfg.Password != "" && r.Header.Get("X-Kill-Switch-Password") != c.cfg.Password {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Target string `json:"target"`
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		if err := c.Suspend(req.Target, req.Reason, SourceAPI); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		if !c.Resume(r.URL.Query().Get("target")) {
+			http.Error(w, "Not suspended", http.StatusNotFound)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.List())
+}
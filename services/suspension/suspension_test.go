@@ -0,0 +1,71 @@
+package suspension
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/britej3/gobot/pkg/state"
+)
+
+func newState(t *testing.T) *state.TradingState {
+	st, err := state.NewStateManager(state.StateConfig{StateDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestCommandsAndFiles(t *testing.T) {
+	st := newState(t)
+	dir := t.TempDir()
+	c := New(Config{Dir: dir}, st)
+
+	if reply, ok := c.Command("/disable@gobot_bot scalper_strategy keeps stopping out"); !ok || !strings.Contains(reply, "SCALPER_STRATEGY suspended") {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+	if !c.IsSuspended("scalper_strategy") || c.IsSuspended("momentum") {
+		t.Error("only the scalper should be suspended")
+	}
+	if _, ok := c.Command("hello"); ok {
+		t.Error("plain text is not a command")
+	}
+
+	os.WriteFile(filepath.Join(dir, "WIFUSDT"), []byte("delisting rumours\n"), 0644)
+	c.SyncDir()
+	if entries := c.List(); len(entries) != 2 || entries[1].Target != "WIFUSDT" || entries[1].Reason != "delisting rumours" {
+		t.Fatalf("unexpected suspensions %+v", entries)
+	}
+
+	os.Remove(filepath.Join(dir, "WIFUSDT"))
+	c.SyncDir()
+	if c.IsSuspended("WIFUSDT") || !c.IsSuspended("SCALPER_STRATEGY") {
+		t.Error("removing the file should lift only the file suspension")
+	}
+
+	if reply, _ := c.Command("/enable scalper_strategy"); !strings.Contains(reply, "resumed") || len(c.List()) != 0 {
+		t.Errorf("unexpected reply %q, %d left", reply, len(c.List()))
+	}
+}
+
+func TestServeHTTPRequiresPassword(t *testing.T) {
+	c := New(Config{Password: "secret"}, newState(t))
+
+	req := httptest.NewRequest(http.MethodPost, "/suspensions", strings.NewReader(`{"target":"BTCUSDT"}`))
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without password, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/suspensions", strings.NewReader(`{"target":"BTCUSDT","reason":"manual"}`))
+	req.Header.Set("X-Kill-Switch-Password", "secret")
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !c.IsSuspended("btcusdt") {
+		t.Errorf("expected BTCUSDT suspended, got %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,213 @@
+package symbolmemory
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config tunes how strongly past results on a symbol sway the screener and
+// the entry threshold. Results lose half their weight every HalfLife, and a
+// symbol needs MinTrades of (decayed) history before it is scored at all.
+//
+// Entry slippage counts against a symbol once its decayed average, in basis
+// points, exceeds SlippageAllowance: the screener multiplier shrinks by up
+// to MaxSlippagePenalty as the excess approaches SlippageSaturation.
+type Config struct {
+	HalfLife   time.Duration
+	MinTrades  float64
+	Saturation float64
+	MaxBoost   float64
+	MaxPenalty float64
+	MaxHistory int
+
+	SlippageAllowance  float64
+	SlippageSaturation float64
+	MaxSlippagePenalty float64
+}
+
+type Outcome struct {
+	Symbol     string
+	PnLPercent float64
+	At         time.Time
+}
+
+// Fill is the slippage of one entry against its signal price, in basis
+// points; positive is worse than the signal.
+type Fill struct {
+	Symbol      string
+	SlippageBps float64
+	At          time.Time
+}
+
+type SymbolScore struct {
+	Symbol      string
+	Score       float64
+	Trades      int
+	SlippageBps float64
+}
+
+type Memory struct {
+	cfg      Config
+	mu       sync.RWMutex
+	outcomes map[string][]Outcome
+	fills    map[string][]Fill
+	now      func() time.Time
+}
+
+func New(cfg Config) *Memory {
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = 7 * 24 * time.Hour
+	}
+	if cfg.MinTrades <= 0 {
+		cfg.MinTrades = 2
+	}
+	if cfg.Saturation <= 0 {
+		cfg.Saturation = 5
+	}
+	if cfg.MaxBoost <= 0 {
+		cfg.MaxBoost = 0.15
+	}
+	if cfg.MaxPenalty <= 0 {
+		cfg.MaxPenalty = 0.1
+	}
+	if cfg.MaxHistory <= 0 {
+		cfg.MaxHistory = 100
+	}
+	if cfg.SlippageAllowance <= 0 {
+		cfg.SlippageAllowance = 5
+	}
+	if cfg.SlippageSaturation <= 0 {
+		cfg.SlippageSaturation = 20
+	}
+	if cfg.MaxSlippagePenalty <= 0 {
+		cfg.MaxSlippagePenalty = 0.2
+	}
+
+	return &Memory{
+		cfg:      cfg,
+		outcomes: make(map[string][]Outcome),
+		fills:    make(map[string][]Fill),
+		now:      time.Now,
+	}
+}
+
+func (m *Memory) Record(symbol string, pnlPercent float64, at time.Time) {
+	symbol = strings.ToUpper(symbol)
+	if at.IsZero() {
+		at = m.now()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := append(m.outcomes[symbol], Outcome{Symbol: symbol, PnLPercent: pnlPercent, At: at})
+	if len(history) > m.cfg.MaxHistory {
+		history = history[len(history)-m.cfg.MaxHistory:]
+	}
+	m.outcomes[symbol] = history
+}
+
+// RecordSlippage remembers how far an entry on symbol filled from its
+// signal price.
+func (m *Memory) RecordSlippage(symbol string, bps float64, at time.Time) {
+	symbol = strings.ToUpper(symbol)
+	if at.IsZero() {
+		at = m.now()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fills := append(m.fills[symbol], Fill{Symbol: symbol, SlippageBps: bps, At: at})
+	if len(fills) > m.cfg.MaxHistory {
+		fills = fills[len(fills)-m.cfg.MaxHistory:]
+	}
+	m.fills[symbol] = fills
+}
+
+// Score summarises a symbol's decayed realized PnL in [-1, 1]: positive for
+// symbols that have paid, negative for repeated losers, zero without enough
+// recent history.
+func (m *Memory) Score(symbol string) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.score(m.outcomes[strings.ToUpper(symbol)])
+}
+
+func (m *Memory) score(history []Outcome) float64 {
+	now := m.now()
+	weight, pnl := 0.0, 0.0
+	for _, o := range history {
+		w := math.Pow(0.5, now.Sub(o.At).Hours()/m.cfg.HalfLife.Hours())
+		weight += w
+		pnl += w * o.PnLPercent
+	}
+	if weight < m.cfg.MinTrades {
+		return 0
+	}
+	return math.Tanh(pnl / m.cfg.Saturation)
+}
+
+// Slippage returns a symbol's decayed average entry slippage in basis
+// points, zero without MinTrades of recent fills.
+func (m *Memory) Slippage(symbol string) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.slippage(m.fills[strings.ToUpper(symbol)])
+}
+
+func (m *Memory) slippage(fills []Fill) float64 {
+	now := m.now()
+	weight, bps := 0.0, 0.0
+	for _, f := range fills {
+		w := math.Pow(0.5, now.Sub(f.At).Hours()/m.cfg.HalfLife.Hours())
+		weight += w
+		bps += w * f.SlippageBps
+	}
+	if weight < m.cfg.MinTrades {
+		return 0
+	}
+	return bps / weight
+}
+
+// Multiplier scales a screener score by up to ±MaxBoost for realized
+// results, and down by up to MaxSlippagePenalty for symbols that keep
+// filling worse than SlippageAllowance.
+func (m *Memory) Multiplier(symbol string) float64 {
+	mult := 1 + m.Score(symbol)*m.cfg.MaxBoost
+	if excess := m.Slippage(symbol) - m.cfg.SlippageAllowance; excess > 0 {
+		mult *= 1 - m.cfg.MaxSlippagePenalty*math.Tanh(excess/m.cfg.SlippageSaturation)
+	}
+	return mult
+}
+
+// RequiredConfidence raises the entry threshold for symbols the bot keeps
+// losing on; profitable symbols keep the base threshold.
+func (m *Memory) RequiredConfidence(symbol string, base float64) float64 {
+	score := m.Score(symbol)
+	if score >= 0 {
+		return base
+	}
+	return math.Min(base-score*m.cfg.MaxPenalty, 0.99)
+}
+
+// Scores returns every remembered symbol's score, best first.
+func (m *Memory) Scores() []SymbolScore {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]SymbolScore, 0, len(m.outcomes))
+	for symbol, history := range m.outcomes {
+		out = append(out, SymbolScore{
+			Symbol:      symbol,
+			Score:       m.score(history),
+			Trades:      len(history),
+			SlippageBps: m.slippage(m.fills[symbol]),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
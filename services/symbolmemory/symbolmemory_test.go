@@ -0,0 +1,71 @@
+package symbolmemory
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMemory_RewardsWinnersAndPenalisesLosers(t *testing.T) {
+	m := New(Config{})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		m.Record("WIFUSDT", 4, now)
+		m.Record("PEPEUSDT", -3, now)
+	}
+	m.Record("NEWUSDT", 10, now)
+
+	if mult := m.Multiplier("WIFUSDT"); mult <= 1 || mult > 1.15 {
+		t.Errorf("expected winner multiplier in (1, 1.15], got %f", mult)
+	}
+	if mult := m.Multiplier("PEPEUSDT"); mult >= 1 {
+		t.Errorf("expected loser multiplier below 1, got %f", mult)
+	}
+	if mult := m.Multiplier("NEWUSDT"); mult != 1 {
+		t.Errorf("expected single trade to be ignored, got %f", mult)
+	}
+
+	if got := m.RequiredConfidence("WIFUSDT", 0.65); got != 0.65 {
+		t.Errorf("expected winner to keep base confidence, got %f", got)
+	}
+	if got := m.RequiredConfidence("PEPEUSDT", 0.65); got <= 0.65 || got > 0.75 {
+		t.Errorf("expected loser confidence in (0.65, 0.75], got %f", got)
+	}
+}
+
+func TestMemory_Decay(t *testing.T) {
+	m := New(Config{HalfLife: time.Hour})
+	old := time.Now().Add(-10 * time.Hour)
+
+	for i := 0; i < 5; i++ {
+		m.Record("WIFUSDT", -5, old)
+	}
+	if s := m.Score("WIFUSDT"); s != 0 {
+		t.Errorf("expected losses ten half-lives old to be forgotten, got %f", s)
+	}
+}
+
+func TestMemory_PenalisesPersistentSlippage(t *testing.T) {
+	m := New(Config{})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		m.RecordSlippage("LOWLIQUSDT", 30, now)
+		m.RecordSlippage("BTCUSDT", 2, now)
+	}
+	m.RecordSlippage("ONCEUSDT", 80, now)
+
+	if got := m.Slippage("lowliqusdt"); math.Abs(got-30) > 1e-6 {
+		t.Errorf("expected average slippage 30bps, got %f", got)
+	}
+	if mult := m.Multiplier("LOWLIQUSDT"); mult >= 0.9 || mult < 0.8 {
+		t.Errorf("expected slippage multiplier in [0.8, 0.9), got %f", mult)
+	}
+	if mult := m.Multiplier("BTCUSDT"); mult != 1 {
+		t.Errorf("slippage within the allowance should not penalise, got %f", mult)
+	}
+	if mult := m.Multiplier("ONCEUSDT"); mult != 1 {
+		t.Errorf("expected a single fill to be ignored, got %f", mult)
+	}
+}
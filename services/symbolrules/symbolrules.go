@@ -0,0 +1,346 @@
+package symbolrules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownSymbol    = errors.New("no trading rules for symbol")
+	ErrInvalidTick      = errors.New("price is not a multiple of tick size")
+	ErrPriceOutOfRange  = errors.New("price outside allowed range")
+	ErrInvalidStep      = errors.New("quantity is not a multiple of step size")
+	ErrQuantityTooSmall = errors.New("quantity below minimum")
+	ErrQuantityTooLarge = errors.New("quantity above maximum")
+	ErrNotionalTooSmall = errors.New("notional below minimum")
+)
+
+// Rules are the PRICE_FILTER, LOT_SIZE and MIN_NOTIONAL constraints the
+// exchange enforces for a symbol.
+type Rules struct {
+	Symbol      string    `json:"symbol"`
+	TickSize    float64   `json:"tick_size"`
+	MinPrice    float64   `json:"min_price"`
+	MaxPrice    float64   `json:"max_price"`
+	StepSize    float64   `json:"step_size"`
+	MinQty      float64   `json:"min_qty"`
+	MaxQty      float64   `json:"max_qty"`
+	MinNotional float64   `json:"min_notional"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RoundPrice snaps price to the nearest valid tick.
+func (r Rules) RoundPrice(price float64) float64 {
+	return roundTo(price, r.TickSize, math.Round)
+}
+
+// RoundQuantity floors qty to the lot step so rounding never increases size.
+func (r Rules) RoundQuantity(qty float64) float64 {
+	return roundTo(qty, r.StepSize, math.Floor)
+}
+
+// MinQuantity returns the smallest lot-aligned quantity that meets both the
+// minimum quantity and, at price, the minimum notional.
+func (r Rules) MinQuantity(price float64) float64 {
+	qty := r.MinQty
+	if price > 0 && r.MinNotional > 0 && r.MinNotional/price > qty {
+		qty = r.MinNotional / price
+	}
+	return roundTo(qty, r.StepSize, func(n float64) float64 { return math.Ceil(n - 2e-9) })
+}
+
+func (r Rules) FormatPrice(price float64) string {
+	return strconv.FormatFloat(r.RoundPrice(price), 'f', decimals(r.TickSize), 64)
+}
+
+func (r Rules) FormatQuantity(qty float64) string {
+	return strconv.FormatFloat(r.RoundQuantity(qty), 'f', decimals(r.StepSize), 64)
+}
+
+// Validate checks an order against the rules. A zero price skips the price
+// and notional checks, as for market orders without a reference price.
+func (r Rules) Validate(price, qty float64) error {
+	if price > 0 {
+		if !aligned(price, r.TickSize) {
+			return fmt.Errorf("%w: %s %v (tick %v)", ErrInvalidTick, r.Symbol, price, r.TickSize)
+		}
+		if (r.MinPrice > 0 && price < r.MinPrice) || (r.MaxPrice > 0 && price > r.MaxPrice) {
+			return fmt.Errorf("%w: %s %v", ErrPriceOutOfRange, r.Symbol, price)
+		}
+	}
+
+	if !aligned(qty, r.StepSize) {
+		return fmt.Errorf("%w: %s %v (step %v)", ErrInvalidStep, r.Symbol, qty, r.StepSize)
+	}
+	if r.MinQty > 0 && qty < r.MinQty {
+		return fmt.Errorf("%w: %s %v < %v", ErrQuantityTooSmall, r.Symbol, qty, r.MinQty)
+	}
+	if r.MaxQty > 0 && qty > r.MaxQty {
+		return fmt.Errorf("%w: %s %v > %v", ErrQuantityTooLarge, r.Symbol, qty, r.MaxQty)
+	}
+	if price > 0 && r.MinNotional > 0 && price*qty < r.MinNotional {
+		return fmt.Errorf("%w: %s %v < %v", ErrNotionalTooSmall, r.Symbol, price*qty, r.MinNotional)
+	}
+	return nil
+}
+
+type Source interface {
+	SymbolRules(ctx context.Context) ([]Rules, error)
+}
+
+type Config struct {
+	Path            string
+	RefreshInterval time.Duration
+}
+
+type Registry struct {
+	cfg     Config
+	source  Source
+	mu      sync.RWMutex
+	running bool
+	watched map[string]struct{}
+	rules   map[string]Rules
+	stopCh  chan struct{}
+}
+
+func New(cfg Config, source Source) *Registry {
+	if cfg.Path == "" {
+		cfg.Path = filepath.Join("state", "symbol_rules.json")
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = time.Hour
+	}
+
+	return &Registry{
+		cfg:     cfg,
+		source:  source,
+		watched: make(map[string]struct{}),
+		rules:   make(map[string]Rules),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start loads the persisted snapshot and refreshes it from the exchange. A
+// failed refresh is only fatal when there is no snapshot to fall back on.
+func (r *Registry) Start(ctx context.Context) error {
+	r.mu.Lock()
+	if r.running {
+		r.mu.Unlock()
+		return nil
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	if err := r.Load(); err != nil {
+		return err
+	}
+	if err := r.Refresh(ctx); err != nil && r.Len() == 0 {
+		return err
+	}
+
+	go r.run(ctx)
+	return nil
+}
+
+func (r *Registry) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.running {
+		return nil
+	}
+
+	r.running = false
+	close(r.stopCh)
+	return nil
+}
+
+func (r *Registry) run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.Refresh(ctx)
+		}
+	}
+}
+
+// Watch restricts the preloaded set to the given symbols. With nothing
+// watched, rules for every symbol the source returns are kept.
+func (r *Registry) Watch(symbols ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sym := range symbols {
+		r.watched[sym] = struct{}{}
+	}
+}
+
+func (r *Registry) Watched() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]string, 0, len(r.watched))
+	for sym := range r.watched {
+		result = append(result, sym)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (r *Registry) Refresh(ctx context.Context) error {
+	fetched, err := r.source.SymbolRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	for _, rule := range fetched {
+		if _, ok := r.watched[rule.Symbol]; len(r.watched) > 0 && !ok {
+			continue
+		}
+		if rule.UpdatedAt.IsZero() {
+			rule.UpdatedAt = now
+		}
+		r.rules[rule.Symbol] = rule
+	}
+	r.mu.Unlock()
+
+	return r.Save()
+}
+
+func (r *Registry) Get(symbol string) (Rules, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[symbol]
+	return rule, ok
+}
+
+// Ensure returns the rules for symbol, watching it and refreshing once if it
+// was not preloaded.
+func (r *Registry) Ensure(ctx context.Context, symbol string) (Rules, error) {
+	if rule, ok := r.Get(symbol); ok {
+		return rule, nil
+	}
+
+	r.Watch(symbol)
+	if err := r.Refresh(ctx); err != nil {
+		return Rules{}, err
+	}
+	if rule, ok := r.Get(symbol); ok {
+		return rule, nil
+	}
+	return Rules{}, fmt.Errorf("%w: %s", ErrUnknownSymbol, symbol)
+}
+
+func (r *Registry) Validate(symbol string, price, qty float64) error {
+	rule, ok := r.Get(symbol)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownSymbol, symbol)
+	}
+	return rule.Validate(price, qty)
+}
+
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.rules)
+}
+
+func (r *Registry) Load() error {
+	data, err := os.ReadFile(r.cfg.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read symbol rules: %w", err)
+	}
+
+	var rules []Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("failed to parse symbol rules: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rule := range rules {
+		r.rules[rule.Symbol] = rule
+	}
+	return nil
+}
+
+func (r *Registry) Save() error {
+	r.mu.RLock()
+	rules := make([]Rules, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Symbol < rules[j].Symbol
+	})
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal symbol rules: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.cfg.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create symbol rules directory: %w", err)
+	}
+
+	tmpPath := r.cfg.Path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write symbol rules: %w", err)
+	}
+	if err := os.Rename(tmpPath, r.cfg.Path); err != nil {
+		return fmt.Errorf("failed to rename symbol rules: %w", err)
+	}
+	return nil
+}
+
+func roundTo(v, step float64, fn func(float64) float64) float64 {
+	if step <= 0 {
+		return v
+	}
+	// The epsilon keeps values like 0.3/0.1 = 2.9999999 from flooring a step.
+	n := fn(v/step + 1e-9)
+	return n * step
+}
+
+func aligned(v, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	n := v / step
+	return math.Abs(n-math.Round(n)) < 1e-6
+}
+
+// decimals returns how many fractional digits step needs, e.g. 0.001 -> 3.
+func decimals(step float64) int {
+	if step <= 0 {
+		return 8
+	}
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package symbolrules
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+type mockSource struct {
+	rules []Rules
+	calls int
+}
+
+func (m *mockSource) SymbolRules(ctx context.Context) ([]Rules, error) {
+	m.calls++
+	return m.rules, nil
+}
+
+var btc = Rules{Symbol: "BTCUSDT", TickSize: 0.1, StepSize: 0.001, MinQty: 0.001, MinNotional: 100}
+
+func TestRules_RoundAndFormat(t *testing.T) {
+	if got := btc.FormatPrice(43210.1234); got != "43210.1" {
+		t.Errorf("expected 43210.1, got %s", got)
+	}
+	if got := btc.FormatQuantity(0.0129); got != "0.012" {
+		t.Errorf("expected quantity floored to 0.012, got %s", got)
+	}
+	if got := btc.RoundQuantity(0.3); got < 0.2999 {
+		t.Errorf("float noise should not drop a step, got %v", got)
+	}
+}
+
+func TestRules_Validate(t *testing.T) {
+	if err := btc.Validate(43210.1, 0.01); err != nil {
+		t.Errorf("expected valid order, got %v", err)
+	}
+	if err := btc.Validate(43210.15, 0.01); !errors.Is(err, ErrInvalidTick) {
+		t.Errorf("expected ErrInvalidTick, got %v", err)
+	}
+	if err := btc.Validate(43210.1, 0.0015); !errors.Is(err, ErrInvalidStep) {
+		t.Errorf("expected ErrInvalidStep, got %v", err)
+	}
+	if err := btc.Validate(43210.1, 0.002); !errors.Is(err, ErrNotionalTooSmall) {
+		t.Errorf("expected ErrNotionalTooSmall, got %v", err)
+	}
+}
+
+func TestRegistry_PersistsWatchedSymbols(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	src := &mockSource{rules: []Rules{btc, {Symbol: "ETHUSDT", TickSize: 0.01}}}
+
+	r := New(Config{Path: path}, src)
+	r.Watch("BTCUSDT")
+	if err := r.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Get("ETHUSDT"); ok {
+		t.Error("unwatched symbol should not be preloaded")
+	}
+
+	if _, err := r.Ensure(context.Background(), "ETHUSDT"); err != nil {
+		t.Errorf("expected Ensure to load ETHUSDT, got %v", err)
+	}
+
+	reloaded := New(Config{Path: path}, src)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Len() != 2 {
+		t.Errorf("expected 2 persisted symbols, got %d", reloaded.Len())
+	}
+}
+
+func TestRules_MinQuantity(t *testing.T) {
+	// 100 notional at 43210.1 needs 0.00231..., rounded up to the lot step.
+	if got := btc.MinQuantity(43210.1); math.Abs(got-0.003) > 1e-12 {
+		t.Errorf("expected 0.003, got %v", got)
+	}
+	if err := btc.Validate(43210.1, btc.MinQuantity(43210.1)); err != nil {
+		t.Errorf("minimum quantity should validate, got %v", err)
+	}
+	// An exact multiple is not bumped a step.
+	if got := btc.MinQuantity(50000); math.Abs(got-0.002) > 1e-12 {
+		t.Errorf("expected 0.002, got %v", got)
+	}
+	if got := btc.MinQuantity(1e6); got != btc.MinQty {
+		t.Errorf("expected the minimum quantity at high prices, got %v", got)
+	}
+}
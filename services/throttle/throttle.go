@@ -0,0 +1,369 @@
+// Package throttle holds back symbols the bot has stopped making money on.
+// Each closed trade's realized edge, its return from fill to exit net of
+// fees in basis points, joins a rolling window per symbol. When the
+// window's mean, the symbol's expectancy, turns negative the symbol needs a
+// stronger signal to be entered; when it falls below a floor the symbol is
+// suspended for a cooldown and then judged afresh on new trades.
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/services/suspension"
+)
+
+// Source marks suspensions made by the throttle. Only those are lifted
+// after the cooldown; a symbol suspended by an operator stays suspended.
+const Source = "throttle"
+
+// Switcher suspends and resumes symbols, typically the suspension
+// controller.
+type Switcher interface {
+	Suspend(target, reason, source string) error
+	Resume(target string) bool
+	List() []suspension.Entry
+}
+
+type Config struct {
+	// Window is how many recent trades per symbol make up its expectancy,
+	// and MinTrades how many it needs before it is judged.
+	Window    int
+	MinTrades int
+	// ConfidenceStep is added to the entry threshold of a symbol with
+	// negative expectancy, and of a symbol back from a suspension until it
+	// has MinTrades new trades.
+	ConfidenceStep float64
+	// SuspendBps is the expectancy at or below which the symbol is
+	// suspended; zero only raises the threshold.
+	SuspendBps    float64
+	Cooldown      time.Duration
+	CheckInterval time.Duration
+	// OnChange is called when a symbol is throttled, suspended or resumed.
+	OnChange func(Change)
+}
+
+// Change is one throttle transition of a symbol.
+type Change struct {
+	Symbol      string
+	Action      string
+	Expectancy  float64
+	Trades      int
+	Reason      string
+	At          time.Time
+	ResumeAfter time.Time
+}
+
+// Actions of a Change.
+const (
+	ActionThrottle = "throttle"
+	ActionRelease  = "release"
+	ActionSuspend  = "suspend"
+	ActionResume   = "resume"
+)
+
+func (c Change) String() string {
+	switch c.Action {
+	case ActionResume:
+		return fmt.Sprintf("%s resumed on probation after its cooldown", c.Symbol)
+	case ActionSuspend:
+		return fmt.Sprintf("%s suspended until %s: expectancy %.1f bps over %d trades", c.Symbol, c.ResumeAfter.Format(time.RFC3339), c.Expectancy, c.Trades)
+	case ActionRelease:
+		return fmt.Sprintf("%s released: expectancy %.1f bps over %d trades", c.Symbol, c.Expectancy, c.Trades)
+	}
+	return fmt.Sprintf("%s throttled: expectancy %.1f bps over %d trades", c.Symbol, c.Expectancy, c.Trades)
+}
+
+// SymbolStats is the current standing of one symbol.
+type SymbolStats struct {
+	Symbol     string  `json:"symbol"`
+	Trades     int     `json:"trades"`
+	Expectancy float64 `json:"expectancy_bps"`
+	Throttled  bool    `json:"throttled"`
+	Probation  bool    `json:"probation"`
+}
+
+type Stats struct {
+	Symbols     int
+	Throttled   int
+	Suspensions int
+	Resumes     int
+}
+
+type window struct {
+	edges     []float64
+	throttled bool
+	probation bool
+	// since drops trades closed before a resume, so a symbol back from a
+	// suspension is judged on its new trades only.
+	since time.Time
+}
+
+func (w *window) expectancy() float64 {
+	sum := 0.0
+	for _, v := range w.edges {
+		sum += v
+	}
+	return sum / float64(len(w.edges))
+}
+
+type Throttle struct {
+	cfg      Config
+	switcher Switcher
+	mu       sync.Mutex
+	running  bool
+	symbols  map[string]*window
+	stats    Stats
+	stopCh   chan struct{}
+	now      func() time.Time
+}
+
+// New creates a throttle. sw may be nil, in which case symbols are only
+// throttled, never suspended.
+func New(cfg Config, sw Switcher) *Throttle {
+	if cfg.Window <= 0 {
+		cfg.Window = 20
+	}
+	if cfg.MinTrades <= 0 {
+		cfg.MinTrades = 5
+	}
+	if cfg.MinTrades > cfg.Window {
+		cfg.MinTrades = cfg.Window
+	}
+	if cfg.ConfidenceStep <= 0 {
+		cfg.ConfidenceStep = 0.05
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 24 * time.Hour
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Minute
+	}
+
+	return &Throttle{
+		cfg:      cfg,
+		switcher: sw,
+		symbols:  make(map[string]*window),
+		stopCh:   make(chan struct{}),
+		now:      time.Now,
+	}
+}
+
+// Edge is the realized return of a trade from fill to exit after paying
+// feeRate on both sides, in basis points.
+func Edge(side string, fill, exit, feeRate float64) float64 {
+	if fill <= 0 || exit <= 0 {
+		return 0
+	}
+	move := (exit - fill) / fill
+	if side == "SELL" || side == "SHORT" {
+		move = -move
+	}
+	return (move - 2*feeRate) * 1e4
+}
+
+func (t *Throttle) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running {
+		return nil
+	}
+	t.running = true
+	go t.run(ctx)
+	return nil
+}
+
+func (t *Throttle) Stop() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.running {
+		return nil
+	}
+	t.running = false
+	close(t.stopCh)
+	return nil
+}
+
+func (t *Throttle) run(ctx context.Context) {
+	t.Check()
+
+	ticker := time.NewTicker(t.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+			t.Check()
+		}
+	}
+}
+
+// Record adds a closed trade's edge to symbol's window and throttles,
+// releases or suspends the symbol as its expectancy now reads.
+func (t *Throttle) Record(symbol string, edgeBps float64, at time.Time) {
+	symbol = strings.ToUpper(symbol)
+	if at.IsZero() {
+		at = t.now()
+	}
+
+	t.mu.Lock()
+	w := t.symbols[symbol]
+	if w == nil {
+		w = &window{}
+		t.symbols[symbol] = w
+	}
+	if at.Before(w.since) {
+		t.mu.Unlock()
+		return
+	}
+	w.edges = append(w.edges, edgeBps)
+	if len(w.edges) > t.cfg.Window {
+		w.edges = w.edges[len(w.edges)-t.cfg.Window:]
+	}
+	if len(w.edges) < t.cfg.MinTrades {
+		t.mu.Unlock()
+		return
+	}
+	w.probation = false
+
+	e := w.expectancy()
+	change := Change{Symbol: symbol, Expectancy: e, Trades: len(w.edges), At: at}
+	switch {
+	case e <= t.cfg.SuspendBps && t.cfg.SuspendBps < 0 && t.switcher != nil:
+		change.Action = ActionSuspend
+	case e < 0 && !w.throttled:
+		change.Action = ActionThrottle
+	case e >= 0 && w.throttled:
+		change.Action = ActionRelease
+	}
+	wasThrottled := w.throttled
+	w.throttled = e < 0
+	t.mu.Unlock()
+
+	if change.Action == ActionSuspend && !t.suspend(&change) {
+		if wasThrottled {
+			return
+		}
+		change.Action = ActionThrottle
+	}
+	if change.Action != "" && t.cfg.OnChange != nil {
+		t.cfg.OnChange(change)
+	}
+}
+
+// suspend suspends change's symbol unless it already is, and reports
+// whether it did.
+func (t *Throttle) suspend(change *Change) bool {
+	for _, e := range t.switcher.List() {
+		if e.Target == change.Symbol {
+			return false
+		}
+	}
+	change.ResumeAfter = change.At.Add(t.cfg.Cooldown)
+	change.Reason = fmt.Sprintf("expectancy %.1f bps over the last %d trades", change.Expectancy, change.Trades)
+	if err := t.switcher.Suspend(change.Symbol, change.Reason, Source); err != nil {
+		return false
+	}
+
+	t.mu.Lock()
+	t.stats.Suspensions++
+	t.mu.Unlock()
+	return true
+}
+
+// Check resumes the symbols this throttle suspended whose cooldown has
+// passed. A resumed symbol starts an empty window on probation.
+func (t *Throttle) Check() {
+	if t.switcher == nil {
+		return
+	}
+	now := t.now()
+	for _, e := range t.switcher.List() {
+		if e.Source != Source || now.Sub(e.Since) < t.cfg.Cooldown {
+			continue
+		}
+		if !t.switcher.Resume(e.Target) {
+			continue
+		}
+
+		t.mu.Lock()
+		t.symbols[e.Target] = &window{probation: true, throttled: true, since: now}
+		t.stats.Resumes++
+		t.mu.Unlock()
+
+		if t.cfg.OnChange != nil {
+			t.cfg.OnChange(Change{Symbol: e.Target, Action: ActionResume, Reason: e.Reason, At: now})
+		}
+	}
+}
+
+// RequiredConfidence raises base by ConfidenceStep for a throttled symbol or
+// one on probation.
+func (t *Throttle) RequiredConfidence(symbol string, base float64) float64 {
+	if !t.Throttled(symbol) {
+		return base
+	}
+	return math.Min(base+t.cfg.ConfidenceStep, 0.99)
+}
+
+func (t *Throttle) Throttled(symbol string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w := t.symbols[strings.ToUpper(symbol)]
+	return w != nil && (w.throttled || w.probation)
+}
+
+// Expectancy returns symbol's mean edge in basis points and how many trades
+// it is over.
+func (t *Throttle) Expectancy(symbol string) (float64, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w := t.symbols[strings.ToUpper(symbol)]
+	if w == nil || len(w.edges) == 0 {
+		return 0, 0
+	}
+	return w.expectancy(), len(w.edges)
+}
+
+// Symbols returns the standing of every symbol with trades, worst
+// expectancy first.
+func (t *Throttle) Symbols() []SymbolStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]SymbolStats, 0, len(t.symbols))
+	for symbol, w := range t.symbols {
+		s := SymbolStats{Symbol: symbol, Trades: len(w.edges), Throttled: w.throttled, Probation: w.probation}
+		if len(w.edges) > 0 {
+			s.Expectancy = w.expectancy()
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Expectancy < out[j].Expectancy })
+	return out
+}
+
+func (t *Throttle) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.stats
+	s.Symbols = len(t.symbols)
+	for _, w := range t.symbols {
+		if w.throttled || w.probation {
+			s.Throttled++
+		}
+	}
+	return s
+}
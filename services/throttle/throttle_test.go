@@ -0,0 +1,107 @@
+package throttle
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/services/suspension"
+)
+
+type switches struct {
+	entries map[string]suspension.Entry
+	now     time.Time
+}
+
+func (s *switches) Suspend(target, reason, source string) error {
+	s.entries[target] = suspension.Entry{Target: target, Reason: reason, Source: source, Since: s.now}
+	return nil
+}
+
+func (s *switches) Resume(target string) bool {
+	_, ok := s.entries[target]
+	delete(s.entries, target)
+	return ok
+}
+
+func (s *switches) List() []suspension.Entry {
+	out := make([]suspension.Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e)
+	}
+	return out
+}
+
+func TestEdge(t *testing.T) {
+	// A 1% long win pays 8 bps of fees.
+	if got := Edge("BUY", 100, 101, 0.0004); math.Abs(got-92) > 1e-6 {
+		t.Errorf("long edge = %f, want 92", got)
+	}
+	if got := Edge("SHORT", 100, 101, 0.0004); math.Abs(got+108) > 1e-6 {
+		t.Errorf("short edge = %f, want -108", got)
+	}
+}
+
+func TestThrottle_RaisesThresholdThenSuspendsAndResumes(t *testing.T) {
+	at := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	sw := &switches{entries: map[string]suspension.Entry{}, now: at}
+	var changes []Change
+	th := New(Config{Window: 4, MinTrades: 3, ConfidenceStep: 0.05, SuspendBps: -20, Cooldown: time.Hour, OnChange: func(c Change) { changes = append(changes, c) }}, sw)
+	th.now = func() time.Time { return at }
+
+	th.Record("wifusdt", 30, at)
+	th.Record("WIFUSDT", -40, at)
+	if th.Throttled("WIFUSDT") || th.RequiredConfidence("WIFUSDT", 0.6) != 0.6 {
+		t.Fatal("two trades should not be judged yet")
+	}
+	th.Record("WIFUSDT", -20, at)
+	if got := th.RequiredConfidence("WIFUSDT", 0.6); math.Abs(got-0.65) > 1e-9 {
+		t.Fatalf("negative expectancy should raise the threshold, got %f", got)
+	}
+	if len(changes) != 1 || changes[0].Action != ActionThrottle || len(sw.entries) != 0 {
+		t.Fatalf("changes = %+v, suspensions = %+v", changes, sw.entries)
+	}
+
+	// The window rolls: 30 drops out once four more trades are in.
+	th.Record("WIFUSDT", -10, at)
+	th.Record("WIFUSDT", -30, at)
+	if e, n := th.Expectancy("WIFUSDT"); n != 4 || math.Abs(e+25) > 1e-9 {
+		t.Fatalf("expectancy = %f over %d", e, n)
+	}
+	if _, ok := sw.entries["WIFUSDT"]; !ok || changes[len(changes)-1].Action != ActionSuspend {
+		t.Fatalf("expectancy below the floor should suspend: %+v", changes)
+	}
+
+	// An operator's suspension is left alone.
+	sw.entries["PEPEUSDT"] = suspension.Entry{Target: "PEPEUSDT", Source: suspension.SourceTelegram, Since: at}
+
+	at = at.Add(30 * time.Minute)
+	th.Check()
+	if _, ok := sw.entries["WIFUSDT"]; !ok {
+		t.Fatal("resumed before the cooldown")
+	}
+	at = at.Add(time.Hour)
+	th.Check()
+	if _, ok := sw.entries["WIFUSDT"]; ok {
+		t.Fatal("not resumed after the cooldown")
+	}
+	if _, ok := sw.entries["PEPEUSDT"]; !ok {
+		t.Fatal("operator suspension was lifted")
+	}
+
+	// Back on probation: the old trades no longer count and the threshold
+	// stays raised until three new trades are in.
+	th.Record("WIFUSDT", -50, at.Add(-2*time.Hour))
+	if _, n := th.Expectancy("WIFUSDT"); n != 0 || !th.Throttled("WIFUSDT") {
+		t.Fatalf("probation window holds %d trades", n)
+	}
+	for i := 0; i < 3; i++ {
+		th.Record("WIFUSDT", 15, at)
+	}
+	if th.Throttled("WIFUSDT") || changes[len(changes)-1].Action != ActionRelease {
+		t.Fatalf("profitable trades should release the symbol: %+v", changes)
+	}
+	if s := th.Stats(); s.Suspensions != 1 || s.Resumes != 1 || s.Throttled != 0 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
@@ -0,0 +1,354 @@
+// Package tif enters positions with limit orders of a fixed time in force.
+// Each has its own retry rule:
+//
+//   - IOC sweeps the book up to SweepBps beyond the far touch, keeps what
+//     filled and sweeps the rest again from a fresh quote, up to Retries
+//     times.
+//   - FOK does the same all-or-nothing: an attempt fills the whole entry or
+//     none of it, and only an attempt that expired is retried.
+//   - GTX posts at the near touch. A post-only rejection, because the touch
+//     moved through the price, is retried from a fresh quote; an order that
+//     rests is worked for RestFor and then cancelled, keeping what filled.
+//
+// An order whose outcome cannot be read back may have filled, so it is
+// never retried: the entry stops with ErrUnresolved.
+package tif
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+	"github.com/britej3/gobot/pkg/callpolicy"
+)
+
+// ErrUnfilled is returned for an entry no attempt filled.
+var ErrUnfilled = errors.New("time-in-force entry did not fill")
+
+var ErrNoBook = errors.New("no top of book to price from")
+
+// ErrUnresolved is returned when an order could be neither read back nor
+// cancelled. It may have filled beyond what the result reports.
+var ErrUnresolved = errors.New("time-in-force order state unknown")
+
+// Venue is the exchange surface the entries need.
+type Venue interface {
+	BookTop(ctx context.Context, symbol string) (bid, ask float64, err error)
+	CreateOrder(ctx context.Context, order *trade.Order) (*trade.Order, error)
+	GetOrder(ctx context.Context, orderID, symbol string) (*trade.Order, error)
+	CancelOrder(ctx context.Context, symbol, orderID string) (*trade.Order, error)
+}
+
+type Config struct {
+	TimeInForce trade.TimeInForce
+	// SweepBps is how far beyond the far touch IOC and FOK orders are
+	// priced. Default 5.
+	SweepBps float64
+	// Retries is how many more attempts follow the first. Default 2;
+	// negative allows none.
+	Retries int
+	// RestFor is how long a resting GTX order is worked. Default 10s.
+	RestFor      time.Duration
+	PollInterval time.Duration
+	// RoundPrice and RoundQuantity, if set, round to the symbol's filters.
+	RoundPrice    func(symbol string, price float64) float64
+	RoundQuantity func(symbol string, qty float64) float64
+	// PostOnlyRejected reports whether a CreateOrder error is the venue
+	// refusing a GTX order that would take.
+	PostOnlyRejected func(error) bool
+	Calls            *callpolicy.Policy
+}
+
+// Result is the outcome of one entry.
+type Result struct {
+	TimeInForce trade.TimeInForce
+	Filled      float64
+	AvgPrice    float64
+	Attempts    int
+	Duration    time.Duration
+}
+
+// Stats counts entries and attempts. Expired counts IOC and FOK attempts
+// that filled nothing, Rejected GTX orders refused as takers.
+type Stats struct {
+	TimeInForce trade.TimeInForce
+	Entries     int
+	Filled      int
+	Partial     int
+	Unfilled    int
+	Attempts    int
+	Retries     int
+	Expired     int
+	Rejected    int
+}
+
+type Executor struct {
+	cfg   Config
+	venue Venue
+	mu    sync.RWMutex
+	stats Stats
+}
+
+func New(cfg Config, venue Venue) (*Executor, error) {
+	switch cfg.TimeInForce {
+	case trade.TimeInForceIOC, trade.TimeInForceFOK, trade.TimeInForceGTX:
+	default:
+		return nil, fmt.Errorf("tif: unsupported time in force %q", cfg.TimeInForce)
+	}
+	if cfg.SweepBps <= 0 {
+		cfg.SweepBps = 5
+	}
+	if cfg.Retries < 0 {
+		cfg.Retries = 0
+	} else if cfg.Retries == 0 {
+		cfg.Retries = 2
+	}
+	if cfg.RestFor <= 0 {
+		cfg.RestFor = 10 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 500 * time.Millisecond
+	}
+	return &Executor{cfg: cfg, venue: venue, stats: Stats{TimeInForce: cfg.TimeInForce}}, nil
+}
+
+func (e *Executor) Stats() Stats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.stats
+}
+
+// Execute enters order's quantity and returns what filled, with ErrUnfilled
+// when nothing did. With ErrUnresolved the result holds only what is known
+// to have filled. Only the entry is placed; order's stop loss and take
+// profit are left to the caller.
+func (e *Executor) Execute(ctx context.Context, order *trade.Order) (Result, error) {
+	start := time.Now()
+	res := Result{TimeInForce: e.cfg.TimeInForce}
+	var notional float64
+	var err error
+
+	for res.Attempts <= e.cfg.Retries && ctx.Err() == nil {
+		remaining := e.roundQty(order.Symbol, order.Quantity-res.Filled)
+		if remaining <= 0 {
+			break
+		}
+		res.Attempts++
+
+		var filled, avg float64
+		var retry bool
+		filled, avg, retry, err = e.attempt(ctx, order, remaining)
+		res.Filled += filled
+		notional += filled * avg
+		if !retry {
+			break
+		}
+	}
+
+	if res.Filled > 0 {
+		res.AvgPrice = notional / res.Filled
+		if !errors.Is(err, ErrUnresolved) {
+			err = nil
+		}
+	} else if err == nil {
+		err = ErrUnfilled
+	}
+	res.Duration = time.Since(start)
+	e.record(res, order.Quantity)
+	return res, err
+}
+
+// attempt sends one order for qty and reports what filled and whether the
+// entry should try again.
+func (e *Executor) attempt(ctx context.Context, order *trade.Order, qty float64) (float64, float64, bool, error) {
+	bid, ask, err := e.bookTop(ctx, order.Symbol)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	price := far(order.Side, bid, ask)
+	if e.cfg.TimeInForce == trade.TimeInForceGTX {
+		price = near(order.Side, bid, ask)
+	} else if order.Side == trade.SideBuy {
+		price *= 1 + e.cfg.SweepBps/1e4
+	} else {
+		price *= 1 - e.cfg.SweepBps/1e4
+	}
+	if e.cfg.RoundPrice != nil {
+		price = e.cfg.RoundPrice(order.Symbol, price)
+	}
+
+	placed, err := e.place(ctx, order, qty, price)
+	if err != nil {
+		if e.cfg.TimeInForce == trade.TimeInForceGTX && e.cfg.PostOnlyRejected != nil && e.cfg.PostOnlyRejected(err) {
+			e.count(func(s *Stats) { s.Rejected++ })
+			return 0, 0, true, nil
+		}
+		return 0, 0, false, err
+	}
+
+	if e.cfg.TimeInForce == trade.TimeInForceGTX {
+		if placed.Status == trade.OrderStatusExpired && placed.FilledQty == 0 {
+			// Venues that accept the request expire a crossing GTX order
+			// instead of rejecting it.
+			e.count(func(s *Stats) { s.Rejected++ })
+			return 0, 0, true, nil
+		}
+		filled, avg, err := e.rest(ctx, order.Symbol, placed)
+		return filled, avg, false, err
+	}
+
+	final, err := e.settle(ctx, order.Symbol, placed)
+	if err != nil {
+		return placed.FilledQty, fillPrice(placed, price), false, err
+	}
+	if final.FilledQty <= 0 {
+		e.count(func(s *Stats) { s.Expired++ })
+	}
+	// IOC retries the rest of a partial fill; FOK fills all or nothing, so
+	// only its misses are retried.
+	return final.FilledQty, fillPrice(final, price), final.FilledQty < qty, nil
+}
+
+// settle returns the final state of an IOC or FOK order, reading it back
+// when the venue answered before the order was done. An order that cannot
+// be read back fails with ErrUnresolved rather than passing for unfilled.
+func (e *Executor) settle(ctx context.Context, symbol string, placed *trade.Order) (*trade.Order, error) {
+	if placed.Status == trade.OrderStatusFilled || placed.Status == trade.OrderStatusExpired {
+		return placed, nil
+	}
+	ctx, cancel := e.cfg.Calls.Context(ctx, callpolicy.Order)
+	defer cancel()
+	o, err := e.venue.GetOrder(ctx, placed.ID, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("%w: order %s: %v", ErrUnresolved, placed.ID, err)
+	}
+	return o, nil
+}
+
+// rest works a resting GTX order until it fills or RestFor passes, then
+// cancels it and returns what filled. One that can be neither cancelled
+// nor read back fails with ErrUnresolved.
+func (e *Executor) rest(ctx context.Context, symbol string, placed *trade.Order) (float64, float64, error) {
+	if placed.Status == trade.OrderStatusFilled {
+		return placed.FilledQty, fillPrice(placed, placed.Price), nil
+	}
+	ticker := time.NewTicker(e.cfg.PollInterval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(e.cfg.RestFor)
+
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			continue
+		case <-ticker.C:
+		}
+		getCtx, cancel := e.cfg.Calls.Context(ctx, callpolicy.Order)
+		o, err := e.venue.GetOrder(getCtx, placed.ID, symbol)
+		cancel()
+		if err == nil && o.Status == trade.OrderStatusFilled {
+			return o.FilledQty, fillPrice(o, placed.Price), nil
+		}
+	}
+
+	// The context may already be cancelled; the cancel must still go out.
+	cancelCtx, cancel := e.cfg.Calls.Context(context.Background(), callpolicy.Order)
+	defer cancel()
+	final, cancelErr := e.venue.CancelOrder(cancelCtx, symbol, placed.ID)
+	if cancelErr != nil {
+		var err error
+		if final, err = e.venue.GetOrder(cancelCtx, placed.ID, symbol); err != nil {
+			return placed.FilledQty, fillPrice(placed, placed.Price), fmt.Errorf("%w: order %s: cancel: %v; lookup: %v", ErrUnresolved, placed.ID, cancelErr, err)
+		}
+	}
+	return final.FilledQty, fillPrice(final, placed.Price), nil
+}
+
+func (e *Executor) place(ctx context.Context, order *trade.Order, qty, price float64) (*trade.Order, error) {
+	ctx, cancel := e.cfg.Calls.Context(ctx, callpolicy.Order)
+	defer cancel()
+
+	placed, err := e.venue.CreateOrder(ctx, &trade.Order{
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		Type:        trade.OrderTypeLimit,
+		TimeInForce: e.cfg.TimeInForce,
+		Quantity:    qty,
+		Price:       price,
+	})
+	if err != nil {
+		return nil, err
+	}
+	placed.Price = price
+	return placed, nil
+}
+
+func (e *Executor) bookTop(ctx context.Context, symbol string) (float64, float64, error) {
+	ctx, cancel := e.cfg.Calls.Context(ctx, callpolicy.Exchange)
+	defer cancel()
+
+	bid, ask, err := e.venue.BookTop(ctx, symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	if bid <= 0 || ask <= 0 {
+		return 0, 0, ErrNoBook
+	}
+	return bid, ask, nil
+}
+
+func (e *Executor) roundQty(symbol string, qty float64) float64 {
+	if e.cfg.RoundQuantity != nil {
+		return e.cfg.RoundQuantity(symbol, qty)
+	}
+	return qty
+}
+
+func (e *Executor) count(fn func(*Stats)) {
+	e.mu.Lock()
+	fn(&e.stats)
+	e.mu.Unlock()
+}
+
+func (e *Executor) record(res Result, requested float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stats.Entries++
+	e.stats.Attempts += res.Attempts
+	if res.Attempts > 1 {
+		e.stats.Retries += res.Attempts - 1
+	}
+	switch {
+	case res.Filled <= 0:
+		e.stats.Unfilled++
+	case res.Filled < requested:
+		e.stats.Partial++
+	default:
+		e.stats.Filled++
+	}
+}
+
+func near(side trade.Side, bid, ask float64) float64 {
+	if side == trade.SideBuy {
+		return bid
+	}
+	return ask
+}
+
+func far(side trade.Side, bid, ask float64) float64 {
+	if side == trade.SideBuy {
+		return ask
+	}
+	return bid
+}
+
+func fillPrice(o *trade.Order, fallback float64) float64 {
+	if o.AvgFillPrice > 0 {
+		return o.AvgFillPrice
+	}
+	return fallback
+}
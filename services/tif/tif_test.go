@@ -0,0 +1,162 @@
+package tif
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+var errPostOnly = errors.New("post only rejected")
+
+// fakeVenue answers each CreateOrder with the next scripted fill, as a
+// fraction of the requested quantity: 1 fills, 0 expires, and a negative
+// value rejects a GTX order. Resting orders fill once filled is set. With
+// acked set IOC and FOK orders are answered before they are done; with
+// down set lookups and cancels fail.
+type fakeVenue struct {
+	mu     sync.Mutex
+	script []float64
+	placed []trade.Order
+	filled bool
+	acked  bool
+	down   error
+}
+
+func (v *fakeVenue) BookTop(context.Context, string) (float64, float64, error) {
+	return 100, 100.2, nil
+}
+
+func (v *fakeVenue) CreateOrder(_ context.Context, o *trade.Order) (*trade.Order, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.placed = append(v.placed, *o)
+	placed := *o
+	placed.ID = strconv.Itoa(len(v.placed))
+
+	fraction := 0.0
+	if len(v.script) > 0 {
+		fraction, v.script = v.script[0], v.script[1:]
+	}
+	switch {
+	case fraction < 0:
+		return nil, errPostOnly
+	case o.TimeInForce == trade.TimeInForceGTX || v.acked:
+		placed.Status = trade.OrderStatusSubmitted
+	case fraction == 0:
+		placed.Status = trade.OrderStatusExpired
+	default:
+		placed.Status, placed.FilledQty, placed.AvgFillPrice = trade.OrderStatusExpired, o.Quantity*fraction, o.Price
+		if fraction == 1 {
+			placed.Status = trade.OrderStatusFilled
+		}
+	}
+	return &placed, nil
+}
+
+func (v *fakeVenue) GetOrder(_ context.Context, id, _ string) (*trade.Order, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.down != nil {
+		return nil, v.down
+	}
+	i, _ := strconv.Atoi(id)
+	o := v.placed[i-1]
+	o.ID = id
+	if v.filled {
+		o.Status, o.FilledQty, o.AvgFillPrice = trade.OrderStatusFilled, o.Quantity, o.Price
+	}
+	return &o, nil
+}
+
+func (v *fakeVenue) CancelOrder(ctx context.Context, symbol, id string) (*trade.Order, error) {
+	return v.GetOrder(ctx, id, symbol)
+}
+
+func entry() *trade.Order {
+	return &trade.Order{Symbol: "BTCUSDT", Side: trade.SideBuy, Quantity: 1}
+}
+
+func TestIOCSweepsTheRemainderAgain(t *testing.T) {
+	venue := &fakeVenue{script: []float64{0.25, 1}}
+	e, err := New(Config{TimeInForce: trade.TimeInForceIOC, SweepBps: 10}, venue)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := e.Execute(context.Background(), entry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Filled != 1 || res.Attempts != 2 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if p := venue.placed[0].Price; p < 100.3 || p > 100.31 {
+		t.Errorf("sweep priced at %v, want 10 bps through the 100.2 ask", p)
+	}
+	if venue.placed[1].Quantity != 0.75 {
+		t.Errorf("second sweep sent %v, want the 0.75 remainder", venue.placed[1].Quantity)
+	}
+	if st := e.Stats(); st.Filled != 1 || st.Retries != 1 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestFOKRetriesMissesAndGivesUp(t *testing.T) {
+	venue := &fakeVenue{script: []float64{0, 0, 0}}
+	e, _ := New(Config{TimeInForce: trade.TimeInForceFOK, Retries: 2}, venue)
+
+	res, err := e.Execute(context.Background(), entry())
+	if !errors.Is(err, ErrUnfilled) || res.Attempts != 3 {
+		t.Fatalf("got %+v, %v; want three misses and ErrUnfilled", res, err)
+	}
+	for _, o := range venue.placed {
+		if o.Quantity != 1 || o.TimeInForce != trade.TimeInForceFOK {
+			t.Errorf("attempt %+v is not the whole entry as FOK", o)
+		}
+	}
+	if st := e.Stats(); st.Unfilled != 1 || st.Expired != 3 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestGTXRetriesRejectionsThenRests(t *testing.T) {
+	venue := &fakeVenue{script: []float64{-1, 1}, filled: true}
+	e, _ := New(Config{
+		TimeInForce:      trade.TimeInForceGTX,
+		RestFor:          time.Second,
+		PollInterval:     time.Millisecond,
+		PostOnlyRejected: func(err error) bool { return errors.Is(err, errPostOnly) },
+	}, venue)
+
+	res, err := e.Execute(context.Background(), entry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Filled != 1 || res.AvgPrice != 100 || res.Attempts != 2 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if st := e.Stats(); st.Rejected != 1 || st.Filled != 1 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestOrdersThatCannotBeReadBackAreNotRetried(t *testing.T) {
+	down := errors.New("connection reset")
+
+	venue := &fakeVenue{script: []float64{0, 0, 0}, acked: true, down: down}
+	e, _ := New(Config{TimeInForce: trade.TimeInForceIOC}, venue)
+	if _, err := e.Execute(context.Background(), entry()); !errors.Is(err, ErrUnresolved) || len(venue.placed) != 1 {
+		t.Fatalf("IOC: err %v after %d orders; want ErrUnresolved after one", err, len(venue.placed))
+	}
+
+	venue = &fakeVenue{script: []float64{1, 1}, down: down}
+	e, _ = New(Config{TimeInForce: trade.TimeInForceGTX, RestFor: time.Millisecond, PollInterval: time.Millisecond}, venue)
+	if _, err := e.Execute(context.Background(), entry()); !errors.Is(err, ErrUnresolved) || len(venue.placed) != 1 {
+		t.Fatalf("GTX: err %v after %d orders; want ErrUnresolved after one", err, len(venue.placed))
+	}
+}
@@ -0,0 +1,234 @@
+package volspike
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+type KlineSource interface {
+	Klines(ctx context.Context, symbol, interval string, limit int) ([]trade.Kline, error)
+}
+
+// Config sets what counts as a spike and how hard entries are throttled
+// afterwards. A spike is a last-candle absolute log return whose z-score
+// against the preceding Lookback candles reaches ZScore.
+type Config struct {
+	Interval      string
+	Lookback      int
+	ZScore        float64
+	Duration      time.Duration
+	MaxLeverage   int
+	SizeFactor    float64
+	CheckInterval time.Duration
+}
+
+type Spike struct {
+	Symbol string
+	ZScore float64
+	Return float64
+	At     time.Time
+	Until  time.Time
+}
+
+type Detector struct {
+	cfg      Config
+	source   KlineSource
+	mu       sync.RWMutex
+	running  bool
+	watched  map[string]struct{}
+	until    time.Time
+	last     Spike
+	spikes   int
+	handlers []func(Spike)
+	stopCh   chan struct{}
+}
+
+func New(cfg Config, source KlineSource) *Detector {
+	if cfg.Interval == "" {
+		cfg.Interval = "1m"
+	}
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = 60
+	}
+	if cfg.ZScore <= 0 {
+		cfg.ZScore = 4
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = 15 * time.Minute
+	}
+	if cfg.MaxLeverage <= 0 {
+		cfg.MaxLeverage = 3
+	}
+	if cfg.SizeFactor <= 0 || cfg.SizeFactor > 1 {
+		cfg.SizeFactor = 0.5
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 30 * time.Second
+	}
+
+	return &Detector{
+		cfg:     cfg,
+		source:  source,
+		watched: make(map[string]struct{}),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func (d *Detector) OnSpike(fn func(Spike)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, fn)
+}
+
+func (d *Detector) Watch(symbols ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, s := range symbols {
+		d.watched[s] = struct{}{}
+	}
+}
+
+func (d *Detector) Start(ctx context.Context) error {
+	d.mu.Lock()
+	if d.running {
+		d.mu.Unlock()
+		return nil
+	}
+	d.running = true
+	d.mu.Unlock()
+
+	go d.run(ctx)
+	return nil
+}
+
+func (d *Detector) Stop() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.running {
+		return nil
+	}
+	d.running = false
+	close(d.stopCh)
+	return nil
+}
+
+func (d *Detector) run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.Check(ctx)
+		}
+	}
+}
+
+// Check scans every watched symbol and arms the throttle on the first spike.
+func (d *Detector) Check(ctx context.Context) {
+	d.mu.RLock()
+	symbols := make([]string, 0, len(d.watched))
+	for s := range d.watched {
+		symbols = append(symbols, s)
+	}
+	d.mu.RUnlock()
+
+	for _, symbol := range symbols {
+		klines, err := d.source.Klines(ctx, symbol, d.cfg.Interval, d.cfg.Lookback+2)
+		if err != nil {
+			continue
+		}
+		d.Observe(symbol, klines)
+	}
+}
+
+// Observe evaluates one symbol's candles and, on a spike, throttles
+// account-wide for Duration. It reports whether a spike was found.
+func (d *Detector) Observe(symbol string, klines []trade.Kline) (Spike, bool) {
+	z, ret, ok := zscore(klines)
+	if !ok || z < d.cfg.ZScore {
+		return Spike{}, false
+	}
+
+	now := time.Now()
+	spike := Spike{Symbol: symbol, ZScore: z, Return: ret, At: now, Until: now.Add(d.cfg.Duration)}
+
+	d.mu.Lock()
+	if spike.Until.After(d.until) {
+		d.until = spike.Until
+	}
+	d.last = spike
+	d.spikes++
+	handlers := append([]func(Spike){}, d.handlers...)
+	d.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(spike)
+	}
+	return spike, true
+}
+
+func (d *Detector) Active() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return time.Now().Before(d.until)
+}
+
+// Throttle returns the leverage cap and size multiplier to apply while a
+// spike is active; callers should ignore the values when active is false.
+func (d *Detector) Throttle() (maxLeverage int, sizeFactor float64, active bool) {
+	return d.cfg.MaxLeverage, d.cfg.SizeFactor, d.Active()
+}
+
+func (d *Detector) LastSpike() (Spike, int) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.last, d.spikes
+}
+
+// zscore compares the absolute log return of the latest candle with the
+// mean and standard deviation of those before it.
+func zscore(klines []trade.Kline) (z, ret float64, ok bool) {
+	if len(klines) < 12 {
+		return 0, 0, false
+	}
+
+	moves := make([]float64, 0, len(klines)-1)
+	for i := 1; i < len(klines); i++ {
+		prev, cur := klines[i-1].Close, klines[i].Close
+		if prev <= 0 || cur <= 0 {
+			return 0, 0, false
+		}
+		moves = append(moves, math.Log(cur/prev))
+	}
+
+	ret = moves[len(moves)-1]
+	history := moves[:len(moves)-1]
+
+	mean := 0.0
+	for _, m := range history {
+		mean += math.Abs(m)
+	}
+	mean /= float64(len(history))
+
+	variance := 0.0
+	for _, m := range history {
+		diff := math.Abs(m) - mean
+		variance += diff * diff
+	}
+	std := math.Sqrt(variance / float64(len(history)-1))
+	if std == 0 {
+		return 0, ret, false
+	}
+
+	return (math.Abs(ret) - mean) / std, ret, true
+}
@@ -0,0 +1,53 @@
+package volspike
+
+import (
+	"testing"
+
+	"github.com/britej3/gobot/domain/trade"
+)
+
+func candles(closes ...float64) []trade.Kline {
+	out := make([]trade.Kline, len(closes))
+	for i, c := range closes {
+		out[i] = trade.Kline{Close: c}
+	}
+	return out
+}
+
+func TestDetector_SpikeThrottlesUntilExpiry(t *testing.T) {
+	d := New(Config{}, nil)
+
+	calm := make([]float64, 0, 31)
+	price := 100.0
+	for i := 0; i < 30; i++ {
+		if i%2 == 0 {
+			price *= 1.001
+		} else {
+			price *= 0.9985
+		}
+		calm = append(calm, price)
+	}
+
+	if _, ok := d.Observe("BTCUSDT", candles(calm...)); ok {
+		t.Fatal("calm candles should not trigger a spike")
+	}
+	if _, _, active := d.Throttle(); active {
+		t.Fatal("throttle should be inactive before any spike")
+	}
+
+	var notified bool
+	d.OnSpike(func(Spike) { notified = true })
+
+	spike, ok := d.Observe("BTCUSDT", candles(append(calm, price*0.96)...))
+	if !ok {
+		t.Fatal("expected a 4% candle to register as a spike")
+	}
+	if spike.Return >= 0 || !notified {
+		t.Errorf("expected a negative spike with handler notified, got %+v notified=%v", spike, notified)
+	}
+
+	maxLev, factor, active := d.Throttle()
+	if !active || maxLev != 3 || factor != 0.5 {
+		t.Errorf("expected active throttle 3x/0.5, got %dx/%.2f active=%v", maxLev, factor, active)
+	}
+}